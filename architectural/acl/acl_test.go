@@ -0,0 +1,274 @@
+package acl
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sampleOrder() Order {
+	return Order{
+		ID:       "ORD-000042",
+		Customer: Customer{Name: "张三", Email: "zs@example.com", VIP: true},
+		Lines: []Line{
+			{SKU: "SKU-A", Quantity: 2, UnitPrice: 123456},
+			{SKU: "SKU-B", Quantity: 1, UnitPrice: 5},
+		},
+		Currency: EUR,
+		Status:   StatusShipped,
+		PlacedAt: time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// TestToDomainNormalizes 测试各种旧写法都转换为同一个领域值
+func TestToDomainNormalizes(t *testing.T) {
+	rec := LegacyOrder{
+		OrdNo: " ord-42 ", CustNm: " 张三 ", CustEml: "ZS@Example.com", VipFlg: "y",
+		Curr: "978", Stat: "03", OrdDt: "09/03/2024",
+		Items: " sku-a*2@1,234.56 | sku-b * 1 @0.05", Amt: "2469.17",
+	}
+	order, err := ToDomain(rec)
+	assert.NoError(t, err)
+	assert.Equal(t, sampleOrder(), order)
+	assert.Equal(t, Money(246917), order.Total())
+
+	cases := map[string]func(*LegacyOrder){
+		"无前缀订单号": func(r *LegacyOrder) { r.OrdNo = "000042" },
+		"紧凑订单号":  func(r *LegacyOrder) { r.OrdNo = "ORD000042" },
+		"紧凑日期":   func(r *LegacyOrder) { r.OrdDt = "20240309" },
+		"ISO 日期": func(r *LegacyOrder) { r.OrdDt = "2024-03-09" },
+		"字母货币代码": func(r *LegacyOrder) { r.Curr = "eur" },
+		"VIP 标志": func(r *LegacyOrder) { r.VipFlg = "1" },
+	}
+	for name, mutate := range cases {
+		variant := rec
+		mutate(&variant)
+		got, err := ToDomain(variant)
+		assert.NoError(t, err, name)
+		assert.Equal(t, order, got, name)
+	}
+}
+
+// TestToDomainMappingTables 测试映射表覆盖旧系统的所有编码
+func TestToDomainMappingTables(t *testing.T) {
+	base := ToLegacy(sampleOrder())
+
+	statuses := map[string]Status{
+		"01": StatusPending, "02": StatusPaid, "03": StatusShipped,
+		"04": StatusDelivered, "99": StatusCancelled, "x": StatusCancelled,
+	}
+	for code, want := range statuses {
+		rec := base
+		rec.Stat = code
+		order, err := ToDomain(rec)
+		assert.NoError(t, err, code)
+		assert.Equal(t, want, order.Status, code)
+	}
+
+	currencies := map[string]Currency{
+		"RMB": CNY, "CNY": CNY, "156": CNY, "USD": USD, "840": USD, "EUR": EUR, "978": EUR,
+	}
+	for code, want := range currencies {
+		rec := base
+		rec.Curr = code
+		order, err := ToDomain(rec)
+		assert.NoError(t, err, code)
+		assert.Equal(t, want, order.Currency, code)
+	}
+
+	for flag, want := range map[string]bool{"Y": true, "1": true, "true": true, "N": false, "0": false, "": false} {
+		rec := base
+		rec.VipFlg = flag
+		order, err := ToDomain(rec)
+		assert.NoError(t, err, flag)
+		assert.Equal(t, want, order.Customer.VIP, flag)
+	}
+
+	for _, email := range []string{"", "N/A", "n/a"} {
+		rec := base
+		rec.CustEml = email
+		order, err := ToDomain(rec)
+		assert.NoError(t, err, email)
+		assert.Empty(t, order.Customer.Email, email)
+	}
+
+	assert.Equal(t, []string{"99", "X"}, LegacyStatusCodes(StatusCancelled), "规范写法在前")
+	assert.Equal(t, []string{"01"}, LegacyStatusCodes(StatusPending))
+}
+
+// TestToDomainRejectsBadData 测试脏数据被拒绝，并一次报告所有问题字段
+func TestToDomainRejectsBadData(t *testing.T) {
+	rec := LegacyOrder{
+		OrdNo: "ORD-ABC", CustNm: "张三", VipFlg: "maybe",
+		Curr: "JPY", Stat: "07", OrdDt: "2024/03/09",
+		Items: "SKU-A*2@1.005", Amt: "2.01",
+	}
+	_, err := ToDomain(rec)
+	assert.ErrorIs(t, err, ErrInvalidLegacyData)
+	for _, field := range []string{"OrdNo", "VipFlg", "Curr", "Stat", "OrdDt", "Items"} {
+		assert.ErrorContains(t, err, field)
+	}
+
+	base := ToLegacy(sampleOrder())
+	cases := map[string]func(*LegacyOrder){
+		"总额与明细不一致": func(r *LegacyOrder) { r.Amt = "1.00" },
+		"总额格式错误":   func(r *LegacyOrder) { r.Amt = "12.3.4" },
+		"数量不是整数":   func(r *LegacyOrder) { r.Items = "SKU-A*two@1.00" },
+		"缺少单价":     func(r *LegacyOrder) { r.Items = "SKU-A*2" },
+		"没有明细":     func(r *LegacyOrder) { r.Items = " " },
+		"订单号超出范围":  func(r *LegacyOrder) { r.OrdNo = "ORD-1000000" },
+	}
+	for name, mutate := range cases {
+		variant := base
+		mutate(&variant)
+		_, err := ToDomain(variant)
+		assert.ErrorIs(t, err, ErrInvalidLegacyData, name)
+	}
+
+	// 字段都能转换，但违反领域规则
+	invalid := base
+	invalid.CustNm = " "
+	invalid.Items = "SKU-A*0@1.00"
+	invalid.Amt = "0.00"
+	_, err = ToDomain(invalid)
+	assert.ErrorIs(t, err, ErrInvalidLegacyData)
+	assert.ErrorIs(t, err, ErrInvalidOrder)
+	assert.ErrorContains(t, err, "客户名称为空")
+	assert.ErrorContains(t, err, "数量 0 必须为正数")
+}
+
+// TestRoundTripFromDomain 测试领域模型 -> 旧系统 -> 领域模型得到相同的订单
+func TestRoundTripFromDomain(t *testing.T) {
+	orders := []Order{sampleOrder()}
+
+	noEmail := sampleOrder()
+	noEmail.Customer = Customer{Name: "李四"}
+	noEmail.Currency = CNY
+	noEmail.Status = StatusCancelled
+	orders = append(orders, noEmail)
+
+	for _, status := range []Status{StatusPending, StatusPaid, StatusDelivered} {
+		o := sampleOrder()
+		o.Status = status
+		o.Currency = USD
+		o.Lines = []Line{{SKU: "BIG", Quantity: 1000, UnitPrice: 99999999}}
+		orders = append(orders, o)
+	}
+
+	for _, order := range orders {
+		got, err := ToDomain(ToLegacy(order))
+		assert.NoError(t, err)
+		assert.Equal(t, order, got)
+	}
+}
+
+// TestRoundTripFromLegacy 测试旧记录经过一次转换后成为规范写法，之后的往返保持不变
+func TestRoundTripFromLegacy(t *testing.T) {
+	messy := LegacyOrder{
+		OrdNo: "7", CustNm: "王五 ", CustEml: "n/a", VipFlg: "0",
+		Curr: "156", Stat: "x", OrdDt: "01/12/2023",
+		Items: "a-1*3@1000|b-2*1@0.5", Amt: "3,000.50",
+	}
+	order, err := ToDomain(messy)
+	assert.NoError(t, err)
+
+	canonical := ToLegacy(order)
+	assert.Equal(t, LegacyOrder{
+		OrdNo: "ORD-000007", CustNm: "王五", CustEml: "N/A", VipFlg: "N",
+		Curr: "RMB", Stat: "99", OrdDt: "20231201",
+		Items: "A-1*3@1,000.00|B-2*1@0.50", Amt: "3,000.50",
+	}, canonical)
+
+	again, err := ToDomain(canonical)
+	assert.NoError(t, err)
+	assert.Equal(t, canonical, ToLegacy(again), "规范写法是不动点")
+}
+
+// TestMoneyFormatting 测试金额的解析与格式化
+func TestMoneyFormatting(t *testing.T) {
+	cases := map[string]Money{
+		"0.00": 0, "0.05": 5, "1.50": 150, "999.99": 99999,
+		"1,000.00": 100000, "1,234,567.89": 123456789, "-12,345.60": -1234560,
+	}
+	for s, m := range cases {
+		assert.Equal(t, s, formatMoney(m))
+		parsed, err := parseMoney(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, m, parsed, s)
+	}
+
+	for s, m := range map[string]Money{"12": 1200, "12.5": 1250, ".5": 50, " 1,0.1 ": 1010} {
+		parsed, err := parseMoney(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, m, parsed, s)
+	}
+	for _, s := range []string{"", "abc", "1.234", "1..2", "1.-5", "--1"} {
+		_, err := parseMoney(s)
+		assert.Error(t, err, s)
+	}
+}
+
+// TestOrderRepository 测试外观把旧系统的返回码和记录转换为领域模型和 error
+func TestOrderRepository(t *testing.T) {
+	legacy := NewFakeLegacySystem(
+		LegacyOrder{OrdNo: "ORD-000001", CustNm: "A", Curr: "RMB", Stat: "99", OrdDt: "20240101", Items: "X*1@1", Amt: "1"},
+		LegacyOrder{OrdNo: "ORD-000002", CustNm: "B", Curr: "USD", Stat: "X", OrdDt: "20240102", Items: "Y*2@1", Amt: "2"},
+		LegacyOrder{OrdNo: "ORD-000003", CustNm: "C", Curr: "???", Stat: "X", OrdDt: "20240103", Items: "Z*1@1", Amt: "1"},
+	)
+	repo := NewOrderRepository(legacy)
+
+	order, err := repo.Find("ORD-000002")
+	assert.NoError(t, err)
+	assert.Equal(t, Money(200), order.Total())
+
+	_, err = repo.Find("ORD-000404")
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+
+	_, err = repo.Find("ORD-000003")
+	assert.ErrorIs(t, err, ErrInvalidLegacyData)
+
+	cancelled, err := repo.FindByStatus(StatusCancelled)
+	assert.ErrorIs(t, err, ErrInvalidLegacyData, "无法转换的记录单独报告")
+	assert.Len(t, cancelled, 2, "两种状态码的记录都返回")
+	assert.Equal(t, OrderID("ORD-000001"), cancelled[0].ID)
+	assert.Equal(t, OrderID("ORD-000002"), cancelled[1].ID)
+
+	// 保存以规范写法写回
+	order.Status = StatusDelivered
+	assert.NoError(t, repo.Save(order))
+	rec, rc := legacy.QueryOrd("ORD-000002")
+	assert.Equal(t, LegacyOK, rc)
+	assert.Equal(t, "04", rec.Stat)
+	assert.Equal(t, "2.00", rec.Amt)
+	assert.Equal(t, "N/A", rec.CustEml)
+
+	// 不合法的订单不会写入旧系统
+	bad := order
+	bad.Lines = nil
+	assert.ErrorIs(t, repo.Save(bad), ErrInvalidOrder)
+	rec, _ = legacy.QueryOrd("ORD-000002")
+	assert.Equal(t, "Y*2@1.00", rec.Items)
+
+	legacy.SetDown(true)
+	_, err = repo.Find("ORD-000001")
+	assert.ErrorIs(t, err, ErrLegacySystem)
+	_, err = repo.FindByStatus(StatusPending)
+	assert.ErrorIs(t, err, ErrLegacySystem)
+	err = repo.Save(order)
+	assert.ErrorIs(t, err, ErrLegacySystem)
+	assert.False(t, errors.Is(err, ErrOrderNotFound))
+}
+
+// BenchmarkToDomain 测试转换一条旧记录的开销
+func BenchmarkToDomain(b *testing.B) {
+	rec := ToLegacy(sampleOrder())
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ToDomain(rec); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
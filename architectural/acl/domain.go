@@ -0,0 +1,152 @@
+package acl
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// 领域模型相关错误
+var (
+	ErrInvalidOrder  = errors.New("订单不合法")
+	ErrOrderNotFound = errors.New("订单不存在")
+)
+
+// OrderID 订单号，格式为 ORD- 加 6 位数字
+type OrderID string
+
+var orderIDPattern = regexp.MustCompile(`^ORD-\d{6}$`)
+
+// Money 金额，以分为单位，避免浮点误差
+type Money int64
+
+// String 格式化为两位小数
+func (m Money) String() string {
+	sign := ""
+	if m < 0 {
+		sign, m = "-", -m
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, m/100, m%100)
+}
+
+// Currency 货币，使用 ISO 4217 字母代码
+type Currency string
+
+const (
+	CNY Currency = "CNY"
+	USD Currency = "USD"
+	EUR Currency = "EUR"
+)
+
+// Status 订单状态
+type Status int
+
+const (
+	StatusPending Status = iota + 1
+	StatusPaid
+	StatusShipped
+	StatusDelivered
+	StatusCancelled
+)
+
+// String 返回状态名称
+func (s Status) String() string {
+	switch s {
+	case StatusPending:
+		return "待支付"
+	case StatusPaid:
+		return "已支付"
+	case StatusShipped:
+		return "已发货"
+	case StatusDelivered:
+		return "已送达"
+	case StatusCancelled:
+		return "已取消"
+	default:
+		return fmt.Sprintf("Status(%d)", int(s))
+	}
+}
+
+// Customer 客户
+type Customer struct {
+	Name  string
+	Email string // 可以为空
+	VIP   bool
+}
+
+// Line 订单行
+type Line struct {
+	SKU       string
+	Quantity  int
+	UnitPrice Money
+}
+
+// Subtotal 小计
+func (l Line) Subtotal() Money {
+	return l.UnitPrice * Money(l.Quantity)
+}
+
+// Order 内部领域模型中的订单：字段类型明确，创建后总是合法的
+type Order struct {
+	ID       OrderID
+	Customer Customer
+	Lines    []Line
+	Currency Currency
+	Status   Status
+	PlacedAt time.Time // 下单日期，精确到天，UTC
+}
+
+// Total 订单总额
+func (o Order) Total() Money {
+	var total Money
+	for _, l := range o.Lines {
+		total += l.Subtotal()
+	}
+	return total
+}
+
+// Validate 校验领域规则，返回所有违反的规则
+func (o Order) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]any{ErrInvalidOrder}, args...)...))
+	}
+
+	if !orderIDPattern.MatchString(string(o.ID)) {
+		fail("订单号 %q 格式错误", o.ID)
+	}
+	if strings.TrimSpace(o.Customer.Name) == "" {
+		fail("客户名称为空")
+	}
+	if o.Customer.Email != "" && !strings.Contains(o.Customer.Email, "@") {
+		fail("邮箱 %q 格式错误", o.Customer.Email)
+	}
+	if len(o.Lines) == 0 {
+		fail("订单没有商品")
+	}
+	for i, l := range o.Lines {
+		if l.SKU == "" {
+			fail("第 %d 行缺少 SKU", i+1)
+		}
+		if l.Quantity <= 0 {
+			fail("第 %d 行数量 %d 必须为正数", i+1, l.Quantity)
+		}
+		if l.UnitPrice < 0 {
+			fail("第 %d 行单价 %s 不能为负", i+1, l.UnitPrice)
+		}
+	}
+	switch o.Currency {
+	case CNY, USD, EUR:
+	default:
+		fail("不支持的货币 %q", o.Currency)
+	}
+	if o.Status < StatusPending || o.Status > StatusCancelled {
+		fail("未知状态 %d", o.Status)
+	}
+	if o.PlacedAt.IsZero() {
+		fail("缺少下单日期")
+	}
+	return errors.Join(errs...)
+}
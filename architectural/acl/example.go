@@ -0,0 +1,66 @@
+package acl
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RunExample 运行防腐层示例：内部代码只通过 OrderRepository 访问旧订单系统
+func RunExample() {
+	legacy := NewFakeLegacySystem(
+		LegacyOrder{
+			OrdNo: "ORD-000101", CustNm: " 张三 ", CustEml: "ZhangSan@Example.com", VipFlg: "1",
+			Curr: "156", Stat: "02", OrdDt: "15/01/2024",
+			Items: "sku-a*2@1,200.00|sku-b*1@99.5", Amt: "2,499.50",
+		},
+		LegacyOrder{
+			OrdNo: "102", CustNm: "李四", CustEml: "N/A", VipFlg: "",
+			Curr: "USD", Stat: "X", OrdDt: "2024-02-01",
+			Items: "SKU-C*1@19.99", Amt: "19.99",
+		},
+		// 总额与明细对不上的脏数据
+		LegacyOrder{
+			OrdNo: "ORD-000103", CustNm: "王五", VipFlg: "N",
+			Curr: "RMB", Stat: "99", OrdDt: "20240301",
+			Items: "SKU-D*3@10.00", Amt: "31.00",
+		},
+	)
+	repo := NewOrderRepository(legacy)
+
+	order, err := repo.Find("ORD-000101")
+	if err != nil {
+		fmt.Println("查询失败:", err)
+		return
+	}
+	fmt.Printf("订单 %s: %s（VIP=%v），%s %s，%d 行，状态 %s，下单日期 %s\n",
+		order.ID, order.Customer.Name, order.Customer.VIP, order.Total(), order.Currency,
+		len(order.Lines), order.Status, order.PlacedAt.Format(time.DateOnly))
+
+	// 一个状态对应旧系统的多个状态码，脏数据被拒绝而不是混入结果
+	cancelled, err := repo.FindByStatus(StatusCancelled)
+	for _, o := range cancelled {
+		fmt.Printf("已取消订单: %s %s %s\n", o.ID, o.Total(), o.Currency)
+	}
+	if err != nil {
+		fmt.Println("被拒绝的记录:", err)
+	}
+
+	// 领域模型的修改以规范写法写回旧系统
+	order.Status = StatusShipped
+	if err := repo.Save(order); err != nil {
+		fmt.Println("保存失败:", err)
+		return
+	}
+	rec, _ := legacy.QueryOrd("ORD-000101")
+	fmt.Printf("写回旧系统的记录: %+v\n", rec)
+
+	// 旧系统的返回码被转换为 error
+	if _, err := repo.Find("ORD-000999"); errors.Is(err, ErrOrderNotFound) {
+		fmt.Println("查询不存在的订单:", err)
+	}
+	legacy.SetDown(true)
+	if _, err := repo.Find("ORD-000101"); errors.Is(err, ErrLegacySystem) {
+		fmt.Println("旧系统不可用:", err)
+	}
+}
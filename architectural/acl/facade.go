@@ -0,0 +1,79 @@
+package acl
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrLegacySystem 旧系统返回了错误码
+var ErrLegacySystem = errors.New("旧系统调用失败")
+
+// OrderRepository 防腐层的外观：内部代码只通过它读写订单，看到的只有领域模型和 error
+//
+// 旧系统的返回码、字段命名和各种写法都被挡在这一层之内，
+// 旧系统返回的脏数据在这里被拒绝，不会进入领域模型。
+type OrderRepository struct {
+	legacy LegacyOrderSystem
+}
+
+// NewOrderRepository 基于旧系统客户端创建订单仓储
+func NewOrderRepository(legacy LegacyOrderSystem) *OrderRepository {
+	return &OrderRepository{legacy: legacy}
+}
+
+// Find 按订单号查询订单
+func (r *OrderRepository) Find(id OrderID) (Order, error) {
+	rec, rc := r.legacy.QueryOrd(string(id))
+	if err := legacyError("QueryOrd", rc); err != nil {
+		return Order{}, fmt.Errorf("查询订单 %s: %w", id, err)
+	}
+	return ToDomain(rec)
+}
+
+// Save 校验订单后以规范写法写入旧系统
+func (r *OrderRepository) Save(order Order) error {
+	if err := order.Validate(); err != nil {
+		return err
+	}
+	if rc := r.legacy.UpsertOrd(ToLegacy(order)); rc != LegacyOK {
+		return fmt.Errorf("保存订单 %s: %w", order.ID, legacyError("UpsertOrd", rc))
+	}
+	return nil
+}
+
+// FindByStatus 查询某个状态的所有订单
+// 一个状态在旧系统中可能有多个状态码，逐个查询后合并；
+// 无法转换的记录不会返回，它们的错误合并为第二个返回值，调用者可以决定是否忽略
+func (r *OrderRepository) FindByStatus(status Status) ([]Order, error) {
+	var (
+		orders []Order
+		errs   []error
+	)
+	for _, code := range LegacyStatusCodes(status) {
+		recs, rc := r.legacy.ListOrdByStat(code)
+		if err := legacyError("ListOrdByStat", rc); err != nil {
+			return nil, fmt.Errorf("查询%s订单: %w", status, err)
+		}
+		for _, rec := range recs {
+			order, err := ToDomain(rec)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			orders = append(orders, order)
+		}
+	}
+	return orders, errors.Join(errs...)
+}
+
+// legacyError 把旧系统的返回码转换为 error
+func legacyError(op string, rc int) error {
+	switch rc {
+	case LegacyOK:
+		return nil
+	case LegacyNotFound:
+		return ErrOrderNotFound
+	default:
+		return fmt.Errorf("%w: %s 返回码 %d", ErrLegacySystem, op, rc)
+	}
+}
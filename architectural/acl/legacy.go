@@ -0,0 +1,106 @@
+package acl
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LegacyOrder 旧系统的订单记录
+//
+// 字段命名沿用旧系统，所有数据都是字符串，同一含义有多种写法：
+// 订单号可能带空格或缺少前缀，金额带千分位，日期有三种格式，是否标志有 Y/N/1/0 等写法。
+// 内部代码不应直接使用这个类型，而是通过防腐层转换为 Order。
+type LegacyOrder struct {
+	OrdNo   string // 订单号，如 "ORD-000123"、" ord000123 "、"123"
+	CustNm  string // 客户名称
+	CustEml string // 客户邮箱，未填写时为空或 "N/A"
+	VipFlg  string // 是否 VIP："Y"/"N"/"1"/"0"/""
+	Curr    string // 货币："RMB"、"CNY"、"156"、"USD"、"840"、"EUR"、"978"
+	Stat    string // 状态码："01" 待支付 "02" 已支付 "03" 已发货 "04" 已送达 "99"/"X" 已取消
+	OrdDt   string // 下单日期："20240115"、"2024-01-15"、"15/01/2024"
+	Items   string // 商品明细："SKU*数量@单价"，以 "|" 分隔，如 "A-1*2@9.99|B-2*1@1,005.00"
+	Amt     string // 订单总额，如 "1,024.98"，由旧系统冗余保存
+}
+
+// 旧系统接口的返回码
+const (
+	LegacyOK          = 0
+	LegacyNotFound    = 100
+	LegacyUnavailable = 503
+)
+
+// LegacyOrderSystem 旧订单系统的客户端：通过返回码而不是 error 报告结果
+type LegacyOrderSystem interface {
+	QueryOrd(ordNo string) (LegacyOrder, int)
+	UpsertOrd(rec LegacyOrder) int
+	ListOrdByStat(stat string) ([]LegacyOrder, int)
+}
+
+// FakeLegacySystem 内存中模拟的旧系统，用于示例和测试
+type FakeLegacySystem struct {
+	mutex   sync.Mutex
+	records map[string]LegacyOrder // 按原样保存，键为去掉空格的订单号
+	down    bool
+}
+
+// NewFakeLegacySystem 创建模拟的旧系统
+func NewFakeLegacySystem(records ...LegacyOrder) *FakeLegacySystem {
+	s := &FakeLegacySystem{records: make(map[string]LegacyOrder)}
+	for _, rec := range records {
+		s.records[strings.TrimSpace(rec.OrdNo)] = rec
+	}
+	return s
+}
+
+// QueryOrd 按订单号查询，订单号必须与保存时完全一致（去掉空格后）
+func (s *FakeLegacySystem) QueryOrd(ordNo string) (LegacyOrder, int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.down {
+		return LegacyOrder{}, LegacyUnavailable
+	}
+	rec, exists := s.records[strings.TrimSpace(ordNo)]
+	if !exists {
+		return LegacyOrder{}, LegacyNotFound
+	}
+	return rec, LegacyOK
+}
+
+// UpsertOrd 新增或覆盖订单
+func (s *FakeLegacySystem) UpsertOrd(rec LegacyOrder) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.down {
+		return LegacyUnavailable
+	}
+	s.records[strings.TrimSpace(rec.OrdNo)] = rec
+	return LegacyOK
+}
+
+// ListOrdByStat 按状态码查询，按订单号排序
+func (s *FakeLegacySystem) ListOrdByStat(stat string) ([]LegacyOrder, int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.down {
+		return nil, LegacyUnavailable
+	}
+	var recs []LegacyOrder
+	for _, rec := range s.records {
+		if rec.Stat == stat {
+			recs = append(recs, rec)
+		}
+	}
+	sort.Slice(recs, func(i, j int) bool { return recs[i].OrdNo < recs[j].OrdNo })
+	return recs, LegacyOK
+}
+
+// SetDown 模拟旧系统不可用
+func (s *FakeLegacySystem) SetDown(down bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.down = down
+}
@@ -0,0 +1,289 @@
+package acl
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidLegacyData 旧系统的数据无法转换为领域模型
+var ErrInvalidLegacyData = errors.New("旧系统数据无法转换")
+
+// 映射表：旧系统的编码 -> 领域模型，同一个值可能有多种写法
+var (
+	legacyStatus = map[string]Status{
+		"01": StatusPending,
+		"02": StatusPaid,
+		"03": StatusShipped,
+		"04": StatusDelivered,
+		"99": StatusCancelled,
+		"X":  StatusCancelled, // 早期版本用 X 表示取消
+	}
+	legacyCurrency = map[string]Currency{
+		"RMB": CNY,
+		"CNY": CNY,
+		"156": CNY, // ISO 4217 数字代码
+		"USD": USD,
+		"840": USD,
+		"EUR": EUR,
+		"978": EUR,
+	}
+	legacyFlag = map[string]bool{
+		"Y": true, "1": true, "TRUE": true,
+		"N": false, "0": false, "FALSE": false, "": false,
+	}
+)
+
+// 映射表：领域模型 -> 旧系统的规范写法，写回旧系统时使用
+var (
+	statusCode = map[Status]string{
+		StatusPending:   "01",
+		StatusPaid:      "02",
+		StatusShipped:   "03",
+		StatusDelivered: "04",
+		StatusCancelled: "99",
+	}
+	currencyCode = map[Currency]string{
+		CNY: "RMB",
+		USD: "USD",
+		EUR: "EUR",
+	}
+)
+
+// 旧系统使用过的日期格式，写回时使用第一种
+var legacyDateLayouts = []string{"20060102", "2006-01-02", "02/01/2006"}
+
+// legacyNoEmail 旧系统表示没有邮箱的写法
+const legacyNoEmail = "N/A"
+
+// ToDomain 把旧系统的订单转换为领域模型
+// 一次报告所有无法转换的字段，错误可以用 errors.Is 判断为 ErrInvalidLegacyData；
+// 转换后还会做领域校验，违反规则时错误同时包含 ErrInvalidOrder
+func ToDomain(rec LegacyOrder) (Order, error) {
+	var errs []error
+	fail := func(field, value string, reason string) {
+		errs = append(errs, fmt.Errorf("%w: %s=%q: %s", ErrInvalidLegacyData, field, value, reason))
+	}
+
+	var order Order
+	if id, err := parseOrderID(rec.OrdNo); err != nil {
+		fail("OrdNo", rec.OrdNo, err.Error())
+	} else {
+		order.ID = id
+	}
+
+	order.Customer.Name = strings.TrimSpace(rec.CustNm)
+	if email := strings.TrimSpace(rec.CustEml); !strings.EqualFold(email, legacyNoEmail) {
+		order.Customer.Email = strings.ToLower(email)
+	}
+	if vip, ok := legacyFlag[strings.ToUpper(strings.TrimSpace(rec.VipFlg))]; ok {
+		order.Customer.VIP = vip
+	} else {
+		fail("VipFlg", rec.VipFlg, "未知的标志")
+	}
+
+	if currency, ok := legacyCurrency[strings.ToUpper(strings.TrimSpace(rec.Curr))]; ok {
+		order.Currency = currency
+	} else {
+		fail("Curr", rec.Curr, "未知的货币代码")
+	}
+	if status, ok := legacyStatus[strings.ToUpper(strings.TrimSpace(rec.Stat))]; ok {
+		order.Status = status
+	} else {
+		fail("Stat", rec.Stat, "未知的状态码")
+	}
+	if placed, err := parseLegacyDate(rec.OrdDt); err != nil {
+		fail("OrdDt", rec.OrdDt, err.Error())
+	} else {
+		order.PlacedAt = placed
+	}
+
+	lines, err := parseItems(rec.Items)
+	if err != nil {
+		fail("Items", rec.Items, err.Error())
+	}
+	order.Lines = lines
+
+	// 旧系统冗余保存了总额，与明细不一致说明数据已经损坏，不能静默采用任何一方
+	if amount, err := parseMoney(rec.Amt); err != nil {
+		fail("Amt", rec.Amt, err.Error())
+	} else if lines != nil && amount != order.Total() {
+		fail("Amt", rec.Amt, fmt.Sprintf("与明细合计 %s 不一致", order.Total()))
+	}
+
+	if len(errs) > 0 {
+		return Order{}, errors.Join(errs...)
+	}
+	if err := order.Validate(); err != nil {
+		return Order{}, fmt.Errorf("%w: 订单 %s: %w", ErrInvalidLegacyData, order.ID, err)
+	}
+	return order, nil
+}
+
+// ToLegacy 把领域模型转换为旧系统的订单，每个字段都使用规范写法
+// 对 ToDomain 的结果调用 ToLegacy 得到的是规范化后的记录，再次 ToDomain 得到相同的订单
+func ToLegacy(order Order) LegacyOrder {
+	email := order.Customer.Email
+	if email == "" {
+		email = legacyNoEmail
+	}
+	vip := "N"
+	if order.Customer.VIP {
+		vip = "Y"
+	}
+
+	items := make([]string, len(order.Lines))
+	for i, l := range order.Lines {
+		items[i] = fmt.Sprintf("%s*%d@%s", l.SKU, l.Quantity, formatMoney(l.UnitPrice))
+	}
+
+	return LegacyOrder{
+		OrdNo:   string(order.ID),
+		CustNm:  order.Customer.Name,
+		CustEml: email,
+		VipFlg:  vip,
+		Curr:    currencyCode[order.Currency],
+		Stat:    statusCode[order.Status],
+		OrdDt:   order.PlacedAt.UTC().Format(legacyDateLayouts[0]),
+		Items:   strings.Join(items, "|"),
+		Amt:     formatMoney(order.Total()),
+	}
+}
+
+// LegacyStatusCodes 返回旧系统中表示该状态的所有状态码，用于按状态查询
+func LegacyStatusCodes(status Status) []string {
+	var codes []string
+	for code, s := range legacyStatus {
+		if s == status {
+			codes = append(codes, code)
+		}
+	}
+	// 规范写法排在前面，其余按字典序
+	canonical := statusCode[status]
+	sort.Slice(codes, func(i, j int) bool {
+		if (codes[i] == canonical) != (codes[j] == canonical) {
+			return codes[i] == canonical
+		}
+		return codes[i] < codes[j]
+	})
+	return codes
+}
+
+// parseOrderID 解析订单号：忽略大小写、空格和横线，数字部分补齐到 6 位
+// "ORD-000123"、" ord000123 "、"123" 都解析为 "ORD-000123"
+func parseOrderID(s string) (OrderID, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	s = strings.TrimPrefix(s, "ORD")
+	s = strings.TrimPrefix(s, "-")
+	if s == "" {
+		return "", errors.New("缺少订单编号")
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return "", errors.New("订单编号不是数字")
+	}
+	if n == 0 || n > 999999 {
+		return "", errors.New("订单编号超出范围")
+	}
+	return OrderID(fmt.Sprintf("ORD-%06d", n)), nil
+}
+
+// parseLegacyDate 按旧系统用过的几种格式依次尝试解析日期
+func parseLegacyDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range legacyDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("无法识别的日期格式")
+}
+
+// parseItems 解析商品明细 "SKU*数量@单价|..."
+func parseItems(s string) ([]Line, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, errors.New("没有商品明细")
+	}
+
+	var lines []Line
+	for i, part := range strings.Split(s, "|") {
+		sku, rest, ok := strings.Cut(strings.TrimSpace(part), "*")
+		if !ok {
+			return nil, fmt.Errorf("第 %d 项缺少 '*'", i+1)
+		}
+		qty, price, ok := strings.Cut(rest, "@")
+		if !ok {
+			return nil, fmt.Errorf("第 %d 项缺少 '@'", i+1)
+		}
+		quantity, err := strconv.Atoi(strings.TrimSpace(qty))
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 项数量 %q 不是整数", i+1, qty)
+		}
+		unitPrice, err := parseMoney(price)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 项单价: %w", i+1, err)
+		}
+		lines = append(lines, Line{
+			SKU:       strings.ToUpper(strings.TrimSpace(sku)),
+			Quantity:  quantity,
+			UnitPrice: unitPrice,
+		})
+	}
+	return lines, nil
+}
+
+// parseMoney 解析金额：允许千分位逗号，最多两位小数，结果以分为单位
+func parseMoney(s string) (Money, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+	if s == "" {
+		return 0, errors.New("金额为空")
+	}
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if len(frac) > 2 {
+		return 0, fmt.Errorf("金额 %q 超过两位小数", s)
+	}
+	frac += strings.Repeat("0", 2-len(frac))
+	if whole == "" {
+		whole = "0"
+	}
+	units, err := strconv.ParseUint(whole, 10, 62)
+	if err != nil {
+		return 0, fmt.Errorf("金额 %q 格式错误", s)
+	}
+	cents, err := strconv.ParseUint(frac, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("金额 %q 格式错误", s)
+	}
+
+	m := Money(units*100 + cents)
+	if negative {
+		m = -m
+	}
+	return m, nil
+}
+
+// formatMoney 按旧系统的习惯格式化金额：千分位逗号，两位小数
+func formatMoney(m Money) string {
+	s := m.String()
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+
+	var b strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	return sign + b.String() + "." + frac
+}
@@ -0,0 +1,72 @@
+package config_watcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format 配置文件格式
+type Format int
+
+const (
+	FormatAuto Format = iota // 按扩展名判断
+	FormatJSON
+	FormatYAML
+)
+
+// String 返回格式名称
+func (f Format) String() string {
+	switch f {
+	case FormatJSON:
+		return "JSON"
+	case FormatYAML:
+		return "YAML"
+	default:
+		return "自动"
+	}
+}
+
+// formatOf 按扩展名判断文件格式
+func formatOf(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON, nil
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	default:
+		return FormatAuto, fmt.Errorf("%w: %q", ErrUnsupportedFormat, filepath.Ext(path))
+	}
+}
+
+// decode 把配置内容严格解析为 T：出现 T 中没有的字段视为错误，避免拼错的键被静默忽略
+func decode[T any](format Format, data []byte) (T, error) {
+	var cfg T
+	var err error
+	switch format {
+	case FormatJSON:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&cfg)
+	case FormatYAML:
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(&cfg)
+		if errors.Is(err, io.EOF) {
+			// 空的 YAML 文件解析为零值，是否允许交给校验决定
+			err = nil
+		}
+	default:
+		err = fmt.Errorf("%w: %v", ErrUnsupportedFormat, format)
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("%w: %w", ErrParse, err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,91 @@
+package config_watcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Change 两个配置版本之间一个字段的变化
+// Path 是以点分隔的字段路径，字段名取 JSON 标签；新增的字段 Old 为 nil，删除的字段 New 为 nil，值为 null 视为不存在
+type Change struct {
+	Path string
+	Old  any
+	New  any
+}
+
+// String 返回变化的可读描述
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %v -> %v", c.Path, c.Old, c.New)
+}
+
+// Diff 比较两个配置，返回按路径排序的字段变化
+// 配置先按 JSON 编码展开为叶子字段再逐个比较：结构体和映射逐层展开，切片整体作为一个字段
+func Diff[T any](old, new T) ([]Change, error) {
+	before, err := flatten(old)
+	if err != nil {
+		return nil, err
+	}
+	after, err := flatten(new)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	for path, value := range after {
+		if prev, ok := before[path]; !ok || !reflect.DeepEqual(prev, value) {
+			changes = append(changes, Change{Path: path, Old: prev, New: value})
+		}
+	}
+	for path, prev := range before {
+		if _, ok := after[path]; !ok {
+			changes = append(changes, Change{Path: path, Old: prev})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// flatten 把配置展开为 路径 -> 叶子值
+func flatten(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("展开配置: %w", err)
+	}
+	var tree any
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("展开配置: %w", err)
+	}
+	leaves := make(map[string]any)
+	walk("", tree, leaves)
+	return leaves, nil
+}
+
+// walk 递归展开对象，非对象的值作为叶子
+func walk(prefix string, node any, leaves map[string]any) {
+	if node == nil {
+		// null 与字段不存在等同
+		return
+	}
+	obj, ok := node.(map[string]any)
+	if !ok || len(obj) == 0 {
+		if prefix != "" {
+			leaves[prefix] = node
+		}
+		return
+	}
+	for key, child := range obj {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		walk(path, child, leaves)
+	}
+}
+
+// under 判断路径是否等于 prefix 或位于 prefix 之下
+func under(path, prefix string) bool {
+	return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+".")
+}
@@ -0,0 +1,101 @@
+package config_watcher
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// AppConfig 示例中的应用配置
+type AppConfig struct {
+	Server struct {
+		Host string `json:"host" yaml:"host"`
+		Port int    `json:"port" yaml:"port"`
+	} `json:"server" yaml:"server"`
+	Log struct {
+		Level string `json:"level" yaml:"level"`
+	} `json:"log" yaml:"log"`
+	Features []string `json:"features" yaml:"features"`
+}
+
+// Validate 校验配置，未通过校验的版本不会生效
+func (c AppConfig) Validate() error {
+	var errs []error
+	if c.Server.Port <= 0 || c.Server.Port > 65535 {
+		errs = append(errs, fmt.Errorf("端口 %d 超出范围", c.Server.Port))
+	}
+	switch c.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Errorf("未知的日志级别 %q", c.Log.Level))
+	}
+	return errors.Join(errs...)
+}
+
+// RunExample 运行配置热加载示例：修改配置文件，订阅者收到字段级的变化，无效的版本被拒绝
+func RunExample() {
+	dir, err := os.MkdirTemp("", "config_watcher")
+	if err != nil {
+		fmt.Println("创建临时目录失败:", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.yaml")
+
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			fmt.Println("写入配置失败:", err)
+		}
+	}
+	write("server:\n  host: 0.0.0.0\n  port: 8080\nlog:\n  level: info\nfeatures: [search]\n")
+
+	w, err := New[AppConfig](path,
+		WithInterval(20*time.Millisecond),
+		WithErrorHandler(func(err error) { fmt.Println("拒绝新版本:", err) }),
+	)
+	if err != nil {
+		fmt.Println("加载配置失败:", err)
+		return
+	}
+	defer w.Close()
+	fmt.Printf("%v: 端口 %d，日志级别 %s\n", w.Current(), w.Config().Server.Port, w.Config().Log.Level)
+
+	changed := make(chan struct{}, 1)
+	w.Subscribe(func(e Event[AppConfig]) {
+		fmt.Printf("v%d -> v%d:\n", e.Old.Version, e.New.Version)
+		for _, c := range e.Changes {
+			fmt.Println("  ", c)
+		}
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+	w.SubscribePath("server", func(e Event[AppConfig]) {
+		fmt.Printf("   服务器配置变化，重新监听 %s:%d\n", e.New.Config.Server.Host, e.New.Config.Server.Port)
+	})
+
+	// 后台轮询发现修改
+	write("server:\n  host: 0.0.0.0\n  port: 9090\nlog:\n  level: debug\nfeatures: [search, export]\n")
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		fmt.Println("超时未收到变更通知")
+	}
+
+	// 无效的版本：校验失败，继续使用旧版本
+	write("server:\n  host: 0.0.0.0\n  port: 70000\nlog:\n  level: verbose\n")
+	if _, err := w.Reload(); err != nil {
+		fmt.Println("手动重新加载失败:", err)
+	}
+	fmt.Printf("仍在使用 %v: 端口 %d\n", w.Current(), w.Config().Server.Port)
+
+	// 只改注释：内容哈希变化，但没有字段变化，不通知订阅者
+	write("# 生产环境\nserver:\n  host: 0.0.0.0\n  port: 9090\nlog:\n  level: debug\nfeatures: [search, export]\n")
+	w.Reload()
+
+	stats := w.Stats()
+	fmt.Printf("统计: 成功 %d 次，拒绝 %d 次，当前 %v\n", stats.Reloads, stats.Rejected, w.Current())
+}
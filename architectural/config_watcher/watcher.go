@@ -0,0 +1,361 @@
+package config_watcher
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 配置监视相关错误
+var (
+	ErrUnsupportedFormat = errors.New("不支持的配置格式")
+	ErrParse             = errors.New("解析配置失败")
+	ErrInvalidConfig     = errors.New("配置校验失败")
+)
+
+// Validator 配置类型可以实现的校验接口
+// 新版本的配置只有通过校验才会替换当前快照，校验失败时继续使用旧版本
+type Validator interface {
+	Validate() error
+}
+
+// Option 监视器配置选项
+type Option func(*config)
+
+// config 监视器配置，与配置类型无关
+type config struct {
+	interval time.Duration
+	format   Format
+	onError  func(err error)
+}
+
+// WithInterval 轮询文件的间隔，默认 1 秒
+func WithInterval(d time.Duration) Option {
+	return func(c *config) {
+		if d > 0 {
+			c.interval = d
+		}
+	}
+}
+
+// WithFormat 指定文件格式，默认按扩展名判断（.json、.yaml、.yml）
+func WithFormat(f Format) Option {
+	return func(c *config) {
+		c.format = f
+	}
+}
+
+// WithErrorHandler 后台重新加载失败或订阅者 panic 时调用
+// 失败的重新加载不影响当前快照，这里是唯一能观察到它们的地方
+func WithErrorHandler(fn func(err error)) Option {
+	return func(c *config) {
+		c.onError = fn
+	}
+}
+
+// Snapshot 某个版本的配置，加载后不再修改，可以安全地在多个协程间共享
+type Snapshot[T any] struct {
+	Config   T
+	Version  uint64 // 从 1 开始，每次成功替换加一
+	Hash     string // 文件内容的 SHA-256
+	LoadedAt time.Time
+}
+
+// String 返回快照的可读摘要
+func (s *Snapshot[T]) String() string {
+	return fmt.Sprintf("配置 v%d (%s)", s.Version, s.Hash[:12])
+}
+
+// Event 配置变更通知
+type Event[T any] struct {
+	Old     *Snapshot[T]
+	New     *Snapshot[T]
+	Changes []Change
+}
+
+// Changed 判断 prefix 下是否有字段发生变化，prefix 为空时判断是否有任何变化
+func (e Event[T]) Changed(prefix string) bool {
+	for _, c := range e.Changes {
+		if under(c.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Stats 监视器统计信息
+type Stats struct {
+	Checks    int // 检查文件的次数
+	Reloads   int // 成功替换快照的次数
+	Unchanged int // 文件被改写但内容没有变化的次数
+	Rejected  int // 读取、解析或校验失败而保留旧版本的次数
+}
+
+// subscriber 订阅者，prefix 非空时只关心该路径下的变化
+type subscriber[T any] struct {
+	id     uint64
+	prefix string
+	fn     func(Event[T])
+}
+
+// Watcher 配置热加载监视器：轮询配置文件，内容变化且新版本通过校验后原子地替换快照，并把变化通知订阅者
+//
+// 读取配置只是一次原子指针加载，不加锁；替换快照是写时复制（Copy-on-Write），
+// 读者在替换前后拿到的都是完整的某一个版本，不会看到一半新一半旧的配置。
+type Watcher[T any] struct {
+	path    string
+	config  config
+	format  Format
+	current atomic.Pointer[Snapshot[T]]
+
+	reloadMutex sync.Mutex // 同一时间只有一次加载，通知按版本顺序送达
+	modTime     time.Time  // 上次读取时文件的修改时间和大小，由 reloadMutex 保护
+	size        int64
+
+	mutex       sync.RWMutex
+	subscribers []subscriber[T]
+	nextID      uint64
+	stats       Stats
+
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+// New 加载配置文件并启动后台轮询
+// 首次加载失败（文件不存在、格式不支持、解析或校验失败）时直接返回错误，不会以无效配置启动
+func New[T any](path string, opts ...Option) (*Watcher[T], error) {
+	cfg := config{interval: time.Second}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	format := cfg.format
+	if format == FormatAuto {
+		f, err := formatOf(path)
+		if err != nil {
+			return nil, err
+		}
+		format = f
+	}
+
+	w := &Watcher[T]{
+		path:   path,
+		config: cfg,
+		format: format,
+		done:   make(chan struct{}),
+	}
+	if _, err := w.Reload(); err != nil {
+		return nil, err
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	w.stop = stop
+	go w.run(ctx)
+	return w, nil
+}
+
+// Current 返回当前的配置快照
+func (w *Watcher[T]) Current() *Snapshot[T] {
+	return w.current.Load()
+}
+
+// Config 返回当前的配置
+func (w *Watcher[T]) Config() T {
+	return w.current.Load().Config
+}
+
+// Subscribe 订阅配置变更，返回取消订阅的函数
+// 通知在加载配置的协程中按版本顺序同步调用，fn 中不要调用 Reload，耗时的处理应交给其他协程
+func (w *Watcher[T]) Subscribe(fn func(Event[T])) func() {
+	return w.SubscribePath("", fn)
+}
+
+// SubscribePath 只订阅 prefix 下字段的变更，例如 "server" 同时匹配 "server.port" 和 "server.host"
+func (w *Watcher[T]) SubscribePath(prefix string, fn func(Event[T])) func() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.nextID++
+	id := w.nextID
+	w.subscribers = append(w.subscribers, subscriber[T]{id: id, prefix: prefix, fn: fn})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { w.unsubscribe(id) })
+	}
+}
+
+// unsubscribe 移除订阅者，复制切片以免影响正在进行的通知
+func (w *Watcher[T]) unsubscribe(id uint64) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	subscribers := make([]subscriber[T], 0, len(w.subscribers))
+	for _, s := range w.subscribers {
+		if s.id != id {
+			subscribers = append(subscribers, s)
+		}
+	}
+	w.subscribers = subscribers
+}
+
+// Reload 立即读取配置文件，不论修改时间是否变化
+// 内容变化且通过校验时替换快照并通知订阅者，返回 true；内容没有变化返回 false；
+// 失败时保留当前快照并返回错误，可以用 errors.Is 判断为 ErrParse 或 ErrInvalidConfig
+func (w *Watcher[T]) Reload() (bool, error) {
+	w.reloadMutex.Lock()
+	defer w.reloadMutex.Unlock()
+	return w.reloadLocked()
+}
+
+// Stats 返回统计信息
+func (w *Watcher[T]) Stats() Stats {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	return w.stats
+}
+
+// Close 停止后台轮询，之后 Current 仍返回最后一个版本
+func (w *Watcher[T]) Close() {
+	w.stop()
+	<-w.done
+}
+
+// run 后台轮询
+func (w *Watcher[T]) run(ctx context.Context) {
+	defer close(w.done)
+	ticker := time.NewTicker(w.config.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := w.poll(); err != nil {
+				w.report(err)
+			}
+		}
+	}
+}
+
+// poll 修改时间和大小都没有变化时跳过，否则读取文件，再由内容哈希判断是否真的变化
+func (w *Watcher[T]) poll() (bool, error) {
+	w.reloadMutex.Lock()
+	defer w.reloadMutex.Unlock()
+
+	w.count(func(s *Stats) { s.Checks++ })
+	info, err := os.Stat(w.path)
+	if err != nil {
+		w.count(func(s *Stats) { s.Rejected++ })
+		return false, err
+	}
+	if info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return false, nil
+	}
+	return w.reloadLocked()
+}
+
+// reloadLocked 读取、解析、校验并替换快照，调用方持有 reloadMutex
+func (w *Watcher[T]) reloadLocked() (bool, error) {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		w.count(func(s *Stats) { s.Rejected++ })
+		return false, err
+	}
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		w.count(func(s *Stats) { s.Rejected++ })
+		return false, err
+	}
+	// 不论新内容能否使用都记下修改时间，无效的文件只报告一次，直到再次被修改
+	w.modTime, w.size = info.ModTime(), info.Size()
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	old := w.current.Load()
+	if old != nil && old.Hash == hash {
+		w.count(func(s *Stats) { s.Unchanged++ })
+		return false, nil
+	}
+
+	cfg, err := decode[T](w.format, data)
+	if err == nil {
+		err = validate(&cfg)
+	}
+	if err != nil {
+		w.count(func(s *Stats) { s.Rejected++ })
+		return false, fmt.Errorf("%s: %w", w.path, err)
+	}
+
+	snapshot := &Snapshot[T]{Config: cfg, Hash: hash, LoadedAt: time.Now(), Version: 1}
+	if old != nil {
+		snapshot.Version = old.Version + 1
+	}
+	w.current.Store(snapshot)
+	w.count(func(s *Stats) { s.Reloads++ })
+
+	if old != nil {
+		w.notify(old, snapshot)
+	}
+	return true, nil
+}
+
+// validate 配置实现了 Validator 时执行校验，值接收者和指针接收者都可以
+func validate[T any](cfg *T) error {
+	if v, ok := any(cfg).(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+		}
+	}
+	return nil
+}
+
+// notify 计算两个版本的差异并通知关心这些变化的订阅者
+func (w *Watcher[T]) notify(old, new *Snapshot[T]) {
+	changes, err := Diff(old.Config, new.Config)
+	if err != nil {
+		w.report(err)
+		return
+	}
+	if len(changes) == 0 {
+		// 内容不同但字段相同，例如只修改了注释或缩进
+		return
+	}
+	event := Event[T]{Old: old, New: new, Changes: changes}
+
+	w.mutex.RLock()
+	subscribers := w.subscribers
+	w.mutex.RUnlock()
+	for _, s := range subscribers {
+		if event.Changed(s.prefix) {
+			w.deliver(s.fn, event)
+		}
+	}
+}
+
+// deliver 调用订阅者，把 panic 转换为错误报告，不影响其他订阅者
+func (w *Watcher[T]) deliver(fn func(Event[T]), event Event[T]) {
+	defer func() {
+		if r := recover(); r != nil {
+			w.report(fmt.Errorf("订阅者 panic: %v", r))
+		}
+	}()
+	fn(event)
+}
+
+// count 更新统计信息
+func (w *Watcher[T]) count(update func(*Stats)) {
+	w.mutex.Lock()
+	update(&w.stats)
+	w.mutex.Unlock()
+}
+
+// report 把后台错误交给错误处理函数
+func (w *Watcher[T]) report(err error) {
+	if w.config.onError != nil {
+		w.config.onError(err)
+	}
+}
@@ -0,0 +1,305 @@
+package config_watcher
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testConfig 测试用的配置
+type testConfig struct {
+	Name   string         `json:"name" yaml:"name"`
+	Limits map[string]int `json:"limits" yaml:"limits"`
+	DB     struct {
+		DSN  string `json:"dsn" yaml:"dsn"`
+		Pool int    `json:"pool" yaml:"pool"`
+	} `json:"db" yaml:"db"`
+	Tags []string `json:"tags,omitempty" yaml:"tags"`
+}
+
+func (c *testConfig) Validate() error {
+	if c.DB.Pool < 1 {
+		return errors.New("连接池至少为 1")
+	}
+	return nil
+}
+
+// writeFile 写入配置文件并把修改时间推后，保证轮询能发现修改
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	var mtime time.Time
+	if info, err := os.Stat(path); err == nil {
+		mtime = info.ModTime()
+	}
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	if !mtime.IsZero() {
+		assert.NoError(t, os.Chtimes(path, mtime.Add(time.Second), mtime.Add(time.Second)))
+	}
+}
+
+// newWatcher 在临时目录中创建配置文件和监视器，后台轮询间隔很长，测试通过 Reload 和 poll 驱动
+func newWatcher(t *testing.T, name, content string, opts ...Option) (*Watcher[testConfig], string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	writeFile(t, path, content)
+	w, err := New[testConfig](path, append([]Option{WithInterval(time.Hour)}, opts...)...)
+	assert.NoError(t, err)
+	t.Cleanup(w.Close)
+	return w, path
+}
+
+// TestLoadFormats 测试 JSON 和 YAML 加载为相同的配置
+func TestLoadFormats(t *testing.T) {
+	jw, _ := newWatcher(t, "app.json", `{"name":"svc","limits":{"qps":100},"db":{"dsn":"pg://a","pool":4}}`)
+	yw, _ := newWatcher(t, "app.yml", "name: svc\nlimits:\n  qps: 100\ndb:\n  dsn: pg://a\n  pool: 4\n")
+
+	assert.Equal(t, jw.Config(), yw.Config())
+	assert.Equal(t, "svc", jw.Config().Name)
+	assert.Equal(t, 100, yw.Config().Limits["qps"])
+	assert.Equal(t, uint64(1), jw.Current().Version)
+	assert.Len(t, jw.Current().Hash, 64)
+
+	// 扩展名不能识别时需要指定格式
+	path := filepath.Join(t.TempDir(), "app.conf")
+	writeFile(t, path, "name: svc\ndb:\n  pool: 1\n")
+	_, err := New[testConfig](path)
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+	w, err := New[testConfig](path, WithFormat(FormatYAML))
+	assert.NoError(t, err)
+	w.Close()
+}
+
+// TestInitialLoadFailure 测试首次加载失败时不会以无效配置启动
+func TestInitialLoadFailure(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := New[testConfig](filepath.Join(dir, "missing.json"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	path := filepath.Join(dir, "bad.json")
+	writeFile(t, path, `{"name":"svc","db":{"pool":0}}`)
+	_, err = New[testConfig](path)
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+
+	writeFile(t, path, `{"name":"svc","db":{"pool":1},"nmae":"typo"}`)
+	_, err = New[testConfig](path)
+	assert.ErrorIs(t, err, ErrParse, "未知字段视为错误")
+}
+
+// TestReloadSwapsSnapshot 测试内容变化时替换快照并通知订阅者，旧快照不受影响
+func TestReloadSwapsSnapshot(t *testing.T) {
+	w, path := newWatcher(t, "app.json", `{"name":"svc","limits":{"qps":100,"burst":10},"db":{"dsn":"pg://a","pool":4}}`)
+	first := w.Current()
+
+	var events []Event[testConfig]
+	unsubscribe := w.Subscribe(func(e Event[testConfig]) { events = append(events, e) })
+
+	writeFile(t, path, `{"name":"svc","limits":{"qps":200,"conn":5},"db":{"dsn":"pg://a","pool":8},"tags":["a"]}`)
+	changed, err := w.Reload()
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	assert.Equal(t, uint64(2), w.Current().Version)
+	assert.Equal(t, 8, w.Config().DB.Pool)
+	assert.Equal(t, 4, first.Config.DB.Pool, "已经取得的快照不受替换影响")
+
+	assert.Len(t, events, 1)
+	assert.Same(t, first, events[0].Old)
+	assert.Same(t, w.Current(), events[0].New)
+	assert.Equal(t, []Change{
+		{Path: "db.pool", Old: float64(4), New: float64(8)},
+		{Path: "limits.burst", Old: float64(10)},
+		{Path: "limits.conn", New: float64(5)},
+		{Path: "limits.qps", Old: float64(100), New: float64(200)},
+		{Path: "tags", New: []any{"a"}},
+	}, events[0].Changes)
+	assert.True(t, events[0].Changed("limits"))
+	assert.False(t, events[0].Changed("name"))
+	assert.False(t, events[0].Changed("db.dsn"))
+
+	// 内容相同：不替换、不通知
+	changed, err = w.Reload()
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	// 只改格式：替换快照，但没有字段变化，不通知
+	writeFile(t, path, "{\n  \"name\": \"svc\", \"limits\": {\"qps\": 200, \"conn\": 5},\n  \"db\": {\"dsn\": \"pg://a\", \"pool\": 8}, \"tags\": [\"a\"]\n}\n")
+	changed, err = w.Reload()
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, uint64(3), w.Current().Version)
+	assert.Len(t, events, 1)
+
+	unsubscribe()
+	unsubscribe()
+	writeFile(t, path, `{"name":"svc2","db":{"pool":1}}`)
+	w.Reload()
+	assert.Len(t, events, 1, "取消订阅后不再通知")
+
+	stats := w.Stats()
+	assert.Equal(t, 4, stats.Reloads)
+	assert.Equal(t, 1, stats.Unchanged)
+}
+
+// TestRejectedVersionKeepsOld 测试解析或校验失败的新版本不生效，修复后再次加载
+func TestRejectedVersionKeepsOld(t *testing.T) {
+	var (
+		mutex sync.Mutex
+		errs  []error
+	)
+	w, path := newWatcher(t, "app.yaml", "name: svc\ndb:\n  pool: 2\n", WithErrorHandler(func(err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		errs = append(errs, err)
+	}))
+	notified := 0
+	w.Subscribe(func(Event[testConfig]) { notified++ })
+
+	writeFile(t, path, "name: svc\ndb:\n  pool: 0\n")
+	_, err := w.Reload()
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+	assert.ErrorContains(t, err, "连接池至少为 1")
+
+	writeFile(t, path, "name: [svc\n")
+	_, err = w.Reload()
+	assert.ErrorIs(t, err, ErrParse)
+
+	assert.Equal(t, uint64(1), w.Current().Version)
+	assert.Equal(t, 2, w.Config().DB.Pool)
+	assert.Equal(t, 0, notified)
+	assert.Equal(t, 2, w.Stats().Rejected)
+
+	writeFile(t, path, "name: svc\ndb:\n  pool: 3\n")
+	changed, err := w.Reload()
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, uint64(2), w.Current().Version, "被拒绝的版本不占用版本号")
+	assert.Equal(t, 1, notified)
+	assert.Empty(t, errs, "手动 Reload 的错误直接返回，不交给错误处理函数")
+}
+
+// TestPollUsesModTime 测试轮询先比较修改时间和大小，没有变化时不读取文件
+func TestPollUsesModTime(t *testing.T) {
+	w, path := newWatcher(t, "app.json", `{"name":"a","db":{"pool":1}}`)
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+
+	// 内容变了但修改时间和大小都没变：轮询发现不了，Reload 可以
+	assert.NoError(t, os.WriteFile(path, []byte(`{"name":"b","db":{"pool":1}}`), 0o644))
+	assert.NoError(t, os.Chtimes(path, info.ModTime(), info.ModTime()))
+	changed, err := w.poll()
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, "a", w.Config().Name)
+
+	// 修改时间变了但内容没变：读取后按哈希判断为未变化
+	assert.NoError(t, os.WriteFile(path, []byte(`{"name":"a","db":{"pool":1}}`), 0o644))
+	assert.NoError(t, os.Chtimes(path, info.ModTime(), info.ModTime()))
+	w.Reload()
+	assert.NoError(t, os.Chtimes(path, info.ModTime().Add(time.Minute), info.ModTime().Add(time.Minute)))
+	changed, err = w.poll()
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	writeFile(t, path, `{"name":"c","db":{"pool":1}}`)
+	changed, err = w.poll()
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "c", w.Config().Name)
+
+	stats := w.Stats()
+	assert.Equal(t, 3, stats.Checks)
+	assert.Equal(t, 2, stats.Unchanged)
+
+	// 文件被删除：保留当前版本
+	assert.NoError(t, os.Remove(path))
+	_, err = w.poll()
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.Equal(t, "c", w.Config().Name)
+}
+
+// TestSubscribePath 测试按路径订阅，以及订阅者 panic 不影响其他订阅者
+func TestSubscribePath(t *testing.T) {
+	var reported []error
+	w, path := newWatcher(t, "app.json", `{"name":"svc","db":{"dsn":"pg://a","pool":1}}`,
+		WithErrorHandler(func(err error) { reported = append(reported, err) }))
+
+	var db, name int
+	w.SubscribePath("db", func(Event[testConfig]) { panic("订阅者崩溃") })
+	w.SubscribePath("db", func(Event[testConfig]) { db++ })
+	w.SubscribePath("name", func(Event[testConfig]) { name++ })
+
+	writeFile(t, path, `{"name":"svc","db":{"dsn":"pg://b","pool":1}}`)
+	w.Reload()
+	writeFile(t, path, `{"name":"svc2","db":{"dsn":"pg://b","pool":1}}`)
+	w.Reload()
+
+	assert.Equal(t, 1, db)
+	assert.Equal(t, 1, name)
+	assert.Len(t, reported, 1)
+	assert.ErrorContains(t, reported[0], "订阅者崩溃")
+}
+
+// TestBackgroundPolling 测试后台轮询发现修改，并发读取总是拿到完整的某个版本
+func TestBackgroundPolling(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.json")
+	writeFile(t, path, `{"name":"v1","db":{"dsn":"v1","pool":1}}`)
+	w, err := New[testConfig](path, WithInterval(5*time.Millisecond))
+	assert.NoError(t, err)
+	defer w.Close()
+
+	changed := make(chan string, 10)
+	w.Subscribe(func(e Event[testConfig]) { changed <- e.New.Config.Name })
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				cfg := w.Config()
+				assert.Equal(t, cfg.Name, cfg.DB.DSN, "不会读到一半新一半旧的配置")
+			}
+		}()
+	}
+
+	writeFile(t, path, `{"name":"v2","db":{"dsn":"v2","pool":1}}`)
+	select {
+	case name := <-changed:
+		assert.Equal(t, "v2", name)
+	case <-time.After(2 * time.Second):
+		t.Fatal("超时未收到变更通知")
+	}
+	close(stop)
+	wg.Wait()
+	assert.Equal(t, uint64(2), w.Current().Version)
+}
+
+// BenchmarkConfig 读取当前配置的开销
+func BenchmarkConfig(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "app.json")
+	os.WriteFile(path, []byte(`{"name":"svc","db":{"pool":1}}`), 0o644)
+	w, err := New[testConfig](path, WithInterval(time.Hour))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer w.Close()
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = w.Current().Config.DB.Pool
+		}
+	})
+}
@@ -0,0 +1,304 @@
+package consistent_hash
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testKeys 统计测试使用的键数
+const testKeys = 100000
+
+// newRing 创建包含 n 个节点 node-0 ... node-(n-1) 的环
+func newRing(t testing.TB, n int, opts ...Option) *Ring {
+	r := New(opts...)
+	for i := 0; i < n; i++ {
+		assert.NoError(t, r.AddNode(fmt.Sprintf("node-%d", i)))
+	}
+	return r
+}
+
+// assign 返回每个测试键的归属节点
+func assign(t *testing.T, r *Ring) []string {
+	owners := make([]string, testKeys)
+	for i := range owners {
+		node, err := r.Get(fmt.Sprintf("key-%d", i))
+		assert.NoError(t, err)
+		owners[i] = node
+	}
+	return owners
+}
+
+// counts 统计每个节点负责的键数
+func counts(owners []string) map[string]int {
+	c := map[string]int{}
+	for _, node := range owners {
+		c[node]++
+	}
+	return c
+}
+
+func TestEmptyRing(t *testing.T) {
+	r := New()
+	_, err := r.Get("key")
+	assert.ErrorIs(t, err, ErrEmptyRing)
+	_, err = r.GetN("key", 2)
+	assert.ErrorIs(t, err, ErrEmptyRing)
+	assert.Empty(t, r.Nodes())
+	assert.Empty(t, r.Ownership())
+}
+
+func TestNodeManagement(t *testing.T) {
+	r := New()
+	assert.NoError(t, r.AddNode("b"))
+	assert.NoError(t, r.AddWeightedNode("a", 2))
+	assert.ErrorIs(t, r.AddNode("a"), ErrNodeExists)
+	assert.ErrorIs(t, r.AddWeightedNode("c", 0), ErrInvalidWeight)
+	assert.Equal(t, []string{"a", "b"}, r.Nodes())
+	assert.Equal(t, 2, r.Len())
+
+	assert.NoError(t, r.RemoveNode("a"))
+	assert.ErrorIs(t, r.RemoveNode("a"), ErrNodeNotFound)
+	assert.Equal(t, []string{"b"}, r.Nodes())
+
+	// 只剩一个节点时所有键都归它
+	node, err := r.Get("anything")
+	assert.NoError(t, err)
+	assert.Equal(t, "b", node)
+	assert.InDelta(t, 1.0, r.Ownership()["b"], 1e-9)
+}
+
+func TestDeterministic(t *testing.T) {
+	// 节点的添加顺序不影响键的归属
+	a := New()
+	b := New()
+	for i := 0; i < 5; i++ {
+		a.AddNode(fmt.Sprintf("node-%d", i))
+		b.AddNode(fmt.Sprintf("node-%d", 4-i))
+	}
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		na, _ := a.Get(key)
+		nb, _ := b.Get(key)
+		assert.Equal(t, na, nb)
+	}
+}
+
+func TestDistribution(t *testing.T) {
+	const nodes = 10
+	r := newRing(t, nodes)
+	c := counts(assign(t, r))
+	assert.Len(t, c, nodes)
+
+	// 160 个虚拟节点时每个节点负责的比例标准差约为 1/sqrt(160) ≈ 8%，留出足够的余量
+	mean := float64(testKeys) / nodes
+	for node, n := range c {
+		assert.InDelta(t, mean, float64(n), 0.25*mean, "节点 %s 负责 %d 个键", node, n)
+	}
+
+	// 哈希空间的比例与实际键数一致
+	total := 0.0
+	for node, share := range r.Ownership() {
+		total += share
+		assert.InDelta(t, share, float64(c[node])/testKeys, 0.01, node)
+	}
+	assert.InDelta(t, 1.0, total, 1e-9)
+
+	// 虚拟节点越少，分布越不均匀
+	few := newRing(t, nodes, WithReplicas(1))
+	assert.Greater(t, spread(few.Ownership()), spread(r.Ownership()))
+}
+
+// spread 返回各节点负责比例的最大值与最小值之比
+func spread(shares map[string]float64) float64 {
+	lo, hi := math.Inf(1), 0.0
+	for _, s := range shares {
+		lo, hi = min(lo, s), max(hi, s)
+	}
+	return hi / lo
+}
+
+func TestWeightedDistribution(t *testing.T) {
+	r := newRing(t, 3)
+	assert.NoError(t, r.AddWeightedNode("big", 3))
+	c := counts(assign(t, r))
+
+	// big 的权重是其他节点的 3 倍，期望负责 3/6 的键
+	assert.InDelta(t, 0.5, float64(c["big"])/testKeys, 0.05)
+}
+
+func TestAddNodeMovesMinimalKeys(t *testing.T) {
+	const nodes = 10
+	r := newRing(t, nodes)
+	before := assign(t, r)
+
+	assert.NoError(t, r.AddNode("node-new"))
+	after := assign(t, r)
+
+	moved := 0
+	for i := range before {
+		if before[i] != after[i] {
+			moved++
+			// 移动的键只会移到新节点上，其他节点之间不会交换键
+			assert.Equal(t, "node-new", after[i])
+		}
+	}
+	// 理想情况下移动 1/(N+1) 的键
+	assert.InDelta(t, 1.0/(nodes+1), float64(moved)/testKeys, 0.03)
+}
+
+func TestRemoveNodeMovesOnlyItsKeys(t *testing.T) {
+	const nodes = 10
+	r := newRing(t, nodes)
+	before := assign(t, r)
+
+	assert.NoError(t, r.RemoveNode("node-3"))
+	after := assign(t, r)
+
+	moved := 0
+	for i := range before {
+		if before[i] == "node-3" {
+			assert.NotEqual(t, "node-3", after[i])
+			moved++
+		} else {
+			assert.Equal(t, before[i], after[i], "不属于被删除节点的键不应移动")
+		}
+	}
+	assert.InDelta(t, 1.0/nodes, float64(moved)/testKeys, 0.03)
+
+	// 再加回来，归属完全恢复
+	assert.NoError(t, r.AddNode("node-3"))
+	assert.Equal(t, before, assign(t, r))
+}
+
+func TestGetN(t *testing.T) {
+	r := newRing(t, 5)
+
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		replicas, err := r.GetN(key, 3)
+		assert.NoError(t, err)
+		assert.Len(t, replicas, 3)
+
+		// 第一个副本就是 Get 的结果，且副本互不相同
+		primary, _ := r.Get(key)
+		assert.Equal(t, primary, replicas[0])
+		assert.Len(t, counts(replicas), 3)
+	}
+
+	all, err := r.GetN("key", 10)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, r.Nodes(), all, "节点不足时返回所有节点")
+
+	none, err := r.GetN("key", 0)
+	assert.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func TestGetNStableOnRemove(t *testing.T) {
+	r := newRing(t, 6)
+	before := map[string][]string{}
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		before[key], _ = r.GetN(key, 3)
+	}
+
+	assert.NoError(t, r.RemoveNode("node-2"))
+	for key, old := range before {
+		now, _ := r.GetN(key, 3)
+		// 去掉被删除的节点后，原来的副本保持相对顺序，排在新补上的副本前面
+		var kept []string
+		for _, node := range old {
+			if node != "node-2" {
+				kept = append(kept, node)
+			}
+		}
+		assert.Equal(t, kept, now[:len(kept)], key)
+	}
+}
+
+func TestCustomHash(t *testing.T) {
+	// 虚拟节点名的哈希决定节点位置，键的哈希决定查找位置
+	positions := map[string]uint64{"a#0": 100, "b#0": 200}
+	r := New(WithReplicas(1), WithHash(func(s string) uint64 {
+		if p, ok := positions[s]; ok {
+			return p
+		}
+		var v uint64
+		fmt.Sscan(s, &v)
+		return v
+	}))
+	r.AddNode("a")
+	r.AddNode("b")
+
+	for key, want := range map[string]string{"50": "a", "100": "a", "150": "b", "250": "a"} {
+		node, err := r.Get(key)
+		assert.NoError(t, err)
+		assert.Equal(t, want, node, "键 %s", key)
+	}
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	r := newRing(t, 4)
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				_, err := r.GetN(fmt.Sprintf("key-%d", i), 2)
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		node := fmt.Sprintf("extra-%d", i)
+		assert.NoError(t, r.AddNode(node))
+		assert.NoError(t, r.RemoveNode(node))
+	}
+	wg.Wait()
+}
+
+func TestShardedStoreExample(t *testing.T) {
+	store := newShardedStore("a", "b", "c")
+	for i := 0; i < 1000; i++ {
+		store.put(fmt.Sprintf("user:%d", i), fmt.Sprint(i))
+	}
+	moved := store.addShard("d")
+	assert.Equal(t, len(store.shards["d"]), moved, "迁移的键都到了新分片")
+
+	moved = store.removeShard("b")
+	assert.Greater(t, moved, 0)
+	for i := 0; i < 1000; i++ {
+		v, ok := store.get(fmt.Sprintf("user:%d", i))
+		assert.True(t, ok)
+		assert.Equal(t, fmt.Sprint(i), v)
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	for _, nodes := range []int{10, 100} {
+		b.Run(fmt.Sprintf("Nodes%d", nodes), func(b *testing.B) {
+			r := newRing(b, nodes)
+			keys := make([]string, 1024)
+			for i := range keys {
+				keys[i] = fmt.Sprintf("key-%d", i)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				r.Get(keys[i%len(keys)])
+			}
+		})
+	}
+}
+
+func BenchmarkGetN(b *testing.B) {
+	r := newRing(b, 10)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.GetN("key", 3)
+	}
+}
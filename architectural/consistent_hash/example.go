@@ -0,0 +1,159 @@
+package consistent_hash
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/XiaoluCoding626/go-design-pattern/synchronization/semaphore"
+)
+
+// shardedStore 示例中的分片存储：键由哈希环分配到各个分片，增删分片时只迁移归属改变的键
+type shardedStore struct {
+	ring   *Ring
+	shards map[string]map[string]string
+}
+
+// newShardedStore 创建包含给定分片的存储
+func newShardedStore(nodes ...string) *shardedStore {
+	s := &shardedStore{ring: New(), shards: make(map[string]map[string]string)}
+	for _, node := range nodes {
+		s.ring.AddNode(node)
+		s.shards[node] = make(map[string]string)
+	}
+	return s
+}
+
+// put 写入键值
+func (s *shardedStore) put(key, value string) {
+	node, _ := s.ring.Get(key)
+	s.shards[node][key] = value
+}
+
+// get 读取键值
+func (s *shardedStore) get(key string) (string, bool) {
+	node, _ := s.ring.Get(key)
+	v, ok := s.shards[node][key]
+	return v, ok
+}
+
+// addShard 增加分片并迁移归属改变的键，返回迁移的键数
+func (s *shardedStore) addShard(node string) int {
+	s.ring.AddNode(node)
+	s.shards[node] = make(map[string]string)
+	return s.rebalance()
+}
+
+// removeShard 删除分片并把它的键迁移到新的归属，返回迁移的键数
+func (s *shardedStore) removeShard(node string) int {
+	s.ring.RemoveNode(node)
+	keys := s.shards[node]
+	delete(s.shards, node)
+	for k, v := range keys {
+		s.put(k, v)
+	}
+	return len(keys)
+}
+
+// rebalance 把不在归属分片上的键迁移过去
+func (s *shardedStore) rebalance() int {
+	moved := 0
+	for node, keys := range s.shards {
+		for k, v := range keys {
+			if owner, _ := s.ring.Get(k); owner != node {
+				delete(keys, k)
+				s.shards[owner][k] = v
+				moved++
+			}
+		}
+	}
+	return moved
+}
+
+// sizes 返回各个分片的键数
+func (s *shardedStore) sizes() string {
+	nodes := s.ring.Nodes()
+	out := ""
+	for _, node := range nodes {
+		out += fmt.Sprintf(" %s=%d", node, len(s.shards[node]))
+	}
+	return out
+}
+
+// RunExample 运行一致性哈希示例
+func RunExample() {
+	const keys = 10000
+	store := newShardedStore("cache-a", "cache-b", "cache-c")
+	for i := 0; i < keys; i++ {
+		store.put(fmt.Sprintf("user:%d", i), fmt.Sprint(i))
+	}
+	fmt.Println("== 3 个分片 ==")
+	fmt.Println("  各分片键数:" + store.sizes())
+
+	fmt.Println("== 增加分片 cache-d ==")
+	moved := store.addShard("cache-d")
+	fmt.Printf("  迁移 %d 个键（%.1f%%），理想值为 1/4\n", moved, 100*float64(moved)/keys)
+	fmt.Printf("  对比取模分片（hash %% N）：N 从 3 变为 4 时迁移 %.1f%% 的键\n", 100*modMoved(keys, 3, 4))
+	fmt.Println("  各分片键数:" + store.sizes())
+
+	fmt.Println("== 删除分片 cache-b ==")
+	moved = store.removeShard("cache-b")
+	fmt.Printf("  只迁移 cache-b 上的 %d 个键\n", moved)
+	v, ok := store.get("user:42")
+	fmt.Println("  读取 user:42:", v, ok)
+
+	fmt.Println("== 副本选择 ==")
+	replicas, _ := store.ring.GetN("user:42", 2)
+	fmt.Println("  user:42 的主副本和从副本:", replicas)
+
+	fmt.Println("== 按分片限流 ==")
+	// 键控信号量以分片为键：每个分片最多同时处理 2 个请求，热点分片不会拖累其他分片
+	limiter := semaphore.NewKeyed(2)
+	var (
+		mutex   sync.Mutex
+		running = map[string]int{}
+		peak    = map[string]int{}
+		wg      sync.WaitGroup
+	)
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			shard, _ := store.ring.Get(key)
+			limiter.Acquire(context.Background(), shard)
+			defer limiter.Release(shard)
+
+			mutex.Lock()
+			running[shard]++
+			peak[shard] = max(peak[shard], running[shard])
+			mutex.Unlock()
+			time.Sleep(time.Millisecond)
+			mutex.Lock()
+			running[shard]--
+			mutex.Unlock()
+		}(fmt.Sprintf("user:%d", i))
+	}
+	wg.Wait()
+	shards := make([]string, 0, len(peak))
+	for shard := range peak {
+		shards = append(shards, shard)
+	}
+	sort.Strings(shards)
+	for _, shard := range shards {
+		fmt.Printf("  %s 最大并发 %d\n", shard, peak[shard])
+	}
+}
+
+// modMoved 返回取模分片从 from 个分片变为 to 个时需要迁移的键的比例
+func modMoved(keys, from, to int) float64 {
+	moved := 0
+	for i := 0; i < keys; i++ {
+		h := DefaultHash(fmt.Sprintf("user:%d", i))
+		if h%uint64(from) != h%uint64(to) {
+			moved++
+		}
+	}
+	return float64(moved) / float64(keys)
+}
@@ -0,0 +1,231 @@
+package consistent_hash
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// 哈希环相关错误
+var (
+	ErrEmptyRing     = errors.New("哈希环中没有节点")
+	ErrNodeExists    = errors.New("节点已存在")
+	ErrNodeNotFound  = errors.New("节点不存在")
+	ErrInvalidWeight = errors.New("节点权重必须大于 0")
+)
+
+// HashFunc 把字符串映射到哈希环上的位置
+type HashFunc func(s string) uint64
+
+// DefaultHash 默认哈希函数：FNV-1a 之后再做一次 murmur3 的末尾混合
+// FNV 对只有末尾几个字符不同的字符串（如虚拟节点名 "node#1"、"node#2"）分布不够均匀，混合后才能把虚拟节点打散
+func DefaultHash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// Option 哈希环配置选项
+type Option func(*Ring)
+
+// WithReplicas 设置权重为 1 的节点在环上放置的虚拟节点数，默认 160
+// 虚拟节点越多，键的分布越均匀，查找和增删节点的开销越大
+func WithReplicas(n int) Option {
+	return func(r *Ring) {
+		if n > 0 {
+			r.replicas = n
+		}
+	}
+}
+
+// WithHash 设置哈希函数，默认 DefaultHash
+func WithHash(fn HashFunc) Option {
+	return func(r *Ring) {
+		if fn != nil {
+			r.hash = fn
+		}
+	}
+}
+
+// point 环上的一个虚拟节点
+type point struct {
+	hash uint64
+	node string
+}
+
+// Ring 带虚拟节点的一致性哈希环
+//
+// 每个节点按权重在环上放置若干虚拟节点，键顺时针找到的第一个虚拟节点所属的节点负责这个键。
+// 增加节点时只有落在新节点虚拟节点上的键会移动，而且都移动到新节点；
+// 删除节点时只有原来属于它的键会移动，其他键的归属不变。
+// 所有方法都可以并发调用。
+type Ring struct {
+	replicas int
+	hash     HashFunc
+
+	mutex  sync.RWMutex
+	points []point        // 按位置排序，位置相同时按节点名排序
+	nodes  map[string]int // 节点及其权重
+}
+
+// New 创建空的哈希环
+func New(opts ...Option) *Ring {
+	r := &Ring{
+		replicas: 160,
+		hash:     DefaultHash,
+		nodes:    make(map[string]int),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// AddNode 以权重 1 添加节点
+func (r *Ring) AddNode(node string) error {
+	return r.AddWeightedNode(node, 1)
+}
+
+// AddWeightedNode 添加节点，权重为 w 的节点放置 w 倍的虚拟节点，大致负责 w 倍的键
+func (r *Ring) AddWeightedNode(node string, weight int) error {
+	if weight <= 0 {
+		return fmt.Errorf("%w: %s 的权重为 %d", ErrInvalidWeight, node, weight)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.nodes[node]; ok {
+		return fmt.Errorf("%w: %s", ErrNodeExists, node)
+	}
+	r.nodes[node] = weight
+
+	points := r.points
+	for i := 0; i < r.replicas*weight; i++ {
+		points = append(points, point{hash: r.hash(node + "#" + strconv.Itoa(i)), node: node})
+	}
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].hash != points[j].hash {
+			return points[i].hash < points[j].hash
+		}
+		return points[i].node < points[j].node
+	})
+	r.points = points
+	return nil
+}
+
+// RemoveNode 删除节点及其所有虚拟节点
+func (r *Ring) RemoveNode(node string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.nodes[node]; !ok {
+		return fmt.Errorf("%w: %s", ErrNodeNotFound, node)
+	}
+	delete(r.nodes, node)
+
+	points := make([]point, 0, len(r.points))
+	for _, p := range r.points {
+		if p.node != node {
+			points = append(points, p)
+		}
+	}
+	r.points = points
+	return nil
+}
+
+// Get 返回负责 key 的节点
+func (r *Ring) Get(key string) (string, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if len(r.points) == 0 {
+		return "", ErrEmptyRing
+	}
+	return r.points[r.searchLocked(r.hash(key))].node, nil
+}
+
+// GetN 返回负责 key 的前 n 个不同节点，用于选择副本
+// 第一个节点与 Get 的结果相同，其余节点沿环顺时针依次选取；节点不足 n 个时返回所有节点
+// 某个节点被删除时，其余节点在结果中的相对顺序不变，只是后面的节点依次补上
+func (r *Ring) GetN(key string, n int) ([]string, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if len(r.points) == 0 {
+		return nil, ErrEmptyRing
+	}
+	n = min(n, len(r.nodes))
+	if n <= 0 {
+		return nil, nil
+	}
+
+	result := make([]string, 0, n)
+	start := r.searchLocked(r.hash(key))
+	for i := 0; i < len(r.points) && len(result) < n; i++ {
+		node := r.points[(start+i)%len(r.points)].node
+		if !slices.Contains(result, node) {
+			result = append(result, node)
+		}
+	}
+	return result, nil
+}
+
+// searchLocked 返回顺时针方向第一个位置不小于 hash 的虚拟节点下标，超过最大位置时回到开头，调用时必须持有锁
+func (r *Ring) searchLocked(hash uint64) int {
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i].hash >= hash })
+	if i == len(r.points) {
+		return 0
+	}
+	return i
+}
+
+// Nodes 返回环上的所有节点，按名称排序
+func (r *Ring) Nodes() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	nodes := make([]string, 0, len(r.nodes))
+	for node := range r.nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// Len 返回节点数
+func (r *Ring) Len() int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return len(r.nodes)
+}
+
+// Ownership 返回每个节点负责的哈希空间比例，所有节点之和为 1
+// 键足够多且哈希均匀时，节点负责的键的比例接近这个值
+func (r *Ring) Ownership() map[string]float64 {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	owned := make(map[string]float64, len(r.nodes))
+	if len(r.points) == 0 {
+		return owned
+	}
+
+	// 每个虚拟节点负责从前一个虚拟节点（不含）到它自己的一段弧
+	const space = float64(math.MaxUint64) + 1
+	prev := r.points[len(r.points)-1].hash
+	for _, p := range r.points {
+		owned[p.node] += float64(p.hash-prev) / space // 无符号减法，第一段自动绕过零点
+		prev = p.hash
+	}
+	if len(r.points) == 1 {
+		// 只有一个虚拟节点时它负责整圈，上面的减法得到 0
+		owned[r.points[0].node] = 1
+	}
+	return owned
+}
@@ -0,0 +1,91 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/XiaoluCoding626/go-design-pattern/behavioral/registry"
+)
+
+// 命令总线相关错误
+var (
+	ErrNoHandler         = errors.New("命令没有注册处理器")
+	ErrDuplicateHandler  = errors.New("命令已经注册了处理器")
+	ErrUnexpectedCommand = errors.New("处理器收到了不支持的命令")
+)
+
+// Command 命令：表达修改系统状态的意图，不返回查询结果
+type Command interface {
+	CommandName() string // 命令名称，用于查找处理器
+}
+
+// CommandHandler 命令处理器：校验命令并修改写模型
+type CommandHandler interface {
+	Handle(ctx context.Context, cmd Command) error
+}
+
+// CommandHandlerFunc 函数形式的命令处理器
+type CommandHandlerFunc func(ctx context.Context, cmd Command) error
+
+// Handle 调用函数本身
+func (f CommandHandlerFunc) Handle(ctx context.Context, cmd Command) error {
+	return f(ctx, cmd)
+}
+
+// HandlerFor 把只处理某一种命令的函数包装成命令处理器，收到其他类型的命令时返回 ErrUnexpectedCommand
+func HandlerFor[C Command](fn func(ctx context.Context, cmd C) error) CommandHandler {
+	return CommandHandlerFunc(func(ctx context.Context, cmd Command) error {
+		typed, ok := cmd.(C)
+		if !ok {
+			return fmt.Errorf("%w: %T", ErrUnexpectedCommand, cmd)
+		}
+		return fn(ctx, typed)
+	})
+}
+
+// CommandBus 命令总线：按命令名称在注册表中查找处理器并分发命令
+type CommandBus struct {
+	registry *registry.Registry
+}
+
+// NewCommandBus 创建命令总线，处理器注册到给定的注册表中，reg 为 nil 时使用独立的注册表
+func NewCommandBus(reg *registry.Registry) *CommandBus {
+	if reg == nil {
+		reg = registry.NewRegistry()
+	}
+	return &CommandBus{registry: reg}
+}
+
+// handlerKey 处理器在注册表中的键，加前缀避免与注册表中的其他服务冲突
+func handlerKey(name string) string {
+	return "command:" + name
+}
+
+// Register 为命令注册处理器，每种命令只能有一个处理器
+func (b *CommandBus) Register(name string, handler CommandHandler) error {
+	if handler == nil {
+		return fmt.Errorf("不能注册nil处理器")
+	}
+	if b.registry.Has(handlerKey(name)) {
+		return fmt.Errorf("%w: %s", ErrDuplicateHandler, name)
+	}
+	return b.registry.Register(handlerKey(name), handler)
+}
+
+// Dispatch 把命令分发给它的处理器
+func (b *CommandBus) Dispatch(ctx context.Context, cmd Command) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	service, err := b.registry.Get(handlerKey(cmd.CommandName()))
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrNoHandler, cmd.CommandName())
+	}
+	handler, ok := service.(CommandHandler)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNoHandler, cmd.CommandName())
+	}
+	return handler.Handle(ctx, cmd)
+}
@@ -0,0 +1,113 @@
+package cqrs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/XiaoluCoding626/go-design-pattern/behavioral/observer"
+	"github.com/XiaoluCoding626/go-design-pattern/behavioral/registry"
+)
+
+// ReadModel 读模型：通过订阅领域事件更新自身，并报告已应用的事件数
+type ReadModel interface {
+	observer.Observer
+	Version() uint64
+	WaitForVersion(ctx context.Context, version uint64) error
+}
+
+// System 把命令侧和查询侧组装在一起
+//
+// 命令经命令总线交给写模型，写模型把领域事件发布到事件总线（observer.StockMarket），
+// 读模型各自拥有独立的事件队列，异步应用事件，因此读模型与写模型之间是最终一致的。
+type System struct {
+	Commands *CommandBus
+	Exchange *Exchange
+	Prices   *PriceBoard
+	Movers   *MoversBoard
+
+	events *observer.StockMarket
+	queue  observer.QueueOptions
+}
+
+// NewSystem 创建 CQRS 系统，命令处理器注册到 reg 中，reg 为 nil 时使用独立的注册表
+func NewSystem(reg *registry.Registry) (*System, error) {
+	events := observer.NewStockMarket()
+	s := &System{
+		Commands: NewCommandBus(reg),
+		Exchange: NewExchange(events),
+		Prices:   NewPriceBoard("price-board"),
+		Movers:   NewMoversBoard("movers-board"),
+		events:   events,
+		queue:    observer.QueueOptions{Capacity: 64, Policy: observer.Block},
+	}
+	if err := s.Exchange.RegisterHandlers(s.Commands); err != nil {
+		return nil, err
+	}
+	s.Subscribe(s.Prices)
+	s.Subscribe(s.Movers)
+	return s, nil
+}
+
+// Subscribe 让读模型订阅领域事件
+// 读模型拥有独立的队列，队列满时阻塞发布方而不是丢弃事件，因为丢失事件的读模型永远无法追上写模型
+func (s *System) Subscribe(model observer.Observer) {
+	s.events.RegisterQueued(model, s.queue)
+}
+
+// Dispatch 发送命令
+func (s *System) Dispatch(ctx context.Context, cmd Command) error {
+	return s.Commands.Dispatch(ctx, cmd)
+}
+
+// Sync 等待读模型追上写模型当前的版本
+func (s *System) Sync(ctx context.Context, models ...ReadModel) error {
+	version := s.Exchange.Version()
+	for _, model := range models {
+		if err := model.WaitForVersion(ctx, version); err != nil {
+			return fmt.Errorf("读模型 %s 停在版本 %d，写模型版本 %d: %w", model.GetID(), model.Version(), version, err)
+		}
+	}
+	return nil
+}
+
+// Close 等待已发布的事件投递完成并停止事件队列
+func (s *System) Close() {
+	s.events.Close()
+}
+
+// RunExample 运行 CQRS 模式示例
+func RunExample() {
+	system, err := NewSystem(nil)
+	if err != nil {
+		fmt.Printf("初始化失败: %v\n", err)
+		return
+	}
+	defer system.Close()
+
+	ctx := context.Background()
+	commands := []Command{
+		ListStock{Symbol: "AAPL", Price: 180},
+		ListStock{Symbol: "TSLA", Price: 250},
+		ChangePrice{Symbol: "AAPL", Price: 198, Reason: "财报超预期"},
+		ChangePrice{Symbol: "TSLA", Price: 225, Reason: "交付量下滑"},
+		ChangePrice{Symbol: "MSFT", Price: 420, Reason: "未上市"},
+	}
+	for _, cmd := range commands {
+		if err := system.Dispatch(ctx, cmd); err != nil {
+			fmt.Printf("命令 %s 被拒绝: %v\n", cmd.CommandName(), err)
+		}
+	}
+
+	// 读模型异步更新，查询前等待它们追上写模型
+	if err := system.Sync(ctx, system.Prices, system.Movers); err != nil {
+		fmt.Printf("同步失败: %v\n", err)
+		return
+	}
+	for _, symbol := range system.Prices.Symbols() {
+		quote, _ := system.Prices.Quote(symbol)
+		fmt.Printf("%s: %.2f（%s）\n", quote.Symbol, quote.Price, quote.Reason)
+	}
+	for _, mover := range system.Movers.TopGainers(1) {
+		fmt.Printf("涨幅最大: %s %+.2f%%\n", mover.Symbol, mover.ChangePercent)
+	}
+}
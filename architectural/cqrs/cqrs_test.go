@@ -0,0 +1,165 @@
+package cqrs
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/XiaoluCoding626/go-design-pattern/behavioral/observer"
+	"github.com/XiaoluCoding626/go-design-pattern/behavioral/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+// gatedBoard 在 gate 关闭之前不应用事件的行情看板，用来观察读模型落后于写模型的状态
+type gatedBoard struct {
+	*PriceBoard
+	gate chan struct{}
+}
+
+func (g *gatedBoard) Update(event observer.StockEvent, message string) {
+	<-g.gate
+	g.PriceBoard.Update(event, message)
+}
+
+func newTestSystem(t *testing.T) *System {
+	t.Helper()
+	system, err := NewSystem(nil)
+	assert.NoError(t, err)
+	t.Cleanup(system.Close)
+	return system
+}
+
+func syncContext(t *testing.T) context.Context {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+// TestCommandBus 测试命令处理器通过注册表查找
+func TestCommandBus(t *testing.T) {
+	reg := registry.NewRegistry()
+	bus := NewCommandBus(reg)
+	ctx := context.Background()
+
+	var handled []string
+	assert.NoError(t, bus.Register("ListStock", HandlerFor(func(_ context.Context, cmd ListStock) error {
+		handled = append(handled, cmd.Symbol)
+		return nil
+	})))
+	assert.True(t, reg.Has("command:ListStock"), "处理器保存在注册表中")
+	assert.ErrorIs(t, bus.Register("ListStock", CommandHandlerFunc(func(context.Context, Command) error { return nil })), ErrDuplicateHandler)
+
+	assert.NoError(t, bus.Dispatch(ctx, ListStock{Symbol: "AAPL", Price: 1}))
+	assert.Equal(t, []string{"AAPL"}, handled)
+	assert.ErrorIs(t, bus.Dispatch(ctx, ChangePrice{Symbol: "AAPL", Price: 2}), ErrNoHandler)
+
+	// 处理器收到了错误类型的命令
+	assert.NoError(t, bus.Register("ChangePrice", HandlerFor(func(context.Context, ListStock) error { return nil })))
+	assert.ErrorIs(t, bus.Dispatch(ctx, ChangePrice{Symbol: "AAPL", Price: 2}), ErrUnexpectedCommand)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, bus.Dispatch(cancelled, ListStock{Symbol: "MSFT", Price: 1}), context.Canceled)
+	assert.Len(t, handled, 1)
+}
+
+// TestEndToEnd 测试命令经写模型发布事件，读模型最终反映所有变化
+func TestEndToEnd(t *testing.T) {
+	system := newTestSystem(t)
+	ctx := syncContext(t)
+
+	assert.NoError(t, system.Dispatch(ctx, ListStock{Symbol: "AAPL", Price: 100}))
+	assert.NoError(t, system.Dispatch(ctx, ListStock{Symbol: "TSLA", Price: 200}))
+	assert.NoError(t, system.Dispatch(ctx, ListStock{Symbol: "MSFT", Price: 300}))
+	assert.NoError(t, system.Dispatch(ctx, ChangePrice{Symbol: "AAPL", Price: 120, Reason: "新品发布"}))
+	assert.NoError(t, system.Dispatch(ctx, ChangePrice{Symbol: "TSLA", Price: 150, Reason: "召回"}))
+
+	// 被拒绝的命令不产生事件
+	assert.ErrorIs(t, system.Dispatch(ctx, ListStock{Symbol: "AAPL", Price: 1}), ErrAlreadyListed)
+	assert.ErrorIs(t, system.Dispatch(ctx, ChangePrice{Symbol: "NVDA", Price: 1}), ErrNotListed)
+	assert.ErrorIs(t, system.Dispatch(ctx, ChangePrice{Symbol: "AAPL", Price: -1}), ErrInvalidPrice)
+	assert.ErrorIs(t, system.Dispatch(ctx, ListStock{Price: 1}), ErrInvalidSymbol)
+	assert.Equal(t, uint64(5), system.Exchange.Version())
+
+	assert.NoError(t, system.Sync(ctx, system.Prices, system.Movers))
+	assert.Equal(t, []string{"AAPL", "MSFT", "TSLA"}, system.Prices.Symbols())
+	quote, ok := system.Prices.Quote("AAPL")
+	assert.True(t, ok)
+	assert.Equal(t, 120.0, quote.Price)
+	assert.InDelta(t, 20.0, quote.ChangePercent, 1e-9)
+	assert.Equal(t, "新品发布", quote.Reason)
+
+	gainers := system.Movers.TopGainers(2)
+	if assert.Len(t, gainers, 2) {
+		assert.Equal(t, "AAPL", gainers[0].Symbol)
+		assert.Equal(t, "MSFT", gainers[1].Symbol)
+	}
+	losers := system.Movers.TopLosers(1)
+	if assert.Len(t, losers, 1) {
+		assert.Equal(t, Mover{Symbol: "TSLA", ListPrice: 200, Price: 150, ChangePercent: -25}, losers[0])
+	}
+}
+
+// TestEventualConsistency 测试读模型可以落后于写模型，追上之后与写模型一致
+func TestEventualConsistency(t *testing.T) {
+	system := newTestSystem(t)
+	ctx := syncContext(t)
+
+	lagging := &gatedBoard{PriceBoard: NewPriceBoard("lagging-board"), gate: make(chan struct{})}
+	system.Subscribe(lagging)
+
+	assert.NoError(t, system.Dispatch(ctx, ListStock{Symbol: "AAPL", Price: 100}))
+	assert.NoError(t, system.Dispatch(ctx, ChangePrice{Symbol: "AAPL", Price: 110, Reason: "上调"}))
+
+	// 其他读模型不受慢读模型影响
+	assert.NoError(t, system.Sync(ctx, system.Prices))
+
+	// 写模型已经接受命令，但慢读模型还看不到
+	_, ok := lagging.Quote("AAPL")
+	assert.False(t, ok, "读模型尚未应用事件")
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, system.Sync(short, lagging), context.DeadlineExceeded)
+
+	close(lagging.gate)
+	assert.NoError(t, system.Sync(ctx, lagging))
+	quote, ok := lagging.Quote("AAPL")
+	assert.True(t, ok)
+	assert.Equal(t, 110.0, quote.Price)
+	assert.Equal(t, system.Exchange.Version(), lagging.Version())
+}
+
+// TestConcurrentCommands 测试并发命令下读模型按写模型的顺序应用事件
+func TestConcurrentCommands(t *testing.T) {
+	system := newTestSystem(t)
+	ctx := syncContext(t)
+
+	symbols := []string{"A", "B", "C", "D"}
+	for _, symbol := range symbols {
+		assert.NoError(t, system.Dispatch(ctx, ListStock{Symbol: symbol, Price: 100}))
+	}
+
+	var wg sync.WaitGroup
+	for _, symbol := range symbols {
+		wg.Add(1)
+		go func(symbol string) {
+			defer wg.Done()
+			for price := 101; price <= 150; price++ {
+				assert.NoError(t, system.Dispatch(ctx, ChangePrice{Symbol: symbol, Price: float64(price)}))
+			}
+		}(symbol)
+	}
+	wg.Wait()
+
+	assert.NoError(t, system.Sync(ctx, system.Prices, system.Movers))
+	assert.Equal(t, uint64(4+4*50), system.Prices.Version())
+	for _, symbol := range symbols {
+		quote, _ := system.Prices.Quote(symbol)
+		assert.Equal(t, 150.0, quote.Price, "读模型的最终状态与写模型一致")
+	}
+	for _, mover := range system.Movers.TopGainers(4) {
+		assert.InDelta(t, 50.0, mover.ChangePercent, 1e-9)
+	}
+}
@@ -0,0 +1,190 @@
+package cqrs
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/XiaoluCoding626/go-design-pattern/behavioral/observer"
+)
+
+// projection 读模型的公共部分：记录已应用的事件数，并允许调用者等待读模型追上写模型
+type projection struct {
+	mutex   sync.Mutex
+	version uint64
+	changed chan struct{} // 版本变化时关闭，没有等待者时为 nil
+}
+
+// advanceLocked 应用一个事件后调用，唤醒所有等待者，调用者需持有锁
+func (p *projection) advanceLocked() {
+	p.version++
+	if p.changed != nil {
+		close(p.changed)
+		p.changed = nil
+	}
+}
+
+// Version 返回读模型已应用的事件数
+func (p *projection) Version() uint64 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.version
+}
+
+// WaitForVersion 等待读模型应用到指定版本，用于在命令之后"读到自己的写入"
+func (p *projection) WaitForVersion(ctx context.Context, version uint64) error {
+	for {
+		p.mutex.Lock()
+		if p.version >= version {
+			p.mutex.Unlock()
+			return nil
+		}
+		if p.changed == nil {
+			p.changed = make(chan struct{})
+		}
+		changed := p.changed
+		p.mutex.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Quote 报价查询结果
+type Quote struct {
+	Symbol        string
+	Price         float64
+	ChangePercent float64 // 相对上一次价格的涨跌幅
+	Reason        string  // 最近一次变动的原因
+	UpdatedAt     time.Time
+}
+
+// PriceBoard 行情看板读模型：按股票代码查询最新报价
+type PriceBoard struct {
+	projection
+	id     string
+	quotes map[string]Quote
+}
+
+// NewPriceBoard 创建行情看板
+func NewPriceBoard(id string) *PriceBoard {
+	return &PriceBoard{id: id, quotes: make(map[string]Quote)}
+}
+
+// Update 应用领域事件
+func (b *PriceBoard) Update(event observer.StockEvent, message string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.quotes[event.Symbol] = Quote{
+		Symbol:        event.Symbol,
+		Price:         event.Price,
+		ChangePercent: event.ChangePercent(),
+		Reason:        message,
+		UpdatedAt:     event.Timestamp,
+	}
+	b.advanceLocked()
+}
+
+// GetID 返回读模型标识
+func (b *PriceBoard) GetID() string {
+	return b.id
+}
+
+// Quote 查询股票的最新报价
+func (b *PriceBoard) Quote(symbol string) (Quote, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	quote, ok := b.quotes[symbol]
+	return quote, ok
+}
+
+// Symbols 返回所有已上市的股票代码，按字典序排列
+func (b *PriceBoard) Symbols() []string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	symbols := make([]string, 0, len(b.quotes))
+	for symbol := range b.quotes {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// Mover 涨跌幅排行的一项
+type Mover struct {
+	Symbol        string
+	ListPrice     float64 // 发行价
+	Price         float64 // 最新价格
+	ChangePercent float64 // 相对发行价的涨跌幅
+}
+
+// MoversBoard 涨跌幅排行读模型：与行情看板订阅同样的事件，但按另一种查询需求组织数据
+type MoversBoard struct {
+	projection
+	id     string
+	movers map[string]*Mover
+}
+
+// NewMoversBoard 创建涨跌幅排行
+func NewMoversBoard(id string) *MoversBoard {
+	return &MoversBoard{id: id, movers: make(map[string]*Mover)}
+}
+
+// Update 应用领域事件，第一次出现的股票以当前价格作为发行价
+func (m *MoversBoard) Update(event observer.StockEvent, _ string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	mover, ok := m.movers[event.Symbol]
+	if !ok {
+		mover = &Mover{Symbol: event.Symbol, ListPrice: event.Price}
+		m.movers[event.Symbol] = mover
+	}
+	mover.Price = event.Price
+	mover.ChangePercent = (mover.Price - mover.ListPrice) / mover.ListPrice * 100
+	m.advanceLocked()
+}
+
+// GetID 返回读模型标识
+func (m *MoversBoard) GetID() string {
+	return m.id
+}
+
+// TopGainers 返回涨幅最大的 n 只股票
+func (m *MoversBoard) TopGainers(n int) []Mover {
+	return m.ranked(n, func(a, b Mover) bool { return a.ChangePercent > b.ChangePercent })
+}
+
+// TopLosers 返回跌幅最大的 n 只股票
+func (m *MoversBoard) TopLosers(n int) []Mover {
+	return m.ranked(n, func(a, b Mover) bool { return a.ChangePercent < b.ChangePercent })
+}
+
+// ranked 按 less 排序后返回前 n 项，涨跌幅相同时按股票代码排序
+func (m *MoversBoard) ranked(n int, less func(a, b Mover) bool) []Mover {
+	m.mutex.Lock()
+	movers := make([]Mover, 0, len(m.movers))
+	for _, mover := range m.movers {
+		movers = append(movers, *mover)
+	}
+	m.mutex.Unlock()
+
+	sort.Slice(movers, func(i, j int) bool {
+		if movers[i].ChangePercent != movers[j].ChangePercent {
+			return less(movers[i], movers[j])
+		}
+		return movers[i].Symbol < movers[j].Symbol
+	})
+	if n < len(movers) {
+		movers = movers[:n]
+	}
+	return movers
+}
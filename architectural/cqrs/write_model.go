@@ -0,0 +1,121 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/XiaoluCoding626/go-design-pattern/behavioral/observer"
+)
+
+// 写模型的业务规则错误
+var (
+	ErrInvalidSymbol = errors.New("股票代码不能为空")
+	ErrInvalidPrice  = errors.New("价格必须大于0")
+	ErrAlreadyListed = errors.New("股票已经上市")
+	ErrNotListed     = errors.New("股票尚未上市")
+)
+
+// ListStock 股票上市命令
+type ListStock struct {
+	Symbol string
+	Price  float64 // 发行价
+}
+
+// CommandName 返回命令名称
+func (ListStock) CommandName() string { return "ListStock" }
+
+// ChangePrice 调整股票价格命令
+type ChangePrice struct {
+	Symbol string
+	Price  float64
+	Reason string // 调价原因，随领域事件一起发布
+}
+
+// CommandName 返回命令名称
+func (ChangePrice) CommandName() string { return "ChangePrice" }
+
+// Exchange 交易所写模型：只负责校验命令、维护业务规则所需的最少状态，并发布领域事件
+// 写模型不提供任何查询，查询由订阅领域事件的读模型负责
+type Exchange struct {
+	mutex   sync.Mutex
+	listed  map[string]float64    // 已上市股票的当前价格
+	version uint64                // 已发布的领域事件数
+	events  *observer.StockMarket // 领域事件总线
+}
+
+// NewExchange 创建交易所写模型，领域事件发布到 events
+func NewExchange(events *observer.StockMarket) *Exchange {
+	return &Exchange{
+		listed: make(map[string]float64),
+		events: events,
+	}
+}
+
+// RegisterHandlers 在命令总线上注册交易所能够处理的命令
+func (e *Exchange) RegisterHandlers(bus *CommandBus) error {
+	return errors.Join(
+		bus.Register(ListStock{}.CommandName(), HandlerFor(e.list)),
+		bus.Register(ChangePrice{}.CommandName(), HandlerFor(e.changePrice)),
+	)
+}
+
+// list 处理上市命令
+func (e *Exchange) list(_ context.Context, cmd ListStock) error {
+	if err := validate(cmd.Symbol, cmd.Price); err != nil {
+		return err
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if _, exists := e.listed[cmd.Symbol]; exists {
+		return fmt.Errorf("%w: %s", ErrAlreadyListed, cmd.Symbol)
+	}
+	e.listed[cmd.Symbol] = cmd.Price
+	e.publishLocked(cmd.Symbol, cmd.Price, fmt.Sprintf("%s 上市", cmd.Symbol))
+	return nil
+}
+
+// changePrice 处理调价命令
+func (e *Exchange) changePrice(_ context.Context, cmd ChangePrice) error {
+	if err := validate(cmd.Symbol, cmd.Price); err != nil {
+		return err
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if _, exists := e.listed[cmd.Symbol]; !exists {
+		return fmt.Errorf("%w: %s", ErrNotListed, cmd.Symbol)
+	}
+	e.listed[cmd.Symbol] = cmd.Price
+	e.publishLocked(cmd.Symbol, cmd.Price, cmd.Reason)
+	return nil
+}
+
+// publishLocked 发布领域事件，调用者需持有锁，保证事件按版本顺序进入事件总线
+func (e *Exchange) publishLocked(symbol string, price float64, message string) {
+	e.version++
+	e.events.UpdateStockPrice(symbol, price, message, 0)
+}
+
+// Version 返回已发布的领域事件数，读模型追上这个版本后即与写模型一致
+func (e *Exchange) Version() uint64 {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return e.version
+}
+
+// validate 校验命令的公共字段
+func validate(symbol string, price float64) error {
+	if symbol == "" {
+		return ErrInvalidSymbol
+	}
+	if price <= 0 {
+		return fmt.Errorf("%w: %s %.2f", ErrInvalidPrice, symbol, price)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package gateway
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// RunExample 运行 API 网关示例：路由转发、限流、熔断和请求日志
+func RunExample() {
+	users := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "用户服务收到 %s", r.URL.Path)
+	}))
+	defer users.Close()
+
+	// 订单服务前 3 次请求返回 500，之后恢复
+	var calls atomic.Int64
+	orders := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 3 {
+			http.Error(w, "数据库连接失败", http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, "订单列表")
+	}))
+	defer orders.Close()
+
+	gw, err := New(
+		WithLogger(func(e LogEntry) {
+			fmt.Printf("  [日志] %-6s %s %-14s -> %d\n", e.Route, e.Method, e.Path, e.Status)
+		}),
+		WithTimeout(time.Second),
+		WithRoute("users", "/api/users", users.URL, WithStripPrefix(), WithRateLimit(1, 2)),
+		WithRoute("orders", "/api/orders", orders.URL, WithCircuitBreaker(3, 50*time.Millisecond)),
+	)
+	if err != nil {
+		fmt.Println("创建网关失败:", err)
+		return
+	}
+	front := httptest.NewServer(gw)
+	defer front.Close()
+
+	get := func(path string) {
+		resp, err := http.Get(front.URL + path)
+		if err != nil {
+			fmt.Println("请求失败:", err)
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		fmt.Printf("GET %s: %d %s\n", path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	fmt.Println("== 路由与限流（突发 2 个）==")
+	for i := 1; i <= 3; i++ {
+		get(fmt.Sprintf("/api/users/%d", i))
+	}
+
+	fmt.Println("== 熔断（连续 3 次失败后打开）==")
+	for i := 0; i < 4; i++ {
+		get("/api/orders")
+	}
+	time.Sleep(60 * time.Millisecond)
+	fmt.Println("冷却结束，试探请求:")
+	get("/api/orders")
+
+	fmt.Println("== 没有匹配的路由 ==")
+	get("/api/payments")
+
+	for _, s := range gw.Routes() {
+		fmt.Printf("%s: 请求 %d，限流 %d，熔断拒绝 %d，失败 %d，熔断器 %q\n",
+			s.Name, s.Requests, s.Limited, s.Rejected, s.Failures, s.Breaker)
+	}
+}
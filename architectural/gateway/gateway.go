@@ -0,0 +1,436 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/XiaoluCoding626/go-design-pattern/behavioral/registry"
+	"github.com/XiaoluCoding626/go-design-pattern/structural/proxy"
+)
+
+// 网关相关错误
+var (
+	ErrInvalidRoute   = errors.New("无效的路由")
+	ErrDuplicateRoute = errors.New("路由已存在")
+)
+
+// Option 网关配置选项
+type Option func(*config)
+
+// config 网关配置
+type config struct {
+	timeout     time.Duration
+	middlewares []Middleware
+	routes      []routeSpec
+	transport   http.RoundTripper
+	registry    *registry.Registry
+}
+
+// routeSpec 通过选项声明的路由，在 New 中注册
+type routeSpec struct {
+	name, prefix, backend string
+	opts                  []RouteOption
+}
+
+// WithTimeout 转发到后端的默认超时时间，超时返回 504，默认 10 秒
+func WithTimeout(d time.Duration) Option {
+	return func(c *config) {
+		if d > 0 {
+			c.timeout = d
+		}
+	}
+}
+
+// WithMiddleware 添加作用于所有请求的中间件，包括没有匹配到路由的请求，按添加顺序由外到内
+func WithMiddleware(mws ...Middleware) Option {
+	return func(c *config) {
+		c.middlewares = append(c.middlewares, mws...)
+	}
+}
+
+// WithRegistry 把路由登记到给定的注册表中，默认使用独立的注册表
+// 路由的键为 "route:" 加路由名称，不会与注册表中的其他服务冲突
+func WithRegistry(reg *registry.Registry) Option {
+	return func(c *config) {
+		c.registry = reg
+	}
+}
+
+// WithLogger 记录每个请求的日志，等同于 WithMiddleware(Logging(fn))
+func WithLogger(fn func(LogEntry)) Option {
+	return WithMiddleware(Logging(fn))
+}
+
+// WithTransport 转发请求使用的 http.RoundTripper，默认为 http.DefaultTransport
+func WithTransport(t http.RoundTripper) Option {
+	return func(c *config) {
+		c.transport = t
+	}
+}
+
+// WithRoute 注册一条路由，见 Gateway.Register
+func WithRoute(name, prefix, backend string, opts ...RouteOption) Option {
+	return func(c *config) {
+		c.routes = append(c.routes, routeSpec{name: name, prefix: prefix, backend: backend, opts: opts})
+	}
+}
+
+// RouteOption 路由配置选项
+type RouteOption func(*routeConfig)
+
+// routeConfig 路由配置
+type routeConfig struct {
+	limiter     *TokenBucket
+	breaker     *proxy.CircuitBreaker
+	stripPrefix bool
+	timeout     time.Duration
+	middlewares []Middleware
+}
+
+// WithRateLimit 路由的令牌桶限流：每秒补充 rate 个令牌，最多突发 burst 个请求
+func WithRateLimit(rate float64, burst int) RouteOption {
+	return func(c *routeConfig) {
+		c.limiter = NewTokenBucket(rate, burst)
+	}
+}
+
+// WithCircuitBreaker 路由的熔断器：后端连续 threshold 次失败后熔断 cooldown
+// 非正值时使用 proxy.DefaultFailureThreshold 和 proxy.DefaultCooldown
+func WithCircuitBreaker(threshold int, cooldown time.Duration) RouteOption {
+	return func(c *routeConfig) {
+		c.breaker = proxy.NewCircuitBreaker(threshold, cooldown)
+	}
+}
+
+// WithStripPrefix 转发前去掉路径中的路由前缀，例如 /users/42 转发为后端的 /42
+func WithStripPrefix() RouteOption {
+	return func(c *routeConfig) {
+		c.stripPrefix = true
+	}
+}
+
+// WithRouteTimeout 覆盖网关的默认超时时间
+func WithRouteTimeout(d time.Duration) RouteOption {
+	return func(c *routeConfig) {
+		if d > 0 {
+			c.timeout = d
+		}
+	}
+}
+
+// WithRouteMiddleware 添加只作用于该路由的中间件，在限流和熔断之后、转发之前执行
+func WithRouteMiddleware(mws ...Middleware) RouteOption {
+	return func(c *routeConfig) {
+		c.middlewares = append(c.middlewares, mws...)
+	}
+}
+
+// RouteStats 路由的信息和统计
+type RouteStats struct {
+	Name     string
+	Prefix   string
+	Backend  string
+	Breaker  string // 熔断器状态，没有配置熔断器时为空
+	Requests int64  // 匹配到该路由的请求数
+	Limited  int64  // 被限流拒绝的请求数（429）
+	Rejected int64  // 被熔断拒绝的请求数（503）
+	Failures int64  // 转发失败或后端返回 5xx 的请求数
+}
+
+// route 注册到网关的一条路由
+type route struct {
+	name    string
+	prefix  string
+	backend *url.URL
+	config  routeConfig
+	handler http.Handler
+
+	requests atomic.Int64
+	limited  atomic.Int64
+	rejected atomic.Int64
+	failures atomic.Int64
+}
+
+// Gateway API 网关：对外是一个统一的入口（外观），按路径前缀把请求转发到注册的后端服务
+//
+// 每条路由的处理器是一条职责链：限流 → 熔断 → 路由中间件 → 反向代理，
+// 任何一环都可以直接返回响应而不再向后传递；全局中间件（如日志）包在所有路由之外。
+// 路由登记在注册表中，可以在运行时注册和注销。
+type Gateway struct {
+	config   config
+	handler  http.Handler       // 全局中间件包装后的分发处理器
+	registry *registry.Registry // 路由的登记处，键为 registryKey(名称)
+
+	mutex      sync.RWMutex
+	sorted     []*route // 由注册表中的路由构建，按前缀长度降序，最长前缀优先匹配
+	generation uint64   // sorted 对应的注册表代数
+}
+
+// routeKeyPrefix 路由在注册表中的键前缀
+const routeKeyPrefix = "route:"
+
+// registryKey 路由在注册表中的键，加前缀避免与注册表中的其他服务冲突
+func registryKey(name string) string {
+	return routeKeyPrefix + name
+}
+
+// New 创建网关并注册通过 WithRoute 声明的路由
+func New(opts ...Option) (*Gateway, error) {
+	cfg := config{
+		timeout:   10 * time.Second,
+		transport: http.DefaultTransport,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if cfg.registry == nil {
+		cfg.registry = registry.NewRegistry()
+	}
+	g := &Gateway{
+		config:   cfg,
+		registry: cfg.registry,
+	}
+	g.rebuildLocked()
+	g.handler = Chain(http.HandlerFunc(g.dispatch), cfg.middlewares...)
+	for _, spec := range cfg.routes {
+		if err := g.Register(spec.name, spec.prefix, spec.backend, spec.opts...); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// Register 注册一条路由：路径等于 prefix 或以 prefix + "/" 开头的请求转发到 backend
+// 多条路由都能匹配时，前缀最长的优先
+func (g *Gateway) Register(name, prefix, backend string, opts ...RouteOption) error {
+	if name == "" {
+		return fmt.Errorf("%w: 名称为空", ErrInvalidRoute)
+	}
+	if !strings.HasPrefix(prefix, "/") {
+		return fmt.Errorf("%w: %s 的前缀 %q 必须以 / 开头", ErrInvalidRoute, name, prefix)
+	}
+	target, err := url.Parse(backend)
+	if err != nil || target.Scheme == "" || target.Host == "" {
+		return fmt.Errorf("%w: %s 的后端地址 %q 无效", ErrInvalidRoute, name, backend)
+	}
+
+	rc := routeConfig{timeout: g.config.timeout}
+	for _, opt := range opts {
+		opt(&rc)
+	}
+	rt := &route{
+		name:    name,
+		prefix:  strings.TrimSuffix(prefix, "/"),
+		backend: target,
+		config:  rc,
+	}
+	rt.handler = g.buildHandler(rt)
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.registry.Has(registryKey(name)) {
+		return fmt.Errorf("%w: %s", ErrDuplicateRoute, name)
+	}
+	g.rebuildLocked()
+	for _, other := range g.sorted {
+		if other.prefix == rt.prefix {
+			return fmt.Errorf("%w: 前缀 %q 已被 %s 使用", ErrDuplicateRoute, prefix, other.name)
+		}
+	}
+	if err := g.registry.Register(registryKey(name), rt); err != nil {
+		return fmt.Errorf("%w: %v", ErrDuplicateRoute, err)
+	}
+	g.rebuildLocked()
+	return nil
+}
+
+// Deregister 注销路由，返回路由是否存在；正在转发的请求不受影响
+func (g *Gateway) Deregister(name string) bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if !g.registry.Has(registryKey(name)) {
+		return false
+	}
+	g.registry.Unregister(registryKey(name))
+	g.rebuildLocked()
+	return true
+}
+
+// rebuildLocked 从注册表重建按前缀长度排序的路由表，调用方持有写锁
+// 先读取代数再读取路由：期间注册表又被修改时，记下的代数已经过期，下次查表会再次重建
+func (g *Gateway) rebuildLocked() {
+	generation := g.registry.Generation()
+	var sorted []*route
+	for _, key := range g.registry.Keys() {
+		if !strings.HasPrefix(key, routeKeyPrefix) {
+			continue
+		}
+		service, err := g.registry.Get(key)
+		if err != nil {
+			continue
+		}
+		if rt, ok := service.(*route); ok {
+			sorted = append(sorted, rt)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i].prefix) > len(sorted[j].prefix) })
+	g.sorted = sorted
+	g.generation = generation
+}
+
+// table 返回当前的路由表
+// 注册表的代数变化时（例如共享的注册表被直接修改）重新构建，注册表始终是路由的唯一来源
+func (g *Gateway) table() []*route {
+	generation := g.registry.Generation()
+	g.mutex.RLock()
+	if g.generation == generation {
+		sorted := g.sorted
+		g.mutex.RUnlock()
+		return sorted
+	}
+	g.mutex.RUnlock()
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.generation != generation {
+		g.rebuildLocked()
+	}
+	return g.sorted
+}
+
+// match 按最长前缀查找路由
+func (g *Gateway) match(path string) *route {
+	for _, rt := range g.table() {
+		if rt.prefix == "" || path == rt.prefix || strings.HasPrefix(path, rt.prefix+"/") {
+			return rt
+		}
+	}
+	return nil
+}
+
+// Routes 返回所有路由的信息和统计，按名称排序
+func (g *Gateway) Routes() []RouteStats {
+	routes := g.table()
+	stats := make([]RouteStats, 0, len(routes))
+	for _, rt := range routes {
+		s := RouteStats{
+			Name:     rt.name,
+			Prefix:   rt.prefix,
+			Backend:  rt.backend.String(),
+			Requests: rt.requests.Load(),
+			Limited:  rt.limited.Load(),
+			Rejected: rt.rejected.Load(),
+			Failures: rt.failures.Load(),
+		}
+		if rt.config.breaker != nil {
+			s.Breaker = rt.config.breaker.State().String()
+		}
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
+
+// ServeHTTP 实现 http.Handler：先匹配路由，再依次经过全局中间件和路由的职责链
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if rt := g.match(r.URL.Path); rt != nil {
+		r = withRoute(r, rt)
+	}
+	g.handler.ServeHTTP(w, r)
+}
+
+// dispatch 把请求交给匹配到的路由
+func (g *Gateway) dispatch(w http.ResponseWriter, r *http.Request) {
+	rt := routeOf(r)
+	if rt == nil {
+		http.Error(w, "没有匹配的路由", http.StatusNotFound)
+		return
+	}
+	rt.requests.Add(1)
+	rt.handler.ServeHTTP(w, r)
+}
+
+// buildHandler 组装路由的职责链：限流 → 熔断 → 路由中间件 → 反向代理
+func (g *Gateway) buildHandler(rt *route) http.Handler {
+	var chain []Middleware
+	if rt.config.limiter != nil {
+		chain = append(chain, rejections(&rt.limited, RateLimit(rt.config.limiter)))
+	}
+	if rt.config.breaker != nil {
+		chain = append(chain, rejections(&rt.rejected, CircuitBreak(rt.config.breaker)))
+	}
+	chain = append(chain, rt.config.middlewares...)
+	chain = append(chain, failures(&rt.failures))
+	return Chain(g.proxy(rt), chain...)
+}
+
+// rejections 统计被 mw 拦下、没有交给下一个处理器的请求
+func rejections(counter *atomic.Int64, mw Middleware) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			passed := false
+			mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				passed = true
+				next.ServeHTTP(w, r)
+			})).ServeHTTP(w, r)
+			if !passed {
+				counter.Add(1)
+			}
+		})
+	}
+}
+
+// failures 统计转发失败或后端返回 5xx 的请求
+func failures(counter *atomic.Int64) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+			if rec.Status() >= http.StatusInternalServerError {
+				counter.Add(1)
+			}
+		})
+	}
+}
+
+// proxy 创建转发到路由后端的反向代理
+func (g *Gateway) proxy(rt *route) http.Handler {
+	rp := &httputil.ReverseProxy{
+		Transport: g.config.transport,
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			if rt.config.stripPrefix {
+				pr.Out.URL.Path = strings.TrimPrefix(pr.In.URL.Path, rt.prefix)
+				pr.Out.URL.RawPath = ""
+				if pr.Out.URL.Path == "" {
+					pr.Out.URL.Path = "/"
+				}
+			}
+			pr.SetURL(rt.backend)
+			pr.SetXForwarded()
+			pr.Out.Header.Set("X-Gateway-Route", rt.name)
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			if errors.Is(err, context.DeadlineExceeded) {
+				http.Error(w, "后端响应超时", http.StatusGatewayTimeout)
+				return
+			}
+			http.Error(w, "后端不可用", http.StatusBadGateway)
+		},
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), rt.config.timeout)
+		defer cancel()
+		rp.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
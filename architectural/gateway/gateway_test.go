@@ -0,0 +1,393 @@
+package gateway
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/XiaoluCoding626/go-design-pattern/behavioral/registry"
+	"github.com/XiaoluCoding626/go-design-pattern/structural/proxy"
+	"github.com/stretchr/testify/assert"
+)
+
+// echoBackend 返回把收到的路径和部分请求头写回的后端
+func echoBackend(t *testing.T, name string) *httptest.Server {
+	t.Helper()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s route=%s xff=%s", name, r.URL.RequestURI(),
+			r.Header.Get("X-Gateway-Route"), r.Header.Get("X-Forwarded-For"))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// statusBackend 返回由 status 决定状态码的后端，并统计收到的请求数
+func statusBackend(t *testing.T, status *atomic.Int64, hits *atomic.Int64) *httptest.Server {
+	t.Helper()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(int(status.Load()))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// serve 启动网关的前端服务器
+func serve(t *testing.T, gw *Gateway) *httptest.Server {
+	t.Helper()
+	s := httptest.NewServer(gw)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// get 发送请求，返回状态码、响应体和响应头
+func get(t *testing.T, url string) (int, string, http.Header) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if !assert.NoError(t, err) {
+		return 0, "", nil
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, strings.TrimSpace(string(body)), resp.Header
+}
+
+// routeByName 返回网关中的路由，用于替换时钟
+func routeByName(g *Gateway, name string) *route {
+	for _, rt := range g.table() {
+		if rt.name == name {
+			return rt
+		}
+	}
+	return nil
+}
+
+// TestRouting 测试按最长前缀转发、去掉前缀、转发请求头和 404
+func TestRouting(t *testing.T) {
+	users := echoBackend(t, "users")
+	admin := echoBackend(t, "admin")
+	fallback := echoBackend(t, "web")
+
+	gw, err := New(
+		WithRoute("users", "/api/users", users.URL, WithStripPrefix()),
+		WithRoute("admin", "/api/users/admin/", admin.URL),
+	)
+	assert.NoError(t, err)
+	front := serve(t, gw)
+
+	status, body, _ := get(t, front.URL+"/api/users/42?x=1")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "users /42?x=1 route=users xff=127.0.0.1", body)
+
+	status, body, _ = get(t, front.URL+"/api/users")
+	assert.Equal(t, http.StatusOK, status)
+	assert.True(t, strings.HasPrefix(body, "users / "), body)
+
+	_, body, _ = get(t, front.URL+"/api/users/admin/audit")
+	assert.True(t, strings.HasPrefix(body, "admin /api/users/admin/audit route=admin"), "最长前缀优先，不去掉前缀: %s", body)
+
+	status, _, _ = get(t, front.URL+"/api/usersx")
+	assert.Equal(t, http.StatusNotFound, status, "前缀按路径段匹配")
+
+	// 运行时注册和注销
+	assert.NoError(t, gw.Register("web", "/", fallback.URL))
+	_, body, _ = get(t, front.URL+"/index.html")
+	assert.True(t, strings.HasPrefix(body, "web /index.html"), body)
+	assert.True(t, gw.Deregister("users"))
+	assert.False(t, gw.Deregister("users"))
+	_, body, _ = get(t, front.URL+"/api/users/42")
+	assert.True(t, strings.HasPrefix(body, "web /api/users/42"), "注销后落到兜底路由: %s", body)
+
+	stats := gw.Routes()
+	assert.Len(t, stats, 2)
+	assert.Equal(t, "admin", stats[0].Name)
+	assert.Equal(t, int64(1), stats[0].Requests)
+	assert.Equal(t, int64(2), stats[1].Requests)
+}
+
+// TestRegisterErrors 测试无效和重复的路由
+func TestRegisterErrors(t *testing.T) {
+	_, err := New(WithRoute("a", "api", "http://localhost:1"))
+	assert.ErrorIs(t, err, ErrInvalidRoute)
+	_, err = New(WithRoute("a", "/api", "localhost:1"))
+	assert.ErrorIs(t, err, ErrInvalidRoute)
+	_, err = New(WithRoute("", "/api", "http://localhost:1"))
+	assert.ErrorIs(t, err, ErrInvalidRoute)
+
+	gw, err := New(WithRoute("a", "/api", "http://localhost:1"))
+	assert.NoError(t, err)
+	assert.ErrorIs(t, gw.Register("a", "/other", "http://localhost:1"), ErrDuplicateRoute)
+	assert.ErrorIs(t, gw.Register("b", "/api/", "http://localhost:1"), ErrDuplicateRoute, "前缀相同")
+}
+
+// TestSharedRegistry 测试路由登记在共享的注册表中，直接从注册表注销的路由不再匹配
+func TestSharedRegistry(t *testing.T) {
+	reg := registry.NewRegistry()
+	assert.NoError(t, reg.Register("config", "其他服务"))
+	backend := echoBackend(t, "orders")
+	gw, err := New(WithRegistry(reg), WithRoute("orders", "/orders", backend.URL))
+	assert.NoError(t, err)
+	front := serve(t, gw)
+
+	assert.True(t, reg.Has("route:orders"))
+	assert.Len(t, gw.Routes(), 1, "注册表中的其他服务不是路由")
+	code, _, _ := get(t, front.URL+"/orders")
+	assert.Equal(t, http.StatusOK, code)
+
+	reg.Unregister("route:orders")
+	assert.Empty(t, gw.Routes())
+	code, _, _ = get(t, front.URL+"/orders")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	_, err = New(WithRegistry(reg), WithRoute("config", "/config", backend.URL))
+	assert.NoError(t, err, "路由的键带前缀，不与其他服务冲突")
+	_, err = New(WithRegistry(reg), WithRoute("config", "/config", backend.URL))
+	assert.ErrorIs(t, err, ErrDuplicateRoute, "共享注册表中已有同名路由")
+}
+
+// TestRateLimit 测试令牌用完时返回 429 和 Retry-After，令牌按时间补充
+func TestRateLimit(t *testing.T) {
+	var status, hits atomic.Int64
+	status.Store(http.StatusOK)
+	backend := statusBackend(t, &status, &hits)
+	gw, err := New(WithRoute("api", "/api", backend.URL, WithRateLimit(0.5, 2)))
+	assert.NoError(t, err)
+	front := serve(t, gw)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	bucket := routeByName(gw, "api").config.limiter
+	bucket.mutex.Lock()
+	bucket.now = func() time.Time { return now }
+	bucket.last = now
+	bucket.mutex.Unlock()
+
+	for i := 0; i < 2; i++ {
+		code, _, _ := get(t, front.URL+"/api")
+		assert.Equal(t, http.StatusOK, code)
+	}
+	code, _, header := get(t, front.URL+"/api")
+	assert.Equal(t, http.StatusTooManyRequests, code)
+	assert.Equal(t, "2", header.Get("Retry-After"), "每 2 秒补充一个令牌")
+	assert.Equal(t, int64(2), hits.Load(), "被限流的请求不转发")
+
+	now = now.Add(2 * time.Second)
+	code, _, _ = get(t, front.URL+"/api")
+	assert.Equal(t, http.StatusOK, code)
+
+	s := gw.Routes()[0]
+	assert.Equal(t, int64(4), s.Requests)
+	assert.Equal(t, int64(1), s.Limited)
+}
+
+// TestTokenBucket 测试令牌桶的补充不超过容量
+func TestTokenBucket(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := NewTokenBucket(10, 5)
+	b.now = func() time.Time { return now }
+	b.last = now
+
+	for i := 0; i < 5; i++ {
+		ok, _ := b.Take()
+		assert.True(t, ok)
+	}
+	ok, wait := b.Take()
+	assert.False(t, ok)
+	assert.Equal(t, 100*time.Millisecond, wait)
+
+	now = now.Add(250 * time.Millisecond)
+	assert.InDelta(t, 2.5, b.Tokens(), 1e-9)
+	now = now.Add(time.Hour)
+	assert.Equal(t, 5.0, b.Tokens(), "不超过容量")
+}
+
+// TestCircuitBreaker 测试后端连续失败后熔断，冷却后试探，恢复后关闭
+func TestCircuitBreaker(t *testing.T) {
+	var status, hits atomic.Int64
+	status.Store(http.StatusInternalServerError)
+	backend := statusBackend(t, &status, &hits)
+	gw, err := New(WithRoute("orders", "/orders", backend.URL, WithCircuitBreaker(3, time.Minute)))
+	assert.NoError(t, err)
+	front := serve(t, gw)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	breaker := routeByName(gw, "orders").config.breaker
+	breaker.SetClock(func() time.Time { return now })
+
+	for i := 0; i < 3; i++ {
+		code, _, _ := get(t, front.URL+"/orders")
+		assert.Equal(t, http.StatusInternalServerError, code)
+	}
+	assert.Equal(t, proxy.StateOpen, breaker.State())
+
+	code, _, header := get(t, front.URL+"/orders")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "60", header.Get("Retry-After"))
+	assert.Equal(t, int64(3), hits.Load(), "熔断期间不转发")
+
+	// 冷却结束，试探失败，重新打开
+	now = now.Add(time.Minute)
+	code, _, _ = get(t, front.URL+"/orders")
+	assert.Equal(t, http.StatusInternalServerError, code)
+	assert.Equal(t, proxy.StateOpen, breaker.State())
+
+	// 再次冷却，试探成功，关闭
+	now = now.Add(time.Minute)
+	status.Store(http.StatusOK)
+	code, _, _ = get(t, front.URL+"/orders")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, proxy.StateClosed, breaker.State())
+
+	s := gw.Routes()[0]
+	assert.Equal(t, RouteStats{
+		Name: "orders", Prefix: "/orders", Backend: backend.URL, Breaker: "closed",
+		Requests: 6, Rejected: 1, Failures: 4,
+	}, s)
+
+	// 4xx 是客户端的问题，不算后端失败
+	status.Store(http.StatusNotFound)
+	for i := 0; i < 5; i++ {
+		get(t, front.URL+"/orders")
+	}
+	assert.Equal(t, proxy.StateClosed, breaker.State())
+}
+
+// TestBackendErrors 测试后端不可达返回 502，超时返回 504
+func TestBackendErrors(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	gw, err := New(
+		WithTimeout(time.Minute),
+		WithRoute("down", "/down", down.URL),
+		WithRoute("slow", "/slow", slow.URL, WithRouteTimeout(20*time.Millisecond)),
+	)
+	assert.NoError(t, err)
+	front := serve(t, gw)
+
+	code, _, _ := get(t, front.URL+"/down")
+	assert.Equal(t, http.StatusBadGateway, code)
+
+	start := time.Now()
+	code, _, _ = get(t, front.URL+"/slow")
+	assert.Equal(t, http.StatusGatewayTimeout, code)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	for _, s := range gw.Routes() {
+		assert.Equal(t, int64(1), s.Failures, s.Name)
+	}
+}
+
+// TestMiddlewareChain 测试中间件的顺序、日志和中途返回
+func TestMiddlewareChain(t *testing.T) {
+	backend := echoBackend(t, "api")
+	var (
+		mutex   sync.Mutex
+		order   []string
+		entries []LogEntry
+	)
+	trace := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mutex.Lock()
+				order = append(order, name)
+				mutex.Unlock()
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	auth := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				http.Error(w, "未认证", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	gw, err := New(
+		WithLogger(func(e LogEntry) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			entries = append(entries, e)
+		}),
+		WithMiddleware(trace("global-1"), trace("global-2")),
+		WithRoute("api", "/api", backend.URL, WithRouteMiddleware(trace("route"), auth)),
+	)
+	assert.NoError(t, err)
+	front := serve(t, gw)
+
+	code, _, _ := get(t, front.URL+"/api/x")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	req, _ := http.NewRequest(http.MethodGet, front.URL+"/api/x", nil)
+	req.Header.Set("Authorization", "Bearer t")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	get(t, front.URL+"/missing")
+
+	assert.Equal(t, []string{"global-1", "global-2", "route", "global-1", "global-2", "route", "global-1", "global-2"}, order)
+	assert.Len(t, entries, 3)
+	assert.Equal(t, LogEntry{Route: "api", Method: "GET", Path: "/api/x", Status: 401}, LogEntry{
+		Route: entries[0].Route, Method: entries[0].Method, Path: entries[0].Path, Status: entries[0].Status,
+	})
+	assert.Equal(t, 200, entries[1].Status)
+	assert.Equal(t, "", entries[2].Route)
+	assert.Equal(t, 404, entries[2].Status)
+}
+
+// TestConcurrentRequests 测试并发请求与运行时注册
+func TestConcurrentRequests(t *testing.T) {
+	backend := echoBackend(t, "api")
+	gw, err := New(WithRoute("api", "/api", backend.URL, WithRateLimit(1000, 1000), WithCircuitBreaker(5, time.Second)))
+	assert.NoError(t, err)
+	front := serve(t, gw)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				code, _, _ := get(t, front.URL+"/api")
+				assert.Equal(t, http.StatusOK, code)
+			}
+			name := fmt.Sprint("r", i)
+			gw.Register(name, "/"+name, backend.URL)
+			gw.Deregister(name)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(100), gw.Routes()[0].Requests)
+}
+
+// BenchmarkGateway 测量经过网关的一次转发的开销
+func BenchmarkGateway(b *testing.B) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer backend.Close()
+	gw, _ := New(WithRoute("api", "/api", backend.URL, WithRateLimit(1e9, 1e9), WithCircuitBreaker(5, time.Second)))
+	req := httptest.NewRequest(http.MethodGet, "/api/x", nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		gw.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
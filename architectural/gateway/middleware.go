@@ -0,0 +1,149 @@
+package gateway
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/XiaoluCoding626/go-design-pattern/structural/proxy"
+)
+
+// Middleware 中间件：包装一个处理器，在请求前后加入自己的逻辑，或者直接返回响应而不调用下一个处理器
+// 多个中间件组成职责链，每一环决定是处理请求还是交给下一环
+type Middleware func(next http.Handler) http.Handler
+
+// Chain 用中间件包装处理器，第一个中间件在最外层，最先收到请求
+func Chain(h http.Handler, mws ...Middleware) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// statusRecorder 记录处理器写出的状态码
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Status 返回状态码，处理器什么也没写时为 200
+func (r *statusRecorder) Status() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
+
+// Unwrap 让 http.ResponseController 能找到底层的 ResponseWriter
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// routeKey 请求上下文中保存匹配到的路由的键
+type routeKey struct{}
+
+// withRoute 把匹配到的路由放入请求上下文
+func withRoute(r *http.Request, rt *route) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), routeKey{}, rt))
+}
+
+// routeOf 返回请求上下文中的路由
+func routeOf(r *http.Request) *route {
+	rt, _ := r.Context().Value(routeKey{}).(*route)
+	return rt
+}
+
+// RouteName 返回网关为请求匹配到的路由名称，没有匹配到时返回空字符串
+func RouteName(r *http.Request) string {
+	if rt := routeOf(r); rt != nil {
+		return rt.name
+	}
+	return ""
+}
+
+// LogEntry 一次请求的日志
+type LogEntry struct {
+	Route    string
+	Method   string
+	Path     string
+	Status   int
+	Duration time.Duration
+	Remote   string
+}
+
+// Logging 请求日志中间件，请求处理完成后调用 fn
+func Logging(fn func(LogEntry)) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+			fn(LogEntry{
+				Route:    RouteName(r),
+				Method:   r.Method,
+				Path:     r.URL.Path,
+				Status:   rec.Status(),
+				Duration: time.Since(start),
+				Remote:   r.RemoteAddr,
+			})
+		})
+	}
+}
+
+// RateLimit 限流中间件：令牌用完时返回 429，Retry-After 为下一个令牌补充到位的秒数
+func RateLimit(bucket *TokenBucket) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ok, wait := bucket.Take(); !ok {
+				reject(w, http.StatusTooManyRequests, "请求过于频繁", wait)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// CircuitBreak 熔断中间件：熔断器打开时返回 503，不再调用下一个处理器
+// 状态机复用 proxy 包的熔断器，与熔断代理的行为一致
+// 下一个处理器返回 5xx 记为失败，其余记为成功
+func CircuitBreak(breaker *proxy.CircuitBreaker) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ok, wait := breaker.Allow()
+			if !ok {
+				reject(w, http.StatusServiceUnavailable, "后端暂时不可用", wait)
+				return
+			}
+			rec := &statusRecorder{ResponseWriter: w}
+			// 处理器 panic 时同样记为失败，避免半开状态的试探请求一直占着名额
+			success := false
+			defer func() { breaker.Record(success) }()
+			next.ServeHTTP(rec, r)
+			success = rec.Status() < http.StatusInternalServerError
+		})
+	}
+}
+
+// reject 返回拒绝响应，wait 大于 0 时设置 Retry-After（向上取整到秒）
+func reject(w http.ResponseWriter, status int, msg string, wait time.Duration) {
+	if wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	}
+	http.Error(w, msg, status)
+}
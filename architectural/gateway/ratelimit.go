@@ -0,0 +1,71 @@
+package gateway
+
+import (
+	"sync"
+	"time"
+)
+
+// TokenBucket 令牌桶限流器
+// 桶中最多存放 burst 个令牌，以每秒 rate 个的速度补充；每个请求消耗一个令牌，没有令牌时拒绝。
+// 允许短时间内突发 burst 个请求，长期平均速率不超过 rate
+type TokenBucket struct {
+	mutex  sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	now func() time.Time // 时间来源，便于测试
+}
+
+// NewTokenBucket 创建令牌桶，初始时桶是满的
+// rate 和 burst 为非正值时分别按每秒 1 个令牌和容量 1 处理
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if rate <= 0 {
+		rate = 1
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+	b := &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+	b.last = b.now()
+	return b
+}
+
+// Take 尝试取出一个令牌
+// 成功时返回 true；失败时返回 false 和下一个令牌补充到位需要等待的时间
+func (b *TokenBucket) Take() (bool, time.Duration) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.refillLocked()
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	return false, wait
+}
+
+// Tokens 返回桶中当前的令牌数
+func (b *TokenBucket) Tokens() float64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.refillLocked()
+	return b.tokens
+}
+
+// refillLocked 按经过的时间补充令牌，调用方持有锁
+func (b *TokenBucket) refillLocked() {
+	now := b.now()
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+	}
+	b.last = now
+}
@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"github.com/XiaoluCoding626/go-design-pattern/concurrency/bounded_parallelism"
+	"github.com/XiaoluCoding626/go-design-pattern/creational/object_pool"
+	"github.com/XiaoluCoding626/go-design-pattern/synchronization/semaphore"
+)
+
+// 以下适配器把项目中已有组件的 Stats 转换为统一的样本，组件本身不需要依赖本包。
+// labels 用于区分同类组件的多个实例，例如 Labels{"pool": "db"}
+
+// ObjectPoolCollector 导出对象池的统计
+func ObjectPoolCollector(pool *object_pool.ObjectPool, labels Labels) Collector {
+	return CollectorFunc(func(emit func(Sample)) {
+		stats := pool.Stats()
+		active, idle, total := pool.Status()
+		emit(CounterSample("object_pool_created_total", labels, float64(stats.Created)))
+		emit(CounterSample("object_pool_acquired_total", labels, float64(stats.Acquired)))
+		emit(CounterSample("object_pool_released_total", labels, float64(stats.Released)))
+		emit(CounterSample("object_pool_destroyed_total", labels, float64(stats.Destroyed)))
+		emit(CounterSample("object_pool_waits_total", labels, float64(stats.Waits)))
+		emit(CounterSample("object_pool_timeouts_total", labels, float64(stats.Timeouts)))
+		emit(CounterSample("object_pool_wait_seconds_total", labels, stats.WaitTime.Seconds()))
+		emit(GaugeSample("object_pool_active", labels, float64(active)))
+		emit(GaugeSample("object_pool_idle", labels, float64(idle)))
+		emit(GaugeSample("object_pool_objects", labels, float64(total)))
+	})
+}
+
+// SemaphoreCollector 导出信号量的容量和使用情况
+func SemaphoreCollector(s semaphore.Semaphorer, labels Labels) Collector {
+	return CollectorFunc(func(emit func(Sample)) {
+		size, available := s.Size(), s.Available()
+		emit(GaugeSample("semaphore_size", labels, float64(size)))
+		emit(GaugeSample("semaphore_available", labels, float64(available)))
+		emit(GaugeSample("semaphore_in_use", labels, float64(size-available)))
+	})
+}
+
+// ExecutorCollector 导出有界执行器的任务统计和 panic 统计
+func ExecutorCollector[T any](e *bounded_parallelism.BoundedExecutor[T], labels Labels) Collector {
+	return CollectorFunc(func(emit func(Sample)) {
+		stats := e.Stats()
+		panics := e.PanicStats()
+		emit(CounterSample("executor_submitted_total", labels, float64(stats.Submitted)))
+		emit(CounterSample("executor_completed_total", labels, float64(stats.Completed)))
+		emit(CounterSample("executor_failed_total", labels, float64(stats.Failed)))
+		emit(CounterSample("executor_panics_total", labels, float64(panics.Panics)))
+		emit(CounterSample("executor_worker_restarts_total", labels, float64(panics.WorkerRestarts)))
+		emit(GaugeSample("executor_queued", labels, float64(stats.Queued)))
+		emit(GaugeSample("executor_running", labels, float64(stats.Running)))
+	})
+}
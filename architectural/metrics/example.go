@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/XiaoluCoding626/go-design-pattern/concurrency/bounded_parallelism"
+	"github.com/XiaoluCoding626/go-design-pattern/creational/object_pool"
+	"github.com/XiaoluCoding626/go-design-pattern/synchronization/semaphore"
+)
+
+// connection 示例中对象池管理的连接
+type connection struct {
+	id int
+}
+
+func (c *connection) Reset() error   { return nil }
+func (c *connection) Validate() bool { return true }
+func (c *connection) ID() int        { return c.id }
+
+// RunExample 运行指标注册表示例：直接埋点、已有组件的适配器，以及订阅快照
+func RunExample() {
+	registry := NewRegistry()
+
+	// 直接埋点：请求数和耗时
+	requests, _ := registry.Counter("http_requests_total", Labels{"route": "/orders", "code": "200"})
+	latency, _ := registry.Histogram("http_request_seconds", Labels{"route": "/orders"}, []float64{0.01, 0.05, 0.1, 0.5})
+	for _, d := range []float64{0.004, 0.02, 0.03, 0.07, 0.3} {
+		requests.Inc()
+		latency.Observe(d)
+	}
+
+	// 已有组件通过适配器导出统计
+	nextID := 0
+	config := object_pool.DefaultPoolConfig(func() (object_pool.Object, error) {
+		nextID++
+		return &connection{id: nextID}, nil
+	})
+	config.InitialSize = 2
+	pool, err := object_pool.NewObjectPool(config)
+	if err != nil {
+		fmt.Println("创建对象池失败:", err)
+		return
+	}
+	defer pool.Close()
+	conn, _ := pool.AcquireObject()
+
+	sem := semaphore.New(3)
+	sem.TryAcquire()
+
+	executor := bounded_parallelism.NewBoundedExecutor[int](2, 4)
+	executor.Submit(bounded_parallelism.Task[int]{ID: "job-1", Execute: func() (int, error) { return 1, nil }})
+	<-executor.Results()
+
+	registry.Register(ObjectPoolCollector(pool, Labels{"pool": "db"}))
+	registry.Register(SemaphoreCollector(sem, Labels{"name": "uploads"}))
+	registry.Register(ExecutorCollector(executor, Labels{"executor": "jobs"}))
+
+	// 观察者：每次发布快照时打印关心的几项
+	cancel := registry.Subscribe(func(s Snapshot) {
+		for _, sample := range s.Samples {
+			switch sample.Name {
+			case "http_requests_total", "object_pool_active", "semaphore_in_use", "executor_completed_total":
+				fmt.Printf("  %s%s = %v\n", sample.Name, sample.Labels, sample.Value)
+			case "http_request_seconds":
+				h := sample.Histogram
+				fmt.Printf("  %s%s: 次数 %d，平均 %.3fs，p90 约 %.3fs\n",
+					sample.Name, sample.Labels, h.Count, h.Mean(), h.Quantile(0.9))
+			}
+		}
+	})
+	defer cancel()
+
+	fmt.Println("== 第一次发布 ==")
+	registry.Publish()
+
+	pool.ReleaseObject(conn)
+	sem.Release()
+	executor.Shutdown()
+
+	fmt.Println("== 每 50ms 发布一次 ==")
+	ctx, stop := context.WithTimeout(context.Background(), 120*time.Millisecond)
+	defer stop()
+	registry.PublishEvery(ctx, 50*time.Millisecond)
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+)
+
+// expvarMutex 串行化发布：expvar.Get 和 expvar.Publish 之间不能插入另一次同名发布，否则 expvar 会 panic
+var expvarMutex sync.Mutex
+
+// MarshalJSON 导出桶；JSON 不支持无穷大，+Inf 桶的上界导出为字符串 "+Inf"
+func (b Bucket) MarshalJSON() ([]byte, error) {
+	var le any = b.UpperBound
+	if math.IsInf(b.UpperBound, 1) {
+		le = "+Inf"
+	}
+	return json.Marshal(struct {
+		UpperBound any    `json:"le"`
+		Count      uint64 `json:"count"`
+	}{le, b.Count})
+}
+
+// WriteJSON 以缩进的 JSON 格式写出快照
+func (s Snapshot) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// ServeHTTP 以 JSON 格式返回当前快照，可以直接挂载为 /metrics 之类的路由
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := r.Snapshot().WriteJSON(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// String 以 JSON 格式返回当前快照，实现 expvar.Var 接口
+func (r *Registry) String() string {
+	data, err := json.Marshal(r.Snapshot())
+	if err != nil {
+		return fmt.Sprintf("%q", err.Error())
+	}
+	return string(data)
+}
+
+// PublishExpvar 将注册表以指定名称发布到 expvar，之后可以通过 /debug/vars 查看
+// expvar 不允许重复发布同名变量，因此名称冲突时返回错误而不是 panic
+func (r *Registry) PublishExpvar(name string) error {
+	expvarMutex.Lock()
+	defer expvarMutex.Unlock()
+	if expvar.Get(name) != nil {
+		return fmt.Errorf("expvar 变量 %q 已存在", name)
+	}
+	expvar.Publish(name, r)
+	return nil
+}
@@ -0,0 +1,184 @@
+package metrics
+
+import (
+	"math"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// Counter 只增不减的计数器，例如请求总数、错误总数。所有方法都是并发安全的
+type Counter struct {
+	value atomic.Uint64
+}
+
+// Inc 计数加一
+func (c *Counter) Inc() {
+	c.value.Add(1)
+}
+
+// Add 计数增加 n
+func (c *Counter) Add(n uint64) {
+	c.value.Add(n)
+}
+
+// Value 返回当前计数
+func (c *Counter) Value() uint64 {
+	return c.value.Load()
+}
+
+// Gauge 可增可减的瞬时值，例如队列长度、连接数、温度。所有方法都是并发安全的
+type Gauge struct {
+	bits atomic.Uint64 // float64 的二进制表示
+}
+
+// Set 设置当前值
+func (g *Gauge) Set(v float64) {
+	g.bits.Store(math.Float64bits(v))
+}
+
+// Add 当前值增加 delta，delta 可以为负
+func (g *Gauge) Add(delta float64) {
+	addFloat(&g.bits, delta)
+}
+
+// Inc 当前值加一
+func (g *Gauge) Inc() {
+	g.Add(1)
+}
+
+// Dec 当前值减一
+func (g *Gauge) Dec() {
+	g.Add(-1)
+}
+
+// Value 返回当前值
+func (g *Gauge) Value() float64 {
+	return math.Float64frombits(g.bits.Load())
+}
+
+// DefaultBuckets 默认的直方图桶上界，适合以秒为单位的请求耗时
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// LinearBuckets 返回 count 个等差的桶上界：start, start+width, ...
+func LinearBuckets(start, width float64, count int) []float64 {
+	buckets := make([]float64, count)
+	for i := range buckets {
+		buckets[i] = start + float64(i)*width
+	}
+	return buckets
+}
+
+// ExponentialBuckets 返回 count 个等比的桶上界：start, start*factor, ...
+func ExponentialBuckets(start, factor float64, count int) []float64 {
+	buckets := make([]float64, count)
+	for i := range buckets {
+		buckets[i] = start * math.Pow(factor, float64(i))
+	}
+	return buckets
+}
+
+// Histogram 固定桶的直方图，统计观测值落在各个区间内的次数，以及观测值的总数与总和
+//
+// 每个桶只是一个原子计数器，Observe 不加锁，也不保存观测值本身，
+// 内存占用与观测次数无关。代价是只能得到分位数的近似值，精度取决于桶的划分
+type Histogram struct {
+	bounds []float64       // 升序的桶上界，最后隐含一个 +Inf 桶
+	counts []atomic.Uint64 // counts[i] 为落在 (bounds[i-1], bounds[i]] 内的次数
+	count  atomic.Uint64
+	sum    atomic.Uint64 // float64 的二进制表示
+}
+
+// newHistogram 用升序、去重后的桶上界创建直方图
+func newHistogram(bounds []float64) *Histogram {
+	return &Histogram{bounds: bounds, counts: make([]atomic.Uint64, len(bounds)+1)}
+}
+
+// Observe 记录一个观测值
+func (h *Histogram) Observe(v float64) {
+	i, _ := slices.BinarySearch(h.bounds, v)
+	h.counts[i].Add(1)
+	addFloat(&h.sum, v)
+	h.count.Add(1)
+}
+
+// ObserveSince 记录从 start 到现在经过的秒数，常用写法为 defer h.ObserveSince(time.Now())
+func (h *Histogram) ObserveSince(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+// Snapshot 返回直方图当前状态的快照
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	s := HistogramSnapshot{Buckets: make([]Bucket, len(h.bounds)+1)}
+	var cumulative uint64
+	for i := range h.counts {
+		cumulative += h.counts[i].Load()
+		bound := math.Inf(1)
+		if i < len(h.bounds) {
+			bound = h.bounds[i]
+		}
+		s.Buckets[i] = Bucket{UpperBound: bound, Count: cumulative}
+	}
+	// 以各桶之和作为总数，保证快照内部一致；并发的 Observe 可能让 Sum 稍有出入
+	s.Count = cumulative
+	s.Sum = math.Float64frombits(h.sum.Load())
+	return s
+}
+
+// Bucket 直方图的一个桶，Count 是观测值不大于 UpperBound 的累计次数
+type Bucket struct {
+	UpperBound float64 `json:"le"`
+	Count      uint64  `json:"count"`
+}
+
+// HistogramSnapshot 直方图的快照
+type HistogramSnapshot struct {
+	Count   uint64   `json:"count"`
+	Sum     float64  `json:"sum"`
+	Buckets []Bucket `json:"buckets"` // 最后一个桶的上界为 +Inf
+}
+
+// Mean 返回观测值的平均值，没有观测值时返回 0
+func (s HistogramSnapshot) Mean() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Sum / float64(s.Count)
+}
+
+// Quantile 返回第 q（0 到 1）分位数的估计值
+// 在目标所在的桶内按线性插值估计；落在 +Inf 桶中时返回最后一个有限的上界
+func (s HistogramSnapshot) Quantile(q float64) float64 {
+	if s.Count == 0 || len(s.Buckets) == 0 {
+		return math.NaN()
+	}
+	rank := q * float64(s.Count)
+	lower, prev := 0.0, uint64(0)
+	for i, b := range s.Buckets {
+		if float64(b.Count) >= rank {
+			if math.IsInf(b.UpperBound, 1) {
+				if i == 0 {
+					return math.NaN()
+				}
+				return s.Buckets[i-1].UpperBound
+			}
+			inBucket := b.Count - prev
+			if inBucket == 0 {
+				return b.UpperBound
+			}
+			return lower + (b.UpperBound-lower)*(rank-float64(prev))/float64(inBucket)
+		}
+		lower, prev = b.UpperBound, b.Count
+	}
+	return s.Buckets[len(s.Buckets)-1].UpperBound
+}
+
+// addFloat 用比较并交换把 delta 原子地加到以二进制表示保存的 float64 上
+func addFloat(bits *atomic.Uint64, delta float64) {
+	for {
+		old := bits.Load()
+		if bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"math"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/XiaoluCoding626/go-design-pattern/concurrency/bounded_parallelism"
+	"github.com/XiaoluCoding626/go-design-pattern/creational/object_pool"
+	"github.com/XiaoluCoding626/go-design-pattern/synchronization/semaphore"
+)
+
+// TestPrimitivesConcurrent 测试计数器、仪表盘和直方图在并发更新下不丢失
+func TestPrimitivesConcurrent(t *testing.T) {
+	var c Counter
+	var g Gauge
+	h := newHistogram([]float64{1, 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				c.Inc()
+				g.Add(0.5)
+				h.Observe(float64(j % 3))
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, uint64(8000), c.Value())
+	assert.Equal(t, 4000.0, g.Value())
+	s := h.Snapshot()
+	assert.Equal(t, uint64(8000), s.Count)
+	assert.Equal(t, 8*999.0, s.Sum, "每个协程观测 333 个 1 和 333 个 2")
+
+	g.Set(3)
+	g.Dec()
+	assert.Equal(t, 2.0, g.Value())
+}
+
+// TestHistogramBuckets 测试桶的边界、累计计数和分位数估计
+func TestHistogramBuckets(t *testing.T) {
+	h := newHistogram([]float64{1, 2, 4})
+	for _, v := range []float64{0.5, 1, 1.5, 3, 3, 10} {
+		h.Observe(v)
+	}
+	s := h.Snapshot()
+	assert.Equal(t, []Bucket{
+		{UpperBound: 1, Count: 2}, // 上界是闭区间
+		{UpperBound: 2, Count: 3},
+		{UpperBound: 4, Count: 5},
+		{UpperBound: math.Inf(1), Count: 6},
+	}, s.Buckets)
+	assert.Equal(t, 19.0/6, s.Mean())
+	assert.InDelta(t, 1.0, s.Quantile(1.0/3), 1e-9)
+	assert.InDelta(t, 3.0, s.Quantile(4.0/6), 1e-9, "在 (2, 4] 桶内线性插值")
+	assert.Equal(t, 4.0, s.Quantile(1), "落在 +Inf 桶时返回最后一个有限上界")
+	assert.True(t, math.IsNaN(HistogramSnapshot{}.Quantile(0.5)))
+
+	assert.Equal(t, []float64{1, 3, 5}, LinearBuckets(1, 2, 3))
+	assert.Equal(t, []float64{1, 10, 100}, ExponentialBuckets(1, 10, 3))
+}
+
+// TestRegistry 测试按名称和标签获取同一个指标，以及各种注册错误
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	a, err := r.Counter("requests_total", Labels{"code": "200", "route": "/a"})
+	assert.NoError(t, err)
+	b, _ := r.Counter("requests_total", Labels{"route": "/a", "code": "200"})
+	assert.Same(t, a, b, "标签顺序不影响")
+	other, _ := r.Counter("requests_total", Labels{"code": "500", "route": "/a"})
+	assert.NotSame(t, a, other)
+
+	_, err = r.Gauge("requests_total", nil)
+	assert.ErrorIs(t, err, ErrKindMismatch)
+	_, err = r.Counter("bad-name", nil)
+	assert.ErrorIs(t, err, ErrInvalidName)
+	_, err = r.Counter("ok", Labels{"1st": "x"})
+	assert.ErrorIs(t, err, ErrInvalidName)
+
+	h1, err := r.Histogram("latency", nil, nil)
+	assert.NoError(t, err)
+	h2, _ := r.Histogram("latency", nil, DefaultBuckets)
+	assert.Same(t, h1, h2)
+	_, err = r.Histogram("latency", Labels{"x": "y"}, []float64{1, 2})
+	assert.ErrorIs(t, err, ErrInvalidBuckets, "同名直方图的桶必须一致")
+	_, err = r.Histogram("sizes", nil, []float64{2, 1})
+	assert.ErrorIs(t, err, ErrInvalidBuckets)
+	_, err = r.Histogram("sizes", nil, []float64{1, 1})
+	assert.ErrorIs(t, err, ErrInvalidBuckets)
+}
+
+// TestSnapshotAndObservers 测试快照包含直接注册的指标和 Collector 的样本，并通知订阅者
+func TestSnapshotAndObservers(t *testing.T) {
+	r := NewRegistry()
+	fixed := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	r.now = func() time.Time { return fixed }
+
+	c, _ := r.Counter("b_total", nil)
+	c.Add(3)
+	g, _ := r.Gauge("a_gauge", Labels{"k": "v"})
+	g.Set(1.5)
+	h, _ := r.Histogram("c_seconds", nil, []float64{1})
+	h.Observe(0.5)
+	unregister := r.Register(CollectorFunc(func(emit func(Sample)) {
+		emit(GaugeSample("a_gauge", Labels{"k": "w"}, 7))
+	}))
+
+	var received []Snapshot
+	cancel := r.Subscribe(func(s Snapshot) { received = append(received, s) })
+
+	s := r.Publish()
+	assert.Equal(t, fixed, s.Time)
+	ids := make([]string, len(s.Samples))
+	for i, sample := range s.Samples {
+		ids[i] = sample.ID()
+	}
+	assert.Equal(t, []string{`a_gauge{k="v"}`, `a_gauge{k="w"}`, "b_total", "c_seconds"}, ids)
+
+	sample, ok := s.Get("b_total", nil)
+	assert.True(t, ok)
+	assert.Equal(t, KindCounter, sample.Kind)
+	assert.Equal(t, 3.0, sample.Value)
+	sample, _ = s.Get("c_seconds", nil)
+	assert.Equal(t, uint64(1), sample.Histogram.Count)
+	_, ok = s.Get("a_gauge", nil)
+	assert.False(t, ok)
+
+	unregister()
+	cancel()
+	s = r.Publish()
+	assert.Len(t, s.Samples, 3)
+	assert.Len(t, received, 1, "取消订阅后不再收到快照")
+}
+
+// TestPublishEvery 测试定期发布，ctx 取消后返回
+func TestPublishEvery(t *testing.T) {
+	r := NewRegistry()
+	var mutex sync.Mutex
+	count := 0
+	r.Subscribe(func(Snapshot) {
+		mutex.Lock()
+		count++
+		mutex.Unlock()
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 55*time.Millisecond)
+	defer cancel()
+	r.PublishEvery(ctx, 10*time.Millisecond)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.GreaterOrEqual(t, count, 3)
+}
+
+// TestExport 测试 JSON、HTTP 和 expvar 导出
+func TestExport(t *testing.T) {
+	r := NewRegistry()
+	h, _ := r.Histogram("latency_seconds", Labels{"route": "/a"}, []float64{0.1})
+	h.Observe(0.05)
+	h.Observe(1)
+
+	var buf bytes.Buffer
+	assert.NoError(t, r.Snapshot().WriteJSON(&buf))
+	var decoded struct {
+		Samples []struct {
+			Name      string            `json:"name"`
+			Labels    map[string]string `json:"labels"`
+			Kind      string            `json:"kind"`
+			Histogram struct {
+				Buckets []struct {
+					Le    any    `json:"le"`
+					Count uint64 `json:"count"`
+				} `json:"buckets"`
+			} `json:"histogram"`
+		} `json:"samples"`
+	}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Len(t, decoded.Samples, 1)
+	sample := decoded.Samples[0]
+	assert.Equal(t, "histogram", sample.Kind)
+	assert.Equal(t, map[string]string{"route": "/a"}, sample.Labels)
+	assert.Equal(t, 0.1, sample.Histogram.Buckets[0].Le)
+	assert.Equal(t, "+Inf", sample.Histogram.Buckets[1].Le)
+	assert.Equal(t, uint64(2), sample.Histogram.Buckets[1].Count)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, 200, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "application/json")
+	assert.Contains(t, rec.Body.String(), "latency_seconds")
+
+	name := uniqueExpvarName(t)
+	assert.NoError(t, r.PublishExpvar(name))
+	assert.Contains(t, expvar.Get(name).String(), `"name":"latency_seconds"`)
+	assert.Error(t, r.PublishExpvar(name), "重复发布返回错误")
+}
+
+// expvarSeq 为每次发布生成不同的名称；expvar 是进程级的，go test -count=N 时同名发布会冲突
+var expvarSeq atomic.Int64
+
+// uniqueExpvarName 返回本次运行中唯一的 expvar 名称
+func uniqueExpvarName(t *testing.T) string {
+	return fmt.Sprintf("%s_%d", t.Name(), expvarSeq.Add(1))
+}
+
+// TestPublishExpvarConcurrent 测试并发发布同名变量时只有一次成功，其余返回错误而不是 panic
+func TestPublishExpvarConcurrent(t *testing.T) {
+	name := uniqueExpvarName(t)
+	var wg sync.WaitGroup
+	var published atomic.Int64
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if NewRegistry().PublishExpvar(name) == nil {
+				published.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(1), published.Load())
+}
+
+// simpleObject 测试用的池对象
+type simpleObject struct{ id int }
+
+func (o *simpleObject) Reset() error   { return nil }
+func (o *simpleObject) Validate() bool { return true }
+func (o *simpleObject) ID() int        { return o.id }
+
+// TestAdapters 测试对象池、信号量和有界执行器通过适配器导出统计
+func TestAdapters(t *testing.T) {
+	r := NewRegistry()
+
+	config := object_pool.DefaultPoolConfig(func() (object_pool.Object, error) { return &simpleObject{id: 1}, nil })
+	config.InitialSize = 1
+	pool, err := object_pool.NewObjectPool(config)
+	assert.NoError(t, err)
+	defer pool.Close()
+	obj, err := pool.AcquireObject()
+	assert.NoError(t, err)
+
+	sem := semaphore.New(4)
+	assert.True(t, sem.TryAcquire())
+
+	executor := bounded_parallelism.NewBoundedExecutor[int](1, 2)
+	assert.NoError(t, executor.Submit(bounded_parallelism.Task[int]{ID: "t", Execute: func() (int, error) { return 1, nil }}))
+	<-executor.Results()
+	executor.Shutdown()
+
+	poolLabels, semLabels, execLabels := Labels{"pool": "db"}, Labels{"name": "io"}, Labels{"executor": "jobs"}
+	r.Register(ObjectPoolCollector(pool, poolLabels))
+	r.Register(SemaphoreCollector(sem, semLabels))
+	r.Register(ExecutorCollector(executor, execLabels))
+
+	s := r.Snapshot()
+	value := func(name string, labels Labels) float64 {
+		sample, ok := s.Get(name, labels)
+		assert.True(t, ok, name)
+		return sample.Value
+	}
+	assert.Equal(t, 1.0, value("object_pool_acquired_total", poolLabels))
+	assert.Equal(t, 1.0, value("object_pool_active", poolLabels))
+	assert.Equal(t, 4.0, value("semaphore_size", semLabels))
+	assert.Equal(t, 1.0, value("semaphore_in_use", semLabels))
+	assert.Equal(t, 1.0, value("executor_submitted_total", execLabels))
+	assert.Equal(t, 1.0, value("executor_completed_total", execLabels))
+	assert.Equal(t, 0.0, value("executor_running", execLabels))
+
+	assert.NoError(t, pool.ReleaseObject(obj))
+	assert.NoError(t, sem.Release())
+	s = r.Snapshot()
+	assert.Equal(t, 0.0, value("object_pool_active", poolLabels))
+	assert.Equal(t, 0.0, value("semaphore_in_use", semLabels))
+}
+
+// BenchmarkCounter 测试并发递增计数器的开销
+func BenchmarkCounter(b *testing.B) {
+	var c Counter
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c.Inc()
+		}
+	})
+}
+
+// BenchmarkHistogramObserve 测试并发记录观测值的开销
+func BenchmarkHistogramObserve(b *testing.B) {
+	h := newHistogram(DefaultBuckets)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		v := 0.0
+		for pb.Next() {
+			h.Observe(v)
+			v += 0.001
+		}
+	})
+}
@@ -0,0 +1,357 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidName 指标名称或标签名称不合法
+	ErrInvalidName = errors.New("指标名称无效")
+	// ErrKindMismatch 同一个名称已经注册为其他类型的指标
+	ErrKindMismatch = errors.New("指标类型冲突")
+	// ErrInvalidBuckets 直方图的桶为空、不是严格升序，或与已注册的同名直方图不一致
+	ErrInvalidBuckets = errors.New("直方图桶无效")
+)
+
+// Kind 指标类型
+type Kind int
+
+const (
+	KindCounter Kind = iota
+	KindGauge
+	KindHistogram
+)
+
+// String 返回类型名称
+func (k Kind) String() string {
+	switch k {
+	case KindCounter:
+		return "counter"
+	case KindGauge:
+		return "gauge"
+	case KindHistogram:
+		return "histogram"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText 以类型名称导出到 JSON
+func (k Kind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Labels 指标的标签，同名指标按标签区分不同的序列，例如 {"route": "/users", "code": "200"}
+type Labels map[string]string
+
+// String 返回按标签名排序的表示，例如 {code="200",route="/users"}，没有标签时返回空字符串
+func (l Labels) String() string {
+	if len(l) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, k := range slices.Sorted(maps.Keys(l)) {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=%q", k, l[k])
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// Sample 快照中的一条序列
+type Sample struct {
+	Name      string             `json:"name"`
+	Labels    Labels             `json:"labels,omitempty"`
+	Kind      Kind               `json:"kind"`
+	Value     float64            `json:"value"`               // 计数器和仪表盘的值，直方图为观测次数
+	Histogram *HistogramSnapshot `json:"histogram,omitempty"` // 仅直方图
+}
+
+// ID 返回序列的唯一标识：名称加标签
+func (s Sample) ID() string {
+	return s.Name + s.Labels.String()
+}
+
+// CounterSample 创建计数器类型的样本，供 Collector 使用
+func CounterSample(name string, labels Labels, value float64) Sample {
+	return Sample{Name: name, Labels: labels, Kind: KindCounter, Value: value}
+}
+
+// GaugeSample 创建仪表盘类型的样本，供 Collector 使用
+func GaugeSample(name string, labels Labels, value float64) Sample {
+	return Sample{Name: name, Labels: labels, Kind: KindGauge, Value: value}
+}
+
+// Snapshot 某一时刻所有指标的快照，按名称和标签排序
+type Snapshot struct {
+	Time    time.Time `json:"time"`
+	Samples []Sample  `json:"samples"`
+}
+
+// Get 按名称和标签查找序列
+func (s Snapshot) Get(name string, labels Labels) (Sample, bool) {
+	id := name + labels.String()
+	for _, sample := range s.Samples {
+		if sample.ID() == id {
+			return sample, true
+		}
+	}
+	return Sample{}, false
+}
+
+// Collector 在生成快照时被调用，把已有组件自己维护的统计转换为样本
+// 适合那些已经有 Stats 方法、不方便改为直接使用 Counter、Gauge 的组件
+type Collector interface {
+	Collect(emit func(Sample))
+}
+
+// CollectorFunc 函数形式的 Collector
+type CollectorFunc func(emit func(Sample))
+
+// Collect 调用函数本身
+func (f CollectorFunc) Collect(emit func(Sample)) {
+	f(emit)
+}
+
+// series 一条已注册的序列
+type series struct {
+	labels Labels
+	metric any // *Counter、*Gauge 或 *Histogram
+}
+
+// family 同名的一组序列，类型和直方图的桶都相同
+type family struct {
+	kind    Kind
+	buckets []float64
+	series  map[string]*series // 键为 Labels.String()
+}
+
+// Registry 指标注册表
+//
+// 直接注册的指标（Counter、Gauge、Histogram）由调用方更新，Collector 在生成快照时才被拉取。
+// 注册表同时是观察者模式中的主题：Publish 生成快照并通知所有订阅者，
+// 日志、推送网关等导出方式只需订阅快照，不需要了解各个组件的统计方式
+type Registry struct {
+	mutex      sync.RWMutex
+	families   map[string]*family
+	collectors map[int]Collector
+	observers  map[int]func(Snapshot)
+	nextID     int
+
+	now func() time.Time // 时间来源，便于测试
+}
+
+// NewRegistry 创建空的注册表
+func NewRegistry() *Registry {
+	return &Registry{
+		families:   make(map[string]*family),
+		collectors: make(map[int]Collector),
+		observers:  make(map[int]func(Snapshot)),
+		now:        time.Now,
+	}
+}
+
+// Counter 返回名称和标签对应的计数器，不存在时创建
+func (r *Registry) Counter(name string, labels Labels) (*Counter, error) {
+	m, err := r.getOrCreate(name, labels, KindCounter, nil, func() any { return new(Counter) })
+	if err != nil {
+		return nil, err
+	}
+	return m.(*Counter), nil
+}
+
+// Gauge 返回名称和标签对应的仪表盘，不存在时创建
+func (r *Registry) Gauge(name string, labels Labels) (*Gauge, error) {
+	m, err := r.getOrCreate(name, labels, KindGauge, nil, func() any { return new(Gauge) })
+	if err != nil {
+		return nil, err
+	}
+	return m.(*Gauge), nil
+}
+
+// Histogram 返回名称和标签对应的直方图，不存在时创建
+// buckets 为 nil 时使用 DefaultBuckets；同名的直方图必须使用相同的桶
+func (r *Registry) Histogram(name string, labels Labels, buckets []float64) (*Histogram, error) {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	if len(buckets) == 0 || !slices.IsSorted(buckets) || len(slices.Compact(slices.Clone(buckets))) != len(buckets) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBuckets, buckets)
+	}
+	buckets = slices.Clone(buckets)
+	m, err := r.getOrCreate(name, labels, KindHistogram, buckets, func() any { return newHistogram(buckets) })
+	if err != nil {
+		return nil, err
+	}
+	return m.(*Histogram), nil
+}
+
+// getOrCreate 查找序列，不存在时用 create 创建
+func (r *Registry) getOrCreate(name string, labels Labels, kind Kind, buckets []float64, create func() any) (any, error) {
+	if err := validate(name, labels); err != nil {
+		return nil, err
+	}
+	key := labels.String()
+
+	r.mutex.RLock()
+	f, ok := r.families[name]
+	var s *series
+	if ok && f.kind == kind {
+		s = f.series[key]
+	}
+	r.mutex.RUnlock()
+	if s != nil && slices.Equal(f.buckets, buckets) {
+		return s.metric, nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	f, ok = r.families[name]
+	if !ok {
+		f = &family{kind: kind, buckets: buckets, series: make(map[string]*series)}
+		r.families[name] = f
+	}
+	if f.kind != kind {
+		return nil, fmt.Errorf("%w: %s 已注册为 %s，不能再注册为 %s", ErrKindMismatch, name, f.kind, kind)
+	}
+	if !slices.Equal(f.buckets, buckets) {
+		return nil, fmt.Errorf("%w: %s 已使用桶 %v", ErrInvalidBuckets, name, f.buckets)
+	}
+	s, ok = f.series[key]
+	if !ok {
+		s = &series{labels: maps.Clone(labels), metric: create()}
+		f.series[key] = s
+	}
+	return s.metric, nil
+}
+
+// Register 注册 Collector，返回的函数用于取消注册
+func (r *Registry) Register(c Collector) (unregister func()) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	id := r.nextID
+	r.nextID++
+	r.collectors[id] = c
+	return func() {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		delete(r.collectors, id)
+	}
+}
+
+// Snapshot 返回所有指标当前值的快照，包括各个 Collector 产生的样本
+func (r *Registry) Snapshot() Snapshot {
+	r.mutex.RLock()
+	var samples []Sample
+	for name, f := range r.families {
+		for _, s := range f.series {
+			samples = append(samples, sampleOf(name, f.kind, s))
+		}
+	}
+	collectors := slices.Collect(maps.Values(r.collectors))
+	r.mutex.RUnlock()
+
+	// Collector 可能较慢或者反过来访问注册表，在锁外调用
+	for _, c := range collectors {
+		c.Collect(func(s Sample) { samples = append(samples, s) })
+	}
+	slices.SortFunc(samples, func(a, b Sample) int {
+		return strings.Compare(a.ID(), b.ID())
+	})
+	return Snapshot{Time: r.now(), Samples: samples}
+}
+
+// Subscribe 订阅 Publish 产生的快照，返回的函数用于取消订阅
+func (r *Registry) Subscribe(fn func(Snapshot)) (cancel func()) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	id := r.nextID
+	r.nextID++
+	r.observers[id] = fn
+	return func() {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		delete(r.observers, id)
+	}
+}
+
+// Publish 生成快照并同步地通知所有订阅者，返回该快照
+func (r *Registry) Publish() Snapshot {
+	snapshot := r.Snapshot()
+	r.mutex.RLock()
+	observers := slices.Collect(maps.Values(r.observers))
+	r.mutex.RUnlock()
+	for _, fn := range observers {
+		fn(snapshot)
+	}
+	return snapshot
+}
+
+// PublishEvery 每隔 interval 调用一次 Publish，直到 ctx 被取消；该方法会阻塞，通常在单独的协程中运行
+func (r *Registry) PublishEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.Publish()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sampleOf 读取一条序列的当前值
+func sampleOf(name string, kind Kind, s *series) Sample {
+	sample := Sample{Name: name, Labels: s.labels, Kind: kind}
+	switch m := s.metric.(type) {
+	case *Counter:
+		sample.Value = float64(m.Value())
+	case *Gauge:
+		sample.Value = m.Value()
+	case *Histogram:
+		h := m.Snapshot()
+		sample.Value = float64(h.Count)
+		sample.Histogram = &h
+	}
+	return sample
+}
+
+// validate 检查指标名称和标签名称：以字母或下划线开头，只包含字母、数字和下划线
+func validate(name string, labels Labels) error {
+	if !validName(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	for k := range labels {
+		if !validName(k) {
+			return fmt.Errorf("%w: 标签 %q", ErrInvalidName, k)
+		}
+	}
+	return nil
+}
+
+// validName 判断名称是否合法
+func validName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case i > 0 && '0' <= c && c <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
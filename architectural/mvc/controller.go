@@ -0,0 +1,170 @@
+package mvc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 控制器相关错误
+var (
+	ErrUnknownCommand = errors.New("未知命令")
+	ErrUsage          = errors.New("命令参数错误")
+)
+
+// command 一条文本命令的定义
+type command struct {
+	usage   string
+	minArgs int
+	maxArgs int
+	run     func(c *Controller, args []string) error
+}
+
+// commands 控制器支持的命令
+var commands = map[string]command{
+	"add": {"add <名称> <价格> [类别] [库存]", 2, 4, (*Controller).add},
+	"list": {"list", 0, 0, func(c *Controller, _ []string) error {
+		c.view.RenderList(c.model.Products())
+		return nil
+	}},
+	"show": {"show <名称>", 1, 1, func(c *Controller, args []string) error {
+		info, err := c.model.Get(args[0])
+		if err == nil {
+			c.view.RenderProduct(info)
+		}
+		return err
+	}},
+	"restock": {"restock <名称> <数量>", 2, 2, func(c *Controller, args []string) error {
+		n, err := parseInt(args[1])
+		if err != nil {
+			return err
+		}
+		return c.model.Restock(args[0], n)
+	}},
+	"sell": {"sell <名称> <数量>", 2, 2, func(c *Controller, args []string) error {
+		n, err := parseInt(args[1])
+		if err != nil {
+			return err
+		}
+		return c.model.Sell(args[0], n)
+	}},
+	"discount": {"discount <名称> <百分比>", 2, 2, func(c *Controller, args []string) error {
+		percent, err := parseFloat(args[1])
+		if err != nil {
+			return err
+		}
+		return c.model.Discount(args[0], percent)
+	}},
+	"remove": {"remove <名称>", 1, 1, func(c *Controller, args []string) error {
+		return c.model.Remove(args[0])
+	}},
+}
+
+// help 需要遍历 commands，放在 init 中注册以避免初始化循环
+func init() {
+	commands["help"] = command{"help", 0, 0, (*Controller).help}
+}
+
+// Controller 控制器：解析用户输入，调用模型修改数据，选择视图渲染查询结果
+//
+// 修改命令成功后控制器不需要刷新视图，视图作为模型的观察者会自动更新。
+type Controller struct {
+	model *Catalog
+	view  View
+}
+
+// NewController 创建控制器，并把视图注册为模型的观察者
+func NewController(model *Catalog, view View) *Controller {
+	model.Subscribe(view)
+	return &Controller{model: model, view: view}
+}
+
+// Handle 执行一行命令，出错时同时渲染错误并返回
+func (c *Controller) Handle(line string) error {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	name, args := strings.ToLower(fields[0]), fields[1:]
+	cmd, exists := commands[name]
+	var err error
+	switch {
+	case !exists:
+		err = fmt.Errorf("%w: %s，输入 help 查看可用命令", ErrUnknownCommand, name)
+	case len(args) < cmd.minArgs || len(args) > cmd.maxArgs:
+		err = fmt.Errorf("%w: 用法 %s", ErrUsage, cmd.usage)
+	default:
+		err = cmd.run(c, args)
+	}
+
+	if err != nil {
+		c.view.RenderError(err)
+	}
+	return err
+}
+
+// Run 逐行读取并执行命令，直到输入结束或遇到 quit
+// 单条命令出错不会中断执行，返回值只反映读取输入时的错误
+func (c *Controller) Run(in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "quit" || line == "exit" {
+			break
+		}
+		c.Handle(line)
+	}
+	return scanner.Err()
+}
+
+// add 添加商品
+func (c *Controller) add(args []string) error {
+	price, err := parseFloat(args[1])
+	if err != nil {
+		return err
+	}
+	category, stock := "未分类", 0
+	if len(args) > 2 {
+		category = args[2]
+	}
+	if len(args) > 3 {
+		if stock, err = parseInt(args[3]); err != nil {
+			return err
+		}
+	}
+	return c.model.Add(args[0], price, category, stock)
+}
+
+// help 列出所有命令
+func (c *Controller) help([]string) error {
+	usages := make([]string, 0, len(commands))
+	for _, cmd := range commands {
+		usages = append(usages, "  "+cmd.usage)
+	}
+	sort.Strings(usages)
+	c.view.RenderMessage("可用命令:\n%s", strings.Join(usages, "\n"))
+	return nil
+}
+
+// parseInt 解析整数参数
+func parseInt(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q 不是整数", ErrUsage, s)
+	}
+	return n, nil
+}
+
+// parseFloat 解析数字参数
+func parseFloat(s string) (float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q 不是数字", ErrUsage, s)
+	}
+	return f, nil
+}
@@ -0,0 +1,30 @@
+package mvc
+
+import (
+	"os"
+	"strings"
+)
+
+// RunExample 运行 MVC 示例：用一段命令脚本模拟用户在控制台中的输入
+func RunExample() {
+	model := NewCatalog()
+	view := NewConsoleView(os.Stdout)
+	controller := NewController(model, view)
+
+	script := `
+add 机械键盘 399 外设 10
+add 显示器 1299 外设 3
+add 咖啡豆 89 食品 20
+list
+sell 显示器 2
+discount 机械键盘 20
+sell 显示器 5
+restock 显示器 10
+show 显示器
+remove 咖啡豆
+refund 机械键盘
+list
+quit
+`
+	controller.Run(strings.NewReader(script))
+}
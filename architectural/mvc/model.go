@@ -0,0 +1,219 @@
+package mvc
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	product "github.com/XiaoluCoding626/go-design-pattern/creational/new"
+)
+
+// 模型相关错误
+var (
+	ErrProductNotFound  = errors.New("商品不存在")
+	ErrDuplicateProduct = errors.New("商品已存在")
+)
+
+// ChangeKind 模型变化的类型
+type ChangeKind int
+
+const (
+	ProductAdded ChangeKind = iota
+	ProductUpdated
+	ProductRemoved
+)
+
+// String 返回变化类型名称
+func (k ChangeKind) String() string {
+	switch k {
+	case ProductAdded:
+		return "新增"
+	case ProductUpdated:
+		return "更新"
+	case ProductRemoved:
+		return "删除"
+	default:
+		return fmt.Sprintf("ChangeKind(%d)", int(k))
+	}
+}
+
+// ProductInfo 商品的只读快照，模型只把快照交给视图，视图无法修改模型
+type ProductInfo struct {
+	Name          string
+	Category      string
+	OriginalPrice float64
+	Price         float64 // 折后价
+	Discount      float64 // 折扣百分比
+	Stock         int
+}
+
+// ChangeEvent 模型变化事件
+type ChangeEvent struct {
+	Kind   ChangeKind
+	Before ProductInfo // 变化前的快照，新增时为零值
+	After  ProductInfo // 变化后的快照，删除时为零值
+}
+
+// ModelListener 模型变化的观察者
+type ModelListener interface {
+	ModelChanged(event ChangeEvent)
+}
+
+// Catalog 商品目录模型：保存商品并在变化时通知观察者
+// 模型不知道视图和控制器的存在，只通过 ModelListener 接口发出通知
+type Catalog struct {
+	mutex     sync.Mutex
+	products  map[string]*product.Product // 按名称索引
+	listeners []ModelListener
+}
+
+// NewCatalog 创建空的商品目录
+func NewCatalog() *Catalog {
+	return &Catalog{products: make(map[string]*product.Product)}
+}
+
+// Subscribe 注册观察者，返回取消注册的函数
+func (c *Catalog) Subscribe(listener ModelListener) func() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.listeners = append(c.listeners, listener)
+	return func() {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+
+		for i, l := range c.listeners {
+			if l == listener {
+				c.listeners = append(c.listeners[:i:i], c.listeners[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Add 添加商品，商品通过 creational/new 的构造函数创建，校验规则由构造函数负责
+func (c *Catalog) Add(name string, price float64, category string, stock int) error {
+	p, err := product.NewProductComplete(name, price, category, stock, 0)
+	if err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	if _, exists := c.products[name]; exists {
+		c.mutex.Unlock()
+		return fmt.Errorf("%w: %s", ErrDuplicateProduct, name)
+	}
+	c.products[name] = p
+	listeners := c.listenersLocked()
+	c.mutex.Unlock()
+
+	notify(listeners, ChangeEvent{Kind: ProductAdded, After: snapshot(p)})
+	return nil
+}
+
+// Restock 增加库存
+func (c *Catalog) Restock(name string, amount int) error {
+	return c.update(name, func(p *product.Product) error { return p.AddStock(amount) })
+}
+
+// Sell 卖出商品，减少库存
+func (c *Catalog) Sell(name string, amount int) error {
+	return c.update(name, func(p *product.Product) error { return p.ReduceStock(amount) })
+}
+
+// Discount 设置折扣百分比
+func (c *Catalog) Discount(name string, percent float64) error {
+	return c.update(name, func(p *product.Product) error { return p.ApplyDiscount(percent) })
+}
+
+// Remove 删除商品
+func (c *Catalog) Remove(name string) error {
+	c.mutex.Lock()
+	p, exists := c.products[name]
+	if !exists {
+		c.mutex.Unlock()
+		return fmt.Errorf("%w: %s", ErrProductNotFound, name)
+	}
+	delete(c.products, name)
+	listeners := c.listenersLocked()
+	c.mutex.Unlock()
+
+	notify(listeners, ChangeEvent{Kind: ProductRemoved, Before: snapshot(p)})
+	return nil
+}
+
+// Get 返回商品快照
+func (c *Catalog) Get(name string) (ProductInfo, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	p, exists := c.products[name]
+	if !exists {
+		return ProductInfo{}, fmt.Errorf("%w: %s", ErrProductNotFound, name)
+	}
+	return snapshot(p), nil
+}
+
+// Products 返回所有商品的快照，按类别和名称排序
+func (c *Catalog) Products() []ProductInfo {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	infos := make([]ProductInfo, 0, len(c.products))
+	for _, p := range c.products {
+		infos = append(infos, snapshot(p))
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Category != infos[j].Category {
+			return infos[i].Category < infos[j].Category
+		}
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
+// update 修改商品并通知观察者，修改失败时不通知
+func (c *Catalog) update(name string, change func(p *product.Product) error) error {
+	c.mutex.Lock()
+	p, exists := c.products[name]
+	if !exists {
+		c.mutex.Unlock()
+		return fmt.Errorf("%w: %s", ErrProductNotFound, name)
+	}
+	before := snapshot(p)
+	if err := change(p); err != nil {
+		c.mutex.Unlock()
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	after := snapshot(p)
+	listeners := c.listenersLocked()
+	c.mutex.Unlock()
+
+	notify(listeners, ChangeEvent{Kind: ProductUpdated, Before: before, After: after})
+	return nil
+}
+
+// listenersLocked 复制观察者列表，调用者需持有锁；通知在锁外进行，观察者可以回头查询模型
+func (c *Catalog) listenersLocked() []ModelListener {
+	return append([]ModelListener(nil), c.listeners...)
+}
+
+// notify 依次通知观察者
+func notify(listeners []ModelListener, event ChangeEvent) {
+	for _, l := range listeners {
+		l.ModelChanged(event)
+	}
+}
+
+// snapshot 生成商品快照
+func snapshot(p *product.Product) ProductInfo {
+	return ProductInfo{
+		Name:          p.GetName(),
+		Category:      p.GetCategory(),
+		OriginalPrice: p.GetOriginalPrice(),
+		Price:         p.GetPrice(),
+		Discount:      p.GetDiscount(),
+		Stock:         p.GetStock(),
+	}
+}
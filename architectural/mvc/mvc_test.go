@@ -0,0 +1,140 @@
+package mvc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder 记录收到的模型变化
+type recorder struct {
+	events []ChangeEvent
+}
+
+func (r *recorder) ModelChanged(event ChangeEvent) {
+	r.events = append(r.events, event)
+}
+
+func newTestController() (*Controller, *Catalog, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	model := NewCatalog()
+	return NewController(model, NewConsoleView(out)), model, out
+}
+
+// TestCatalogNotifications 测试模型变化通知，失败的修改不通知
+func TestCatalogNotifications(t *testing.T) {
+	model := NewCatalog()
+	rec := &recorder{}
+	unsubscribe := model.Subscribe(rec)
+
+	assert.NoError(t, model.Add("键盘", 100, "外设", 5))
+	assert.ErrorIs(t, model.Add("键盘", 100, "外设", 5), ErrDuplicateProduct)
+	assert.NoError(t, model.Sell("键盘", 2))
+	assert.ErrorContains(t, model.Sell("键盘", 10), "库存不足")
+	assert.NoError(t, model.Discount("键盘", 25))
+	assert.ErrorIs(t, model.Restock("鼠标", 1), ErrProductNotFound)
+	assert.NoError(t, model.Remove("键盘"))
+
+	assert.Len(t, rec.events, 4)
+	assert.Equal(t, ProductAdded, rec.events[0].Kind)
+	assert.Equal(t, 5, rec.events[0].After.Stock)
+	assert.Equal(t, ProductUpdated, rec.events[1].Kind)
+	assert.Equal(t, 5, rec.events[1].Before.Stock)
+	assert.Equal(t, 3, rec.events[1].After.Stock)
+	assert.InDelta(t, 75.0, rec.events[2].After.Price, 0.001)
+	assert.InDelta(t, 25.0, rec.events[2].After.Discount, 0.001)
+	assert.Equal(t, ProductRemoved, rec.events[3].Kind)
+	assert.Equal(t, "键盘", rec.events[3].Before.Name)
+
+	unsubscribe()
+	assert.NoError(t, model.Add("鼠标", 50, "外设", 1))
+	assert.Len(t, rec.events, 4, "取消注册后不再收到通知")
+}
+
+// TestViewUpdatesAutomatically 测试修改命令不经控制器渲染，视图通过观察者自动更新
+func TestViewUpdatesAutomatically(t *testing.T) {
+	c, model, out := newTestController()
+
+	assert.NoError(t, c.Handle("add 键盘 399 外设 10"))
+	assert.Equal(t, "[新增] 键盘 ¥399.00 库存 10\n", out.String())
+
+	// 绕过控制器直接修改模型，视图同样会刷新
+	out.Reset()
+	assert.NoError(t, model.Sell("键盘", 4))
+	assert.Equal(t, "[更新] 键盘 ¥399.00 库存 6（库存 10 → 6）\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, c.Handle("discount 键盘 50"))
+	assert.Contains(t, out.String(), "售价 ¥399.00 → ¥199.50")
+
+	out.Reset()
+	assert.NoError(t, c.Handle("remove 键盘"))
+	assert.Equal(t, "[删除] 键盘\n", out.String())
+}
+
+// TestQueries 测试查询命令由控制器选择视图渲染
+func TestQueries(t *testing.T) {
+	c, _, out := newTestController()
+	assert.NoError(t, c.Handle("list"))
+	assert.Equal(t, "目录为空\n", out.String())
+
+	c.Handle("add 显示器 1299 外设 3")
+	c.Handle("add 键盘 399 外设")
+	c.Handle("add 咖啡豆 89")
+
+	out.Reset()
+	assert.NoError(t, c.Handle("LIST"))
+	assert.Equal(t, "== 外设 ==\n  显示器 ¥1299.00 库存 3\n  键盘 ¥399.00 库存 0\n== 未分类 ==\n  咖啡豆 ¥89.00 库存 0\n共 3 件商品\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, c.Handle("show 显示器"))
+	assert.Contains(t, out.String(), "名称: 显示器\n类别: 外设\n")
+	assert.Contains(t, out.String(), "库存: 3\n")
+
+	out.Reset()
+	assert.NoError(t, c.Handle("help"))
+	assert.Contains(t, out.String(), "add <名称> <价格> [类别] [库存]")
+	assert.Contains(t, out.String(), "sell <名称> <数量>")
+}
+
+// TestCommandErrors 测试命令错误被渲染并返回
+func TestCommandErrors(t *testing.T) {
+	c, _, out := newTestController()
+
+	tests := []struct {
+		line string
+		want error
+	}{
+		{"refund 键盘", ErrUnknownCommand},
+		{"add 键盘", ErrUsage},
+		{"add 键盘 abc", ErrUsage},
+		{"sell 键盘 1 2", ErrUsage},
+		{"restock 键盘 x", ErrUsage},
+		{"show 键盘", ErrProductNotFound},
+	}
+	for _, tt := range tests {
+		out.Reset()
+		err := c.Handle(tt.line)
+		assert.ErrorIs(t, err, tt.want, tt.line)
+		assert.Equal(t, "错误: "+err.Error()+"\n", out.String(), tt.line)
+	}
+
+	assert.Error(t, c.Handle("add 键盘 -1"), "价格校验由 creational/new 的构造函数负责")
+	assert.NoError(t, c.Handle("   "), "空行被忽略")
+}
+
+// TestRun 测试逐行执行命令，遇到 quit 停止
+func TestRun(t *testing.T) {
+	c, model, out := newTestController()
+	script := "add 键盘 399 外设 1\nsell 键盘 5\n\nsell 键盘 1\nquit\nadd 鼠标 99\n"
+
+	assert.NoError(t, c.Run(strings.NewReader(script)))
+	assert.Contains(t, out.String(), "错误: 键盘: 库存不足", "单条命令出错不中断执行")
+	info, err := model.Get("键盘")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, info.Stock)
+	_, err = model.Get("鼠标")
+	assert.ErrorIs(t, err, ErrProductNotFound, "quit 之后的命令不执行")
+}
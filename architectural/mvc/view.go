@@ -0,0 +1,109 @@
+package mvc
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// View 视图：只负责把数据渲染成输出，不包含业务逻辑
+type View interface {
+	ModelListener
+
+	RenderList(products []ProductInfo)
+	RenderProduct(info ProductInfo)
+	RenderMessage(format string, args ...any)
+	RenderError(err error)
+}
+
+// ConsoleView 把目录渲染为文本的视图，输出写入注入的 io.Writer，便于测试和重定向
+type ConsoleView struct {
+	mutex sync.Mutex // 模型通知和控制器的渲染可能来自不同的协程，保证每次输出完整
+	out   io.Writer
+}
+
+// NewConsoleView 创建文本视图
+func NewConsoleView(out io.Writer) *ConsoleView {
+	return &ConsoleView{out: out}
+}
+
+// ModelChanged 模型变化时自动刷新：输出变化的商品
+func (v *ConsoleView) ModelChanged(event ChangeEvent) {
+	switch event.Kind {
+	case ProductAdded:
+		v.printf("[%s] %s\n", event.Kind, formatLine(event.After))
+	case ProductRemoved:
+		v.printf("[%s] %s\n", event.Kind, event.Before.Name)
+	default:
+		v.printf("[%s] %s%s\n", event.Kind, formatLine(event.After), formatDiff(event.Before, event.After))
+	}
+}
+
+// RenderList 渲染商品列表
+func (v *ConsoleView) RenderList(products []ProductInfo) {
+	if len(products) == 0 {
+		v.printf("目录为空\n")
+		return
+	}
+
+	var b strings.Builder
+	category := ""
+	for _, p := range products {
+		if p.Category != category {
+			category = p.Category
+			fmt.Fprintf(&b, "== %s ==\n", category)
+		}
+		fmt.Fprintf(&b, "  %s\n", formatLine(p))
+	}
+	fmt.Fprintf(&b, "共 %d 件商品\n", len(products))
+	v.printf("%s", b.String())
+}
+
+// RenderProduct 渲染单个商品的详情
+func (v *ConsoleView) RenderProduct(p ProductInfo) {
+	v.printf("名称: %s\n类别: %s\n原价: ¥%.2f\n折扣: %.0f%%\n售价: ¥%.2f\n库存: %d\n",
+		p.Name, p.Category, p.OriginalPrice, p.Discount, p.Price, p.Stock)
+}
+
+// RenderMessage 渲染提示信息
+func (v *ConsoleView) RenderMessage(format string, args ...any) {
+	v.printf(format+"\n", args...)
+}
+
+// RenderError 渲染错误
+func (v *ConsoleView) RenderError(err error) {
+	v.printf("错误: %v\n", err)
+}
+
+// printf 加锁输出
+func (v *ConsoleView) printf(format string, args ...any) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	fmt.Fprintf(v.out, format, args...)
+}
+
+// formatLine 商品的单行摘要
+func formatLine(p ProductInfo) string {
+	price := fmt.Sprintf("¥%.2f", p.Price)
+	if p.Discount > 0 {
+		price += fmt.Sprintf("（原价 ¥%.2f，%.0f%% off）", p.OriginalPrice, p.Discount)
+	}
+	return fmt.Sprintf("%s %s 库存 %d", p.Name, price, p.Stock)
+}
+
+// formatDiff 描述商品的变化
+func formatDiff(before, after ProductInfo) string {
+	var changes []string
+	if before.Stock != after.Stock {
+		changes = append(changes, fmt.Sprintf("库存 %d → %d", before.Stock, after.Stock))
+	}
+	if before.Price != after.Price {
+		changes = append(changes, fmt.Sprintf("售价 ¥%.2f → ¥%.2f", before.Price, after.Price))
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+	return "（" + strings.Join(changes, "，") + "）"
+}
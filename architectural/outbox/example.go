@@ -0,0 +1,162 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/XiaoluCoding626/go-design-pattern/concurrency/mq"
+)
+
+// order 示例中的订单
+type order struct {
+	ID     string `json:"id"`
+	Amount int    `json:"amount"`
+}
+
+// orderPlaced 下单事件
+type orderPlaced struct {
+	OrderID string `json:"order_id"`
+	Amount  int    `json:"amount"`
+}
+
+// placeOrder 下单：保存订单和写入下单事件在同一个事务中完成
+func placeOrder(store *Store, id string, amount int) error {
+	return store.Update(func(tx *Tx) error {
+		if amount <= 0 {
+			return fmt.Errorf("订单 %s 的金额必须大于 0", id)
+		}
+		if _, err := tx.Get("order:" + id); err == nil {
+			return fmt.Errorf("订单 %s 已存在", id)
+		}
+		data, _ := json.Marshal(order{ID: id, Amount: amount})
+		tx.Put("order:"+id, data)
+		payload, _ := json.Marshal(orderPlaced{OrderID: id, Amount: amount})
+		tx.Emit("orders", id, payload)
+		return nil
+	})
+}
+
+// flakyPublisher 模拟不可靠的网络：ackLost 中的事件发布成功但确认丢失，down 为 true 时发布失败
+type flakyPublisher struct {
+	next Publisher
+
+	mutex   sync.Mutex
+	ackLost map[uint64]bool
+	down    bool
+}
+
+// Publish 按设定注入故障
+func (p *flakyPublisher) Publish(ctx context.Context, e Event) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.down {
+		return errors.New("消息代理不可达")
+	}
+	if err := p.next.Publish(ctx, e); err != nil {
+		return err
+	}
+	if p.ackLost[e.ID] {
+		delete(p.ackLost, e.ID)
+		return errors.New("等待确认超时")
+	}
+	return nil
+}
+
+// setDown 设置消息代理是否不可达
+func (p *flakyPublisher) setDown(down bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.down = down
+}
+
+// RunExample 运行事务性发件箱示例：下单与下单事件一起提交，中继把事件可靠地发布到消息队列
+func RunExample() {
+	store := NewStore()
+	broker := mq.NewBroker()
+	defer broker.Close()
+	orders, _ := broker.Declare("orders", mq.WithRetryDelay(10*time.Millisecond))
+	billing, shipping := orders.Group("billing"), orders.Group("shipping")
+
+	var mutex sync.Mutex
+	log := func(format string, args ...any) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		fmt.Printf("  "+format+"\n", args...)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+
+	// 消费者：计费和发货各自去重
+	billed := map[string]int{}
+	billingDedup := NewDeduplicator(1000)
+	shippingDedup := NewDeduplicator(1000)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		billing.Consume(ctx, 2, billingDedup.Handler(func(ctx context.Context, env Envelope) error {
+			var e orderPlaced
+			json.Unmarshal(env.Payload, &e)
+			mutex.Lock()
+			billed[e.OrderID] += e.Amount
+			mutex.Unlock()
+			log("[billing] 事件 #%d：订单 %s 扣款 %d", env.EventID, e.OrderID, e.Amount)
+			return nil
+		}))
+	}()
+	go func() {
+		defer wg.Done()
+		shipping.Consume(ctx, 1, shippingDedup.Handler(func(ctx context.Context, env Envelope) error {
+			log("[shipping] 事件 #%d：订单 %s 准备发货", env.EventID, env.Key)
+			return nil
+		}))
+	}()
+
+	// 中继：事件 #2 发布后确认丢失，会被再次发布
+	publisher := &flakyPublisher{next: NewMQPublisher(broker), ackLost: map[uint64]bool{2: true}}
+	relay := NewRelay(store, publisher, WithPollInterval(20*time.Millisecond), WithRelayErrorHandler(func(e Event, err error) {
+		log("[relay] 事件 #%d 发布失败，留在发件箱中稍后重试: %v", e.ID, err)
+	}))
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		relay.Run(ctx)
+	}()
+
+	fmt.Println("== 下单：订单和事件在同一个事务中提交 ==")
+	placeOrder(store, "A-1", 100)
+	placeOrder(store, "A-2", 250)
+	if err := placeOrder(store, "A-3", 0); err != nil {
+		log("下单失败，订单和事件都没有写入: %v", err)
+	}
+	time.Sleep(80 * time.Millisecond)
+
+	fmt.Println("== 消息代理不可达：事件留在发件箱中 ==")
+	publisher.setDown(true)
+	placeOrder(store, "A-4", 80)
+	time.Sleep(50 * time.Millisecond)
+	log("发件箱中待发布的事件: %d", store.Stats().Pending)
+	publisher.setDown(false)
+	time.Sleep(80 * time.Millisecond)
+
+	cancel()
+	wg.Wait()
+
+	fmt.Println("== 结果 ==")
+	ids := make([]string, 0, len(billed))
+	for id := range billed {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		log("订单 %s 共扣款 %d", id, billed[id])
+	}
+	log("存储: %+v", store.Stats())
+	log("中继: %+v", relay.Stats())
+	log("计费跳过重复投递 %d 次，发货跳过 %d 次", billingDedup.Duplicates(), shippingDedup.Duplicates())
+}
@@ -0,0 +1,129 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/XiaoluCoding626/go-design-pattern/concurrency/mq"
+)
+
+// ErrInProgress 同一个事件的另一次投递正在处理中
+var ErrInProgress = errors.New("事件正在处理中")
+
+// Envelope 事件在消息中的格式
+// 消息代理给每次发布分配新的消息编号，重复发布的同一个事件只能靠 EventID 识别
+type Envelope struct {
+	EventID   uint64    `json:"event_id"`
+	Topic     string    `json:"topic"`
+	Key       string    `json:"key"`
+	Payload   []byte    `json:"payload"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DecodeEnvelope 从消息体解码事件
+func DecodeEnvelope(body []byte) (Envelope, error) {
+	var env Envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		return Envelope{}, fmt.Errorf("解码事件: %w", err)
+	}
+	return env, nil
+}
+
+// MQPublisher 把事件发布到消息队列，主题即队列名
+// 队列和消费组需要事先声明，消息只会投递给发布时已经存在的消费组
+type MQPublisher struct {
+	broker *mq.Broker
+}
+
+// NewMQPublisher 创建发布到 broker 的 Publisher
+func NewMQPublisher(broker *mq.Broker) *MQPublisher {
+	return &MQPublisher{broker: broker}
+}
+
+// Publish 编码事件并发布到与主题同名的队列
+func (p *MQPublisher) Publish(_ context.Context, e Event) error {
+	body, err := json.Marshal(Envelope{
+		EventID:   e.ID,
+		Topic:     e.Topic,
+		Key:       e.Key,
+		Payload:   e.Payload,
+		CreatedAt: e.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = p.broker.Publish(e.Topic, body)
+	return err
+}
+
+// Deduplicator 消费端去重：记住处理过的事件编号，重复投递的事件直接确认，不再处理
+//
+// 只记住最近 capacity 个事件，重复投递通常紧跟在原始投递之后，足以覆盖中继重试造成的重复。
+// 处理结果和"已处理"标记应当原子地保存（收件箱模式），这里放在内存中，进程重启后会遗忘。
+type Deduplicator struct {
+	capacity int
+
+	mutex      sync.Mutex
+	done       map[uint64]bool // true 表示已处理，false 表示正在处理
+	order      []uint64        // 已处理事件的先后顺序，用于淘汰最早的记录
+	duplicates int
+}
+
+// NewDeduplicator 创建最多记住 capacity 个事件的去重器
+func NewDeduplicator(capacity int) *Deduplicator {
+	return &Deduplicator{capacity: max(capacity, 1), done: make(map[uint64]bool)}
+}
+
+// Handler 包装事件处理函数，返回可以交给消息队列消费组的 Handler
+// 已处理过的事件直接确认；另一次投递正在处理时返回 ErrInProgress，消息稍后重新投递；
+// 处理失败时不记录，消息重新投递后会再次处理
+func (d *Deduplicator) Handler(fn func(ctx context.Context, env Envelope) error) mq.Handler {
+	return func(ctx context.Context, delivery *mq.Delivery) error {
+		env, err := DecodeEnvelope(delivery.Body)
+		if err != nil {
+			return mq.Permanent(err)
+		}
+
+		d.mutex.Lock()
+		finished, seen := d.done[env.EventID]
+		if seen {
+			if finished {
+				d.duplicates++
+			}
+			d.mutex.Unlock()
+			if finished {
+				return nil
+			}
+			return ErrInProgress
+		}
+		d.done[env.EventID] = false
+		d.mutex.Unlock()
+
+		err = fn(ctx, env)
+
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+		if err != nil {
+			delete(d.done, env.EventID)
+			return err
+		}
+		d.done[env.EventID] = true
+		d.order = append(d.order, env.EventID)
+		if len(d.order) > d.capacity {
+			delete(d.done, d.order[0])
+			d.order = d.order[1:]
+		}
+		return nil
+	}
+}
+
+// Duplicates 返回被识别为重复而跳过的投递次数
+func (d *Deduplicator) Duplicates() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.duplicates
+}
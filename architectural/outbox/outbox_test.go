@@ -0,0 +1,366 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/XiaoluCoding626/go-design-pattern/concurrency/mq"
+)
+
+// recorder 记录发布的事件，可以按次数注入失败
+type recorder struct {
+	mutex     sync.Mutex
+	events    []Event
+	failNext  int  // 接下来失败的次数
+	ackLost   bool // 失败时事件是否已经发出
+	published chan Event
+}
+
+func (r *recorder) Publish(_ context.Context, e Event) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.failNext > 0 {
+		r.failNext--
+		if r.ackLost {
+			r.events = append(r.events, e)
+		}
+		return errors.New("发布失败")
+	}
+	r.events = append(r.events, e)
+	if r.published != nil {
+		r.published <- e
+	}
+	return nil
+}
+
+func (r *recorder) ids() []uint64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var ids []uint64
+	for _, e := range r.events {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+// emit 提交一个只写入一个事件的事务
+func emit(t *testing.T, s *Store, key string) {
+	t.Helper()
+	assert.NoError(t, s.Update(func(tx *Tx) error {
+		tx.Put(key, []byte("v"))
+		tx.Emit("topic", key, []byte(key))
+		return nil
+	}))
+}
+
+func TestTransactionCommit(t *testing.T) {
+	s := NewStore()
+	err := s.Update(func(tx *Tx) error {
+		tx.Put("a", []byte("1"))
+		tx.Put("b", []byte("2"))
+		tx.Delete("b")
+		v, err := tx.Get("a")
+		assert.NoError(t, err)
+		assert.Equal(t, "1", string(v), "事务内可以读到自己的写入")
+		_, err = tx.Get("b")
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		tx.Emit("orders", "a", []byte("created"))
+		tx.Emit("orders", "a", []byte("paid"))
+		return nil
+	})
+	assert.NoError(t, err)
+
+	v, err := s.Get("a")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", string(v))
+	assert.Equal(t, map[string][]byte{"a": []byte("1")}, s.Snapshot())
+
+	pending := s.Pending(0)
+	if assert.Len(t, pending, 2) {
+		assert.Equal(t, uint64(1), pending[0].ID)
+		assert.Equal(t, uint64(2), pending[1].ID)
+		assert.Equal(t, "paid", string(pending[1].Payload))
+		assert.False(t, pending[0].CreatedAt.IsZero())
+	}
+	assert.Len(t, s.Pending(1), 1)
+}
+
+func TestTransactionRollback(t *testing.T) {
+	s := NewStore()
+	emit(t, s, "kept")
+
+	failure := errors.New("校验失败")
+	err := s.Update(func(tx *Tx) error {
+		tx.Put("kept", []byte("changed"))
+		tx.Put("new", []byte("x"))
+		tx.Emit("topic", "new", nil)
+		return failure
+	})
+	assert.ErrorIs(t, err, failure)
+
+	assert.Panics(t, func() {
+		s.Update(func(tx *Tx) error {
+			tx.Put("new", []byte("x"))
+			tx.Emit("topic", "new", nil)
+			panic("意外错误")
+		})
+	})
+
+	// 数据和事件都没有写入
+	v, _ := s.Get("kept")
+	assert.Equal(t, "v", string(v))
+	_, err = s.Get("new")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Len(t, s.Pending(0), 1)
+	assert.Equal(t, StoreStats{Commits: 1, Rollbacks: 2, Emitted: 1, Pending: 1}, s.Stats())
+}
+
+func TestMarkPublished(t *testing.T) {
+	s := NewStore()
+	for i := 0; i < 3; i++ {
+		emit(t, s, fmt.Sprint(i))
+	}
+	assert.NoError(t, s.MarkPublished(2))
+	assert.ErrorIs(t, s.MarkPublished(2), ErrEventNotFound)
+
+	var ids []uint64
+	for _, e := range s.Pending(0) {
+		ids = append(ids, e.ID)
+	}
+	assert.Equal(t, []uint64{1, 3}, ids)
+	assert.Equal(t, 1, s.Stats().Published)
+}
+
+func TestRelayPublishesInOrder(t *testing.T) {
+	s := NewStore()
+	for i := 0; i < 5; i++ {
+		emit(t, s, fmt.Sprint(i))
+	}
+	rec := &recorder{}
+	relay := NewRelay(s, rec, WithBatchSize(3))
+
+	n, err := relay.RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	n, err = relay.RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	assert.Equal(t, []uint64{1, 2, 3, 4, 5}, rec.ids())
+	assert.Empty(t, s.Pending(0))
+	assert.Equal(t, RelayStats{Rounds: 2, Published: 5}, relay.Stats())
+}
+
+func TestRelayRetriesAtLeastOnce(t *testing.T) {
+	s := NewStore()
+	for i := 0; i < 3; i++ {
+		emit(t, s, fmt.Sprint(i))
+	}
+	// 第二个事件发出去了但确认丢失
+	rec := &recorder{}
+	var failed []uint64
+	relay := NewRelay(s, PublisherFunc(func(ctx context.Context, e Event) error {
+		if e.ID == 2 && len(failed) == 0 {
+			rec.ackLost, rec.failNext = true, 1
+		}
+		return rec.Publish(ctx, e)
+	}), WithRelayErrorHandler(func(e Event, err error) { failed = append(failed, e.ID) }))
+
+	n, err := relay.RunOnce(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 1, n, "失败后停止本轮，后面的事件不会越过失败的事件")
+	assert.Equal(t, []uint64{2}, failed)
+	assert.Len(t, s.Pending(0), 2)
+
+	n, err = relay.RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []uint64{1, 2, 2, 3}, rec.ids(), "事件 2 发布了两次")
+	assert.Equal(t, 1, relay.Stats().Failures)
+}
+
+func TestRelayRunWakesOnCommit(t *testing.T) {
+	s := NewStore()
+	rec := &recorder{published: make(chan Event, 10)}
+	relay := NewRelay(s, rec, WithPollInterval(time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- relay.Run(ctx) }()
+
+	// 轮询间隔很长，提交后仍然立即发布
+	emit(t, s, "a")
+	select {
+	case e := <-rec.published:
+		assert.Equal(t, "a", e.Key)
+	case <-time.After(time.Second):
+		t.Fatal("提交后没有发布")
+	}
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestRelayRunRetriesAfterFailure(t *testing.T) {
+	s := NewStore()
+	emit(t, s, "a")
+	rec := &recorder{failNext: 2, published: make(chan Event, 10)}
+	relay := NewRelay(s, rec, WithPollInterval(5*time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	go relay.Run(ctx)
+
+	select {
+	case e := <-rec.published:
+		assert.Equal(t, uint64(1), e.ID)
+	case <-ctx.Done():
+		t.Fatal("失败后没有重试")
+	}
+	assert.GreaterOrEqual(t, relay.Stats().Failures, 2)
+}
+
+// delivery 构造一次投递，只用于调用 Handler
+func delivery(t *testing.T, env Envelope) *mq.Delivery {
+	body, err := json.Marshal(env)
+	assert.NoError(t, err)
+	return &mq.Delivery{Message: mq.Message{Body: body}}
+}
+
+func TestDeduplicator(t *testing.T) {
+	d := NewDeduplicator(2)
+	var calls []uint64
+	fail := false
+	h := d.Handler(func(ctx context.Context, env Envelope) error {
+		if fail {
+			return errors.New("处理失败")
+		}
+		calls = append(calls, env.EventID)
+		return nil
+	})
+	ctx := context.Background()
+
+	assert.NoError(t, h(ctx, delivery(t, Envelope{EventID: 1})))
+	assert.NoError(t, h(ctx, delivery(t, Envelope{EventID: 1})))
+	assert.Equal(t, []uint64{1}, calls, "重复投递不再处理")
+	assert.Equal(t, 1, d.Duplicates())
+
+	// 处理失败不记录，重新投递后再次处理
+	fail = true
+	assert.Error(t, h(ctx, delivery(t, Envelope{EventID: 2})))
+	fail = false
+	assert.NoError(t, h(ctx, delivery(t, Envelope{EventID: 2})))
+	assert.Equal(t, []uint64{1, 2}, calls)
+
+	// 超过容量后最早的记录被淘汰
+	assert.NoError(t, h(ctx, delivery(t, Envelope{EventID: 3})))
+	assert.NoError(t, h(ctx, delivery(t, Envelope{EventID: 1})))
+	assert.Equal(t, []uint64{1, 2, 3, 1}, calls)
+
+	// 消息体无法解码是永久错误，不会重试
+	err := h(ctx, &mq.Delivery{Message: mq.Message{Body: []byte("not json")}})
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInProgress)
+}
+
+func TestDeduplicatorInProgress(t *testing.T) {
+	d := NewDeduplicator(10)
+	started, release := make(chan struct{}), make(chan struct{})
+	h := d.Handler(func(ctx context.Context, env Envelope) error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	done := make(chan error)
+	go func() { done <- h(context.Background(), delivery(t, Envelope{EventID: 7})) }()
+	<-started
+	// 同一个事件的另一次投递在处理期间到达，稍后重试
+	assert.ErrorIs(t, h(context.Background(), delivery(t, Envelope{EventID: 7})), ErrInProgress)
+	close(release)
+	assert.NoError(t, <-done)
+	assert.NoError(t, h(context.Background(), delivery(t, Envelope{EventID: 7})))
+	assert.Equal(t, 1, d.Duplicates())
+}
+
+func TestEndToEndWithMessageQueue(t *testing.T) {
+	store := NewStore()
+	broker := mq.NewBroker()
+	defer broker.Close()
+	orders, err := broker.Declare("orders", mq.WithRetryDelay(time.Millisecond))
+	assert.NoError(t, err)
+	billing := orders.Group("billing")
+
+	// 每个事件的第一次发布都丢失确认，每个事件都会被投递两次
+	var lost sync.Map
+	publisher := NewMQPublisher(broker)
+	relay := NewRelay(store, PublisherFunc(func(ctx context.Context, e Event) error {
+		if err := publisher.Publish(ctx, e); err != nil {
+			return err
+		}
+		if _, seen := lost.LoadOrStore(e.ID, true); !seen {
+			return errors.New("确认丢失")
+		}
+		return nil
+	}), WithPollInterval(time.Millisecond))
+
+	const orderCount = 20
+	var handled atomic.Int64
+	var mutex sync.Mutex
+	total := map[string]int{}
+	dedup := NewDeduplicator(100)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		relay.Run(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		billing.Consume(ctx, 3, dedup.Handler(func(ctx context.Context, env Envelope) error {
+			var e orderPlaced
+			assert.NoError(t, json.Unmarshal(env.Payload, &e))
+			mutex.Lock()
+			total[e.OrderID] += e.Amount
+			mutex.Unlock()
+			handled.Add(1)
+			return nil
+		}))
+	}()
+
+	for i := 0; i < orderCount; i++ {
+		assert.NoError(t, placeOrder(store, fmt.Sprintf("A-%d", i), 10))
+	}
+	assert.Error(t, placeOrder(store, "A-0", 10), "重复下单被拒绝，不产生事件")
+
+	deadline := time.After(5 * time.Second)
+	for billing.Stats().Acked < 2*orderCount {
+		select {
+		case <-deadline:
+			t.Fatalf("超时：已确认 %d 条", billing.Stats().Acked)
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+	cancel()
+	wg.Wait()
+
+	// 每个事件投递两次，只处理一次
+	assert.Equal(t, int64(orderCount), handled.Load())
+	assert.Equal(t, orderCount, dedup.Duplicates())
+	assert.Len(t, total, orderCount)
+	for id, amount := range total {
+		assert.Equal(t, 10, amount, id)
+	}
+	assert.Equal(t, 0, store.Stats().Pending)
+}
@@ -0,0 +1,149 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Publisher 把事件发布到消息系统
+// 返回 nil 表示消息系统已经确认收到；返回错误时事件留在发件箱中，下一轮重新发布
+type Publisher interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+// PublisherFunc 把普通函数适配为 Publisher
+type PublisherFunc func(ctx context.Context, e Event) error
+
+// Publish 调用函数本身
+func (f PublisherFunc) Publish(ctx context.Context, e Event) error {
+	return f(ctx, e)
+}
+
+// RelayOption 中继配置选项
+type RelayOption func(*Relay)
+
+// WithBatchSize 每轮最多发布的事件数，默认 100
+func WithBatchSize(n int) RelayOption {
+	return func(r *Relay) {
+		if n > 0 {
+			r.batchSize = n
+		}
+	}
+}
+
+// WithPollInterval 轮询发件箱的间隔，默认 1 秒；有新事件提交时会提前醒来
+func WithPollInterval(d time.Duration) RelayOption {
+	return func(r *Relay) {
+		if d > 0 {
+			r.pollInterval = d
+		}
+	}
+}
+
+// WithRelayErrorHandler 发布失败时调用，默认忽略；失败的事件会在下一轮重试
+func WithRelayErrorHandler(fn func(e Event, err error)) RelayOption {
+	return func(r *Relay) {
+		r.onError = fn
+	}
+}
+
+// RelayStats 中继的统计信息
+type RelayStats struct {
+	Rounds    int // 轮询次数
+	Published int // 发布成功的次数，同一个事件重试成功也计入
+	Failures  int // 发布失败的次数
+}
+
+// Relay 消息中继：轮询发件箱，把已提交的事件发布到消息系统，发布成功后从发件箱中移除
+//
+// 发布成功和从发件箱移除不是原子的：发布之后、移除之前崩溃，或者消息系统收到了但确认丢失，
+// 重启后会再次发布同一个事件。因此投递语义是至少一次（At-Least-Once），消费者必须去重。
+// 同一个存储只应运行一个中继，否则同一个事件会被并发发布。
+type Relay struct {
+	store        *Store
+	publisher    Publisher
+	batchSize    int
+	pollInterval time.Duration
+	onError      func(e Event, err error)
+
+	mutex sync.Mutex
+	stats RelayStats
+}
+
+// NewRelay 创建从 store 发布到 publisher 的中继
+func NewRelay(store *Store, publisher Publisher, opts ...RelayOption) *Relay {
+	r := &Relay{
+		store:        store,
+		publisher:    publisher,
+		batchSize:    100,
+		pollInterval: time.Second,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// RunOnce 发布一批事件，返回发布成功的个数
+// 事件按提交顺序发布，遇到失败就停止本轮，保证同一个发件箱中的事件不会乱序
+func (r *Relay) RunOnce(ctx context.Context) (int, error) {
+	r.mutex.Lock()
+	r.stats.Rounds++
+	r.mutex.Unlock()
+
+	published := 0
+	for _, e := range r.store.Pending(r.batchSize) {
+		if err := ctx.Err(); err != nil {
+			return published, err
+		}
+		if err := r.publisher.Publish(ctx, e); err != nil {
+			r.mutex.Lock()
+			r.stats.Failures++
+			r.mutex.Unlock()
+			if r.onError != nil {
+				r.onError(e, err)
+			}
+			return published, fmt.Errorf("发布事件 %d: %w", e.ID, err)
+		}
+		r.mutex.Lock()
+		r.stats.Published++
+		r.mutex.Unlock()
+		if err := r.store.MarkPublished(e.ID); err != nil {
+			return published, err
+		}
+		published++
+	}
+	return published, nil
+}
+
+// Run 持续轮询发布，直到 ctx 结束
+// 发布失败时等待下一次轮询再重试；一批发满时不等待，立即发布下一批
+func (r *Relay) Run(ctx context.Context) error {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		committed := r.store.Committed()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		case <-committed:
+		}
+
+		n, err := r.RunOnce(ctx)
+		next := r.pollInterval
+		if err == nil && n == r.batchSize {
+			next = 0
+		}
+		timer.Reset(next)
+	}
+}
+
+// Stats 返回统计信息
+func (r *Relay) Stats() RelayStats {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.stats
+}
@@ -0,0 +1,197 @@
+package outbox
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 存储相关错误
+var (
+	ErrNotFound      = errors.New("记录不存在")
+	ErrEventNotFound = errors.New("发件箱中没有该事件")
+)
+
+// Event 发件箱中的一条待发布事件
+type Event struct {
+	ID        uint64    // 存储分配的编号，单调递增，消费者据此去重
+	Topic     string    // 发布到的主题，对应消息队列中的队列名
+	Key       string    // 事件所属的实体，例如订单号
+	Payload   []byte    // 事件内容
+	CreatedAt time.Time // 事务提交的时间
+}
+
+// Tx 一个事务：对业务数据的修改和要发布的事件在提交时一起生效，失败时一起丢弃
+type Tx struct {
+	store   *Store
+	writes  map[string][]byte // nil 值表示删除
+	pending []Event
+}
+
+// Get 读取键的值，能读到本事务中尚未提交的写入
+func (tx *Tx) Get(key string) ([]byte, error) {
+	if v, ok := tx.writes[key]; ok {
+		if v == nil {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+		return v, nil
+	}
+	v, ok := tx.store.data[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return v, nil
+}
+
+// Put 写入键值
+func (tx *Tx) Put(key string, value []byte) {
+	if value == nil {
+		value = []byte{}
+	}
+	tx.writes[key] = value
+}
+
+// Delete 删除键
+func (tx *Tx) Delete(key string) {
+	tx.writes[key] = nil
+}
+
+// Emit 把事件写入发件箱，事务提交后由中继发布
+func (tx *Tx) Emit(topic, key string, payload []byte) {
+	tx.pending = append(tx.pending, Event{Topic: topic, Key: key, Payload: payload})
+}
+
+// StoreStats 存储的统计信息
+type StoreStats struct {
+	Commits   int // 提交的事务数
+	Rollbacks int // 回滚的事务数
+	Emitted   int // 写入发件箱的事件数
+	Published int // 已标记为发布的事件数
+	Pending   int // 发件箱中尚未发布的事件数
+}
+
+// Store 进程内的事务性存储，模拟一个同时保存业务表和发件箱表的数据库
+//
+// Update 中的业务修改和 Emit 的事件在同一个事务中提交，要么都生效要么都不生效，
+// 不会出现"数据改了但事件没发"或者"事件发了但数据没改"的情况。
+// 事务串行执行，相当于数据库的可串行化隔离级别。
+type Store struct {
+	mutex  sync.Mutex
+	data   map[string][]byte
+	outbox []Event // 按 ID 排序
+	nextID uint64
+	stats  StoreStats
+	now    func() time.Time
+
+	// notify 有新事件提交时关闭并替换，中继可以据此立即醒来而不必等到下一次轮询
+	notify chan struct{}
+}
+
+// NewStore 创建空的存储
+func NewStore() *Store {
+	return &Store{
+		data:   make(map[string][]byte),
+		now:    time.Now,
+		notify: make(chan struct{}),
+	}
+}
+
+// Update 在事务中执行 fn，fn 返回 nil 时提交，返回错误或 panic 时回滚
+func (s *Store) Update(fn func(tx *Tx) error) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx := &Tx{store: s, writes: make(map[string][]byte)}
+	defer func() {
+		if r := recover(); r != nil {
+			s.stats.Rollbacks++
+			panic(r)
+		}
+	}()
+	if err := fn(tx); err != nil {
+		s.stats.Rollbacks++
+		return err
+	}
+
+	for k, v := range tx.writes {
+		if v == nil {
+			delete(s.data, k)
+		} else {
+			s.data[k] = v
+		}
+	}
+	now := s.now()
+	for _, e := range tx.pending {
+		s.nextID++
+		e.ID, e.CreatedAt = s.nextID, now
+		s.outbox = append(s.outbox, e)
+	}
+	s.stats.Commits++
+	s.stats.Emitted += len(tx.pending)
+	if len(tx.pending) > 0 {
+		close(s.notify)
+		s.notify = make(chan struct{})
+	}
+	return nil
+}
+
+// Get 读取已提交的值
+func (s *Store) Get(key string) ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	v, ok := s.data[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return v, nil
+}
+
+// Snapshot 返回所有已提交数据的副本
+func (s *Store) Snapshot() map[string][]byte {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return maps.Clone(s.data)
+}
+
+// Pending 按提交顺序返回发件箱中最早的 limit 个未发布事件，limit <= 0 表示全部
+func (s *Store) Pending(limit int) []Event {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	n := len(s.outbox)
+	if limit > 0 {
+		n = min(n, limit)
+	}
+	return append([]Event(nil), s.outbox[:n]...)
+}
+
+// MarkPublished 把事件从发件箱中移除
+func (s *Store) MarkPublished(id uint64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	i := sort.Search(len(s.outbox), func(i int) bool { return s.outbox[i].ID >= id })
+	if i == len(s.outbox) || s.outbox[i].ID != id {
+		return fmt.Errorf("%w: %d", ErrEventNotFound, id)
+	}
+	s.outbox = append(s.outbox[:i], s.outbox[i+1:]...)
+	s.stats.Published++
+	return nil
+}
+
+// Committed 返回一个通道，下一次有事件提交时关闭
+func (s *Store) Committed() <-chan struct{} {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.notify
+}
+
+// Stats 返回统计信息
+func (s *Store) Stats() StoreStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	stats := s.stats
+	stats.Pending = len(s.outbox)
+	return stats
+}
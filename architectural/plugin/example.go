@@ -0,0 +1,120 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ConfigPlugin 配置插件：提供键值配置，没有依赖
+type ConfigPlugin struct {
+	values map[string]string
+}
+
+func (p *ConfigPlugin) Name() string { return "config" }
+
+func (p *ConfigPlugin) Init(ctx context.Context, host Host) error {
+	p.values = map[string]string{"greeting": "你好", "punctuation": "！"}
+	fmt.Println("  config: 配置已加载")
+	return nil
+}
+
+// Execute 输入键名，返回配置值
+func (p *ConfigPlugin) Execute(ctx context.Context, input any) (any, error) {
+	key, _ := input.(string)
+	value, ok := p.values[key]
+	if !ok {
+		return nil, fmt.Errorf("配置项 %q 不存在", key)
+	}
+	return value, nil
+}
+
+func (p *ConfigPlugin) Shutdown(ctx context.Context) error {
+	fmt.Println("  config: 已关闭")
+	return nil
+}
+
+// GreeterPlugin 问候插件：依赖 config 插件读取问候语
+type GreeterPlugin struct {
+	config Plugin
+}
+
+func (p *GreeterPlugin) Name() string { return "greeter" }
+
+func (p *GreeterPlugin) Dependencies() []string { return []string{"config"} }
+
+func (p *GreeterPlugin) Init(ctx context.Context, host Host) error {
+	config, err := host.Plugin("config")
+	if err != nil {
+		return err
+	}
+	p.config = config
+	fmt.Println("  greeter: 已就绪")
+	return nil
+}
+
+// Execute 输入名字，返回问候语
+func (p *GreeterPlugin) Execute(ctx context.Context, input any) (any, error) {
+	greeting, err := p.config.Execute(ctx, "greeting")
+	if err != nil {
+		return nil, err
+	}
+	punctuation, err := p.config.Execute(ctx, "punctuation")
+	if err != nil {
+		return nil, err
+	}
+	return fmt.Sprintf("%s，%v%s", greeting, input, punctuation), nil
+}
+
+func (p *GreeterPlugin) Shutdown(ctx context.Context) error {
+	fmt.Println("  greeter: 已关闭")
+	return nil
+}
+
+// UpperPlugin 大写插件：演示执行中的 panic 被隔离
+type UpperPlugin struct{}
+
+func (UpperPlugin) Name() string                              { return "upper" }
+func (UpperPlugin) Init(ctx context.Context, host Host) error { return nil }
+func (UpperPlugin) Shutdown(ctx context.Context) error        { return nil }
+
+// Execute 把字符串转为大写，输入不是字符串时 panic
+func (UpperPlugin) Execute(ctx context.Context, input any) (any, error) {
+	return strings.ToUpper(input.(string)), nil
+}
+
+// RunExample 运行插件架构示例
+func RunExample() {
+	ctx := context.Background()
+	manager := NewManager(nil)
+
+	// 注册顺序与依赖顺序无关，Load 会先初始化 config 再初始化 greeter
+	manager.Register(&GreeterPlugin{})
+	manager.RegisterFactory("config", func() Plugin { return &ConfigPlugin{} })
+	manager.Register(UpperPlugin{})
+	// .so 插件同样注册到注册表中，需要使用 -tags goplugin 编译
+	if err := manager.LoadFile("greeter.so"); err != nil {
+		fmt.Println("跳过 .so 插件:", err)
+	}
+
+	fmt.Println("加载插件:")
+	if err := manager.Load(ctx); err != nil {
+		fmt.Println("加载失败:", err)
+		return
+	}
+	for _, info := range manager.Plugins() {
+		fmt.Printf("  %-8s %-6s 依赖 %v\n", info.Name, info.State, info.Dependencies)
+	}
+
+	fmt.Println("执行插件:")
+	out, _ := manager.Execute(ctx, "greeter", "Gopher")
+	fmt.Println(" ", out)
+	out, _ = manager.Execute(ctx, "upper", "plugin")
+	fmt.Println(" ", out)
+	if _, err := manager.Execute(ctx, "upper", 42); err != nil {
+		fmt.Println("  错误:", err)
+	}
+
+	fmt.Println("关闭插件:")
+	manager.Shutdown(ctx)
+}
@@ -0,0 +1,10 @@
+//go:build !goplugin
+
+package plugin
+
+import "fmt"
+
+// LoadFile 从 .so 文件加载插件，默认构建不支持，需要使用 -tags goplugin 编译
+func (m *Manager) LoadFile(path string) error {
+	return fmt.Errorf("%w: %s（使用 -tags goplugin 编译）", ErrPluginsUnsupported, path)
+}
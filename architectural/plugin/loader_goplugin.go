@@ -0,0 +1,52 @@
+//go:build goplugin
+
+package plugin
+
+import (
+	"fmt"
+	goplugin "plugin"
+)
+
+// SymbolName .so 插件需要导出的符号名
+//
+// 插件以 package main 编译（go build -buildmode=plugin），导出以下两种形式之一：
+//
+//	var Plugin plugin.Plugin = &myPlugin{}
+//	func NewPlugin() plugin.Plugin { return &myPlugin{} }
+//
+// 插件和宿主程序必须使用相同的 Go 版本和依赖版本编译，且只支持 Linux、FreeBSD 和 macOS。
+const (
+	SymbolName    = "Plugin"
+	SymbolFactory = "NewPlugin"
+)
+
+// LoadFile 打开 .so 文件并注册其中导出的插件，之后与其他插件一起由 Load 初始化
+// .so 文件一旦打开就无法卸载，Shutdown 只会调用插件的 Shutdown 方法
+func (m *Manager) LoadFile(path string) error {
+	lib, err := goplugin.Open(path)
+	if err != nil {
+		return fmt.Errorf("打开插件 %s 失败: %w", path, err)
+	}
+
+	if symbol, err := lib.Lookup(SymbolFactory); err == nil {
+		create, ok := symbol.(func() Plugin)
+		if !ok {
+			return fmt.Errorf("%w: %s 的 %s 类型为 %T", ErrNotPlugin, path, SymbolFactory, symbol)
+		}
+		return m.Register(create())
+	}
+
+	symbol, err := lib.Lookup(SymbolName)
+	if err != nil {
+		return fmt.Errorf("%w: %s 没有导出 %s 或 %s", ErrNotPlugin, path, SymbolName, SymbolFactory)
+	}
+	// 导出的变量以指针形式返回
+	switch p := symbol.(type) {
+	case *Plugin:
+		return m.Register(*p)
+	case Plugin:
+		return m.Register(p)
+	default:
+		return fmt.Errorf("%w: %s 的 %s 类型为 %T", ErrNotPlugin, path, SymbolName, symbol)
+	}
+}
@@ -0,0 +1,417 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/XiaoluCoding626/go-design-pattern/behavioral/registry"
+)
+
+// 插件相关错误
+var (
+	ErrDuplicatePlugin    = errors.New("插件已经注册")
+	ErrPluginNotFound     = errors.New("插件不存在")
+	ErrNotPlugin          = errors.New("注册表中的服务不是插件")
+	ErrMissingDependency  = errors.New("插件依赖不存在")
+	ErrDependencyCycle    = errors.New("插件依赖存在循环")
+	ErrInitFailed         = errors.New("插件初始化失败")
+	ErrShutdownFailed     = errors.New("插件关闭失败")
+	ErrPluginPanic        = errors.New("插件发生panic")
+	ErrNotLoaded          = errors.New("插件尚未加载")
+	ErrAlreadyLoaded      = errors.New("插件管理器已经加载")
+	ErrManagerClosed      = errors.New("插件管理器已经关闭")
+	ErrPluginsUnsupported = errors.New("当前构建不支持加载 .so 插件")
+)
+
+// Plugin 插件接口：宿主程序只通过这四个方法与插件交互
+type Plugin interface {
+	Name() string
+	// Init 初始化插件，依赖的插件保证已经初始化完成，可以通过 host 获取
+	Init(ctx context.Context, host Host) error
+	// Execute 执行插件的功能
+	Execute(ctx context.Context, input any) (any, error)
+	// Shutdown 释放插件持有的资源，依赖它的插件保证已经先关闭
+	Shutdown(ctx context.Context) error
+}
+
+// Dependent 声明依赖的插件，可选实现
+type Dependent interface {
+	Dependencies() []string
+}
+
+// Host 插件可以使用的宿主能力
+type Host interface {
+	// Plugin 返回已初始化的插件，插件通常在 Init 中获取自己的依赖
+	Plugin(name string) (Plugin, error)
+}
+
+// State 插件的生命周期状态
+type State int
+
+const (
+	StateRegistered State = iota // 已注册，尚未初始化
+	StateReady                   // 初始化成功，可以执行
+	StateFailed                  // 初始化失败
+	StateStopped                 // 已关闭
+)
+
+// String 返回状态名称
+func (s State) String() string {
+	switch s {
+	case StateRegistered:
+		return "Registered"
+	case StateReady:
+		return "Ready"
+	case StateFailed:
+		return "Failed"
+	case StateStopped:
+		return "Stopped"
+	default:
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+}
+
+// Info 插件的状态信息
+type Info struct {
+	Name         string
+	Dependencies []string
+	State        State
+	Err          error // 初始化或关闭失败的原因
+}
+
+// Manager 插件管理器：从注册表中发现插件，按依赖顺序初始化，逆序关闭
+type Manager struct {
+	registry *registry.Registry
+
+	mutex   sync.RWMutex
+	entries map[string]*entry
+	order   []string // 初始化顺序，只包含初始化成功的插件
+	loaded  bool
+	closed  bool
+	running sync.WaitGroup // 执行中的 Execute 调用，关闭前等待它们返回
+}
+
+// entry 已加载插件的记录
+type entry struct {
+	plugin Plugin
+	deps   []string
+	state  State
+	err    error
+}
+
+// NewManager 创建插件管理器，插件注册到给定的注册表中，reg 为 nil 时使用独立的注册表
+func NewManager(reg *registry.Registry) *Manager {
+	if reg == nil {
+		reg = registry.NewRegistry()
+	}
+	return &Manager{registry: reg, entries: make(map[string]*entry)}
+}
+
+// keyPrefix 插件在注册表中的键前缀，避免与注册表中的其他服务冲突
+const keyPrefix = "plugin:"
+
+// pluginKey 插件在注册表中的键
+func pluginKey(name string) string {
+	return keyPrefix + name
+}
+
+// Register 注册插件实例
+func (m *Manager) Register(p Plugin) error {
+	if p == nil {
+		return fmt.Errorf("不能注册nil插件")
+	}
+	if m.registry.Has(pluginKey(p.Name())) {
+		return fmt.Errorf("%w: %s", ErrDuplicatePlugin, p.Name())
+	}
+	return m.registry.Register(pluginKey(p.Name()), p)
+}
+
+// RegisterFactory 注册插件工厂，插件在 Load 时才创建
+func (m *Manager) RegisterFactory(name string, create func() Plugin) error {
+	if create == nil {
+		return fmt.Errorf("不能注册nil创建函数")
+	}
+	if m.registry.Has(pluginKey(name)) {
+		return fmt.Errorf("%w: %s", ErrDuplicatePlugin, name)
+	}
+	return m.registry.RegisterFactory(pluginKey(name), func() interface{} { return create() })
+}
+
+// Load 从注册表中取出所有插件，按依赖顺序初始化
+//
+// 任意插件初始化失败时，已经初始化的插件按逆序关闭，Load 返回失败原因，
+// 插件的最终状态可以通过 Plugins 查看。Load 只能成功调用一次。
+func (m *Manager) Load(ctx context.Context) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.closed {
+		return ErrManagerClosed
+	}
+	if m.loaded {
+		return ErrAlreadyLoaded
+	}
+
+	entries, err := m.discover()
+	if err != nil {
+		return err
+	}
+	order, err := sortByDependencies(entries)
+	if err != nil {
+		return err
+	}
+	m.entries, m.order = entries, nil
+
+	// 初始化期间持有写锁，插件通过 host 查询时不能再加锁
+	host := lockedHost{m}
+	for _, name := range order {
+		e := m.entries[name]
+		err := guard(name, "Init", func() error { return e.plugin.Init(ctx, host) })
+		if err != nil {
+			e.state, e.err = StateFailed, err
+			rollbackErr := m.shutdownLocked(ctx)
+			return errors.Join(fmt.Errorf("%w: %s: %w", ErrInitFailed, name, err), rollbackErr)
+		}
+		e.state = StateReady
+		m.order = append(m.order, name)
+	}
+
+	m.loaded = true
+	return nil
+}
+
+// discover 从注册表中取出所有插件，调用者需持有锁
+func (m *Manager) discover() (map[string]*entry, error) {
+	entries := make(map[string]*entry)
+	for _, key := range m.registry.Keys() {
+		name, ok := strings.CutPrefix(key, keyPrefix)
+		if !ok {
+			continue
+		}
+		service, err := m.registry.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		p, ok := service.(Plugin)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s (%T)", ErrNotPlugin, key, service)
+		}
+		if p.Name() != name {
+			return nil, fmt.Errorf("%w: 注册名 %s 与插件名 %s 不一致", ErrNotPlugin, name, p.Name())
+		}
+
+		e := &entry{plugin: p}
+		if d, ok := p.(Dependent); ok {
+			e.deps = d.Dependencies()
+		}
+		entries[name] = e
+	}
+	return entries, nil
+}
+
+// sortByDependencies 拓扑排序，依赖排在被依赖者之后；同一层按名称排序，保证顺序稳定
+func sortByDependencies(entries map[string]*entry) ([]string, error) {
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	marks := make(map[string]int, len(entries))
+	order := make([]string, 0, len(entries))
+	var path []string
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch marks[name] {
+		case done:
+			return nil
+		case visiting:
+			// path 中从 name 第一次出现开始的部分就是环
+			for i, n := range path {
+				if n == name {
+					return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(append(path[i:], name), " -> "))
+				}
+			}
+		}
+
+		marks[name] = visiting
+		path = append(path, name)
+		for _, dep := range entries[name].deps {
+			if _, exists := entries[dep]; !exists {
+				return fmt.Errorf("%w: %s 依赖 %s", ErrMissingDependency, name, dep)
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		marks[name] = done
+		order = append(order, name)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// Execute 执行插件
+func (m *Manager) Execute(ctx context.Context, name string, input any) (output any, err error) {
+	m.mutex.RLock()
+	if m.closed {
+		m.mutex.RUnlock()
+		return nil, ErrManagerClosed
+	}
+	e, err := m.readyLocked(name)
+	if err != nil {
+		m.mutex.RUnlock()
+		return nil, err
+	}
+	// 在锁内登记，保证 Shutdown 等待所有已经开始的调用
+	m.running.Add(1)
+	m.mutex.RUnlock()
+	defer m.running.Done()
+
+	err = guard(name, "Execute", func() error {
+		output, err = e.plugin.Execute(ctx, input)
+		return err
+	})
+	return output, err
+}
+
+// Plugin 返回已初始化的插件，实现 Host 接口
+func (m *Manager) Plugin(name string) (Plugin, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	e, err := m.readyLocked(name)
+	if err != nil {
+		return nil, err
+	}
+	return e.plugin, nil
+}
+
+// readyLocked 返回处于 Ready 状态的插件，调用者需持有锁
+func (m *Manager) readyLocked(name string) (*entry, error) {
+	e, exists := m.entries[name]
+	if !exists {
+		if m.registry.Has(pluginKey(name)) {
+			return nil, fmt.Errorf("%w: %s", ErrNotLoaded, name)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrPluginNotFound, name)
+	}
+	if e.state != StateReady {
+		return nil, fmt.Errorf("%w: %s 处于 %s 状态", ErrNotLoaded, name, e.state)
+	}
+	return e, nil
+}
+
+// Plugins 返回所有插件的状态，按初始化顺序排列，未能初始化的插件按名称排在最后
+func (m *Manager) Plugins() []Info {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	infos := make([]Info, 0, len(m.entries))
+	seen := make(map[string]bool, len(m.order))
+	for _, name := range m.order {
+		infos = append(infos, m.entries[name].info(name))
+		seen[name] = true
+	}
+
+	var rest []string
+	for name := range m.entries {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	for _, name := range rest {
+		infos = append(infos, m.entries[name].info(name))
+	}
+	return infos
+}
+
+// info 生成状态信息
+func (e *entry) info(name string) Info {
+	return Info{
+		Name:         name,
+		Dependencies: append([]string(nil), e.deps...),
+		State:        e.state,
+		Err:          e.err,
+	}
+}
+
+// Shutdown 等待执行中的调用返回，然后按初始化的逆序关闭插件
+// 某个插件关闭失败不影响其他插件关闭，所有错误合并返回
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mutex.Lock()
+	if m.closed {
+		m.mutex.Unlock()
+		return nil
+	}
+	m.closed = true
+	m.mutex.Unlock()
+
+	// closed 之后不会再有新的 Execute 登记
+	m.running.Wait()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.shutdownLocked(ctx)
+}
+
+// shutdownLocked 按逆序关闭处于 Ready 状态的插件，调用者需持有锁
+func (m *Manager) shutdownLocked(ctx context.Context) error {
+	var errs []error
+	for i := len(m.order) - 1; i >= 0; i-- {
+		name := m.order[i]
+		e := m.entries[name]
+		if e.state != StateReady {
+			continue
+		}
+		err := guard(name, "Shutdown", func() error { return e.plugin.Shutdown(ctx) })
+		e.state = StateStopped
+		if err != nil {
+			e.err = err
+			errs = append(errs, fmt.Errorf("%w: %s: %w", ErrShutdownFailed, name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// guard 调用插件代码，把 panic 转换为错误，避免一个插件拖垮宿主程序
+func guard(name, stage string, fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %s.%s: %v", ErrPluginPanic, name, stage, r)
+		}
+	}()
+	return fn()
+}
+
+// lockedHost 初始化期间交给插件的 Host，管理器的写锁已被 Load 持有
+type lockedHost struct {
+	m *Manager
+}
+
+// Plugin 返回已初始化的插件
+func (h lockedHost) Plugin(name string) (Plugin, error) {
+	e, err := h.m.readyLocked(name)
+	if err != nil {
+		return nil, err
+	}
+	return e.plugin, nil
+}
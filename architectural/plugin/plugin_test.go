@@ -0,0 +1,214 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/XiaoluCoding626/go-design-pattern/behavioral/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePlugin 可配置行为的测试插件，把生命周期调用记录到共享日志中
+type fakePlugin struct {
+	name        string
+	deps        []string
+	initErr     error
+	shutdownErr error
+	execute     func(ctx context.Context, input any) (any, error)
+	log         *[]string
+}
+
+func (p *fakePlugin) Name() string           { return p.name }
+func (p *fakePlugin) Dependencies() []string { return p.deps }
+
+func (p *fakePlugin) Init(ctx context.Context, host Host) error {
+	*p.log = append(*p.log, "init "+p.name)
+	for _, dep := range p.deps {
+		if _, err := host.Plugin(dep); err != nil {
+			return err
+		}
+	}
+	return p.initErr
+}
+
+func (p *fakePlugin) Execute(ctx context.Context, input any) (any, error) {
+	if p.execute != nil {
+		return p.execute(ctx, input)
+	}
+	return p.name, nil
+}
+
+func (p *fakePlugin) Shutdown(ctx context.Context) error {
+	*p.log = append(*p.log, "shutdown "+p.name)
+	return p.shutdownErr
+}
+
+// TestDependencyOrder 测试按依赖顺序初始化、逆序关闭
+func TestDependencyOrder(t *testing.T) {
+	var log []string
+	m := NewManager(nil)
+	assert.NoError(t, m.Register(&fakePlugin{name: "api", deps: []string{"cache", "db"}, log: &log}))
+	assert.NoError(t, m.Register(&fakePlugin{name: "cache", deps: []string{"db"}, log: &log}))
+	assert.NoError(t, m.RegisterFactory("db", func() Plugin { return &fakePlugin{name: "db", log: &log} }))
+	assert.NoError(t, m.Register(&fakePlugin{name: "metrics", log: &log}))
+	assert.ErrorIs(t, m.Register(&fakePlugin{name: "db", log: &log}), ErrDuplicatePlugin)
+
+	_, err := m.Execute(context.Background(), "db", nil)
+	assert.ErrorIs(t, err, ErrNotLoaded, "Load 之前不能执行")
+
+	assert.NoError(t, m.Load(context.Background()))
+	assert.ErrorIs(t, m.Load(context.Background()), ErrAlreadyLoaded)
+	assert.Equal(t, []string{"init db", "init cache", "init api", "init metrics"}, log)
+
+	var names []string
+	for _, info := range m.Plugins() {
+		names = append(names, info.Name)
+		assert.Equal(t, StateReady, info.State)
+	}
+	assert.Equal(t, []string{"db", "cache", "api", "metrics"}, names)
+
+	out, err := m.Execute(context.Background(), "api", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "api", out)
+	_, err = m.Execute(context.Background(), "missing", nil)
+	assert.ErrorIs(t, err, ErrPluginNotFound)
+
+	log = nil
+	assert.NoError(t, m.Shutdown(context.Background()))
+	assert.Equal(t, []string{"shutdown metrics", "shutdown api", "shutdown cache", "shutdown db"}, log)
+	assert.NoError(t, m.Shutdown(context.Background()), "重复关闭是安全的")
+
+	_, err = m.Execute(context.Background(), "api", nil)
+	assert.ErrorIs(t, err, ErrManagerClosed)
+	assert.ErrorIs(t, m.Load(context.Background()), ErrManagerClosed)
+}
+
+// TestDependencyErrors 测试缺失依赖和循环依赖在初始化任何插件之前被发现
+func TestDependencyErrors(t *testing.T) {
+	var log []string
+	m := NewManager(nil)
+	m.Register(&fakePlugin{name: "a", deps: []string{"missing"}, log: &log})
+	assert.ErrorIs(t, m.Load(context.Background()), ErrMissingDependency)
+
+	m = NewManager(nil)
+	m.Register(&fakePlugin{name: "a", deps: []string{"b"}, log: &log})
+	m.Register(&fakePlugin{name: "b", deps: []string{"c"}, log: &log})
+	m.Register(&fakePlugin{name: "c", deps: []string{"a"}, log: &log})
+	m.Register(&fakePlugin{name: "d", log: &log})
+	err := m.Load(context.Background())
+	assert.ErrorIs(t, err, ErrDependencyCycle)
+	assert.ErrorContains(t, err, "a -> b -> c -> a")
+	assert.Empty(t, log, "排序失败时不初始化任何插件")
+}
+
+// TestInitFailureRollback 测试初始化失败时已初始化的插件被逆序关闭
+func TestInitFailureRollback(t *testing.T) {
+	var log []string
+	boom := errors.New("连接失败")
+	m := NewManager(nil)
+	m.Register(&fakePlugin{name: "db", log: &log})
+	m.Register(&fakePlugin{name: "cache", deps: []string{"db"}, log: &log})
+	m.Register(&fakePlugin{name: "search", deps: []string{"db"}, initErr: boom, log: &log})
+	m.Register(&fakePlugin{name: "web", deps: []string{"search"}, log: &log})
+
+	err := m.Load(context.Background())
+	assert.ErrorIs(t, err, ErrInitFailed)
+	assert.ErrorIs(t, err, boom)
+	assert.ErrorContains(t, err, "search")
+	assert.Equal(t, []string{"init db", "init cache", "init search", "shutdown cache", "shutdown db"}, log)
+
+	states := map[string]State{}
+	for _, info := range m.Plugins() {
+		states[info.Name] = info.State
+	}
+	assert.Equal(t, map[string]State{
+		"db": StateStopped, "cache": StateStopped, "search": StateFailed, "web": StateRegistered,
+	}, states)
+
+	log = nil
+	assert.NoError(t, m.Shutdown(context.Background()))
+	assert.Empty(t, log, "已经关闭的插件不会再次关闭")
+}
+
+// TestPanicsAndShutdownErrors 测试插件 panic 被转换为错误，关闭失败不影响其他插件
+func TestPanicsAndShutdownErrors(t *testing.T) {
+	var log []string
+	m := NewManager(nil)
+	m.Register(&fakePlugin{name: "a", shutdownErr: errors.New("刷盘失败"), log: &log})
+	m.Register(&fakePlugin{name: "b", log: &log, execute: func(ctx context.Context, input any) (any, error) {
+		panic("坏插件")
+	}})
+	assert.NoError(t, m.Load(context.Background()))
+
+	_, err := m.Execute(context.Background(), "b", nil)
+	assert.ErrorIs(t, err, ErrPluginPanic)
+	assert.ErrorContains(t, err, "b.Execute: 坏插件")
+
+	err = m.Shutdown(context.Background())
+	assert.ErrorIs(t, err, ErrShutdownFailed)
+	assert.ErrorContains(t, err, "刷盘失败")
+	assert.Equal(t, []string{"init a", "init b", "shutdown b", "shutdown a"}, log)
+	assert.EqualError(t, m.Plugins()[0].Err, "刷盘失败")
+}
+
+// TestShutdownWaitsForExecute 测试关闭等待执行中的调用返回
+func TestShutdownWaitsForExecute(t *testing.T) {
+	var log []string
+	started, release := make(chan struct{}), make(chan struct{})
+	m := NewManager(nil)
+	m.Register(&fakePlugin{name: "slow", log: &log, execute: func(ctx context.Context, input any) (any, error) {
+		close(started)
+		<-release
+		return nil, nil
+	}})
+	assert.NoError(t, m.Load(context.Background()))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		m.Execute(context.Background(), "slow", nil)
+	}()
+	<-started
+
+	done := make(chan error)
+	go func() { done <- m.Shutdown(context.Background()) }()
+	select {
+	case <-done:
+		t.Fatal("执行中的调用返回之前不应关闭")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	assert.NoError(t, <-done)
+	wg.Wait()
+	assert.Equal(t, []string{"init slow", "shutdown slow"}, log)
+}
+
+// TestSharedRegistry 测试插件与其他服务共享注册表
+func TestSharedRegistry(t *testing.T) {
+	reg := registry.NewRegistry()
+	assert.NoError(t, reg.Register("db", "不是插件的服务"))
+	assert.NoError(t, reg.Register(pluginKey("bad"), "不是插件"))
+
+	m := NewManager(reg)
+	assert.ErrorIs(t, m.Load(context.Background()), ErrNotPlugin)
+
+	reg.Unregister(pluginKey("bad"))
+	var log []string
+	m.RegisterFactory("alias", func() Plugin { return &fakePlugin{name: "real", log: &log} })
+	assert.ErrorIs(t, m.Load(context.Background()), ErrNotPlugin, "注册名必须与插件名一致")
+
+	reg.Unregister(pluginKey("alias"))
+	m.Register(&fakePlugin{name: "real", log: &log})
+	assert.NoError(t, m.Load(context.Background()), "前缀以外的服务被忽略")
+	assert.Len(t, m.Plugins(), 1)
+}
+
+// TestLoadFileUnsupported 测试默认构建不支持 .so 插件
+func TestLoadFileUnsupported(t *testing.T) {
+	assert.ErrorIs(t, NewManager(nil).LoadFile("x.so"), ErrPluginsUnsupported)
+}
@@ -0,0 +1,258 @@
+package wal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/XiaoluCoding626/go-design-pattern/architectural/cqrs"
+	"github.com/XiaoluCoding626/go-design-pattern/behavioral/command"
+	"github.com/XiaoluCoding626/go-design-pattern/behavioral/observer"
+)
+
+// journalEntry 命令事件在日志中的格式；CommandEvent.Err 是接口，保存为错误信息
+type journalEntry struct {
+	Type      command.CommandEventType `json:"type"`
+	Command   string                   `json:"command"`
+	Principal string                   `json:"principal"`
+	Time      time.Time                `json:"time"`
+	Err       string                   `json:"err,omitempty"`
+}
+
+// commandJournal 命令日志：作为遥控器的 EventPublisher，把每个命令事件追加到预写日志
+// Publish 没有返回值，追加失败交给 onError
+type commandJournal struct {
+	log     *Log
+	onError func(error)
+}
+
+// Publish 把命令事件编码为 JSON 追加到日志
+func (j *commandJournal) Publish(event command.CommandEvent) {
+	entry := journalEntry{
+		Type:      event.Type,
+		Command:   event.Command,
+		Principal: event.Principal,
+		Time:      event.Time,
+	}
+	if event.Err != nil {
+		entry.Err = event.Err.Error()
+	}
+	data, err := json.Marshal(entry)
+	if err == nil {
+		_, err = j.log.Append(data)
+	}
+	if err != nil && j.onError != nil {
+		j.onError(err)
+	}
+}
+
+// replayJournal 回放命令日志，把事件还原为 CommandEvent 交给 fn
+func replayJournal(log *Log, from uint64, fn func(uint64, command.CommandEvent)) (uint64, error) {
+	return log.Replay(from, func(rec Record) error {
+		var entry journalEntry
+		if err := json.Unmarshal(rec.Data, &entry); err != nil {
+			return fmt.Errorf("解码偏移量 %d: %w", rec.Offset, err)
+		}
+		event := command.CommandEvent{
+			Type:      entry.Type,
+			Command:   entry.Command,
+			Principal: entry.Principal,
+			Time:      entry.Time,
+		}
+		if entry.Err != "" {
+			event.Err = errors.New(entry.Err)
+		}
+		fn(rec.Offset, event)
+		return nil
+	})
+}
+
+// appliedCommands 从命令事件重建仍然生效的命令：成功执行的入栈，成功撤销的出栈
+func appliedCommands(events []command.CommandEvent) []string {
+	var applied []string
+	for _, e := range events {
+		switch {
+		case !e.Succeeded():
+		case e.Type == command.CommandExecuted:
+			applied = append(applied, e.Command)
+		case e.Type == command.CommandUndone && len(applied) > 0:
+			applied = applied[:len(applied)-1]
+		}
+	}
+	return applied
+}
+
+// eventEntry 领域事件在日志中的格式
+type eventEntry struct {
+	Symbol    string    `json:"symbol"`
+	Price     float64   `json:"price"`
+	PrevPrice float64   `json:"prev_price"`
+	Time      time.Time `json:"time"`
+	Message   string    `json:"message"`
+}
+
+// eventJournal 事件存储：订阅 CQRS 写模型发布的领域事件，按发布顺序追加到预写日志
+// 读模型丢失或重建时，从日志回放即可恢复，不依赖写模型重新发布
+type eventJournal struct {
+	log     *Log
+	onError func(error)
+}
+
+// GetID 返回订阅者标识
+func (j *eventJournal) GetID() string {
+	return "wal-event-journal"
+}
+
+// Update 把领域事件编码为 JSON 追加到日志
+func (j *eventJournal) Update(event observer.StockEvent, message string) {
+	data, err := json.Marshal(eventEntry{
+		Symbol:    event.Symbol,
+		Price:     event.Price,
+		PrevPrice: event.PrevPrice,
+		Time:      event.Timestamp,
+		Message:   message,
+	})
+	if err == nil {
+		_, err = j.log.Append(data)
+	}
+	if err != nil && j.onError != nil {
+		j.onError(err)
+	}
+}
+
+// replayEvents 从 from 开始回放领域事件，依次交给各个读模型，返回下一次回放的起点
+func replayEvents(log *Log, from uint64, models ...observer.Observer) (uint64, error) {
+	return log.Replay(from, func(rec Record) error {
+		var entry eventEntry
+		if err := json.Unmarshal(rec.Data, &entry); err != nil {
+			return fmt.Errorf("解码偏移量 %d: %w", rec.Offset, err)
+		}
+		event := observer.StockEvent{
+			Symbol:    entry.Symbol,
+			Price:     entry.Price,
+			PrevPrice: entry.PrevPrice,
+			Timestamp: entry.Time,
+		}
+		for _, model := range models {
+			model.Update(event, entry.Message)
+		}
+		return nil
+	})
+}
+
+// runEventStoreExample 把 CQRS 的领域事件写入日志，再从日志重建一个新的读模型
+func runEventStoreExample(dir string) {
+	log, err := Open(dir)
+	if err != nil {
+		fmt.Println("打开日志失败:", err)
+		return
+	}
+	defer log.Close()
+
+	system, err := cqrs.NewSystem(nil)
+	if err != nil {
+		fmt.Println("初始化 CQRS 系统失败:", err)
+		return
+	}
+	system.Subscribe(&eventJournal{log: log, onError: func(err error) {
+		fmt.Println("  写入事件日志失败:", err)
+	}})
+
+	ctx := context.Background()
+	for _, cmd := range []cqrs.Command{
+		cqrs.ListStock{Symbol: "AAPL", Price: 180},
+		cqrs.ListStock{Symbol: "TSLA", Price: 250},
+		cqrs.ChangePrice{Symbol: "AAPL", Price: 198, Reason: "财报超预期"},
+		cqrs.ChangePrice{Symbol: "MSFT", Price: 420, Reason: "未上市"},
+	} {
+		if err := system.Dispatch(ctx, cmd); err != nil {
+			fmt.Printf("  命令 %s 被拒绝，不产生事件: %v\n", cmd.CommandName(), err)
+		}
+	}
+	system.Close() // 等待事件投递到所有订阅者，包括事件日志
+	fmt.Printf("  写模型发布 %d 个事件，日志中 %d 条记录\n", system.Exchange.Version(), log.NextOffset())
+
+	board := cqrs.NewPriceBoard("rebuilt")
+	if _, err := replayEvents(log, 0, board); err != nil {
+		fmt.Println("回放失败:", err)
+		return
+	}
+	for _, symbol := range board.Symbols() {
+		quote, _ := board.Quote(symbol)
+		fmt.Printf("  重建的行情: %s %.2f（%s）\n", quote.Symbol, quote.Price, quote.Reason)
+	}
+}
+
+// RunExample 运行预写日志示例：遥控器的命令事件和 CQRS 的领域事件写入日志，"重启"后从日志回放
+func RunExample() {
+	dir, err := os.MkdirTemp("", "wal-example-")
+	if err != nil {
+		fmt.Println("创建临时目录失败:", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	// 段设得很小，便于观察滚动
+	log, err := Open(dir, WithSegmentSize(512), WithSyncInterval(10*time.Millisecond))
+	if err != nil {
+		fmt.Println("打开日志失败:", err)
+		return
+	}
+
+	fmt.Println("== 命令事件写入日志 ==")
+	light := command.NewLight("客厅灯")
+	remote := command.NewRemoteControl(2)
+	remote.BindPrincipal(command.NewPrincipal("小明", command.RoleMember))
+	remote.SetCommand(0, command.NewTurnOnCommand(light), command.NewTurnOffCommand(light))
+	remote.SetEventPublisher(&commandJournal{log: log, onError: func(err error) {
+		fmt.Println("  写入命令日志失败:", err)
+	}})
+
+	remote.OnButtonPressed(0)
+	remote.OnButtonPressed(0) // 重复开灯被前置条件拒绝，不会执行，也不产生事件
+	remote.OffButtonPressed(0)
+	remote.UndoLastCommand()
+	remote.OffButtonPressed(0)
+	remote.OnButtonPressed(0)
+	fmt.Printf("  共 %d 条记录，%d 个段\n", log.NextOffset(), log.Segments())
+	log.Close()
+
+	fmt.Println("== 重新打开日志并回放 ==")
+	log, err = Open(dir)
+	if err != nil {
+		fmt.Println("打开日志失败:", err)
+		return
+	}
+	defer log.Close()
+
+	var events []command.CommandEvent
+	next, err := replayJournal(log, 0, func(offset uint64, e command.CommandEvent) {
+		events = append(events, e)
+		fmt.Printf("  #%d %s\n", offset, e)
+	})
+	if err != nil {
+		fmt.Println("回放失败:", err)
+		return
+	}
+	fmt.Println("  仍然生效的命令:", appliedCommands(events))
+
+	fmt.Println("== 从检查点继续回放 ==")
+	light2 := command.NewLight("卧室灯")
+	remote.SetCommand(1, command.NewTurnOnCommand(light2), command.NewTurnOffCommand(light2))
+	remote.SetEventPublisher(&commandJournal{log: log})
+	remote.OnButtonPressed(1)
+	replayJournal(log, next, func(offset uint64, e command.CommandEvent) {
+		fmt.Printf("  #%d %s\n", offset, e)
+	})
+
+	fmt.Println("== 删除检查点之前的段 ==")
+	log.TruncateFront(next)
+	fmt.Printf("  保留的记录从 #%d 开始，%d 个段\n", log.FirstOffset(), log.Segments())
+
+	fmt.Println("== CQRS 领域事件写入日志并重建读模型 ==")
+	runEventStoreExample(filepath.Join(dir, "events"))
+}
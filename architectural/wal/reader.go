@@ -0,0 +1,150 @@
+package wal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// Reader 从指定偏移量开始顺序读取日志
+// 读取范围在创建时确定，之后追加的记录不会被读到；读取方式与 bufio.Scanner 相同：
+//
+//	r, err := log.NewReader(offset)
+//	defer r.Close()
+//	for r.Next() {
+//	    rec := r.Record()
+//	}
+//	err = r.Err()
+type Reader struct {
+	dir      string
+	segments []uint64 // 需要读取的段
+	end      uint64   // 读取到这个偏移量为止（不含）
+
+	file   *os.File
+	buf    *bufio.Reader
+	seg    int    // 正在读取的段在 segments 中的下标
+	offset uint64 // 下一条要读取的记录的偏移量
+	skip   uint64 // 到达起始偏移量之前要跳过的记录数
+	record Record
+	err    error
+}
+
+// NewReader 创建从 from 开始读取的 Reader
+// from 早于最早的记录（已被 TruncateFront 删除）或晚于日志末尾时返回 ErrOffsetOutOfRange
+func (l *Log) NewReader(from uint64) (*Reader, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed {
+		return nil, ErrClosed
+	}
+	if from < l.segments[0] || from > l.next {
+		return nil, fmt.Errorf("%w: %d 不在 [%d, %d] 中", ErrOffsetOutOfRange, from, l.segments[0], l.next)
+	}
+
+	// 找到包含 from 的段：起始偏移量不大于 from 的最后一个段
+	i := sort.Search(len(l.segments), func(i int) bool { return l.segments[i] > from }) - 1
+	return &Reader{
+		dir:      l.dir,
+		segments: append([]uint64(nil), l.segments[i:]...),
+		end:      l.next,
+		seg:      -1,
+		offset:   l.segments[i],
+		skip:     from - l.segments[i],
+	}, nil
+}
+
+// Next 读取下一条记录，没有更多记录或出错时返回 false
+func (r *Reader) Next() bool {
+	for r.err == nil && r.offset < r.end {
+		if r.file == nil && !r.openNext() {
+			return false
+		}
+		data, err := readRecord(r.buf)
+		if err == io.EOF {
+			// 段读完了，继续读下一个段
+			r.file.Close()
+			r.file = nil
+			continue
+		}
+		if err != nil {
+			if errors.Is(err, errTorn) {
+				err = fmt.Errorf("%w: %v", ErrCorrupt, err)
+			}
+			r.err = fmt.Errorf("读取偏移量 %d: %w", r.offset, err)
+			return false
+		}
+		offset := r.offset
+		r.offset++
+		if r.skip > 0 {
+			r.skip--
+			continue
+		}
+		r.record = Record{Offset: offset, Data: data}
+		return true
+	}
+	return false
+}
+
+// openNext 打开下一个段，段的起始偏移量必须与已读到的位置衔接
+func (r *Reader) openNext() bool {
+	r.seg++
+	if r.seg >= len(r.segments) {
+		r.err = fmt.Errorf("%w: 偏移量 %d 之后的段缺失", ErrCorrupt, r.offset)
+		return false
+	}
+	if base := r.segments[r.seg]; base != r.offset {
+		r.err = fmt.Errorf("%w: 段 %s 应从偏移量 %d 开始", ErrCorrupt, segmentName(base), r.offset)
+		return false
+	}
+	file, err := os.Open(segmentPath(r.dir, r.segments[r.seg]))
+	if err != nil {
+		r.err = err
+		return false
+	}
+	r.file = file
+	r.buf = bufio.NewReader(file)
+	return true
+}
+
+// Record 返回 Next 读到的记录，Data 归调用方所有
+func (r *Reader) Record() Record {
+	return r.record
+}
+
+// Err 返回读取过程中遇到的错误，正常读完时返回 nil
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// Close 关闭正在读取的段文件
+func (r *Reader) Close() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// Replay 从 from 开始按顺序把记录交给 fn，用于启动时重建状态
+// fn 返回错误时停止回放并返回该错误，返回时最后处理成功的记录之后的偏移量可以作为下一次回放的起点
+func (l *Log) Replay(from uint64, fn func(Record) error) (next uint64, err error) {
+	r, err := l.NewReader(from)
+	if err != nil {
+		return from, err
+	}
+	defer r.Close()
+
+	next = from
+	for r.Next() {
+		rec := r.Record()
+		if err := fn(rec); err != nil {
+			return next, err
+		}
+		next = rec.Offset + 1
+	}
+	return next, r.Err()
+}
@@ -0,0 +1,132 @@
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MaxRecordSize 单条记录的最大字节数
+const MaxRecordSize = 16 << 20
+
+const (
+	headerSize  = 8      // 记录头：4 字节长度 + 4 字节 CRC
+	segmentExt  = ".wal" // 段文件扩展名
+	segmentPerm = os.FileMode(0o644)
+)
+
+// crcTable 记录校验使用 Castagnoli 多项式，现代 CPU 有硬件指令支持
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// errTorn 记录不完整，只可能出现在最后一个段的末尾（写入时崩溃）
+var errTorn = errors.New("记录不完整")
+
+// segmentName 返回段文件名，文件名是段中第一条记录的偏移量，补零后按字典序即按偏移量排序
+func segmentName(base uint64) string {
+	return fmt.Sprintf("%020d%s", base, segmentExt)
+}
+
+// listSegments 返回目录中所有段的起始偏移量，按升序排列
+func listSegments(dir string) ([]uint64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var bases []uint64
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		base, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		bases = append(bases, base)
+	}
+	sort.Slice(bases, func(i, j int) bool { return bases[i] < bases[j] })
+	return bases, nil
+}
+
+// encodeRecord 编码一条记录：长度 | CRC | 数据，长度和 CRC 都是小端序
+func encodeRecord(data []byte) []byte {
+	buf := make([]byte, headerSize+len(data))
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(len(data)))
+	binary.LittleEndian.PutUint32(buf[4:8], crc32.Checksum(data, crcTable))
+	copy(buf[headerSize:], data)
+	return buf
+}
+
+// readRecord 读取一条记录
+// 段正好结束时返回 io.EOF，记录被截断时返回 errTorn，校验失败时返回 ErrCorrupt
+func readRecord(r *bufio.Reader) ([]byte, error) {
+	var header [headerSize]byte
+	if n, err := io.ReadFull(r, header[:]); err != nil {
+		if n == 0 && err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, errTorn
+	}
+	size := binary.LittleEndian.Uint32(header[0:4])
+	if size > MaxRecordSize {
+		return nil, fmt.Errorf("%w: 记录长度 %d 超过上限", ErrCorrupt, size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, errTorn
+	}
+	if crc32.Checksum(data, crcTable) != binary.LittleEndian.Uint32(header[4:8]) {
+		return nil, fmt.Errorf("%w: 校验和不匹配", ErrCorrupt)
+	}
+	return data, nil
+}
+
+// recoverSegment 扫描段文件，返回完整记录的条数，并截掉末尾不完整或校验失败的部分
+// 只对最后一个段调用：崩溃时只有最后一个段可能写了一半，截断后从这里继续追加
+func recoverSegment(path string) (records uint64, size int64, err error) {
+	file, err := os.OpenFile(path, os.O_RDWR, segmentPerm)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	for {
+		data, err := readRecord(r)
+		if err == io.EOF {
+			return records, size, nil
+		}
+		if err != nil {
+			// 不完整或损坏的尾部：丢弃它以及之后的所有内容
+			if err := file.Truncate(size); err != nil {
+				return 0, 0, err
+			}
+			return records, size, file.Sync()
+		}
+		records++
+		size += int64(headerSize + len(data))
+	}
+}
+
+// syncDir 同步目录，使新建或删除的段文件在崩溃后仍然可见
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// segmentPath 返回段文件的完整路径
+func segmentPath(dir string, base uint64) string {
+	return filepath.Join(dir, segmentName(base))
+}
@@ -0,0 +1,336 @@
+package wal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// 日志相关错误
+var (
+	ErrClosed           = errors.New("日志已关闭")
+	ErrCorrupt          = errors.New("日志记录已损坏")
+	ErrRecordTooLarge   = errors.New("记录超过大小上限")
+	ErrOffsetOutOfRange = errors.New("偏移量超出日志范围")
+)
+
+// SyncPolicy 决定追加的记录何时刷到磁盘（fsync）
+type SyncPolicy int
+
+const (
+	SyncAlways   SyncPolicy = iota // 每次追加后刷盘，Append 返回即持久化，最慢
+	SyncInterval                   // 后台定时刷盘，崩溃时最多丢失一个间隔内的记录
+	SyncNever                      // 只写入操作系统缓存，由操作系统决定何时落盘，最快
+)
+
+// String 返回刷盘策略的名称
+func (p SyncPolicy) String() string {
+	switch p {
+	case SyncAlways:
+		return "always"
+	case SyncInterval:
+		return "interval"
+	case SyncNever:
+		return "never"
+	default:
+		return fmt.Sprintf("SyncPolicy(%d)", int(p))
+	}
+}
+
+// Option 日志配置选项
+type Option func(*config)
+
+// config 日志配置
+type config struct {
+	segmentSize  int64
+	syncPolicy   SyncPolicy
+	syncInterval time.Duration
+}
+
+// WithSegmentSize 单个段文件的大小上限，写满后滚动到新的段，默认 64 MiB
+// 超过上限的单条记录会独占一个段
+func WithSegmentSize(n int64) Option {
+	return func(c *config) {
+		if n > 0 {
+			c.segmentSize = n
+		}
+	}
+}
+
+// WithSyncPolicy 设置刷盘策略，默认 SyncAlways
+func WithSyncPolicy(p SyncPolicy) Option {
+	return func(c *config) {
+		c.syncPolicy = p
+	}
+}
+
+// WithSyncInterval 使用 SyncInterval 策略，每隔 d 刷盘一次，默认 100 毫秒
+func WithSyncInterval(d time.Duration) Option {
+	return func(c *config) {
+		c.syncPolicy = SyncInterval
+		if d > 0 {
+			c.syncInterval = d
+		}
+	}
+}
+
+// Record 日志中的一条记录
+type Record struct {
+	Offset uint64 // 记录在日志中的序号，从 0 开始连续递增
+	Data   []byte
+}
+
+// Log 只追加的预写日志（Write-Ahead Log）
+//
+// 记录按顺序写入一组段文件，每条记录带有长度和 CRC 校验；段写满后滚动到新的段，
+// 文件名是段中第一条记录的偏移量。打开时检查最后一个段，截掉崩溃时写了一半的尾部。
+// 所有方法都可以并发调用。
+type Log struct {
+	dir    string
+	config config
+
+	mutex    sync.Mutex
+	segments []uint64 // 各个段的起始偏移量，最后一个是正在写入的段
+	file     *os.File // 正在写入的段
+	size     int64    // 正在写入的段的字节数
+	next     uint64   // 下一条记录的偏移量
+	dirty    bool     // 有尚未刷盘的记录
+	syncErr  error    // 后台刷盘失败的错误，由 Sync 和 Close 返回
+	closed   bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Open 打开目录中的日志，目录不存在时创建
+// 最后一个段末尾不完整或校验失败的记录被视为崩溃时未写完，截断后从那里继续追加
+func Open(dir string, opts ...Option) (*Log, error) {
+	cfg := config{
+		segmentSize:  64 << 20,
+		syncPolicy:   SyncAlways,
+		syncInterval: 100 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	l := &Log{dir: dir, config: cfg}
+	segments, err := listSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 0 {
+		if err := l.createSegmentLocked(0); err != nil {
+			return nil, err
+		}
+	} else {
+		last := segments[len(segments)-1]
+		records, size, err := recoverSegment(segmentPath(dir, last))
+		if err != nil {
+			return nil, fmt.Errorf("恢复段 %s: %w", segmentName(last), err)
+		}
+		file, err := os.OpenFile(segmentPath(dir, last), os.O_WRONLY|os.O_APPEND, segmentPerm)
+		if err != nil {
+			return nil, err
+		}
+		l.segments, l.file, l.size, l.next = segments, file, size, last+records
+	}
+
+	if cfg.syncPolicy == SyncInterval {
+		l.stop = make(chan struct{})
+		l.done = make(chan struct{})
+		go l.syncLoop()
+	}
+	return l, nil
+}
+
+// Append 追加一条记录，返回它的偏移量
+// SyncAlways 策略下返回时记录已经刷盘
+func (l *Log) Append(data []byte) (uint64, error) {
+	if len(data) > MaxRecordSize {
+		return 0, fmt.Errorf("%w: %d 字节", ErrRecordTooLarge, len(data))
+	}
+	record := encodeRecord(data)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed {
+		return 0, ErrClosed
+	}
+	if l.size > 0 && l.size+int64(len(record)) > l.config.segmentSize {
+		if err := l.rotateLocked(); err != nil {
+			return 0, err
+		}
+	}
+
+	// 一条记录一次写入，读取方只会读到写完的记录
+	n, err := l.file.Write(record)
+	if err != nil {
+		// 写了一半的记录截掉，保持段的完整
+		if n > 0 {
+			if terr := l.file.Truncate(l.size); terr != nil {
+				err = errors.Join(err, terr)
+			}
+		}
+		return 0, err
+	}
+	l.size += int64(n)
+	offset := l.next
+	l.next++
+
+	if l.config.syncPolicy == SyncAlways {
+		if err := l.file.Sync(); err != nil {
+			return 0, err
+		}
+		return offset, nil
+	}
+	l.dirty = true
+	return offset, nil
+}
+
+// Sync 立即把已追加的记录刷盘，并返回之前后台刷盘的错误
+func (l *Log) Sync() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed {
+		return ErrClosed
+	}
+	return l.syncLocked()
+}
+
+// syncLocked 刷盘，调用时必须持有锁
+func (l *Log) syncLocked() error {
+	if l.dirty {
+		if err := l.file.Sync(); err != nil {
+			l.syncErr = err
+		} else {
+			l.dirty = false
+		}
+	}
+	err := l.syncErr
+	l.syncErr = nil
+	return err
+}
+
+// syncLoop SyncInterval 策略下定时刷盘
+func (l *Log) syncLoop() {
+	defer close(l.done)
+	ticker := time.NewTicker(l.config.syncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			l.mutex.Lock()
+			if l.dirty && !l.closed {
+				if err := l.file.Sync(); err != nil {
+					l.syncErr = err
+				} else {
+					l.dirty = false
+				}
+			}
+			l.mutex.Unlock()
+		}
+	}
+}
+
+// rotateLocked 结束当前段并从下一条记录的偏移量开始新的段，调用时必须持有锁
+func (l *Log) rotateLocked() error {
+	if l.config.syncPolicy != SyncNever {
+		if err := l.file.Sync(); err != nil {
+			return err
+		}
+		l.dirty = false
+	}
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+	return l.createSegmentLocked(l.next)
+}
+
+// createSegmentLocked 创建以 base 为起始偏移量的新段并开始写入，调用时必须持有锁
+func (l *Log) createSegmentLocked(base uint64) error {
+	file, err := os.OpenFile(segmentPath(l.dir, base), os.O_WRONLY|os.O_APPEND|os.O_CREATE|os.O_EXCL, segmentPerm)
+	if err != nil {
+		return err
+	}
+	if l.config.syncPolicy != SyncNever {
+		if err := syncDir(l.dir); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	l.segments = append(l.segments, base)
+	l.file, l.size, l.next = file, 0, base
+	return nil
+}
+
+// FirstOffset 返回日志中最早一条记录的偏移量
+func (l *Log) FirstOffset() uint64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.segments[0]
+}
+
+// NextOffset 返回下一条追加的记录将得到的偏移量，也就是日志的长度
+func (l *Log) NextOffset() uint64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.next
+}
+
+// Segments 返回段文件的个数
+func (l *Log) Segments() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return len(l.segments)
+}
+
+// TruncateFront 删除所有记录都早于 offset 的段，通常在状态做了快照之后调用
+// 删除以段为单位，offset 之前的记录可能还留在日志中；正在写入的段不会删除
+func (l *Log) TruncateFront(offset uint64) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed {
+		return ErrClosed
+	}
+
+	removed := 0
+	for removed < len(l.segments)-1 && l.segments[removed+1] <= offset {
+		if err := os.Remove(segmentPath(l.dir, l.segments[removed])); err != nil {
+			l.segments = l.segments[removed:]
+			return err
+		}
+		removed++
+	}
+	l.segments = l.segments[removed:]
+	if removed > 0 && l.config.syncPolicy != SyncNever {
+		return syncDir(l.dir)
+	}
+	return nil
+}
+
+// Close 刷盘并关闭日志
+func (l *Log) Close() error {
+	l.mutex.Lock()
+	if l.closed {
+		l.mutex.Unlock()
+		return ErrClosed
+	}
+	err := l.syncLocked()
+	l.closed = true
+	err = errors.Join(err, l.file.Close())
+	l.mutex.Unlock()
+
+	if l.stop != nil {
+		close(l.stop)
+		<-l.done
+	}
+	return err
+}
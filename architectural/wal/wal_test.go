@@ -0,0 +1,454 @@
+package wal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/XiaoluCoding626/go-design-pattern/architectural/cqrs"
+	"github.com/XiaoluCoding626/go-design-pattern/behavioral/command"
+	"github.com/XiaoluCoding626/go-design-pattern/behavioral/observer"
+)
+
+// openLog 在临时目录中打开日志
+func openLog(t *testing.T, dir string, opts ...Option) *Log {
+	t.Helper()
+	l, err := Open(dir, opts...)
+	assert.NoError(t, err)
+	return l
+}
+
+// appendN 追加 n 条内容为 "record-i" 的记录
+func appendN(t *testing.T, l *Log, from, n int) {
+	t.Helper()
+	for i := from; i < from+n; i++ {
+		_, err := l.Append([]byte(fmt.Sprintf("record-%d", i)))
+		assert.NoError(t, err)
+	}
+}
+
+// readAll 从 from 开始读取所有记录的内容
+func readAll(t *testing.T, l *Log, from uint64) []string {
+	t.Helper()
+	var got []string
+	_, err := l.Replay(from, func(rec Record) error {
+		assert.Equal(t, fmt.Sprintf("record-%d", rec.Offset), string(rec.Data))
+		got = append(got, string(rec.Data))
+		return nil
+	})
+	assert.NoError(t, err)
+	return got
+}
+
+// lastSegment 返回目录中最后一个段文件的路径
+func lastSegment(t *testing.T, dir string) string {
+	t.Helper()
+	bases, err := listSegments(dir)
+	assert.NoError(t, err)
+	return segmentPath(dir, bases[len(bases)-1])
+}
+
+func TestAppendAndReplay(t *testing.T) {
+	l := openLog(t, t.TempDir())
+	defer l.Close()
+
+	for i := 0; i < 3; i++ {
+		offset, err := l.Append([]byte(fmt.Sprintf("record-%d", i)))
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(i), offset)
+	}
+	_, err := l.Append(nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, uint64(0), l.FirstOffset())
+	assert.Equal(t, uint64(4), l.NextOffset())
+
+	var records []Record
+	next, err := l.Replay(1, func(rec Record) error {
+		records = append(records, rec)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), next)
+	assert.Equal(t, []Record{
+		{Offset: 1, Data: []byte("record-1")},
+		{Offset: 2, Data: []byte("record-2")},
+		{Offset: 3, Data: []byte{}},
+	}, records)
+
+	// 从末尾回放没有记录
+	next, err = l.Replay(4, func(Record) error { t.Fatal("不应读到记录"); return nil })
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), next)
+}
+
+func TestSegmentRotation(t *testing.T) {
+	dir := t.TempDir()
+	// 每条记录 8 字节头 + 8 到 9 字节数据，每个段放 3 条
+	l := openLog(t, dir, WithSegmentSize(60))
+	appendN(t, l, 0, 10)
+	assert.Equal(t, 4, l.Segments())
+
+	bases, err := listSegments(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{0, 3, 6, 9}, bases)
+	assert.FileExists(t, filepath.Join(dir, "00000000000000000003.wal"))
+
+	// 从段的中间开始回放，跨越多个段
+	assert.Len(t, readAll(t, l, 4), 6)
+	assert.NoError(t, l.Close())
+
+	// 重新打开后从最后一个段继续追加
+	l = openLog(t, dir, WithSegmentSize(60))
+	defer l.Close()
+	assert.Equal(t, uint64(10), l.NextOffset())
+	appendN(t, l, 10, 3)
+	assert.Len(t, readAll(t, l, 0), 13)
+}
+
+func TestOversizedRecordGetsOwnSegment(t *testing.T) {
+	l := openLog(t, t.TempDir(), WithSegmentSize(16))
+	defer l.Close()
+
+	_, err := l.Append(make([]byte, 100))
+	assert.NoError(t, err)
+	_, err = l.Append([]byte("x"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, l.Segments())
+
+	_, err = l.Append(make([]byte, MaxRecordSize+1))
+	assert.ErrorIs(t, err, ErrRecordTooLarge)
+	assert.Equal(t, uint64(2), l.NextOffset())
+}
+
+func TestRecoverTornTail(t *testing.T) {
+	tests := []struct {
+		name string
+		tail []byte
+	}{
+		{"记录头不完整", []byte{42, 0}},
+		{"数据不完整", encodeRecord([]byte("record-3"))[:12]},
+		{"校验和不匹配", func() []byte {
+			rec := encodeRecord([]byte("record-3"))
+			rec[len(rec)-1] ^= 0xff
+			return rec
+		}()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			l := openLog(t, dir)
+			appendN(t, l, 0, 3)
+			assert.NoError(t, l.Close())
+
+			path := lastSegment(t, dir)
+			before, _ := os.Stat(path)
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+			assert.NoError(t, err)
+			f.Write(tt.tail)
+			f.Close()
+
+			l = openLog(t, dir)
+			defer l.Close()
+			after, _ := os.Stat(path)
+			assert.Equal(t, before.Size(), after.Size(), "不完整的尾部应被截掉")
+			assert.Equal(t, uint64(3), l.NextOffset())
+
+			appendN(t, l, 3, 1)
+			assert.Len(t, readAll(t, l, 0), 4)
+		})
+	}
+}
+
+func TestCorruptSealedSegment(t *testing.T) {
+	dir := t.TempDir()
+	l := openLog(t, dir, WithSegmentSize(60))
+	defer l.Close()
+	appendN(t, l, 0, 6)
+
+	// 已经写满的段中间的记录损坏，回放读到这里时报错，之前的记录正常交付
+	path := segmentPath(dir, 0)
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	data[len(data)-1] ^= 0xff
+	assert.NoError(t, os.WriteFile(path, data, 0o644))
+
+	var got []uint64
+	next, err := l.Replay(0, func(rec Record) error {
+		got = append(got, rec.Offset)
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrCorrupt)
+	assert.Equal(t, []uint64{0, 1}, got)
+	assert.Equal(t, uint64(2), next)
+
+	// 段缺失同样报告为损坏
+	assert.NoError(t, os.Remove(segmentPath(dir, 3)))
+	_, err = l.Replay(3, func(Record) error { return nil })
+	assert.Error(t, err)
+}
+
+func TestReplayStopsOnError(t *testing.T) {
+	l := openLog(t, t.TempDir())
+	defer l.Close()
+	appendN(t, l, 0, 5)
+
+	stop := errors.New("停止")
+	next, err := l.Replay(0, func(rec Record) error {
+		if rec.Offset == 2 {
+			return stop
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, uint64(2), next, "返回第一条未处理成功的记录，下次从这里继续")
+	assert.Len(t, readAll(t, l, next), 3)
+}
+
+func TestReaderSnapshot(t *testing.T) {
+	l := openLog(t, t.TempDir(), WithSegmentSize(60))
+	defer l.Close()
+	appendN(t, l, 0, 4)
+
+	r, err := l.NewReader(2)
+	assert.NoError(t, err)
+	defer r.Close()
+	appendN(t, l, 4, 4) // 创建之后追加的记录不在读取范围内
+
+	var got []uint64
+	for r.Next() {
+		got = append(got, r.Record().Offset)
+	}
+	assert.NoError(t, r.Err())
+	assert.Equal(t, []uint64{2, 3}, got)
+	assert.False(t, r.Next())
+}
+
+func TestTruncateFront(t *testing.T) {
+	dir := t.TempDir()
+	l := openLog(t, dir, WithSegmentSize(60))
+	defer l.Close()
+	appendN(t, l, 0, 10)
+
+	// 偏移量 7 所在的段从 6 开始，之前的两个段可以删除
+	assert.NoError(t, l.TruncateFront(7))
+	assert.Equal(t, uint64(6), l.FirstOffset())
+	assert.Equal(t, 2, l.Segments())
+	assert.Equal(t, []string{"record-6", "record-7", "record-8", "record-9"}, readAll(t, l, 6))
+
+	_, err := l.NewReader(5)
+	assert.ErrorIs(t, err, ErrOffsetOutOfRange)
+	_, err = l.NewReader(11)
+	assert.ErrorIs(t, err, ErrOffsetOutOfRange)
+
+	// 正在写入的段不会删除
+	assert.NoError(t, l.TruncateFront(100))
+	assert.Equal(t, 1, l.Segments())
+	assert.NoError(t, l.Close())
+
+	l = openLog(t, dir)
+	assert.Equal(t, uint64(9), l.FirstOffset())
+	assert.Equal(t, uint64(10), l.NextOffset())
+	assert.NoError(t, l.Close())
+}
+
+func TestSyncPolicies(t *testing.T) {
+	assert.Equal(t, "always", SyncAlways.String())
+	assert.Equal(t, "interval", SyncInterval.String())
+	assert.Equal(t, "never", SyncNever.String())
+
+	policies := map[string]Option{
+		"always":   WithSyncPolicy(SyncAlways),
+		"interval": WithSyncInterval(5 * time.Millisecond),
+		"never":    WithSyncPolicy(SyncNever),
+	}
+	for name, opt := range policies {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			l := openLog(t, dir, opt, WithSegmentSize(60))
+			appendN(t, l, 0, 5)
+			// 不论何种策略，追加的记录都可以立即读到
+			assert.Len(t, readAll(t, l, 0), 5)
+			time.Sleep(10 * time.Millisecond)
+			assert.NoError(t, l.Sync())
+			assert.NoError(t, l.Close())
+
+			l = openLog(t, dir)
+			defer l.Close()
+			assert.Len(t, readAll(t, l, 0), 5)
+		})
+	}
+}
+
+func TestClosed(t *testing.T) {
+	l := openLog(t, t.TempDir(), WithSyncInterval(time.Millisecond))
+	assert.NoError(t, l.Close())
+
+	_, err := l.Append([]byte("x"))
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.ErrorIs(t, l.Sync(), ErrClosed)
+	assert.ErrorIs(t, l.TruncateFront(0), ErrClosed)
+	_, err = l.NewReader(0)
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.ErrorIs(t, l.Close(), ErrClosed)
+}
+
+func TestConcurrentAppend(t *testing.T) {
+	l := openLog(t, t.TempDir(), WithSegmentSize(256), WithSyncPolicy(SyncNever))
+	defer l.Close()
+
+	const writers, perWriter = 8, 50
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				_, err := l.Append([]byte(fmt.Sprintf("w%d-%d", w, i)))
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	// 写入的同时读取
+	for i := 0; i < 10; i++ {
+		_, err := l.Replay(l.FirstOffset(), func(Record) error { return nil })
+		assert.NoError(t, err)
+	}
+	wg.Wait()
+
+	seen := map[string]bool{}
+	var offsets []uint64
+	_, err := l.Replay(0, func(rec Record) error {
+		seen[string(rec.Data)] = true
+		offsets = append(offsets, rec.Offset)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, seen, writers*perWriter)
+	for i, offset := range offsets {
+		assert.Equal(t, uint64(i), offset)
+	}
+}
+
+func TestCommandJournal(t *testing.T) {
+	dir := t.TempDir()
+	l := openLog(t, dir)
+
+	light := command.NewLight("客厅灯")
+	remote := command.NewRemoteControl(1)
+	remote.BindPrincipal(command.NewPrincipal("小明", command.RoleMember))
+	remote.SetCommand(0, command.NewTurnOnCommand(light), command.NewTurnOffCommand(light))
+	remote.SetEventPublisher(&commandJournal{log: l, onError: func(err error) { t.Error(err) }})
+
+	assert.NoError(t, remote.OnButtonPressed(0))
+	assert.NoError(t, remote.OffButtonPressed(0))
+	assert.NoError(t, remote.UndoLastCommand())
+	assert.NoError(t, l.Close())
+
+	l = openLog(t, dir)
+	defer l.Close()
+	var events []command.CommandEvent
+	next, err := replayJournal(l, 0, func(_ uint64, e command.CommandEvent) { events = append(events, e) })
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), next)
+	if assert.Len(t, events, 3) {
+		assert.Equal(t, command.CommandExecuted, events[0].Type)
+		assert.Equal(t, "开启 客厅灯", events[0].Command)
+		assert.Equal(t, "小明", events[0].Principal)
+		assert.Equal(t, command.CommandUndone, events[2].Type)
+	}
+	assert.Equal(t, []string{"开启 客厅灯"}, appliedCommands(events))
+
+	// 失败的事件保留错误信息，不计入生效的命令
+	failed := command.CommandEvent{Type: command.CommandExecuted, Command: "关闭 客厅灯", Err: errors.New("设备离线")}
+	(&commandJournal{log: l}).Publish(failed)
+	events = nil
+	_, err = replayJournal(l, next, func(_ uint64, e command.CommandEvent) { events = append(events, e) })
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.EqualError(t, events[0].Err, "设备离线")
+		assert.Empty(t, appliedCommands(events))
+	}
+}
+
+func TestEventJournal(t *testing.T) {
+	dir := t.TempDir()
+	l := openLog(t, dir)
+
+	system, err := cqrs.NewSystem(nil)
+	assert.NoError(t, err)
+	system.Subscribe(&eventJournal{log: l, onError: func(err error) { t.Error(err) }})
+
+	ctx := context.Background()
+	assert.NoError(t, system.Dispatch(ctx, cqrs.ListStock{Symbol: "AAPL", Price: 180}))
+	assert.NoError(t, system.Dispatch(ctx, cqrs.ListStock{Symbol: "TSLA", Price: 250}))
+	assert.NoError(t, system.Dispatch(ctx, cqrs.ChangePrice{Symbol: "AAPL", Price: 198, Reason: "财报超预期"}))
+	assert.Error(t, system.Dispatch(ctx, cqrs.ChangePrice{Symbol: "MSFT", Price: 420}), "被拒绝的命令不产生事件")
+	system.Close()
+	assert.NoError(t, l.Close())
+
+	// 重新打开日志，从头回放得到与原读模型相同的状态
+	l = openLog(t, dir)
+	defer l.Close()
+	prices, movers := cqrs.NewPriceBoard("prices"), cqrs.NewMoversBoard("movers")
+	next, err := replayEvents(l, 0, prices, movers)
+	assert.NoError(t, err)
+	assert.Equal(t, system.Exchange.Version(), next)
+	assert.Equal(t, next, prices.Version())
+	assert.Equal(t, system.Prices.Symbols(), prices.Symbols())
+	for _, symbol := range system.Prices.Symbols() {
+		want, _ := system.Prices.Quote(symbol)
+		got, _ := prices.Quote(symbol)
+		assert.Equal(t, want.Price, got.Price, symbol)
+		assert.Equal(t, want.ChangePercent, got.ChangePercent, symbol)
+		assert.Equal(t, want.Reason, got.Reason, symbol)
+		assert.True(t, want.UpdatedAt.Equal(got.UpdatedAt), symbol)
+	}
+	assert.Equal(t, system.Movers.TopGainers(2), movers.TopGainers(2))
+
+	// 从检查点继续回放只交付之后的事件
+	(&eventJournal{log: l}).Update(observer.StockEvent{Symbol: "TSLA", Price: 225, PrevPrice: 250}, "交付量下滑")
+	next, err = replayEvents(l, next, prices)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), next)
+	quote, _ := prices.Quote("TSLA")
+	assert.Equal(t, 225.0, quote.Price)
+	assert.InDelta(t, -10.0, quote.ChangePercent, 1e-9)
+	assert.Equal(t, "交付量下滑", quote.Reason)
+}
+
+// BenchmarkAppend 比较不同刷盘策略下追加一条 128 字节记录的开销
+func BenchmarkAppend(b *testing.B) {
+	policies := []struct {
+		name string
+		opt  Option
+	}{
+		{"SyncAlways", WithSyncPolicy(SyncAlways)},
+		{"SyncInterval", WithSyncInterval(10 * time.Millisecond)},
+		{"SyncNever", WithSyncPolicy(SyncNever)},
+	}
+	data := make([]byte, 128)
+	for _, p := range policies {
+		b.Run(p.name, func(b *testing.B) {
+			l, err := Open(b.TempDir(), p.opt)
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer l.Close()
+			b.SetBytes(int64(len(data)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := l.Append(data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,389 @@
+package write_behind_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/XiaoluCoding626/go-design-pattern/concurrency/singleflight"
+)
+
+// 缓存相关错误
+var (
+	ErrClosed     = errors.New("缓存已关闭")
+	ErrFlushStuck = errors.New("仍有写入未能持久化")
+)
+
+// Option 缓存配置选项
+type Option func(*config)
+
+// config 缓存配置，与值类型无关
+type config struct {
+	maxBatch      int
+	flushInterval time.Duration
+	retryMax      int
+	retryWaitMin  time.Duration
+	retryWaitMax  time.Duration
+	onError       func(keys int, err error)
+}
+
+// WithMaxBatch 一批最多写入的键数，脏数据攒够一批时立即刷新，默认 100
+func WithMaxBatch(n int) Option {
+	return func(c *config) {
+		if n > 0 {
+			c.maxBatch = n
+		}
+	}
+}
+
+// WithFlushInterval 定时刷新的间隔，脏数据最多在缓存中停留这么久（不计重试），默认 1 秒
+func WithFlushInterval(d time.Duration) Option {
+	return func(c *config) {
+		if d > 0 {
+			c.flushInterval = d
+		}
+	}
+}
+
+// WithRetry 写入存储失败时的重试策略：最多重试 maxRetries 次，等待时间从 minWait 开始指数增长，不超过 maxWait
+// 重试耗尽后脏数据保留在缓存中，下一次刷新时再试，默认重试 3 次，等待 10 毫秒到 1 秒
+func WithRetry(maxRetries int, minWait, maxWait time.Duration) Option {
+	return func(c *config) {
+		if maxRetries >= 0 {
+			c.retryMax = maxRetries
+		}
+		if minWait > 0 {
+			c.retryWaitMin = minWait
+		}
+		if maxWait > 0 {
+			c.retryWaitMax = maxWait
+		}
+	}
+}
+
+// WithErrorHandler 一批写入在重试耗尽后仍然失败时调用，参数为该批的键数
+func WithErrorHandler(fn func(keys int, err error)) Option {
+	return func(c *config) {
+		c.onError = fn
+	}
+}
+
+// backoff 返回第 attempt 次重试前的等待时间
+func (c config) backoff(attempt int) time.Duration {
+	wait := c.retryWaitMin << (attempt - 1)
+	if wait <= 0 || wait > c.retryWaitMax {
+		return c.retryWaitMax
+	}
+	return wait
+}
+
+// Stats 缓存统计信息
+type Stats struct {
+	Hits      int // 读取命中缓存的次数
+	Misses    int // 读取穿透到存储的次数
+	Writes    int // Set 和 Delete 的次数
+	Coalesced int // 覆盖了尚未持久化的写入的次数，这些写入合并为一次持久化
+	Flushes   int // 成功写入存储的批次数
+	Persisted int // 成功持久化的键数
+	Retries   int // 写入存储的重试次数
+	Failures  int // 重试耗尽后仍然失败的批次数
+	Dirty     int // 当前尚未持久化的键数
+}
+
+// entry 缓存中的一项
+type entry[V any] struct {
+	value   V
+	deleted bool   // 删除也是一次写入，持久化之前保留为墓碑，读取时返回 ErrNotFound
+	dirty   bool   // 尚未持久化
+	version uint64 // 每次写入递增，刷新完成时用于判断期间是否又有新的写入
+}
+
+// Cache 写回缓存（Write-Behind）：写入只修改内存并标记为脏，由后台协程按批异步持久化到存储
+//
+// 读取未命中时穿透到存储（Read-Through），并发读取同一个键只会访问一次存储。
+// 同一个键在持久化之前的多次写入只会持久化最后一次。
+type Cache[V any] struct {
+	config config
+	store  Store[V]
+	loads  singleflight.Group[V]
+
+	mutex   sync.Mutex
+	entries map[string]*entry[V]
+	dirty   int
+	version uint64
+	closed  bool
+	stats   Stats
+
+	flushMutex sync.Mutex // 同一时间只有一次刷新，避免旧数据覆盖新数据
+	kick       chan struct{}
+	stop       context.CancelFunc // 取消后台刷新，包括正在等待的重试
+	done       chan struct{}
+}
+
+// New 创建写回缓存并启动后台刷新协程
+func New[V any](store Store[V], opts ...Option) *Cache[V] {
+	cfg := config{
+		maxBatch:      100,
+		flushInterval: time.Second,
+		retryMax:      3,
+		retryWaitMin:  10 * time.Millisecond,
+		retryWaitMax:  time.Second,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	c := &Cache[V]{
+		config:  cfg,
+		store:   store,
+		entries: make(map[string]*entry[V]),
+		kick:    make(chan struct{}, 1),
+		stop:    stop,
+		done:    make(chan struct{}),
+	}
+	go c.run(ctx)
+	return c
+}
+
+// Get 读取一个键，未命中时从存储加载并放入缓存
+func (c *Cache[V]) Get(ctx context.Context, key string) (V, error) {
+	var zero V
+	c.mutex.Lock()
+	if c.closed {
+		c.mutex.Unlock()
+		return zero, ErrClosed
+	}
+	if e, exists := c.entries[key]; exists {
+		c.stats.Hits++
+		c.mutex.Unlock()
+		if e.deleted {
+			return zero, fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+		return e.value, nil
+	}
+	c.stats.Misses++
+	c.mutex.Unlock()
+
+	value, err, _ := c.loads.Do(key, func() (V, error) {
+		v, err := c.store.Load(ctx, key)
+		if err != nil {
+			return v, err
+		}
+
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		// 加载期间可能已经有了新的写入，以缓存中的为准
+		if e, exists := c.entries[key]; exists {
+			if e.deleted {
+				return zero, fmt.Errorf("%w: %s", ErrNotFound, key)
+			}
+			return e.value, nil
+		}
+		c.entries[key] = &entry[V]{value: v}
+		return v, nil
+	})
+	return value, err
+}
+
+// Set 写入一个键，立即返回，数据由后台异步持久化
+func (c *Cache[V]) Set(key string, value V) error {
+	return c.write(key, value, false)
+}
+
+// Delete 删除一个键，删除同样异步持久化
+func (c *Cache[V]) Delete(key string) error {
+	var zero V
+	return c.write(key, zero, true)
+}
+
+// write 修改缓存并标记为脏，脏数据攒够一批时通知后台立即刷新
+func (c *Cache[V]) write(key string, value V, deleted bool) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed {
+		return ErrClosed
+	}
+	e, exists := c.entries[key]
+	if !exists {
+		e = &entry[V]{}
+		c.entries[key] = e
+	}
+	if e.dirty {
+		c.stats.Coalesced++
+	} else {
+		e.dirty = true
+		c.dirty++
+	}
+	c.version++
+	e.value, e.deleted, e.version = value, deleted, c.version
+	c.stats.Writes++
+
+	if c.dirty >= c.config.maxBatch {
+		select {
+		case c.kick <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Flush 立即把所有脏数据写入存储，返回时要么全部写入成功，要么返回错误
+func (c *Cache[V]) Flush(ctx context.Context) error {
+	return c.flush(ctx)
+}
+
+// Close 停止后台刷新，把剩余的脏数据写入存储
+// ctx 结束或重试耗尽时返回错误，此时未持久化的数据会丢失；重复调用返回 nil
+func (c *Cache[V]) Close(ctx context.Context) error {
+	c.mutex.Lock()
+	if c.closed {
+		c.mutex.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.mutex.Unlock()
+
+	c.stop()
+	<-c.done
+
+	if err := c.flush(ctx); err != nil {
+		return fmt.Errorf("%w: %d 个键: %w", ErrFlushStuck, c.Stats().Dirty, err)
+	}
+	return nil
+}
+
+// Stats 返回统计信息
+func (c *Cache[V]) Stats() Stats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stats := c.stats
+	stats.Dirty = c.dirty
+	return stats
+}
+
+// run 后台刷新：定时刷新，或在脏数据攒够一批时立即刷新
+func (c *Cache[V]) run(ctx context.Context) {
+	defer close(c.done)
+
+	ticker := time.NewTicker(c.config.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-c.kick:
+		case <-ctx.Done():
+			return
+		}
+		// 失败已经通过错误处理函数报告，脏数据保留到下一次刷新
+		c.flush(ctx)
+	}
+}
+
+// flush 按批写入所有脏数据，某一批重试耗尽后停止，剩余的留给下一次刷新
+func (c *Cache[V]) flush(ctx context.Context) error {
+	c.flushMutex.Lock()
+	defer c.flushMutex.Unlock()
+
+	writes, versions := c.dirtySnapshot()
+	for start := 0; start < len(writes); start += c.config.maxBatch {
+		end := min(start+c.config.maxBatch, len(writes))
+		if err := c.writeBatch(ctx, writes[start:end], versions[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dirtySnapshot 复制所有脏数据，按键排序，保证批次内容稳定
+func (c *Cache[V]) dirtySnapshot() ([]Write[V], []uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	keys := make([]string, 0, c.dirty)
+	for key, e := range c.entries {
+		if e.dirty {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	writes := make([]Write[V], len(keys))
+	versions := make([]uint64, len(keys))
+	for i, key := range keys {
+		e := c.entries[key]
+		writes[i] = Write[V]{Key: key, Value: e.value, Deleted: e.deleted}
+		versions[i] = e.version
+	}
+	return writes, versions
+}
+
+// writeBatch 写入一批数据，失败时按退避策略重试
+func (c *Cache[V]) writeBatch(ctx context.Context, writes []Write[V], versions []uint64) error {
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			c.mutex.Lock()
+			c.stats.Retries++
+			c.mutex.Unlock()
+
+			timer := time.NewTimer(c.config.backoff(attempt))
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				// 被取消不算存储失败，脏数据保留
+				timer.Stop()
+				return ctx.Err()
+			}
+		}
+
+		err := c.store.WriteBatch(ctx, writes)
+		if err == nil {
+			c.markClean(writes, versions)
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if attempt >= c.config.retryMax {
+			return c.fail(len(writes), err)
+		}
+	}
+}
+
+// markClean 把已持久化的键标记为干净；写入期间又被修改的键保持为脏
+func (c *Cache[V]) markClean(writes []Write[V], versions []uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for i, w := range writes {
+		e := c.entries[w.Key]
+		if e.version != versions[i] {
+			continue
+		}
+		e.dirty = false
+		c.dirty--
+		if e.deleted {
+			// 删除已经持久化，之后的读取可以穿透到存储
+			delete(c.entries, w.Key)
+		}
+	}
+	c.stats.Flushes++
+	c.stats.Persisted += len(writes)
+}
+
+// fail 记录失败并通知错误处理函数
+func (c *Cache[V]) fail(keys int, err error) error {
+	c.mutex.Lock()
+	c.stats.Failures++
+	c.mutex.Unlock()
+
+	if c.config.onError != nil {
+		c.config.onError(keys, err)
+	}
+	return err
+}
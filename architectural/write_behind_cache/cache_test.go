@@ -0,0 +1,228 @@
+package write_behind_cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func closeCache[V any](t *testing.T, c *Cache[V]) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, c.Close(ctx))
+}
+
+// TestWriteBehindCoalescing 测试写入先进入缓存，刷新时同一个键只持久化最后一次写入
+func TestWriteBehindCoalescing(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore[string]()
+	store.Put("b", "old")
+	c := New[string](store, WithFlushInterval(time.Hour))
+	defer closeCache(t, c)
+
+	assert.NoError(t, c.Set("a", "1"))
+	assert.NoError(t, c.Set("a", "2"))
+	assert.NoError(t, c.Delete("b"))
+	v, err := c.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", v, "读到自己的写入")
+	_, err = c.Get(ctx, "b")
+	assert.ErrorIs(t, err, ErrNotFound, "删除在持久化之前就对读取可见")
+	assert.Equal(t, []string{"b"}, store.Keys(), "尚未持久化")
+	assert.Equal(t, 2, c.Stats().Dirty)
+
+	assert.NoError(t, c.Flush(ctx))
+	assert.Equal(t, [][]Write[string]{{{Key: "a", Value: "2"}, {Key: "b", Deleted: true}}}, store.Batches())
+	assert.Equal(t, []string{"a"}, store.Keys())
+
+	stats := c.Stats()
+	assert.Equal(t, 3, stats.Writes)
+	assert.Equal(t, 1, stats.Coalesced)
+	assert.Equal(t, 1, stats.Flushes)
+	assert.Equal(t, 2, stats.Persisted)
+	assert.Zero(t, stats.Dirty)
+
+	assert.NoError(t, c.Flush(ctx))
+	assert.Len(t, store.Batches(), 1, "没有脏数据时不写入存储")
+}
+
+// TestFlushPolicies 测试按数量和按时间两种刷新触发方式
+func TestFlushPolicies(t *testing.T) {
+	store := NewMemoryStore[int]()
+	c := New[int](store, WithMaxBatch(3), WithFlushInterval(time.Hour))
+	c.Set("a", 1)
+	c.Set("b", 2)
+	time.Sleep(20 * time.Millisecond)
+	assert.Empty(t, store.Batches(), "不足一批且未到时间")
+	c.Set("c", 3)
+	assert.Eventually(t, func() bool { return len(store.Keys()) == 3 }, time.Second, time.Millisecond, "攒够一批立即刷新")
+	closeCache(t, c)
+
+	store = NewMemoryStore[int]()
+	c = New[int](store, WithMaxBatch(100), WithFlushInterval(10*time.Millisecond))
+	c.Set("a", 1)
+	assert.Eventually(t, func() bool { return len(store.Keys()) == 1 }, time.Second, time.Millisecond, "定时刷新")
+	closeCache(t, c)
+
+	// 一次刷新中超过一批的脏数据被拆成多批
+	store = NewMemoryStore[int]()
+	c = New[int](store, WithMaxBatch(2), WithFlushInterval(time.Hour))
+	c.mutex.Lock()
+	for _, k := range []string{"a", "b", "c", "d", "e"} {
+		c.entries[k] = &entry[int]{dirty: true}
+		c.dirty++
+	}
+	c.mutex.Unlock()
+	assert.NoError(t, c.Flush(context.Background()))
+	var sizes []int
+	for _, b := range store.Batches() {
+		sizes = append(sizes, len(b))
+	}
+	assert.Equal(t, []int{2, 2, 1}, sizes)
+	closeCache(t, c)
+}
+
+// TestRetry 测试存储失败时重试，重试耗尽后数据保留到下一次刷新
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore[int]()
+	var reported atomic.Int32
+	c := New[int](store,
+		WithFlushInterval(time.Hour),
+		WithRetry(2, time.Millisecond, 2*time.Millisecond),
+		WithErrorHandler(func(keys int, err error) { reported.Add(1) }),
+	)
+	defer closeCache(t, c)
+
+	// 前两次失败，第三次成功
+	failures := 2
+	boom := errors.New("存储不可用")
+	store.FailWith(func([]Write[int]) error {
+		if failures > 0 {
+			failures--
+			return boom
+		}
+		return nil
+	})
+	c.Set("a", 1)
+	assert.NoError(t, c.Flush(ctx))
+	assert.Equal(t, []string{"a"}, store.Keys())
+	assert.Equal(t, 2, c.Stats().Retries)
+
+	// 一直失败：重试耗尽，数据保持为脏
+	store.FailWith(func([]Write[int]) error { return boom })
+	c.Set("b", 2)
+	assert.ErrorIs(t, c.Flush(ctx), boom)
+	assert.Equal(t, int32(1), reported.Load())
+	stats := c.Stats()
+	assert.Equal(t, 1, stats.Failures)
+	assert.Equal(t, 1, stats.Dirty)
+	v, err := c.Get(ctx, "b")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v, "未持久化的数据仍然可读")
+
+	store.FailWith(nil)
+	assert.NoError(t, c.Flush(ctx))
+	assert.Equal(t, []string{"a", "b"}, store.Keys())
+}
+
+// TestWriteDuringFlush 测试持久化期间的新写入不会被错误地标记为干净
+func TestWriteDuringFlush(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore[int]()
+	c := New[int](store, WithFlushInterval(time.Hour))
+	defer closeCache(t, c)
+
+	c.Set("a", 1)
+	once := sync.Once{}
+	store.FailWith(func([]Write[int]) error {
+		once.Do(func() { c.Set("a", 2) })
+		return nil
+	})
+	assert.NoError(t, c.Flush(ctx))
+	assert.Equal(t, 1, c.Stats().Dirty, "a=2 仍需持久化")
+	assert.NoError(t, c.Flush(ctx))
+	v, _ := store.Load(ctx, "a")
+	assert.Equal(t, 2, v)
+	assert.Zero(t, c.Stats().Dirty)
+}
+
+// countingStore 统计读取次数，读取较慢以便并发读取重叠
+type countingStore struct {
+	*MemoryStore[int]
+	loads atomic.Int32
+}
+
+func (s *countingStore) Load(ctx context.Context, key string) (int, error) {
+	s.loads.Add(1)
+	time.Sleep(20 * time.Millisecond)
+	return s.MemoryStore.Load(ctx, key)
+}
+
+// TestReadThrough 测试读穿透：并发读取同一个键只访问一次存储，之后命中缓存
+func TestReadThrough(t *testing.T) {
+	ctx := context.Background()
+	store := &countingStore{MemoryStore: NewMemoryStore[int]()}
+	store.Put("a", 42)
+	c := New[int](store, WithFlushInterval(time.Hour))
+	defer closeCache(t, c)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := c.Get(ctx, "a")
+			assert.NoError(t, err)
+			assert.Equal(t, 42, v)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), store.loads.Load())
+
+	v, _ := c.Get(ctx, "a")
+	assert.Equal(t, 42, v)
+	assert.Equal(t, int32(1), store.loads.Load(), "命中缓存")
+
+	_, err := c.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Zero(t, c.Stats().Dirty, "读取不产生脏数据")
+}
+
+// TestClose 测试关闭时刷新剩余数据，之后拒绝读写
+func TestClose(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore[int]()
+	c := New[int](store, WithFlushInterval(time.Hour))
+	c.Set("a", 1)
+	c.Set("b", 2)
+	assert.NoError(t, c.Close(ctx))
+	assert.Equal(t, []string{"a", "b"}, store.Keys())
+	assert.ErrorIs(t, c.Set("c", 3), ErrClosed)
+	_, err := c.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.NoError(t, c.Close(ctx), "重复关闭是安全的")
+
+	// 存储一直失败：关闭报告丢失的键数
+	store = NewMemoryStore[int]()
+	store.FailWith(func([]Write[int]) error { return errors.New("磁盘已满") })
+	c = New[int](store, WithFlushInterval(time.Hour), WithRetry(1, time.Millisecond, time.Millisecond))
+	c.Set("a", 1)
+	err = c.Close(ctx)
+	assert.ErrorIs(t, err, ErrFlushStuck)
+	assert.ErrorContains(t, err, "1 个键")
+	assert.ErrorContains(t, err, "磁盘已满")
+
+	// ctx 到期时停止重试
+	c = New[int](store, WithFlushInterval(time.Hour), WithRetry(100, time.Hour, time.Hour))
+	c.Set("a", 1)
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, c.Close(timeout), context.DeadlineExceeded)
+}
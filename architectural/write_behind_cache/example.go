@@ -0,0 +1,60 @@
+package write_behind_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RunExample 运行写回缓存示例：高频更新商品浏览量，存储只收到合并后的批量写入
+func RunExample() {
+	ctx := context.Background()
+	store := NewMemoryStore[int]()
+	store.Put("product:1", 100)
+
+	cache := New[int](store,
+		WithMaxBatch(3),
+		WithFlushInterval(50*time.Millisecond),
+		WithRetry(2, 5*time.Millisecond, 20*time.Millisecond),
+		WithErrorHandler(func(keys int, err error) {
+			fmt.Printf("  一批 %d 个键写入失败，稍后重试: %v\n", keys, err)
+		}),
+	)
+
+	// 读穿透：首次读取从存储加载
+	views, _ := cache.Get(ctx, "product:1")
+	fmt.Println("product:1 初始浏览量:", views)
+
+	// 同一个键的多次写入在持久化前合并
+	for i := 1; i <= 5; i++ {
+		cache.Set("product:1", views+i)
+	}
+	cache.Set("product:2", 1)
+	fmt.Printf("写入后立即读取缓存: %d，存储中的键: %v\n", mustGet(cache, "product:1"), store.Keys())
+
+	time.Sleep(80 * time.Millisecond)
+	fmt.Println("定时刷新后存储中的键:", store.Keys())
+
+	// 存储暂时故障：重试耗尽后脏数据保留，恢复后由下一次刷新写入
+	store.FailWith(func([]Write[int]) error { return errors.New("数据库连接断开") })
+	cache.Set("product:3", 7)
+	cache.Delete("product:2")
+	time.Sleep(80 * time.Millisecond)
+	store.FailWith(nil)
+
+	// 关闭时把剩余脏数据写入存储
+	if err := cache.Close(ctx); err != nil {
+		fmt.Println("关闭失败:", err)
+	}
+	fmt.Println("关闭后存储中的键:", store.Keys())
+
+	stats := cache.Stats()
+	fmt.Printf("统计: 写入 %d 次，合并 %d 次，持久化 %d 批共 %d 个键，重试 %d 次，失败 %d 批\n",
+		stats.Writes, stats.Coalesced, stats.Flushes, stats.Persisted, stats.Retries, stats.Failures)
+}
+
+func mustGet(c *Cache[int], key string) int {
+	v, _ := c.Get(context.Background(), key)
+	return v
+}
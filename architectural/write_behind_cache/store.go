@@ -0,0 +1,107 @@
+package write_behind_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrNotFound 键不存在
+var ErrNotFound = errors.New("键不存在")
+
+// Write 一次待持久化的写入
+type Write[V any] struct {
+	Key     string
+	Value   V
+	Deleted bool // 为 true 时表示删除，Value 无意义
+}
+
+// Store 后端存储，缓存通过它读取未命中的数据并批量持久化写入
+type Store[V any] interface {
+	// Load 读取一个键，不存在时返回 ErrNotFound
+	Load(ctx context.Context, key string) (V, error)
+	// WriteBatch 持久化一批写入，返回错误时缓存会重试整批，实现需要保证重复写入是安全的
+	WriteBatch(ctx context.Context, writes []Write[V]) error
+}
+
+// MemoryStore 内存中的存储，用于示例和测试，可以注入失败
+type MemoryStore[V any] struct {
+	mutex   sync.Mutex
+	data    map[string]V
+	batches [][]Write[V]
+	fail    func(writes []Write[V]) error // 非 nil 时在写入前调用，返回错误则本次写入失败
+}
+
+// NewMemoryStore 创建内存存储
+func NewMemoryStore[V any]() *MemoryStore[V] {
+	return &MemoryStore[V]{data: make(map[string]V)}
+}
+
+// Load 读取一个键
+func (s *MemoryStore[V]) Load(ctx context.Context, key string) (V, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	v, exists := s.data[key]
+	if !exists {
+		return v, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return v, nil
+}
+
+// WriteBatch 写入一批数据
+func (s *MemoryStore[V]) WriteBatch(ctx context.Context, writes []Write[V]) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.fail != nil {
+		if err := s.fail(writes); err != nil {
+			return err
+		}
+	}
+	for _, w := range writes {
+		if w.Deleted {
+			delete(s.data, w.Key)
+		} else {
+			s.data[w.Key] = w.Value
+		}
+	}
+	s.batches = append(s.batches, append([]Write[V](nil), writes...))
+	return nil
+}
+
+// Put 直接写入存储，绕过缓存，用于准备数据
+func (s *MemoryStore[V]) Put(key string, value V) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.data[key] = value
+}
+
+// Keys 返回存储中的所有键，已排序
+func (s *MemoryStore[V]) Keys() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	keys := make([]string, 0, len(s.data))
+	for k := range s.data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Batches 返回成功写入的批次
+func (s *MemoryStore[V]) Batches() [][]Write[V] {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([][]Write[V](nil), s.batches...)
+}
+
+// FailWith 设置失败注入函数，传入 nil 恢复正常
+func (s *MemoryStore[V]) FailWith(fail func(writes []Write[V]) error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.fail = fail
+}
@@ -0,0 +1,212 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrExecutorClosed 表示合并执行器已经关闭，不再接受新命令
+var ErrExecutorClosed = errors.New("合并执行器已关闭")
+
+// Coalescable 是可以被合并的命令
+// CoalesceKey 相同的命令修改的是同一个设备的同一个属性，窗口内只需执行最后一条
+type Coalescable interface {
+	Command
+	CoalesceKey() string
+}
+
+// CoalesceKey 设置亮度的命令按灯合并，拖动滑块产生的一串命令只保留最终亮度
+func (c *SetLevelCommand) CoalesceKey() string {
+	return c.light.name + "/亮度"
+}
+
+// CoalesceStats 合并执行器的统计信息
+type CoalesceStats struct {
+	Submitted  int // 提交的命令数
+	Dispatched int // 实际执行的命令数
+	Coalesced  int // 被后续命令覆盖而跳过的命令数
+	Failed     int // 执行失败的命令数
+}
+
+// String 格式化统计信息
+func (s CoalesceStats) String() string {
+	return fmt.Sprintf("提交 %d 条，执行 %d 条，合并 %d 条，失败 %d 条",
+		s.Submitted, s.Dispatched, s.Coalesced, s.Failed)
+}
+
+// CoalescingOption 合并执行器的配置选项
+type CoalescingOption func(*CoalescingExecutor)
+
+// WithDispatchHandler 设置命令执行后的回调，可用于记录异步执行的错误
+func WithDispatchHandler(handler func(cmd Command, err error)) CoalescingOption {
+	return func(e *CoalescingExecutor) {
+		e.onDispatch = handler
+	}
+}
+
+// pendingCommand 等待窗口结束的命令
+type pendingCommand struct {
+	cmd   Command
+	timer *time.Timer
+}
+
+// CoalescingExecutor 合并执行器 - 把窗口内针对同一设备属性的多条命令合并为最后一条
+//
+// 同一个键的第一条命令开启一个窗口，窗口结束时执行窗口内最后提交的命令；
+// 不可合并的命令会先执行所有等待中的命令再立即执行，以保持命令之间的先后顺序。
+// 命令的执行是串行的，设备不需要自己处理并发
+type CoalescingExecutor struct {
+	window     time.Duration
+	onDispatch func(cmd Command, err error)
+
+	mu      sync.Mutex
+	pending map[string]*pendingCommand
+	order   []string // 等待中的键，按窗口开启的顺序排列
+	stats   CoalesceStats
+	closed  bool
+
+	dispatchMu sync.Mutex // 串行化命令执行
+}
+
+// NewCoalescingExecutor 创建一个合并窗口为 window 的执行器
+func NewCoalescingExecutor(window time.Duration, opts ...CoalescingOption) *CoalescingExecutor {
+	e := &CoalescingExecutor{
+		window:  window,
+		pending: make(map[string]*pendingCommand),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Submit 提交一条命令
+// 可合并的命令在窗口结束时异步执行，返回 nil；不可合并的命令立即执行并返回执行结果
+func (e *CoalescingExecutor) Submit(cmd Command) error {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return ErrExecutorClosed
+	}
+	e.stats.Submitted++
+
+	c, ok := cmd.(Coalescable)
+	if !ok || e.window <= 0 {
+		e.mu.Unlock()
+		e.Flush()
+		return e.dispatch(cmd)
+	}
+
+	key := c.CoalesceKey()
+	if p, exists := e.pending[key]; exists {
+		p.cmd = cmd
+		e.stats.Coalesced++
+		e.mu.Unlock()
+		return nil
+	}
+
+	p := &pendingCommand{cmd: cmd}
+	p.timer = time.AfterFunc(e.window, func() { e.fire(key, p) })
+	e.pending[key] = p
+	e.order = append(e.order, key)
+	e.mu.Unlock()
+	return nil
+}
+
+// fire 窗口结束时执行该键最后提交的命令
+func (e *CoalescingExecutor) fire(key string, p *pendingCommand) {
+	e.dispatchMu.Lock()
+	defer e.dispatchMu.Unlock()
+
+	e.mu.Lock()
+	// 窗口结束前已经被 Flush 执行
+	if e.pending[key] != p {
+		e.mu.Unlock()
+		return
+	}
+	e.removeUnsafe(key)
+	cmd := p.cmd
+	e.mu.Unlock()
+
+	e.dispatchLocked(cmd)
+}
+
+// Flush 立即执行所有等待中的命令，按窗口开启的顺序执行
+func (e *CoalescingExecutor) Flush() {
+	e.dispatchMu.Lock()
+	defer e.dispatchMu.Unlock()
+
+	e.mu.Lock()
+	cmds := make([]Command, 0, len(e.order))
+	for _, key := range e.order {
+		p := e.pending[key]
+		p.timer.Stop()
+		cmds = append(cmds, p.cmd)
+	}
+	e.pending = make(map[string]*pendingCommand)
+	e.order = nil
+	e.mu.Unlock()
+
+	for _, cmd := range cmds {
+		e.dispatchLocked(cmd)
+	}
+}
+
+// Close 关闭执行器，执行所有等待中的命令，之后提交的命令返回 ErrExecutorClosed
+func (e *CoalescingExecutor) Close() {
+	e.mu.Lock()
+	e.closed = true
+	e.mu.Unlock()
+	e.Flush()
+}
+
+// Pending 返回等待中的命令数
+func (e *CoalescingExecutor) Pending() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.pending)
+}
+
+// Stats 返回统计信息
+func (e *CoalescingExecutor) Stats() CoalesceStats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.stats
+}
+
+// removeUnsafe 从等待队列中移除键，调用者需持有 mu
+func (e *CoalescingExecutor) removeUnsafe(key string) {
+	delete(e.pending, key)
+	for i, k := range e.order {
+		if k == key {
+			e.order = append(e.order[:i], e.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// dispatch 串行执行一条命令
+func (e *CoalescingExecutor) dispatch(cmd Command) error {
+	e.dispatchMu.Lock()
+	defer e.dispatchMu.Unlock()
+	return e.dispatchLocked(cmd)
+}
+
+// dispatchLocked 执行命令并记录结果，调用者需持有 dispatchMu
+func (e *CoalescingExecutor) dispatchLocked(cmd Command) error {
+	err := cmd.Execute()
+
+	e.mu.Lock()
+	e.stats.Dispatched++
+	if err != nil {
+		e.stats.Failed++
+	}
+	e.mu.Unlock()
+
+	if e.onDispatch != nil {
+		e.onDispatch(cmd, err)
+	}
+	return err
+}
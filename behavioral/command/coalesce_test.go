@@ -0,0 +1,116 @@
+package command
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingCommand 记录执行次数的可合并命令，用于模拟健谈的设备
+type countingCommand struct {
+	NoOpCommand
+	key   string
+	value int
+	log   *[]string
+	err   error
+}
+
+func (c *countingCommand) Execute() error {
+	*c.log = append(*c.log, c.Name())
+	return c.err
+}
+
+func (c *countingCommand) Name() string {
+	return c.key + "=" + string(rune('0'+c.value))
+}
+
+func (c *countingCommand) CoalesceKey() string { return c.key }
+
+// 测试拖动滑块产生的一串亮度命令被合并为最终亮度
+func TestCoalescingSetLevel(t *testing.T) {
+	light := NewLight("客厅灯")
+	executor := NewCoalescingExecutor(time.Hour)
+
+	output := captureOutput(func() {
+		for level := 5; level <= 100; level += 5 {
+			assert.NoError(t, executor.Submit(NewSetLevelCommand(light, level)))
+		}
+		assert.Equal(t, 1, executor.Pending())
+		assert.Equal(t, 0, light.Level(), "窗口结束前不应操作设备")
+		executor.Flush()
+	})
+
+	assert.Equal(t, 100, light.Level())
+	assert.Equal(t, 1, strings.Count(output, "亮度设置为"), "20 条命令只应操作设备一次")
+	assert.Equal(t, CoalesceStats{Submitted: 20, Dispatched: 1, Coalesced: 19}, executor.Stats())
+}
+
+// 测试窗口结束后自动执行，不同的键互不影响
+func TestCoalescingWindow(t *testing.T) {
+	var log []string
+	executor := NewCoalescingExecutor(20 * time.Millisecond)
+
+	submit := func(key string, value int) {
+		assert.NoError(t, executor.Submit(&countingCommand{key: key, value: value, log: &log}))
+	}
+	submit("灯", 1)
+	submit("音量", 1)
+	submit("灯", 2)
+	submit("灯", 3)
+
+	// 统计在命令执行之后更新，读取统计后可以安全读取日志
+	assert.Eventually(t, func() bool { return executor.Stats().Dispatched == 2 }, time.Second, 5*time.Millisecond)
+	assert.ElementsMatch(t, []string{"灯=3", "音量=1"}, log)
+
+	// 窗口结束后提交的命令开启新的窗口
+	submit("灯", 4)
+	assert.Eventually(t, func() bool { return executor.Stats().Dispatched == 3 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 2, executor.Stats().Coalesced)
+}
+
+// 测试不可合并的命令会先执行等待中的命令，保持先后顺序
+func TestCoalescingPreservesOrder(t *testing.T) {
+	light := NewLight("卧室灯")
+	executor := NewCoalescingExecutor(time.Hour)
+
+	captureOutput(func() {
+		executor.Submit(NewSetLevelCommand(light, 30))
+		executor.Submit(NewSetLevelCommand(light, 60))
+		assert.NoError(t, executor.Submit(NewTurnOffCommand(light)))
+	})
+
+	assert.False(t, light.IsOn(), "关灯应在调亮之后执行")
+	assert.Equal(t, 0, executor.Pending())
+	assert.Equal(t, 2, executor.Stats().Dispatched)
+
+	// 不可合并命令的错误直接返回
+	var err error
+	captureOutput(func() { err = executor.Submit(NewTurnOffCommand(light)) })
+	assert.Error(t, err)
+	assert.Equal(t, 1, executor.Stats().Failed)
+}
+
+// 测试关闭时执行所有等待中的命令，之后拒绝新命令
+func TestCoalescingFlushOnClose(t *testing.T) {
+	var log []string
+	var failures []error
+	boom := errors.New("设备无响应")
+	executor := NewCoalescingExecutor(time.Hour, WithDispatchHandler(func(_ Command, err error) {
+		if err != nil {
+			failures = append(failures, err)
+		}
+	}))
+
+	executor.Submit(&countingCommand{key: "窗帘", value: 1, log: &log})
+	executor.Submit(&countingCommand{key: "空调", value: 2, log: &log, err: boom})
+	executor.Submit(&countingCommand{key: "窗帘", value: 3, log: &log})
+	executor.Close()
+
+	assert.Equal(t, []string{"窗帘=3", "空调=2"}, log, "按窗口开启的顺序执行")
+	assert.Equal(t, []error{boom}, failures)
+	assert.ErrorIs(t, executor.Submit(&countingCommand{key: "窗帘", log: &log}), ErrExecutorClosed)
+	assert.Equal(t, "提交 3 条，执行 2 条，合并 1 条，失败 1 条", executor.Stats().String())
+}
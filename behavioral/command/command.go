@@ -3,6 +3,8 @@ package command
 import (
 	"fmt"
 	"strings"
+
+	"github.com/XiaoluCoding626/go-design-pattern/behavioral/history"
 )
 
 // Command 接口定义了命令的执行和撤销方法
@@ -10,6 +12,8 @@ type Command interface {
 	Execute() error
 	Undo() error
 	Name() string
+	// DryRun 返回执行命令将产生的变化，但不真正修改设备状态
+	DryRun() (PlannedChanges, error)
 }
 
 // Device 表示可以接收命令的设备接口
@@ -21,9 +25,10 @@ type Device interface {
 
 // Light 表示灯的接收者
 type Light struct {
-	name  string
-	isOn  bool
-	level int // 亮度级别
+	name    string
+	isOn    bool
+	level   int  // 亮度级别
+	offline bool // 设备是否离线
 }
 
 // NewLight 创建一个新的灯
@@ -37,6 +42,9 @@ func NewLight(name string) *Light {
 
 // On 打开灯的操作
 func (l *Light) On() error {
+	if err := l.Ping(); err != nil {
+		return err
+	}
 	if l.isOn {
 		return fmt.Errorf("%s 已经是开启状态", l.name)
 	}
@@ -48,6 +56,9 @@ func (l *Light) On() error {
 
 // Off 关闭灯的操作
 func (l *Light) Off() error {
+	if err := l.Ping(); err != nil {
+		return err
+	}
 	if !l.isOn {
 		return fmt.Errorf("%s 已经是关闭状态", l.name)
 	}
@@ -64,6 +75,9 @@ func (l *Light) GetName() string {
 
 // SetLevel 设置灯的亮度
 func (l *Light) SetLevel(level int) error {
+	if err := l.Ping(); err != nil {
+		return err
+	}
 	if level < 0 || level > 100 {
 		return fmt.Errorf("亮度必须在0-100之间")
 	}
@@ -83,6 +97,7 @@ type TV struct {
 	isOn    bool
 	volume  int
 	channel int
+	offline bool // 设备是否离线
 }
 
 // NewTV 创建一个新的电视
@@ -97,6 +112,9 @@ func NewTV(name string) *TV {
 
 // On 打开电视的操作
 func (t *TV) On() error {
+	if err := t.Ping(); err != nil {
+		return err
+	}
 	if t.isOn {
 		return fmt.Errorf("%s 已经是开启状态", t.name)
 	}
@@ -107,6 +125,9 @@ func (t *TV) On() error {
 
 // Off 关闭电视的操作
 func (t *TV) Off() error {
+	if err := t.Ping(); err != nil {
+		return err
+	}
 	if !t.isOn {
 		return fmt.Errorf("%s 已经是关闭状态", t.name)
 	}
@@ -273,10 +294,12 @@ func (m *MacroCommand) Name() string {
 
 // RemoteControl 表示命令调用者（遥控器）
 type RemoteControl struct {
-	onCommands    []Command
-	offCommands   []Command
-	history       []Command
-	maxHistoryLen int
+	onCommands  []Command
+	offCommands []Command
+	history     *history.HistoryManager[struct{}] // 命令修改的状态保存在设备中，历史只记录命令本身
+	principal   *Principal                        // 当前绑定的用户身份
+	auditLog    *AuditLog                         // 记录被拒绝的操作和管理员越权操作
+	publisher   EventPublisher                    // 接收命令执行和撤销事件
 }
 
 // NewRemoteControl 创建一个新的遥控器
@@ -291,10 +314,9 @@ func NewRemoteControl(slots int) *RemoteControl {
 	}
 
 	return &RemoteControl{
-		onCommands:    onCommands,
-		offCommands:   offCommands,
-		history:       make([]Command, 0),
-		maxHistoryLen: 10,
+		onCommands:  onCommands,
+		offCommands: offCommands,
+		history:     history.New(struct{}{}, history.WithCapacity(10)),
 	}
 }
 
@@ -316,10 +338,14 @@ func (r *RemoteControl) OnButtonPressed(slot int) error {
 	}
 
 	cmd := r.onCommands[slot]
-	err := cmd.Execute()
-	if err == nil {
-		r.addToHistory(cmd)
+	if err := r.authorize(cmd); err != nil {
+		return err
+	}
+	if err := CheckPreconditions(cmd); err != nil {
+		return err
 	}
+	err := r.execute(cmd)
+	r.publish(CommandExecuted, cmd, err)
 	return err
 }
 
@@ -330,45 +356,77 @@ func (r *RemoteControl) OffButtonPressed(slot int) error {
 	}
 
 	cmd := r.offCommands[slot]
-	err := cmd.Execute()
-	if err == nil {
-		r.addToHistory(cmd)
+	if err := r.authorize(cmd); err != nil {
+		return err
+	}
+	if err := CheckPreconditions(cmd); err != nil {
+		return err
 	}
+	err := r.execute(cmd)
+	r.publish(CommandExecuted, cmd, err)
 	return err
 }
 
-// addToHistory 添加命令到历史记录
-func (r *RemoteControl) addToHistory(cmd Command) {
-	r.history = append(r.history, cmd)
-	if len(r.history) > r.maxHistoryLen {
-		// 移除最旧的命令
-		r.history = r.history[1:]
-	}
+// commandDiff 把命令适配为历史中的增量：执行即应用，撤销即回退
+type commandDiff struct {
+	cmd Command
 }
 
-// UndoLastCommand 撤销最后执行的命令
+func (d commandDiff) Apply(s struct{}) (struct{}, error)  { return s, d.cmd.Execute() }
+func (d commandDiff) Revert(s struct{}) (struct{}, error) { return s, d.cmd.Undo() }
+
+// execute 执行命令，成功时记入历史，最多保留最近 10 条
+func (r *RemoteControl) execute(cmd Command) error {
+	_, err := r.history.Apply(cmd.Name(), commandDiff{cmd: cmd})
+	return err
+}
+
+// UndoLastCommand 撤销最后执行的命令，撤销失败时命令保留在历史中，可以再次尝试
 func (r *RemoteControl) UndoLastCommand() error {
-	if len(r.history) == 0 {
+	entry, ok := r.history.PeekUndo()
+	if !ok {
 		return fmt.Errorf("没有可撤销的命令")
 	}
+	lastCmd := entry.Diff.(commandDiff).cmd
+	if err := r.authorize(lastCmd); err != nil {
+		return err
+	}
+
+	_, err := r.history.Undo()
+	r.publish(CommandUndone, lastCmd, err)
+	return err
+}
 
-	lastIndex := len(r.history) - 1
-	lastCmd := r.history[lastIndex]
-	r.history = r.history[:lastIndex]
+// RedoLastCommand 重新执行最近撤销的命令，执行新命令后之前撤销的命令不能再重做
+func (r *RemoteControl) RedoLastCommand() error {
+	entry, ok := r.history.PeekRedo()
+	if !ok {
+		return fmt.Errorf("没有可重做的命令")
+	}
+	cmd := entry.Diff.(commandDiff).cmd
+	if err := r.authorize(cmd); err != nil {
+		return err
+	}
+	if err := CheckPreconditions(cmd); err != nil {
+		return err
+	}
 
-	return lastCmd.Undo()
+	_, err := r.history.Redo()
+	r.publish(CommandExecuted, cmd, err)
+	return err
 }
 
 // ShowHistory 展示命令历史记录
 func (r *RemoteControl) ShowHistory() {
-	if len(r.history) == 0 {
+	entries := r.history.UndoEntries()
+	if len(entries) == 0 {
 		fmt.Println("命令历史记录为空")
 		return
 	}
 
 	fmt.Println("命令历史记录:")
-	for i, cmd := range r.history {
-		fmt.Printf("%d: %s\n", i+1, cmd.Name())
+	for i, entry := range entries {
+		fmt.Printf("%d: %s\n", i+1, entry.Label)
 	}
 }
 
@@ -379,6 +437,11 @@ func (c *NoOpCommand) Execute() error { return nil }
 func (c *NoOpCommand) Undo() error    { return nil }
 func (c *NoOpCommand) Name() string   { return "无操作" }
 
+// DryRun 无操作命令不会产生任何变化
+func (c *NoOpCommand) DryRun() (PlannedChanges, error) {
+	return PlannedChanges{Command: c.Name()}, nil
+}
+
 // String 返回遥控器描述
 func (r *RemoteControl) String() string {
 	var sb strings.Builder
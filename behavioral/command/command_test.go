@@ -230,6 +230,47 @@ func TestRemoteControlHistory(t *testing.T) {
 	assert.Contains(t, err.Error(), "没有可撤销的命令")
 }
 
+// 测试重做撤销的命令，以及历史记录的容量
+func TestRemoteControlRedo(t *testing.T) {
+	remote := NewRemoteControl(1)
+	light := NewLight("书房灯")
+	remote.SetCommand(0, NewTurnOnCommand(light), NewTurnOffCommand(light))
+
+	err := remote.RedoLastCommand()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "没有可重做的命令")
+
+	captureOutput(func() {
+		assert.NoError(t, remote.OnButtonPressed(0))
+		assert.NoError(t, remote.UndoLastCommand())
+	})
+	assert.False(t, light.isOn)
+
+	output := captureOutput(func() {
+		assert.NoError(t, remote.RedoLastCommand())
+	})
+	assert.Contains(t, output, "书房灯 已打开")
+	assert.True(t, light.isOn)
+
+	// 撤销后执行新命令会清空重做历史
+	captureOutput(func() {
+		assert.NoError(t, remote.UndoLastCommand())
+		assert.NoError(t, remote.OnButtonPressed(0))
+	})
+	assert.Error(t, remote.RedoLastCommand())
+
+	// 最多保留最近 10 条命令
+	captureOutput(func() {
+		for i := 0; i < 15; i++ {
+			remote.SetCommand(0, NewSetLevelCommand(light, i), &NoOpCommand{})
+			assert.NoError(t, remote.OnButtonPressed(0))
+		}
+		for remote.UndoLastCommand() == nil {
+		}
+	})
+	assert.Equal(t, 4, light.level, "只能撤销到第 6 条命令之前的亮度")
+}
+
 // 测试复杂场景：家庭自动化
 func TestHomeAutomation(t *testing.T) {
 	remote := NewRemoteControl(4)
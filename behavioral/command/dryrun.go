@@ -0,0 +1,457 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrDeviceUnreachable 表示设备离线，无法接收命令
+	ErrDeviceUnreachable = errors.New("设备不可达")
+	// ErrPreconditionFailed 表示命令的前置条件不满足
+	ErrPreconditionFailed = errors.New("前置条件不满足")
+)
+
+// SetOnline 设置灯是否在线，离线的灯拒绝所有操作
+func (l *Light) SetOnline(online bool) {
+	l.offline = !online
+}
+
+// Ping 检查灯是否可达
+func (l *Light) Ping() error {
+	if l.offline {
+		return fmt.Errorf("%w: %s", ErrDeviceUnreachable, l.name)
+	}
+	return nil
+}
+
+// IsOn 返回灯是否开启
+func (l *Light) IsOn() bool {
+	return l.isOn
+}
+
+// Level 返回灯的当前亮度
+func (l *Light) Level() int {
+	return l.level
+}
+
+// SetOnline 设置电视是否在线，离线的电视拒绝所有操作
+func (t *TV) SetOnline(online bool) {
+	t.offline = !online
+}
+
+// Ping 检查电视是否可达
+func (t *TV) Ping() error {
+	if t.offline {
+		return fmt.Errorf("%w: %s", ErrDeviceUnreachable, t.name)
+	}
+	return nil
+}
+
+// IsOn 返回电视是否开启
+func (t *TV) IsOn() bool {
+	return t.isOn
+}
+
+// Pinger 是可以检查可达性的设备
+type Pinger interface {
+	Ping() error
+}
+
+// StatefulDevice 是可以查询电源状态的设备，预演时据此计算状态变化
+type StatefulDevice interface {
+	Device
+	IsOn() bool
+}
+
+// Change 描述命令将对设备某个属性做出的修改
+type Change struct {
+	Device    string // 设备名称
+	Attribute string // 属性名称，如 "电源"、"亮度"
+	From      string // 修改前的值
+	To        string // 修改后的值
+}
+
+// String 返回变化描述
+func (c Change) String() string {
+	return fmt.Sprintf("%s %s: %s → %s", c.Device, c.Attribute, c.From, c.To)
+}
+
+// PlannedChanges 是命令预演的结果
+type PlannedChanges struct {
+	Command string   // 命令名称
+	Changes []Change // 按执行顺序排列的变化
+	Opaque  bool     // 命令无法预演时为 true，此时 Changes 不完整
+}
+
+// IsEmpty 判断命令是否不会产生任何已知变化
+func (p PlannedChanges) IsEmpty() bool {
+	return len(p.Changes) == 0 && !p.Opaque
+}
+
+// String 返回预演结果的可读描述
+func (p PlannedChanges) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("预演 %s:", p.Command))
+	if len(p.Changes) == 0 && !p.Opaque {
+		sb.WriteString(" 无变化")
+	}
+	for _, change := range p.Changes {
+		sb.WriteString("\n  " + change.String())
+	}
+	if p.Opaque {
+		sb.WriteString("\n  (部分效果无法预演)")
+	}
+	return sb.String()
+}
+
+// LegacyCommand 是没有实现 DryRun 的旧式命令
+type LegacyCommand interface {
+	Execute() error
+	Undo() error
+	Name() string
+}
+
+// legacyAdapter 为旧式命令提供默认的 DryRun 实现
+type legacyAdapter struct {
+	LegacyCommand
+}
+
+// DryRun 旧式命令无法预演，只返回命令名称并标记为不透明
+func (a legacyAdapter) DryRun() (PlannedChanges, error) {
+	if err := CheckPreconditions(a); err != nil {
+		return PlannedChanges{}, err
+	}
+	return PlannedChanges{Command: a.Name(), Opaque: true}, nil
+}
+
+// Preconditions 透传旧式命令自身声明的前置条件
+func (a legacyAdapter) Preconditions() []Precondition {
+	if conditional, ok := a.LegacyCommand.(Conditional); ok {
+		return conditional.Preconditions()
+	}
+	return nil
+}
+
+// AdaptLegacy 把旧式命令适配为 Command，已经实现 Command 的命令原样返回
+func AdaptLegacy(cmd LegacyCommand) Command {
+	if command, ok := cmd.(Command); ok {
+		return command
+	}
+	return legacyAdapter{LegacyCommand: cmd}
+}
+
+// Precondition 是命令执行前需要满足的条件
+type Precondition struct {
+	Description string       // 条件描述，如 "客厅灯 可达"
+	Check       func() error // 条件不满足时返回错误
+}
+
+// Conditional 是声明了前置条件的命令
+type Conditional interface {
+	Preconditions() []Precondition
+}
+
+// reachable 返回设备可达的前置条件，设备不支持 Ping 时返回 false
+func reachable(device Device) (Precondition, bool) {
+	pinger, ok := device.(Pinger)
+	if !ok {
+		return Precondition{}, false
+	}
+	return Precondition{
+		Description: fmt.Sprintf("%s 可达", device.GetName()),
+		Check:       pinger.Ping,
+	}, true
+}
+
+// devicePreconditions 返回设备相关的前置条件
+func devicePreconditions(device Device) []Precondition {
+	if p, ok := reachable(device); ok {
+		return []Precondition{p}
+	}
+	return nil
+}
+
+// Preconditions 开启命令要求设备可达
+func (c *TurnOnCommand) Preconditions() []Precondition {
+	return devicePreconditions(c.device)
+}
+
+// Preconditions 关闭命令要求设备可达
+func (c *TurnOffCommand) Preconditions() []Precondition {
+	return devicePreconditions(c.device)
+}
+
+// Preconditions 设置亮度命令要求灯可达
+func (c *SetLevelCommand) Preconditions() []Precondition {
+	return devicePreconditions(c.light)
+}
+
+// Preconditions 宏命令要求所有子命令的前置条件都满足
+func (m *MacroCommand) Preconditions() []Precondition {
+	var result []Precondition
+	seen := make(map[string]bool)
+	for _, sub := range m.commands {
+		for _, p := range preconditionsOf(sub) {
+			if !seen[p.Description] {
+				seen[p.Description] = true
+				result = append(result, p)
+			}
+		}
+	}
+	return result
+}
+
+// unwrapCommand 去掉权限包装，返回实际执行的命令
+func unwrapCommand(cmd Command) Command {
+	for {
+		switch c := cmd.(type) {
+		case *RestrictedCommand:
+			cmd = c.Command
+		case *AdminOverrideCommand:
+			cmd = c.Command
+		default:
+			return cmd
+		}
+	}
+}
+
+// preconditionsOf 返回命令声明的前置条件，会穿透权限包装
+func preconditionsOf(cmd Command) []Precondition {
+	if conditional, ok := unwrapCommand(cmd).(Conditional); ok {
+		return conditional.Preconditions()
+	}
+	return nil
+}
+
+// CheckPreconditions 在执行前检查命令的前置条件：
+// 先检查设备可达等声明的条件，再按当前设备状态预演命令，确认状态转换合法
+func CheckPreconditions(cmd Command) error {
+	if err := checkDeclared(preconditionsOf(cmd)); err != nil {
+		return err
+	}
+	if p, ok := unwrapCommand(cmd).(planner); ok {
+		if _, err := p.plan(newSimulation()); err != nil {
+			return fmt.Errorf("%w: %w", ErrPreconditionFailed, err)
+		}
+	}
+	return nil
+}
+
+// checkDeclared 依次检查声明的前置条件，返回第一个不满足的条件
+func checkDeclared(preconditions []Precondition) error {
+	for _, p := range preconditions {
+		if err := p.Check(); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrPreconditionFailed, p.Description, err)
+		}
+	}
+	return nil
+}
+
+// planner 是可以在模拟状态上预演的命令
+// 宏命令的子命令共享同一份模拟状态，因此后面的子命令能看到前面子命令的效果
+type planner interface {
+	plan(sim *simulation) ([]Change, error)
+}
+
+// deviceState 是预演过程中设备的模拟状态
+type deviceState struct {
+	on    bool
+	level int
+}
+
+// simulation 记录预演过程中被修改的设备状态，未修改的设备读取真实状态
+type simulation struct {
+	states map[Device]*deviceState
+}
+
+// newSimulation 创建空的模拟状态
+func newSimulation() *simulation {
+	return &simulation{states: make(map[Device]*deviceState)}
+}
+
+// state 返回设备的模拟状态，设备无法查询状态时返回 false
+func (s *simulation) state(device Device) (*deviceState, bool) {
+	if state, ok := s.states[device]; ok {
+		return state, true
+	}
+	stateful, ok := device.(StatefulDevice)
+	if !ok {
+		return nil, false
+	}
+	state := &deviceState{on: stateful.IsOn()}
+	if light, ok := device.(*Light); ok {
+		state.level = light.level
+	}
+	s.states[device] = state
+	return state, true
+}
+
+// dryRun 检查前置条件后在新的模拟状态上预演命令
+func dryRun(cmd Command, p planner) (PlannedChanges, error) {
+	if err := checkDeclared(preconditionsOf(cmd)); err != nil {
+		return PlannedChanges{}, err
+	}
+	changes, err := p.plan(newSimulation())
+	if err != nil {
+		return PlannedChanges{}, fmt.Errorf("%w: %w", ErrPreconditionFailed, err)
+	}
+	return PlannedChanges{Command: cmd.Name(), Changes: changes}, nil
+}
+
+// powerLabel 返回电源状态的描述
+func powerLabel(on bool) string {
+	if on {
+		return "开"
+	}
+	return "关"
+}
+
+// planPower 预演开关操作
+func planPower(sim *simulation, device Device, on bool) ([]Change, error) {
+	state, ok := sim.state(device)
+	if !ok {
+		return []Change{{Device: device.GetName(), Attribute: "电源", From: "未知", To: powerLabel(on)}}, nil
+	}
+	if state.on == on {
+		if on {
+			return nil, fmt.Errorf("%s 已经是开启状态", device.GetName())
+		}
+		return nil, fmt.Errorf("%s 已经是关闭状态", device.GetName())
+	}
+
+	changes := []Change{{Device: device.GetName(), Attribute: "电源", From: powerLabel(state.on), To: powerLabel(on)}}
+	if _, isLight := device.(*Light); isLight {
+		// 灯开启时亮度为 100，关闭时为 0
+		level := 0
+		if on {
+			level = 100
+		}
+		if state.level != level {
+			changes = append(changes, levelChange(device.GetName(), state.level, level))
+		}
+		state.level = level
+	}
+	state.on = on
+	return changes, nil
+}
+
+// levelChange 返回亮度变化
+func levelChange(name string, from, to int) Change {
+	return Change{Device: name, Attribute: "亮度", From: fmt.Sprintf("%d%%", from), To: fmt.Sprintf("%d%%", to)}
+}
+
+// plan 预演开启命令
+func (c *TurnOnCommand) plan(sim *simulation) ([]Change, error) {
+	return planPower(sim, c.device, true)
+}
+
+// DryRun 预演开启命令
+func (c *TurnOnCommand) DryRun() (PlannedChanges, error) {
+	return dryRun(c, c)
+}
+
+// plan 预演关闭命令
+func (c *TurnOffCommand) plan(sim *simulation) ([]Change, error) {
+	return planPower(sim, c.device, false)
+}
+
+// DryRun 预演关闭命令
+func (c *TurnOffCommand) DryRun() (PlannedChanges, error) {
+	return dryRun(c, c)
+}
+
+// plan 预演设置亮度命令
+func (c *SetLevelCommand) plan(sim *simulation) ([]Change, error) {
+	if c.level < 0 || c.level > 100 {
+		return nil, fmt.Errorf("亮度必须在0-100之间")
+	}
+	state, _ := sim.state(c.light)
+
+	var changes []Change
+	if on := c.level > 0; on != state.on {
+		changes = append(changes, Change{Device: c.light.name, Attribute: "电源", From: powerLabel(state.on), To: powerLabel(on)})
+		state.on = on
+	}
+	if state.level != c.level {
+		changes = append(changes, levelChange(c.light.name, state.level, c.level))
+		state.level = c.level
+	}
+	return changes, nil
+}
+
+// DryRun 预演设置亮度命令
+func (c *SetLevelCommand) DryRun() (PlannedChanges, error) {
+	return dryRun(c, c)
+}
+
+// plan 按顺序预演所有子命令，子命令共享模拟状态
+func (m *MacroCommand) plan(sim *simulation) ([]Change, error) {
+	changes, _, err := m.planAll(sim)
+	return changes, err
+}
+
+// planAll 预演所有子命令，opaque 表示存在无法预演的子命令
+func (m *MacroCommand) planAll(sim *simulation) (changes []Change, opaque bool, err error) {
+	for _, sub := range m.commands {
+		if p, ok := unwrapCommand(sub).(planner); ok {
+			subChanges, err := p.plan(sim)
+			if err != nil {
+				return nil, false, fmt.Errorf("宏命令 %s 中的 %s: %w", m.name, sub.Name(), err)
+			}
+			changes = append(changes, subChanges...)
+			continue
+		}
+
+		planned, err := sub.DryRun()
+		if err != nil {
+			return nil, false, fmt.Errorf("宏命令 %s 中的 %s: %w", m.name, sub.Name(), err)
+		}
+		changes = append(changes, planned.Changes...)
+		opaque = opaque || planned.Opaque
+	}
+	return changes, opaque, nil
+}
+
+// DryRun 预演宏命令
+func (m *MacroCommand) DryRun() (PlannedChanges, error) {
+	if err := checkDeclared(m.Preconditions()); err != nil {
+		return PlannedChanges{}, err
+	}
+	changes, opaque, err := m.planAll(newSimulation())
+	if err != nil {
+		return PlannedChanges{}, fmt.Errorf("%w: %w", ErrPreconditionFailed, err)
+	}
+	return PlannedChanges{Command: m.name, Changes: changes, Opaque: opaque}, nil
+}
+
+// Preview 返回按下开启按钮将产生的变化，不执行命令也不记录历史
+func (r *RemoteControl) Preview(slot int) (PlannedChanges, error) {
+	if slot < 0 || slot >= len(r.onCommands) {
+		return PlannedChanges{}, fmt.Errorf("无效的插槽编号: %d", slot)
+	}
+	return r.preview(r.onCommands[slot])
+}
+
+// PreviewOff 返回按下关闭按钮将产生的变化，不执行命令也不记录历史
+func (r *RemoteControl) PreviewOff(slot int) (PlannedChanges, error) {
+	if slot < 0 || slot >= len(r.offCommands) {
+		return PlannedChanges{}, fmt.Errorf("无效的插槽编号: %d", slot)
+	}
+	return r.preview(r.offCommands[slot])
+}
+
+// preview 检查权限后预演命令；预览不是真正的操作，因此不写审计日志
+func (r *RemoteControl) preview(cmd Command) (PlannedChanges, error) {
+	if missing := r.missingRoles(cmd); len(missing) > 0 {
+		return PlannedChanges{}, fmt.Errorf("%w: %s 执行 %s 缺少角色 %v",
+			ErrPermissionDenied, r.principal, cmd.Name(), missing)
+	}
+	planned, err := cmd.DryRun()
+	if err != nil {
+		return PlannedChanges{}, err
+	}
+	planned.Command = cmd.Name()
+	return planned, nil
+}
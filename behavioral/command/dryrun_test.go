@@ -0,0 +1,159 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试单个命令的预演不会修改设备状态
+func TestDryRunSimpleCommands(t *testing.T) {
+	light := NewLight("客厅灯")
+
+	planned, err := NewTurnOnCommand(light).DryRun()
+	assert.NoError(t, err)
+	assert.Equal(t, "开启 客厅灯", planned.Command)
+	assert.Equal(t, []Change{
+		{Device: "客厅灯", Attribute: "电源", From: "关", To: "开"},
+		{Device: "客厅灯", Attribute: "亮度", From: "0%", To: "100%"},
+	}, planned.Changes)
+	assert.False(t, light.IsOn(), "预演不应修改设备状态")
+
+	// 与执行时相同的状态检查
+	_, err = NewTurnOffCommand(light).DryRun()
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+	assert.Contains(t, err.Error(), "已经是关闭状态")
+
+	_, err = NewSetLevelCommand(light, 150).DryRun()
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+
+	planned, err = NewSetLevelCommand(light, 30).DryRun()
+	assert.NoError(t, err)
+	assert.Len(t, planned.Changes, 2)
+	assert.Equal(t, 0, light.Level())
+
+	planned, err = (&NoOpCommand{}).DryRun()
+	assert.NoError(t, err)
+	assert.True(t, planned.IsEmpty())
+	assert.Equal(t, "预演 无操作: 无变化", planned.String())
+}
+
+// 测试宏命令的子命令共享模拟状态
+func TestDryRunMacroSimulation(t *testing.T) {
+	light := NewLight("卧室灯")
+	tv := NewTV("卧室电视")
+
+	// 先开灯再调暗，第二步应基于第一步之后的状态
+	macro := NewMacroCommand("观影模式", []Command{
+		NewTurnOnCommand(light),
+		NewSetLevelCommand(light, 20),
+		NewTurnOnCommand(tv),
+	})
+	planned, err := macro.DryRun()
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Device: "卧室灯", Attribute: "电源", From: "关", To: "开"},
+		{Device: "卧室灯", Attribute: "亮度", From: "0%", To: "100%"},
+		{Device: "卧室灯", Attribute: "亮度", From: "100%", To: "20%"},
+		{Device: "卧室电视", Attribute: "电源", From: "关", To: "开"},
+	}, planned.Changes)
+	assert.False(t, light.IsOn())
+	assert.False(t, tv.IsOn())
+
+	// 开了又开：第二个开启命令在模拟状态下不合法
+	invalid := NewMacroCommand("重复开启", []Command{NewTurnOnCommand(tv), NewTurnOnCommand(tv)})
+	_, err = invalid.DryRun()
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+}
+
+// 测试设备不可达时的前置条件
+func TestPreconditionUnreachable(t *testing.T) {
+	light := NewLight("走廊灯")
+	light.SetOnline(false)
+
+	remote := NewRemoteControl(1)
+	remote.SetCommand(0, NewTurnOnCommand(light), NewTurnOffCommand(light))
+
+	err := remote.OnButtonPressed(0)
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+	assert.ErrorIs(t, err, ErrDeviceUnreachable)
+	assert.False(t, light.IsOn())
+
+	_, err = remote.Preview(0)
+	assert.ErrorIs(t, err, ErrDeviceUnreachable)
+
+	// 宏命令中任一设备不可达都会失败
+	macro := NewMacroCommand("全开", []Command{NewTurnOnCommand(NewTV("电视")), NewTurnOnCommand(light)})
+	assert.ErrorIs(t, CheckPreconditions(macro), ErrDeviceUnreachable)
+
+	light.SetOnline(true)
+	var output string
+	output = captureOutput(func() { err = remote.OnButtonPressed(0) })
+	assert.NoError(t, err)
+	assert.Contains(t, output, "走廊灯 已打开")
+}
+
+// 测试遥控器预览
+func TestRemoteControlPreview(t *testing.T) {
+	tv := NewTV("客厅电视")
+	remote := NewRemoteControl(1)
+	remote.SetCommand(0, NewRestrictedCommand(NewTurnOnCommand(tv), RoleMember), NewTurnOffCommand(tv))
+
+	// 权限不足时预览同样被拒绝，但不写审计日志
+	audit := NewAuditLog()
+	remote.SetAuditLog(audit)
+	_, err := remote.Preview(0)
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+	assert.Empty(t, audit.Entries())
+
+	remote.BindPrincipal(NewPrincipal("妈妈", RoleMember))
+	planned, err := remote.Preview(0)
+	assert.NoError(t, err)
+	assert.Equal(t, "开启 客厅电视", planned.Command)
+	assert.Equal(t, []Change{{Device: "客厅电视", Attribute: "电源", From: "关", To: "开"}}, planned.Changes)
+	assert.False(t, tv.IsOn(), "预览不应执行命令")
+	assert.NotPanics(t, remote.ShowHistory)
+
+	_, err = remote.PreviewOff(0)
+	assert.ErrorIs(t, err, ErrPreconditionFailed, "电视已关闭，关闭按钮不可用")
+
+	_, err = remote.Preview(5)
+	assert.Error(t, err)
+}
+
+// legacyCommand 没有实现 DryRun 的旧式命令
+type legacyCommand struct {
+	executed bool
+}
+
+func (c *legacyCommand) Execute() error { c.executed = true; return nil }
+func (c *legacyCommand) Undo() error    { c.executed = false; return nil }
+func (c *legacyCommand) Name() string   { return "旧式命令" }
+
+// 测试旧式命令适配器
+func TestAdaptLegacy(t *testing.T) {
+	legacy := &legacyCommand{}
+	cmd := AdaptLegacy(legacy)
+
+	planned, err := cmd.DryRun()
+	assert.NoError(t, err)
+	assert.True(t, planned.Opaque)
+	assert.False(t, planned.IsEmpty())
+	assert.False(t, legacy.executed)
+
+	// 已经实现 Command 的命令原样返回
+	on := NewTurnOnCommand(NewLight("灯"))
+	assert.Same(t, on, AdaptLegacy(on))
+
+	// 宏命令中的旧式命令使预演结果变为不透明
+	macro := NewMacroCommand("混合", []Command{on, cmd})
+	planned, err = macro.DryRun()
+	assert.NoError(t, err)
+	assert.True(t, planned.Opaque)
+	assert.Len(t, planned.Changes, 2)
+
+	remote := NewRemoteControl(1)
+	remote.SetCommand(0, cmd, &NoOpCommand{})
+	assert.NoError(t, remote.OnButtonPressed(0))
+	assert.True(t, legacy.executed)
+}
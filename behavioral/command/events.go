@@ -0,0 +1,158 @@
+package command
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CommandEventType 表示遥控器发布的事件类型
+type CommandEventType string
+
+const (
+	CommandExecuted CommandEventType = "executed" // 命令已执行
+	CommandUndone   CommandEventType = "undone"   // 命令已撤销
+)
+
+// CommandEvent 遥控器执行或撤销命令后发布的结构化事件
+// 只有通过了权限和前置条件检查、真正调用了命令的操作才会发布事件，被拒绝的尝试记录在审计日志中
+type CommandEvent struct {
+	Type      CommandEventType
+	Command   string    // 命令名称
+	Principal string    // 执行命令的用户
+	Time      time.Time // 事件发生的时间
+	Err       error     // 命令执行或撤销失败时的错误
+}
+
+// Succeeded 返回命令是否执行或撤销成功
+func (e CommandEvent) Succeeded() bool {
+	return e.Err == nil
+}
+
+// String 格式化事件
+func (e CommandEvent) String() string {
+	action := "执行"
+	if e.Type == CommandUndone {
+		action = "撤销"
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("[%s] %s %s %s 失败: %v", e.Time.Format("15:04:05"), e.Principal, action, e.Command, e.Err)
+	}
+	return fmt.Sprintf("[%s] %s %s %s", e.Time.Format("15:04:05"), e.Principal, action, e.Command)
+}
+
+// EventPublisher 接收遥控器发布的命令事件，Publish 在遥控器的调用方 goroutine 中同步调用
+type EventPublisher interface {
+	Publish(event CommandEvent)
+}
+
+// PublisherFunc 把普通函数适配为 EventPublisher
+type PublisherFunc func(event CommandEvent)
+
+// Publish 调用函数本身
+func (f PublisherFunc) Publish(event CommandEvent) {
+	f(event)
+}
+
+// CommandObserver 订阅命令事件的观察者，与 observer 包的 Observer 接口形式相同
+type CommandObserver interface {
+	Update(event CommandEvent) // 接收事件
+	GetID() string             // 获取观察者标识
+}
+
+// CommandSubject 命令事件的主题，提供与 observer 包的 Subject 相同的注册、注销和通知方法
+// 同时实现了 EventPublisher，可以直接设置到遥控器上，让仪表盘和审计程序订阅设备控制活动
+type CommandSubject struct {
+	mutex     sync.RWMutex
+	observers []CommandObserver
+}
+
+// NewCommandSubject 创建一个命令事件主题
+func NewCommandSubject() *CommandSubject {
+	return &CommandSubject{
+		observers: make([]CommandObserver, 0),
+	}
+}
+
+// Register 注册观察者，相同 ID 的观察者只注册一次
+func (s *CommandSubject) Register(observer CommandObserver) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.hasObserverUnsafe(observer) {
+		return
+	}
+	s.observers = append(s.observers, observer)
+}
+
+// Deregister 注销观察者
+func (s *CommandSubject) Deregister(observer CommandObserver) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, obs := range s.observers {
+		if obs.GetID() == observer.GetID() {
+			s.observers = append(s.observers[:i], s.observers[i+1:]...)
+			return
+		}
+	}
+}
+
+// hasObserverUnsafe 检查观察者是否已注册（非线程安全，只在加锁后使用）
+func (s *CommandSubject) hasObserverUnsafe(observer CommandObserver) bool {
+	for _, obs := range s.observers {
+		if obs.GetID() == observer.GetID() {
+			return true
+		}
+	}
+	return false
+}
+
+// HasObserver 检查观察者是否已注册
+func (s *CommandSubject) HasObserver(observer CommandObserver) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.hasObserverUnsafe(observer)
+}
+
+// CountObservers 获取观察者数量
+func (s *CommandSubject) CountObservers() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.observers)
+}
+
+// Notify 按注册顺序同步通知所有观察者，观察者可以在回调中注册或注销观察者
+func (s *CommandSubject) Notify(event CommandEvent) {
+	s.mutex.RLock()
+	observers := append([]CommandObserver(nil), s.observers...)
+	s.mutex.RUnlock()
+
+	for _, observer := range observers {
+		observer.Update(event)
+	}
+}
+
+// Publish 实现 EventPublisher，等价于 Notify
+func (s *CommandSubject) Publish(event CommandEvent) {
+	s.Notify(event)
+}
+
+// SetEventPublisher 设置遥控器发布命令事件的目标，传入 nil 停止发布
+func (r *RemoteControl) SetEventPublisher(publisher EventPublisher) {
+	r.publisher = publisher
+}
+
+// publish 在设置了事件发布者时发布一条命令事件
+func (r *RemoteControl) publish(eventType CommandEventType, cmd Command, err error) {
+	if r.publisher == nil {
+		return
+	}
+	r.publisher.Publish(CommandEvent{
+		Type:      eventType,
+		Command:   cmd.Name(),
+		Principal: r.principal.String(),
+		Time:      time.Now(),
+		Err:       err,
+	})
+}
@@ -0,0 +1,128 @@
+package command
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// eventRecorder 记录收到的命令事件的观察者
+type eventRecorder struct {
+	id     string
+	events []CommandEvent
+}
+
+func (e *eventRecorder) Update(event CommandEvent) {
+	e.events = append(e.events, event)
+}
+
+func (e *eventRecorder) GetID() string {
+	return e.id
+}
+
+// failingCommand 执行总是失败的命令
+type failingCommand struct {
+	name string
+}
+
+func (c *failingCommand) Execute() error { return errors.New("设备无响应") }
+func (c *failingCommand) Undo() error    { return nil }
+func (c *failingCommand) Name() string   { return c.name }
+
+func (c *failingCommand) DryRun() (PlannedChanges, error) {
+	return PlannedChanges{Command: c.name, Opaque: true}, nil
+}
+
+// 测试遥控器执行和撤销命令时发布事件
+func TestRemoteControlPublishesEvents(t *testing.T) {
+	assert := assert.New(t)
+	light := NewLight("客厅灯")
+	remote := NewRemoteControl(1)
+	remote.SetCommand(0, NewTurnOnCommand(light), NewTurnOffCommand(light))
+	remote.BindPrincipal(NewPrincipal("小明", RoleMember))
+
+	var events []CommandEvent
+	remote.SetEventPublisher(PublisherFunc(func(event CommandEvent) {
+		events = append(events, event)
+	}))
+
+	captureOutput(func() {
+		assert.NoError(remote.OnButtonPressed(0))
+		assert.NoError(remote.UndoLastCommand())
+	})
+
+	if assert.Len(events, 2) {
+		assert.Equal(CommandExecuted, events[0].Type)
+		assert.Equal("开启 客厅灯", events[0].Command)
+		assert.Equal("小明", events[0].Principal)
+		assert.True(events[0].Succeeded())
+		assert.False(events[0].Time.IsZero())
+		assert.Equal(CommandUndone, events[1].Type)
+		assert.Contains(events[1].String(), "小明 撤销 开启 客厅灯")
+	}
+
+	// 停止发布后不再收到事件
+	remote.SetEventPublisher(nil)
+	captureOutput(func() {
+		assert.NoError(remote.OnButtonPressed(0))
+	})
+	assert.Len(events, 2)
+}
+
+// 测试失败和被拒绝的命令
+func TestRemoteControlEventsOnFailure(t *testing.T) {
+	assert := assert.New(t)
+	tv := NewTV("客厅电视")
+	remote := NewRemoteControl(2)
+	remote.SetCommand(0, &failingCommand{name: "坏掉的命令"}, &NoOpCommand{})
+	remote.SetCommand(1, NewRestrictedCommand(NewTurnOnCommand(tv), RoleAdmin), &NoOpCommand{})
+
+	var events []CommandEvent
+	remote.SetEventPublisher(PublisherFunc(func(event CommandEvent) {
+		events = append(events, event)
+	}))
+
+	err := remote.OnButtonPressed(0)
+	assert.Error(err)
+	if assert.Len(events, 1, "执行失败的命令同样发布事件") {
+		assert.False(events[0].Succeeded())
+		assert.Equal(err, events[0].Err)
+		assert.Contains(events[0].String(), "失败")
+	}
+
+	err = remote.OnButtonPressed(1)
+	assert.True(errors.Is(err, ErrPermissionDenied))
+	assert.Len(events, 1, "被拒绝的命令没有执行，不发布事件")
+}
+
+// 测试命令事件主题
+func TestCommandSubject(t *testing.T) {
+	assert := assert.New(t)
+	subject := NewCommandSubject()
+	dashboard := &eventRecorder{id: "dashboard"}
+	auditor := &eventRecorder{id: "auditor"}
+
+	subject.Register(dashboard)
+	subject.Register(auditor)
+	subject.Register(&eventRecorder{id: "dashboard"})
+	assert.Equal(2, subject.CountObservers(), "相同 ID 的观察者只注册一次")
+	assert.True(subject.HasObserver(auditor))
+
+	light := NewLight("卧室灯")
+	remote := NewRemoteControl(1)
+	remote.SetCommand(0, NewTurnOnCommand(light), NewTurnOffCommand(light))
+	remote.SetEventPublisher(subject)
+
+	captureOutput(func() {
+		remote.OnButtonPressed(0)
+		subject.Deregister(auditor)
+		remote.OffButtonPressed(0)
+	})
+
+	assert.False(subject.HasObserver(auditor))
+	assert.Len(dashboard.events, 2)
+	assert.Len(auditor.events, 1)
+	assert.Equal("匿名用户", dashboard.events[1].Principal)
+	assert.Equal("关闭 卧室灯", dashboard.events[1].Command)
+}
@@ -0,0 +1,260 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrPermissionDenied 表示当前用户的角色不足以执行命令
+var ErrPermissionDenied = errors.New("权限不足")
+
+// Role 表示用户角色
+type Role string
+
+const (
+	RoleGuest  Role = "guest"  // 访客
+	RoleMember Role = "member" // 家庭成员
+	RoleAdmin  Role = "admin"  // 管理员
+)
+
+// Principal 表示执行命令的用户身份
+type Principal struct {
+	Name  string
+	Roles []Role
+}
+
+// NewPrincipal 创建一个拥有指定角色的用户身份
+func NewPrincipal(name string, roles ...Role) *Principal {
+	return &Principal{
+		Name:  name,
+		Roles: roles,
+	}
+}
+
+// HasRole 检查用户是否拥有指定角色
+func (p *Principal) HasRole(role Role) bool {
+	if p == nil {
+		return false
+	}
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// String 返回用户名称，未绑定用户时返回"匿名用户"
+func (p *Principal) String() string {
+	if p == nil {
+		return "匿名用户"
+	}
+	return p.Name
+}
+
+// SecuredCommand 是声明了所需角色的命令
+type SecuredCommand interface {
+	Command
+	RequiredRoles() []Role
+}
+
+// RestrictedCommand 为任意命令附加角色要求
+type RestrictedCommand struct {
+	Command
+	roles []Role
+}
+
+// NewRestrictedCommand 创建一个需要全部指定角色才能执行的命令
+func NewRestrictedCommand(cmd Command, roles ...Role) *RestrictedCommand {
+	return &RestrictedCommand{
+		Command: cmd,
+		roles:   roles,
+	}
+}
+
+// RequiredRoles 返回执行该命令所需的角色
+func (c *RestrictedCommand) RequiredRoles() []Role {
+	return c.roles
+}
+
+// AdminOverrideCommand 允许管理员跳过被包装命令自身的角色要求
+type AdminOverrideCommand struct {
+	Command
+	reason string
+}
+
+// NewAdminOverrideCommand 创建一个管理员越权执行命令，reason 会记录到审计日志中
+func NewAdminOverrideCommand(cmd Command, reason string) *AdminOverrideCommand {
+	return &AdminOverrideCommand{
+		Command: cmd,
+		reason:  reason,
+	}
+}
+
+// RequiredRoles 越权命令只要求管理员角色
+func (c *AdminOverrideCommand) RequiredRoles() []Role {
+	return []Role{RoleAdmin}
+}
+
+// Reason 返回越权执行的原因
+func (c *AdminOverrideCommand) Reason() string {
+	return c.reason
+}
+
+// Name 返回命令名称
+func (c *AdminOverrideCommand) Name() string {
+	return fmt.Sprintf("管理员越权: %s", c.Command.Name())
+}
+
+// requiredRoles 收集命令所需的全部角色，宏命令会合并其子命令的要求
+func requiredRoles(cmd Command) []Role {
+	switch c := cmd.(type) {
+	case SecuredCommand:
+		return c.RequiredRoles()
+	case *MacroCommand:
+		var roles []Role
+		seen := make(map[Role]bool)
+		for _, sub := range c.commands {
+			for _, role := range requiredRoles(sub) {
+				if !seen[role] {
+					seen[role] = true
+					roles = append(roles, role)
+				}
+			}
+		}
+		return roles
+	default:
+		return nil
+	}
+}
+
+// AuditEntry 记录一次需要审计的命令执行尝试
+type AuditEntry struct {
+	Time      time.Time
+	Principal string
+	Command   string
+	Missing   []Role // 缺少的角色，越权执行时为空
+	Allowed   bool   // 是否被允许执行
+	Reason    string // 管理员越权的原因
+}
+
+// String 格式化审计记录
+func (e AuditEntry) String() string {
+	if e.Allowed {
+		return fmt.Sprintf("[%s] %s 越权执行 %s，原因: %s",
+			e.Time.Format("15:04:05"), e.Principal, e.Command, e.Reason)
+	}
+	return fmt.Sprintf("[%s] 拒绝 %s 执行 %s，缺少角色: %v",
+		e.Time.Format("15:04:05"), e.Principal, e.Command, e.Missing)
+}
+
+// AuditLog 是并发安全的审计日志
+type AuditLog struct {
+	mu      sync.Mutex
+	entries []AuditEntry
+}
+
+// NewAuditLog 创建一个新的审计日志
+func NewAuditLog() *AuditLog {
+	return &AuditLog{
+		entries: make([]AuditEntry, 0),
+	}
+}
+
+// Record 添加一条审计记录
+func (a *AuditLog) Record(entry AuditEntry) {
+	a.mu.Lock()
+	a.entries = append(a.entries, entry)
+	a.mu.Unlock()
+	fmt.Printf("审计: %s\n", entry)
+}
+
+// Entries 返回所有审计记录的副本
+func (a *AuditLog) Entries() []AuditEntry {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]AuditEntry(nil), a.entries...)
+}
+
+// Denied 返回所有被拒绝的记录
+func (a *AuditLog) Denied() []AuditEntry {
+	var denied []AuditEntry
+	for _, entry := range a.Entries() {
+		if !entry.Allowed {
+			denied = append(denied, entry)
+		}
+	}
+	return denied
+}
+
+// String 返回审计日志的文本形式
+func (a *AuditLog) String() string {
+	var sb strings.Builder
+	for _, entry := range a.Entries() {
+		sb.WriteString(entry.String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// BindPrincipal 将遥控器绑定到指定用户，之后的命令都以该用户身份执行
+func (r *RemoteControl) BindPrincipal(principal *Principal) {
+	r.principal = principal
+}
+
+// Principal 返回遥控器当前绑定的用户，未绑定时返回 nil
+func (r *RemoteControl) Principal() *Principal {
+	return r.principal
+}
+
+// SetAuditLog 设置遥控器使用的审计日志
+func (r *RemoteControl) SetAuditLog(log *AuditLog) {
+	r.auditLog = log
+}
+
+// authorize 检查当前用户是否有权限执行命令
+func (r *RemoteControl) authorize(cmd Command) error {
+	missing := r.missingRoles(cmd)
+	if len(missing) > 0 {
+		r.audit(AuditEntry{
+			Principal: r.principal.String(),
+			Command:   cmd.Name(),
+			Missing:   missing,
+		})
+		return fmt.Errorf("%w: %s 执行 %s 缺少角色 %v",
+			ErrPermissionDenied, r.principal, cmd.Name(), missing)
+	}
+
+	if override, ok := cmd.(*AdminOverrideCommand); ok {
+		r.audit(AuditEntry{
+			Principal: r.principal.String(),
+			Command:   cmd.Name(),
+			Allowed:   true,
+			Reason:    override.Reason(),
+		})
+	}
+	return nil
+}
+
+// missingRoles 返回当前用户执行命令所缺少的角色
+func (r *RemoteControl) missingRoles(cmd Command) []Role {
+	var missing []Role
+	for _, role := range requiredRoles(cmd) {
+		if !r.principal.HasRole(role) {
+			missing = append(missing, role)
+		}
+	}
+	return missing
+}
+
+// audit 在设置了审计日志时写入一条记录
+func (r *RemoteControl) audit(entry AuditEntry) {
+	if r.auditLog == nil {
+		return
+	}
+	entry.Time = time.Now()
+	r.auditLog.Record(entry)
+}
@@ -0,0 +1,136 @@
+package command
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试用户角色检查
+func TestPrincipalRoles(t *testing.T) {
+	admin := NewPrincipal("爸爸", RoleMember, RoleAdmin)
+	assert.True(t, admin.HasRole(RoleAdmin))
+	assert.False(t, NewPrincipal("客人", RoleGuest).HasRole(RoleMember))
+
+	var anonymous *Principal
+	assert.False(t, anonymous.HasRole(RoleGuest), "匿名用户没有任何角色")
+	assert.Equal(t, "匿名用户", anonymous.String())
+}
+
+// 测试权限不足时拒绝执行并记录审计日志
+func TestRemoteControlPermissionDenied(t *testing.T) {
+	tv := NewTV("客厅电视")
+	remote := NewRemoteControl(1)
+	audit := NewAuditLog()
+	remote.SetAuditLog(audit)
+
+	onTV := NewRestrictedCommand(NewTurnOnCommand(tv), RoleMember)
+	offTV := NewTurnOffCommand(tv)
+	remote.SetCommand(0, onTV, offTV)
+
+	// 未绑定用户时拒绝执行受限命令
+	var err error
+	captureOutput(func() { err = remote.OnButtonPressed(0) })
+	assert.True(t, errors.Is(err, ErrPermissionDenied))
+	assert.False(t, tv.isOn, "被拒绝的命令不应执行")
+
+	// 访客角色不足
+	remote.BindPrincipal(NewPrincipal("客人", RoleGuest))
+	output := captureOutput(func() { err = remote.OnButtonPressed(0) })
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+	assert.Contains(t, err.Error(), "客人")
+	assert.Contains(t, output, "拒绝 客人 执行 开启 客厅电视")
+
+	denied := audit.Denied()
+	assert.Len(t, denied, 2)
+	assert.Equal(t, "匿名用户", denied[0].Principal)
+	assert.Equal(t, []Role{RoleMember}, denied[1].Missing)
+
+	// 家庭成员可以执行，且不会产生审计记录
+	remote.BindPrincipal(NewPrincipal("妈妈", RoleMember))
+	captureOutput(func() { err = remote.OnButtonPressed(0) })
+	assert.NoError(t, err)
+	assert.True(t, tv.isOn)
+	assert.Len(t, audit.Entries(), 2)
+
+	// 未声明角色的命令任何人都可以执行
+	remote.BindPrincipal(NewPrincipal("客人", RoleGuest))
+	captureOutput(func() { err = remote.OffButtonPressed(0) })
+	assert.NoError(t, err)
+	assert.False(t, tv.isOn)
+}
+
+// 测试撤销同样需要权限
+func TestUndoRequiresPermission(t *testing.T) {
+	light := NewLight("书房灯")
+	remote := NewRemoteControl(1)
+	remote.SetCommand(0, NewRestrictedCommand(NewTurnOnCommand(light), RoleMember), &NoOpCommand{})
+
+	remote.BindPrincipal(NewPrincipal("妈妈", RoleMember))
+	captureOutput(func() { assert.NoError(t, remote.OnButtonPressed(0)) })
+
+	remote.BindPrincipal(NewPrincipal("客人", RoleGuest))
+	err := remote.UndoLastCommand()
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+	assert.True(t, light.isOn, "无权撤销时灯应保持开启")
+
+	remote.BindPrincipal(NewPrincipal("妈妈", RoleMember))
+	captureOutput(func() { assert.NoError(t, remote.UndoLastCommand()) })
+	assert.False(t, light.isOn)
+}
+
+// 测试宏命令合并子命令的角色要求
+func TestMacroCommandPermissions(t *testing.T) {
+	light := NewLight("客厅灯")
+	tv := NewTV("客厅电视")
+	macro := NewMacroCommand("影院模式", []Command{
+		NewRestrictedCommand(NewTurnOffCommand(light), RoleMember),
+		NewRestrictedCommand(NewTurnOnCommand(tv), RoleMember, RoleAdmin),
+	})
+	assert.Equal(t, []Role{RoleMember, RoleAdmin}, requiredRoles(macro))
+
+	remote := NewRemoteControl(1)
+	remote.SetCommand(0, macro, &NoOpCommand{})
+	remote.BindPrincipal(NewPrincipal("妈妈", RoleMember))
+
+	err := remote.OnButtonPressed(0)
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+	assert.Contains(t, err.Error(), "admin")
+	assert.False(t, tv.isOn, "宏命令中的任何子命令都不应执行")
+}
+
+// 测试管理员越权命令
+func TestAdminOverrideCommand(t *testing.T) {
+	light := NewLight("车库灯")
+	restricted := NewRestrictedCommand(NewTurnOnCommand(light), RoleMember, Role("electrician"))
+	override := NewAdminOverrideCommand(restricted, "夜间紧急照明")
+	assert.Equal(t, "管理员越权: 开启 车库灯", override.Name())
+
+	remote := NewRemoteControl(1)
+	audit := NewAuditLog()
+	remote.SetAuditLog(audit)
+	remote.SetCommand(0, override, &NoOpCommand{})
+
+	// 非管理员无法越权
+	remote.BindPrincipal(NewPrincipal("妈妈", RoleMember))
+	captureOutput(func() {
+		assert.ErrorIs(t, remote.OnButtonPressed(0), ErrPermissionDenied)
+	})
+	assert.False(t, light.isOn)
+
+	// 管理员即使缺少被包装命令的角色也可以执行，且越权操作被审计
+	remote.BindPrincipal(NewPrincipal("爸爸", RoleAdmin))
+	captureOutput(func() {
+		assert.NoError(t, remote.OnButtonPressed(0))
+	})
+	assert.True(t, light.isOn)
+
+	entries := audit.Entries()
+	assert.Len(t, entries, 2)
+	assert.False(t, entries[0].Allowed)
+	assert.True(t, entries[1].Allowed)
+	assert.Equal(t, "夜间紧急照明", entries[1].Reason)
+	assert.Len(t, audit.Denied(), 1)
+	assert.Contains(t, audit.String(), "爸爸 越权执行 管理员越权: 开启 车库灯，原因: 夜间紧急照明")
+}
@@ -70,15 +70,20 @@ func GetUserToken(ctx context.Context) (string, bool) {
 
 // ProcessRequest 处理请求的入口函数
 // 使用超时控制和取消信号处理
-func ProcessRequest(parentCtx context.Context, info RequestInfo, timeout time.Duration) error {
-	// 1. 创建请求上下文
-	ctx := WithRequestInfo(parentCtx, info)
-	ctx = WithRequestID(ctx)
-
-	// 2. 添加超时控制
-	ctx, cancel := context.WithTimeout(ctx, timeout)
+// 请求上下文由增强链构建：先写入请求信息和请求ID，再依次执行调用方提供的增强器，
+// 任一增强器出错时请求直接失败
+func ProcessRequest(parentCtx context.Context, info RequestInfo, timeout time.Duration, enrichers ...Enricher) error {
+	// 1. 添加超时控制
+	ctx, cancel := context.WithTimeout(parentCtx, timeout)
 	defer cancel() // 确保资源被释放
 
+	// 2. 通过增强链创建请求上下文
+	chain := append([]Enricher{RequestInfoEnricher(info), RequestIDEnricher()}, enrichers...)
+	ctx, err := Enrich(ctx, chain...)
+	if err != nil {
+		return fmt.Errorf("enrichment failed: %w", err)
+	}
+
 	// 3. 记录请求开始
 	requestID, _ := GetRequestID(ctx)
 	log.Printf("[%s] Starting request processing for user %s from %s",
@@ -0,0 +1,149 @@
+package context
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// localeKey 是语言区域在上下文中的键
+const localeKey contextKey = "locale"
+
+// DefaultLocale 是未指定语言区域时使用的默认值
+const DefaultLocale = "zh-CN"
+
+// ErrInvalidLocale 表示语言区域格式不合法
+var ErrInvalidLocale = errors.New("invalid locale")
+
+// Enricher 是上下文增强器，接收一个上下文并返回附加了新值的上下文
+// 返回错误时，增强链会立即中止，后续增强器不再执行
+type Enricher func(ctx context.Context) (context.Context, error)
+
+// Chain 把多个增强器按顺序组合成一个增强器
+// 每个增强器接收前一个增强器返回的上下文；任一增强器出错即短路返回
+func Chain(enrichers ...Enricher) Enricher {
+	return func(ctx context.Context) (context.Context, error) {
+		for _, enrich := range enrichers {
+			if enrich == nil {
+				continue
+			}
+			// 上游已取消时不再继续增强
+			if err := ctx.Err(); err != nil {
+				return ctx, mapContextError(err)
+			}
+			next, err := enrich(ctx)
+			if err != nil {
+				return ctx, err
+			}
+			ctx = next
+		}
+		return ctx, nil
+	}
+}
+
+// Enrich 对上下文依次应用增强器，是 Chain(enrichers...)(ctx) 的简写
+func Enrich(ctx context.Context, enrichers ...Enricher) (context.Context, error) {
+	return Chain(enrichers...)(ctx)
+}
+
+// RequestInfoEnricher 返回把请求信息写入上下文的增强器
+func RequestInfoEnricher(info RequestInfo) Enricher {
+	return func(ctx context.Context) (context.Context, error) {
+		return WithRequestInfo(ctx, info), nil
+	}
+}
+
+// RequestIDEnricher 返回生成请求ID的增强器
+// 如果上下文中已经存在请求ID（例如由上游服务传入），则保留原值
+func RequestIDEnricher() Enricher {
+	return func(ctx context.Context) (context.Context, error) {
+		if _, ok := GetRequestID(ctx); ok {
+			return ctx, nil
+		}
+		return WithRequestID(ctx), nil
+	}
+}
+
+// TokenValidator 校验用户令牌，令牌无效时返回错误
+type TokenValidator func(token string) error
+
+// AuthEnricher 返回校验用户令牌的增强器
+// 令牌可以事先通过 WithUserToken 放入上下文，也可以由 token 参数提供（非空时覆盖上下文中的值）
+// 缺少令牌或校验失败时返回 ErrUnauthorized
+func AuthEnricher(token string, validate TokenValidator) Enricher {
+	return func(ctx context.Context) (context.Context, error) {
+		if token != "" {
+			ctx = WithUserToken(ctx, token)
+		}
+		current, ok := GetUserToken(ctx)
+		if !ok || current == "" {
+			return ctx, fmt.Errorf("%w: missing user token", ErrUnauthorized)
+		}
+		if validate != nil {
+			if err := validate(current); err != nil {
+				return ctx, fmt.Errorf("%w: %v", ErrUnauthorized, err)
+			}
+		}
+		return ctx, nil
+	}
+}
+
+// WithLocale 将语言区域添加到上下文中
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey, locale)
+}
+
+// GetLocale 从上下文中获取语言区域，未设置时返回 DefaultLocale 和 false
+func GetLocale(ctx context.Context) (string, bool) {
+	locale, ok := ctx.Value(localeKey).(string)
+	if !ok || locale == "" {
+		return DefaultLocale, false
+	}
+	return locale, true
+}
+
+// LocaleEnricher 返回设置语言区域的增强器
+// 语言区域形如 "zh-CN"、"en-US"，也接受单独的语言代码如 "en"；空字符串表示使用 DefaultLocale
+func LocaleEnricher(locale string) Enricher {
+	return func(ctx context.Context) (context.Context, error) {
+		value := locale
+		if value == "" {
+			value = DefaultLocale
+		}
+		normalized, err := normalizeLocale(value)
+		if err != nil {
+			return ctx, err
+		}
+		return WithLocale(ctx, normalized), nil
+	}
+}
+
+// normalizeLocale 校验并规范化语言区域：语言小写、地区大写，分隔符统一为 "-"
+func normalizeLocale(locale string) (string, error) {
+	parts := strings.Split(strings.ReplaceAll(locale, "_", "-"), "-")
+	if len(parts) > 2 || !isLetters(parts[0], 2, 3) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidLocale, locale)
+	}
+	normalized := strings.ToLower(parts[0])
+	if len(parts) == 2 {
+		if !isLetters(parts[1], 2, 2) {
+			return "", fmt.Errorf("%w: %q", ErrInvalidLocale, locale)
+		}
+		normalized += "-" + strings.ToUpper(parts[1])
+	}
+	return normalized, nil
+}
+
+// isLetters 判断 s 是否由 minLen 到 maxLen 个 ASCII 字母组成
+func isLetters(s string, minLen, maxLen int) bool {
+	if len(s) < minLen || len(s) > maxLen {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,141 @@
+package context
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试增强链按顺序执行并传递上下文
+func TestChain_Order(t *testing.T) {
+	var order []string
+	record := func(name string) Enricher {
+		return func(ctx context.Context) (context.Context, error) {
+			order = append(order, name)
+			return context.WithValue(ctx, contextKey(name), name), nil
+		}
+	}
+
+	ctx, err := Chain(record("first"), nil, record("second"), record("third"))(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first", "second", "third"}, order, "增强器应按顺序执行，nil 增强器被跳过")
+	for _, name := range order {
+		assert.Equal(t, name, ctx.Value(contextKey(name)), "后续上下文应保留之前增强器写入的值")
+	}
+}
+
+// 测试增强链遇到错误时短路
+func TestChain_ShortCircuit(t *testing.T) {
+	errBoom := errors.New("boom")
+	called := false
+
+	_, err := Enrich(context.Background(),
+		func(ctx context.Context) (context.Context, error) { return ctx, errBoom },
+		func(ctx context.Context) (context.Context, error) {
+			called = true
+			return ctx, nil
+		},
+	)
+	assert.ErrorIs(t, err, errBoom)
+	assert.False(t, called, "出错后不应执行后续增强器")
+
+	// 已取消的上下文不再执行增强器
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Enrich(cancelled, RequestIDEnricher())
+	assert.ErrorIs(t, err, ErrRequestCancelled)
+}
+
+// 测试内置增强器
+func TestBuiltinEnrichers(t *testing.T) {
+	t.Run("RequestID", func(t *testing.T) {
+		ctx, err := Enrich(context.Background(), RequestIDEnricher())
+		assert.NoError(t, err)
+		id, ok := GetRequestID(ctx)
+		assert.True(t, ok)
+
+		// 已存在的请求ID应被保留
+		again, err := Enrich(ctx, RequestIDEnricher())
+		assert.NoError(t, err)
+		sameID, _ := GetRequestID(again)
+		assert.Equal(t, id, sameID)
+	})
+
+	t.Run("Auth", func(t *testing.T) {
+		validate := func(token string) error {
+			if token != "valid-token" {
+				return errors.New("token rejected")
+			}
+			return nil
+		}
+
+		ctx, err := Enrich(context.Background(), AuthEnricher("valid-token", validate))
+		assert.NoError(t, err)
+		token, _ := GetUserToken(ctx)
+		assert.Equal(t, "valid-token", token)
+
+		// 使用上下文中已有的令牌
+		_, err = Enrich(WithUserToken(context.Background(), "valid-token"), AuthEnricher("", validate))
+		assert.NoError(t, err)
+
+		_, err = Enrich(context.Background(), AuthEnricher("forged", validate))
+		assert.ErrorIs(t, err, ErrUnauthorized)
+
+		_, err = Enrich(context.Background(), AuthEnricher("", validate))
+		assert.ErrorIs(t, err, ErrUnauthorized, "缺少令牌应返回未授权错误")
+	})
+
+	t.Run("Locale", func(t *testing.T) {
+		locale, ok := GetLocale(context.Background())
+		assert.False(t, ok)
+		assert.Equal(t, DefaultLocale, locale)
+
+		ctx, err := Enrich(context.Background(), LocaleEnricher("en_us"))
+		assert.NoError(t, err)
+		locale, ok = GetLocale(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, "en-US", locale)
+
+		ctx, err = Enrich(context.Background(), LocaleEnricher(""))
+		assert.NoError(t, err)
+		locale, _ = GetLocale(ctx)
+		assert.Equal(t, DefaultLocale, locale)
+
+		for _, invalid := range []string{"e", "english-US", "en-USA", "en-U1", "zh-CN-x"} {
+			_, err = Enrich(context.Background(), LocaleEnricher(invalid))
+			assert.ErrorIs(t, err, ErrInvalidLocale, invalid)
+		}
+	})
+}
+
+// 测试 ProcessRequest 使用自定义增强器
+func TestProcessRequest_WithEnrichers(t *testing.T) {
+	info := RequestInfo{Username: "enricheduser", IPAddress: "127.0.0.1", Timestamp: time.Now()}
+
+	var seen context.Context
+	capture := func(ctx context.Context) (context.Context, error) {
+		seen = ctx
+		return ctx, nil
+	}
+
+	err := ProcessRequest(context.Background(), info, 3*time.Second,
+		AuthEnricher("token-1", nil), LocaleEnricher("en-US"), capture)
+	assert.NoError(t, err)
+
+	// 自定义增强器在内置增强器之后执行，能看到请求信息和请求ID
+	got, ok := GetRequestInfo(seen)
+	assert.True(t, ok)
+	assert.Equal(t, info.Username, got.Username)
+	_, ok = GetRequestID(seen)
+	assert.True(t, ok)
+	locale, _ := GetLocale(seen)
+	assert.Equal(t, "en-US", locale)
+
+	// 增强失败时请求直接失败，不进入后续处理
+	err = ProcessRequest(context.Background(), info, 3*time.Second,
+		AuthEnricher("", nil))
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
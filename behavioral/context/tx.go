@@ -0,0 +1,78 @@
+package context
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// txKey 是数据库事务在上下文中的键
+const txKey contextKey = "tx"
+
+// Tx 是数据库事务，*sql.Tx 等事务类型都可以通过简单的适配满足该接口
+type Tx interface {
+	Commit() error
+	Rollback() error
+}
+
+// BeginTxer 可以开启事务的数据库
+type BeginTxer interface {
+	BeginTx(ctx context.Context) (Tx, error)
+}
+
+// WithTx 将事务添加到上下文中，调用链上的数据访问函数通过 TxFrom 取出同一个事务
+func WithTx(ctx context.Context, tx Tx) context.Context {
+	return context.WithValue(ctx, txKey, tx)
+}
+
+// TxFrom 从上下文中获取事务，不在事务中时返回 false
+func TxFrom(ctx context.Context) (Tx, bool) {
+	tx, ok := ctx.Value(txKey).(Tx)
+	return tx, ok && tx != nil
+}
+
+// RunInTransaction 在事务中执行 fn
+//
+// 开启事务并把它放入传给 fn 的上下文中；fn 返回 nil 且上下文未取消时提交事务，
+// fn 出错、发生 panic 或上下文在执行期间被取消时回滚事务。
+// 上下文中已经存在事务时直接加入该事务，由最外层的 RunInTransaction 负责提交或回滚
+func RunInTransaction(ctx context.Context, db BeginTxer, fn func(ctx context.Context) error) error {
+	if _, ok := TxFrom(ctx); ok {
+		return fn(ctx)
+	}
+	if err := ctx.Err(); err != nil {
+		return mapContextError(err)
+	}
+
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			_ = tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err := fn(WithTx(ctx, tx)); err != nil {
+		return rollback(tx, err)
+	}
+	// fn 可能没有检查上下文，取消后的结果不应被提交
+	if err := ctx.Err(); err != nil {
+		return rollback(tx, mapContextError(err))
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// rollback 回滚事务并返回导致回滚的错误，回滚本身失败时一并返回
+func rollback(tx Tx, cause error) error {
+	if err := tx.Rollback(); err != nil {
+		return errors.Join(cause, fmt.Errorf("rollback transaction: %w", err))
+	}
+	return cause
+}
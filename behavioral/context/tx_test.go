@@ -0,0 +1,186 @@
+package context
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTx 记录提交和回滚的模拟事务
+type fakeTx struct {
+	db         *fakeDB
+	committed  bool
+	rolledBack bool
+	pending    []string // 事务中尚未提交的写入
+}
+
+func (tx *fakeTx) Commit() error {
+	if tx.db.commitErr != nil {
+		return tx.db.commitErr
+	}
+	tx.committed = true
+	tx.db.rows = append(tx.db.rows, tx.pending...)
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.rolledBack = true
+	return tx.db.rollbackErr
+}
+
+// fakeDB 模拟数据库，只有提交的写入才会出现在 rows 中
+type fakeDB struct {
+	txs         []*fakeTx
+	rows        []string
+	beginErr    error
+	commitErr   error
+	rollbackErr error
+}
+
+func (db *fakeDB) BeginTx(ctx context.Context) (Tx, error) {
+	if db.beginErr != nil {
+		return nil, db.beginErr
+	}
+	tx := &fakeTx{db: db}
+	db.txs = append(db.txs, tx)
+	return tx, nil
+}
+
+// insert 模拟数据访问函数：从上下文中取出事务并在其中写入
+func insert(ctx context.Context, row string) error {
+	tx, ok := TxFrom(ctx)
+	if !ok {
+		return errors.New("not in transaction")
+	}
+	tx.(*fakeTx).pending = append(tx.(*fakeTx).pending, row)
+	return nil
+}
+
+// 测试事务在上下文中的存取
+func TestWithTx(t *testing.T) {
+	_, ok := TxFrom(context.Background())
+	assert.False(t, ok, "没有事务时应返回 false")
+
+	tx := &fakeTx{}
+	got, ok := TxFrom(WithTx(context.Background(), tx))
+	assert.True(t, ok)
+	assert.Same(t, tx, got)
+
+	_, ok = TxFrom(WithTx(context.Background(), nil))
+	assert.False(t, ok, "nil 事务应视为不在事务中")
+}
+
+// 测试成功时提交事务
+func TestRunInTransaction_Commit(t *testing.T) {
+	db := &fakeDB{}
+	err := RunInTransaction(context.Background(), db, func(ctx context.Context) error {
+		if err := insert(ctx, "order"); err != nil {
+			return err
+		}
+		return insert(ctx, "payment")
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, db.txs, 1)
+	assert.True(t, db.txs[0].committed)
+	assert.False(t, db.txs[0].rolledBack)
+	assert.Equal(t, []string{"order", "payment"}, db.rows)
+}
+
+// 测试出错时回滚事务
+func TestRunInTransaction_Rollback(t *testing.T) {
+	errStock := errors.New("out of stock")
+
+	t.Run("函数返回错误", func(t *testing.T) {
+		db := &fakeDB{}
+		err := RunInTransaction(context.Background(), db, func(ctx context.Context) error {
+			_ = insert(ctx, "order")
+			return errStock
+		})
+		assert.ErrorIs(t, err, errStock)
+		assert.True(t, db.txs[0].rolledBack)
+		assert.Empty(t, db.rows, "回滚的写入不应生效")
+	})
+
+	t.Run("回滚失败", func(t *testing.T) {
+		errConn := errors.New("connection lost")
+		db := &fakeDB{rollbackErr: errConn}
+		err := RunInTransaction(context.Background(), db, func(ctx context.Context) error {
+			return errStock
+		})
+		assert.ErrorIs(t, err, errStock)
+		assert.ErrorIs(t, err, errConn, "回滚失败的错误也应返回")
+	})
+
+	t.Run("执行期间上下文被取消", func(t *testing.T) {
+		db := &fakeDB{}
+		ctx, cancel := context.WithCancel(context.Background())
+		err := RunInTransaction(ctx, db, func(ctx context.Context) error {
+			_ = insert(ctx, "order")
+			cancel()
+			return nil
+		})
+		assert.ErrorIs(t, err, ErrRequestCancelled)
+		assert.True(t, db.txs[0].rolledBack)
+		assert.Empty(t, db.rows)
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		db := &fakeDB{}
+		assert.PanicsWithValue(t, "boom", func() {
+			_ = RunInTransaction(context.Background(), db, func(ctx context.Context) error {
+				panic("boom")
+			})
+		})
+		assert.True(t, db.txs[0].rolledBack, "panic 时应回滚事务后继续 panic")
+	})
+}
+
+// 测试开启或提交事务失败
+func TestRunInTransaction_Errors(t *testing.T) {
+	errBegin := errors.New("too many connections")
+	called := false
+	err := RunInTransaction(context.Background(), &fakeDB{beginErr: errBegin}, func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	assert.ErrorIs(t, err, errBegin)
+	assert.False(t, called, "开启事务失败时不应执行函数")
+
+	errCommit := errors.New("serialization failure")
+	err = RunInTransaction(context.Background(), &fakeDB{commitErr: errCommit}, func(ctx context.Context) error {
+		return nil
+	})
+	assert.ErrorIs(t, err, errCommit)
+
+	db := &fakeDB{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = RunInTransaction(ctx, db, func(ctx context.Context) error { return nil })
+	assert.ErrorIs(t, err, ErrRequestCancelled)
+	assert.Empty(t, db.txs, "已取消的上下文不应开启事务")
+}
+
+// 测试嵌套调用加入外层事务
+func TestRunInTransaction_Nested(t *testing.T) {
+	db := &fakeDB{}
+	errInner := errors.New("inner failed")
+
+	err := RunInTransaction(context.Background(), db, func(ctx context.Context) error {
+		_ = insert(ctx, "order")
+		innerErr := RunInTransaction(ctx, db, func(ctx context.Context) error {
+			_ = insert(ctx, "audit")
+			return errInner
+		})
+		assert.ErrorIs(t, innerErr, errInner)
+		assert.False(t, db.txs[0].rolledBack, "内层出错不应直接回滚外层事务")
+		return innerErr
+	})
+
+	assert.ErrorIs(t, err, errInner)
+	assert.Len(t, db.txs, 1, "嵌套调用不应开启新事务")
+	assert.True(t, db.txs[0].rolledBack)
+	assert.Empty(t, db.rows)
+}
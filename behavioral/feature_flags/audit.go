@@ -0,0 +1,111 @@
+package feature_flags
+
+import (
+	"maps"
+	"sync"
+)
+
+// FlagSummary 一个开关的评估汇总
+type FlagSummary struct {
+	Evaluations int            // 评估次数
+	Enabled     int            // 结果为开启的次数
+	Reasons     map[Reason]int // 按原因统计的次数
+}
+
+// AuditLog 评估审计日志：保留最近 capacity 条评估记录，并按开关汇总全部评估
+// 用于回答"某个用户为什么看到（没看到）新功能"以及"灰度实际覆盖了多少请求"
+type AuditLog struct {
+	mutex    sync.Mutex
+	entries  []Evaluation // 环形缓冲区
+	next     int          // 下一条记录写入的位置
+	full     bool
+	total    int
+	summary  map[string]*FlagSummary
+	onRecord func(Evaluation)
+}
+
+// NewAuditLog 创建保留最近 capacity 条记录的审计日志
+func NewAuditLog(capacity int) *AuditLog {
+	return &AuditLog{
+		entries: make([]Evaluation, max(capacity, 1)),
+		summary: make(map[string]*FlagSummary),
+	}
+}
+
+// OnRecord 设置每条记录写入后的回调，例如转发到外部日志系统；回调在锁外调用
+func (l *AuditLog) OnRecord(fn func(Evaluation)) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.onRecord = fn
+}
+
+// Record 写入一条评估记录，超出容量时覆盖最早的记录
+func (l *AuditLog) Record(ev Evaluation) {
+	l.mutex.Lock()
+	l.entries[l.next] = ev
+	l.next = (l.next + 1) % len(l.entries)
+	if l.next == 0 {
+		l.full = true
+	}
+	l.total++
+
+	s, ok := l.summary[ev.Flag]
+	if !ok {
+		s = &FlagSummary{Reasons: make(map[Reason]int)}
+		l.summary[ev.Flag] = s
+	}
+	s.Evaluations++
+	if ev.Enabled {
+		s.Enabled++
+	}
+	s.Reasons[ev.Reason]++
+	fn := l.onRecord
+	l.mutex.Unlock()
+
+	if fn != nil {
+		fn(ev)
+	}
+}
+
+// Entries 返回保留的记录，按时间从早到晚排列
+func (l *AuditLog) Entries() []Evaluation {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if !l.full {
+		return append([]Evaluation(nil), l.entries[:l.next]...)
+	}
+	out := make([]Evaluation, 0, len(l.entries))
+	out = append(out, l.entries[l.next:]...)
+	return append(out, l.entries[:l.next]...)
+}
+
+// Query 返回满足 match 的保留记录，按时间从早到晚排列
+func (l *AuditLog) Query(match func(Evaluation) bool) []Evaluation {
+	var out []Evaluation
+	for _, ev := range l.Entries() {
+		if match(ev) {
+			out = append(out, ev)
+		}
+	}
+	return out
+}
+
+// Total 返回写入过的记录总数，包括已被覆盖的
+func (l *AuditLog) Total() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.total
+}
+
+// Summary 返回指定开关的评估汇总
+func (l *AuditLog) Summary(flag string) FlagSummary {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	s, ok := l.summary[flag]
+	if !ok {
+		return FlagSummary{Reasons: map[Reason]int{}}
+	}
+	out := *s
+	out.Reasons = maps.Clone(s.Reasons)
+	return out
+}
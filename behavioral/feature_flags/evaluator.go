@@ -0,0 +1,263 @@
+package feature_flags
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrDuplicateFlag 同一批定义中出现重复的键
+var ErrDuplicateFlag = errors.New("开关重复定义")
+
+// ErrFlagNotFound 开关不存在
+var ErrFlagNotFound = errors.New("开关不存在")
+
+// Reason 评估结果的原因
+type Reason int
+
+const (
+	ReasonNotFound    Reason = iota // 开关不存在，按关闭处理
+	ReasonDisabled                  // 总开关关闭
+	ReasonRule                      // 由定向规则决定
+	ReasonFallthrough               // 没有规则满足，由默认灰度比例决定
+	ReasonMissingID                 // 需要分桶但用户没有 ID，按关闭处理
+)
+
+// String 返回原因的名称
+func (r Reason) String() string {
+	switch r {
+	case ReasonNotFound:
+		return "not_found"
+	case ReasonDisabled:
+		return "disabled"
+	case ReasonRule:
+		return "rule"
+	case ReasonFallthrough:
+		return "fallthrough"
+	case ReasonMissingID:
+		return "missing_id"
+	default:
+		return fmt.Sprintf("Reason(%d)", int(r))
+	}
+}
+
+// Evaluation 一次评估的结果
+type Evaluation struct {
+	Flag    string
+	UserID  string
+	Enabled bool
+	Reason  Reason
+	Rule    string    // 决定结果的规则名，仅 Reason 为 ReasonRule 时有值
+	Bucket  int       // 用户所在的桶，没有分桶时为 -1
+	Version uint64    // 评估使用的快照版本
+	Time    time.Time // 评估时间，仅由 Evaluator 填写
+}
+
+// Snapshot 某一版本的全部开关定义，创建后不再修改，可以在多个 goroutine 中并发使用
+type Snapshot struct {
+	version  uint64
+	loadedAt time.Time
+	flags    map[string]Flag
+}
+
+// Version 返回快照的版本号，每次加载加一，空快照为 0
+func (s *Snapshot) Version() uint64 {
+	return s.version
+}
+
+// LoadedAt 返回快照的加载时间
+func (s *Snapshot) LoadedAt() time.Time {
+	return s.loadedAt
+}
+
+// Flag 返回指定开关的定义
+func (s *Snapshot) Flag(key string) (Flag, bool) {
+	f, ok := s.flags[key]
+	return f, ok
+}
+
+// Keys 返回所有开关的键，按字典序排列
+func (s *Snapshot) Keys() []string {
+	return slices.Sorted(maps.Keys(s.flags))
+}
+
+// Evaluate 在快照上评估开关，不记录审计
+func (s *Snapshot) Evaluate(key string, u User) Evaluation {
+	e := Evaluation{Flag: key, UserID: u.ID, Bucket: -1, Version: s.version}
+	f, ok := s.flags[key]
+	if !ok {
+		e.Reason = ReasonNotFound
+		return e
+	}
+	if f.Disabled {
+		e.Reason = ReasonDisabled
+		return e
+	}
+
+	percent := f.Rollout
+	e.Reason = ReasonFallthrough
+	for _, r := range f.Rules {
+		if r.When.IsSatisfiedBy(u) {
+			percent, e.Reason, e.Rule = r.Rollout, ReasonRule, r.Name
+			break
+		}
+	}
+
+	// 0% 和 100% 不需要分桶，匿名用户也能得到确定的结果
+	switch {
+	case percent <= 0:
+		return e
+	case percent >= 100:
+		e.Enabled = true
+		return e
+	case u.ID == "":
+		e.Reason, e.Rule = ReasonMissingID, ""
+		return e
+	}
+	e.Bucket = Bucket(f.salt(), u.ID)
+	e.Enabled = inRollout(e.Bucket, percent)
+	return e
+}
+
+// Option 评估器配置选项
+type Option func(*Evaluator)
+
+// WithAuditLog 使用指定的审计日志，传 nil 关闭审计；默认保留最近 1000 条
+func WithAuditLog(log *AuditLog) Option {
+	return func(e *Evaluator) {
+		e.audit = log
+	}
+}
+
+// Evaluator 线程安全的开关评估器
+//
+// 开关定义以不可变快照的形式保存（写时复制）：评估时原子地读取当前快照，不加锁；
+// 加载新定义时在互斥锁保护下构建新快照并整体替换，正在进行的评估继续使用旧快照。
+// 因此读远多于写的评估路径不会被重新加载阻塞，一次评估也不会看到新旧定义的混合。
+type Evaluator struct {
+	mutex   sync.Mutex // 串行化写者，保证版本号连续、增量修改不丢失
+	current atomic.Pointer[Snapshot]
+	audit   *AuditLog
+	now     func() time.Time
+}
+
+// NewEvaluator 创建没有任何开关的评估器
+func NewEvaluator(opts ...Option) *Evaluator {
+	e := &Evaluator{audit: NewAuditLog(1000), now: time.Now}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.current.Store(&Snapshot{loadedAt: e.now(), flags: map[string]Flag{}})
+	return e
+}
+
+// Load 用 flags 整体替换所有开关定义，返回新版本号
+// 任何一个定义不合法时返回全部问题，当前快照保持不变
+func (e *Evaluator) Load(flags []Flag) (uint64, error) {
+	next := make(map[string]Flag, len(flags))
+	var errs []error
+	for _, f := range flags {
+		if err := f.Validate(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, ok := next[f.Key]; ok {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrDuplicateFlag, f.Key))
+			continue
+		}
+		next[f.Key] = cloneFlag(f)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return e.Version(), err
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.publishLocked(next), nil
+}
+
+// Set 新增或替换一个开关，返回新版本号
+func (e *Evaluator) Set(f Flag) (uint64, error) {
+	if err := f.Validate(); err != nil {
+		return e.Version(), err
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	next := maps.Clone(e.current.Load().flags)
+	next[f.Key] = cloneFlag(f)
+	return e.publishLocked(next), nil
+}
+
+// Remove 删除一个开关，返回新版本号
+func (e *Evaluator) Remove(key string) (uint64, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	cur := e.current.Load()
+	if _, ok := cur.flags[key]; !ok {
+		return cur.version, fmt.Errorf("%w: %s", ErrFlagNotFound, key)
+	}
+	next := maps.Clone(cur.flags)
+	delete(next, key)
+	return e.publishLocked(next), nil
+}
+
+// publishLocked 以 flags 发布下一个版本的快照，调用方需持有 mutex
+func (e *Evaluator) publishLocked(flags map[string]Flag) uint64 {
+	version := e.current.Load().version + 1
+	e.current.Store(&Snapshot{version: version, loadedAt: e.now(), flags: flags})
+	return version
+}
+
+// Snapshot 返回当前快照；需要在一个请求中评估多个开关时，使用同一个快照保证结果一致
+func (e *Evaluator) Snapshot() *Snapshot {
+	return e.current.Load()
+}
+
+// Version 返回当前快照的版本号
+func (e *Evaluator) Version() uint64 {
+	return e.current.Load().version
+}
+
+// Evaluate 在当前快照上评估开关，并记录审计
+func (e *Evaluator) Evaluate(key string, u User) Evaluation {
+	return e.record(e.current.Load().Evaluate(key, u))
+}
+
+// IsEnabled 判断开关对用户是否开启，开关不存在时返回 false
+func (e *Evaluator) IsEnabled(key string, u User) bool {
+	return e.Evaluate(key, u).Enabled
+}
+
+// EvaluateAll 在同一个快照上评估所有开关，常用于把开关状态一次性下发给前端
+func (e *Evaluator) EvaluateAll(u User) map[string]Evaluation {
+	snap := e.current.Load()
+	result := make(map[string]Evaluation, len(snap.flags))
+	for key := range snap.flags {
+		result[key] = e.record(snap.Evaluate(key, u))
+	}
+	return result
+}
+
+// Audit 返回审计日志，关闭审计时为 nil
+func (e *Evaluator) Audit() *AuditLog {
+	return e.audit
+}
+
+// record 填写评估时间并写入审计日志
+func (e *Evaluator) record(ev Evaluation) Evaluation {
+	ev.Time = e.now()
+	if e.audit != nil {
+		e.audit.Record(ev)
+	}
+	return ev
+}
+
+// cloneFlag 复制规则切片，调用方之后修改自己的切片不会影响已发布的快照
+func cloneFlag(f Flag) Flag {
+	f.Rules = slices.Clone(f.Rules)
+	return f
+}
@@ -0,0 +1,99 @@
+package feature_flags
+
+import (
+	"fmt"
+	"strings"
+)
+
+// exampleFlags 示例中的第一版开关定义
+func exampleFlags() []Flag {
+	return []Flag{
+		Boolean("dark-mode", true),
+		Percentage("new-checkout", 20),
+		Targeted("beta-search",
+			Rule{Name: "内部员工", When: HasSuffix("email", "@example.com"), Rollout: 100},
+			Rule{Name: "国内付费用户", When: And(In("plan", "pro", "enterprise"), Equals("country", "CN")), Rollout: 50},
+		),
+	}
+}
+
+// countEnabled 统计 n 个模拟用户中开关开启的人数，同时返回开启的用户
+func countEnabled(snap *Snapshot, key string, n int) (int, map[string]bool) {
+	enabled := make(map[string]bool)
+	for i := range n {
+		id := fmt.Sprintf("user-%d", i)
+		if snap.Evaluate(key, User{ID: id}).Enabled {
+			enabled[id] = true
+		}
+	}
+	return len(enabled), enabled
+}
+
+// RunExample 运行功能开关示例：定向规则、百分比灰度、放量、一键关闭和审计
+func RunExample() {
+	ev := NewEvaluator()
+	version, _ := ev.Load(exampleFlags())
+	fmt.Printf("== 加载开关定义，版本 %d ==\n", version)
+	for _, key := range ev.Snapshot().Keys() {
+		f, _ := ev.Snapshot().Flag(key)
+		fmt.Printf("  %s: 默认 %v%%", key, f.Rollout)
+		for _, r := range f.Rules {
+			fmt.Printf("，[%s] %s → %v%%", r.Name, r.When, r.Rollout)
+		}
+		fmt.Println()
+	}
+
+	fmt.Println("== 按用户评估 ==")
+	users := []User{
+		{ID: "alice", Attributes: map[string]string{"email": "alice@example.com"}},
+		{ID: "bob", Attributes: map[string]string{"plan": "pro", "country": "CN"}},
+		{ID: "carol", Attributes: map[string]string{"plan": "free", "country": "CN"}},
+		{Attributes: map[string]string{"plan": "pro", "country": "CN"}}, // 匿名用户
+	}
+	for _, u := range users {
+		for _, key := range []string{"beta-search", "new-checkout"} {
+			e := ev.Evaluate(key, u)
+			fmt.Printf("  %-6q %-13s 开启=%-5v 原因=%s", u.ID, key, e.Enabled, e.Reason)
+			if e.Rule != "" {
+				fmt.Printf(" 规则=%s", e.Rule)
+			}
+			if e.Bucket >= 0 {
+				fmt.Printf(" 桶=%d", e.Bucket)
+			}
+			fmt.Println()
+		}
+	}
+
+	fmt.Println("== 放量：new-checkout 从 20% 调到 50% ==")
+	before, enabledBefore := countEnabled(ev.Snapshot(), "new-checkout", 10000)
+	ev.Set(Percentage("new-checkout", 50))
+	after, enabledAfter := countEnabled(ev.Snapshot(), "new-checkout", 10000)
+	kept := 0
+	for id := range enabledBefore {
+		if enabledAfter[id] {
+			kept++
+		}
+	}
+	fmt.Printf("  10000 个用户中开启 %d → %d，原来开启的 %d 个用户中仍然开启 %d 个\n", before, after, before, kept)
+
+	fmt.Println("== 不合法的定义被整体拒绝 ==")
+	bad := append(exampleFlags(), Percentage("new-checkout", 150), Targeted("broken", Rule{Name: "缺少条件"}))
+	if _, err := ev.Load(bad); err != nil {
+		fmt.Printf("  加载失败，仍使用版本 %d:\n  %v\n", ev.Version(), strings.ReplaceAll(err.Error(), "\n", "\n  "))
+	}
+
+	fmt.Println("== 一键关闭 ==")
+	f, _ := ev.Snapshot().Flag("new-checkout")
+	f.Disabled = true
+	version, _ = ev.Set(f)
+	e := ev.Evaluate("new-checkout", users[1])
+	fmt.Printf("  版本 %d: bob 的 new-checkout 开启=%v 原因=%s\n", version, e.Enabled, e.Reason)
+
+	fmt.Println("== 审计 ==")
+	for _, e := range ev.Audit().Query(func(e Evaluation) bool { return e.UserID == "bob" }) {
+		fmt.Printf("  v%d %s bob → %v (%s)\n", e.Version, e.Flag, e.Enabled, e.Reason)
+	}
+	s := ev.Audit().Summary("beta-search")
+	fmt.Printf("  beta-search 评估 %d 次，开启 %d 次，原因分布 %v\n", s.Evaluations, s.Enabled, s.Reasons)
+	fmt.Printf("  共记录 %d 次评估\n", ev.Audit().Total())
+}
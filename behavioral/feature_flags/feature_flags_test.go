@@ -0,0 +1,416 @@
+package feature_flags
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// attrs 构造带属性的用户
+func attrs(id string, kv ...string) User {
+	u := User{ID: id, Attributes: map[string]string{}}
+	for i := 0; i+1 < len(kv); i += 2 {
+		u.Attributes[kv[i]] = kv[i+1]
+	}
+	return u
+}
+
+func TestConditions(t *testing.T) {
+	u := attrs("u1", "country", "CN", "plan", "pro", "email", "a@example.com", "age", "30")
+
+	tests := []struct {
+		cond Condition
+		want bool
+		desc string
+	}{
+		{UserIn("u1", "u2"), true, `id in [u1, u2]`},
+		{Equals("country", "CN"), true, `country == "CN"`},
+		{Equals("missing", ""), false, `missing == ""`},
+		{In("plan", "free", "pro"), true, `plan in ["free", "pro"]`},
+		{HasSuffix("email", "@example.com"), true, `email ends with "@example.com"`},
+		{AtLeast("age", 18), true, `age >= 18`},
+		{AtLeast("plan", 1), false, `plan >= 1`},
+		{And(Equals("country", "CN"), Not(Equals("plan", "pro"))), false, `(country == "CN" && !(plan == "pro"))`},
+		{Or(Equals("country", "US"), AtLeast("age", 21.5)), true, `(country == "US" || age >= 21.5)`},
+		{And(), true, ``},
+		{Or(), false, ``},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.cond.IsSatisfiedBy(u), tt.desc)
+		assert.Equal(t, tt.desc, tt.cond.String())
+	}
+}
+
+func TestFlagValidate(t *testing.T) {
+	assert.NoError(t, Boolean("a", true).Validate())
+	assert.NoError(t, Percentage("a", 0).Validate())
+	assert.NoError(t, Percentage("a", 100).Validate())
+
+	for _, f := range []Flag{
+		Boolean("", true),
+		Percentage("a", -1),
+		Percentage("a", 100.5),
+		Percentage("a", math.NaN()),
+		Targeted("a", Rule{Name: "无条件", Rollout: 100}),
+		Targeted("a", Rule{When: Equals("x", "y"), Rollout: 200}),
+	} {
+		assert.ErrorIs(t, f.Validate(), ErrInvalidFlag, "%+v", f)
+	}
+
+	err := Flag{Rollout: -1, Rules: []Rule{{}}}.Validate()
+	assert.ErrorIs(t, err, ErrInvalidFlag)
+	assert.Len(t, err.(interface{ Unwrap() []error }).Unwrap(), 3)
+}
+
+func TestBucketDeterministic(t *testing.T) {
+	for i := range 100 {
+		id := fmt.Sprintf("user-%d", i)
+		b := Bucket("flag", id)
+		assert.Equal(t, b, Bucket("flag", id))
+		assert.True(t, b >= 0 && b < Buckets)
+	}
+	// 盐与用户 ID 之间有分隔符，拼接结果相同的组合不会落在同一个桶
+	assert.NotEqual(t, Bucket("ab", "c"), Bucket("a", "bc"))
+}
+
+func TestBucketDistribution(t *testing.T) {
+	const n = 20000
+	const groups = 10
+	var counts [groups]int
+	for i := range n {
+		counts[Bucket("checkout", fmt.Sprintf("user-%d", i))*groups/Buckets]++
+	}
+	for g, c := range counts {
+		assert.InDelta(t, n/groups, c, n/groups*0.1, "第 %d 组", g)
+	}
+}
+
+func TestBucketIndependentAcrossFlags(t *testing.T) {
+	// 同一批用户在两个 50% 开关中的分组应当近似独立：两个都开启的约占 25%
+	const n = 20000
+	both := 0
+	for i := range n {
+		id := fmt.Sprintf("user-%d", i)
+		if inRollout(Bucket("a", id), 50) && inRollout(Bucket("b", id), 50) {
+			both++
+		}
+	}
+	assert.InDelta(t, n/4, both, n*0.02)
+}
+
+func TestEvaluateBoolean(t *testing.T) {
+	ev := NewEvaluator()
+	_, err := ev.Load([]Flag{Boolean("on", true), Boolean("off", false)})
+	assert.NoError(t, err)
+
+	for _, u := range []User{{ID: "u1"}, {}} {
+		on := ev.Evaluate("on", u)
+		assert.True(t, on.Enabled)
+		assert.Equal(t, ReasonFallthrough, on.Reason)
+		assert.Equal(t, -1, on.Bucket)
+		assert.False(t, ev.IsEnabled("off", u))
+	}
+
+	missing := ev.Evaluate("missing", User{ID: "u1"})
+	assert.False(t, missing.Enabled)
+	assert.Equal(t, ReasonNotFound, missing.Reason)
+}
+
+func TestEvaluatePercentage(t *testing.T) {
+	ev := NewEvaluator()
+	ev.Load([]Flag{Percentage("p", 30)})
+
+	const n = 10000
+	enabled := 0
+	for i := range n {
+		e := ev.Snapshot().Evaluate("p", User{ID: fmt.Sprintf("user-%d", i)})
+		assert.Equal(t, ReasonFallthrough, e.Reason)
+		assert.Equal(t, inRollout(e.Bucket, 30), e.Enabled)
+		if e.Enabled {
+			enabled++
+		}
+	}
+	assert.InDelta(t, n*0.3, enabled, n*0.03)
+
+	anon := ev.Evaluate("p", User{})
+	assert.False(t, anon.Enabled)
+	assert.Equal(t, ReasonMissingID, anon.Reason)
+}
+
+func TestRolloutMonotonic(t *testing.T) {
+	ev := NewEvaluator()
+	prev := map[string]bool{}
+	for _, percent := range []float64{0, 5, 10, 25, 50, 99.99, 100} {
+		ev.Set(Percentage("ramp", percent))
+		cur := map[string]bool{}
+		for i := range 2000 {
+			id := fmt.Sprintf("user-%d", i)
+			if ev.Snapshot().Evaluate("ramp", User{ID: id}).Enabled {
+				cur[id] = true
+			}
+		}
+		for id := range prev {
+			assert.True(t, cur[id], "放量到 %v%% 后 %s 被关闭", percent, id)
+		}
+		prev = cur
+	}
+	assert.Len(t, prev, 2000)
+}
+
+func TestSaltReshuffles(t *testing.T) {
+	a := Percentage("exp", 50)
+	b := a
+	b.Salt = "exp-v2"
+
+	differ := 0
+	for i := range 1000 {
+		id := fmt.Sprintf("user-%d", i)
+		if Bucket(a.salt(), id) != Bucket(b.salt(), id) {
+			differ++
+		}
+	}
+	assert.Greater(t, differ, 990)
+}
+
+func TestEvaluateRules(t *testing.T) {
+	ev := NewEvaluator()
+	ev.Load([]Flag{{
+		Key: "beta",
+		Rules: []Rule{
+			{Name: "黑名单", When: UserIn("banned"), Rollout: 0},
+			{Name: "员工", When: HasSuffix("email", "@corp.com"), Rollout: 100},
+			{Name: "付费", When: Equals("plan", "pro"), Rollout: 50},
+		},
+		Rollout: 10,
+	}})
+
+	staff := ev.Evaluate("beta", attrs("s1", "email", "s1@corp.com", "plan", "pro"))
+	assert.True(t, staff.Enabled)
+	assert.Equal(t, ReasonRule, staff.Reason)
+	assert.Equal(t, "员工", staff.Rule, "按顺序匹配第一个满足的规则")
+
+	banned := ev.Evaluate("beta", attrs("banned", "email", "b@corp.com"))
+	assert.False(t, banned.Enabled)
+	assert.Equal(t, "黑名单", banned.Rule)
+
+	// 付费规则按 50% 分桶，其他用户按默认的 10% 分桶
+	proOn, otherOn := 0, 0
+	for i := range 4000 {
+		id := fmt.Sprintf("user-%d", i)
+		pro := ev.Evaluate("beta", attrs(id, "plan", "pro"))
+		assert.Equal(t, "付费", pro.Rule)
+		if pro.Enabled {
+			proOn++
+		}
+		other := ev.Evaluate("beta", attrs(id, "plan", "free"))
+		assert.Equal(t, ReasonFallthrough, other.Reason)
+		assert.Empty(t, other.Rule)
+		if other.Enabled {
+			otherOn++
+		}
+	}
+	assert.InDelta(t, 2000, proOn, 200)
+	assert.InDelta(t, 400, otherOn, 100)
+
+	anon := ev.Evaluate("beta", attrs("", "plan", "pro"))
+	assert.Equal(t, ReasonMissingID, anon.Reason)
+	assert.Empty(t, anon.Rule)
+	assert.True(t, ev.IsEnabled("beta", attrs("", "email", "x@corp.com")), "100% 的规则不需要用户 ID")
+}
+
+func TestKillSwitch(t *testing.T) {
+	ev := NewEvaluator()
+	f := Targeted("risky", Rule{Name: "全部", When: And(), Rollout: 100})
+	ev.Set(f)
+	assert.True(t, ev.IsEnabled("risky", User{ID: "u1"}))
+
+	f.Disabled = true
+	ev.Set(f)
+	e := ev.Evaluate("risky", User{ID: "u1"})
+	assert.False(t, e.Enabled)
+	assert.Equal(t, ReasonDisabled, e.Reason)
+}
+
+func TestLoadAtomic(t *testing.T) {
+	ev := NewEvaluator()
+	assert.Equal(t, uint64(0), ev.Version())
+
+	v, err := ev.Load([]Flag{Boolean("a", true), Boolean("b", false)})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), v)
+	assert.Equal(t, []string{"a", "b"}, ev.Snapshot().Keys())
+
+	// 有一个定义不合法时整体拒绝，返回所有问题
+	v, err = ev.Load([]Flag{Boolean("c", true), Percentage("d", 101), Boolean("c", false)})
+	assert.ErrorIs(t, err, ErrInvalidFlag)
+	assert.ErrorIs(t, err, ErrDuplicateFlag)
+	assert.Equal(t, uint64(1), v)
+	assert.Equal(t, []string{"a", "b"}, ev.Snapshot().Keys())
+
+	// 整体替换：不在新定义中的开关被删除
+	v, err = ev.Load([]Flag{Boolean("c", true)})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), v)
+	assert.Equal(t, []string{"c"}, ev.Snapshot().Keys())
+}
+
+func TestSetRemove(t *testing.T) {
+	ev := NewEvaluator()
+	v, err := ev.Set(Boolean("a", true))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), v)
+
+	_, err = ev.Set(Percentage("a", -5))
+	assert.ErrorIs(t, err, ErrInvalidFlag)
+	assert.True(t, ev.IsEnabled("a", User{}))
+
+	old := ev.Snapshot()
+	v, err = ev.Remove("a")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), v)
+	assert.Equal(t, ReasonNotFound, ev.Evaluate("a", User{}).Reason)
+	assert.True(t, old.Evaluate("a", User{}).Enabled, "旧快照不受影响")
+
+	_, err = ev.Remove("a")
+	assert.ErrorIs(t, err, ErrFlagNotFound)
+	assert.Equal(t, uint64(2), ev.Version())
+}
+
+func TestSnapshotIsolatedFromCaller(t *testing.T) {
+	ev := NewEvaluator()
+	rules := []Rule{{Name: "全部", When: And(), Rollout: 100}}
+	ev.Set(Targeted("a", rules...))
+
+	rules[0].Rollout = 0
+	assert.True(t, ev.IsEnabled("a", User{ID: "u1"}), "修改调用方的切片不影响已发布的快照")
+}
+
+func TestEvaluateAllConsistent(t *testing.T) {
+	ev := NewEvaluator()
+	ev.Load([]Flag{Boolean("a", true), Boolean("b", true), Boolean("c", false)})
+
+	all := ev.EvaluateAll(User{ID: "u1"})
+	assert.Len(t, all, 3)
+	for key, e := range all {
+		assert.Equal(t, key, e.Flag)
+		assert.Equal(t, uint64(1), e.Version)
+	}
+	assert.True(t, all["a"].Enabled)
+	assert.False(t, all["c"].Enabled)
+	assert.Equal(t, 3, ev.Audit().Total())
+}
+
+func TestConcurrentReload(t *testing.T) {
+	// 每个版本中 a 和 b 的开启状态相同，评估时读到的快照不会混合新旧定义
+	ev := NewEvaluator()
+	ev.Load([]Flag{Boolean("a", false), Boolean("b", false)})
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				all := ev.EvaluateAll(User{ID: "u1"})
+				assert.Equal(t, all["a"].Enabled, all["b"].Enabled)
+				assert.Equal(t, all["a"].Version, all["b"].Version)
+			}
+		}()
+	}
+	for i := range 200 {
+		on := i%2 == 0
+		ev.Load([]Flag{Boolean("a", on), Boolean("b", on)})
+	}
+	close(stop)
+	wg.Wait()
+	assert.Equal(t, uint64(201), ev.Version())
+}
+
+func TestConcurrentSet(t *testing.T) {
+	ev := NewEvaluator()
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ev.Set(Boolean(fmt.Sprintf("flag-%d", i), true))
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, ev.Snapshot().Keys(), 50, "并发的增量修改不会丢失")
+	assert.Equal(t, uint64(50), ev.Version())
+}
+
+func TestAuditLog(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	log := NewAuditLog(3)
+	ev := NewEvaluator(WithAuditLog(log))
+	ev.now = func() time.Time { return now }
+	ev.Load([]Flag{Percentage("p", 50), Boolean("on", true)})
+
+	var forwarded []Evaluation
+	log.OnRecord(func(e Evaluation) { forwarded = append(forwarded, e) })
+
+	for i := range 5 {
+		ev.Evaluate("p", User{ID: fmt.Sprintf("user-%d", i)})
+	}
+	ev.Evaluate("on", User{ID: "u"})
+	ev.Evaluate("gone", User{ID: "u"})
+
+	assert.Equal(t, 7, log.Total())
+	assert.Len(t, forwarded, 7)
+
+	entries := log.Entries()
+	assert.Len(t, entries, 3, "只保留最近的记录")
+	assert.Equal(t, "user-4", entries[0].UserID)
+	assert.Equal(t, "on", entries[1].Flag)
+	assert.Equal(t, "gone", entries[2].Flag)
+	assert.Equal(t, now, entries[2].Time)
+	assert.Equal(t, uint64(1), entries[2].Version)
+
+	assert.Len(t, log.Query(func(e Evaluation) bool { return e.Reason == ReasonNotFound }), 1)
+
+	s := log.Summary("p")
+	assert.Equal(t, 5, s.Evaluations, "汇总包括已被覆盖的记录")
+	assert.Equal(t, 5, s.Reasons[ReasonFallthrough])
+	s.Reasons[ReasonRule] = 100
+	assert.Zero(t, log.Summary("p").Reasons[ReasonRule], "返回的汇总是副本")
+	assert.Zero(t, log.Summary("unknown").Evaluations)
+}
+
+func TestAuditDisabled(t *testing.T) {
+	ev := NewEvaluator(WithAuditLog(nil))
+	ev.Set(Boolean("a", true))
+	assert.True(t, ev.IsEnabled("a", User{}))
+	assert.Nil(t, ev.Audit())
+}
+
+func TestReasonString(t *testing.T) {
+	assert.Equal(t, "rule", ReasonRule.String())
+	assert.Equal(t, "Reason(42)", Reason(42).String())
+}
+
+func BenchmarkEvaluate(b *testing.B) {
+	ev := NewEvaluator(WithAuditLog(nil))
+	ev.Load([]Flag{Targeted("beta",
+		Rule{Name: "员工", When: HasSuffix("email", "@corp.com"), Rollout: 100},
+		Rule{Name: "付费", When: And(In("plan", "pro", "enterprise"), Equals("country", "CN")), Rollout: 50},
+	)})
+	u := attrs("user-1", "plan", "pro", "country", "CN", "email", "u@mail.com")
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			ev.Evaluate("beta", u)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package feature_flags
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+)
+
+// Buckets 分桶的总数，百分比的精度为 0.01%
+const Buckets = 10000
+
+// ErrInvalidFlag 开关定义不合法
+var ErrInvalidFlag = errors.New("开关定义不合法")
+
+// Rule 定向规则：满足 When 的用户中，按 Rollout 百分比开启
+type Rule struct {
+	Name    string    // 规则名，记录在评估结果中
+	When    Condition // 定向条件
+	Rollout float64   // 满足条件的用户中开启的百分比，0 到 100
+}
+
+// Flag 功能开关的定义
+//
+// 评估顺序：Disabled 为 true 时一律关闭；否则按顺序检查 Rules，第一个满足条件的规则决定结果；
+// 没有规则满足时按 Rollout 百分比开启。布尔开关和百分比灰度是没有规则的特例。
+type Flag struct {
+	Key         string
+	Description string
+	Disabled    bool    // 总开关，出问题时一键关闭，优先于所有规则
+	Rules       []Rule  // 定向规则，按顺序匹配
+	Rollout     float64 // 没有规则满足时开启的百分比，0 到 100
+	Salt        string  // 分桶的盐，默认使用 Key；修改后所有用户重新分桶
+}
+
+// Boolean 创建对所有用户统一开启或关闭的开关
+func Boolean(key string, on bool) Flag {
+	f := Flag{Key: key}
+	if on {
+		f.Rollout = 100
+	}
+	return f
+}
+
+// Percentage 创建按用户 ID 灰度开启 percent% 的开关
+func Percentage(key string, percent float64) Flag {
+	return Flag{Key: key, Rollout: percent}
+}
+
+// Targeted 创建按定向规则开启的开关，没有规则满足时关闭
+func Targeted(key string, rules ...Rule) Flag {
+	return Flag{Key: key, Rules: rules}
+}
+
+// Validate 检查开关定义，返回所有问题
+func (f Flag) Validate() error {
+	var errs []error
+	if f.Key == "" {
+		errs = append(errs, fmt.Errorf("%w: 键为空", ErrInvalidFlag))
+	}
+	if !validPercent(f.Rollout) {
+		errs = append(errs, fmt.Errorf("%w: %s 的灰度比例 %v 不在 0 到 100 之间", ErrInvalidFlag, f.Key, f.Rollout))
+	}
+	for i, r := range f.Rules {
+		if r.When == nil {
+			errs = append(errs, fmt.Errorf("%w: %s 的第 %d 条规则没有条件", ErrInvalidFlag, f.Key, i+1))
+		}
+		if !validPercent(r.Rollout) {
+			errs = append(errs, fmt.Errorf("%w: %s 的第 %d 条规则的灰度比例 %v 不在 0 到 100 之间", ErrInvalidFlag, f.Key, i+1, r.Rollout))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// salt 返回分桶使用的盐
+func (f Flag) salt() string {
+	if f.Salt != "" {
+		return f.Salt
+	}
+	return f.Key
+}
+
+// validPercent 检查百分比是否在 0 到 100 之间，同时排除 NaN
+func validPercent(p float64) bool {
+	return p >= 0 && p <= 100
+}
+
+// Bucket 把用户确定性地映射到 [0, Buckets) 中的一个桶
+// 同一个盐和用户 ID 总是落在同一个桶中，不同的盐相互独立，
+// 因此同一个用户在各个开关中的分组互不相关
+func Bucket(salt, userID string) int {
+	h := fnv.New64a()
+	h.Write([]byte(salt))
+	h.Write([]byte{0})
+	h.Write([]byte(userID))
+	x := h.Sum64()
+	// FNV 对相似的短字符串区分度不够，用 murmur3 的 fmix64 打散
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return int(x % Buckets)
+}
+
+// inRollout 判断桶是否落在 percent% 之内
+// 阈值随比例单调增长：比例从 10% 调到 20% 时，原来开启的用户保持开启
+func inRollout(bucket int, percent float64) bool {
+	return float64(bucket) < percent*Buckets/100
+}
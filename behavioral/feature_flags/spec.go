@@ -0,0 +1,188 @@
+package feature_flags
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// User 被评估的用户：ID 用于分桶，Attributes 用于定向规则
+type User struct {
+	ID         string
+	Attributes map[string]string
+}
+
+// Condition 定向条件（规约模式）：判断用户是否满足条件
+// 条件可以用 And、Or、Not 组合，String 返回可读的描述，用于审计和排查
+type Condition interface {
+	IsSatisfiedBy(u User) bool
+	String() string
+}
+
+// userIn 用户 ID 在名单中
+type userIn struct {
+	ids []string
+}
+
+// UserIn 用户 ID 在名单中，常用于内部测试名单
+func UserIn(ids ...string) Condition {
+	return userIn{ids: slices.Clone(ids)}
+}
+
+func (c userIn) IsSatisfiedBy(u User) bool {
+	return slices.Contains(c.ids, u.ID)
+}
+
+func (c userIn) String() string {
+	return fmt.Sprintf("id in [%s]", strings.Join(c.ids, ", "))
+}
+
+// attrIn 属性值在集合中
+type attrIn struct {
+	name   string
+	values []string
+}
+
+// Equals 属性等于 value；用户没有该属性时不满足
+func Equals(name, value string) Condition {
+	return attrIn{name: name, values: []string{value}}
+}
+
+// In 属性等于 values 中的任意一个
+func In(name string, values ...string) Condition {
+	return attrIn{name: name, values: slices.Clone(values)}
+}
+
+func (c attrIn) IsSatisfiedBy(u User) bool {
+	v, ok := u.Attributes[c.name]
+	return ok && slices.Contains(c.values, v)
+}
+
+func (c attrIn) String() string {
+	if len(c.values) == 1 {
+		return fmt.Sprintf("%s == %q", c.name, c.values[0])
+	}
+	quoted := make([]string, len(c.values))
+	for i, v := range c.values {
+		quoted[i] = strconv.Quote(v)
+	}
+	return fmt.Sprintf("%s in [%s]", c.name, strings.Join(quoted, ", "))
+}
+
+// hasSuffix 属性以指定后缀结尾
+type hasSuffix struct {
+	name   string
+	suffix string
+}
+
+// HasSuffix 属性以 suffix 结尾，例如按邮箱域名定向
+func HasSuffix(name, suffix string) Condition {
+	return hasSuffix{name: name, suffix: suffix}
+}
+
+func (c hasSuffix) IsSatisfiedBy(u User) bool {
+	v, ok := u.Attributes[c.name]
+	return ok && strings.HasSuffix(v, c.suffix)
+}
+
+func (c hasSuffix) String() string {
+	return fmt.Sprintf("%s ends with %q", c.name, c.suffix)
+}
+
+// atLeast 数值属性不小于阈值
+type atLeast struct {
+	name string
+	min  float64
+}
+
+// AtLeast 属性按数字解析后不小于 min；没有该属性或不是数字时不满足
+func AtLeast(name string, min float64) Condition {
+	return atLeast{name: name, min: min}
+}
+
+func (c atLeast) IsSatisfiedBy(u User) bool {
+	v, ok := u.Attributes[c.name]
+	if !ok {
+		return false
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	return err == nil && n >= c.min
+}
+
+func (c atLeast) String() string {
+	return fmt.Sprintf("%s >= %s", c.name, strconv.FormatFloat(c.min, 'g', -1, 64))
+}
+
+// and 全部条件都满足
+type and []Condition
+
+// And 全部条件都满足；没有条件时总是满足
+func And(conds ...Condition) Condition {
+	return and(slices.Clone(conds))
+}
+
+func (c and) IsSatisfiedBy(u User) bool {
+	for _, cond := range c {
+		if !cond.IsSatisfiedBy(u) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c and) String() string {
+	return join(c, " && ")
+}
+
+// or 任意一个条件满足
+type or []Condition
+
+// Or 任意一个条件满足；没有条件时总是不满足
+func Or(conds ...Condition) Condition {
+	return or(slices.Clone(conds))
+}
+
+func (c or) IsSatisfiedBy(u User) bool {
+	for _, cond := range c {
+		if cond.IsSatisfiedBy(u) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c or) String() string {
+	return join(c, " || ")
+}
+
+// not 条件取反
+type not struct {
+	cond Condition
+}
+
+// Not 条件不满足
+func Not(cond Condition) Condition {
+	return not{cond: cond}
+}
+
+func (c not) IsSatisfiedBy(u User) bool {
+	return !c.cond.IsSatisfiedBy(u)
+}
+
+func (c not) String() string {
+	return "!(" + c.cond.String() + ")"
+}
+
+// join 用 sep 连接子条件的描述，多于一个时加括号
+func join(conds []Condition, sep string) string {
+	parts := make([]string, len(conds))
+	for i, cond := range conds {
+		parts[i] = cond.String()
+	}
+	s := strings.Join(parts, sep)
+	if len(conds) > 1 {
+		s = "(" + s + ")"
+	}
+	return s
+}
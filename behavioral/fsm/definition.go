@@ -0,0 +1,206 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrInvalidDefinition 状态机定义不合法，例如父状态不存在或者父子关系成环
+var ErrInvalidDefinition = errors.New("状态机定义无效")
+
+// Action 进入、退出状态或执行转换时调用的动作
+type Action[S, E comparable] func(ctx *Context[S, E])
+
+// Guard 转换的守卫条件，返回 false 时该转换不会发生
+type Guard[S, E comparable] func(ctx *Context[S, E]) bool
+
+// stateDef 一个状态的定义
+type stateDef[S, E comparable] struct {
+	name     S
+	parent   *stateDef[S, E]
+	children []*stateDef[S, E]
+	initial  *stateDef[S, E] // 复合状态进入时默认进入的子状态
+
+	onEnter []Action[S, E]
+	onExit  []Action[S, E]
+
+	timeout      time.Duration // 大于 0 时，在状态中停留这么久会触发 timeoutEvent
+	timeoutEvent E
+}
+
+// ancestorOf 判断 s 是否是 other 本身或其祖先
+func (s *stateDef[S, E]) ancestorOf(other *stateDef[S, E]) bool {
+	for ; other != nil; other = other.parent {
+		if other == s {
+			return true
+		}
+	}
+	return false
+}
+
+// transitionDef 转换表中的一行
+type transitionDef[S, E comparable] struct {
+	from, to  *stateDef[S, E]
+	event     E
+	guard     Guard[S, E]
+	guardName string
+	actions   []Action[S, E]
+}
+
+// Definition 状态机的定义：状态、事件、守卫和动作组成的转换表
+//
+// 与状态模式把每个状态写成一个类型不同，这里的状态和转换都是数据：
+// 先用 State 和 Transition 登记，再用 NewMachine 创建任意多个互相独立的状态机实例。
+// 转换表可以从配置生成，也可以用 DOT 导出成图检查。
+//
+// 定义在第一次调用 NewMachine 时校验，之后不应再修改
+type Definition[S, E comparable] struct {
+	initial     S
+	states      map[S]*stateDef[S, E]
+	order       []*stateDef[S, E] // 登记顺序，保证导出的图稳定
+	parents     map[S]S           // 延迟到校验时再解析，允许先登记子状态
+	initials    map[S]S
+	transitions []*transitionDef[S, E]
+	index       map[S]map[E][]*transitionDef[S, E]
+
+	once sync.Once // 第一次创建状态机时校验
+	err  error
+}
+
+// NewDefinition 创建以 initial 为初始状态的定义
+func NewDefinition[S, E comparable](initial S) *Definition[S, E] {
+	return &Definition[S, E]{
+		initial:  initial,
+		states:   make(map[S]*stateDef[S, E]),
+		parents:  make(map[S]S),
+		initials: make(map[S]S),
+		index:    make(map[S]map[E][]*transitionDef[S, E]),
+	}
+}
+
+// state 返回状态的定义，第一次出现时登记为顶层的普通状态
+func (d *Definition[S, E]) state(name S) *stateDef[S, E] {
+	s, ok := d.states[name]
+	if !ok {
+		s = &stateDef[S, E]{name: name}
+		d.states[name] = s
+		d.order = append(d.order, s)
+	}
+	return s
+}
+
+// StateBuilder 用于配置一个状态
+type StateBuilder[S, E comparable] struct {
+	def   *Definition[S, E]
+	state *stateDef[S, E]
+}
+
+// State 登记状态并返回其配置器；只出现在转换中的状态会自动登记为顶层状态
+func (d *Definition[S, E]) State(name S) *StateBuilder[S, E] {
+	return &StateBuilder[S, E]{def: d, state: d.state(name)}
+}
+
+// Parent 设置父状态，使该状态成为父状态的子状态
+// 子状态中没有处理的事件交给父状态处理，父状态上的转换对所有子状态都有效
+func (b *StateBuilder[S, E]) Parent(parent S) *StateBuilder[S, E] {
+	b.def.state(parent)
+	b.def.parents[b.state.name] = parent
+	return b
+}
+
+// Initial 设置复合状态的初始子状态，未设置时使用第一个登记的子状态
+func (b *StateBuilder[S, E]) Initial(child S) *StateBuilder[S, E] {
+	b.def.state(child)
+	b.def.initials[b.state.name] = child
+	return b
+}
+
+// OnEnter 添加进入该状态时执行的动作
+func (b *StateBuilder[S, E]) OnEnter(action Action[S, E]) *StateBuilder[S, E] {
+	b.state.onEnter = append(b.state.onEnter, action)
+	return b
+}
+
+// OnExit 添加离开该状态时执行的动作
+func (b *StateBuilder[S, E]) OnExit(action Action[S, E]) *StateBuilder[S, E] {
+	b.state.onExit = append(b.state.onExit, action)
+	return b
+}
+
+// Timeout 在状态中停留 d 后触发 event；离开状态时计时取消，重新进入时重新计时
+func (b *StateBuilder[S, E]) Timeout(d time.Duration, event E) *StateBuilder[S, E] {
+	b.state.timeout = d
+	b.state.timeoutEvent = event
+	return b
+}
+
+// TransitionBuilder 用于配置一个转换
+type TransitionBuilder[S, E comparable] struct {
+	transition *transitionDef[S, E]
+}
+
+// Transition 登记转换：处于 from（或其子状态）时收到 event 转换到 to
+// 同一状态的同一事件可以登记多个转换，按登记顺序检查守卫，第一个满足的生效
+func (d *Definition[S, E]) Transition(from S, event E, to S) *TransitionBuilder[S, E] {
+	t := &transitionDef[S, E]{from: d.state(from), to: d.state(to), event: event}
+	d.transitions = append(d.transitions, t)
+	if d.index[from] == nil {
+		d.index[from] = make(map[E][]*transitionDef[S, E])
+	}
+	d.index[from][event] = append(d.index[from][event], t)
+	return &TransitionBuilder[S, E]{transition: t}
+}
+
+// Guard 设置守卫条件，name 用于导出的图和错误信息
+func (b *TransitionBuilder[S, E]) Guard(name string, guard Guard[S, E]) *TransitionBuilder[S, E] {
+	b.transition.guard = guard
+	b.transition.guardName = name
+	return b
+}
+
+// Do 添加转换时执行的动作，在退出源状态之后、进入目标状态之前执行
+func (b *TransitionBuilder[S, E]) Do(action Action[S, E]) *TransitionBuilder[S, E] {
+	b.transition.actions = append(b.transition.actions, action)
+	return b
+}
+
+// validate 解析父子关系并校验定义
+func (d *Definition[S, E]) validate() error {
+	for _, s := range d.order {
+		if p, ok := d.parents[s.name]; ok {
+			s.parent = d.states[p]
+			s.parent.children = append(s.parent.children, s)
+		}
+	}
+
+	var errs []error
+	if _, ok := d.states[d.initial]; !ok {
+		errs = append(errs, fmt.Errorf("%w: 初始状态 %v 没有登记", ErrInvalidDefinition, d.initial))
+	}
+	for _, s := range d.order {
+		seen := map[*stateDef[S, E]]bool{}
+		for p := s; p != nil; p = p.parent {
+			if seen[p] {
+				errs = append(errs, fmt.Errorf("%w: 状态 %v 的父子关系成环", ErrInvalidDefinition, s.name))
+				break
+			}
+			seen[p] = true
+		}
+		if len(s.children) == 0 {
+			if c, ok := d.initials[s.name]; ok {
+				errs = append(errs, fmt.Errorf("%w: 状态 %v 没有子状态，不能设置初始子状态 %v", ErrInvalidDefinition, s.name, c))
+			}
+			continue
+		}
+		s.initial = s.children[0]
+		if c, ok := d.initials[s.name]; ok {
+			s.initial = d.states[c]
+			if s.initial.parent != s {
+				errs = append(errs, fmt.Errorf("%w: %v 不是 %v 的子状态", ErrInvalidDefinition, c, s.name))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
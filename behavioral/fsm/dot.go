@@ -0,0 +1,100 @@
+package fsm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DOT 导出定义的 Graphviz 图，可以用 dot -Tsvg 渲染；定义不合法时返回校验错误
+// 复合状态画成包含子状态的子图，守卫写在事件名后的方括号中，超时写在状态名下方
+func (d *Definition[S, E]) DOT() (string, error) {
+	d.once.Do(func() { d.err = d.validate() })
+	if d.err != nil {
+		return "", d.err
+	}
+	return d.dot(nil), nil
+}
+
+// dot 生成图，active 中的状态被填充颜色
+func (d *Definition[S, E]) dot(active map[*stateDef[S, E]]bool) string {
+	var b strings.Builder
+	b.WriteString("digraph fsm {\n")
+	b.WriteString("  compound=true;\n")
+	b.WriteString("  node [shape=box, style=rounded];\n")
+	b.WriteString("  __start [shape=point];\n")
+
+	var writeState func(s *stateDef[S, E], indent string)
+	writeState = func(s *stateDef[S, E], indent string) {
+		if len(s.children) == 0 {
+			style := ""
+			if active[s] {
+				style = `, style="rounded,filled", fillcolor=lightblue`
+			}
+			fmt.Fprintf(&b, "%s%s [label=%s%s];\n", indent, nodeID(s.name), strconv.Quote(stateLabel(s)), style)
+			return
+		}
+		fmt.Fprintf(&b, "%ssubgraph %s {\n", indent, strconv.Quote("cluster_"+fmt.Sprint(s.name)))
+		fmt.Fprintf(&b, "%s  label=%s;\n", indent, strconv.Quote(stateLabel(s)))
+		if active[s] {
+			fmt.Fprintf(&b, "%s  style=filled; fillcolor=aliceblue;\n", indent)
+		}
+		for _, child := range s.children {
+			writeState(child, indent+"  ")
+		}
+		fmt.Fprintf(&b, "%s}\n", indent)
+	}
+	for _, s := range d.order {
+		if s.parent == nil {
+			writeState(s, "  ")
+		}
+	}
+
+	initial := d.states[d.initial]
+	fmt.Fprintf(&b, "  __start -> %s%s;\n", nodeID(leaf(initial).name), edgeAttrs(nil, nil, initial))
+	for _, t := range d.transitions {
+		label := fmt.Sprint(t.event)
+		if t.guardName != "" {
+			label += " [" + t.guardName + "]"
+		}
+		fmt.Fprintf(&b, "  %s -> %s%s;\n", nodeID(leaf(t.from).name), nodeID(leaf(t.to).name),
+			edgeAttrs([]string{"label=" + strconv.Quote(label)}, t.from, t.to))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// nodeID 返回状态在图中的节点名
+func nodeID[S comparable](name S) string {
+	return strconv.Quote(fmt.Sprint(name))
+}
+
+// stateLabel 返回状态的显示名，有超时的状态附带超时说明
+func stateLabel[S, E comparable](s *stateDef[S, E]) string {
+	if s.timeout > 0 {
+		return fmt.Sprintf("%v\n(%v 后: %v)", s.name, s.timeout, s.timeoutEvent)
+	}
+	return fmt.Sprint(s.name)
+}
+
+// leaf 返回复合状态最终进入的普通状态；Graphviz 的边只能连接节点，连接子图时借用其中的节点
+func leaf[S, E comparable](s *stateDef[S, E]) *stateDef[S, E] {
+	for s.initial != nil {
+		s = s.initial
+	}
+	return s
+}
+
+// edgeAttrs 生成边的属性；连接复合状态的边用 ltail/lhead 画到子图的边框上
+func edgeAttrs[S, E comparable](attrs []string, from, to *stateDef[S, E]) string {
+	if from != nil && len(from.children) > 0 {
+		attrs = append(attrs, "ltail="+strconv.Quote("cluster_"+fmt.Sprint(from.name)))
+	}
+	if to != nil && len(to.children) > 0 {
+		attrs = append(attrs, "lhead="+strconv.Quote("cluster_"+fmt.Sprint(to.name)))
+	}
+	if len(attrs) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(attrs, ", ") + "]"
+}
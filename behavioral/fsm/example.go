@@ -0,0 +1,125 @@
+package fsm
+
+import (
+	"fmt"
+	"time"
+)
+
+// connState 示例中连接的状态
+type connState string
+
+// connEvent 示例中连接收到的事件
+type connEvent string
+
+const (
+	stateDisconnected connState = "Disconnected"
+	stateConnecting   connState = "Connecting"
+	stateConnected    connState = "Connected" // 复合状态，包含 Idle 和 Transferring
+	stateIdle         connState = "Idle"
+	stateTransferring connState = "Transferring"
+	stateFailed       connState = "Failed"
+
+	eventConnect     connEvent = "connect"
+	eventEstablished connEvent = "established"
+	eventTimeout     connEvent = "timeout"
+	eventSend        connEvent = "send"
+	eventDone        connEvent = "done"
+	eventIdle        connEvent = "idle"
+	eventDisconnect  connEvent = "disconnect"
+	eventReset       connEvent = "reset"
+)
+
+// maxAttempts 示例中连接的最大尝试次数
+const maxAttempts = 3
+
+// newConnectionDefinition 创建网络连接的状态机定义
+//
+//   - 连接超时后重试，超过最大次数进入 Failed
+//   - Connected 是复合状态：disconnect 登记在 Connected 上，对 Idle 和 Transferring 都有效
+//   - Idle 空闲一段时间后自动断开
+//
+// 重试次数保存在闭包中，同一个定义只应创建一个状态机
+func newConnectionDefinition(connectTimeout, idleTimeout time.Duration, log func(string)) *Definition[connState, connEvent] {
+	attempts := 0
+	d := NewDefinition[connState, connEvent](stateDisconnected)
+
+	d.State(stateConnecting).
+		Timeout(connectTimeout, eventTimeout).
+		OnEnter(func(ctx *Context[connState, connEvent]) {
+			attempts++
+			log(fmt.Sprintf("第 %d 次尝试连接", attempts))
+		})
+	d.State(stateConnected).
+		Initial(stateIdle).
+		OnEnter(func(*Context[connState, connEvent]) { attempts = 0; log("连接已建立") }).
+		OnExit(func(*Context[connState, connEvent]) { log("连接已关闭") })
+	d.State(stateIdle).Parent(stateConnected).Timeout(idleTimeout, eventIdle)
+	d.State(stateTransferring).Parent(stateConnected).
+		OnEnter(func(ctx *Context[connState, connEvent]) { log(fmt.Sprintf("发送 %v", ctx.Payload)) })
+
+	d.Transition(stateDisconnected, eventConnect, stateConnecting)
+	d.Transition(stateConnecting, eventEstablished, stateConnected)
+	d.Transition(stateConnecting, eventTimeout, stateConnecting).
+		Guard("可以重试", func(*Context[connState, connEvent]) bool { return attempts < maxAttempts })
+	d.Transition(stateConnecting, eventTimeout, stateFailed).
+		Do(func(*Context[connState, connEvent]) { log("重试次数用尽") })
+	d.Transition(stateIdle, eventSend, stateTransferring)
+	d.Transition(stateTransferring, eventDone, stateIdle)
+	d.Transition(stateIdle, eventIdle, stateDisconnected).
+		Do(func(*Context[connState, connEvent]) { log("空闲超时") })
+	d.Transition(stateConnected, eventDisconnect, stateDisconnected)
+	d.Transition(stateFailed, eventReset, stateDisconnected).
+		Do(func(*Context[connState, connEvent]) { attempts = 0 })
+	return d
+}
+
+// RunExample 运行有限状态机示例
+func RunExample() {
+	log := func(msg string) { fmt.Println("  " + msg) }
+	failures := make(chan error, 10)
+	def := newConnectionDefinition(20*time.Millisecond, 30*time.Millisecond, log)
+	m, err := def.NewMachine(WithErrorHandler(func(err error) {
+		select {
+		case failures <- err:
+		default:
+		}
+	}))
+	if err != nil {
+		fmt.Println("创建状态机失败:", err)
+		return
+	}
+	defer m.Close()
+
+	fmt.Println("== 连接、发送、断开 ==")
+	m.Fire(eventConnect, nil)
+	m.Fire(eventEstablished, nil)
+	m.Fire(eventSend, "hello")
+	fmt.Println("  当前状态路径:", m.Path())
+	m.Fire(eventDisconnect, nil) // 登记在父状态 Connected 上，在 Transferring 中同样有效
+	fmt.Println("  当前状态:", m.Current())
+
+	fmt.Println("== 非法事件 ==")
+	fmt.Println(" ", m.Fire(eventSend, "data"))
+
+	fmt.Println("== 连接超时，自动重试 ==")
+	m.Fire(eventConnect, nil)
+	time.Sleep(100 * time.Millisecond)
+	fmt.Println("  当前状态:", m.Current())
+	m.Fire(eventReset, nil)
+
+	fmt.Println("== 空闲超时，自动断开 ==")
+	m.Fire(eventConnect, nil)
+	m.Fire(eventEstablished, nil)
+	time.Sleep(60 * time.Millisecond)
+	fmt.Println("  当前状态:", m.Current())
+
+	select {
+	case err := <-failures:
+		fmt.Println("超时事件处理失败:", err)
+	default:
+	}
+
+	dot, _ := def.DOT()
+	fmt.Println("== DOT ==")
+	fmt.Print(dot)
+}
@@ -0,0 +1,307 @@
+package fsm
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder 按顺序记录进入、退出和转换动作
+type recorder struct {
+	mutex sync.Mutex
+	log   []string
+}
+
+func (r *recorder) add(format string, args ...any) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.log = append(r.log, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) take() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	log := r.log
+	r.log = nil
+	return log
+}
+
+// traced 为每个状态登记记录进入和退出的动作
+func traced(d *Definition[string, string], r *recorder, states ...string) {
+	for _, s := range states {
+		d.State(s).
+			OnEnter(func(*Context[string, string]) { r.add("enter %s", s) }).
+			OnExit(func(*Context[string, string]) { r.add("exit %s", s) })
+	}
+}
+
+// TestBasicTransitions 测试简单转换、动作参数和错误
+func TestBasicTransitions(t *testing.T) {
+	d := NewDefinition[string, string]("locked")
+	var got *Context[string, string]
+	d.Transition("locked", "coin", "unlocked").Do(func(ctx *Context[string, string]) {
+		c := *ctx
+		got = &c
+	})
+	d.Transition("unlocked", "push", "locked")
+
+	m, err := d.NewMachine()
+	assert.NoError(t, err)
+	assert.Equal(t, "locked", m.Current())
+	assert.True(t, m.Can("coin"))
+	assert.False(t, m.Can("push"))
+
+	assert.NoError(t, m.Fire("coin", 50))
+	assert.Equal(t, "unlocked", m.Current())
+	assert.Equal(t, "locked", got.From)
+	assert.Equal(t, "unlocked", got.To)
+	assert.Equal(t, "coin", got.Event)
+	assert.Equal(t, 50, got.Payload)
+
+	err = m.Fire("coin", nil)
+	assert.ErrorIs(t, err, ErrNoTransition)
+	assert.Equal(t, "unlocked", m.Current(), "失败的事件不改变状态")
+}
+
+// TestGuards 测试同一事件的多个转换按登记顺序检查守卫
+func TestGuards(t *testing.T) {
+	d := NewDefinition[string, string]("idle")
+	d.Transition("idle", "order", "vip").Guard("金额大", func(ctx *Context[string, string]) bool {
+		return ctx.Payload.(int) >= 1000
+	})
+	d.Transition("idle", "order", "normal").Guard("金额为正", func(ctx *Context[string, string]) bool {
+		return ctx.Payload.(int) > 0
+	})
+
+	for amount, want := range map[int]string{5000: "vip", 10: "normal"} {
+		m, _ := d.NewMachine()
+		assert.NoError(t, m.Fire("order", amount))
+		assert.Equal(t, want, m.Current())
+	}
+
+	m, _ := d.NewMachine()
+	assert.ErrorIs(t, m.Fire("order", -1), ErrGuardRejected)
+	assert.Equal(t, "idle", m.Current())
+}
+
+// TestHierarchy 测试复合状态：初始子状态、事件向父状态冒泡、进入和退出的顺序
+func TestHierarchy(t *testing.T) {
+	r := &recorder{}
+	d := NewDefinition[string, string]("off")
+	d.State("on").Initial("standby")
+	d.State("standby").Parent("on")
+	d.State("running").Parent("on")
+	d.State("slow").Parent("running")
+	d.State("fast").Parent("running")
+	traced(d, r, "off", "on", "standby", "running", "slow", "fast")
+
+	d.Transition("off", "power", "on")
+	d.Transition("standby", "start", "running")
+	d.Transition("slow", "faster", "fast")
+	d.Transition("running", "stop", "standby")
+	d.Transition("running", "reset", "running").Do(func(*Context[string, string]) { r.add("reset") })
+	d.Transition("on", "power", "off")
+	d.Transition("fast", "home", "on")
+
+	m, err := d.NewMachine()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"enter off"}, r.take())
+
+	assert.NoError(t, m.Fire("power", nil))
+	assert.Equal(t, []string{"exit off", "enter on", "enter standby"}, r.take(), "进入复合状态时进入初始子状态")
+
+	assert.NoError(t, m.Fire("start", nil))
+	assert.Equal(t, "slow", m.Current(), "running 的初始子状态默认为第一个子状态")
+	assert.Equal(t, []string{"on", "running", "slow"}, m.Path())
+	assert.True(t, m.Is("on"))
+	assert.True(t, m.Is("running"))
+	assert.False(t, m.Is("fast"))
+	assert.Equal(t, []string{"exit standby", "enter running", "enter slow"}, r.take())
+
+	assert.NoError(t, m.Fire("faster", nil))
+	assert.Equal(t, []string{"exit slow", "enter fast"}, r.take(), "兄弟状态之间只退出和进入自身")
+
+	assert.NoError(t, m.Fire("reset", nil))
+	assert.Equal(t, []string{"exit fast", "exit running", "reset", "enter running", "enter slow"}, r.take(),
+		"自转换先退出再重新进入")
+
+	assert.NoError(t, m.Fire("faster", nil))
+	r.take()
+	assert.NoError(t, m.Fire("home", nil))
+	assert.Equal(t, []string{"exit fast", "exit running", "enter standby"}, r.take(),
+		"转换到祖先时祖先保持激活，只重新进入初始子状态")
+
+	assert.NoError(t, m.Fire("start", nil))
+	r.take()
+	assert.NoError(t, m.Fire("power", nil), "子状态没有处理的事件交给父状态")
+	assert.Equal(t, []string{"exit slow", "exit running", "exit on", "enter off"}, r.take())
+	assert.Equal(t, "off", m.Current())
+}
+
+// TestChildOverridesParent 测试子状态的转换优先于父状态的同名转换
+func TestChildOverridesParent(t *testing.T) {
+	d := NewDefinition[string, string]("child")
+	d.State("child").Parent("parent")
+	d.Transition("parent", "go", "a")
+	d.Transition("child", "go", "b")
+	m, _ := d.NewMachine()
+	assert.Equal(t, []string{"parent", "child"}, m.Path(), "初始状态的父状态也被进入")
+	assert.NoError(t, m.Fire("go", nil))
+	assert.Equal(t, "b", m.Current())
+}
+
+// TestDeferredEvents 测试动作中触发的事件在当前转换完成后处理
+func TestDeferredEvents(t *testing.T) {
+	r := &recorder{}
+	d := NewDefinition[string, string]("a")
+	traced(d, r, "a", "b", "c")
+	d.State("b").OnEnter(func(ctx *Context[string, string]) { ctx.Fire("next", nil) })
+	d.Transition("a", "go", "b")
+	d.Transition("b", "next", "c")
+
+	m, _ := d.NewMachine()
+	r.take()
+	assert.NoError(t, m.Fire("go", nil))
+	assert.Equal(t, "c", m.Current())
+	assert.Equal(t, []string{"exit a", "enter b", "exit b", "enter c"}, r.take(),
+		"b 的进入动作全部完成后才处理 next")
+
+	d2 := NewDefinition[string, string]("a")
+	d2.Transition("a", "go", "b").Do(func(ctx *Context[string, string]) { ctx.Fire("bad", nil) })
+	m2, _ := d2.NewMachine()
+	err := m2.Fire("go", nil)
+	assert.ErrorIs(t, err, ErrNoTransition, "后续事件的错误一并返回")
+	assert.Equal(t, "b", m2.Current())
+}
+
+// TestTimeout 测试超时触发事件，离开状态后计时取消，重新进入时重新计时
+func TestTimeout(t *testing.T) {
+	d := NewDefinition[string, string]("waiting")
+	d.State("waiting").Timeout(30*time.Millisecond, "expire")
+	d.Transition("waiting", "expire", "expired")
+	d.Transition("waiting", "answer", "answered")
+	d.Transition("waiting", "poke", "waiting")
+
+	m, _ := d.NewMachine()
+	defer m.Close()
+	assert.Eventually(t, func() bool { return m.Current() == "expired" }, time.Second, 5*time.Millisecond)
+
+	m2, _ := d.NewMachine()
+	defer m2.Close()
+	assert.NoError(t, m2.Fire("answer", nil))
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, "answered", m2.Current(), "离开状态后超时不再触发")
+
+	m3, _ := d.NewMachine()
+	defer m3.Close()
+	for i := 0; i < 4; i++ {
+		time.Sleep(15 * time.Millisecond)
+		assert.NoError(t, m3.Fire("poke", nil))
+	}
+	assert.Equal(t, "waiting", m3.Current(), "自转换重新计时")
+	assert.Eventually(t, func() bool { return m3.Current() == "expired" }, time.Second, 5*time.Millisecond)
+}
+
+// TestTimeoutErrorHandler 测试超时事件没有转换时报告给错误处理函数
+func TestTimeoutErrorHandler(t *testing.T) {
+	d := NewDefinition[string, string]("a")
+	d.State("a").Timeout(10*time.Millisecond, "tick")
+	errs := make(chan error, 1)
+	m, _ := d.NewMachine(WithErrorHandler(func(err error) { errs <- err }))
+	defer m.Close()
+
+	select {
+	case err := <-errs:
+		assert.ErrorIs(t, err, ErrNoTransition)
+		assert.Contains(t, err.Error(), "状态 a 超时")
+	case <-time.After(time.Second):
+		t.Fatal("没有收到超时错误")
+	}
+}
+
+// TestClose 测试关闭后拒绝事件并取消计时
+func TestClose(t *testing.T) {
+	d := NewDefinition[string, string]("a")
+	d.State("a").Timeout(20*time.Millisecond, "expire")
+	d.Transition("a", "expire", "b")
+	m, _ := d.NewMachine()
+	m.Close()
+	assert.ErrorIs(t, m.Fire("expire", nil), ErrClosed)
+	time.Sleep(40 * time.Millisecond)
+	assert.Equal(t, "a", m.Current())
+}
+
+// TestInvalidDefinition 测试定义校验
+func TestInvalidDefinition(t *testing.T) {
+	cases := map[string]func(d *Definition[string, string]){
+		"父子关系成环": func(d *Definition[string, string]) {
+			d.State("x").Parent("y")
+			d.State("y").Parent("x")
+		},
+		"初始子状态不是子状态": func(d *Definition[string, string]) {
+			d.State("p").Initial("other")
+			d.State("c").Parent("p")
+		},
+		"普通状态设置初始子状态": func(d *Definition[string, string]) {
+			d.State("start").Initial("other")
+		},
+	}
+	for name, build := range cases {
+		d := NewDefinition[string, string]("start")
+		d.State("start")
+		build(d)
+		_, err := d.NewMachine()
+		assert.ErrorIs(t, err, ErrInvalidDefinition, name)
+		_, err = d.DOT()
+		assert.ErrorIs(t, err, ErrInvalidDefinition, name)
+	}
+
+	_, err := NewDefinition[string, string]("missing").NewMachine()
+	assert.ErrorIs(t, err, ErrInvalidDefinition, "初始状态没有登记")
+}
+
+// TestConcurrentFire 测试多个协程同时触发事件
+func TestConcurrentFire(t *testing.T) {
+	d := NewDefinition[string, int]("s")
+	count := 0
+	d.Transition("s", 1, "s").Do(func(*Context[string, int]) { count++ })
+	m, _ := d.NewMachine()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				assert.NoError(t, m.Fire(1, nil))
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 800, count, "转换串行执行")
+}
+
+// TestDOT 测试导出的图包含子图、守卫、超时和当前状态
+func TestDOT(t *testing.T) {
+	def := newConnectionDefinition(time.Second, time.Second, func(string) {})
+	dot, err := def.DOT()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(dot, "digraph fsm {"))
+	assert.Contains(t, dot, `subgraph "cluster_Connected" {`)
+	assert.Contains(t, dot, `"Connecting" -> "Connecting" [label="timeout [可以重试]"];`)
+	assert.Contains(t, dot, `"Connecting" -> "Idle" [label="established", lhead="cluster_Connected"];`)
+	assert.Contains(t, dot, `"Idle" -> "Disconnected" [label="disconnect", ltail="cluster_Connected"];`)
+	assert.Contains(t, dot, `"Idle" [label="Idle\n(1s 后: idle)"];`)
+	assert.Contains(t, dot, `__start -> "Disconnected";`)
+
+	m, _ := def.NewMachine()
+	defer m.Close()
+	assert.NoError(t, m.Fire(eventConnect, nil))
+	assert.NoError(t, m.Fire(eventEstablished, nil))
+	assert.Contains(t, m.DOT(), `"Idle" [label="Idle\n(1s 后: idle)", style="rounded,filled", fillcolor=lightblue];`)
+	assert.Contains(t, m.DOT(), "style=filled; fillcolor=aliceblue;")
+}
@@ -0,0 +1,287 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNoTransition 当前状态及其所有父状态都没有该事件的转换
+	ErrNoTransition = errors.New("没有可用的转换")
+	// ErrGuardRejected 存在该事件的转换，但所有守卫条件都不满足
+	ErrGuardRejected = errors.New("守卫条件不满足")
+	// ErrClosed 状态机已关闭
+	ErrClosed = errors.New("状态机已关闭")
+)
+
+// Context 传给守卫和动作的转换信息
+type Context[S, E comparable] struct {
+	From    S   // 收到事件时所处的（最内层）状态；进入初始状态时为零值
+	To      S   // 转换登记的目标状态，可能是复合状态
+	Event   E   // 触发转换的事件；进入初始状态时为零值
+	Payload any // Fire 传入的附加数据
+	machine *Machine[S, E]
+}
+
+// Fire 在动作中触发后续事件
+// 事件在当前转换完成后才处理（运行至完成语义），动作中不能直接调用 Machine.Fire，否则会死锁
+func (c *Context[S, E]) Fire(event E, payload any) {
+	c.machine.deferred = append(c.machine.deferred, queuedEvent[E]{event, payload})
+}
+
+// queuedEvent 等待处理的后续事件
+type queuedEvent[E comparable] struct {
+	event   E
+	payload any
+}
+
+// config 状态机的可选配置
+type config struct {
+	errorHandler func(error)
+}
+
+// Option 状态机选项
+type Option func(*config)
+
+// WithErrorHandler 设置超时触发的事件处理失败时的回调，默认忽略
+// 超时在后台协程中触发，没有调用方可以接收 Fire 返回的错误
+func WithErrorHandler(handler func(error)) Option {
+	return func(c *config) {
+		c.errorHandler = handler
+	}
+}
+
+// Machine 按 Definition 运行的状态机实例，并发安全
+//
+// 当前状态总是最内层的普通状态；处于子状态时，它的所有父状态也都处于激活状态
+type Machine[S, E comparable] struct {
+	def    *Definition[S, E]
+	config config
+
+	mutex    sync.Mutex
+	current  *stateDef[S, E]
+	deferred []queuedEvent[E]
+	timers   map[*stateDef[S, E]]*time.Timer
+	epochs   map[*stateDef[S, E]]uint64 // 每次进入状态加一，使过期的超时失效
+	closed   bool
+}
+
+// NewMachine 校验定义并创建状态机，创建时依次进入初始状态及其初始子状态
+// 进入动作中触发的后续事件处理失败时，返回创建好的状态机和这些错误
+func (d *Definition[S, E]) NewMachine(opts ...Option) (*Machine[S, E], error) {
+	d.once.Do(func() { d.err = d.validate() })
+	if d.err != nil {
+		return nil, d.err
+	}
+	m := &Machine[S, E]{
+		def:    d,
+		timers: make(map[*stateDef[S, E]]*time.Timer),
+		epochs: make(map[*stateDef[S, E]]uint64),
+	}
+	for _, opt := range opts {
+		opt(&m.config)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	target := d.states[d.initial]
+	ctx := &Context[S, E]{To: d.initial, machine: m}
+	var path []*stateDef[S, E]
+	for s := target; s != nil; s = s.parent {
+		path = append(path, s)
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		m.enterLocked(path[i], ctx)
+	}
+	m.current = m.descendLocked(target, ctx)
+	return m, m.drainLocked()
+}
+
+// Current 返回当前（最内层）状态
+func (m *Machine[S, E]) Current() S {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.current.name
+}
+
+// Path 返回从最外层到最内层的所有激活状态
+func (m *Machine[S, E]) Path() []S {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	var path []S
+	for s := m.current; s != nil; s = s.parent {
+		path = append([]S{s.name}, path...)
+	}
+	return path
+}
+
+// Is 判断 state 是否处于激活状态，即当前状态本身或其父状态
+func (m *Machine[S, E]) Is(state S) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	s, ok := m.def.states[state]
+	return ok && s.ancestorOf(m.current)
+}
+
+// Can 判断当前状态下 event 能否触发转换，会执行守卫条件
+func (m *Machine[S, E]) Can(event E) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	t, _ := m.findLocked(event, nil)
+	return t != nil
+}
+
+// Fire 触发事件：从当前状态开始逐层向父状态查找该事件的转换，执行第一个守卫满足的转换
+// 动作中通过 Context.Fire 触发的后续事件也在返回前处理完，它们的错误一并返回
+func (m *Machine[S, E]) Fire(event E, payload any) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.closed {
+		return ErrClosed
+	}
+	err := m.fireLocked(event, payload)
+	return errors.Join(err, m.drainLocked())
+}
+
+// Close 关闭状态机并取消所有超时计时，之后 Fire 返回 ErrClosed；不执行退出动作
+func (m *Machine[S, E]) Close() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.closed = true
+	for s, t := range m.timers {
+		t.Stop()
+		delete(m.timers, s)
+	}
+}
+
+// DOT 导出状态机的 Graphviz 图，当前激活的状态被填充颜色
+func (m *Machine[S, E]) DOT() string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	active := make(map[*stateDef[S, E]]bool)
+	for s := m.current; s != nil; s = s.parent {
+		active[s] = true
+	}
+	return m.def.dot(active)
+}
+
+// drainLocked 依次处理动作中触发的后续事件
+func (m *Machine[S, E]) drainLocked() error {
+	var errs []error
+	for len(m.deferred) > 0 && !m.closed {
+		next := m.deferred[0]
+		m.deferred = m.deferred[1:]
+		errs = append(errs, m.fireLocked(next.event, next.payload))
+	}
+	m.deferred = nil
+	return errors.Join(errs...)
+}
+
+// fireLocked 查找并执行一个事件的转换
+func (m *Machine[S, E]) fireLocked(event E, payload any) error {
+	t, ctx := m.findLocked(event, payload)
+	if t == nil {
+		if ctx != nil {
+			return fmt.Errorf("%w: 状态 %v 收到事件 %v", ErrGuardRejected, m.current.name, event)
+		}
+		return fmt.Errorf("%w: 状态 %v 收到事件 %v", ErrNoTransition, m.current.name, event)
+	}
+	m.transitionLocked(t, ctx)
+	return nil
+}
+
+// findLocked 从当前状态逐层向上查找守卫满足的转换
+// 没有找到时，如果存在被守卫拒绝的转换，返回的 ctx 不为 nil
+func (m *Machine[S, E]) findLocked(event E, payload any) (*transitionDef[S, E], *Context[S, E]) {
+	var rejected *Context[S, E]
+	for s := m.current; s != nil; s = s.parent {
+		for _, t := range m.def.index[s.name][event] {
+			ctx := &Context[S, E]{From: m.current.name, To: t.to.name, Event: event, Payload: payload, machine: m}
+			if t.guard == nil || t.guard(ctx) {
+				return t, ctx
+			}
+			rejected = ctx
+		}
+	}
+	return nil, rejected
+}
+
+// transitionLocked 执行转换：退出到源状态与目标状态的最近公共祖先，执行转换动作，再进入目标状态
+//
+// 转换总是外部转换：即使目标就是源状态本身，也会先退出再重新进入，超时随之重新计时
+func (m *Machine[S, E]) transitionLocked(t *transitionDef[S, E], ctx *Context[S, E]) {
+	// 公共祖先是源状态的真祖先中，最内层的那个同时也是目标状态本身或其祖先的状态；nil 表示最外层
+	var lca *stateDef[S, E]
+	for a := t.from.parent; a != nil; a = a.parent {
+		if a.ancestorOf(t.to) {
+			lca = a
+			break
+		}
+	}
+	// 目标是源状态的祖先时 lca 就是目标本身：目标保持激活，只重新进入它的初始子状态
+	for s := m.current; s != lca; s = s.parent {
+		m.exitLocked(s, ctx)
+	}
+
+	for _, action := range t.actions {
+		action(ctx)
+	}
+
+	var path []*stateDef[S, E]
+	for s := t.to; s != lca; s = s.parent {
+		path = append(path, s)
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		m.enterLocked(path[i], ctx)
+	}
+	m.current = m.descendLocked(t.to, ctx)
+}
+
+// descendLocked 从复合状态逐层进入初始子状态，返回最内层的状态
+func (m *Machine[S, E]) descendLocked(s *stateDef[S, E], ctx *Context[S, E]) *stateDef[S, E] {
+	for s.initial != nil {
+		s = s.initial
+		m.enterLocked(s, ctx)
+	}
+	return s
+}
+
+// enterLocked 进入状态：执行进入动作并开始超时计时
+func (m *Machine[S, E]) enterLocked(s *stateDef[S, E], ctx *Context[S, E]) {
+	m.epochs[s]++
+	if s.timeout > 0 && !m.closed {
+		epoch := m.epochs[s]
+		m.timers[s] = time.AfterFunc(s.timeout, func() { m.onTimeout(s, epoch) })
+	}
+	for _, action := range s.onEnter {
+		action(ctx)
+	}
+}
+
+// exitLocked 退出状态：取消超时计时并执行退出动作
+func (m *Machine[S, E]) exitLocked(s *stateDef[S, E], ctx *Context[S, E]) {
+	if t, ok := m.timers[s]; ok {
+		t.Stop()
+		delete(m.timers, s)
+	}
+	m.epochs[s]++
+	for _, action := range s.onExit {
+		action(ctx)
+	}
+}
+
+// onTimeout 超时计时到期；计时器可能在状态退出前已经触发，用 epoch 判断是否仍然有效
+func (m *Machine[S, E]) onTimeout(s *stateDef[S, E], epoch uint64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.closed || m.epochs[s] != epoch {
+		return
+	}
+	delete(m.timers, s)
+	err := errors.Join(m.fireLocked(s.timeoutEvent, nil), m.drainLocked())
+	if err != nil && m.config.errorHandler != nil {
+		m.config.errorHandler(fmt.Errorf("状态 %v 超时: %w", s.name, err))
+	}
+}
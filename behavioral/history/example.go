@@ -0,0 +1,84 @@
+package history
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Lines 示例中编辑的文本，按行保存
+type Lines []string
+
+// DeepClone 复制切片，快照与当前文本互不影响
+func (l Lines) DeepClone() Lines {
+	return append(Lines(nil), l...)
+}
+
+// typing 在最后一行末尾输入文字的增量，连续输入可以合并
+type typing struct {
+	text string
+}
+
+func (d typing) Apply(l Lines) (Lines, error) {
+	if len(l) == 0 {
+		return nil, fmt.Errorf("文本为空，无法输入")
+	}
+	l[len(l)-1] += d.text
+	return l, nil
+}
+
+func (d typing) Revert(l Lines) (Lines, error) {
+	last := len(l) - 1
+	if last < 0 || !strings.HasSuffix(l[last], d.text) {
+		return nil, fmt.Errorf("最后一行不以 %q 结尾", d.text)
+	}
+	l[last] = strings.TrimSuffix(l[last], d.text)
+	return l, nil
+}
+
+// Merge 连续的输入合并为一次
+func (d typing) Merge(next Diff[Lines]) (Diff[Lines], bool) {
+	n, ok := next.(typing)
+	if !ok {
+		return nil, false
+	}
+	return typing{text: d.text + n.text}, true
+}
+
+// RunExample 运行历史管理器示例：逐字输入记录为增量，整体替换记录为快照
+func RunExample() {
+	h := New(Lines{""}, WithCapacity(20))
+	show := func(action string, l Lines, err error) {
+		if err != nil {
+			fmt.Printf("%-6s 失败: %v\n", action, err)
+			return
+		}
+		fmt.Printf("%-6s %q\n", action, []string(l))
+	}
+
+	for _, word := range []string{"你好", "，", "世界"} {
+		l, err := h.Apply("输入 "+word, typing{text: word})
+		show("输入", l, err)
+	}
+	h.Push("粘贴全文", Lines{"第一行", "第二行"})
+	show("粘贴", h.Current(), nil)
+
+	l, err := h.Undo()
+	show("撤销", l, err)
+	l, err = h.Undo()
+	show("撤销", l, err)
+	l, err = h.Redo()
+	show("重做", l, err)
+
+	fmt.Print("可撤销: ")
+	for _, e := range h.UndoEntries() {
+		fmt.Printf("[%s] ", e.Label)
+	}
+	fmt.Println()
+
+	// 压缩后三次输入合并为一条记录，一次撤销回到空文本
+	fmt.Println("压缩减少记录数:", h.Compact(0))
+	l, err = h.Undo()
+	show("撤销", l, err)
+	_, err = h.Undo()
+	show("撤销", nil, err)
+}
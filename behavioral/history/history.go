@@ -0,0 +1,328 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// 历史记录相关错误
+var (
+	ErrNothingToUndo = errors.New("没有可撤销的记录")
+	ErrNothingToRedo = errors.New("没有可重做的记录")
+)
+
+// Diff 增量记录：只记录从一个状态到下一个状态的变化，而不是完整状态
+// Apply 用于首次应用和重做，Revert 用于撤销；两者都可以原地修改并返回传入的状态，
+// 但返回错误时不能修改状态
+type Diff[T any] interface {
+	Apply(state T) (T, error)
+	Revert(state T) (T, error)
+}
+
+// Merger 可选接口：增量实现时，Compact 会尝试把它与紧随其后的增量合并为一个
+type Merger[T any] interface {
+	Merge(next Diff[T]) (Diff[T], bool)
+}
+
+// DeepCloner 可选接口：状态实现时，保存快照和恢复快照都会深拷贝，
+// 之后对当前状态的原地修改不会影响历史中的快照
+type DeepCloner[T any] interface {
+	DeepClone() T
+}
+
+// funcDiff 由两个函数组成的增量
+type funcDiff[T any] struct {
+	apply, revert func(T) (T, error)
+}
+
+func (d funcDiff[T]) Apply(state T) (T, error)  { return d.apply(state) }
+func (d funcDiff[T]) Revert(state T) (T, error) { return d.revert(state) }
+
+// NewDiff 用一对函数创建增量
+func NewDiff[T any](apply, revert func(T) (T, error)) Diff[T] {
+	return funcDiff[T]{apply: apply, revert: revert}
+}
+
+// Entry 历史中的一项
+type Entry[T any] struct {
+	Label string  // 显示在撤销/重做菜单中的名称
+	Diff  Diff[T] // 增量记录；快照记录为 nil
+}
+
+// Snapshot 是否为快照记录
+func (e Entry[T]) Snapshot() bool {
+	return e.Diff == nil
+}
+
+// record 内部记录：快照同时保存变化前后的完整状态，撤销和重做都不需要重放其他记录
+type record[T any] struct {
+	label  string
+	diff   Diff[T]
+	before T
+	after  T
+}
+
+// Option 历史管理器配置选项
+type Option func(*config)
+
+// config 历史管理器配置，与状态类型无关
+type config struct {
+	capacity int
+}
+
+// WithCapacity 最多保留的可撤销记录数，超出时丢弃最旧的记录，默认 100，0 表示不限制
+func WithCapacity(n int) Option {
+	return func(c *config) {
+		if n >= 0 {
+			c.capacity = n
+		}
+	}
+}
+
+// HistoryManager 通用的撤销/重做历史
+//
+// 既可以保存完整快照（Push），也可以保存增量（Apply）：快照适合状态小、变化难以描述的场景，
+// 增量适合状态大或者状态保存在外部（例如命令修改的设备）的场景，两种记录可以混用。
+// 撤销之后再记录新的变化会清空重做历史。
+// 方法可以并发调用；增量的 Apply 和 Revert 在锁内执行，不能再调用同一个历史管理器。
+type HistoryManager[T any] struct {
+	config config
+
+	mutex   sync.Mutex
+	current T
+	records []record[T]
+	cursor  int // records[:cursor] 已应用，可以撤销；records[cursor:] 可以重做
+}
+
+// New 以 initial 为初始状态创建历史管理器
+func New[T any](initial T, opts ...Option) *HistoryManager[T] {
+	cfg := config{capacity: 100}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &HistoryManager[T]{config: cfg, current: cloneState(initial)}
+}
+
+// cloneState 状态实现了 DeepCloner 时深拷贝，否则原样返回
+func cloneState[T any](state T) T {
+	if c, ok := any(state).(DeepCloner[T]); ok {
+		return c.DeepClone()
+	}
+	return state
+}
+
+// Current 返回当前状态；状态实现了 DeepCloner 时返回副本
+func (h *HistoryManager[T]) Current() T {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return cloneState(h.current)
+}
+
+// Push 记录一个完整快照，state 成为当前状态
+func (h *HistoryManager[T]) Push(label string, state T) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	rec := record[T]{label: label, after: cloneState(state)}
+	// 上一条也是快照时，它的 after 就是这一条的 before，共用同一份副本
+	if h.cursor > 0 && h.records[h.cursor-1].diff == nil {
+		rec.before = h.records[h.cursor-1].after
+	} else {
+		rec.before = cloneState(h.current)
+	}
+	h.current = cloneState(rec.after)
+	h.appendLocked(rec)
+}
+
+// Apply 在当前状态上应用增量并记录，返回新的当前状态；应用失败时不记录
+func (h *HistoryManager[T]) Apply(label string, diff Diff[T]) (T, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	next, err := diff.Apply(h.current)
+	if err != nil {
+		return cloneState(h.current), err
+	}
+	h.current = next
+	h.appendLocked(record[T]{label: label, diff: diff})
+	return cloneState(h.current), nil
+}
+
+// appendLocked 追加记录，清空重做历史并按容量丢弃最旧的记录，调用者需持有锁
+func (h *HistoryManager[T]) appendLocked(rec record[T]) {
+	clear(h.records[h.cursor:])
+	h.records = append(h.records[:h.cursor], rec)
+	h.cursor++
+
+	if over := len(h.records) - h.config.capacity; h.config.capacity > 0 && over > 0 {
+		h.records = append(h.records[:0:0], h.records[over:]...)
+		h.cursor -= over
+	}
+}
+
+// Undo 撤销最近的一条记录，返回撤销后的状态；撤销失败时历史保持不变
+func (h *HistoryManager[T]) Undo() (T, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.cursor == 0 {
+		return cloneState(h.current), ErrNothingToUndo
+	}
+	rec := h.records[h.cursor-1]
+	if rec.diff == nil {
+		h.current = cloneState(rec.before)
+	} else {
+		prev, err := rec.diff.Revert(h.current)
+		if err != nil {
+			return cloneState(h.current), fmt.Errorf("撤销 %s: %w", rec.label, err)
+		}
+		h.current = prev
+	}
+	h.cursor--
+	return cloneState(h.current), nil
+}
+
+// Redo 重做最近撤销的一条记录，返回重做后的状态；重做失败时历史保持不变
+func (h *HistoryManager[T]) Redo() (T, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.cursor == len(h.records) {
+		return cloneState(h.current), ErrNothingToRedo
+	}
+	rec := h.records[h.cursor]
+	if rec.diff == nil {
+		h.current = cloneState(rec.after)
+	} else {
+		next, err := rec.diff.Apply(h.current)
+		if err != nil {
+			return cloneState(h.current), fmt.Errorf("重做 %s: %w", rec.label, err)
+		}
+		h.current = next
+	}
+	h.cursor++
+	return cloneState(h.current), nil
+}
+
+// PeekUndo 返回下一次 Undo 将撤销的记录
+func (h *HistoryManager[T]) PeekUndo() (Entry[T], bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.cursor == 0 {
+		return Entry[T]{}, false
+	}
+	return h.records[h.cursor-1].entry(), true
+}
+
+// PeekRedo 返回下一次 Redo 将重做的记录
+func (h *HistoryManager[T]) PeekRedo() (Entry[T], bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.cursor == len(h.records) {
+		return Entry[T]{}, false
+	}
+	return h.records[h.cursor].entry(), true
+}
+
+// entry 转换为对外的记录
+func (r record[T]) entry() Entry[T] {
+	return Entry[T]{Label: r.label, Diff: r.diff}
+}
+
+// UndoEntries 返回所有可撤销的记录，从旧到新
+func (h *HistoryManager[T]) UndoEntries() []Entry[T] {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return entries(h.records[:h.cursor])
+}
+
+// RedoEntries 返回所有可重做的记录，按重做的顺序
+func (h *HistoryManager[T]) RedoEntries() []Entry[T] {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return entries(h.records[h.cursor:])
+}
+
+// entries 转换为对外的记录列表
+func entries[T any](records []record[T]) []Entry[T] {
+	result := make([]Entry[T], len(records))
+	for i, rec := range records {
+		result[i] = rec.entry()
+	}
+	return result
+}
+
+// CanUndo 是否有可撤销的记录
+func (h *HistoryManager[T]) CanUndo() bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.cursor > 0
+}
+
+// CanRedo 是否有可重做的记录
+func (h *HistoryManager[T]) CanRedo() bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.cursor < len(h.records)
+}
+
+// Clear 清空所有历史，保留当前状态
+func (h *HistoryManager[T]) Clear() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.records = nil
+	h.cursor = 0
+}
+
+// Compact 压缩较旧的历史：最近 keep 条可撤销记录保持原样，更早的记录中相邻的同类记录合并为一条
+// 相邻的快照总是可以合并，相邻的增量在前一条实现了 Merger 并同意合并时合并；合并后的记录使用后一条的名称
+// 合并会减少撤销的粒度，但撤销到最早状态的结果不变；重做历史不受影响。返回减少的记录数
+func (h *HistoryManager[T]) Compact(keep int) int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	end := max(h.cursor-max(keep, 0), 0)
+	if end < 2 {
+		return 0
+	}
+
+	compacted := make([]record[T], 0, len(h.records))
+	for _, rec := range h.records[:end] {
+		if n := len(compacted); n > 0 {
+			if merged, ok := mergeRecords(compacted[n-1], rec); ok {
+				compacted[n-1] = merged
+				continue
+			}
+		}
+		compacted = append(compacted, rec)
+	}
+
+	removed := end - len(compacted)
+	h.records = append(compacted, h.records[end:]...)
+	h.cursor -= removed
+	return removed
+}
+
+// mergeRecords 尝试把相邻的两条记录合并为一条
+func mergeRecords[T any](prev, next record[T]) (record[T], bool) {
+	switch {
+	case prev.diff == nil && next.diff == nil:
+		return record[T]{label: next.label, before: prev.before, after: next.after}, true
+	case prev.diff != nil && next.diff != nil:
+		m, ok := prev.diff.(Merger[T])
+		if !ok {
+			return record[T]{}, false
+		}
+		diff, ok := m.Merge(next.diff)
+		if !ok {
+			return record[T]{}, false
+		}
+		return record[T]{label: next.label, diff: diff}, true
+	default:
+		return record[T]{}, false
+	}
+}
@@ -0,0 +1,292 @@
+package history
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// counterDiff 给计数器加上 n 的增量，用于测试
+type counterDiff struct {
+	n    int
+	fail bool
+}
+
+func (d counterDiff) Apply(v int) (int, error) {
+	if d.fail {
+		return v, errors.New("应用失败")
+	}
+	return v + d.n, nil
+}
+
+func (d counterDiff) Revert(v int) (int, error) {
+	if d.fail {
+		return v, errors.New("撤销失败")
+	}
+	return v - d.n, nil
+}
+
+func (d counterDiff) Merge(next Diff[int]) (Diff[int], bool) {
+	n, ok := next.(counterDiff)
+	if !ok || d.fail || n.fail {
+		return nil, false
+	}
+	return counterDiff{n: d.n + n.n}, true
+}
+
+func labels[T any](entries []Entry[T]) []string {
+	result := make([]string, len(entries))
+	for i, e := range entries {
+		result[i] = e.Label
+	}
+	return result
+}
+
+// TestSnapshotUndoRedo 测试快照的撤销和重做
+func TestSnapshotUndoRedo(t *testing.T) {
+	h := New("")
+	_, err := h.Undo()
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+
+	h.Push("a", "A")
+	h.Push("b", "AB")
+	h.Push("c", "ABC")
+	assert.Equal(t, "ABC", h.Current())
+
+	v, err := h.Undo()
+	assert.NoError(t, err)
+	assert.Equal(t, "AB", v)
+	v, _ = h.Undo()
+	assert.Equal(t, "A", v)
+	assert.Equal(t, []string{"a"}, labels(h.UndoEntries()))
+	assert.Equal(t, []string{"b", "c"}, labels(h.RedoEntries()))
+
+	v, err = h.Redo()
+	assert.NoError(t, err)
+	assert.Equal(t, "AB", v)
+
+	// 撤销之后记录新的变化会清空重做历史
+	h.Push("x", "ABX")
+	assert.False(t, h.CanRedo())
+	_, err = h.Redo()
+	assert.ErrorIs(t, err, ErrNothingToRedo)
+	assert.Equal(t, []string{"a", "b", "x"}, labels(h.UndoEntries()))
+
+	for h.CanUndo() {
+		_, err := h.Undo()
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, "", h.Current())
+}
+
+// TestDiffUndoRedo 测试增量与快照混用
+func TestDiffUndoRedo(t *testing.T) {
+	h := New(10)
+	v, err := h.Apply("+5", counterDiff{n: 5})
+	assert.NoError(t, err)
+	assert.Equal(t, 15, v)
+	h.Push("设为 100", 100)
+	v, _ = h.Apply("-1", counterDiff{n: -1})
+	assert.Equal(t, 99, v)
+
+	e, ok := h.PeekUndo()
+	assert.True(t, ok)
+	assert.Equal(t, "-1", e.Label)
+	assert.False(t, e.Snapshot())
+
+	v, _ = h.Undo()
+	assert.Equal(t, 100, v)
+	e, _ = h.PeekUndo()
+	assert.True(t, e.Snapshot())
+	v, _ = h.Undo()
+	assert.Equal(t, 15, v)
+	v, _ = h.Undo()
+	assert.Equal(t, 10, v)
+
+	e, ok = h.PeekRedo()
+	assert.True(t, ok)
+	assert.Equal(t, "+5", e.Label)
+	for i, want := range []int{15, 100, 99} {
+		v, err := h.Redo()
+		assert.NoError(t, err, i)
+		assert.Equal(t, want, v)
+	}
+	_, ok = h.PeekRedo()
+	assert.False(t, ok)
+}
+
+// TestDiffFailures 测试应用失败不记录，撤销和重做失败时历史保持不变
+func TestDiffFailures(t *testing.T) {
+	h := New(0)
+	v, err := h.Apply("失败", counterDiff{fail: true})
+	assert.Error(t, err)
+	assert.Equal(t, 0, v)
+	assert.False(t, h.CanUndo())
+
+	failing := true
+	d := NewDiff(
+		func(v int) (int, error) { return v + 1, nil },
+		func(v int) (int, error) {
+			if failing {
+				return v, errors.New("设备离线")
+			}
+			return v - 1, nil
+		},
+	)
+	h.Apply("+1", d)
+	_, err = h.Undo()
+	assert.ErrorContains(t, err, "撤销 +1")
+	assert.ErrorContains(t, err, "设备离线")
+	assert.True(t, h.CanUndo(), "失败的撤销保留记录")
+	assert.Equal(t, 1, h.Current())
+
+	failing = false
+	v, err = h.Undo()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, v)
+}
+
+// TestCapacity 测试超出容量时丢弃最旧的记录
+func TestCapacity(t *testing.T) {
+	h := New(0, WithCapacity(3))
+	for i := 1; i <= 5; i++ {
+		h.Push("", i)
+	}
+	assert.Len(t, h.UndoEntries(), 3)
+	for h.CanUndo() {
+		h.Undo()
+	}
+	assert.Equal(t, 2, h.Current(), "只能撤销到最早保留的记录之前的状态")
+	assert.Len(t, h.RedoEntries(), 3)
+
+	unlimited := New(0, WithCapacity(0))
+	for i := 0; i < 500; i++ {
+		unlimited.Apply("", counterDiff{n: 1})
+	}
+	assert.Len(t, unlimited.UndoEntries(), 500)
+
+	h.Clear()
+	assert.False(t, h.CanUndo())
+	assert.False(t, h.CanRedo())
+	assert.Equal(t, 2, h.Current())
+}
+
+// TestCompact 测试压缩合并较旧的同类记录，保留最近的记录和重做历史
+func TestCompact(t *testing.T) {
+	h := New(0)
+	h.Apply("+1", counterDiff{n: 1})
+	h.Apply("+2", counterDiff{n: 2})
+	h.Apply("+3", counterDiff{n: 3})
+	h.Push("设为 10", 10)
+	h.Push("设为 20", 20)
+	h.Apply("+4", counterDiff{n: 4})
+	h.Apply("+5", counterDiff{n: 5})
+	h.Undo() // +5 进入重做历史
+
+	assert.Equal(t, 0, h.Compact(10), "记录数不超过 keep 时不压缩")
+	assert.Equal(t, 3, h.Compact(1))
+	assert.Equal(t, []string{"+3", "设为 20", "+4"}, labels(h.UndoEntries()))
+	assert.Equal(t, []string{"+5"}, labels(h.RedoEntries()))
+
+	v, _ := h.Undo()
+	assert.Equal(t, 20, v)
+	v, _ = h.Undo()
+	assert.Equal(t, 6, v, "合并的快照回到合并前第一条之前的状态")
+	v, _ = h.Undo()
+	assert.Equal(t, 0, v, "合并的增量一次撤销")
+
+	for _, want := range []int{6, 20, 24, 29} {
+		v, err := h.Redo()
+		assert.NoError(t, err)
+		assert.Equal(t, want, v)
+	}
+
+	// 不能合并的增量保持原样
+	h2 := New(0)
+	h2.Apply("a", NewDiff(func(v int) (int, error) { return v + 1, nil }, func(v int) (int, error) { return v - 1, nil }))
+	h2.Apply("b", NewDiff(func(v int) (int, error) { return v + 1, nil }, func(v int) (int, error) { return v - 1, nil }))
+	assert.Equal(t, 0, h2.Compact(0))
+}
+
+// cell 可深拷贝的状态，用于测试快照与当前状态互不影响
+type cell struct {
+	values []int
+}
+
+func (c *cell) DeepClone() *cell {
+	return &cell{values: append([]int(nil), c.values...)}
+}
+
+// TestDeepClone 测试实现了 DeepCloner 的状态在保存和返回时都会复制
+func TestDeepClone(t *testing.T) {
+	initial := &cell{values: []int{1}}
+	h := New(initial)
+	initial.values[0] = 100
+	assert.Equal(t, []int{1}, h.Current().values, "外部修改不影响初始状态")
+
+	next := &cell{values: []int{1, 2}}
+	h.Push("追加", next)
+	next.values[0] = 100
+	h.Current().values[0] = 100
+	assert.Equal(t, []int{1, 2}, h.Current().values, "外部修改不影响快照和当前状态")
+
+	appendThree := NewDiff(
+		func(c *cell) (*cell, error) { c.values = append(c.values, 3); return c, nil },
+		func(c *cell) (*cell, error) { c.values = c.values[:len(c.values)-1]; return c, nil },
+	)
+	h.Apply("追加 3", appendThree)
+	h.Push("清空", &cell{})
+
+	v, _ := h.Undo()
+	assert.Equal(t, []int{1, 2, 3}, v.values)
+	v, _ = h.Undo()
+	assert.Equal(t, []int{1, 2}, v.values)
+	v, _ = h.Undo()
+	assert.Equal(t, []int{1}, v.values, "原地修改当前状态的增量不影响快照")
+}
+
+// TestConcurrentUse 测试并发记录和撤销
+func TestConcurrentUse(t *testing.T) {
+	h := New(0, WithCapacity(0))
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				h.Apply("+1", counterDiff{n: 1})
+				if j%2 == 0 {
+					h.Undo()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 400, h.Current())
+	assert.Len(t, h.UndoEntries(), 400)
+}
+
+// BenchmarkSnapshotVsDiff 比较快照与增量记录一个大状态的开销
+func BenchmarkSnapshotVsDiff(b *testing.B) {
+	big := make(Lines, 1000)
+	b.Run("Snapshot", func(b *testing.B) {
+		h := New(big, WithCapacity(50))
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			next := h.Current()
+			next[len(next)-1] += "x"
+			h.Push("", next)
+		}
+	})
+	b.Run("Diff", func(b *testing.B) {
+		h := New(big, WithCapacity(50))
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			h.Apply("", typing{text: "x"})
+		}
+	})
+}
@@ -0,0 +1,224 @@
+package interpreter
+
+import "fmt"
+
+// BitAndExpression 表示按位与表达式
+type BitAndExpression struct {
+	left  Expression
+	right Expression
+}
+
+// NewBitAndExpression 创建一个按位与表达式
+func NewBitAndExpression(left, right Expression) *BitAndExpression {
+	return &BitAndExpression{left: left, right: right}
+}
+
+// Interpret 实现Expression接口，对左右表达式进行按位与操作
+func (b *BitAndExpression) Interpret(context *Context) (int, error) {
+	return traced(context, b, func() (int, error) {
+		leftValue, rightValue, err := interpretOperands(context, b.left, b.right)
+		if err != nil {
+			return 0, err
+		}
+		return leftValue & rightValue, nil
+	})
+}
+
+// String 返回按位与表达式的字符串表示
+func (b *BitAndExpression) String() string {
+	return fmt.Sprintf("(%s & %s)", b.left.String(), b.right.String())
+}
+
+// BitOrExpression 表示按位或表达式
+type BitOrExpression struct {
+	left  Expression
+	right Expression
+}
+
+// NewBitOrExpression 创建一个按位或表达式
+func NewBitOrExpression(left, right Expression) *BitOrExpression {
+	return &BitOrExpression{left: left, right: right}
+}
+
+// Interpret 实现Expression接口，对左右表达式进行按位或操作
+func (b *BitOrExpression) Interpret(context *Context) (int, error) {
+	return traced(context, b, func() (int, error) {
+		leftValue, rightValue, err := interpretOperands(context, b.left, b.right)
+		if err != nil {
+			return 0, err
+		}
+		return leftValue | rightValue, nil
+	})
+}
+
+// String 返回按位或表达式的字符串表示
+func (b *BitOrExpression) String() string {
+	return fmt.Sprintf("(%s | %s)", b.left.String(), b.right.String())
+}
+
+// BitXorExpression 表示按位异或表达式
+type BitXorExpression struct {
+	left  Expression
+	right Expression
+}
+
+// NewBitXorExpression 创建一个按位异或表达式
+func NewBitXorExpression(left, right Expression) *BitXorExpression {
+	return &BitXorExpression{left: left, right: right}
+}
+
+// Interpret 实现Expression接口，对左右表达式进行按位异或操作
+func (b *BitXorExpression) Interpret(context *Context) (int, error) {
+	return traced(context, b, func() (int, error) {
+		leftValue, rightValue, err := interpretOperands(context, b.left, b.right)
+		if err != nil {
+			return 0, err
+		}
+		return leftValue ^ rightValue, nil
+	})
+}
+
+// String 返回按位异或表达式的字符串表示
+func (b *BitXorExpression) String() string {
+	return fmt.Sprintf("(%s ^ %s)", b.left.String(), b.right.String())
+}
+
+// ShiftLeftExpression 表示左移表达式
+type ShiftLeftExpression struct {
+	left  Expression
+	right Expression
+}
+
+// NewShiftLeftExpression 创建一个左移表达式
+func NewShiftLeftExpression(left, right Expression) *ShiftLeftExpression {
+	return &ShiftLeftExpression{left: left, right: right}
+}
+
+// Interpret 实现Expression接口，将左表达式的值左移右表达式的值位
+func (s *ShiftLeftExpression) Interpret(context *Context) (int, error) {
+	return traced(context, s, func() (int, error) {
+		leftValue, rightValue, err := interpretOperands(context, s.left, s.right)
+		if err != nil {
+			return 0, err
+		}
+		if rightValue < 0 {
+			return 0, fmt.Errorf("移位位数不能为负数: %d", rightValue)
+		}
+		return leftValue << rightValue, nil
+	})
+}
+
+// String 返回左移表达式的字符串表示
+func (s *ShiftLeftExpression) String() string {
+	return fmt.Sprintf("(%s << %s)", s.left.String(), s.right.String())
+}
+
+// ShiftRightExpression 表示右移表达式（算术右移，保留符号）
+type ShiftRightExpression struct {
+	left  Expression
+	right Expression
+}
+
+// NewShiftRightExpression 创建一个右移表达式
+func NewShiftRightExpression(left, right Expression) *ShiftRightExpression {
+	return &ShiftRightExpression{left: left, right: right}
+}
+
+// Interpret 实现Expression接口，将左表达式的值右移右表达式的值位
+func (s *ShiftRightExpression) Interpret(context *Context) (int, error) {
+	return traced(context, s, func() (int, error) {
+		leftValue, rightValue, err := interpretOperands(context, s.left, s.right)
+		if err != nil {
+			return 0, err
+		}
+		if rightValue < 0 {
+			return 0, fmt.Errorf("移位位数不能为负数: %d", rightValue)
+		}
+		return leftValue >> rightValue, nil
+	})
+}
+
+// String 返回右移表达式的字符串表示
+func (s *ShiftRightExpression) String() string {
+	return fmt.Sprintf("(%s >> %s)", s.left.String(), s.right.String())
+}
+
+// interpretOperands 依次求值二元表达式的左右操作数
+func interpretOperands(context *Context, left, right Expression) (int, int, error) {
+	leftValue, err := left.Interpret(context)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	rightValue, err := right.Interpret(context)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return leftValue, rightValue, nil
+}
+
+// binaryConstructor 根据左右操作数创建二元表达式
+type binaryConstructor func(left, right Expression) Expression
+
+// parseExpression 解析完整的表达式
+// 优先级从低到高依次为：按位或 |、按位异或 ^、按位与 &、移位 << >>、加减、乘除模，与 C 语言一致
+func (p *Parser) parseExpression() (Expression, error) {
+	return p.parseBitOr()
+}
+
+// parseBitOr 解析按位或表达式
+func (p *Parser) parseBitOr() (Expression, error) {
+	return p.parseBinary(p.parseBitXor, map[string]binaryConstructor{
+		"|": func(left, right Expression) Expression { return NewBitOrExpression(left, right) },
+	})
+}
+
+// parseBitXor 解析按位异或表达式
+func (p *Parser) parseBitXor() (Expression, error) {
+	return p.parseBinary(p.parseBitAnd, map[string]binaryConstructor{
+		"^": func(left, right Expression) Expression { return NewBitXorExpression(left, right) },
+	})
+}
+
+// parseBitAnd 解析按位与表达式
+func (p *Parser) parseBitAnd() (Expression, error) {
+	return p.parseBinary(p.parseShift, map[string]binaryConstructor{
+		"&": func(left, right Expression) Expression { return NewBitAndExpression(left, right) },
+	})
+}
+
+// parseShift 解析移位表达式
+func (p *Parser) parseShift() (Expression, error) {
+	return p.parseBinary(p.parseAdditive, map[string]binaryConstructor{
+		"<<": func(left, right Expression) Expression { return NewShiftLeftExpression(left, right) },
+		">>": func(left, right Expression) Expression { return NewShiftRightExpression(left, right) },
+	})
+}
+
+// parseBinary 解析同一优先级的左结合二元运算，next 负责解析更高一级的操作数
+func (p *Parser) parseBinary(next func() (Expression, error), operators map[string]binaryConstructor) (Expression, error) {
+	left, err := next()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.pos < len(p.tokens) {
+		build, ok := operators[p.tokens[p.pos]]
+		if !ok {
+			break
+		}
+		p.pos++
+		right, err := next()
+		if err != nil {
+			return nil, err
+		}
+		node := build(left, right)
+		if err := p.trackDepth(node, left, right); err != nil {
+			return nil, err
+		}
+		left = node
+	}
+
+	return left, nil
+}
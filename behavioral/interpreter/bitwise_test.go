@@ -0,0 +1,124 @@
+package interpreter
+
+import "testing"
+
+// 位运算与移位测试
+func TestBitwiseOperators(t *testing.T) {
+	context := NewContext()
+	context.SetVariable("flags", 13) // 0b1101
+	context.SetVariable("mask", 6)   // 0b0110
+
+	tests := []struct {
+		expression string
+		expected   int
+		hasError   bool
+	}{
+		{"flags & mask", 4, false},
+		{"flags | mask", 15, false},
+		{"flags ^ mask", 11, false},
+		{"1 << 4", 16, false},
+		{"flags >> 2", 3, false},
+		{"(flags & 4) >> 2", 1, false},
+		{"(flags & 2) >> 1", 0, false},
+		{"0 - 8 >> 1", -4, false}, // 算术右移保留符号
+		{"1 << 0 - 1", 0, true},   // 移位位数为负
+		{"8 >> 0 - 1", 0, true},
+		{"flags & z", 0, true}, // 未定义变量
+	}
+
+	for _, test := range tests {
+		result, err := Evaluate(test.expression, context)
+
+		if test.hasError {
+			if err == nil {
+				t.Errorf("表达式 %s 应该返回错误", test.expression)
+			}
+		} else {
+			if err != nil {
+				t.Errorf("表达式 %s 出错: %v", test.expression, err)
+			} else if result != test.expected {
+				t.Errorf("表达式 %s 结果应为 %d，实际为 %d", test.expression, test.expected, result)
+			}
+		}
+	}
+}
+
+// 位运算与算术运算的优先级测试
+func TestBitwisePrecedence(t *testing.T) {
+	context := NewContext()
+
+	tests := []struct {
+		expression string
+		expected   int
+		tree       string
+	}{
+		// 算术运算优先于移位
+		{"1 << 2 + 1", 8, "(1 << (2 + 1))"},
+		{"16 >> 1 * 2", 4, "(16 >> (1 * 2))"},
+		// 移位优先于按位与
+		{"12 & 1 << 2", 4, "(12 & (1 << 2))"},
+		// 按位与优先于异或，异或优先于按位或
+		{"1 | 6 ^ 3 & 5", 7, "(1 | (6 ^ (3 & 5)))"},
+		{"6 ^ 3 | 8", 13, "((6 ^ 3) | 8)"},
+		// 按位与低于加减
+		{"5 & 3 + 4", 5, "(5 & (3 + 4))"},
+		// 同级左结合
+		{"64 >> 2 >> 1", 8, "((64 >> 2) >> 1)"},
+		{"1 << 3 >> 1", 4, "((1 << 3) >> 1)"},
+		// 括号改变优先级
+		{"(5 & 3) + 4", 5, "((5 & 3) + 4)"},
+		{"(1 << 2) + 1", 5, "((1 << 2) + 1)"},
+	}
+
+	for _, test := range tests {
+		parser := NewParser(context)
+		expr, err := parser.Parse(test.expression)
+		if err != nil {
+			t.Errorf("表达式 %s 解析出错: %v", test.expression, err)
+			continue
+		}
+		if expr.String() != test.tree {
+			t.Errorf("表达式 %s 的语法树应为 %s，实际为 %s", test.expression, test.tree, expr.String())
+		}
+
+		result, err := expr.Interpret(context)
+		if err != nil {
+			t.Errorf("表达式 %s 出错: %v", test.expression, err)
+		} else if result != test.expected {
+			t.Errorf("表达式 %s 结果应为 %d，实际为 %d", test.expression, test.expected, result)
+		}
+	}
+}
+
+// 手动构建位运算表达式树测试
+func TestBitwiseExpressionTree(t *testing.T) {
+	context := NewContext()
+	context.SetVariable("flags", 10)
+
+	// 构建 (flags >> 1) & 1
+	expr := NewBitAndExpression(
+		NewShiftRightExpression(NewVariableExpression("flags"), NewNumberExpression(1)),
+		NewNumberExpression(1),
+	)
+
+	result, err := expr.Interpret(context)
+	if err != nil {
+		t.Fatalf("表达式求值出错: %v", err)
+	}
+	if result != 1 {
+		t.Errorf("结果应为 1，实际为 %d", result)
+	}
+	if expr.String() != "((flags >> 1) & 1)" {
+		t.Errorf("字符串表示不正确: %s", expr.String())
+	}
+
+	// 位运算节点同样会被跟踪
+	recorder := NewTraceRecorder()
+	context.SetTracer(recorder)
+	if _, err := expr.Interpret(context); err != nil {
+		t.Fatalf("表达式求值出错: %v", err)
+	}
+	if len(recorder.Events()) != 10 {
+		t.Errorf("应记录 10 个跟踪事件，实际为 %d", len(recorder.Events()))
+	}
+}
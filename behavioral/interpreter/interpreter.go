@@ -8,8 +8,14 @@ import (
 )
 
 // Context 上下文环境，用于存储变量和其对应的值
+// 上下文可以嵌套形成作用域链，查找变量时由内向外逐级查找
 type Context struct {
 	variables map[string]int
+	parent    *Context
+	tracer    EvalTracer
+	memo      *Memo      // 当前作用域的求值缓存
+	watchers  []*Memo    // 子作用域的求值缓存，本作用域的变量修改时需要通知它们
+	guard     *evalGuard // 受限求值的步数和时间限制
 }
 
 // NewContext 创建一个新的上下文环境
@@ -22,12 +28,17 @@ func NewContext() *Context {
 // SetVariable 设置变量值
 func (c *Context) SetVariable(name string, value int) {
 	c.variables[name] = value
+	c.invalidate(name)
 }
 
-// GetVariable 获取变量值
+// GetVariable 获取变量值，当前作用域不存在时沿父作用域查找
 func (c *Context) GetVariable(name string) (int, bool) {
-	value, exists := c.variables[name]
-	return value, exists
+	for scope := c; scope != nil; scope = scope.parent {
+		if value, exists := scope.variables[name]; exists {
+			return value, true
+		}
+	}
+	return 0, false
 }
 
 // Expression 是解释器接口，定义了解释器的方法
@@ -48,7 +59,9 @@ func NewNumberExpression(value int) *NumberExpression {
 
 // Interpret 实现Expression接口，返回数字值
 func (n *NumberExpression) Interpret(context *Context) (int, error) {
-	return n.value, nil
+	return traced(context, n, func() (int, error) {
+		return n.value, nil
+	})
 }
 
 // String 返回数字表达式的字符串表示
@@ -68,11 +81,13 @@ func NewVariableExpression(name string) *VariableExpression {
 
 // Interpret 实现Expression接口，返回变量的值
 func (v *VariableExpression) Interpret(context *Context) (int, error) {
-	value, exists := context.GetVariable(v.name)
-	if !exists {
-		return 0, fmt.Errorf("变量 '%s' 未定义", v.name)
-	}
-	return value, nil
+	return traced(context, v, func() (int, error) {
+		value, exists := context.GetVariable(v.name)
+		if !exists {
+			return 0, fmt.Errorf("变量 '%s' 未定义", v.name)
+		}
+		return value, nil
+	})
 }
 
 // String 返回变量表达式的字符串表示
@@ -93,17 +108,19 @@ func NewAddExpression(left, right Expression) *AddExpression {
 
 // Interpret 实现Expression接口，对左右表达式进行相加操作
 func (a *AddExpression) Interpret(context *Context) (int, error) {
-	leftValue, err := a.left.Interpret(context)
-	if err != nil {
-		return 0, err
-	}
+	return traced(context, a, func() (int, error) {
+		leftValue, err := a.left.Interpret(context)
+		if err != nil {
+			return 0, err
+		}
 
-	rightValue, err := a.right.Interpret(context)
-	if err != nil {
-		return 0, err
-	}
+		rightValue, err := a.right.Interpret(context)
+		if err != nil {
+			return 0, err
+		}
 
-	return leftValue + rightValue, nil
+		return leftValue + rightValue, nil
+	})
 }
 
 // String 返回加法表达式的字符串表示
@@ -124,17 +141,19 @@ func NewSubtractExpression(left, right Expression) *SubtractExpression {
 
 // Interpret 实现Expression接口，对左右表达式进行相减操作
 func (s *SubtractExpression) Interpret(context *Context) (int, error) {
-	leftValue, err := s.left.Interpret(context)
-	if err != nil {
-		return 0, err
-	}
+	return traced(context, s, func() (int, error) {
+		leftValue, err := s.left.Interpret(context)
+		if err != nil {
+			return 0, err
+		}
 
-	rightValue, err := s.right.Interpret(context)
-	if err != nil {
-		return 0, err
-	}
+		rightValue, err := s.right.Interpret(context)
+		if err != nil {
+			return 0, err
+		}
 
-	return leftValue - rightValue, nil
+		return leftValue - rightValue, nil
+	})
 }
 
 // String 返回减法表达式的字符串表示
@@ -155,17 +174,19 @@ func NewMultiplyExpression(left, right Expression) *MultiplyExpression {
 
 // Interpret 实现Expression接口，对左右表达式进行相乘操作
 func (m *MultiplyExpression) Interpret(context *Context) (int, error) {
-	leftValue, err := m.left.Interpret(context)
-	if err != nil {
-		return 0, err
-	}
+	return traced(context, m, func() (int, error) {
+		leftValue, err := m.left.Interpret(context)
+		if err != nil {
+			return 0, err
+		}
 
-	rightValue, err := m.right.Interpret(context)
-	if err != nil {
-		return 0, err
-	}
+		rightValue, err := m.right.Interpret(context)
+		if err != nil {
+			return 0, err
+		}
 
-	return leftValue * rightValue, nil
+		return leftValue * rightValue, nil
+	})
 }
 
 // String 返回乘法表达式的字符串表示
@@ -186,21 +207,23 @@ func NewDivideExpression(left, right Expression) *DivideExpression {
 
 // Interpret 实现Expression接口，对左右表达式进行相除操作
 func (d *DivideExpression) Interpret(context *Context) (int, error) {
-	leftValue, err := d.left.Interpret(context)
-	if err != nil {
-		return 0, err
-	}
+	return traced(context, d, func() (int, error) {
+		leftValue, err := d.left.Interpret(context)
+		if err != nil {
+			return 0, err
+		}
 
-	rightValue, err := d.right.Interpret(context)
-	if err != nil {
-		return 0, err
-	}
+		rightValue, err := d.right.Interpret(context)
+		if err != nil {
+			return 0, err
+		}
 
-	if rightValue == 0 {
-		return 0, fmt.Errorf("除数不能为零")
-	}
+		if rightValue == 0 {
+			return 0, fmt.Errorf("除数不能为零")
+		}
 
-	return leftValue / rightValue, nil
+		return leftValue / rightValue, nil
+	})
 }
 
 // String 返回除法表达式的字符串表示
@@ -221,21 +244,23 @@ func NewModuloExpression(left, right Expression) *ModuloExpression {
 
 // Interpret 实现Expression接口，对左右表达式进行取模操作
 func (m *ModuloExpression) Interpret(context *Context) (int, error) {
-	leftValue, err := m.left.Interpret(context)
-	if err != nil {
-		return 0, err
-	}
+	return traced(context, m, func() (int, error) {
+		leftValue, err := m.left.Interpret(context)
+		if err != nil {
+			return 0, err
+		}
 
-	rightValue, err := m.right.Interpret(context)
-	if err != nil {
-		return 0, err
-	}
+		rightValue, err := m.right.Interpret(context)
+		if err != nil {
+			return 0, err
+		}
 
-	if rightValue == 0 {
-		return 0, fmt.Errorf("模数不能为零")
-	}
+		if rightValue == 0 {
+			return 0, fmt.Errorf("模数不能为零")
+		}
 
-	return leftValue % rightValue, nil
+		return leftValue % rightValue, nil
+	})
 }
 
 // String 返回取模表达式的字符串表示
@@ -248,6 +273,10 @@ type Parser struct {
 	context *Context
 	tokens  []string
 	pos     int
+
+	limits  Limits
+	depths  map[Expression]int // 已解析节点的深度，只在限制了深度时记录
+	nesting int                // 当前的括号嵌套层数
 }
 
 // NewParser 创建一个新的解析器
@@ -261,6 +290,10 @@ func NewParser(context *Context) *Parser {
 
 // Parse 解析表达式字符串并构建表达式树
 func (p *Parser) Parse(expression string) (Expression, error) {
+	if err := p.beginParse(expression); err != nil {
+		return nil, err
+	}
+
 	// 词法分析，将表达式字符串拆分为标记
 	p.tokenize(expression)
 	p.pos = 0
@@ -302,8 +335,16 @@ func (p *Parser) tokenize(expression string) {
 			continue
 		}
 
+		// 处理移位运算符
+		if (char == '<' || char == '>') && i+1 < len(expression) && expression[i+1] == char {
+			p.tokens = append(p.tokens, expression[i:i+2])
+			i += 2
+			continue
+		}
+
 		// 处理运算符
-		if char == '+' || char == '-' || char == '*' || char == '/' || char == '%' || char == '(' || char == ')' {
+		if char == '&' || char == '|' || char == '^' ||
+			char == '+' || char == '-' || char == '*' || char == '/' || char == '%' || char == '(' || char == ')' {
 			p.tokens = append(p.tokens, string(char))
 			i++
 			continue
@@ -314,75 +355,21 @@ func (p *Parser) tokenize(expression string) {
 	}
 }
 
-// parseExpression 解析加减表达式
-func (p *Parser) parseExpression() (Expression, error) {
-	left, err := p.parseTerm()
-	if err != nil {
-		return nil, err
-	}
-
-	for p.pos < len(p.tokens) {
-		token := p.tokens[p.pos]
-
-		if token == "+" {
-			p.pos++
-			right, err := p.parseTerm()
-			if err != nil {
-				return nil, err
-			}
-			left = NewAddExpression(left, right)
-		} else if token == "-" {
-			p.pos++
-			right, err := p.parseTerm()
-			if err != nil {
-				return nil, err
-			}
-			left = NewSubtractExpression(left, right)
-		} else {
-			break
-		}
-	}
-
-	return left, nil
+// parseAdditive 解析加减表达式
+func (p *Parser) parseAdditive() (Expression, error) {
+	return p.parseBinary(p.parseTerm, map[string]binaryConstructor{
+		"+": func(left, right Expression) Expression { return NewAddExpression(left, right) },
+		"-": func(left, right Expression) Expression { return NewSubtractExpression(left, right) },
+	})
 }
 
 // parseTerm 解析乘除模表达式
 func (p *Parser) parseTerm() (Expression, error) {
-	left, err := p.parseFactor()
-	if err != nil {
-		return nil, err
-	}
-
-	for p.pos < len(p.tokens) {
-		token := p.tokens[p.pos]
-
-		if token == "*" {
-			p.pos++
-			right, err := p.parseFactor()
-			if err != nil {
-				return nil, err
-			}
-			left = NewMultiplyExpression(left, right)
-		} else if token == "/" {
-			p.pos++
-			right, err := p.parseFactor()
-			if err != nil {
-				return nil, err
-			}
-			left = NewDivideExpression(left, right)
-		} else if token == "%" {
-			p.pos++
-			right, err := p.parseFactor()
-			if err != nil {
-				return nil, err
-			}
-			left = NewModuloExpression(left, right)
-		} else {
-			break
-		}
-	}
-
-	return left, nil
+	return p.parseBinary(p.parseFactor, map[string]binaryConstructor{
+		"*": func(left, right Expression) Expression { return NewMultiplyExpression(left, right) },
+		"/": func(left, right Expression) Expression { return NewDivideExpression(left, right) },
+		"%": func(left, right Expression) Expression { return NewModuloExpression(left, right) },
+	})
 }
 
 // parseFactor 解析因子（数字、变量、括号表达式）
@@ -396,6 +383,9 @@ func (p *Parser) parseFactor() (Expression, error) {
 
 	// 处理括号表达式
 	if token == "(" {
+		if err := p.enterParen(); err != nil {
+			return nil, err
+		}
 		expr, err := p.parseExpression()
 		if err != nil {
 			return nil, err
@@ -405,6 +395,7 @@ func (p *Parser) parseFactor() (Expression, error) {
 			return nil, fmt.Errorf("缺少右括号")
 		}
 		p.pos++ // 跳过右括号
+		p.nesting--
 		return expr, nil
 	}
 
@@ -0,0 +1,183 @@
+package interpreter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrLimitExceeded 表示表达式超出了安全限制，所有 LimitError 都可以用 errors.Is 判断
+var ErrLimitExceeded = errors.New("超出安全限制")
+
+// LimitKind 表示超出的限制类型
+type LimitKind int
+
+const (
+	LimitLength  LimitKind = iota // 表达式长度
+	LimitDepth                    // 语法树深度
+	LimitSteps                    // 求值步数
+	LimitTimeout                  // 求值时间
+)
+
+// String 返回限制类型的名称
+func (k LimitKind) String() string {
+	switch k {
+	case LimitLength:
+		return "表达式长度"
+	case LimitDepth:
+		return "语法树深度"
+	case LimitSteps:
+		return "求值步数"
+	case LimitTimeout:
+		return "求值时间"
+	default:
+		return fmt.Sprintf("LimitKind(%d)", int(k))
+	}
+}
+
+// LimitError 超出安全限制时返回的错误
+type LimitError struct {
+	Kind LimitKind
+	Max  int   // 长度、深度、步数的上限
+	Err  error // 求值时间超限时为 context 的错误
+}
+
+// Error 返回错误描述
+func (e *LimitError) Error() string {
+	if e.Kind == LimitTimeout {
+		return fmt.Sprintf("%v: %s超出限制: %v", ErrLimitExceeded, e.Kind, e.Err)
+	}
+	return fmt.Sprintf("%v: %s超过上限 %d", ErrLimitExceeded, e.Kind, e.Max)
+}
+
+// Unwrap 返回 ErrLimitExceeded 和 context 的错误
+func (e *LimitError) Unwrap() []error {
+	if e.Err != nil {
+		return []error{ErrLimitExceeded, e.Err}
+	}
+	return []error{ErrLimitExceeded}
+}
+
+// Limits 解析和求值的安全限制，零值表示不限制
+type Limits struct {
+	MaxLength int           // 表达式字符串的最大字节数
+	MaxDepth  int           // 语法树的最大深度，括号的嵌套层数也不能超过该值
+	MaxSteps  int           // 求值时最多访问的节点数
+	Timeout   time.Duration // 求值的最长时间
+}
+
+// DefaultLimits 适合求值用户输入公式的默认限制
+var DefaultLimits = Limits{
+	MaxLength: 4096,
+	MaxDepth:  64,
+	MaxSteps:  10000,
+	Timeout:   100 * time.Millisecond,
+}
+
+// SetLimits 设置解析器的安全限制，解析器只检查表达式长度和语法树深度
+func (p *Parser) SetLimits(limits Limits) {
+	p.limits = limits
+}
+
+// beginParse 在解析前检查表达式长度并重置深度跟踪
+func (p *Parser) beginParse(expression string) error {
+	if p.limits.MaxLength > 0 && len(expression) > p.limits.MaxLength {
+		return &LimitError{Kind: LimitLength, Max: p.limits.MaxLength}
+	}
+	p.nesting = 0
+	p.depths = nil
+	if p.limits.MaxDepth > 0 {
+		p.depths = make(map[Expression]int)
+	}
+	return nil
+}
+
+// trackDepth 记录新建二元节点的深度，超过上限时返回错误
+func (p *Parser) trackDepth(node, left, right Expression) error {
+	if p.depths == nil {
+		return nil
+	}
+	depth := 1 + max(p.depthOf(left), p.depthOf(right))
+	if depth > p.limits.MaxDepth {
+		return &LimitError{Kind: LimitDepth, Max: p.limits.MaxDepth}
+	}
+	p.depths[node] = depth
+	return nil
+}
+
+// depthOf 返回已解析节点的深度，叶子节点为 1
+func (p *Parser) depthOf(expr Expression) int {
+	if depth, ok := p.depths[expr]; ok {
+		return depth
+	}
+	return 1
+}
+
+// enterParen 进入一层括号，嵌套层数超过上限时返回错误，避免深层括号耗尽解析器的栈
+func (p *Parser) enterParen() error {
+	p.nesting++
+	if p.limits.MaxDepth > 0 && p.nesting > p.limits.MaxDepth {
+		return &LimitError{Kind: LimitDepth, Max: p.limits.MaxDepth}
+	}
+	return nil
+}
+
+// evalGuard 一次受限求值的状态
+type evalGuard struct {
+	ctx      context.Context
+	maxSteps int
+	steps    int
+}
+
+// step 记录一步求值并检查步数和时间
+func (g *evalGuard) step() error {
+	g.steps++
+	if g.maxSteps > 0 && g.steps > g.maxSteps {
+		return &LimitError{Kind: LimitSteps, Max: g.maxSteps}
+	}
+	select {
+	case <-g.ctx.Done():
+		return &LimitError{Kind: LimitTimeout, Err: g.ctx.Err()}
+	default:
+		return nil
+	}
+}
+
+// step 在受限求值中记录一步，沿作用域链查找生效的限制
+func (c *Context) step() error {
+	for scope := c; scope != nil; scope = scope.parent {
+		if scope.guard != nil {
+			return scope.guard.step()
+		}
+	}
+	return nil
+}
+
+// InterpretWithLimits 在步数和时间限制下求值已解析的表达式
+// ctx 被取消或超过 limits.Timeout 时返回 LimitTimeout 错误，context 的错误可以用 errors.Is 判断
+func InterpretWithLimits(ctx context.Context, expr Expression, c *Context, limits Limits) (int, error) {
+	if limits.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, limits.Timeout)
+		defer cancel()
+	}
+
+	previous := c.guard
+	c.guard = &evalGuard{ctx: ctx, maxSteps: limits.MaxSteps}
+	defer func() { c.guard = previous }()
+
+	return expr.Interpret(c)
+}
+
+// EvaluateWithLimits 在安全限制下解析并求值表达式，适合求值用户提供的公式
+func EvaluateWithLimits(ctx context.Context, expression string, c *Context, limits Limits) (int, error) {
+	parser := NewParser(c)
+	parser.SetLimits(limits)
+	expr, err := parser.Parse(expression)
+	if err != nil {
+		return 0, err
+	}
+
+	return InterpretWithLimits(ctx, expr, c, limits)
+}
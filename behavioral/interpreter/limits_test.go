@@ -0,0 +1,137 @@
+package interpreter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// slowExpression 求值时先等待一段时间的表达式
+type slowExpression struct {
+	delay time.Duration
+}
+
+func (s *slowExpression) Interpret(context *Context) (int, error) {
+	return traced(context, s, func() (int, error) {
+		time.Sleep(s.delay)
+		return 1, nil
+	})
+}
+
+func (s *slowExpression) String() string {
+	return "slow"
+}
+
+// limitKind 返回错误对应的限制类型，不是 LimitError 时测试失败
+func limitKind(t *testing.T, err error) LimitKind {
+	t.Helper()
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) || !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("应返回 LimitError: %v", err)
+	}
+	return limitErr.Kind
+}
+
+// 测试表达式长度限制
+func TestParserMaxLength(t *testing.T) {
+	parser := NewParser(NewContext())
+	parser.SetLimits(Limits{MaxLength: 10})
+
+	if _, err := parser.Parse("1 + 2 * 3"); err != nil {
+		t.Fatalf("未超出长度限制的表达式解析失败: %v", err)
+	}
+	_, err := parser.Parse("1 + 2 + 3 + 4")
+	if kind := limitKind(t, err); kind != LimitLength {
+		t.Errorf("应超出表达式长度限制，实际为 %s", kind)
+	}
+	if err.Error() != "超出安全限制: 表达式长度超过上限 10" {
+		t.Errorf("错误信息不正确: %s", err)
+	}
+}
+
+// 测试语法树深度限制
+func TestParserMaxDepth(t *testing.T) {
+	parser := NewParser(NewContext())
+	parser.SetLimits(Limits{MaxDepth: 4})
+
+	// (1 + 2) * (3 + 4) - 5 的深度为 4
+	if _, err := parser.Parse("(1 + 2) * (3 + 4) - 5"); err != nil {
+		t.Fatalf("未超出深度限制的表达式解析失败: %v", err)
+	}
+
+	tests := []string{
+		"1 + 2 + 3 + 4 + 5",       // 左结合的长链
+		"1 * (2 * (3 * (4 * 5)))", // 右侧嵌套
+		"((((((1))))))",           // 只有括号也会耗尽解析器的栈
+	}
+	for _, expression := range tests {
+		_, err := parser.Parse(expression)
+		if kind := limitKind(t, err); kind != LimitDepth {
+			t.Errorf("%s 应超出深度限制，实际为 %s", expression, kind)
+		}
+	}
+
+	// 出错后解析器可以继续使用
+	if _, err := parser.Parse("(1)"); err != nil {
+		t.Errorf("解析器应重置括号层数: %v", err)
+	}
+}
+
+// 测试求值步数限制
+func TestEvaluateMaxSteps(t *testing.T) {
+	ctx := NewContext()
+	ctx.SetVariable("x", 2)
+
+	// x * 3 + 1 共 5 个节点
+	result, err := EvaluateWithLimits(context.Background(), "x * 3 + 1", ctx, Limits{MaxSteps: 5})
+	if err != nil || result != 7 {
+		t.Fatalf("未超出步数限制的求值错误: %d, %v", result, err)
+	}
+
+	_, err = EvaluateWithLimits(context.Background(), "x * 3 + 1 - x", ctx, Limits{MaxSteps: 5})
+	if kind := limitKind(t, err); kind != LimitSteps {
+		t.Errorf("应超出步数限制，实际为 %s", kind)
+	}
+
+	// 限制只在受限求值期间生效，子作用域同样受限
+	if result, err := Evaluate("x * 3 + 1 - x", ctx); err != nil || result != 5 {
+		t.Errorf("普通求值不应受限制: %d, %v", result, err)
+	}
+	scope := ctx.NewChildScope()
+	expr, _ := NewParser(scope).Parse("x + x + x")
+	ctx.guard = &evalGuard{ctx: context.Background(), maxSteps: 2}
+	if _, err := expr.Interpret(scope); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("子作用域应继承父作用域的限制: %v", err)
+	}
+	ctx.guard = nil
+}
+
+// 测试求值超时和取消
+func TestEvaluateTimeout(t *testing.T) {
+	ctx := NewContext()
+	expr := NewAddExpression(&slowExpression{delay: 30 * time.Millisecond}, NewNumberExpression(1))
+
+	_, err := InterpretWithLimits(context.Background(), expr, ctx, Limits{Timeout: 10 * time.Millisecond})
+	if kind := limitKind(t, err); kind != LimitTimeout {
+		t.Errorf("应超出求值时间限制，实际为 %s", kind)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("应能判断 context 的错误: %v", err)
+	}
+	if !strings.Contains(err.Error(), "求值时间超出限制") {
+		t.Errorf("错误信息不正确: %s", err)
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = EvaluateWithLimits(cancelled, "1 + 1", ctx, DefaultLimits)
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("已取消的 context 应立即停止求值: %v", err)
+	}
+
+	if result, err := EvaluateWithLimits(context.Background(), "(1 + 2) * 3 << 2", ctx, DefaultLimits); err != nil || result != 36 {
+		t.Errorf("默认限制下的求值错误: %d, %v", result, err)
+	}
+}
@@ -0,0 +1,211 @@
+package interpreter
+
+import (
+	"reflect"
+	"unicode"
+)
+
+// Memo 子表达式求值结果的缓存
+//
+// 缓存按子表达式的字符串表示保存求值结果，并记录每个子表达式依赖的变量。
+// 修改变量时只清除依赖该变量的子表达式，因此同一棵表达式树在变量大多不变的情况下
+// 反复求值时，未受影响的子树直接返回缓存结果，不再向下求值。
+//
+// 缓存只在开启它的上下文中生效：子作用域可能遮蔽变量，在子作用域中求值时不使用缓存。
+// 上下文本身及其所有父作用域中的 SetVariable / AssignVariable 都会清除相关的缓存项。
+// 依赖的变量通过字符串表示中出现的标识符确定，自定义的表达式类型需要在 String 中
+// 包含其使用的所有变量才能被正确缓存。数字和变量这样的叶子节点不缓存
+type Memo struct {
+	ids     map[string]int          // 子表达式字符串 -> 编号，字符串相同的节点共享缓存项
+	nodes   map[Expression]int      // 当前表达式树的节点 -> 编号，避免每次访问都重新生成字符串
+	root    Expression              // nodes 所属的表达式树的根节点
+	depth   int                     // 正在求值的复合节点层数，为 0 时进入的节点是根节点
+	deps    map[string]map[int]bool // 变量名 -> 依赖该变量的子表达式编号
+	entries map[int]int             // 子表达式编号 -> 缓存的求值结果
+	stats   MemoStats
+}
+
+// MemoStats 缓存的统计信息
+type MemoStats struct {
+	Hits          int // 命中次数
+	Misses        int // 未命中次数
+	Invalidations int // 因变量修改而清除的缓存项数
+	Entries       int // 当前缓存项数
+}
+
+// HitRate 返回命中率，没有查询时为 0
+func (s MemoStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// EnableMemo 为上下文开启求值缓存并返回它，已经开启时返回现有的缓存
+func (c *Context) EnableMemo() *Memo {
+	if c.memo != nil {
+		return c.memo
+	}
+	c.memo = &Memo{
+		ids:     make(map[string]int),
+		nodes:   make(map[Expression]int),
+		deps:    make(map[string]map[int]bool),
+		entries: make(map[int]int),
+	}
+	// 父作用域中的变量修改同样会影响求值结果，需要通知到这里
+	for scope := c.parent; scope != nil; scope = scope.parent {
+		scope.watchers = append(scope.watchers, c.memo)
+	}
+	return c.memo
+}
+
+// DisableMemo 关闭上下文的求值缓存
+func (c *Context) DisableMemo() {
+	if c.memo == nil {
+		return
+	}
+	for scope := c.parent; scope != nil; scope = scope.parent {
+		for i, memo := range scope.watchers {
+			if memo == c.memo {
+				scope.watchers = append(scope.watchers[:i], scope.watchers[i+1:]...)
+				break
+			}
+		}
+	}
+	c.memo = nil
+}
+
+// Memo 返回上下文的求值缓存，没有开启时返回 nil
+func (c *Context) Memo() *Memo {
+	return c.memo
+}
+
+// invalidate 通知当前作用域及子作用域的缓存变量已被修改
+func (c *Context) invalidate(name string) {
+	if c.memo != nil {
+		c.memo.Invalidate(name)
+	}
+	for _, memo := range c.watchers {
+		memo.Invalidate(name)
+	}
+}
+
+// Stats 返回缓存的统计信息
+func (m *Memo) Stats() MemoStats {
+	stats := m.stats
+	stats.Entries = len(m.entries)
+	return stats
+}
+
+// Invalidate 清除依赖指定变量的缓存项
+func (m *Memo) Invalidate(name string) {
+	for id := range m.deps[name] {
+		if _, ok := m.entries[id]; ok {
+			delete(m.entries, id)
+			m.stats.Invalidations++
+		}
+	}
+}
+
+// Clear 清空所有缓存项和统计信息
+func (m *Memo) Clear() {
+	m.ids = make(map[string]int)
+	m.nodes = make(map[Expression]int)
+	m.root = nil
+	m.deps = make(map[string]map[int]bool)
+	m.entries = make(map[int]int)
+	m.stats = MemoStats{}
+}
+
+// memoized 在开启了缓存的上下文中缓存复合节点的求值结果
+func memoized(context *Context, expr Expression, eval func() (int, error)) (int, error) {
+	memo := context.memo
+	switch expr.(type) {
+	case *NumberExpression, *VariableExpression:
+		return eval()
+	}
+	comparable := reflect.TypeOf(expr).Comparable()
+	if memo.depth == 0 {
+		memo.enterTree(expr, comparable)
+	}
+	memo.depth++
+	defer func() { memo.depth-- }()
+	if !comparable {
+		return eval()
+	}
+
+	id := memo.id(expr)
+	if value, ok := memo.entries[id]; ok {
+		memo.stats.Hits++
+		return value, nil
+	}
+	memo.stats.Misses++
+
+	value, err := eval()
+	if err != nil {
+		// 错误不缓存，下次求值时重新报告
+		return value, err
+	}
+	memo.entries[id] = value
+	return value, nil
+}
+
+// enterTree 开始对以 root 为根的表达式树求值
+// 节点编号的缓存只保留当前这棵树：同一棵树反复求值时每个节点的字符串只生成一次，
+// 换成另一棵树（例如每次 Evaluate 都重新解析）时清空，缓存不会随见过的树无限增长
+func (m *Memo) enterTree(root Expression, comparable bool) {
+	if comparable && root == m.root {
+		return
+	}
+	clear(m.nodes)
+	m.root = nil
+	if comparable {
+		m.root = root
+	}
+}
+
+// id 返回节点字符串表示的编号，首次遇到时登记它依赖的变量
+func (m *Memo) id(expr Expression) int {
+	if id, ok := m.nodes[expr]; ok {
+		return id
+	}
+
+	str := expr.String()
+	id, ok := m.ids[str]
+	if !ok {
+		id = len(m.ids)
+		m.ids[str] = id
+		for _, name := range identifiers(str) {
+			if m.deps[name] == nil {
+				m.deps[name] = make(map[int]bool)
+			}
+			m.deps[name][id] = true
+		}
+	}
+	m.nodes[expr] = id
+	return id
+}
+
+// identifiers 按解析器的规则提取字符串中出现的变量名，按出现顺序去重
+func identifiers(s string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		if !unicode.IsLetter(runes[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+			i++
+		}
+		name := string(runes[start:i])
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,232 @@
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// 测试反复解析同一个表达式时缓存不会随求值次数增长
+func TestMemoDoesNotGrowWithReparsing(t *testing.T) {
+	ctx := NewContext()
+	ctx.SetVariable("x", 3)
+	memo := ctx.EnableMemo()
+
+	for i := 0; i < 1000; i++ {
+		if result, err := Evaluate("(x + 1) * (x - 1)", ctx); err != nil || result != 8 {
+			t.Fatalf("第 %d 次求值错误: %d, %v", i+1, result, err)
+		}
+	}
+	if len(memo.ids) != 3 {
+		t.Errorf("每次求值都解析出新的表达式树，缓存应只记录 3 个不同的子表达式，实际 %d 个", len(memo.ids))
+	}
+	if len(memo.nodes) > 3 {
+		t.Errorf("节点编号缓存应只保留当前表达式树的节点，实际 %d 个", len(memo.nodes))
+	}
+	if stats := memo.Stats(); stats.Entries != 3 || stats.Hits != 999 {
+		t.Errorf("之后每次求值应在根节点命中: %+v", stats)
+	}
+}
+
+// 测试同一棵表达式树反复求值时，命中缓存不再生成子表达式的字符串
+func TestMemoHitDoesNotAllocate(t *testing.T) {
+	ctx, expr := newBenchmarkExpression(t, 50)
+	ctx.EnableMemo()
+	if _, err := expr.Interpret(ctx); err != nil {
+		t.Fatalf("求值失败: %v", err)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		expr.Interpret(ctx)
+	})
+	if allocs > 0 {
+		t.Errorf("命中缓存的求值不应分配内存，实际每次 %.1f 次", allocs)
+	}
+}
+
+// 测试缓存命中与变量修改后的失效
+func TestMemoHitsAndInvalidation(t *testing.T) {
+	ctx := NewContext()
+	ctx.SetVariable("x", 3)
+	ctx.SetVariable("y", 4)
+	memo := ctx.EnableMemo()
+
+	expr, err := NewParser(ctx).Parse("(x * 2 + 1) * (y - 1)")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if result, err := expr.Interpret(ctx); err != nil || result != 21 {
+			t.Fatalf("第 %d 次求值错误: %d, %v", i+1, result, err)
+		}
+	}
+	stats := memo.Stats()
+	if stats.Misses != 4 || stats.Hits != 2 {
+		t.Errorf("首次求值应缓存 4 个复合节点，之后每次在根节点命中: %+v", stats)
+	}
+
+	// 修改 y 只需要重新计算依赖 y 的节点
+	ctx.SetVariable("y", 5)
+	if result, _ := expr.Interpret(ctx); result != 28 {
+		t.Errorf("修改变量后结果错误: 期望 28，得到 %d", result)
+	}
+	stats = memo.Stats()
+	if stats.Invalidations != 2 {
+		t.Errorf("应清除依赖 y 的 2 个缓存项: %+v", stats)
+	}
+	if stats.Hits != 3 || stats.Misses != 6 {
+		t.Errorf("只有依赖 y 的节点应重新计算: %+v", stats)
+	}
+
+	// 恢复原值时仍能得到正确结果
+	ctx.SetVariable("y", 4)
+	if result, _ := expr.Interpret(ctx); result != 21 {
+		t.Errorf("恢复变量后结果错误: %d", result)
+	}
+
+	memo.Clear()
+	if memo.Stats() != (MemoStats{}) {
+		t.Error("Clear 后应清空缓存与统计")
+	}
+}
+
+// 测试缓存只在开启它的上下文中生效，父作用域的变量修改同样会清除缓存
+func TestMemoWithScopes(t *testing.T) {
+	global := NewContext()
+	global.SetVariable("price", 100)
+	block := global.NewChildScope()
+	block.SetVariable("qty", 2)
+	memo := block.EnableMemo()
+
+	expr, _ := NewParser(block).Parse("price * qty + 1")
+	if result, _ := expr.Interpret(block); result != 201 {
+		t.Fatalf("期望 201，得到 %d", result)
+	}
+
+	// 父作用域的变量修改会通知子作用域的缓存
+	global.SetVariable("price", 10)
+	if result, _ := expr.Interpret(block); result != 21 {
+		t.Errorf("父作用域修改变量后结果错误: 期望 21，得到 %d", result)
+	}
+
+	// 更深的子作用域遮蔽了变量，求值时不使用缓存
+	inner := block.NewChildScope()
+	inner.SetVariable("qty", 5)
+	if result, _ := expr.Interpret(inner); result != 51 {
+		t.Errorf("子作用域应使用遮蔽后的变量: 期望 51，得到 %d", result)
+	}
+
+	// 通过子作用域给变量赋值同样会清除缓存
+	if err := inner.AssignVariable("price", 20); err != nil {
+		t.Fatal(err)
+	}
+	if result, _ := expr.Interpret(block); result != 41 {
+		t.Errorf("赋值后结果错误: 期望 41，得到 %d", result)
+	}
+	if memo.Stats().Invalidations != 4 {
+		t.Errorf("两次修改 price 各应清除 2 个缓存项: %+v", memo.Stats())
+	}
+
+	// 关闭缓存后不再接收父作用域的通知
+	block.DisableMemo()
+	if block.Memo() != nil || len(global.watchers) != 0 {
+		t.Error("关闭缓存后应取消父作用域的通知")
+	}
+	if block.EnableMemo() != block.EnableMemo() {
+		t.Error("重复开启应返回同一个缓存")
+	}
+}
+
+// 测试求值错误不会被缓存
+func TestMemoDoesNotCacheErrors(t *testing.T) {
+	ctx := NewContext()
+	ctx.EnableMemo()
+	ctx.SetVariable("b", 0)
+
+	expr, _ := NewParser(ctx).Parse("(a + 1) / b")
+	if _, err := expr.Interpret(ctx); err == nil {
+		t.Fatal("未定义变量应报错")
+	}
+
+	ctx.SetVariable("a", 9)
+	if _, err := expr.Interpret(ctx); err == nil {
+		t.Fatal("除零应报错")
+	}
+
+	ctx.SetVariable("b", 2)
+	if result, err := expr.Interpret(ctx); err != nil || result != 5 {
+		t.Errorf("期望 5，得到 %d, %v", result, err)
+	}
+}
+
+// 测试缓存与求值跟踪同时使用时，命中的节点不再展开子节点
+func TestMemoWithTracer(t *testing.T) {
+	ctx := NewContext()
+	ctx.SetVariable("x", 3)
+	ctx.EnableMemo()
+	expr, _ := NewParser(ctx).Parse("x * (2 + 4)")
+	expr.Interpret(ctx)
+
+	recorder := NewTraceRecorder()
+	ctx.SetTracer(recorder)
+	if result, _ := expr.Interpret(ctx); result != 18 {
+		t.Fatalf("期望 18，得到 %d", result)
+	}
+	if len(recorder.Events()) != 2 {
+		t.Errorf("命中缓存的根节点只应产生进入和退出两个事件:\n%s", recorder)
+	}
+}
+
+// 测试变量名提取
+func TestIdentifiers(t *testing.T) {
+	got := identifiers("((b1 * 2) + (a << b1)) - total")
+	want := []string{"b1", "a", "total"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("期望 %v，得到 %v", want, got)
+	}
+}
+
+// newBenchmarkExpression 构建由 n 项乘积之和组成的大表达式，只有 x 会频繁变化
+func newBenchmarkExpression(tb testing.TB, n int) (*Context, Expression) {
+	ctx := NewContext()
+	terms := make([]string, 0, n+1)
+	for i := 0; i < n; i++ {
+		ctx.SetVariable(fmt.Sprintf("a%d", i), i)
+		ctx.SetVariable(fmt.Sprintf("b%d", i), i+1)
+		terms = append(terms, fmt.Sprintf("(a%d * b%d + %d) %% 97", i, i, i))
+	}
+	ctx.SetVariable("x", 0)
+	terms = append(terms, "x")
+
+	expr, err := NewParser(ctx).Parse(strings.Join(terms, " + "))
+	if err != nil {
+		tb.Fatalf("解析失败: %v", err)
+	}
+	return ctx, expr
+}
+
+// BenchmarkInterpretLarge 不使用缓存，每次求值整棵表达式树
+func BenchmarkInterpretLarge(b *testing.B) {
+	ctx, expr := newBenchmarkExpression(b, 200)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ctx.SetVariable("x", i)
+		if _, err := expr.Interpret(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkInterpretLargeMemo 使用缓存，每次只有依赖 x 的根节点需要重新计算
+func BenchmarkInterpretLargeMemo(b *testing.B) {
+	ctx, expr := newBenchmarkExpression(b, 200)
+	ctx.EnableMemo()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ctx.SetVariable("x", i)
+		if _, err := expr.Interpret(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
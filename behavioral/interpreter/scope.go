@@ -0,0 +1,60 @@
+package interpreter
+
+import "fmt"
+
+// NewChildScope 创建一个以当前上下文为父作用域的子作用域
+// 子作用域中 SetVariable 定义的变量只在子作用域内可见，并会遮蔽父作用域中的同名变量
+func (c *Context) NewChildScope() *Context {
+	return &Context{
+		variables: make(map[string]int),
+		parent:    c,
+	}
+}
+
+// Parent 返回父作用域，顶层上下文返回 nil
+func (c *Context) Parent() *Context {
+	return c.parent
+}
+
+// Depth 返回作用域深度，顶层上下文为 0
+func (c *Context) Depth() int {
+	depth := 0
+	for scope := c.parent; scope != nil; scope = scope.parent {
+		depth++
+	}
+	return depth
+}
+
+// HasLocalVariable 检查变量是否定义在当前作用域（不查找父作用域）
+func (c *Context) HasLocalVariable(name string) bool {
+	_, exists := c.variables[name]
+	return exists
+}
+
+// AssignVariable 给已定义的变量赋值，修改的是最近一个定义了该变量的作用域
+// 与 SetVariable 不同，它不会在当前作用域创建新变量
+func (c *Context) AssignVariable(name string, value int) error {
+	for scope := c; scope != nil; scope = scope.parent {
+		if _, exists := scope.variables[name]; exists {
+			scope.variables[name] = value
+			scope.invalidate(name)
+			return nil
+		}
+	}
+	return fmt.Errorf("变量 '%s' 未定义", name)
+}
+
+// WithScope 在新的子作用域中执行 fn，fn 中定义的临时变量在返回后即被丢弃
+func (c *Context) WithScope(fn func(scope *Context)) {
+	fn(c.NewChildScope())
+}
+
+// EvaluateInScope 在带有临时变量的子作用域中评估表达式
+// locals 中的变量只对本次评估可见，不会污染传入的上下文
+func EvaluateInScope(expression string, context *Context, locals map[string]int) (int, error) {
+	scope := context.NewChildScope()
+	for name, value := range locals {
+		scope.SetVariable(name, value)
+	}
+	return Evaluate(expression, scope)
+}
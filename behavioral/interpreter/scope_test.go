@@ -0,0 +1,120 @@
+package interpreter
+
+import "testing"
+
+// 测试子作用域的变量查找
+func TestChildScopeLookup(t *testing.T) {
+	global := NewContext()
+	global.SetVariable("x", 10)
+	global.SetVariable("y", 5)
+
+	block := global.NewChildScope()
+	block.SetVariable("tmp", 3)
+
+	// 子作用域可以访问父作用域的变量
+	result, err := Evaluate("x + y + tmp", block)
+	if err != nil {
+		t.Fatalf("评估失败: %v", err)
+	}
+	if result != 18 {
+		t.Errorf("期望 18，得到 %d", result)
+	}
+
+	// 父作用域看不到子作用域的临时变量
+	if _, err := Evaluate("tmp", global); err == nil {
+		t.Error("父作用域不应能访问子作用域的变量")
+	}
+
+	if block.Parent() != global {
+		t.Error("子作用域的父作用域不正确")
+	}
+	if global.Depth() != 0 || block.Depth() != 1 || block.NewChildScope().Depth() != 2 {
+		t.Error("作用域深度计算错误")
+	}
+}
+
+// 测试变量遮蔽
+func TestScopeShadowing(t *testing.T) {
+	global := NewContext()
+	global.SetVariable("x", 10)
+
+	inner := global.NewChildScope()
+	inner.SetVariable("x", 100)
+
+	if value, _ := inner.GetVariable("x"); value != 100 {
+		t.Errorf("子作用域应遮蔽父作用域的变量，期望 100，得到 %d", value)
+	}
+	if value, _ := global.GetVariable("x"); value != 10 {
+		t.Errorf("遮蔽不应修改父作用域，期望 10，得到 %d", value)
+	}
+	if !inner.HasLocalVariable("x") {
+		t.Error("x 应定义在子作用域")
+	}
+
+	// 多层嵌套时取最近的定义
+	deeper := inner.NewChildScope()
+	if value, _ := deeper.GetVariable("x"); value != 100 {
+		t.Errorf("应取最近作用域的值，期望 100，得到 %d", value)
+	}
+	if deeper.HasLocalVariable("x") {
+		t.Error("x 不应定义在最内层作用域")
+	}
+}
+
+// 测试给外层变量赋值
+func TestAssignVariable(t *testing.T) {
+	global := NewContext()
+	global.SetVariable("counter", 1)
+
+	inner := global.NewChildScope().NewChildScope()
+	if err := inner.AssignVariable("counter", 2); err != nil {
+		t.Fatalf("赋值失败: %v", err)
+	}
+
+	if value, _ := global.GetVariable("counter"); value != 2 {
+		t.Errorf("赋值应修改定义变量的作用域，期望 2，得到 %d", value)
+	}
+	if inner.HasLocalVariable("counter") {
+		t.Error("赋值不应在当前作用域创建新变量")
+	}
+
+	if err := inner.AssignVariable("missing", 1); err == nil {
+		t.Error("给未定义的变量赋值应返回错误")
+	}
+}
+
+// 测试 WithScope 与 EvaluateInScope
+func TestWithScope(t *testing.T) {
+	global := NewContext()
+	global.SetVariable("price", 100)
+
+	var total int
+	global.WithScope(func(scope *Context) {
+		scope.SetVariable("qty", 3)
+		scope.SetVariable("discount", 20)
+
+		var err error
+		total, err = Evaluate("price * qty - discount", scope)
+		if err != nil {
+			t.Fatalf("评估失败: %v", err)
+		}
+	})
+
+	if total != 280 {
+		t.Errorf("期望 280，得到 %d", total)
+	}
+	if _, exists := global.GetVariable("qty"); exists {
+		t.Error("WithScope 结束后临时变量应被丢弃")
+	}
+
+	result, err := EvaluateInScope("price * rate / 100", global, map[string]int{"rate": 80})
+	if err != nil {
+		t.Fatalf("评估失败: %v", err)
+	}
+	if result != 80 {
+		t.Errorf("期望 80，得到 %d", result)
+	}
+	if _, exists := global.GetVariable("rate"); exists {
+		t.Error("EvaluateInScope 不应污染传入的上下文")
+	}
+}
@@ -0,0 +1,142 @@
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EvalTracer 接收表达式求值过程中每个节点的进入和退出事件
+// 通过 Context.SetTracer 设置，子作用域会继承父作用域的跟踪器
+type EvalTracer interface {
+	OnEnter(expr Expression)                      // 开始求值某个节点
+	OnExit(expr Expression, value int, err error) // 节点求值结束，携带中间结果
+}
+
+// SetTracer 为上下文设置求值跟踪器，传入 nil 取消跟踪
+func (c *Context) SetTracer(tracer EvalTracer) {
+	c.tracer = tracer
+}
+
+// Tracer 返回当前生效的求值跟踪器，沿作用域链向外查找
+func (c *Context) Tracer() EvalTracer {
+	for scope := c; scope != nil; scope = scope.parent {
+		if scope.tracer != nil {
+			return scope.tracer
+		}
+	}
+	return nil
+}
+
+// traced 在受限求值时检查限制，在跟踪器存在时通知节点的进入和退出，在求值缓存存在时缓存节点的结果
+func traced(context *Context, expr Expression, eval func() (int, error)) (int, error) {
+	if err := context.step(); err != nil {
+		return 0, err
+	}
+
+	if context.memo != nil {
+		inner := eval
+		eval = func() (int, error) { return memoized(context, expr, inner) }
+	}
+
+	tracer := context.Tracer()
+	if tracer == nil {
+		return eval()
+	}
+
+	tracer.OnEnter(expr)
+	value, err := eval()
+	tracer.OnExit(expr, value, err)
+	return value, err
+}
+
+// TraceEventKind 表示跟踪事件的类型
+type TraceEventKind int
+
+const (
+	TraceEnter TraceEventKind = iota // 进入节点
+	TraceExit                        // 退出节点
+)
+
+// TraceEvent 记录求值过程中的一步
+type TraceEvent struct {
+	Kind  TraceEventKind // 事件类型
+	Depth int            // 节点在表达式树中的深度，根节点为 0
+	Expr  string         // 节点的字符串表示
+	Value int            // 节点的求值结果，仅退出事件有效
+	Err   error          // 节点的求值错误，仅退出事件有效
+}
+
+// String 格式化跟踪事件，按深度缩进
+func (e TraceEvent) String() string {
+	indent := strings.Repeat("  ", e.Depth)
+	switch {
+	case e.Kind == TraceEnter:
+		return fmt.Sprintf("%s→ %s", indent, e.Expr)
+	case e.Err != nil:
+		return fmt.Sprintf("%s← %s 错误: %v", indent, e.Expr, e.Err)
+	default:
+		return fmt.Sprintf("%s← %s = %d", indent, e.Expr, e.Value)
+	}
+}
+
+// TraceRecorder 是记录所有求值步骤的跟踪器
+// OnStep 不为空时每记录一步都会调用它，可用于实现单步调试
+type TraceRecorder struct {
+	OnStep func(event TraceEvent)
+
+	events []TraceEvent
+	depth  int
+}
+
+// NewTraceRecorder 创建一个新的跟踪记录器
+func NewTraceRecorder() *TraceRecorder {
+	return &TraceRecorder{
+		events: make([]TraceEvent, 0),
+	}
+}
+
+// OnEnter 记录进入节点
+func (r *TraceRecorder) OnEnter(expr Expression) {
+	r.record(TraceEvent{Kind: TraceEnter, Depth: r.depth, Expr: expr.String()})
+	r.depth++
+}
+
+// OnExit 记录退出节点及其结果
+func (r *TraceRecorder) OnExit(expr Expression, value int, err error) {
+	r.depth--
+	r.record(TraceEvent{Kind: TraceExit, Depth: r.depth, Expr: expr.String(), Value: value, Err: err})
+}
+
+// record 保存事件并通知单步回调
+func (r *TraceRecorder) record(event TraceEvent) {
+	r.events = append(r.events, event)
+	if r.OnStep != nil {
+		r.OnStep(event)
+	}
+}
+
+// Events 返回已记录的事件
+func (r *TraceRecorder) Events() []TraceEvent {
+	return r.events
+}
+
+// String 返回逐行的求值日志
+func (r *TraceRecorder) String() string {
+	var sb strings.Builder
+	for _, event := range r.events {
+		sb.WriteString(event.String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// Trace 评估表达式并返回逐步的求值日志
+// 跟踪器设置在临时子作用域上，不会影响传入的上下文
+func Trace(expression string, context *Context) (int, []TraceEvent, error) {
+	recorder := NewTraceRecorder()
+	scope := context.NewChildScope()
+	scope.SetTracer(recorder)
+
+	value, err := Evaluate(expression, scope)
+	return value, recorder.Events(), err
+}
@@ -0,0 +1,78 @@
+package interpreter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTrace 测试逐步求值日志
+func TestTrace(t *testing.T) {
+	ctx := NewContext()
+	ctx.SetVariable("x", 3)
+
+	result, events, err := Trace("x * (2 + 4)", ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 18, result)
+
+	// 5 个节点，每个节点一次进入一次退出
+	assert.Len(t, events, 10)
+	assert.Equal(t, TraceEvent{Kind: TraceEnter, Depth: 0, Expr: "(x * (2 + 4))"}, events[0])
+	assert.Equal(t, TraceEvent{Kind: TraceExit, Depth: 1, Expr: "x", Value: 3}, events[2])
+	assert.Equal(t, TraceEvent{Kind: TraceExit, Depth: 1, Expr: "(2 + 4)", Value: 6}, events[8])
+	assert.Equal(t, TraceEvent{Kind: TraceExit, Depth: 0, Expr: "(x * (2 + 4))", Value: 18}, events[9])
+
+	assert.Nil(t, ctx.Tracer(), "Trace 不应修改传入的上下文")
+}
+
+// TestTraceError 测试求值出错时的跟踪
+func TestTraceError(t *testing.T) {
+	_, events, err := Trace("10 / (y - y)", NewContext())
+	assert.Error(t, err)
+
+	last := events[len(events)-1]
+	assert.Equal(t, TraceExit, last.Kind)
+	assert.Equal(t, 0, last.Depth)
+	assert.Error(t, last.Err)
+	assert.Contains(t, last.String(), "错误: 变量 'y' 未定义")
+}
+
+// TestTraceRecorderOnStep 测试单步回调和格式化输出
+func TestTraceRecorderOnStep(t *testing.T) {
+	ctx := NewContext()
+	ctx.SetVariable("a", 5)
+
+	recorder := NewTraceRecorder()
+	var steps []string
+	recorder.OnStep = func(event TraceEvent) {
+		if event.Kind == TraceExit {
+			steps = append(steps, event.Expr)
+		}
+	}
+	ctx.SetTracer(recorder)
+
+	// 子作用域继承父作用域的跟踪器
+	child := ctx.NewChildScope()
+	child.SetVariable("b", 2)
+	result, err := Evaluate("a - b", child)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result)
+	assert.Equal(t, []string{"a", "b", "(a - b)"}, steps)
+
+	expected := strings.Join([]string{
+		"→ (a - b)",
+		"  → a",
+		"  ← a = 5",
+		"  → b",
+		"  ← b = 2",
+		"← (a - b) = 3",
+		"",
+	}, "\n")
+	assert.Equal(t, expected, recorder.String())
+
+	// 取消跟踪后不再记录
+	ctx.SetTracer(nil)
+	Evaluate("a + 1", ctx)
+	assert.Len(t, recorder.Events(), 6)
+}
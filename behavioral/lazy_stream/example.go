@@ -0,0 +1,41 @@
+package lazy_stream
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RunExample 运行惰性数据流示例
+func RunExample() {
+	// 无限流 + Take：只计算需要的前 10 项
+	fmt.Println("斐波那契数列前 10 项:", Fibonacci().Take(10).Collect())
+
+	// 惰性求值：Peek 显示每个元素何时被拉取，找到 3 个结果后立即停止
+	fmt.Println("寻找前 3 个平方数大于 50 的偶数:")
+	evens := Naturals().
+		Peek(func(n int) { fmt.Printf("  拉取 %d\n", n) }).
+		Filter(func(n int) bool { return n%2 == 0 })
+	squares := Map(evens, func(n int) int { return n * n })
+	fmt.Println("  结果:", squares.Filter(func(n int) bool { return n > 50 }).Take(3).Collect())
+
+	// 折叠：100 以内 3 或 5 的倍数之和
+	sum := Reduce(Naturals().TakeWhile(func(n int) bool { return n < 100 }).
+		Filter(func(n int) bool { return n%3 == 0 || n%5 == 0 }), 0,
+		func(acc, n int) int { return acc + n })
+	fmt.Println("100 以内 3 或 5 的倍数之和:", sum)
+
+	// 迭代器桥接：数据流可以直接用于 for range
+	for word := range FromSlice([]string{"lazy", "pull", "stream"}).Skip(1).Seq() {
+		fmt.Println("  单词:", word)
+	}
+
+	// 时钟流：ctx 超时后流结束
+	ctx, cancel := context.WithTimeout(context.Background(), 55*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	elapsed := Map(Ticks(ctx, 20*time.Millisecond), func(t time.Time) time.Duration {
+		return t.Sub(start).Round(10 * time.Millisecond)
+	})
+	fmt.Println("时钟流:", elapsed.Collect())
+}
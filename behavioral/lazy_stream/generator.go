@@ -0,0 +1,56 @@
+package lazy_stream
+
+import (
+	"context"
+	"time"
+)
+
+// Generate 无限流：每次拉取时调用 f 生成一个元素
+func Generate[T any](f func() T) *Stream[T] {
+	return New(func() (T, bool) { return f(), true })
+}
+
+// Iterate 无限流：seed, f(seed), f(f(seed)), ...
+func Iterate[T any](seed T, f func(T) T) *Stream[T] {
+	next, started := seed, false
+	return New(func() (T, bool) {
+		if started {
+			next = f(next)
+		}
+		started = true
+		return next, true
+	})
+}
+
+// Naturals 无限流：0, 1, 2, ...
+func Naturals() *Stream[int] {
+	return Iterate(0, func(n int) int { return n + 1 })
+}
+
+// Fibonacci 无限流：斐波那契数列 0, 1, 1, 2, 3, 5, ...
+// 第 94 项起超出 uint64 范围，会发生回绕，需要更多项时请改用 math/big
+func Fibonacci() *Stream[uint64] {
+	a, b := uint64(0), uint64(1)
+	return Generate(func() uint64 {
+		v := a
+		a, b = b, a+b
+		return v
+	})
+}
+
+// Ticks 时钟流：每隔 interval 产生一个当前时间，ctx 结束后流结束
+// 拉取会阻塞到下一次触发；底层的 Ticker 在流关闭时停止
+func Ticks(ctx context.Context, interval time.Duration) *Stream[time.Time] {
+	ticker := time.NewTicker(interval)
+	return &Stream[time.Time]{
+		pull: func() (time.Time, bool) {
+			select {
+			case t := <-ticker.C:
+				return t, true
+			case <-ctx.Done():
+				return time.Time{}, false
+			}
+		},
+		stop: ticker.Stop,
+	}
+}
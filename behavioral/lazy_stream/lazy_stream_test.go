@@ -0,0 +1,188 @@
+package lazy_stream
+
+import (
+	"context"
+	"maps"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/XiaoluCoding626/go-design-pattern/behavioral/iterator"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLaziness 测试中间操作不拉取元素，终端操作只拉取需要的元素
+func TestLaziness(t *testing.T) {
+	pulled, mapped := 0, 0
+	s := Naturals().Peek(func(int) { pulled++ })
+	doubled := Map(s, func(n int) int { mapped++; return n * 2 })
+	pipeline := doubled.Filter(func(n int) bool { return n%3 == 0 }).Take(4)
+	assert.Zero(t, pulled, "构建流水线时不拉取任何元素")
+
+	assert.Equal(t, []int{0, 6, 12, 18}, pipeline.Collect())
+	assert.Equal(t, 10, pulled, "只拉取到第 4 个结果为止")
+	assert.Equal(t, 10, mapped)
+
+	_, ok := pipeline.Next()
+	assert.False(t, ok, "流只能消费一次")
+}
+
+// TestOperations 测试各种操作
+func TestOperations(t *testing.T) {
+	assert.Equal(t, []uint64{0, 1, 1, 2, 3, 5, 8, 13, 21, 34}, Fibonacci().Take(10).Collect())
+	assert.Equal(t, []int{1, 2, 4, 8, 16}, Iterate(1, func(n int) int { return n * 2 }).Take(5).Collect())
+	assert.Equal(t, []int{3, 4, 5, 6}, Naturals().Skip(3).TakeWhile(func(n int) bool { return n < 7 }).Collect())
+	assert.Equal(t, []int{3}, FromSlice([]int{1, 2, 3}).Skip(2).Take(5).Collect())
+	assert.Empty(t, FromSlice([]int{1, 2}).Skip(5).Collect())
+	assert.Empty(t, Naturals().Take(0).Collect())
+	assert.Equal(t, 5, FromSlice([]string{"a", "b", "c", "d", "e"}).Count())
+
+	sum := Reduce(FromSlice([]int{1, 2, 3, 4}), 10, func(acc, n int) int { return acc + n })
+	assert.Equal(t, 20, sum)
+	lengths := Reduce(FromSlice([]string{"go", "lazy"}), map[string]int{}, func(m map[string]int, s string) map[string]int {
+		m[s] = len(s)
+		return m
+	})
+	assert.Equal(t, map[string]int{"go": 2, "lazy": 4}, lengths)
+
+	first, ok := Naturals().Filter(func(n int) bool { return n > 41 }).First()
+	assert.True(t, ok)
+	assert.Equal(t, 42, first)
+	_, ok = FromSlice([]int(nil)).First()
+	assert.False(t, ok)
+
+	calls := 0
+	counter := Generate(func() int { calls++; return calls })
+	assert.Equal(t, []int{1, 2, 3}, counter.Take(3).Collect())
+	assert.Equal(t, 3, calls)
+}
+
+// TestIteratorBridge 测试与 behavioral/iterator 和 iter.Seq 之间的桥接
+func TestIteratorBridge(t *testing.T) {
+	it := iterator.NewConcreteIterator([]string{"a", "bb", "ccc"})
+	lengths := Map(FromIterator[string](it), func(s string) int { return len(s) })
+	assert.Equal(t, []int{1, 2, 3}, lengths.Collect())
+
+	var got []int
+	for n := range Naturals().Seq() {
+		if n == 3 {
+			break
+		}
+		got = append(got, n)
+	}
+	assert.Equal(t, []int{0, 1, 2}, got)
+
+	m := map[string]int{"x": 1, "y": 2, "z": 3}
+	keys := FromSeq(maps.Keys(m)).Collect()
+	slices.Sort(keys)
+	assert.Equal(t, []string{"x", "y", "z"}, keys)
+	assert.Equal(t, []int{1, 2}, slices.Collect(FromSlice([]int{1, 2}).Seq()))
+}
+
+// TestClosePropagates 测试关闭下游时释放源头：iter.Pull 的协程退出
+func TestClosePropagates(t *testing.T) {
+	before := runtime.NumGoroutine()
+	for i := 0; i < 50; i++ {
+		s := FromSeq(func(yield func(int) bool) {
+			for n := 0; yield(n); n++ {
+			}
+		})
+		assert.Equal(t, []int{0, 1, 2}, s.Filter(func(int) bool { return true }).Take(3).Collect())
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before+1)
+
+	closed := 0
+	s := &Stream[int]{pull: func() (int, bool) { return 1, true }, stop: func() { closed++ }}
+	doubled := Map(s.Skip(1), func(n int) int { return n * 2 })
+	v, ok := doubled.First()
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+	doubled.Close()
+	assert.Equal(t, 1, closed, "关闭只执行一次")
+}
+
+// TestTicks 测试时钟流在 ctx 结束后终止
+func TestTicks(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	ticks := Ticks(ctx, 5*time.Millisecond).Take(3).Collect()
+	assert.Len(t, ticks, 3)
+	assert.True(t, ticks[0].Before(ticks[2]))
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	assert.Empty(t, Ticks(ctx, time.Hour).Collect())
+}
+
+const benchSize = 100000
+
+func benchData() []int {
+	data := make([]int, benchSize)
+	for i := range data {
+		data[i] = i
+	}
+	return data
+}
+
+// 前 100 个满足条件的结果：惰性流只处理需要的元素，急切求值的切片流水线要处理全部数据
+
+func BenchmarkEagerFirst100(b *testing.B) {
+	data := benchData()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var evens []int
+		for _, n := range data {
+			if n%2 == 0 {
+				evens = append(evens, n)
+			}
+		}
+		squares := make([]int, len(evens))
+		for j, n := range evens {
+			squares[j] = n * n
+		}
+		_ = squares[:100]
+	}
+}
+
+func BenchmarkLazyFirst100(b *testing.B) {
+	data := benchData()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		evens := FromSlice(data).Filter(func(n int) bool { return n%2 == 0 })
+		_ = Map(evens, func(n int) int { return n * n }).Take(100).Collect()
+	}
+}
+
+// 处理全部数据：惰性流不分配中间切片，但每个元素要经过多层函数调用
+
+func BenchmarkEagerSum(b *testing.B) {
+	data := benchData()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var evens []int
+		for _, n := range data {
+			if n%2 == 0 {
+				evens = append(evens, n)
+			}
+		}
+		sum := 0
+		for _, n := range evens {
+			sum += n * n
+		}
+		_ = sum
+	}
+}
+
+func BenchmarkLazySum(b *testing.B) {
+	data := benchData()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		evens := FromSlice(data).Filter(func(n int) bool { return n%2 == 0 })
+		_ = Reduce(Map(evens, func(n int) int { return n * n }), 0, func(acc, n int) int { return acc + n })
+	}
+}
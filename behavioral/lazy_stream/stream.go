@@ -0,0 +1,199 @@
+package lazy_stream
+
+import (
+	"iter"
+
+	"github.com/XiaoluCoding626/go-design-pattern/behavioral/iterator"
+)
+
+// Stream 惰性求值的拉取式数据流
+//
+// 中间操作（Map、Filter、Take 等）只是把拉取函数包装起来，不会读取任何元素；
+// 只有终端操作（Collect、Reduce、ForEach 等）或直接调用 Next 时，
+// 元素才会被一个个地从源头拉出来，并且只拉取需要的数量，所以可以处理无限流。
+//
+// Stream 只能被消费一次，也不是并发安全的。
+type Stream[T any] struct {
+	pull func() (T, bool) // 返回下一个元素，false 表示流已结束
+	stop func()           // 释放源头持有的资源，可以为 nil
+	done bool
+}
+
+// New 用拉取函数创建数据流，pull 返回 false 表示流已结束
+func New[T any](pull func() (T, bool)) *Stream[T] {
+	return &Stream[T]{pull: pull}
+}
+
+// derive 基于上游创建新的数据流，关闭时一并关闭上游
+func derive[T, U any](upstream *Stream[T], pull func() (U, bool)) *Stream[U] {
+	return &Stream[U]{pull: pull, stop: upstream.Close}
+}
+
+// Next 拉取下一个元素，流结束后一直返回 false
+func (s *Stream[T]) Next() (T, bool) {
+	var zero T
+	if s.done {
+		return zero, false
+	}
+	v, ok := s.pull()
+	if !ok {
+		s.Close()
+		return zero, false
+	}
+	return v, true
+}
+
+// Close 结束数据流并释放源头的资源，可以重复调用
+// 终端操作结束时会自动调用，只有提前放弃消费时才需要手动调用
+func (s *Stream[T]) Close() {
+	if s.done {
+		return
+	}
+	s.done = true
+	if s.stop != nil {
+		s.stop()
+	}
+}
+
+// FromSlice 从切片创建数据流
+func FromSlice[T any](items []T) *Stream[T] {
+	i := 0
+	return New(func() (T, bool) {
+		if i >= len(items) {
+			var zero T
+			return zero, false
+		}
+		i++
+		return items[i-1], true
+	})
+}
+
+// FromIterator 把 behavioral/iterator 的迭代器桥接为数据流
+func FromIterator[T any](it iterator.Iterator[T]) *Stream[T] {
+	return New(it.Next)
+}
+
+// FromSeq 把 Go 的 iter.Seq 桥接为拉取式数据流
+// 提前放弃消费时需要调用 Close，否则 iter.Pull 创建的协程不会退出
+func FromSeq[T any](seq iter.Seq[T]) *Stream[T] {
+	next, stop := iter.Pull(seq)
+	return &Stream[T]{pull: next, stop: stop}
+}
+
+// Seq 把数据流转换为 iter.Seq，可以直接用于 for range，循环结束时自动关闭数据流
+func (s *Stream[T]) Seq() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		defer s.Close()
+		for {
+			v, ok := s.Next()
+			if !ok || !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Map 对每个元素应用 f，f 只在元素被拉取时才调用
+func Map[T, U any](s *Stream[T], f func(T) U) *Stream[U] {
+	return derive(s, func() (U, bool) {
+		v, ok := s.Next()
+		if !ok {
+			var zero U
+			return zero, false
+		}
+		return f(v), true
+	})
+}
+
+// Filter 只保留满足条件的元素
+func (s *Stream[T]) Filter(keep func(T) bool) *Stream[T] {
+	return derive(s, func() (T, bool) {
+		for {
+			v, ok := s.Next()
+			if !ok || keep(v) {
+				return v, ok
+			}
+		}
+	})
+}
+
+// Take 只取前 n 个元素，取够之后不再拉取上游
+func (s *Stream[T]) Take(n int) *Stream[T] {
+	taken := 0
+	return derive(s, func() (T, bool) {
+		if taken >= n {
+			var zero T
+			return zero, false
+		}
+		taken++
+		return s.Next()
+	})
+}
+
+// TakeWhile 取元素直到条件第一次不满足
+func (s *Stream[T]) TakeWhile(keep func(T) bool) *Stream[T] {
+	return derive(s, func() (T, bool) {
+		v, ok := s.Next()
+		if !ok || !keep(v) {
+			var zero T
+			return zero, false
+		}
+		return v, true
+	})
+}
+
+// Skip 跳过前 n 个元素
+func (s *Stream[T]) Skip(n int) *Stream[T] {
+	return derive(s, func() (T, bool) {
+		for ; n > 0; n-- {
+			if _, ok := s.Next(); !ok {
+				break
+			}
+		}
+		return s.Next()
+	})
+}
+
+// Peek 元素被拉取时调用 f，常用于观察惰性求值的过程
+func (s *Stream[T]) Peek(f func(T)) *Stream[T] {
+	return derive(s, func() (T, bool) {
+		v, ok := s.Next()
+		if ok {
+			f(v)
+		}
+		return v, ok
+	})
+}
+
+// Reduce 终端操作：从 initial 开始依次累积所有元素
+func Reduce[T, A any](s *Stream[T], initial A, f func(A, T) A) A {
+	defer s.Close()
+	acc := initial
+	for v, ok := s.Next(); ok; v, ok = s.Next() {
+		acc = f(acc, v)
+	}
+	return acc
+}
+
+// Collect 终端操作：把所有元素收集到切片中
+func (s *Stream[T]) Collect() []T {
+	return Reduce(s, []T(nil), func(items []T, v T) []T { return append(items, v) })
+}
+
+// ForEach 终端操作：对每个元素调用 f
+func (s *Stream[T]) ForEach(f func(T)) {
+	for v := range s.Seq() {
+		f(v)
+	}
+}
+
+// Count 终端操作：统计元素个数
+func (s *Stream[T]) Count() int {
+	return Reduce(s, 0, func(n int, _ T) int { return n + 1 })
+}
+
+// First 终端操作：返回第一个元素，只拉取一个元素
+func (s *Stream[T]) First() (T, bool) {
+	defer s.Close()
+	return s.Next()
+}
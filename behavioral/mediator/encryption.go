@@ -0,0 +1,224 @@
+package mediator
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 端到端加密相关错误
+var (
+	ErrNoPublicKey        = errors.New("参与者没有登记公钥")
+	ErrInvalidPublicKey   = errors.New("公钥不是 X25519 公钥")
+	ErrEncryptionDisabled = errors.New("参与者没有启用加密")
+	ErrNoKeyDirectory     = errors.New("中介者不支持登记公钥")
+	ErrDecryptFailed      = errors.New("无法解密消息")
+	ErrEncryptedBroadcast = errors.New("加密消息必须指定接收者")
+)
+
+// keyInfo 派生消息密钥时使用的上下文，区分本示例与其他用途的密钥
+const keyInfo = "go-design-pattern/mediator 私信"
+
+// KeyDirectory 登记和查询参与者公钥的中介者
+// 中介者只转发公钥和密文，没有任何参与者的私钥，因此无法读取加密消息
+type KeyDirectory interface {
+	RegisterKey(id string, key *ecdh.PublicKey) error // 登记或更换公钥
+	PublicKey(id string) (*ecdh.PublicKey, bool)      // 查询公钥
+}
+
+// KeyPair 参与者的 X25519 密钥对，私钥不会离开参与者
+type KeyPair struct {
+	private *ecdh.PrivateKey
+}
+
+// NewKeyPair 生成新的 X25519 密钥对
+func NewKeyPair() (*KeyPair, error) {
+	private, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyPair{private: private}, nil
+}
+
+// PublicKey 返回公钥，可以公开登记到中介者
+func (k *KeyPair) PublicKey() *ecdh.PublicKey {
+	return k.private.PublicKey()
+}
+
+// SharedKey 与对方公钥协商出发送者到接收者方向的 AES-256 消息密钥
+// 双方用各自的私钥和对方的公钥计算出相同的密钥：ECDH 共享秘密经 HKDF-SHA256 派生，
+// 派生时绑定发送者和接收者的ID，同一对参与者两个方向的密钥不同
+func (k *KeyPair) SharedKey(peer *ecdh.PublicKey, sender, recipient string) ([]byte, error) {
+	if peer == nil || peer.Curve() != ecdh.X25519() {
+		return nil, ErrInvalidPublicKey
+	}
+	secret, err := k.private.ECDH(peer)
+	if err != nil {
+		return nil, err
+	}
+	return hkdf.Key(sha256.New, secret, nil, keyInfo+":"+sender+"->"+recipient, 32)
+}
+
+// Seal 用 AES-GCM 加密明文，返回 base64 编码的随机数和密文
+// 发送者和接收者作为附加数据参与认证，篡改消息的收发双方会导致解密失败
+func Seal(key []byte, plaintext, sender, recipient string) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(sender+"->"+recipient))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open 解密 Seal 生成的密文，密钥、收发双方不匹配或密文被篡改时返回 ErrDecryptFailed
+func Open(key []byte, ciphertext, sender, recipient string) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("%w: 密文格式不正确", ErrDecryptFailed)
+	}
+	nonce, body := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, body, []byte(sender+"->"+recipient))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrDecryptFailed, err)
+	}
+	return string(plaintext), nil
+}
+
+// newAEAD 创建 AES-GCM 加密器
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// RegisterKey 登记参与者的公钥，重复登记会替换旧公钥（更换密钥）
+func (c *ChatRoom) RegisterKey(id string, key *ecdh.PublicKey) error {
+	if key == nil || key.Curve() != ecdh.X25519() {
+		return fmt.Errorf("%w: %s", ErrInvalidPublicKey, id)
+	}
+
+	c.mutex.Lock()
+	c.keys[id] = key
+	c.mutex.Unlock()
+
+	c.log().Info(fmt.Sprintf("%s 登记了公钥", id), "room", c.name, "event", "key", "colleague", id)
+	return nil
+}
+
+// PublicKey 查询参与者登记的公钥
+func (c *ChatRoom) PublicKey(id string) (*ecdh.PublicKey, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	key, ok := c.keys[id]
+	return key, ok
+}
+
+// keyDirectory 返回用户的中介者的公钥目录
+func (u *User) keyDirectory() (KeyDirectory, error) {
+	directory, ok := u.mediator.(KeyDirectory)
+	if !ok {
+		return nil, ErrNoKeyDirectory
+	}
+	return directory, nil
+}
+
+// EnableEncryption 生成密钥对并把公钥登记到中介者，再次调用会更换密钥
+// 更换密钥后，用旧公钥加密、尚未投递的消息将无法解密
+func (u *User) EnableEncryption() error {
+	directory, err := u.keyDirectory()
+	if err != nil {
+		return err
+	}
+	keys, err := NewKeyPair()
+	if err != nil {
+		return err
+	}
+	if err := directory.RegisterKey(u.id, keys.PublicKey()); err != nil {
+		return err
+	}
+	u.keys = keys
+	return nil
+}
+
+// SendEncrypted 用接收者登记的公钥加密内容并通过中介者发送私信
+// 中介者只能看到收发双方和密文
+func (u *User) SendEncrypted(content string, recipient string) error {
+	if u.keys == nil {
+		return ErrEncryptionDisabled
+	}
+	directory, err := u.keyDirectory()
+	if err != nil {
+		return err
+	}
+	peer, ok := directory.PublicKey(recipient)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNoPublicKey, recipient)
+	}
+
+	key, err := u.keys.SharedKey(peer, u.id, recipient)
+	if err != nil {
+		return err
+	}
+	ciphertext, err := Seal(key, content, u.id, recipient)
+	if err != nil {
+		return err
+	}
+
+	u.mediator.Send(Message{
+		Type:      TextMessage,
+		Content:   ciphertext,
+		Sender:    u.id,
+		Recipient: recipient,
+		Timestamp: time.Now(),
+		Encrypted: true,
+	})
+	return nil
+}
+
+// Decrypt 用发送者登记的公钥解密发给自己的消息，返回内容为明文的消息，没有加密的消息原样返回
+func (u *User) Decrypt(message Message) (Message, error) {
+	if !message.Encrypted {
+		return message, nil
+	}
+	if u.keys == nil {
+		return message, ErrEncryptionDisabled
+	}
+	directory, err := u.keyDirectory()
+	if err != nil {
+		return message, err
+	}
+	peer, ok := directory.PublicKey(message.Sender)
+	if !ok {
+		return message, fmt.Errorf("%w: %s", ErrNoPublicKey, message.Sender)
+	}
+
+	key, err := u.keys.SharedKey(peer, message.Sender, u.id)
+	if err != nil {
+		return message, err
+	}
+	plaintext, err := Open(key, message.Content, message.Sender, u.id)
+	if err != nil {
+		return message, err
+	}
+	message.Content = plaintext
+	message.Encrypted = false
+	return message, nil
+}
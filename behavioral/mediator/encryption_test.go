@@ -0,0 +1,129 @@
+package mediator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// secureUser 记录解密结果的用户
+type secureUser struct {
+	*User
+	received []Message
+	errors   []error
+}
+
+func (s *secureUser) Receive(message Message) {
+	plain, err := s.Decrypt(message)
+	if err != nil {
+		s.errors = append(s.errors, err)
+		return
+	}
+	s.received = append(s.received, plain)
+}
+
+// newSecureRoom 创建关闭日志的聊天室，并注册启用了加密的用户
+func newSecureRoom(t *testing.T, ids ...string) (*ChatRoom, map[string]*secureUser) {
+	t.Helper()
+	chatRoom := NewChatRoom("加密测试组")
+	chatRoom.SetLogger(nil)
+
+	users := make(map[string]*secureUser, len(ids))
+	for _, id := range ids {
+		user := &secureUser{User: NewUser(id, id, "member")}
+		chatRoom.Register(user)
+		user.SetMediator(chatRoom)
+		assert.NoError(t, user.EnableEncryption())
+		users[id] = user
+	}
+	return chatRoom, users
+}
+
+// 测试加密私信只有接收者能够解密
+func TestEncryptedDirectMessage(t *testing.T) {
+	chatRoom, u := newSecureRoom(t, "alice", "bob", "carol")
+	eve := NewMessageCollector("eve", "eve")
+	chatRoom.Register(eve)
+
+	assert.NoError(t, u["alice"].SendEncrypted("今晚八点发布", "bob"))
+	if assert.Len(t, u["bob"].received, 1) {
+		msg := u["bob"].received[0]
+		assert.Equal(t, "今晚八点发布", msg.Content)
+		assert.Equal(t, "alice", msg.Sender)
+		assert.False(t, msg.Encrypted)
+	}
+	assert.Empty(t, eve.GetMessages(), "私信不会投递给其他人")
+	assert.Empty(t, u["carol"].received)
+	assert.Equal(t, 1, chatRoom.Stats().Routed[TextMessage])
+
+	// 中介者转发的密文不包含明文，拿到密文的第三方也无法解密
+	sealed := captureCiphertext(t, chatRoom, u["alice"].User, "bob", "密码是 hunter2")
+	assert.NotContains(t, sealed, "hunter2")
+	ciphertext := Message{Type: TextMessage, Content: sealed, Sender: "alice", Recipient: "bob", Encrypted: true}
+
+	_, err := u["carol"].Decrypt(ciphertext)
+	assert.ErrorIs(t, err, ErrDecryptFailed, "第三方的密钥无法解密")
+	plain, err := u["bob"].Decrypt(ciphertext)
+	assert.NoError(t, err)
+	assert.Equal(t, "密码是 hunter2", plain.Content)
+
+	// 冒充发送者会导致认证失败
+	forged := ciphertext
+	forged.Sender = "carol"
+	_, err = u["bob"].Decrypt(forged)
+	assert.ErrorIs(t, err, ErrDecryptFailed)
+}
+
+// captureCiphertext 加密一条消息并返回中介者转发的密文
+func captureCiphertext(t *testing.T, chatRoom *ChatRoom, sender *User, recipient, content string) string {
+	t.Helper()
+	peer, ok := chatRoom.PublicKey(recipient)
+	assert.True(t, ok)
+	key, err := sender.keys.SharedKey(peer, sender.GetID(), recipient)
+	assert.NoError(t, err)
+	sealed, err := Seal(key, content, sender.GetID(), recipient)
+	assert.NoError(t, err)
+	return sealed
+}
+
+// 测试缺少密钥、加密广播以及更换密钥
+func TestEncryptedMessageErrors(t *testing.T) {
+	chatRoom, u := newSecureRoom(t, "alice", "bob")
+
+	plain := NewUser("dave", "dave", "member")
+	chatRoom.Register(plain)
+	plain.SetMediator(chatRoom)
+	assert.ErrorIs(t, plain.SendEncrypted("你好", "alice"), ErrEncryptionDisabled)
+	assert.ErrorIs(t, u["alice"].SendEncrypted("你好", "dave"), ErrNoPublicKey)
+	assert.ErrorIs(t, NewUser("x", "x", "member").EnableEncryption(), ErrNoKeyDirectory)
+	assert.ErrorIs(t, chatRoom.RegisterKey("x", nil), ErrInvalidPublicKey)
+
+	// 中介者拒绝没有接收者的加密消息
+	chatRoom.Send(Message{Type: TextMessage, Content: "密文", Sender: "alice", Encrypted: true})
+	assert.Empty(t, u["bob"].received)
+	assert.Equal(t, 1, chatRoom.Stats().Undeliverable)
+
+	// bob 更换密钥后，用旧密钥加密的消息无法解密，新消息正常
+	stale := captureCiphertext(t, chatRoom, u["alice"].User, "bob", "旧消息")
+	assert.NoError(t, u["bob"].EnableEncryption())
+	chatRoom.Send(Message{Type: TextMessage, Content: stale, Sender: "alice", Recipient: "bob", Encrypted: true})
+	if assert.Len(t, u["bob"].errors, 1) {
+		assert.ErrorIs(t, u["bob"].errors[0], ErrDecryptFailed)
+	}
+	assert.NoError(t, u["alice"].SendEncrypted("新消息", "bob"))
+	if assert.Len(t, u["bob"].received, 1) {
+		assert.Equal(t, "新消息", u["bob"].received[0].Content)
+	}
+}
+
+// 测试中介者的日志中不包含明文
+func TestEncryptedMessageLogging(t *testing.T) {
+	chatRoom, u := newSecureRoom(t, "alice", "bob")
+	var log strings.Builder
+	chatRoom.SetLogger(NewConsoleLogger("加密测试组", &log))
+
+	assert.NoError(t, u["alice"].SendEncrypted("机密内容", "bob"))
+	assert.Contains(t, log.String(), "来自 alice 的加密消息")
+	assert.NotContains(t, log.String(), "机密内容")
+}
@@ -1,7 +1,9 @@
 package mediator
 
 import (
+	"crypto/ecdh"
 	"fmt"
+	"sync"
 	"time"
 )
 
@@ -21,6 +23,17 @@ type Message struct {
 	Sender    string      // 发送者ID
 	Recipient string      // 接收者ID（空字符串表示广播给所有人）
 	Timestamp time.Time   // 时间戳
+	DeliverAt time.Time   // 计划投递时间（零值表示立即投递）
+	ExpiresAt time.Time   // 过期时间（零值表示永不过期）
+	ID        string      // 消息编号（为空时由聊天室在投递时分配）
+	ThreadID  string      // 所属的会话线程（空字符串表示不属于任何线程）
+	ReplyTo   string      // 回复的消息编号，回复只投递给线程参与者
+	Encrypted bool        // Content 是只有接收者能解密的密文
+}
+
+// IsExpired 检查消息在指定时间是否已过期
+func (m Message) IsExpired(now time.Time) bool {
+	return !m.ExpiresAt.IsZero() && !now.Before(m.ExpiresAt)
 }
 
 // Mediator 定义通信协调的接口
@@ -34,6 +47,15 @@ type Mediator interface {
 type ChatRoom struct {
 	name       string               // 聊天室名称
 	colleagues map[string]Colleague // 参与者映射表
+	mutex      sync.RWMutex         // 保护参与者映射表和计划消息
+	now        func() time.Time     // 时钟，便于测试时替换
+	logger     Logger               // 路由日志
+	stats      *routeStats          // 路由指标
+
+	scheduler messageScheduler           // 计划投递的消息
+	threads   threadIndex                // 会话线程
+	routing   routingTable               // 广播消息的路由规则
+	keys      map[string]*ecdh.PublicKey // 参与者登记的公钥
 }
 
 // NewChatRoom 创建一个新的聊天室中介者
@@ -41,54 +63,132 @@ func NewChatRoom(name string) *ChatRoom {
 	return &ChatRoom{
 		name:       name,
 		colleagues: make(map[string]Colleague),
+		now:        time.Now,
+		logger:     NewConsoleLogger(name, nil),
+		stats:      newRouteStats(),
+		scheduler:  newMessageScheduler(),
+		threads:    newThreadIndex(),
+		keys:       make(map[string]*ecdh.PublicKey),
 	}
 }
 
 // Register 将参与者添加到中介者的注册表中
 func (c *ChatRoom) Register(colleague Colleague) {
+	c.mutex.Lock()
 	c.colleagues[colleague.GetID()] = colleague
-	fmt.Printf("[%s] %s 已加入聊天室\n", c.name, colleague.GetName())
+	c.mutex.Unlock()
+	c.log().Info(fmt.Sprintf("%s 已加入聊天室", colleague.GetName()),
+		"room", c.name, "event", "join", "colleague", colleague.GetID())
 }
 
 // Unregister 从中介者的注册表中移除参与者
 func (c *ChatRoom) Unregister(colleague Colleague) {
-	if _, exists := c.colleagues[colleague.GetID()]; exists {
-		delete(c.colleagues, colleague.GetID())
-		fmt.Printf("[%s] %s 已离开聊天室\n", c.name, colleague.GetName())
+	c.mutex.Lock()
+	_, exists := c.colleagues[colleague.GetID()]
+	delete(c.colleagues, colleague.GetID())
+	c.mutex.Unlock()
+
+	if exists {
+		c.log().Info(fmt.Sprintf("%s 已离开聊天室", colleague.GetName()),
+			"room", c.name, "event", "leave", "colleague", colleague.GetID())
 	}
 }
 
 // Send 将消息分发给适当的接收者
+// 带有未来投递时间的消息会被放入计划队列，已过期的消息会被丢弃
 func (c *ChatRoom) Send(message Message) {
+	now := c.now()
 	if message.Timestamp.IsZero() {
-		message.Timestamp = time.Now()
+		message.Timestamp = now
+	}
+
+	if message.IsExpired(now) {
+		c.stats.recordExpired(1)
+		c.log().Warn(fmt.Sprintf("消息已过期，丢弃来自 %s 的消息", message.Sender),
+			"room", c.name, "event", "expired", "sender", message.Sender, "type", message.Type.String())
+		return
+	}
+	if message.DeliverAt.After(now) {
+		c.Schedule(message)
+		return
+	}
+
+	c.deliver(message)
+}
+
+// deliver 立即投递消息
+func (c *ChatRoom) deliver(message Message) {
+	logger := c.log()
+
+	// 中介者无法读取加密消息，也就无法替接收者以外的参与者重新加密
+	if message.Encrypted && message.Recipient == "" {
+		c.stats.recordUndeliverable()
+		logger.Warn(fmt.Sprintf("错误: %s", ErrEncryptedBroadcast),
+			"room", c.name, "event", "undeliverable", "sender", message.Sender)
+		return
+	}
+
+	message, participants, reply, err := c.threadMessage(message)
+	if err != nil {
+		c.stats.recordUndeliverable()
+		logger.Warn(fmt.Sprintf("错误: %s", err),
+			"room", c.name, "event", "undeliverable", "sender", message.Sender, "replyTo", message.ReplyTo)
+		return
 	}
 
 	// 记录消息
+	var summary string
 	switch message.Type {
 	case TextMessage:
-		fmt.Printf("[%s] 来自 %s 的消息: %s\n", c.name, message.Sender, message.Content)
+		summary = fmt.Sprintf("来自 %s 的消息: %s", message.Sender, message.Content)
 	case CommandMessage:
-		fmt.Printf("[%s] 来自 %s 的命令: %s\n", c.name, message.Sender, message.Content)
+		summary = fmt.Sprintf("来自 %s 的命令: %s", message.Sender, message.Content)
 	case NotificationMessage:
-		fmt.Printf("[%s] 通知: %s\n", c.name, message.Content)
+		summary = fmt.Sprintf("通知: %s", message.Content)
+	}
+	if message.Encrypted {
+		summary = fmt.Sprintf("来自 %s 的加密消息", message.Sender)
+	}
+	if summary != "" {
+		logger.Info(summary, "room", c.name, "event", "message",
+			"type", message.Type.String(), "sender", message.Sender, "recipient", message.Recipient,
+			"thread", message.ThreadID)
+	}
+
+	// 线程中的回复只投递给线程参与者，加密的回复只有接收者能解密，按私信投递
+	if reply && !message.Encrypted {
+		c.deliverToThread(message, participants)
+		return
 	}
 
 	// 将消息发送给适当的接收者
 	if message.Recipient != "" {
 		// 发送直接消息给特定接收者
-		if recipient, exists := c.colleagues[message.Recipient]; exists {
-			recipient.Receive(message)
-		} else {
-			fmt.Printf("[%s] 错误: 接收者 %s 未找到\n", c.name, message.Recipient)
-		}
-	} else {
-		// 广播消息给除发送者外的所有参与者
-		for id, colleague := range c.colleagues {
-			if id != message.Sender {
-				colleague.Receive(message)
-			}
+		c.mutex.RLock()
+		recipient, exists := c.colleagues[message.Recipient]
+		c.mutex.RUnlock()
+
+		if !exists {
+			c.stats.recordUndeliverable()
+			logger.Warn(fmt.Sprintf("错误: 接收者 %s 未找到", message.Recipient),
+				"room", c.name, "event", "undeliverable", "sender", message.Sender, "recipient", message.Recipient)
+			return
 		}
+		c.stats.recordRouted(message, []string{message.Recipient})
+		recipient.Receive(message)
+		return
+	}
+
+	// 按路由规则广播消息，没有规则时广播给除发送者外的所有参与者
+	// 在锁外投递，参与者可以在 Receive 中再次通过中介者发送消息
+	colleagues := c.routeColleagues(message)
+	ids := make([]string, len(colleagues))
+	for i, colleague := range colleagues {
+		ids[i] = colleague.GetID()
+	}
+	c.stats.recordRouted(message, ids)
+	for _, colleague := range colleagues {
+		colleague.Receive(message)
 	}
 }
 
@@ -111,7 +211,8 @@ type BaseColleague struct {
 // User 是表示聊天用户的具体参与者
 type User struct {
 	BaseColleague
-	role string // 用户角色
+	role string   // 用户角色
+	keys *KeyPair // 端到端加密的密钥对，为 nil 时不能收发加密消息
 }
 
 // NewUser 创建一个新的用户参与者
@@ -135,6 +236,11 @@ func (u *User) GetName() string {
 	return u.name
 }
 
+// GetRole 返回用户的角色
+func (u *User) GetRole() string {
+	return u.role
+}
+
 // Send 创建消息并通过中介者发送
 func (u *User) Send(content string, messageType MessageType, recipient string) {
 	if u.mediator == nil {
@@ -153,8 +259,14 @@ func (u *User) Send(content string, messageType MessageType, recipient string) {
 	u.mediator.Send(message)
 }
 
-// Receive 处理接收到的消息
+// Receive 处理接收到的消息，加密消息先解密
 func (u *User) Receive(message Message) {
+	message, err := u.Decrypt(message)
+	if err != nil {
+		fmt.Printf("[%s (%s)] 无法读取来自 %s 的加密消息: %v\n", u.name, u.role, message.Sender, err)
+		return
+	}
+
 	switch message.Type {
 	case TextMessage:
 		fmt.Printf("[%s (%s)] 收到来自 %s 的消息: %s\n",
@@ -200,6 +312,11 @@ func (b *Bot) GetName() string {
 	return b.name
 }
 
+// GetRole 返回机器人的角色，所有机器人的角色都是 "bot"
+func (b *Bot) GetRole() string {
+	return "bot"
+}
+
 // Send 创建消息并通过中介者发送
 func (b *Bot) Send(content string, messageType MessageType, recipient string) {
 	if b.mediator == nil {
@@ -0,0 +1,218 @@
+package mediator
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+)
+
+// String 返回消息类型名称
+func (t MessageType) String() string {
+	switch t {
+	case TextMessage:
+		return "text"
+	case CommandMessage:
+		return "command"
+	case NotificationMessage:
+		return "notification"
+	default:
+		return fmt.Sprintf("MessageType(%d)", int(t))
+	}
+}
+
+// Logger 中介者使用的日志接口
+// 方法签名与 *slog.Logger 一致，可以直接传入 slog.Default() 获得结构化日志；
+// args 是交替出现的键值对，例如 "sender", "alice", "recipients", 3
+type Logger interface {
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+}
+
+// consoleLogger 默认日志实现，以 "[聊天室] 消息" 的格式输出到控制台，忽略键值对
+type consoleLogger struct {
+	room string
+	out  io.Writer
+}
+
+// NewConsoleLogger 创建输出到 w 的控制台日志，w 为 nil 时输出到标准输出
+func NewConsoleLogger(room string, w io.Writer) Logger {
+	if w == nil {
+		w = os.Stdout
+	}
+	return &consoleLogger{room: room, out: w}
+}
+
+// Info 输出普通日志
+func (l *consoleLogger) Info(msg string, args ...any) {
+	fmt.Fprintf(l.out, "[%s] %s\n", l.room, msg)
+}
+
+// Warn 输出警告日志
+func (l *consoleLogger) Warn(msg string, args ...any) {
+	fmt.Fprintf(l.out, "[%s] %s\n", l.room, msg)
+}
+
+// nopLogger 丢弃所有日志
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...any) {}
+func (nopLogger) Warn(string, ...any) {}
+
+// SetLogger 设置聊天室的日志实现，传入 nil 表示关闭日志
+func (c *ChatRoom) SetLogger(logger Logger) {
+	if logger == nil {
+		logger = nopLogger{}
+	}
+	c.mutex.Lock()
+	c.logger = logger
+	c.mutex.Unlock()
+}
+
+// log 返回当前日志实现
+func (c *ChatRoom) log() Logger {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.logger
+}
+
+// ColleagueStats 单个参与者的收发计数
+type ColleagueStats struct {
+	Sent     int // 经中介者路由的、由该参与者发出的消息数
+	Received int // 该参与者收到的消息数
+}
+
+// Stats 中介者的路由指标快照
+type Stats struct {
+	Routed        map[MessageType]int       // 按消息类型统计的已路由消息数
+	Broadcasts    int                       // 广播次数
+	FanOut        map[int]int               // 广播扇出分布：接收者数量 -> 广播次数
+	Undeliverable int                       // 因接收者不存在而无法投递的消息数
+	Expired       int                       // 因过期被丢弃的消息数
+	Colleagues    map[string]ColleagueStats // 按参与者ID统计的收发计数
+}
+
+// TotalRouted 返回已路由消息总数
+func (s Stats) TotalRouted() int {
+	total := 0
+	for _, n := range s.Routed {
+		total += n
+	}
+	return total
+}
+
+// AverageFanOut 返回广播的平均扇出，没有广播时返回 0
+func (s Stats) AverageFanOut() float64 {
+	if s.Broadcasts == 0 {
+		return 0
+	}
+	recipients := 0
+	for size, n := range s.FanOut {
+		recipients += size * n
+	}
+	return float64(recipients) / float64(s.Broadcasts)
+}
+
+// String 返回指标的可读摘要
+func (s Stats) String() string {
+	types := make([]MessageType, 0, len(s.Routed))
+	for t := range s.Routed {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	result := fmt.Sprintf("已路由 %d 条消息", s.TotalRouted())
+	for _, t := range types {
+		result += fmt.Sprintf(" %s=%d", t, s.Routed[t])
+	}
+	result += fmt.Sprintf("，广播 %d 次(平均扇出 %.1f)，无法投递 %d 条，过期 %d 条",
+		s.Broadcasts, s.AverageFanOut(), s.Undeliverable, s.Expired)
+	return result
+}
+
+// routeStats 记录路由指标，使用独立的锁，避免投递路径与参与者注册互相阻塞
+type routeStats struct {
+	mu            sync.Mutex
+	routed        map[MessageType]int
+	broadcasts    int
+	fanOut        map[int]int
+	undeliverable int
+	expired       int
+	colleagues    map[string]ColleagueStats
+}
+
+// newRouteStats 创建空的路由指标
+func newRouteStats() *routeStats {
+	return &routeStats{
+		routed:     make(map[MessageType]int),
+		fanOut:     make(map[int]int),
+		colleagues: make(map[string]ColleagueStats),
+	}
+}
+
+// recordRouted 记录一条已路由的消息及其实际接收者
+func (s *routeStats) recordRouted(message Message, recipients []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.routed[message.Type]++
+	if message.Recipient == "" {
+		s.broadcasts++
+		s.fanOut[len(recipients)]++
+	}
+	if message.Sender != "" {
+		sender := s.colleagues[message.Sender]
+		sender.Sent++
+		s.colleagues[message.Sender] = sender
+	}
+	for _, id := range recipients {
+		recipient := s.colleagues[id]
+		recipient.Received++
+		s.colleagues[id] = recipient
+	}
+}
+
+// recordUndeliverable 记录一条无法投递的消息
+func (s *routeStats) recordUndeliverable() {
+	s.mu.Lock()
+	s.undeliverable++
+	s.mu.Unlock()
+}
+
+// recordExpired 记录被丢弃的过期消息
+func (s *routeStats) recordExpired(n int) {
+	s.mu.Lock()
+	s.expired += n
+	s.mu.Unlock()
+}
+
+// snapshot 返回指标的深拷贝
+func (s *routeStats) snapshot() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := Stats{
+		Routed:        make(map[MessageType]int, len(s.routed)),
+		Broadcasts:    s.broadcasts,
+		FanOut:        make(map[int]int, len(s.fanOut)),
+		Undeliverable: s.undeliverable,
+		Expired:       s.expired,
+		Colleagues:    make(map[string]ColleagueStats, len(s.colleagues)),
+	}
+	for t, n := range s.routed {
+		stats.Routed[t] = n
+	}
+	for size, n := range s.fanOut {
+		stats.FanOut[size] = n
+	}
+	for id, cs := range s.colleagues {
+		stats.Colleagues[id] = cs
+	}
+	return stats
+}
+
+// Stats 返回聊天室当前的路由指标快照
+func (c *ChatRoom) Stats() Stats {
+	return c.stats.snapshot()
+}
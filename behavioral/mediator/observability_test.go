@@ -0,0 +1,156 @@
+package mediator
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// *slog.Logger 可以直接作为中介者的日志实现
+var _ Logger = slog.Default()
+
+// logEntry 记录的一条日志
+type logEntry struct {
+	level string
+	msg   string
+	attrs map[string]any
+}
+
+// recordingLogger 记录所有日志，便于断言
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+func (l *recordingLogger) record(level, msg string, args []any) {
+	attrs := make(map[string]any)
+	for i := 0; i+1 < len(args); i += 2 {
+		attrs[args[i].(string)] = args[i+1]
+	}
+	l.mu.Lock()
+	l.entries = append(l.entries, logEntry{level: level, msg: msg, attrs: attrs})
+	l.mu.Unlock()
+}
+
+func (l *recordingLogger) Info(msg string, args ...any) { l.record("info", msg, args) }
+func (l *recordingLogger) Warn(msg string, args ...any) { l.record("warn", msg, args) }
+
+// byEvent 返回指定事件的日志
+func (l *recordingLogger) byEvent(event string) []logEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var result []logEntry
+	for _, entry := range l.entries {
+		if entry.attrs["event"] == event {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+func TestChatRoom_InjectedLogger(t *testing.T) {
+	logger := &recordingLogger{}
+	room := NewChatRoom("日志测试")
+	room.SetLogger(logger)
+
+	alice := NewUser("alice", "Alice", "成员")
+	bob := NewUser("bob", "Bob", "成员")
+	alice.SetMediator(room)
+	bob.SetMediator(room)
+	room.Register(alice)
+	room.Register(bob)
+
+	alice.Send("你好", TextMessage, "")
+	alice.Send("在吗", TextMessage, "ghost")
+
+	joins := logger.byEvent("join")
+	assert.Len(t, joins, 2)
+	assert.Equal(t, "日志测试", joins[0].attrs["room"])
+
+	messages := logger.byEvent("message")
+	assert.Len(t, messages, 2)
+	assert.Equal(t, "text", messages[0].attrs["type"])
+	assert.Equal(t, "alice", messages[0].attrs["sender"])
+
+	undeliverable := logger.byEvent("undeliverable")
+	if assert.Len(t, undeliverable, 1) {
+		assert.Equal(t, "warn", undeliverable[0].level)
+		assert.Equal(t, "ghost", undeliverable[0].attrs["recipient"])
+	}
+}
+
+func TestChatRoom_ConsoleLoggerAndSlog(t *testing.T) {
+	// 默认控制台格式保持 "[聊天室] 消息"
+	var console bytes.Buffer
+	room := NewChatRoom("控制台")
+	room.SetLogger(NewConsoleLogger("控制台", &console))
+	room.Send(Message{Type: NotificationMessage, Content: "系统维护"})
+	assert.Equal(t, "[控制台] 通知: 系统维护\n", console.String())
+
+	// 结构化日志包含键值对
+	var structured bytes.Buffer
+	room.SetLogger(slog.New(slog.NewTextHandler(&structured, nil)))
+	room.Send(Message{Type: CommandMessage, Content: "!help", Sender: "alice"})
+	assert.True(t, strings.Contains(structured.String(), "event=message"), structured.String())
+	assert.True(t, strings.Contains(structured.String(), "type=command"), structured.String())
+
+	// nil 关闭日志
+	room.SetLogger(nil)
+	room.Send(Message{Type: TextMessage, Content: "静默"})
+}
+
+func TestChatRoom_Stats(t *testing.T) {
+	room := NewChatRoom("指标测试")
+	room.SetLogger(nil)
+
+	users := map[string]*MessageCollector{}
+	for _, id := range []string{"alice", "bob", "carol"} {
+		users[id] = NewMessageCollector(id, id)
+		users[id].SetMediator(room)
+		room.Register(users[id])
+	}
+
+	users["alice"].Send("大家好", TextMessage, "")           // 广播，扇出 2
+	users["bob"].Send("/status", CommandMessage, "carol") // 私聊
+	users["carol"].Send("喂", TextMessage, "dave")         // 无法投递
+	room.Unregister(users["carol"])
+	users["alice"].Send("再见", TextMessage, "") // 广播，扇出 1
+
+	past := time.Now().Add(-time.Minute)
+	room.Send(Message{Type: TextMessage, Content: "过期", Sender: "bob", ExpiresAt: past})
+
+	stats := room.Stats()
+	assert.Equal(t, 2, stats.Routed[TextMessage])
+	assert.Equal(t, 1, stats.Routed[CommandMessage])
+	assert.Equal(t, 3, stats.TotalRouted())
+	assert.Equal(t, 2, stats.Broadcasts)
+	assert.Equal(t, map[int]int{2: 1, 1: 1}, stats.FanOut)
+	assert.InDelta(t, 1.5, stats.AverageFanOut(), 1e-9)
+	assert.Equal(t, 1, stats.Undeliverable)
+	assert.Equal(t, 1, stats.Expired)
+
+	assert.Equal(t, ColleagueStats{Sent: 2, Received: 0}, stats.Colleagues["alice"])
+	assert.Equal(t, ColleagueStats{Sent: 1, Received: 2}, stats.Colleagues["bob"])
+	assert.Equal(t, ColleagueStats{Sent: 0, Received: 2}, stats.Colleagues["carol"])
+
+	// 快照与内部状态相互独立
+	stats.Routed[TextMessage] = 100
+	assert.Equal(t, 2, room.Stats().Routed[TextMessage])
+	assert.Contains(t, room.Stats().String(), "已路由 3 条消息")
+}
+
+func TestChatRoom_StatsScheduledExpiry(t *testing.T) {
+	room := NewChatRoom("计划指标")
+	room.SetLogger(nil)
+	now := time.Now()
+	room.Schedule(Message{Type: TextMessage, Content: "迟到", DeliverAt: now.Add(time.Second), ExpiresAt: now.Add(2 * time.Second)})
+
+	_, expired := room.ProcessDue(now.Add(3 * time.Second))
+	assert.Equal(t, 1, expired)
+	assert.Equal(t, 1, room.Stats().Expired)
+}
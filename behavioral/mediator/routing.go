@@ -0,0 +1,192 @@
+package mediator
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// ErrInvalidRule 表示路由规则不合法
+var ErrInvalidRule = errors.New("路由规则不合法")
+
+// RoleProvider 由带有角色的参与者实现，路由规则按角色匹配发送者和选择接收者
+// 没有实现该接口的参与者角色为空字符串
+type RoleProvider interface {
+	GetRole() string
+}
+
+// RoutingRule 广播消息的路由规则
+//
+// 匹配条件之间是"与"的关系，条件为空表示不限制；SenderRoles、RecipientRoles 和 Content
+// 支持通配符：* 匹配任意长度的字符串，? 匹配单个字符。
+// 规则按 Priority 从高到低依次匹配，优先级相同时先添加的规则优先，第一条匹配的规则决定接收者。
+// 规则只作用于广播消息，指定了接收者的私信和线程中的回复不受影响
+type RoutingRule struct {
+	Name     string // 规则名称，在聊天室中唯一
+	Priority int    // 优先级，数值越大越先匹配
+
+	Types       []MessageType // 匹配的消息类型
+	SenderRoles []string      // 匹配的发送者角色
+	Content     string        // 匹配的消息内容
+
+	RecipientRoles []string // 接收者角色，"*" 表示所有人
+	Recipients     []string // 额外的接收者ID
+}
+
+// matches 判断规则是否匹配消息
+func (r RoutingRule) matches(message Message, senderRole string) bool {
+	if len(r.Types) > 0 && !slices.Contains(r.Types, message.Type) {
+		return false
+	}
+	if len(r.SenderRoles) > 0 && !matchAny(r.SenderRoles, senderRole) {
+		return false
+	}
+	return r.Content == "" || matchWildcard(r.Content, message.Content)
+}
+
+// selects 判断参与者是否属于规则的接收者
+func (r RoutingRule) selects(id, role string) bool {
+	return slices.Contains(r.Recipients, id) || matchAny(r.RecipientRoles, role)
+}
+
+// routingTable 保存聊天室的路由规则，由 ChatRoom 的锁保护
+type routingTable struct {
+	rules    []RoutingRule // 按优先级从高到低排列
+	fallback []string      // 没有规则匹配时的接收者角色，为空时广播给所有人
+}
+
+// AddRoutingRule 添加路由规则，可以在运行时随时调用
+func (c *ChatRoom) AddRoutingRule(rule RoutingRule) error {
+	if rule.Name == "" {
+		return fmt.Errorf("%w: 规则名称不能为空", ErrInvalidRule)
+	}
+	if len(rule.RecipientRoles) == 0 && len(rule.Recipients) == 0 {
+		return fmt.Errorf("%w: 规则 %s 没有指定接收者", ErrInvalidRule, rule.Name)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	table := &c.routing
+	for _, existing := range table.rules {
+		if existing.Name == rule.Name {
+			return fmt.Errorf("%w: 规则 %s 已存在", ErrInvalidRule, rule.Name)
+		}
+	}
+	rule.Types = slices.Clone(rule.Types)
+	rule.SenderRoles = slices.Clone(rule.SenderRoles)
+	rule.RecipientRoles = slices.Clone(rule.RecipientRoles)
+	rule.Recipients = slices.Clone(rule.Recipients)
+
+	// 插入到第一条优先级更低的规则之前，保持同优先级规则的添加顺序
+	i := sort.Search(len(table.rules), func(i int) bool {
+		return table.rules[i].Priority < rule.Priority
+	})
+	table.rules = slices.Insert(table.rules, i, rule)
+	return nil
+}
+
+// RemoveRoutingRule 删除指定名称的路由规则，规则不存在时返回 false
+func (c *ChatRoom) RemoveRoutingRule(name string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for i, rule := range c.routing.rules {
+		if rule.Name == name {
+			c.routing.rules = slices.Delete(c.routing.rules, i, i+1)
+			return true
+		}
+	}
+	return false
+}
+
+// RoutingRules 返回当前的路由规则，按匹配顺序排列
+func (c *ChatRoom) RoutingRules() []RoutingRule {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return slices.Clone(c.routing.rules)
+}
+
+// SetDefaultRoute 设置没有规则匹配时接收广播的角色，不传参数时恢复为广播给所有人
+func (c *ChatRoom) SetDefaultRoute(roles ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.routing.fallback = slices.Clone(roles)
+}
+
+// routeColleagues 按路由规则选出广播消息的接收者（不包括发送者），没有规则匹配时使用默认路由
+func (c *ChatRoom) routeColleagues(message Message) []Colleague {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	table := &c.routing
+	var senderRole string
+	if sender, ok := c.colleagues[message.Sender]; ok {
+		senderRole = roleOf(sender)
+	}
+
+	selects := func(id, role string) bool {
+		return len(table.fallback) == 0 || matchAny(table.fallback, role)
+	}
+	for _, rule := range table.rules {
+		if rule.matches(message, senderRole) {
+			selects = rule.selects
+			break
+		}
+	}
+
+	colleagues := make([]Colleague, 0, len(c.colleagues))
+	for id, colleague := range c.colleagues {
+		if id != message.Sender && selects(id, roleOf(colleague)) {
+			colleagues = append(colleagues, colleague)
+		}
+	}
+	return colleagues
+}
+
+// roleOf 返回参与者的角色，没有角色时返回空字符串
+func roleOf(colleague Colleague) string {
+	if provider, ok := colleague.(RoleProvider); ok {
+		return provider.GetRole()
+	}
+	return ""
+}
+
+// matchAny 判断 s 是否匹配任意一个通配符模式
+func matchAny(patterns []string, s string) bool {
+	for _, pattern := range patterns {
+		if matchWildcard(pattern, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchWildcard 判断 s 是否匹配通配符模式：* 匹配任意长度的字符串，? 匹配单个字符
+func matchWildcard(pattern, s string) bool {
+	p, str := []rune(pattern), []rune(s)
+	// star 记录最近一个 * 的位置，match 记录它当前匹配到的 str 位置，失配时回溯
+	star, match := -1, 0
+	i, j := 0, 0
+	for j < len(str) {
+		switch {
+		case i < len(p) && (p[i] == '?' || p[i] == str[j]):
+			i++
+			j++
+		case i < len(p) && p[i] == '*':
+			star, match = i, j
+			i++
+		case star >= 0:
+			i = star + 1
+			match++
+			j = match
+		default:
+			return false
+		}
+	}
+	for i < len(p) && p[i] == '*' {
+		i++
+	}
+	return i == len(p)
+}
@@ -0,0 +1,156 @@
+package mediator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// roleCollector 带有角色的消息收集器
+type roleCollector struct {
+	*MessageCollector
+	role string
+}
+
+func (r *roleCollector) GetRole() string {
+	return r.role
+}
+
+// newRoutingTestRoom 创建关闭日志的聊天室，并按 id -> 角色 注册消息收集器
+func newRoutingTestRoom(roles map[string]string) (*ChatRoom, map[string]*roleCollector) {
+	chatRoom := NewChatRoom("路由测试组")
+	chatRoom.SetLogger(nil)
+
+	collectors := make(map[string]*roleCollector, len(roles))
+	for id, role := range roles {
+		collector := &roleCollector{MessageCollector: NewMessageCollector(id, id), role: role}
+		chatRoom.Register(collector)
+		collectors[id] = collector
+	}
+	return chatRoom, collectors
+}
+
+// received 返回收到消息的参与者ID集合
+func received(collectors map[string]*roleCollector) map[string]int {
+	counts := make(map[string]int)
+	for id, collector := range collectors {
+		if n := len(collector.GetMessages()); n > 0 {
+			counts[id] = n
+		}
+	}
+	return counts
+}
+
+// 测试按消息类型和角色路由
+func TestRoutingRuleByTypeAndRole(t *testing.T) {
+	chatRoom, c := newRoutingTestRoom(map[string]string{
+		"admin": "admin", "mod": "moderator", "alice": "member", "bob": "member", "system": "bot",
+	})
+	assert.NoError(t, chatRoom.AddRoutingRule(RoutingRule{
+		Name:           "notifications-to-staff",
+		Types:          []MessageType{NotificationMessage},
+		RecipientRoles: []string{"admin", "mod*"},
+	}))
+
+	chatRoom.Send(Message{Type: NotificationMessage, Content: "服务器即将重启", Sender: "system"})
+	assert.Equal(t, map[string]int{"admin": 1, "mod": 1}, received(c), "通知只应发给管理员和版主")
+
+	// 不匹配的消息仍然广播给所有人
+	chatRoom.Send(Message{Type: TextMessage, Content: "大家好", Sender: "alice"})
+	assert.Equal(t, map[string]int{"admin": 2, "mod": 2, "bob": 1, "system": 1}, received(c))
+
+	// 私信不受路由规则影响
+	chatRoom.Send(Message{Type: NotificationMessage, Content: "你的工单已处理", Sender: "system", Recipient: "bob"})
+	assert.Len(t, c["bob"].GetMessages(), 2)
+}
+
+// 测试规则优先级、内容通配符和发送者角色
+func TestRoutingRulePriority(t *testing.T) {
+	chatRoom, c := newRoutingTestRoom(map[string]string{
+		"admin": "admin", "alice": "member", "bob": "member", "oncall": "sre",
+	})
+	assert.NoError(t, chatRoom.AddRoutingRule(RoutingRule{
+		Name:           "members-chat",
+		Priority:       1,
+		SenderRoles:    []string{"member"},
+		RecipientRoles: []string{"member"},
+	}))
+	assert.NoError(t, chatRoom.AddRoutingRule(RoutingRule{
+		Name:           "alerts",
+		Priority:       10,
+		Content:        "*[告警]*",
+		RecipientRoles: []string{"admin"},
+		Recipients:     []string{"oncall"},
+	}))
+
+	rules := chatRoom.RoutingRules()
+	assert.Equal(t, "alerts", rules[0].Name, "高优先级的规则应排在前面")
+
+	chatRoom.Send(Message{Type: TextMessage, Content: "磁盘 [告警] 使用率 95%", Sender: "alice"})
+	assert.Equal(t, map[string]int{"admin": 1, "oncall": 1}, received(c), "高优先级的规则先匹配")
+
+	chatRoom.Send(Message{Type: TextMessage, Content: "午饭吃什么", Sender: "alice"})
+	assert.Equal(t, map[string]int{"admin": 1, "oncall": 1, "bob": 1}, received(c), "成员消息只发给其他成员")
+
+	// 运行时删除规则后恢复为广播
+	assert.True(t, chatRoom.RemoveRoutingRule("members-chat"))
+	assert.False(t, chatRoom.RemoveRoutingRule("members-chat"))
+	chatRoom.Send(Message{Type: TextMessage, Content: "下班了", Sender: "alice"})
+	assert.Equal(t, map[string]int{"admin": 2, "oncall": 2, "bob": 2}, received(c))
+}
+
+// 测试默认路由
+func TestRoutingDefaultRoute(t *testing.T) {
+	chatRoom, c := newRoutingTestRoom(map[string]string{
+		"admin": "admin", "alice": "member", "guest": "",
+	})
+	chatRoom.SetDefaultRoute("admin", "member")
+	assert.NoError(t, chatRoom.AddRoutingRule(RoutingRule{
+		Name:           "commands-to-all",
+		Types:          []MessageType{CommandMessage},
+		RecipientRoles: []string{"*"},
+	}))
+
+	chatRoom.Send(Message{Type: TextMessage, Content: "hi", Sender: "admin"})
+	assert.Equal(t, map[string]int{"alice": 1}, received(c), "没有规则匹配时使用默认路由")
+
+	chatRoom.Send(Message{Type: CommandMessage, Content: "/help", Sender: "admin"})
+	assert.Equal(t, map[string]int{"alice": 2, "guest": 1}, received(c), "* 匹配所有角色，包括空角色")
+
+	chatRoom.SetDefaultRoute()
+	chatRoom.Send(Message{Type: TextMessage, Content: "hi", Sender: "admin"})
+	assert.Equal(t, map[string]int{"alice": 3, "guest": 2}, received(c), "清空默认路由后恢复为广播")
+}
+
+// 测试规则校验
+func TestAddRoutingRuleValidation(t *testing.T) {
+	chatRoom := NewChatRoom("校验")
+	assert.ErrorIs(t, chatRoom.AddRoutingRule(RoutingRule{RecipientRoles: []string{"admin"}}), ErrInvalidRule)
+	assert.ErrorIs(t, chatRoom.AddRoutingRule(RoutingRule{Name: "empty"}), ErrInvalidRule)
+	assert.NoError(t, chatRoom.AddRoutingRule(RoutingRule{Name: "r", RecipientRoles: []string{"admin"}}))
+	assert.ErrorIs(t, chatRoom.AddRoutingRule(RoutingRule{Name: "r", RecipientRoles: []string{"admin"}}), ErrInvalidRule)
+}
+
+// 测试通配符匹配
+func TestMatchWildcard(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"*", "", true},
+		{"admin", "admin", true},
+		{"admin", "admins", false},
+		{"mod*", "moderator", true},
+		{"*[告警]*", "磁盘[告警]满了", true},
+		{"a?c", "abc", true},
+		{"a?c", "ac", false},
+		{"*a*b", "xaayb", true},
+		{"*a*b", "xaaybc", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchWildcard(tt.pattern, tt.s), "%q ~ %q", tt.pattern, tt.s)
+	}
+
+	assert.Equal(t, "admin", NewUser("u1", "管理员", "admin").GetRole())
+	assert.Equal(t, "bot", NewBot("b1", "助手", "/").GetRole())
+}
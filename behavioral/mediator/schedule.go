@@ -0,0 +1,168 @@
+package mediator
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ScheduleID 标识一条计划投递的消息
+type ScheduleID uint64
+
+// ScheduledMessage 表示等待投递的计划消息
+type ScheduledMessage struct {
+	ID      ScheduleID // 计划编号
+	Message Message    // 待投递的消息
+}
+
+// messageScheduler 保存聊天室中的计划消息，由 ChatRoom 的锁保护
+type messageScheduler struct {
+	nextID  ScheduleID             // 下一个计划编号
+	pending map[ScheduleID]Message // 尚未投递的消息
+	stop    chan struct{}          // 关闭以停止后台调度协程
+	done    chan struct{}          // 后台调度协程退出时关闭
+}
+
+// newMessageScheduler 创建空的消息调度器
+func newMessageScheduler() messageScheduler {
+	return messageScheduler{
+		pending: make(map[ScheduleID]Message),
+	}
+}
+
+// Schedule 按照消息的 DeliverAt 将其加入计划队列，返回可用于取消的编号
+// DeliverAt 为零值时消息会在下一次处理时投递
+func (c *ChatRoom) Schedule(message Message) ScheduleID {
+	c.mutex.Lock()
+	if message.Timestamp.IsZero() {
+		message.Timestamp = c.now()
+	}
+	if message.DeliverAt.IsZero() {
+		message.DeliverAt = message.Timestamp
+	}
+	c.scheduler.nextID++
+	id := c.scheduler.nextID
+	c.scheduler.pending[id] = message
+	c.mutex.Unlock()
+
+	c.log().Info(fmt.Sprintf("已安排来自 %s 的消息于 %s 投递", message.Sender, message.DeliverAt.Format("15:04:05")),
+		"room", c.name, "event", "scheduled", "id", uint64(id), "sender", message.Sender, "deliverAt", message.DeliverAt)
+	return id
+}
+
+// ScheduleAfter 安排消息在指定延迟后投递
+func (c *ChatRoom) ScheduleAfter(message Message, delay time.Duration) ScheduleID {
+	message.DeliverAt = c.now().Add(delay)
+	return c.Schedule(message)
+}
+
+// Cancel 取消尚未投递的计划消息，返回是否取消成功
+func (c *ChatRoom) Cancel(id ScheduleID) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, exists := c.scheduler.pending[id]; !exists {
+		return false
+	}
+	delete(c.scheduler.pending, id)
+	return true
+}
+
+// Pending 返回所有等待投递的消息，按投递时间排序
+func (c *ChatRoom) Pending() []ScheduledMessage {
+	c.mutex.RLock()
+	pending := make([]ScheduledMessage, 0, len(c.scheduler.pending))
+	for id, message := range c.scheduler.pending {
+		pending = append(pending, ScheduledMessage{ID: id, Message: message})
+	}
+	c.mutex.RUnlock()
+
+	sortScheduled(pending)
+	return pending
+}
+
+// ProcessDue 投递在指定时间已到期的消息，并丢弃已过期的消息
+// 返回投递和丢弃的消息数量
+func (c *ChatRoom) ProcessDue(now time.Time) (delivered, expired int) {
+	var due []ScheduledMessage
+	logger := c.log()
+
+	c.mutex.Lock()
+	for id, message := range c.scheduler.pending {
+		switch {
+		case message.IsExpired(now):
+			delete(c.scheduler.pending, id)
+			expired++
+			logger.Warn(fmt.Sprintf("计划消息 #%d 已过期，丢弃来自 %s 的消息", id, message.Sender),
+				"room", c.name, "event", "expired", "id", uint64(id), "sender", message.Sender)
+		case !message.DeliverAt.After(now):
+			delete(c.scheduler.pending, id)
+			due = append(due, ScheduledMessage{ID: id, Message: message})
+		}
+	}
+	c.mutex.Unlock()
+	c.stats.recordExpired(expired)
+
+	// 在锁外按投递时间顺序投递，参与者可以在 Receive 中继续发送消息
+	sortScheduled(due)
+	for _, scheduled := range due {
+		c.deliver(scheduled.Message)
+	}
+	return len(due), expired
+}
+
+// StartScheduler 启动后台协程，按指定间隔处理到期的计划消息
+// 调度器已经在运行时该调用没有效果
+func (c *ChatRoom) StartScheduler(interval time.Duration) {
+	c.mutex.Lock()
+	if c.scheduler.stop != nil {
+		c.mutex.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	c.scheduler.stop = stop
+	c.scheduler.done = done
+	c.mutex.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.ProcessDue(c.now())
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// StopScheduler 停止后台调度协程并等待其退出，未投递的消息仍保留在队列中
+func (c *ChatRoom) StopScheduler() {
+	c.mutex.Lock()
+	stop, done := c.scheduler.stop, c.scheduler.done
+	c.scheduler.stop = nil
+	c.scheduler.done = nil
+	c.mutex.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// sortScheduled 按投递时间排序，时间相同时按计划编号排序
+func sortScheduled(messages []ScheduledMessage) {
+	sort.Slice(messages, func(i, j int) bool {
+		a, b := messages[i].Message.DeliverAt, messages[j].Message.DeliverAt
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return messages[i].ID < messages[j].ID
+	})
+}
@@ -0,0 +1,129 @@
+package mediator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newScheduleTestRoom 创建使用固定时钟的聊天室和一个消息收集器
+func newScheduleTestRoom(now time.Time) (*ChatRoom, *MessageCollector) {
+	chatRoom := NewChatRoom("计划消息测试组")
+	chatRoom.now = func() time.Time { return now }
+
+	collector := NewMessageCollector("collector", "消息收集器")
+	chatRoom.Register(collector)
+	collector.SetMediator(chatRoom)
+	return chatRoom, collector
+}
+
+// 测试延迟投递的消息在到期时才被投递
+func TestScheduledDelivery(t *testing.T) {
+	base := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	chatRoom, collector := newScheduleTestRoom(base)
+
+	chatRoom.Send(Message{
+		Type:      TextMessage,
+		Content:   "早会提醒",
+		Sender:    "u1",
+		DeliverAt: base.Add(10 * time.Minute),
+	})
+	chatRoom.ScheduleAfter(Message{Type: TextMessage, Content: "午餐提醒", Sender: "u1"}, 3*time.Hour)
+
+	assert.Empty(t, collector.GetMessages(), "未到投递时间的消息不应被投递")
+	pending := chatRoom.Pending()
+	assert.Len(t, pending, 2)
+	assert.Equal(t, "早会提醒", pending[0].Message.Content, "计划消息应按投递时间排序")
+
+	delivered, expired := chatRoom.ProcessDue(base.Add(5 * time.Minute))
+	assert.Equal(t, 0, delivered)
+	assert.Equal(t, 0, expired)
+
+	delivered, _ = chatRoom.ProcessDue(base.Add(10 * time.Minute))
+	assert.Equal(t, 1, delivered)
+	assert.Len(t, collector.GetMessages(), 1)
+	assert.Equal(t, "早会提醒", collector.GetMessages()[0].Content)
+
+	delivered, _ = chatRoom.ProcessDue(base.Add(4 * time.Hour))
+	assert.Equal(t, 1, delivered)
+	assert.Empty(t, chatRoom.Pending())
+}
+
+// 测试过期消息被丢弃
+func TestMessageExpiry(t *testing.T) {
+	base := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	chatRoom, collector := newScheduleTestRoom(base)
+
+	// 发送时已过期的消息直接被丢弃
+	chatRoom.Send(Message{Type: TextMessage, Content: "过期消息", Sender: "u1", ExpiresAt: base})
+	assert.Empty(t, collector.GetMessages())
+
+	// 未过期的即时消息正常投递
+	chatRoom.Send(Message{Type: TextMessage, Content: "有效消息", Sender: "u1", ExpiresAt: base.Add(time.Minute)})
+	assert.Len(t, collector.GetMessages(), 1)
+
+	// 在投递之前过期的计划消息被丢弃
+	chatRoom.Schedule(Message{
+		Type:      TextMessage,
+		Content:   "限时优惠",
+		Sender:    "u1",
+		DeliverAt: base.Add(time.Hour),
+		ExpiresAt: base.Add(30 * time.Minute),
+	})
+	chatRoom.Schedule(Message{
+		Type:      TextMessage,
+		Content:   "全天有效",
+		Sender:    "u1",
+		DeliverAt: base.Add(time.Hour),
+	})
+
+	delivered, expired := chatRoom.ProcessDue(base.Add(time.Hour))
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, 1, expired)
+	assert.Len(t, collector.GetMessages(), 2)
+	assert.Equal(t, "全天有效", collector.GetMessages()[1].Content)
+}
+
+// 测试取消计划消息
+func TestCancelScheduledMessage(t *testing.T) {
+	base := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	chatRoom, collector := newScheduleTestRoom(base)
+
+	id := chatRoom.ScheduleAfter(Message{Type: TextMessage, Content: "将被取消", Sender: "u1"}, time.Minute)
+	keep := chatRoom.ScheduleAfter(Message{Type: TextMessage, Content: "保留", Sender: "u1"}, time.Minute)
+	assert.NotEqual(t, id, keep)
+
+	assert.True(t, chatRoom.Cancel(id), "第一次取消应成功")
+	assert.False(t, chatRoom.Cancel(id), "重复取消应失败")
+	assert.False(t, chatRoom.Cancel(ScheduleID(999)), "取消不存在的消息应失败")
+
+	delivered, _ := chatRoom.ProcessDue(base.Add(time.Minute))
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, "保留", collector.GetMessages()[0].Content)
+	assert.False(t, chatRoom.Cancel(keep), "已投递的消息不能再取消")
+}
+
+// 测试后台调度器自动投递到期消息
+func TestBackgroundScheduler(t *testing.T) {
+	chatRoom := NewChatRoom("后台调度测试组")
+	collector := NewMessageCollector("collector", "消息收集器")
+	chatRoom.Register(collector)
+	collector.SetMediator(chatRoom)
+
+	chatRoom.ScheduleAfter(Message{Type: NotificationMessage, Content: "延迟通知", Sender: "system"}, 20*time.Millisecond)
+	chatRoom.ScheduleAfter(Message{Type: NotificationMessage, Content: "远期通知", Sender: "system"}, time.Hour)
+
+	chatRoom.StartScheduler(5 * time.Millisecond)
+	chatRoom.StartScheduler(5 * time.Millisecond) // 重复启动没有效果
+
+	assert.Eventually(t, func() bool {
+		return len(chatRoom.Pending()) == 1
+	}, time.Second, 5*time.Millisecond, "到期消息应被后台调度器投递")
+	chatRoom.StopScheduler()
+	chatRoom.StopScheduler() // 重复停止没有效果
+
+	assert.Len(t, collector.GetMessages(), 1)
+	assert.Equal(t, "延迟通知", collector.GetMessages()[0].Content)
+	assert.Equal(t, "远期通知", chatRoom.Pending()[0].Message.Content, "停止后未到期的消息仍保留")
+}
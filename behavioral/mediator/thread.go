@@ -0,0 +1,171 @@
+package mediator
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// ThreadInfo 会话线程的概要信息
+type ThreadInfo struct {
+	ID           string    // 线程编号
+	Participants []string  // 参与者ID，按加入顺序排列
+	Messages     int       // 线程中的消息数量
+	StartedAt    time.Time // 第一条消息的时间
+	LastActivity time.Time // 最后一条消息的时间
+}
+
+// thread 单个会话线程
+type thread struct {
+	id           string
+	participants []string
+	messages     []Message
+}
+
+// addParticipant 把参与者加入线程，已经在线程中时忽略
+func (t *thread) addParticipant(id string) {
+	if id != "" && !slices.Contains(t.participants, id) {
+		t.participants = append(t.participants, id)
+	}
+}
+
+// info 返回线程的概要信息
+func (t *thread) info() ThreadInfo {
+	return ThreadInfo{
+		ID:           t.id,
+		Participants: slices.Clone(t.participants),
+		Messages:     len(t.messages),
+		StartedAt:    t.messages[0].Timestamp,
+		LastActivity: t.messages[len(t.messages)-1].Timestamp,
+	}
+}
+
+// threadIndex 保存聊天室中的会话线程，由 ChatRoom 的锁保护
+type threadIndex struct {
+	nextMessageID uint64             // 下一个消息编号
+	threads       map[string]*thread // 按线程编号索引
+	messages      map[string]string  // 线程中的消息编号到线程编号的映射
+}
+
+// newThreadIndex 创建空的线程索引
+func newThreadIndex() threadIndex {
+	return threadIndex{
+		threads:  make(map[string]*thread),
+		messages: make(map[string]string),
+	}
+}
+
+// threadMessage 为消息分配编号并处理线程关系，在投递前调用
+//
+//   - 设置了 ReplyTo 的消息是对该消息所在线程的回复；
+//   - 设置了已存在的 ThreadID 的消息同样是对该线程的回复；
+//   - 设置了新的 ThreadID 的消息开启一个线程，按普通消息路由。
+//
+// 回复只投递给线程参与者，返回的 participants 为此时的参与者列表（包含发送者）；
+// 回复的消息不属于任何线程时返回错误
+func (c *ChatRoom) threadMessage(message Message) (_ Message, participants []string, reply bool, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	index := &c.threads
+	if message.ID == "" {
+		index.nextMessageID++
+		message.ID = fmt.Sprintf("m%d", index.nextMessageID)
+	}
+
+	if message.ReplyTo != "" {
+		threadID, ok := index.messages[message.ReplyTo]
+		if !ok {
+			return message, nil, false, fmt.Errorf("回复的消息 %s 不属于任何线程", message.ReplyTo)
+		}
+		message.ThreadID = threadID
+	}
+	if message.ThreadID == "" {
+		return message, nil, false, nil
+	}
+
+	t, exists := index.threads[message.ThreadID]
+	if !exists {
+		t = &thread{id: message.ThreadID}
+		index.threads[t.id] = t
+	}
+	t.addParticipant(message.Sender)
+	t.addParticipant(message.Recipient)
+	t.messages = append(t.messages, message)
+	index.messages[message.ID] = t.id
+	return message, slices.Clone(t.participants), exists, nil
+}
+
+// deliverToThread 把回复投递给线程中除发送者外仍在聊天室中的参与者
+func (c *ChatRoom) deliverToThread(message Message, participants []string) {
+	c.mutex.RLock()
+	recipients := make([]Colleague, 0, len(participants))
+	ids := make([]string, 0, len(participants))
+	for _, id := range participants {
+		if colleague, ok := c.colleagues[id]; ok && id != message.Sender {
+			recipients = append(recipients, colleague)
+			ids = append(ids, id)
+		}
+	}
+	c.mutex.RUnlock()
+
+	c.stats.recordRouted(message, ids)
+	for _, colleague := range recipients {
+		colleague.Receive(message)
+	}
+}
+
+// Threads 返回所有会话线程的概要信息，最近活跃的线程排在前面
+func (c *ChatRoom) Threads() []ThreadInfo {
+	c.mutex.RLock()
+	infos := make([]ThreadInfo, 0, len(c.threads.threads))
+	for _, t := range c.threads.threads {
+		infos = append(infos, t.info())
+	}
+	c.mutex.RUnlock()
+
+	sort.Slice(infos, func(i, j int) bool {
+		if !infos[i].LastActivity.Equal(infos[j].LastActivity) {
+			return infos[i].LastActivity.After(infos[j].LastActivity)
+		}
+		return infos[i].ID < infos[j].ID
+	})
+	return infos
+}
+
+// Thread 返回线程的完整记录，按投递顺序排列
+func (c *ChatRoom) Thread(id string) ([]Message, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	t, ok := c.threads.threads[id]
+	if !ok {
+		return nil, false
+	}
+	return slices.Clone(t.messages), true
+}
+
+// Reply 在消息所在的线程中回复，只有线程参与者会收到
+// 被回复的消息不属于任何线程时，以该消息的编号开启一个新线程
+func (u *User) Reply(to Message, content string) {
+	if u.mediator == nil {
+		fmt.Printf("错误: %s 没有中介者，无法发送消息\n", u.name)
+		return
+	}
+
+	message := Message{
+		Type:      TextMessage,
+		Content:   content,
+		Sender:    u.id,
+		Timestamp: time.Now(),
+	}
+	if to.ThreadID != "" {
+		message.ReplyTo = to.ID
+	} else {
+		// 被回复的消息没有线程，以它的编号作为线程编号，并把原发送者加入线程
+		message.ThreadID = to.ID
+		message.Recipient = to.Sender
+	}
+	u.mediator.Send(message)
+}
@@ -0,0 +1,144 @@
+package mediator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newThreadTestRoom 创建使用固定时钟、关闭日志的聊天室，并注册若干消息收集器
+func newThreadTestRoom(ids ...string) (*ChatRoom, map[string]*MessageCollector) {
+	chatRoom := NewChatRoom("线程测试组")
+	chatRoom.SetLogger(nil)
+	base := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	tick := 0
+	chatRoom.now = func() time.Time {
+		tick++
+		return base.Add(time.Duration(tick) * time.Minute)
+	}
+
+	collectors := make(map[string]*MessageCollector, len(ids))
+	for _, id := range ids {
+		collector := NewMessageCollector(id, id)
+		chatRoom.Register(collector)
+		collector.SetMediator(chatRoom)
+		collectors[id] = collector
+	}
+	return chatRoom, collectors
+}
+
+// 测试线程中的回复只投递给线程参与者
+func TestThreadReplyRouting(t *testing.T) {
+	chatRoom, c := newThreadTestRoom("alice", "bob", "carol", "dave")
+
+	// alice 开启一个线程并广播给所有人
+	chatRoom.Send(Message{Type: TextMessage, Content: "发布计划讨论", Sender: "alice", ThreadID: "release"})
+	for _, id := range []string{"bob", "carol", "dave"} {
+		assert.Len(t, c[id].GetMessages(), 1, "开启线程的消息按普通消息路由")
+	}
+	root := c["bob"].GetMessages()[0]
+	assert.Equal(t, "release", root.ThreadID)
+	assert.NotEmpty(t, root.ID, "聊天室应为消息分配编号")
+
+	// bob 回复，只有线程参与者 alice 收到
+	chatRoom.Send(Message{Type: TextMessage, Content: "周五可以吗", Sender: "bob", ReplyTo: root.ID})
+	assert.Len(t, c["alice"].GetMessages(), 1)
+	assert.Equal(t, "周五可以吗", c["alice"].GetMessages()[0].Content)
+	assert.Equal(t, "release", c["alice"].GetMessages()[0].ThreadID, "回复应归入被回复消息所在的线程")
+	assert.Len(t, c["carol"].GetMessages(), 1, "非参与者不应收到回复")
+
+	// 通过线程编号回复，并点名邀请 carol 加入
+	chatRoom.Send(Message{Type: TextMessage, Content: "carol 也看看", Sender: "alice", ThreadID: "release", Recipient: "carol"})
+	assert.Len(t, c["bob"].GetMessages(), 2)
+	assert.Len(t, c["carol"].GetMessages(), 2)
+	assert.Len(t, c["dave"].GetMessages(), 1)
+
+	// carol 之后也能收到线程中的回复
+	chatRoom.Send(Message{Type: TextMessage, Content: "没问题", Sender: "bob", ReplyTo: c["alice"].GetMessages()[0].ID})
+	assert.Len(t, c["carol"].GetMessages(), 3)
+	assert.Len(t, c["alice"].GetMessages(), 2)
+	assert.Len(t, c["bob"].GetMessages(), 2, "发送者不会收到自己的回复")
+
+	// 已离开聊天室的参与者被跳过
+	chatRoom.Unregister(c["carol"])
+	chatRoom.Send(Message{Type: TextMessage, Content: "定了", Sender: "alice", ThreadID: "release"})
+	assert.Len(t, c["carol"].GetMessages(), 3)
+	assert.Len(t, c["bob"].GetMessages(), 3)
+}
+
+// 测试线程列表与线程记录
+func TestThreadsAndTranscript(t *testing.T) {
+	chatRoom, c := newThreadTestRoom("alice", "bob")
+
+	chatRoom.Send(Message{Type: TextMessage, Content: "问题一", Sender: "alice", ThreadID: "q1"})
+	chatRoom.Send(Message{Type: TextMessage, Content: "问题二", Sender: "bob", ThreadID: "q2"})
+	chatRoom.Send(Message{Type: TextMessage, Content: "普通消息", Sender: "alice"})
+	chatRoom.Send(Message{Type: TextMessage, Content: "回答一", Sender: "bob", ThreadID: "q1"})
+
+	threads := chatRoom.Threads()
+	assert.Len(t, threads, 2)
+	assert.Equal(t, "q1", threads[0].ID, "最近活跃的线程排在前面")
+	assert.Equal(t, []string{"alice", "bob"}, threads[0].Participants)
+	assert.Equal(t, 2, threads[0].Messages)
+	assert.True(t, threads[0].LastActivity.After(threads[0].StartedAt))
+	assert.Equal(t, []string{"bob"}, threads[1].Participants)
+
+	transcript, ok := chatRoom.Thread("q1")
+	assert.True(t, ok)
+	assert.Len(t, transcript, 2)
+	assert.Equal(t, "问题一", transcript[0].Content)
+	assert.Equal(t, "回答一", transcript[1].Content)
+
+	// 返回的是副本
+	transcript[0].Content = "被修改"
+	transcript, _ = chatRoom.Thread("q1")
+	assert.Equal(t, "问题一", transcript[0].Content)
+
+	_, ok = chatRoom.Thread("missing")
+	assert.False(t, ok)
+	assert.Len(t, c["alice"].GetMessages(), 2)
+}
+
+// 测试回复不属于任何线程的消息无法投递
+func TestReplyToUnknownMessage(t *testing.T) {
+	chatRoom, c := newThreadTestRoom("alice", "bob")
+
+	chatRoom.Send(Message{Type: TextMessage, Content: "你好", Sender: "alice"})
+	plain := c["bob"].GetMessages()[0]
+	assert.Empty(t, plain.ThreadID)
+
+	chatRoom.Send(Message{Type: TextMessage, Content: "回复", Sender: "bob", ReplyTo: plain.ID})
+	chatRoom.Send(Message{Type: TextMessage, Content: "回复", Sender: "bob", ReplyTo: "missing"})
+	assert.Empty(t, c["alice"].GetMessages())
+	assert.Equal(t, 2, chatRoom.Stats().Undeliverable)
+	assert.Empty(t, chatRoom.Threads())
+}
+
+// 测试用户的 Reply 方法
+func TestUserReply(t *testing.T) {
+	chatRoom, c := newThreadTestRoom("alice", "carol")
+	bob := NewUser("bob", "Bob", "成员")
+	chatRoom.Register(bob)
+	bob.SetMediator(chatRoom)
+
+	// 回复没有线程的消息时，以该消息开启线程并私信原发送者
+	chatRoom.Send(Message{Type: TextMessage, Content: "谁有空", Sender: "alice"})
+	bob.Reply(c["carol"].GetMessages()[0], "我有空")
+	assert.Equal(t, "我有空", c["alice"].GetMessages()[0].Content)
+	assert.Len(t, c["carol"].GetMessages(), 1)
+
+	threads := chatRoom.Threads()
+	assert.Len(t, threads, 1)
+	assert.Equal(t, []string{"bob", "alice"}, threads[0].Participants)
+
+	// alice 在线程中继续回复，bob 收到；再次回复时沿用同一个线程
+	chatRoom.Send(Message{Type: TextMessage, Content: "好的", Sender: "alice", ReplyTo: c["alice"].GetMessages()[0].ID})
+	transcript, _ := chatRoom.Thread(threads[0].ID)
+	bob.Reply(transcript[1], "收到")
+	assert.Len(t, c["alice"].GetMessages(), 2)
+	assert.Len(t, c["carol"].GetMessages(), 1)
+
+	transcript, _ = chatRoom.Thread(threads[0].ID)
+	assert.Len(t, transcript, 3)
+}
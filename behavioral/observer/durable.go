@@ -0,0 +1,381 @@
+package observer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// 持久订阅相关错误
+var (
+	ErrHistoryDisabled    = errors.New("事件历史未开启")
+	ErrOffsetTruncated    = errors.New("偏移量对应的事件已被清理")
+	ErrOffsetOutOfRange   = errors.New("偏移量超出事件历史")
+	ErrAlreadySubscribed  = errors.New("持久订阅已存在")
+	ErrSubscriptionClosed = errors.New("持久订阅已关闭")
+)
+
+// EventRecord 事件历史中的一条记录，Offset 从 0 开始连续递增
+type EventRecord struct {
+	Offset  uint64     // 事件在历史中的偏移量
+	Event   StockEvent // 股票事件
+	Message string     // 市场公告
+}
+
+// DurableObserver 持久订阅的观察者
+// Deliver 按偏移量顺序被调用，处理完成后通过订阅的 Ack 确认；
+// 没有确认的事件会在重新订阅时再次投递，因此观察者需要能够处理重复事件（至少一次语义）
+type DurableObserver interface {
+	Deliver(record EventRecord) // 接收事件记录
+	GetID() string              // 获取观察者标识，也是偏移量的存储键
+}
+
+// OffsetStore 持久化每个观察者已确认的偏移量
+// 保存的是下一条待消费事件的偏移量，没有记录的观察者从 0 开始
+type OffsetStore interface {
+	Load(id string) (uint64, error)
+	Save(id string, offset uint64) error
+}
+
+// MemoryOffsetStore 保存在内存中的偏移量，适合测试和单进程内的重新订阅
+type MemoryOffsetStore struct {
+	mutex   sync.Mutex
+	offsets map[string]uint64
+}
+
+// NewMemoryOffsetStore 创建内存偏移量存储
+func NewMemoryOffsetStore() *MemoryOffsetStore {
+	return &MemoryOffsetStore{offsets: make(map[string]uint64)}
+}
+
+// Load 读取观察者的偏移量
+func (m *MemoryOffsetStore) Load(id string) (uint64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.offsets[id], nil
+}
+
+// Save 保存观察者的偏移量
+func (m *MemoryOffsetStore) Save(id string, offset uint64) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.offsets[id] = offset
+	return nil
+}
+
+// FileOffsetStore 以 JSON 文件保存偏移量，进程重启后可以继续消费
+type FileOffsetStore struct {
+	mutex sync.Mutex
+	path  string
+}
+
+// NewFileOffsetStore 创建文件偏移量存储，文件不存在时在第一次保存时创建
+func NewFileOffsetStore(path string) *FileOffsetStore {
+	return &FileOffsetStore{path: path}
+}
+
+// Load 读取观察者的偏移量
+func (f *FileOffsetStore) Load(id string) (uint64, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	offsets, err := f.readUnsafe()
+	if err != nil {
+		return 0, err
+	}
+	return offsets[id], nil
+}
+
+// Save 保存观察者的偏移量，先写入临时文件再重命名，避免写到一半时崩溃损坏文件
+func (f *FileOffsetStore) Save(id string, offset uint64) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	offsets, err := f.readUnsafe()
+	if err != nil {
+		return err
+	}
+	offsets[id] = offset
+
+	data, err := json.MarshalIndent(offsets, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
+
+// readUnsafe 读取偏移量文件（需持有锁）
+func (f *FileOffsetStore) readUnsafe() (map[string]uint64, error) {
+	offsets := make(map[string]uint64)
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return offsets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &offsets); err != nil {
+		return nil, fmt.Errorf("偏移量文件 %s 格式错误: %w", f.path, err)
+	}
+	return offsets, nil
+}
+
+// eventHistory 市场的事件历史，由 StockMarket 的锁保护
+type eventHistory struct {
+	enabled  bool
+	limit    int           // 最多保留的记录数，0 表示不限制
+	first    uint64        // records[0] 的偏移量
+	records  []EventRecord // 保留的记录
+	durables map[string]*DurableSubscription
+}
+
+// next 返回下一条记录的偏移量
+func (h *eventHistory) next() uint64 {
+	return h.first + uint64(len(h.records))
+}
+
+// rangeUnsafe 返回从 offset 开始保留的记录，offset 早于最早的记录时从最早的记录开始
+func (h *eventHistory) rangeUnsafe(offset uint64) []EventRecord {
+	if offset < h.first {
+		offset = h.first
+	}
+	if offset >= h.next() {
+		return nil
+	}
+	return append([]EventRecord(nil), h.records[offset-h.first:]...)
+}
+
+// EnableHistory 开启事件历史，最多保留 limit 条记录（0 表示不限制）
+// 开启后每次通知都会被记录，持久订阅依赖事件历史补发错过的事件
+func (s *StockMarket) EnableHistory(limit int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.history.enabled = true
+	s.history.limit = limit
+	if s.history.durables == nil {
+		s.history.durables = make(map[string]*DurableSubscription)
+	}
+	s.trimHistoryUnsafe()
+}
+
+// History 返回从 offset 开始保留的事件记录
+func (s *StockMarket) History(offset uint64) []EventRecord {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.history.rangeUnsafe(offset)
+}
+
+// recordEvent 把事件追加到历史，返回记录和此时的持久订阅，未开启历史时返回 false
+func (s *StockMarket) recordEvent(event StockEvent, message string) (EventRecord, []*DurableSubscription, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.history.enabled {
+		return EventRecord{}, nil, false
+	}
+	record := EventRecord{Offset: s.history.next(), Event: event, Message: message}
+	s.history.records = append(s.history.records, record)
+	s.trimHistoryUnsafe()
+
+	subscriptions := make([]*DurableSubscription, 0, len(s.history.durables))
+	for _, sub := range s.history.durables {
+		subscriptions = append(subscriptions, sub)
+	}
+	return record, subscriptions, true
+}
+
+// trimHistoryUnsafe 按保留条数清理最早的记录（需持有写锁）
+func (s *StockMarket) trimHistoryUnsafe() {
+	h := &s.history
+	if h.limit <= 0 || len(h.records) <= h.limit {
+		return
+	}
+	drop := len(h.records) - h.limit
+	h.records = append([]EventRecord(nil), h.records[drop:]...)
+	h.first += uint64(drop)
+}
+
+// notifyDurable 记录事件并投递给持久订阅
+func (s *StockMarket) notifyDurable(event StockEvent, message string) {
+	record, subscriptions, ok := s.recordEvent(event, message)
+	if !ok {
+		return
+	}
+	for _, sub := range subscriptions {
+		sub.deliver(record)
+	}
+}
+
+// DurableSubscription 持久订阅，记录观察者已确认的偏移量
+// 观察者断开后再次以相同ID订阅时，从已确认的偏移量开始补发错过的事件
+type DurableSubscription struct {
+	market   *StockMarket
+	observer DurableObserver
+	store    OffsetStore
+
+	deliverMutex sync.Mutex // 串行化补发和实时投递，保证按偏移量顺序投递
+	next         uint64     // 下一条待投递的偏移量
+	closed       bool
+
+	ackMutex  sync.Mutex
+	committed uint64 // 下一条待确认的偏移量
+}
+
+// RegisterDurable 以持久订阅模式注册观察者，观察者会收到所有股票的事件
+// 从存储中已确认的偏移量开始补发事件历史，之后继续投递实时事件
+func (s *StockMarket) RegisterDurable(observer DurableObserver, store OffsetStore) (*DurableSubscription, error) {
+	committed, err := store.Load(observer.GetID())
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &DurableSubscription{market: s, observer: observer, store: store, committed: committed}
+	// 补发完成之前到达的实时事件等待补发结束，避免乱序
+	sub.deliverMutex.Lock()
+	defer sub.deliverMutex.Unlock()
+
+	s.mutex.Lock()
+	if !s.history.enabled {
+		s.mutex.Unlock()
+		return nil, ErrHistoryDisabled
+	}
+	if _, exists := s.history.durables[observer.GetID()]; exists {
+		s.mutex.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrAlreadySubscribed, observer.GetID())
+	}
+	s.history.durables[observer.GetID()] = sub
+	s.mutex.Unlock()
+
+	if err := sub.resumeUnsafe(committed); err != nil {
+		s.mutex.Lock()
+		delete(s.history.durables, observer.GetID())
+		s.mutex.Unlock()
+		return nil, err
+	}
+	fmt.Printf("观察者 %s 已持久订阅股票市场（从偏移量 %d 开始）\n", observer.GetID(), committed)
+	return sub, nil
+}
+
+// ResumeFrom 从 offset 开始重新投递事件历史，之后继续投递实时事件
+// offset 对应的事件已被清理时返回 ErrOffsetTruncated，超出历史末尾时返回 ErrOffsetOutOfRange
+func (d *DurableSubscription) ResumeFrom(offset uint64) error {
+	d.deliverMutex.Lock()
+	defer d.deliverMutex.Unlock()
+
+	if d.closed {
+		return ErrSubscriptionClosed
+	}
+	return d.resumeUnsafe(offset)
+}
+
+// resumeUnsafe 从 offset 开始补发事件历史（需持有 deliverMutex）
+func (d *DurableSubscription) resumeUnsafe(offset uint64) error {
+	d.market.mutex.RLock()
+	first, next := d.market.history.first, d.market.history.next()
+	records := d.market.history.rangeUnsafe(offset)
+	d.market.mutex.RUnlock()
+
+	if offset < first {
+		return fmt.Errorf("%w: 请求 %d，最早保留 %d", ErrOffsetTruncated, offset, first)
+	}
+	if offset > next {
+		return fmt.Errorf("%w: 请求 %d，历史末尾 %d", ErrOffsetOutOfRange, offset, next)
+	}
+
+	d.next = offset
+	for _, record := range records {
+		d.deliverUnsafe(record)
+	}
+	return nil
+}
+
+// deliver 投递一条实时事件，补发期间已经投递过的事件被跳过，漏掉的事件先从历史补发
+func (d *DurableSubscription) deliver(record EventRecord) {
+	d.deliverMutex.Lock()
+	defer d.deliverMutex.Unlock()
+
+	if d.closed || record.Offset < d.next {
+		return
+	}
+	if record.Offset > d.next {
+		for _, missed := range d.market.History(d.next) {
+			if missed.Offset >= record.Offset {
+				break
+			}
+			d.deliverUnsafe(missed)
+		}
+	}
+	d.deliverUnsafe(record)
+}
+
+// deliverUnsafe 把记录交给观察者（需持有 deliverMutex）
+func (d *DurableSubscription) deliverUnsafe(record EventRecord) {
+	d.observer.Deliver(record)
+	d.next = record.Offset + 1
+}
+
+// Ack 确认 offset 及之前的所有事件已处理完成，并持久化偏移量
+// 确认是累积的，重复确认或确认更早的偏移量不会使偏移量后退
+func (d *DurableSubscription) Ack(offset uint64) error {
+	d.ackMutex.Lock()
+	defer d.ackMutex.Unlock()
+
+	if offset+1 <= d.committed {
+		return nil
+	}
+	if err := d.store.Save(d.observer.GetID(), offset+1); err != nil {
+		return err
+	}
+	d.committed = offset + 1
+	return nil
+}
+
+// Committed 返回下一条待确认事件的偏移量
+func (d *DurableSubscription) Committed() uint64 {
+	d.ackMutex.Lock()
+	defer d.ackMutex.Unlock()
+	return d.committed
+}
+
+// Pending 返回已投递但尚未确认的事件数
+func (d *DurableSubscription) Pending() uint64 {
+	d.deliverMutex.Lock()
+	next := d.next
+	d.deliverMutex.Unlock()
+
+	committed := d.Committed()
+	if next < committed {
+		return 0
+	}
+	return next - committed
+}
+
+// Close 取消订阅，已确认的偏移量保留在存储中，之后可以用相同ID重新订阅
+func (d *DurableSubscription) Close() {
+	d.market.mutex.Lock()
+	if d.market.history.durables[d.observer.GetID()] == d {
+		delete(d.market.history.durables, d.observer.GetID())
+	}
+	d.market.mutex.Unlock()
+
+	d.deliverMutex.Lock()
+	d.closed = true
+	d.deliverMutex.Unlock()
+}
@@ -0,0 +1,187 @@
+package observer
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// durableRecorder 记录收到的偏移量，autoAck 为 true 时收到即确认
+type durableRecorder struct {
+	id      string
+	mutex   sync.Mutex
+	offsets []uint64
+	symbols []string
+	sub     *DurableSubscription
+	autoAck bool
+}
+
+func (r *durableRecorder) Deliver(record EventRecord) {
+	r.mutex.Lock()
+	r.offsets = append(r.offsets, record.Offset)
+	r.symbols = append(r.symbols, record.Event.Symbol)
+	sub, autoAck := r.sub, r.autoAck
+	r.mutex.Unlock()
+
+	if autoAck && sub != nil {
+		sub.Ack(record.Offset)
+	}
+}
+
+func (r *durableRecorder) GetID() string {
+	return r.id
+}
+
+func (r *durableRecorder) received() []uint64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]uint64(nil), r.offsets...)
+}
+
+// publish 依次发布若干股票的首次报价
+func publish(market *StockMarket, symbols ...string) {
+	captureOutput(func() {
+		for _, symbol := range symbols {
+			market.UpdateStockPrice(symbol, 100, "报价", 0)
+		}
+	})
+}
+
+// TestDurableSubscriptionCatchUp 测试断开后重新订阅时补发未确认的事件
+func TestDurableSubscriptionCatchUp(t *testing.T) {
+	assert := assert.New(t)
+	market := NewStockMarket()
+	store := NewMemoryOffsetStore()
+
+	_, err := market.RegisterDurable(&durableRecorder{id: "risk"}, store)
+	assert.ErrorIs(err, ErrHistoryDisabled)
+	market.EnableHistory(0)
+
+	publish(market, "AAPL", "MSFT") // 订阅之前的事件同样会补发
+
+	consumer := &durableRecorder{id: "risk"}
+	var sub *DurableSubscription
+	captureOutput(func() { sub, err = market.RegisterDurable(consumer, store) })
+	assert.NoError(err)
+	assert.Equal([]uint64{0, 1}, consumer.received(), "从偏移量 0 补发历史")
+
+	publish(market, "GOOG", "TSLA")
+	assert.Equal([]uint64{0, 1, 2, 3}, consumer.received())
+	assert.Equal([]string{"AAPL", "MSFT", "GOOG", "TSLA"}, consumer.symbols)
+
+	// 只确认到 GOOG 就断开
+	assert.NoError(sub.Ack(2))
+	assert.NoError(sub.Ack(0), "确认更早的偏移量不会使偏移量后退")
+	assert.Equal(uint64(3), sub.Committed())
+	assert.Equal(uint64(1), sub.Pending())
+	sub.Close()
+
+	_, err = market.RegisterDurable(&durableRecorder{id: "other"}, store)
+	assert.NoError(err)
+	_, err = market.RegisterDurable(&durableRecorder{id: "other"}, store)
+	assert.ErrorIs(err, ErrAlreadySubscribed)
+
+	publish(market, "NVDA") // 断开期间错过的事件
+
+	// 重新订阅：TSLA 没有确认，至少投递一次
+	restarted := &durableRecorder{id: "risk"}
+	captureOutput(func() { sub, err = market.RegisterDurable(restarted, store) })
+	assert.NoError(err)
+	assert.Equal([]uint64{3, 4}, restarted.received())
+	assert.Equal([]string{"TSLA", "NVDA"}, restarted.symbols)
+
+	// 回放到指定偏移量
+	assert.NoError(sub.ResumeFrom(1))
+	assert.Equal([]uint64{3, 4, 1, 2, 3, 4}, restarted.received())
+	assert.ErrorIs(sub.ResumeFrom(10), ErrOffsetOutOfRange)
+
+	sub.Close()
+	publish(market, "AMD")
+	assert.Len(restarted.received(), 6, "关闭后不再投递")
+	assert.ErrorIs(sub.ResumeFrom(0), ErrSubscriptionClosed)
+}
+
+// TestDurableSubscriptionRetention 测试历史保留条数
+func TestDurableSubscriptionRetention(t *testing.T) {
+	assert := assert.New(t)
+	market := NewStockMarket()
+	market.EnableHistory(2)
+	publish(market, "A", "B", "C")
+
+	history := market.History(0)
+	if assert.Len(history, 2) {
+		assert.Equal(uint64(1), history[0].Offset)
+		assert.Equal("C", history[1].Event.Symbol)
+	}
+
+	store := NewMemoryOffsetStore()
+	_, err := market.RegisterDurable(&durableRecorder{id: "late"}, store)
+	assert.ErrorIs(err, ErrOffsetTruncated, "需要的事件已被清理")
+
+	assert.NoError(store.Save("late", 2))
+	consumer := &durableRecorder{id: "late"}
+	captureOutput(func() { _, err = market.RegisterDurable(consumer, store) })
+	assert.NoError(err)
+	assert.Equal([]uint64{2}, consumer.received())
+}
+
+// TestDurableSubscriptionFileStore 测试偏移量持久化到文件
+func TestDurableSubscriptionFileStore(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), "offsets.json")
+	market := NewStockMarket()
+	market.EnableHistory(0)
+
+	consumer := &durableRecorder{id: "audit", autoAck: true}
+	var sub *DurableSubscription
+	var err error
+	captureOutput(func() { sub, err = market.RegisterDurable(consumer, NewFileOffsetStore(path)) })
+	assert.NoError(err)
+	consumer.sub = sub
+	publish(market, "AAPL", "MSFT", "GOOG")
+	sub.Close()
+
+	// 模拟进程重启：新的存储实例从文件读取偏移量
+	offset, err := NewFileOffsetStore(path).Load("audit")
+	assert.NoError(err)
+	assert.Equal(uint64(3), offset)
+
+	assert.NoError(os.WriteFile(path, []byte("not json"), 0o644))
+	_, err = NewFileOffsetStore(path).Load("audit")
+	assert.Error(err)
+}
+
+// TestDurableSubscriptionConcurrent 测试并发发布时按偏移量顺序投递且不丢失
+func TestDurableSubscriptionConcurrent(t *testing.T) {
+	market := NewStockMarket()
+	market.EnableHistory(0)
+	consumer := &durableRecorder{id: "ordered"}
+
+	var wg sync.WaitGroup
+	captureOutput(func() {
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 25; j++ {
+					market.UpdateStockPrice("AAPL", float64(100+j), "报价", 0)
+				}
+			}()
+		}
+		// 发布过程中订阅
+		_, err := market.RegisterDurable(consumer, NewMemoryOffsetStore())
+		assert.NoError(t, err)
+		wg.Wait()
+	})
+
+	received := consumer.received()
+	assert.Len(t, received, 100)
+	for i, offset := range received {
+		if offset != uint64(i) {
+			t.Fatalf("第 %d 个事件的偏移量为 %d，应按顺序投递", i, offset)
+		}
+	}
+}
@@ -71,6 +71,11 @@ type StockMarket struct {
 	observers []Observer         // 观察者列表
 	stocks    map[string]float64 // 股票价格映射表
 	mutex     sync.RWMutex       // 保证线程安全
+
+	topics   map[string]map[string]bool // 观察者ID -> 订阅的股票代码或通配模式
+	resolved map[string][]Observer      // 股票代码 -> 订阅者索引，订阅变化时重建
+	version  uint64                     // 价格版本号，每次价格更新加一
+	history  eventHistory               // 事件历史和持久订阅
 }
 
 // NewStockMarket 创建一个新的股票市场
@@ -78,10 +83,12 @@ func NewStockMarket() *StockMarket {
 	return &StockMarket{
 		observers: make([]Observer, 0),
 		stocks:    make(map[string]float64),
+		topics:    make(map[string]map[string]bool),
+		resolved:  make(map[string][]Observer),
 	}
 }
 
-// Register 实现注册观察者
+// Register 实现注册观察者，观察者会收到所有股票的通知
 func (s *StockMarket) Register(observer Observer) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -91,22 +98,24 @@ func (s *StockMarket) Register(observer Observer) {
 		fmt.Printf("观察者 %s 已经注册\n", observer.GetID())
 		return
 	}
-	s.observers = append(s.observers, observer)
+	s.addObserverUnsafe(observer, AllSymbols)
 	fmt.Printf("观察者 %s 已注册到股票市场\n", observer.GetID())
 }
 
 // Deregister 实现注销观察者
 func (s *StockMarket) Deregister(observer Observer) {
 	s.mutex.Lock()
-	defer s.mutex.Unlock()
+	removed := s.removeObserverUnsafe(observer.GetID())
+	s.mutex.Unlock()
 
-	for i, obs := range s.observers {
-		if obs.GetID() == observer.GetID() {
-			s.observers = append(s.observers[:i], s.observers[i+1:]...)
-			fmt.Printf("观察者 %s 已从股票市场注销\n", observer.GetID())
-			return
-		}
+	if removed == nil {
+		return
 	}
+	// 队列模式的观察者需要在锁外等待剩余事件投递完成
+	if q, ok := removed.(*queuedObserver); ok {
+		q.close()
+	}
+	fmt.Printf("观察者 %s 已从股票市场注销\n", observer.GetID())
 }
 
 // HasObserverUnsafe 检查观察者是否已注册（非线程安全，只在加锁后使用）
@@ -135,12 +144,9 @@ func (s *StockMarket) CountObservers() int {
 	return len(s.observers)
 }
 
-// Notify 通知所有观察者（同步）
+// Notify 同步通知订阅了该股票的观察者
 func (s *StockMarket) Notify(event StockEvent, message string) {
-	s.mutex.RLock()
-	observers := make([]Observer, len(s.observers))
-	copy(observers, s.observers)
-	s.mutex.RUnlock()
+	observers := s.subscribersFor(event.Symbol)
 
 	fmt.Printf("\n【市场公告】%s\n", message)
 	fmt.Printf("股票行情: %s\n", event.String())
@@ -148,14 +154,12 @@ func (s *StockMarket) Notify(event StockEvent, message string) {
 	for _, observer := range observers {
 		observer.Update(event, message)
 	}
+	s.notifyDurable(event, message)
 }
 
-// NotifyAsync 异步通知所有观察者
+// NotifyAsync 异步通知订阅了该股票的观察者
 func (s *StockMarket) NotifyAsync(event StockEvent, message string) {
-	s.mutex.RLock()
-	observers := make([]Observer, len(s.observers))
-	copy(observers, s.observers)
-	s.mutex.RUnlock()
+	observers := s.subscribersFor(event.Symbol)
 
 	fmt.Printf("\n【市场公告】%s\n", message)
 	fmt.Printf("股票行情: %s\n", event.String())
@@ -169,31 +173,41 @@ func (s *StockMarket) NotifyAsync(event StockEvent, message string) {
 		}(observer)
 	}
 
+	s.notifyDurable(event, message)
+
 	// 可以选择等待所有通知完成或不等待
 	// wg.Wait()
 }
 
 // UpdateStockPrice 更新股票价格并通知观察者
 func (s *StockMarket) UpdateStockPrice(symbol string, newPrice float64, message string, notifyThreshold float64) {
+	s.updateStockPriceAt(symbol, newPrice, message, notifyThreshold, time.Now())
+}
+
+// updateStockPriceAt 更新股票价格并通知观察者，事件时间为 at，返回是否发出了通知
+func (s *StockMarket) updateStockPriceAt(symbol string, newPrice float64, message string, notifyThreshold float64, at time.Time) bool {
 	s.mutex.Lock()
 	prevPrice, exists := s.stocks[symbol]
 	if !exists {
 		prevPrice = 0
 	}
 	s.stocks[symbol] = newPrice
+	s.version++
 	s.mutex.Unlock()
 
 	event := StockEvent{
 		Symbol:    symbol,
 		Price:     newPrice,
 		PrevPrice: prevPrice,
-		Timestamp: time.Now(),
+		Timestamp: at,
 	}
 
 	// 只有价格变动超过阈值时才通知
 	if !exists || event.IsPriceChange(notifyThreshold) {
 		s.Notify(event, message)
+		return true
 	}
+	return false
 }
 
 // GetStockPrice 获取股票价格
@@ -342,6 +356,11 @@ func NewMarketAnalyst(id, name, company string) *MarketAnalyst {
 
 // Update 实现了 Observer 接口的更新方法
 func (a *MarketAnalyst) Update(event StockEvent, message string) {
+	fmt.Printf("%s分析师(%s): %s\n", a.name, a.company, a.analyze(event))
+}
+
+// analyze 根据价格变动给出分析结论
+func (a *MarketAnalyst) analyze(event StockEvent) string {
 	var analysis string
 
 	// 根据价格变动提供分析
@@ -358,8 +377,7 @@ func (a *MarketAnalyst) Update(event StockEvent, message string) {
 	default:
 		analysis = "市场波动不大，维持原有策略"
 	}
-
-	fmt.Printf("%s分析师(%s): %s\n", a.name, a.company, analysis)
+	return analysis
 }
 
 // GetID 实现 Observer 接口的 GetID 方法
@@ -0,0 +1,286 @@
+package observer
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy 队列满时的处理策略
+type OverflowPolicy int
+
+const (
+	DropNew    OverflowPolicy = iota // 丢弃新事件
+	DropOldest                       // 丢弃最旧的事件，为新事件腾出位置
+	Block                            // 阻塞通知方，直到队列有空位（不丢事件）
+)
+
+// String 返回策略名称
+func (p OverflowPolicy) String() string {
+	switch p {
+	case DropNew:
+		return "丢弃新事件"
+	case DropOldest:
+		return "丢弃旧事件"
+	case Block:
+		return "阻塞等待"
+	default:
+		return "未知策略"
+	}
+}
+
+// QueueOptions 观察者独立队列的配置
+type QueueOptions struct {
+	Capacity int            // 队列容量
+	Policy   OverflowPolicy // 队列满时的策略
+}
+
+// QueueStats 观察者队列的统计信息
+type QueueStats struct {
+	ObserverID  string         // 观察者ID
+	Policy      OverflowPolicy // 溢出策略
+	Capacity    int            // 队列容量
+	Pending     int            // 尚未投递的事件数（滞后量）
+	Enqueued    uint64         // 已入队事件数
+	Delivered   uint64         // 已投递事件数
+	Dropped     uint64         // 因溢出丢弃的事件数
+	LastLatency time.Duration  // 最近一次从入队到投递的耗时
+	MaxLatency  time.Duration  // 入队到投递的最大耗时
+}
+
+// queuedEvent 队列中的事件
+type queuedEvent struct {
+	event      StockEvent
+	message    string
+	snapshot   *MarketSnapshot // 批量通知附带的市场快照，普通通知为 nil
+	enqueuedAt time.Time
+}
+
+// queuedObserver 为观察者提供独立的有界队列和投递协程
+// 它本身也实现了 Observer 接口，Update 只负责入队，因此慢观察者不会阻塞其他观察者
+type queuedObserver struct {
+	observer Observer
+	policy   OverflowPolicy
+	queue    chan queuedEvent
+	done     chan struct{} // 关闭后不再接收新事件，阻塞中的入队放弃并计为丢弃
+	sealed   chan struct{} // 所有入队都已结束，投递协程投递完剩余事件后退出
+	closed   chan struct{}
+	once     sync.Once
+
+	// sendMutex 入队期间持有读锁，close 通过获取写锁等待正在进行的入队结束，
+	// 保证入队成功的事件一定会被投递，不会在投递协程排空队列之后才放入队列
+	sendMutex sync.RWMutex
+
+	enqueued  atomic.Uint64
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
+
+	mutex       sync.Mutex
+	lastLatency time.Duration
+	maxLatency  time.Duration
+}
+
+// newQueuedObserver 创建带队列的观察者并启动投递协程
+func newQueuedObserver(observer Observer, opts QueueOptions) *queuedObserver {
+	if opts.Capacity <= 0 {
+		opts.Capacity = 1
+	}
+	q := &queuedObserver{
+		observer: observer,
+		policy:   opts.Policy,
+		queue:    make(chan queuedEvent, opts.Capacity),
+		done:     make(chan struct{}),
+		sealed:   make(chan struct{}),
+		closed:   make(chan struct{}),
+	}
+	go q.deliver()
+	return q
+}
+
+// Update 将事件放入队列，按溢出策略处理队列已满的情况
+func (q *queuedObserver) Update(event StockEvent, message string) {
+	q.enqueue(queuedEvent{event: event, message: message, enqueuedAt: time.Now()})
+}
+
+// UpdateWithSnapshot 将事件连同市场快照放入队列，投递时原样交给被包装的观察者
+func (q *queuedObserver) UpdateWithSnapshot(event StockEvent, message string, snapshot MarketSnapshot) {
+	q.enqueue(queuedEvent{event: event, message: message, snapshot: &snapshot, enqueuedAt: time.Now()})
+}
+
+// enqueue 按溢出策略将事件放入队列
+// 入队成功的事件在 close 返回前一定会被投递，关闭之后到达的事件计为丢弃
+func (q *queuedObserver) enqueue(item queuedEvent) {
+	q.sendMutex.RLock()
+	defer q.sendMutex.RUnlock()
+
+	for {
+		select {
+		case <-q.done:
+			q.dropped.Add(1)
+			return
+		default:
+		}
+
+		select {
+		case q.queue <- item:
+			q.enqueued.Add(1)
+			return
+		default:
+		}
+
+		switch q.policy {
+		case DropOldest:
+			// 取出最旧的事件后重试入队
+			select {
+			case <-q.queue:
+				q.dropped.Add(1)
+			default:
+			}
+		case Block:
+			select {
+			case q.queue <- item:
+				q.enqueued.Add(1)
+			case <-q.done:
+				q.dropped.Add(1)
+			}
+			return
+		default:
+			q.dropped.Add(1)
+			return
+		}
+	}
+}
+
+// GetID 返回被包装观察者的ID
+func (q *queuedObserver) GetID() string {
+	return q.observer.GetID()
+}
+
+// deliver 投递协程，逐个将事件交给观察者
+func (q *queuedObserver) deliver() {
+	defer close(q.closed)
+
+	for {
+		select {
+		case item := <-q.queue:
+			q.dispatch(item)
+		case <-q.sealed:
+			// 关闭前投递完剩余事件
+			for {
+				select {
+				case item := <-q.queue:
+					q.dispatch(item)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// dispatch 投递单个事件并记录延迟
+func (q *queuedObserver) dispatch(item queuedEvent) {
+	if so, ok := q.observer.(SnapshotObserver); ok && item.snapshot != nil {
+		so.UpdateWithSnapshot(item.event, item.message, *item.snapshot)
+	} else {
+		q.observer.Update(item.event, item.message)
+	}
+	q.delivered.Add(1)
+
+	latency := time.Since(item.enqueuedAt)
+	q.mutex.Lock()
+	q.lastLatency = latency
+	if latency > q.maxLatency {
+		q.maxLatency = latency
+	}
+	q.mutex.Unlock()
+}
+
+// close 停止接收新事件，等待剩余事件投递完成
+func (q *queuedObserver) close() {
+	q.once.Do(func() {
+		close(q.done)
+		// 等待正在进行的入队结束，之后的入队都会看到 done 已关闭
+		q.sendMutex.Lock()
+		close(q.sealed)
+		q.sendMutex.Unlock()
+	})
+	<-q.closed
+}
+
+// stats 返回当前统计信息
+func (q *queuedObserver) stats() QueueStats {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return QueueStats{
+		ObserverID:  q.observer.GetID(),
+		Policy:      q.policy,
+		Capacity:    cap(q.queue),
+		Pending:     len(q.queue),
+		Enqueued:    q.enqueued.Load(),
+		Delivered:   q.delivered.Load(),
+		Dropped:     q.dropped.Load(),
+		LastLatency: q.lastLatency,
+		MaxLatency:  q.maxLatency,
+	}
+}
+
+// RegisterQueued 以独立队列模式注册观察者
+// 每个观察者拥有自己的有界队列和投递协程，一个慢观察者不会拖慢其他观察者
+func (s *StockMarket) RegisterQueued(observer Observer, opts QueueOptions) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.HasObserverUnsafe(observer) {
+		fmt.Printf("观察者 %s 已经注册\n", observer.GetID())
+		return
+	}
+	s.addObserverUnsafe(newQueuedObserver(observer, opts), AllSymbols)
+	fmt.Printf("观察者 %s 已注册到股票市场（独立队列，容量 %d，%s）\n",
+		observer.GetID(), opts.Capacity, opts.Policy)
+}
+
+// QueueStats 返回指定观察者的队列统计，非队列模式的观察者返回 false
+func (s *StockMarket) QueueStats(observerID string) (QueueStats, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, obs := range s.observers {
+		if q, ok := obs.(*queuedObserver); ok && q.GetID() == observerID {
+			return q.stats(), true
+		}
+	}
+	return QueueStats{}, false
+}
+
+// AllQueueStats 返回所有队列模式观察者的统计信息
+func (s *StockMarket) AllQueueStats() []QueueStats {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	stats := make([]QueueStats, 0)
+	for _, obs := range s.observers {
+		if q, ok := obs.(*queuedObserver); ok {
+			stats = append(stats, q.stats())
+		}
+	}
+	return stats
+}
+
+// Close 关闭所有观察者队列，等待已入队的事件投递完成
+func (s *StockMarket) Close() {
+	s.mutex.RLock()
+	queues := make([]*queuedObserver, 0)
+	for _, obs := range s.observers {
+		if q, ok := obs.(*queuedObserver); ok {
+			queues = append(queues, q)
+		}
+	}
+	s.mutex.RUnlock()
+
+	for _, q := range queues {
+		q.close()
+	}
+}
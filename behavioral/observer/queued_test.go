@@ -0,0 +1,256 @@
+package observer
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestEvent 创建测试事件
+func newTestEvent(symbol string, price float64) StockEvent {
+	return StockEvent{Symbol: symbol, Price: price, PrevPrice: price - 1, Timestamp: time.Now()}
+}
+
+// TestQueuedObserverIsolation 测试慢观察者不会阻塞其他观察者
+func TestQueuedObserverIsolation(t *testing.T) {
+	assert := assert.New(t)
+	market := NewStockMarket()
+
+	release := make(chan struct{})
+	fastDone := make(chan struct{}, 10)
+
+	captureOutput(func() {
+		market.RegisterQueued(&testObserver{
+			id: "slow",
+			updateFn: func(event StockEvent, message string) {
+				<-release
+			},
+		}, QueueOptions{Capacity: 10, Policy: Block})
+		market.RegisterQueued(&testObserver{
+			id: "fast",
+			updateFn: func(event StockEvent, message string) {
+				fastDone <- struct{}{}
+			},
+		}, QueueOptions{Capacity: 10, Policy: Block})
+
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			market.Notify(newTestEvent("AAPL", float64(100+i)), "更新")
+		}
+		assert.Less(time.Since(start), 100*time.Millisecond, "通知不应被慢观察者阻塞")
+	})
+
+	// 快观察者在慢观察者被阻塞时依然能收到全部事件
+	for i := 0; i < 3; i++ {
+		select {
+		case <-fastDone:
+		case <-time.After(time.Second):
+			t.Fatal("快观察者未能及时收到事件")
+		}
+	}
+
+	stats, ok := market.QueueStats("slow")
+	assert.True(ok, "应能获取慢观察者的队列统计")
+	assert.GreaterOrEqual(stats.Pending, 2, "慢观察者应存在积压事件")
+
+	close(release)
+	captureOutput(market.Close)
+
+	stats, _ = market.QueueStats("slow")
+	assert.Equal(uint64(3), stats.Delivered, "关闭时应投递完所有剩余事件")
+	assert.Equal(0, stats.Pending, "关闭后不应有积压")
+}
+
+// TestQueueOverflowPolicies 测试三种溢出策略
+func TestQueueOverflowPolicies(t *testing.T) {
+	// 构造一个被阻塞的观察者：第一个事件卡在投递中，其余事件留在队列
+	setup := func(policy OverflowPolicy) (*StockMarket, chan struct{}, *[]float64, *sync.Mutex) {
+		market := NewStockMarket()
+		release := make(chan struct{})
+		started := make(chan struct{}, 1)
+		var received []float64
+		var mutex sync.Mutex
+
+		captureOutput(func() {
+			market.RegisterQueued(&testObserver{
+				id: "observer",
+				updateFn: func(event StockEvent, message string) {
+					select {
+					case started <- struct{}{}:
+					default:
+					}
+					<-release
+					mutex.Lock()
+					received = append(received, event.Price)
+					mutex.Unlock()
+				},
+			}, QueueOptions{Capacity: 2, Policy: policy})
+			market.Notify(newTestEvent("X", 1), "")
+		})
+		<-started
+		return market, release, &received, &mutex
+	}
+
+	t.Run("丢弃新事件", func(t *testing.T) {
+		assert := assert.New(t)
+		market, release, received, mutex := setup(DropNew)
+
+		captureOutput(func() {
+			for i := 2; i <= 5; i++ {
+				market.Notify(newTestEvent("X", float64(i)), "")
+			}
+		})
+		stats, _ := market.QueueStats("observer")
+		assert.Equal(uint64(2), stats.Dropped, "应丢弃2个新事件")
+
+		close(release)
+		captureOutput(market.Close)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		assert.Equal([]float64{1, 2, 3}, *received, "应保留最早的事件")
+	})
+
+	t.Run("丢弃旧事件", func(t *testing.T) {
+		assert := assert.New(t)
+		market, release, received, mutex := setup(DropOldest)
+
+		captureOutput(func() {
+			for i := 2; i <= 5; i++ {
+				market.Notify(newTestEvent("X", float64(i)), "")
+			}
+		})
+		stats, _ := market.QueueStats("observer")
+		assert.Equal(uint64(2), stats.Dropped, "应丢弃2个旧事件")
+
+		close(release)
+		captureOutput(market.Close)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		assert.Equal([]float64{1, 4, 5}, *received, "应保留最新的事件")
+	})
+
+	t.Run("阻塞等待", func(t *testing.T) {
+		assert := assert.New(t)
+		market, release, received, mutex := setup(Block)
+
+		var notified atomic.Bool
+		go func() {
+			captureOutput(func() {
+				for i := 2; i <= 5; i++ {
+					market.Notify(newTestEvent("X", float64(i)), "")
+				}
+			})
+			notified.Store(true)
+		}()
+
+		time.Sleep(50 * time.Millisecond)
+		assert.False(notified.Load(), "队列满时通知方应被阻塞")
+
+		close(release)
+		assert.Eventually(notified.Load, time.Second, 10*time.Millisecond, "释放后通知方应继续")
+		captureOutput(market.Close)
+
+		stats, _ := market.QueueStats("observer")
+		assert.Equal(uint64(0), stats.Dropped, "阻塞策略不应丢弃事件")
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		assert.Equal([]float64{1, 2, 3, 4, 5}, *received, "应按顺序收到所有事件")
+	})
+}
+
+// TestQueuedObserverDeregister 测试注销时投递剩余事件并停止协程
+func TestQueuedObserverDeregister(t *testing.T) {
+	assert := assert.New(t)
+	market := NewStockMarket()
+
+	var count atomic.Int32
+	observer := &testObserver{
+		id: "q1",
+		updateFn: func(event StockEvent, message string) {
+			time.Sleep(5 * time.Millisecond)
+			count.Add(1)
+		},
+	}
+
+	captureOutput(func() {
+		market.RegisterQueued(observer, QueueOptions{Capacity: 10, Policy: Block})
+		market.RegisterQueued(observer, QueueOptions{Capacity: 10, Policy: Block})
+		assert.Equal(1, market.CountObservers(), "重复注册应被忽略")
+
+		for i := 0; i < 5; i++ {
+			market.Notify(newTestEvent("Y", float64(i)), "")
+		}
+		market.Deregister(observer)
+	})
+
+	assert.Equal(int32(5), count.Load(), "注销前应投递完已入队的事件")
+	assert.False(market.HasObserver(observer))
+	_, ok := market.QueueStats("q1")
+	assert.False(ok, "注销后不应再有队列统计")
+}
+
+// TestAllQueueStats 测试获取所有队列统计
+func TestAllQueueStats(t *testing.T) {
+	assert := assert.New(t)
+	market := NewStockMarket()
+
+	captureOutput(func() {
+		market.Register(NewMarketAnalyst("a1", "同步分析师", "证券公司"))
+		market.RegisterQueued(&testObserver{id: "q1"}, QueueOptions{Capacity: 4, Policy: DropNew})
+		market.RegisterQueued(&testObserver{id: "q2"}, QueueOptions{Capacity: 8, Policy: DropOldest})
+		market.Notify(newTestEvent("Z", 10), "")
+		market.Close()
+	})
+
+	stats := market.AllQueueStats()
+	assert.Len(stats, 2, "只有队列模式的观察者有统计")
+	for _, s := range stats {
+		assert.Equal(uint64(1), s.Enqueued)
+		assert.Equal(uint64(1), s.Delivered)
+	}
+	assert.Equal(8, stats[1].Capacity)
+	assert.Equal("丢弃旧事件", stats[1].Policy.String())
+}
+
+// TestQueuedObserverEnqueueDuringClose 测试关闭与入队并发时，每个事件要么被投递，要么计为丢弃
+func TestQueuedObserverEnqueueDuringClose(t *testing.T) {
+	assert := assert.New(t)
+	const rounds, senders, perSender = 50, 16, 50
+
+	for _, policy := range []OverflowPolicy{DropNew, DropOldest, Block} {
+		for round := 0; round < rounds; round++ {
+			var received atomic.Uint64
+			q := newQueuedObserver(&testObserver{
+				id:       "observer",
+				updateFn: func(event StockEvent, message string) { received.Add(1) },
+			}, QueueOptions{Capacity: senders * perSender, Policy: policy})
+
+			var wg sync.WaitGroup
+			for i := 0; i < senders; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < perSender; j++ {
+						q.Update(newTestEvent("AAPL", float64(j)), "")
+					}
+				}()
+			}
+			q.close()
+			delivered := received.Load()
+			wg.Wait()
+
+			stats := q.stats()
+			if !assert.Equal(uint64(senders*perSender), stats.Delivered+stats.Dropped, "%s: 每个事件要么被投递，要么计为丢弃", policy) ||
+				!assert.Equal(delivered, received.Load(), "%s: 关闭后不应再投递事件", policy) ||
+				!assert.Equal(0, stats.Pending, "%s: 关闭后不应有积压", policy) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package observer
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidScenario 表示行情剧本的格式或内容不合法
+var ErrInvalidScenario = errors.New("无效的行情剧本")
+
+// Clock 模拟器使用的时钟，用于控制回放节奏
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock 使用系统时间的时钟
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// RealClock 返回使用系统时间的时钟
+func RealClock() Clock {
+	return realClock{}
+}
+
+// SimulatedClock 虚拟时钟，等待时立即把时间向前拨动，不会真正阻塞
+// 使用虚拟时钟回放剧本是确定性的，并且与剧本的时间跨度无关
+type SimulatedClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewSimulatedClock 创建从 start 开始计时的虚拟时钟
+func NewSimulatedClock(start time.Time) *SimulatedClock {
+	return &SimulatedClock{now: start}
+}
+
+// Now 返回虚拟时钟的当前时间
+func (c *SimulatedClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After 把虚拟时间向前拨动 d，返回的通道立即可读
+func (c *SimulatedClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	ch <- c.Advance(d)
+	return ch
+}
+
+// Advance 把虚拟时间向前拨动 d 并返回拨动后的时间
+func (c *SimulatedClock) Advance(d time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d > 0 {
+		c.now = c.now.Add(d)
+	}
+	return c.now
+}
+
+// Tick 剧本中的一条行情
+type Tick struct {
+	Time    time.Time `json:"time"`              // 行情时间
+	Symbol  string    `json:"symbol"`            // 股票代码
+	Price   float64   `json:"price"`             // 价格
+	Message string    `json:"message,omitempty"` // 附带的市场公告
+}
+
+// Scenario 按时间排序的行情剧本
+type Scenario struct {
+	Name  string `json:"name"`
+	Ticks []Tick `json:"ticks"`
+}
+
+// Duration 返回剧本第一条到最后一条行情的时间跨度
+func (s Scenario) Duration() time.Duration {
+	if len(s.Ticks) == 0 {
+		return 0
+	}
+	return s.Ticks[len(s.Ticks)-1].Time.Sub(s.Ticks[0].Time)
+}
+
+// Validate 检查剧本中的行情是否合法并按时间排序
+func (s Scenario) Validate() error {
+	for i, tick := range s.Ticks {
+		switch {
+		case tick.Symbol == "":
+			return fmt.Errorf("%w: 第 %d 条行情缺少股票代码", ErrInvalidScenario, i+1)
+		case tick.Price <= 0:
+			return fmt.Errorf("%w: 第 %d 条行情的价格 %.2f 必须为正数", ErrInvalidScenario, i+1, tick.Price)
+		case tick.Time.IsZero():
+			return fmt.Errorf("%w: 第 %d 条行情缺少时间", ErrInvalidScenario, i+1)
+		case i > 0 && tick.Time.Before(s.Ticks[i-1].Time):
+			return fmt.Errorf("%w: 第 %d 条行情的时间早于前一条", ErrInvalidScenario, i+1)
+		}
+	}
+	return nil
+}
+
+// LoadScenarioJSON 从 JSON 读取行情剧本，格式为 {"name": ..., "ticks": [{"time": RFC3339, "symbol": ..., "price": ...}]}
+func LoadScenarioJSON(r io.Reader) (Scenario, error) {
+	var scenario Scenario
+	if err := json.NewDecoder(r).Decode(&scenario); err != nil {
+		return Scenario{}, fmt.Errorf("%w: %v", ErrInvalidScenario, err)
+	}
+	if err := scenario.Validate(); err != nil {
+		return Scenario{}, err
+	}
+	return scenario, nil
+}
+
+// LoadScenarioCSV 从 CSV 读取行情剧本
+// 第一行为表头，必须包含 time、symbol、price 列，可选 message 列，列的顺序任意；时间使用 RFC3339 格式
+func LoadScenarioCSV(name string, r io.Reader) (Scenario, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return Scenario{}, fmt.Errorf("%w: 读取表头失败: %v", ErrInvalidScenario, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, required := range []string{"time", "symbol", "price"} {
+		if _, ok := columns[required]; !ok {
+			return Scenario{}, fmt.Errorf("%w: 缺少 %s 列", ErrInvalidScenario, required)
+		}
+	}
+
+	scenario := Scenario{Name: name}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Scenario{}, fmt.Errorf("%w: %v", ErrInvalidScenario, err)
+		}
+		field := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		at, err := time.Parse(time.RFC3339, field("time"))
+		if err != nil {
+			return Scenario{}, fmt.Errorf("%w: 第 %d 行时间格式错误: %v", ErrInvalidScenario, line, err)
+		}
+		price, err := strconv.ParseFloat(field("price"), 64)
+		if err != nil {
+			return Scenario{}, fmt.Errorf("%w: 第 %d 行价格格式错误: %v", ErrInvalidScenario, line, err)
+		}
+		scenario.Ticks = append(scenario.Ticks, Tick{
+			Time:    at,
+			Symbol:  field("symbol"),
+			Price:   price,
+			Message: field("message"),
+		})
+	}
+
+	if err := scenario.Validate(); err != nil {
+		return Scenario{}, err
+	}
+	return scenario, nil
+}
+
+// SimulatorOption 行情模拟器的配置选项
+type SimulatorOption func(*MarketSimulator)
+
+// WithClock 设置控制回放节奏的时钟，默认使用系统时间
+func WithClock(clock Clock) SimulatorOption {
+	return func(m *MarketSimulator) {
+		if clock != nil {
+			m.clock = clock
+		}
+	}
+}
+
+// WithSpeed 设置回放倍速，例如 60 表示剧本中的一分钟回放一秒；0 表示不等待，尽快回放
+func WithSpeed(speed float64) SimulatorOption {
+	return func(m *MarketSimulator) {
+		if speed >= 0 {
+			m.speed = speed
+		}
+	}
+}
+
+// WithNotifyThreshold 设置通知阈值(百分比)，与 UpdateStockPrice 的 notifyThreshold 含义相同
+func WithNotifyThreshold(threshold float64) SimulatorOption {
+	return func(m *MarketSimulator) {
+		m.threshold = threshold
+	}
+}
+
+// SimulationStats 一次回放的统计信息
+type SimulationStats struct {
+	Ticks            int           // 回放的行情数量
+	Notifications    int           // 触发通知的行情数量
+	Symbols          int           // 涉及的股票数量
+	ScenarioDuration time.Duration // 剧本的时间跨度
+	Elapsed          time.Duration // 按模拟器时钟计算的回放耗时
+}
+
+// MarketSimulator 行情模拟器，按剧本驱动 StockMarket 的价格更新
+//
+// 每条行情按照与上一条行情的时间间隔(除以倍速)等待后调用价格更新，事件时间使用剧本中的行情时间，
+// 因此观察者收到的事件与剧本完全一致。配合 SimulatedClock 使用时回放是确定性的，不会真正等待
+type MarketSimulator struct {
+	market    *StockMarket
+	clock     Clock
+	speed     float64
+	threshold float64
+}
+
+// NewMarketSimulator 创建驱动 market 的行情模拟器，默认使用系统时间按原速回放
+func NewMarketSimulator(market *StockMarket, opts ...SimulatorOption) *MarketSimulator {
+	m := &MarketSimulator{
+		market: market,
+		clock:  RealClock(),
+		speed:  1,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Run 回放剧本，ctx 取消时停止回放并返回已回放部分的统计信息和 ctx 的错误
+func (m *MarketSimulator) Run(ctx context.Context, scenario Scenario) (stats SimulationStats, err error) {
+	if err := scenario.Validate(); err != nil {
+		return SimulationStats{}, err
+	}
+
+	stats.ScenarioDuration = scenario.Duration()
+	symbols := make(map[string]bool)
+	start := m.clock.Now()
+	defer func() {
+		stats.Symbols = len(symbols)
+		stats.Elapsed = m.clock.Now().Sub(start)
+	}()
+
+	for i, tick := range scenario.Ticks {
+		if i > 0 {
+			if err := m.wait(ctx, tick.Time.Sub(scenario.Ticks[i-1].Time)); err != nil {
+				return stats, err
+			}
+		} else if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+
+		message := tick.Message
+		if message == "" {
+			message = fmt.Sprintf("%s 行情回放", scenario.Name)
+		}
+		if m.market.updateStockPriceAt(tick.Symbol, tick.Price, message, m.threshold, tick.Time) {
+			stats.Notifications++
+		}
+		stats.Ticks++
+		symbols[tick.Symbol] = true
+	}
+	return stats, nil
+}
+
+// wait 按倍速等待剧本中的时间间隔
+func (m *MarketSimulator) wait(ctx context.Context, gap time.Duration) error {
+	if m.speed == 0 || gap <= 0 {
+		return ctx.Err()
+	}
+	select {
+	case <-m.clock.After(time.Duration(float64(gap) / m.speed)):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,157 @@
+package observer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testScenarioCSV = `time,symbol,price,message
+2024-03-01T09:30:00Z,AAPL,100,开盘
+2024-03-01T09:30:30Z,MSFT,300,
+2024-03-01T09:31:00Z,AAPL,100.5,
+2024-03-01T09:32:00Z,AAPL,110,利好消息
+`
+
+// TestLoadScenario 测试从 CSV 和 JSON 读取剧本
+func TestLoadScenario(t *testing.T) {
+	assert := assert.New(t)
+
+	scenario, err := LoadScenarioCSV("开盘", strings.NewReader(testScenarioCSV))
+	assert.NoError(err)
+	assert.Len(scenario.Ticks, 4)
+	assert.Equal("开盘", scenario.Ticks[0].Message)
+	assert.Equal(110.0, scenario.Ticks[3].Price)
+	assert.Equal(2*time.Minute, scenario.Duration())
+
+	fromJSON, err := LoadScenarioJSON(strings.NewReader(`{"name": "开盘", "ticks": [
+		{"time": "2024-03-01T09:30:00Z", "symbol": "AAPL", "price": 100, "message": "开盘"},
+		{"time": "2024-03-01T09:30:30Z", "symbol": "MSFT", "price": 300},
+		{"time": "2024-03-01T09:31:00Z", "symbol": "AAPL", "price": 100.5},
+		{"time": "2024-03-01T09:32:00Z", "symbol": "AAPL", "price": 110, "message": "利好消息"}
+	]}`))
+	assert.NoError(err)
+	assert.Equal(scenario, fromJSON, "两种格式应读取出相同的剧本")
+
+	invalid := []string{
+		"symbol,price\nAAPL,1\n",
+		"time,symbol,price\nyesterday,AAPL,1\n",
+		"time,symbol,price\n2024-03-01T09:30:00Z,AAPL,abc\n",
+		"time,symbol,price\n2024-03-01T09:30:00Z,AAPL,-1\n",
+		"time,symbol,price\n2024-03-01T09:31:00Z,AAPL,1\n2024-03-01T09:30:00Z,AAPL,2\n",
+	}
+	for _, input := range invalid {
+		_, err := LoadScenarioCSV("bad", strings.NewReader(input))
+		assert.ErrorIs(err, ErrInvalidScenario, input)
+	}
+	_, err = LoadScenarioJSON(strings.NewReader(`{"ticks": [{"symbol": "AAPL", "price": 1}]}`))
+	assert.ErrorIs(err, ErrInvalidScenario, "缺少时间的行情不合法")
+}
+
+// TestMarketSimulatorDeterministic 测试使用虚拟时钟加速回放
+func TestMarketSimulatorDeterministic(t *testing.T) {
+	assert := assert.New(t)
+	scenario, err := LoadScenarioCSV("开盘", strings.NewReader(testScenarioCSV))
+	assert.NoError(err)
+
+	market := NewStockMarket()
+	var events []StockEvent
+	var messages []string
+	clock := NewSimulatedClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	var stats SimulationStats
+	captureOutput(func() {
+		market.Register(&testObserver{id: "recorder", updateFn: func(event StockEvent, message string) {
+			events = append(events, event)
+			messages = append(messages, message)
+		}})
+		simulator := NewMarketSimulator(market, WithClock(clock), WithSpeed(60), WithNotifyThreshold(1))
+		stats, err = simulator.Run(context.Background(), scenario)
+	})
+	assert.NoError(err)
+
+	// 100 -> 100.5 只变动了 0.5%，低于阈值不通知
+	assert.Equal(SimulationStats{
+		Ticks:            4,
+		Notifications:    3,
+		Symbols:          2,
+		ScenarioDuration: 2 * time.Minute,
+		Elapsed:          2 * time.Second, // 60 倍速回放两分钟的剧本
+	}, stats)
+
+	assert.Len(events, 3)
+	assert.Equal(scenario.Ticks[3].Time, events[2].Timestamp, "事件时间应使用剧本中的行情时间")
+	assert.Equal(100.5, events[2].PrevPrice)
+	assert.Equal([]string{"开盘", "开盘 行情回放", "利好消息"}, messages)
+
+	price, _ := market.GetStockPrice("AAPL")
+	assert.Equal(110.0, price)
+}
+
+// TestMarketSimulatorCancel 测试取消回放
+func TestMarketSimulatorCancel(t *testing.T) {
+	assert := assert.New(t)
+	scenario, _ := LoadScenarioCSV("开盘", strings.NewReader(testScenarioCSV))
+
+	// 使用系统时钟按原速回放，第二条行情前需要等待 30 秒
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	var stats SimulationStats
+	var err error
+	captureOutput(func() {
+		stats, err = NewMarketSimulator(NewStockMarket()).Run(ctx, scenario)
+	})
+	assert.ErrorIs(err, context.DeadlineExceeded)
+	assert.Equal(1, stats.Ticks, "取消前只应回放第一条行情")
+
+	// 不等待模式下立即回放完整个剧本
+	captureOutput(func() {
+		stats, err = NewMarketSimulator(NewStockMarket(), WithSpeed(0)).Run(context.Background(), scenario)
+	})
+	assert.NoError(err)
+	assert.Equal(4, stats.Ticks)
+}
+
+// newBenchmarkScenario 生成 n 条行情的随机游走剧本，每秒一条，覆盖 symbols 只股票
+func newBenchmarkScenario(n, symbols int) Scenario {
+	start := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	scenario := Scenario{Name: "基准", Ticks: make([]Tick, n)}
+	for i := range scenario.Ticks {
+		scenario.Ticks[i] = Tick{
+			Time:   start.Add(time.Duration(i) * time.Second),
+			Symbol: fmt.Sprintf("SYM%d", i%symbols),
+			Price:  100 + float64(i%7) - float64(i%3),
+		}
+	}
+	return scenario
+}
+
+// BenchmarkMarketSimulator 回放一个交易日规模的剧本，衡量观察者在真实节奏行情下的开销
+func BenchmarkMarketSimulator(b *testing.B) {
+	// 通知会输出到标准输出，基准测试期间丢弃
+	stdout := os.Stdout
+	devNull, _ := os.Open(os.DevNull)
+	os.Stdout = devNull
+	defer func() { os.Stdout = stdout }()
+
+	market := NewStockMarket()
+	for i := 0; i < 100; i++ {
+		market.Subscribe(&testObserver{id: fmt.Sprintf("obs-%d", i)}, fmt.Sprintf("SYM%d", i%20))
+	}
+	scenario := newBenchmarkScenario(23400, 20)
+	clock := NewSimulatedClock(scenario.Ticks[0].Time)
+	simulator := NewMarketSimulator(market, WithClock(clock), WithNotifyThreshold(2))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := simulator.Run(context.Background(), scenario); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package observer
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"time"
+)
+
+// MarketSnapshot 某一时刻全部股票价格的不可变视图
+// 快照持有价格表的独立副本，之后的价格更新不会影响已经取得的快照，可以安全地在多个协程间共享
+type MarketSnapshot struct {
+	prices  map[string]float64
+	version uint64
+	takenAt time.Time
+}
+
+// Price 返回快照中某只股票的价格
+func (m MarketSnapshot) Price(symbol string) (float64, bool) {
+	price, ok := m.prices[symbol]
+	return price, ok
+}
+
+// Symbols 返回快照中的所有股票代码，按字典序排列
+func (m MarketSnapshot) Symbols() []string {
+	symbols := make([]string, 0, len(m.prices))
+	for symbol := range m.prices {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// Len 返回快照中的股票数量
+func (m MarketSnapshot) Len() int {
+	return len(m.prices)
+}
+
+// Prices 返回价格表的副本，修改返回值不会影响快照
+func (m MarketSnapshot) Prices() map[string]float64 {
+	return maps.Clone(m.prices)
+}
+
+// AveragePrice 返回快照中所有股票的平均价格，空快照返回 0
+func (m MarketSnapshot) AveragePrice() float64 {
+	if len(m.prices) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, price := range m.prices {
+		total += price
+	}
+	return total / float64(len(m.prices))
+}
+
+// Version 返回快照对应的市场版本号，每次价格更新版本号加一
+func (m MarketSnapshot) Version() uint64 {
+	return m.version
+}
+
+// Time 返回快照的生成时间
+func (m MarketSnapshot) Time() time.Time {
+	return m.takenAt
+}
+
+// String 返回快照的可读摘要
+func (m MarketSnapshot) String() string {
+	return fmt.Sprintf("市场快照 v%d: %d 只股票, 均价 %.2f", m.version, m.Len(), m.AveragePrice())
+}
+
+// SnapshotObserver 可选接口，希望基于一致数据计算的观察者实现它
+// 批量通知时市场会调用 UpdateWithSnapshot 而不是 Update，snapshot 是本批更新全部生效后的价格视图，
+// 不会被通知期间的并发更新改变
+type SnapshotObserver interface {
+	Observer
+	UpdateWithSnapshot(event StockEvent, message string, snapshot MarketSnapshot)
+}
+
+// PriceUpdate 批量更新中的一项
+type PriceUpdate struct {
+	Symbol string  // 股票代码
+	Price  float64 // 新价格
+}
+
+// Snapshot 返回当前全部股票价格的不可变视图
+func (s *StockMarket) Snapshot() MarketSnapshot {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.snapshotUnsafe()
+}
+
+// snapshotUnsafe 生成价格快照（非线程安全，只在加锁后使用）
+func (s *StockMarket) snapshotUnsafe() MarketSnapshot {
+	return MarketSnapshot{
+		prices:  maps.Clone(s.stocks),
+		version: s.version,
+		takenAt: time.Now(),
+	}
+}
+
+// UpdateStockPrices 原子地批量更新股票价格，并基于同一份快照通知观察者
+// 所有价格在同一次加锁内生效并生成快照，实现了 SnapshotObserver 的观察者在整批通知中看到的都是这份快照，
+// 即使通知期间价格继续被并发更新。通知规则与 UpdateStockPrice 相同：首次报价或变动超过阈值时才通知。
+// 同一批次中重复出现的股票以最后一项为准，返回本批次的快照
+func (s *StockMarket) UpdateStockPrices(updates []PriceUpdate, message string, notifyThreshold float64) MarketSnapshot {
+	now := time.Now()
+	events := make([]StockEvent, 0, len(updates))
+	index := make(map[string]int, len(updates))
+	quoted := make([]bool, 0, len(updates)) // 批次开始前是否已有报价
+
+	s.mutex.Lock()
+	for _, update := range updates {
+		prevPrice, exists := s.stocks[update.Symbol]
+		s.stocks[update.Symbol] = update.Price
+		s.version++
+
+		// 重复出现的股票合并为一个事件，保留批次开始前的价格
+		if i, ok := index[update.Symbol]; ok {
+			events[i].Price = update.Price
+			continue
+		}
+		index[update.Symbol] = len(events)
+		events = append(events, StockEvent{
+			Symbol:    update.Symbol,
+			Price:     update.Price,
+			PrevPrice: prevPrice,
+			Timestamp: now,
+		})
+		quoted = append(quoted, exists)
+	}
+	snapshot := s.snapshotUnsafe()
+	s.mutex.Unlock()
+
+	for i, event := range events {
+		if !quoted[i] || event.IsPriceChange(notifyThreshold) {
+			s.NotifyWithSnapshot(event, message, snapshot)
+		}
+	}
+	return snapshot
+}
+
+// NotifyWithSnapshot 同步通知订阅了该股票的观察者，并附带市场快照
+// 实现了 SnapshotObserver 的观察者收到快照，其他观察者按普通方式通知
+func (s *StockMarket) NotifyWithSnapshot(event StockEvent, message string, snapshot MarketSnapshot) {
+	observers := s.subscribersFor(event.Symbol)
+
+	fmt.Printf("\n【市场公告】%s\n", message)
+	fmt.Printf("股票行情: %s\n", event.String())
+
+	for _, observer := range observers {
+		if so, ok := observer.(SnapshotObserver); ok {
+			so.UpdateWithSnapshot(event, message, snapshot)
+		} else {
+			observer.Update(event, message)
+		}
+	}
+	s.notifyDurable(event, message)
+}
+
+// UpdateWithSnapshot 基于市场快照给出分析，在单只股票的分析之外补充整体市场概况
+func (a *MarketAnalyst) UpdateWithSnapshot(event StockEvent, message string, snapshot MarketSnapshot) {
+	fmt.Printf("%s分析师(%s): %s [市场共 %d 只股票, 均价 %.2f]\n",
+		a.name, a.company, a.analyze(event), snapshot.Len(), snapshot.AveragePrice())
+}
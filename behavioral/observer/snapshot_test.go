@@ -0,0 +1,200 @@
+package observer
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// snapshotObserver 记录收到的快照的测试观察者
+type snapshotObserver struct {
+	testObserver
+	mu        sync.Mutex
+	snapshots []MarketSnapshot
+	events    []StockEvent
+	onUpdate  func(StockEvent, MarketSnapshot)
+}
+
+func (o *snapshotObserver) UpdateWithSnapshot(event StockEvent, message string, snapshot MarketSnapshot) {
+	if o.onUpdate != nil {
+		o.onUpdate(event, snapshot)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, event)
+	o.snapshots = append(o.snapshots, snapshot)
+}
+
+func (o *snapshotObserver) received() ([]StockEvent, []MarketSnapshot) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]StockEvent(nil), o.events...), append([]MarketSnapshot(nil), o.snapshots...)
+}
+
+// TestMarketSnapshotIsImmutable 测试快照不受之后价格更新和返回值修改的影响
+func TestMarketSnapshotIsImmutable(t *testing.T) {
+	assert := assert.New(t)
+	market := NewStockMarket()
+
+	captureOutput(func() {
+		market.UpdateStockPrice("AAPL", 150, "初始价格", 0)
+		market.UpdateStockPrice("MSFT", 300, "初始价格", 0)
+	})
+
+	snap := market.Snapshot()
+	assert.Equal(2, snap.Len())
+	assert.Equal([]string{"AAPL", "MSFT"}, snap.Symbols())
+	assert.Equal(uint64(2), snap.Version())
+	assert.Equal(225.0, snap.AveragePrice())
+	assert.False(snap.Time().IsZero())
+
+	captureOutput(func() {
+		market.UpdateStockPrice("AAPL", 999, "暴涨", 0)
+		market.UpdateStockPrice("TSLA", 700, "新股上市", 0)
+	})
+
+	price, ok := snap.Price("AAPL")
+	assert.True(ok)
+	assert.Equal(150.0, price, "快照不应受后续更新影响")
+	_, ok = snap.Price("TSLA")
+	assert.False(ok)
+
+	prices := snap.Prices()
+	prices["AAPL"] = 1
+	price, _ = snap.Price("AAPL")
+	assert.Equal(150.0, price, "修改 Prices 的返回值不应影响快照")
+
+	assert.Equal(uint64(4), market.Snapshot().Version())
+	assert.Equal(0.0, MarketSnapshot{}.AveragePrice())
+}
+
+// TestUpdateStockPricesSharesSnapshot 测试批量更新中所有通知共享同一份包含全部新价格的快照
+func TestUpdateStockPricesSharesSnapshot(t *testing.T) {
+	assert := assert.New(t)
+	market := NewStockMarket()
+	plain := &testObserver{id: "plain"}
+	plainCalls := 0
+	plain.updateFn = func(StockEvent, string) { plainCalls++ }
+	observer := &snapshotObserver{testObserver: testObserver{id: "snap"}}
+
+	var snap MarketSnapshot
+	captureOutput(func() {
+		market.Register(plain)
+		market.Register(observer)
+		market.UpdateStockPrice("AAPL", 100, "初始价格", 0)
+		market.UpdateStockPrice("MSFT", 200, "初始价格", 0)
+		plainCalls = 0
+
+		snap = market.UpdateStockPrices([]PriceUpdate{
+			{Symbol: "AAPL", Price: 110},
+			{Symbol: "MSFT", Price: 201}, // 0.5%，低于阈值
+			{Symbol: "TSLA", Price: 700}, // 首次报价，总是通知
+			{Symbol: "AAPL", Price: 120}, // 同一批次重复出现，以最后一项为准
+		}, "收盘批量更新", 1.0)
+	})
+
+	events, snapshots := observer.received()
+	assert.Len(events, 2, "MSFT 变动低于阈值不应通知")
+	assert.Equal("AAPL", events[0].Symbol)
+	assert.Equal(100.0, events[0].PrevPrice, "重复项应保留批次开始前的价格")
+	assert.Equal(120.0, events[0].Price)
+	assert.Equal("TSLA", events[1].Symbol)
+	assert.Equal(2, plainCalls, "普通观察者仍按 Update 接收通知")
+
+	for _, s := range snapshots {
+		assert.Equal(snap.Version(), s.Version(), "整批通知应共享同一份快照")
+		assert.Equal(map[string]float64{"AAPL": 120, "MSFT": 201, "TSLA": 700}, s.Prices())
+	}
+}
+
+// TestSnapshotConsistentDuringConcurrentUpdates 测试通知期间价格被并发修改时，观察者看到的快照保持一致
+func TestSnapshotConsistentDuringConcurrentUpdates(t *testing.T) {
+	assert := assert.New(t)
+	market := NewStockMarket()
+
+	observer := &snapshotObserver{testObserver: testObserver{id: "snap"}}
+	observer.onUpdate = func(event StockEvent, snapshot MarketSnapshot) {
+		// 通知期间让其他协程修改价格
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			market.mutex.Lock()
+			market.stocks["AAPL"] = -1
+			market.stocks["MSFT"] = -1
+			market.version++
+			market.mutex.Unlock()
+		}()
+		wg.Wait()
+
+		price, _ := snapshot.Price(event.Symbol)
+		assert.Equal(event.Price, price, "快照中的价格应与事件一致")
+	}
+
+	captureOutput(func() {
+		market.Register(observer)
+		market.UpdateStockPrices([]PriceUpdate{
+			{Symbol: "AAPL", Price: 150},
+			{Symbol: "MSFT", Price: 300},
+		}, "开盘", 0)
+	})
+
+	_, snapshots := observer.received()
+	assert.Len(snapshots, 2)
+	for _, s := range snapshots {
+		assert.Equal(map[string]float64{"AAPL": 150, "MSFT": 300}, s.Prices())
+	}
+	price, _ := market.GetStockPrice("AAPL")
+	assert.Equal(-1.0, price, "实时价格已被并发更新")
+}
+
+// TestQueuedObserverReceivesSnapshot 测试队列模式的观察者也能收到批量通知的快照
+func TestQueuedObserverReceivesSnapshot(t *testing.T) {
+	assert := assert.New(t)
+	market := NewStockMarket()
+	observer := &snapshotObserver{testObserver: testObserver{id: "queued"}}
+
+	captureOutput(func() {
+		market.RegisterQueued(observer, QueueOptions{Capacity: 10, Policy: Block})
+		market.UpdateStockPrices([]PriceUpdate{{Symbol: "AAPL", Price: 150}}, "开盘", 0)
+		market.UpdateStockPrice("AAPL", 160, "普通更新", 0)
+		market.Close()
+	})
+
+	events, snapshots := observer.received()
+	assert.Len(events, 1, "普通通知不经过快照路径")
+	assert.Len(snapshots, 1)
+	price, _ := snapshots[0].Price("AAPL")
+	assert.Equal(150.0, price)
+}
+
+// TestMarketAnalystWithSnapshot 测试分析师基于快照输出市场概况
+func TestMarketAnalystWithSnapshot(t *testing.T) {
+	market := NewStockMarket()
+	analyst := NewMarketAnalyst("anl1", "专家赵", "某证券公司")
+
+	output := captureOutput(func() {
+		market.Register(analyst)
+		market.UpdateStockPrices([]PriceUpdate{
+			{Symbol: "AAPL", Price: 100},
+			{Symbol: "MSFT", Price: 300},
+		}, "开盘", 0)
+	})
+
+	assert.Contains(t, output, "专家赵分析师(某证券公司)")
+	assert.Contains(t, output, "[市场共 2 只股票, 均价 200.00]")
+}
+
+// BenchmarkSnapshot 测试生成快照的开销
+func BenchmarkSnapshot(b *testing.B) {
+	market := NewStockMarket()
+	for i := 0; i < 100; i++ {
+		market.stocks[fmt.Sprintf("SYM%03d", i)] = float64(i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = market.Snapshot()
+	}
+}
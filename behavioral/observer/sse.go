@@ -0,0 +1,266 @@
+package observer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SSEOption SSE 适配器的配置选项
+type SSEOption func(*SSEHandler)
+
+// WithClientBuffer 设置每个客户端的事件缓冲区大小，缓冲区满时丢弃最旧的事件
+func WithClientBuffer(size int) SSEOption {
+	return func(h *SSEHandler) {
+		if size > 0 {
+			h.bufferSize = size
+		}
+	}
+}
+
+// WithHeartbeat 设置心跳间隔，空闲连接会定期收到注释行，防止被代理断开；0 表示不发送心跳
+func WithHeartbeat(interval time.Duration) SSEOption {
+	return func(h *SSEHandler) {
+		h.heartbeat = interval
+	}
+}
+
+// sseEvent 推送给客户端的事件数据
+type sseEvent struct {
+	ID            uint64    `json:"-"`
+	Symbol        string    `json:"symbol"`
+	Price         float64   `json:"price"`
+	PrevPrice     float64   `json:"prevPrice"`
+	ChangePercent float64   `json:"changePercent"`
+	Timestamp     time.Time `json:"timestamp"`
+	Message       string    `json:"message"`
+}
+
+// sseClient 一个已连接的 SSE 客户端
+type sseClient struct {
+	topics  []string      // 订阅的股票代码或通配模式
+	events  chan sseEvent // 待推送的事件
+	dropped atomic.Uint64 // 因缓冲区满而丢弃的事件数
+}
+
+// wants 检查客户端是否订阅了该股票
+func (c *sseClient) wants(symbol string) bool {
+	for _, topic := range c.topics {
+		if matchTopic(topic, symbol) {
+			return true
+		}
+	}
+	return false
+}
+
+// send 非阻塞地放入事件，缓冲区满时丢弃最旧的事件，保证客户端看到最新行情
+func (c *sseClient) send(event sseEvent) {
+	for {
+		select {
+		case c.events <- event:
+			return
+		default:
+		}
+		select {
+		case <-c.events:
+			c.dropped.Add(1)
+		default:
+		}
+	}
+}
+
+// SSEHandler 把进程内的观察者模式桥接到网络 - 它既是观察者，也是 http.Handler
+//
+// 注册到 StockMarket 后，收到的每个 StockEvent 都以 Server-Sent Events 的形式推送给已连接的客户端。
+// 客户端通过查询参数 symbols 订阅股票代码或通配模式（如 "?symbols=AAPL,TECH.*"），不指定时接收所有股票。
+// 每个客户端拥有独立的缓冲区，一个读取缓慢的客户端不会阻塞市场通知或其他客户端
+type SSEHandler struct {
+	id         string
+	bufferSize int
+	heartbeat  time.Duration
+
+	mutex   sync.RWMutex
+	clients map[*sseClient]struct{}
+	closed  bool
+	done    chan struct{}
+
+	nextID  atomic.Uint64
+	dropped atomic.Uint64 // 已断开客户端累计丢弃的事件数
+}
+
+// NewSSEHandler 创建一个 SSE 适配器，id 为其作为观察者的标识
+func NewSSEHandler(id string, opts ...SSEOption) *SSEHandler {
+	h := &SSEHandler{
+		id:         id,
+		bufferSize: 16,
+		heartbeat:  15 * time.Second,
+		clients:    make(map[*sseClient]struct{}),
+		done:       make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// GetID 返回观察者标识
+func (h *SSEHandler) GetID() string {
+	return h.id
+}
+
+// Update 把事件分发给订阅了该股票的客户端，不会等待客户端读取
+func (h *SSEHandler) Update(event StockEvent, message string) {
+	payload := sseEvent{
+		ID:            h.nextID.Add(1),
+		Symbol:        event.Symbol,
+		Price:         event.Price,
+		PrevPrice:     event.PrevPrice,
+		ChangePercent: event.ChangePercent(),
+		Timestamp:     event.Timestamp,
+		Message:       message,
+	}
+
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	for client := range h.clients {
+		if client.wants(event.Symbol) {
+			client.send(payload)
+		}
+	}
+}
+
+// ServeHTTP 建立 SSE 连接并持续推送事件，直到客户端断开或适配器关闭
+func (h *SSEHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "不支持流式响应", http.StatusInternalServerError)
+		return
+	}
+
+	topics, err := parseSymbols(r.URL.Query().Get("symbols"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	client := &sseClient{topics: topics, events: make(chan sseEvent, h.bufferSize)}
+	if !h.addClient(client) {
+		http.Error(w, "行情推送已关闭", http.StatusServiceUnavailable)
+		return
+	}
+	defer h.removeClient(client)
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, ": 已订阅 %s\n\n", strings.Join(topics, ","))
+	flusher.Flush()
+
+	var heartbeat <-chan time.Time
+	if h.heartbeat > 0 {
+		ticker := time.NewTicker(h.heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	for {
+		select {
+		case event := <-client.events:
+			if err := writeSSEEvent(w, event); err != nil {
+				return
+			}
+		case <-heartbeat:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		case <-h.done:
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// writeSSEEvent 按 SSE 格式写出一个事件
+func writeSSEEvent(w http.ResponseWriter, event sseEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: stock\ndata: %s\n\n", event.ID, data)
+	return err
+}
+
+// parseSymbols 解析逗号分隔的订阅主题，为空时订阅所有股票
+func parseSymbols(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return []string{AllSymbols}, nil
+	}
+	var topics []string
+	for _, topic := range strings.Split(raw, ",") {
+		topic = strings.TrimSpace(topic)
+		if err := validateTopic(topic); err != nil {
+			return nil, err
+		}
+		topics = append(topics, topic)
+	}
+	return topics, nil
+}
+
+// addClient 登记客户端，适配器已关闭时返回 false
+func (h *SSEHandler) addClient(client *sseClient) bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.closed {
+		return false
+	}
+	h.clients[client] = struct{}{}
+	return true
+}
+
+// removeClient 在客户端断开时清理其缓冲区
+func (h *SSEHandler) removeClient(client *sseClient) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	delete(h.clients, client)
+	h.dropped.Add(client.dropped.Load())
+}
+
+// Clients 返回当前连接的客户端数量
+func (h *SSEHandler) Clients() int {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return len(h.clients)
+}
+
+// Dropped 返回因客户端缓冲区满而丢弃的事件总数
+func (h *SSEHandler) Dropped() uint64 {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	total := h.dropped.Load()
+	for client := range h.clients {
+		total += client.dropped.Load()
+	}
+	return total
+}
+
+// Close 断开所有客户端并拒绝新的连接，通常在从市场注销后调用
+func (h *SSEHandler) Close() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if !h.closed {
+		h.closed = true
+		close(h.done)
+	}
+}
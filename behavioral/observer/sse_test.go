@@ -0,0 +1,175 @@
+package observer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sseStream 测试用的 SSE 客户端连接
+type sseStream struct {
+	resp   *http.Response
+	reader *bufio.Reader
+	cancel context.CancelFunc
+}
+
+// connectSSE 连接到 SSE 服务并返回流
+func connectSSE(t *testing.T, url string) *sseStream {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		cancel()
+		t.Fatalf("连接 SSE 失败: %v", err)
+	}
+	t.Cleanup(func() {
+		cancel()
+		resp.Body.Close()
+	})
+	return &sseStream{resp: resp, reader: bufio.NewReader(resp.Body), cancel: cancel}
+}
+
+// newSSEServer 启动测试服务，测试结束时先断开客户端再关闭服务
+func newSSEServer(t *testing.T, handler *SSEHandler) *httptest.Server {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	t.Cleanup(handler.Close)
+	return server
+}
+
+// next 读取下一帧（以空行结束的若干行）
+func (s *sseStream) next(t *testing.T) []string {
+	t.Helper()
+	var lines []string
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("读取 SSE 帧失败: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		if line == "" {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+}
+
+// nextEvent 跳过注释帧，读取下一个事件的数据
+func (s *sseStream) nextEvent(t *testing.T) sseEvent {
+	t.Helper()
+	for {
+		frame := s.next(t)
+		for _, line := range frame {
+			if data, ok := strings.CutPrefix(line, "data: "); ok {
+				var event sseEvent
+				if err := json.Unmarshal([]byte(data), &event); err != nil {
+					t.Fatalf("解析事件失败: %v", err)
+				}
+				return event
+			}
+		}
+	}
+}
+
+// TestSSEStreamsMarketEvents 测试注册到市场的 SSE 适配器把事件推送给客户端
+func TestSSEStreamsMarketEvents(t *testing.T) {
+	assert := assert.New(t)
+	market := NewStockMarket()
+	handler := NewSSEHandler("sse", WithHeartbeat(0))
+	captureOutput(func() { market.Register(handler) })
+
+	server := newSSEServer(t, handler)
+
+	all := connectSSE(t, server.URL)
+	tech := connectSSE(t, server.URL+"?symbols=TECH.*")
+	assert.Equal("text/event-stream", all.resp.Header.Get("Content-Type"))
+	assert.Equal([]string{": 已订阅 *"}, all.next(t))
+	assert.Equal([]string{": 已订阅 TECH.*"}, tech.next(t))
+	assert.Equal(2, handler.Clients())
+
+	captureOutput(func() {
+		market.UpdateStockPrice("BANK.ICBC", 5.2, "工行开盘", 0)
+		market.UpdateStockPrice("TECH.AAPL", 150, "苹果开盘", 0)
+		market.UpdateStockPrice("TECH.AAPL", 165, "苹果大涨", 1)
+	})
+
+	first := all.nextEvent(t)
+	assert.Equal("BANK.ICBC", first.Symbol)
+	assert.Equal("工行开盘", first.Message)
+
+	frame := tech.next(t)
+	assert.Equal([]string{"id: 2", "event: stock"}, frame[:2], "事件编号在所有客户端间递增")
+	surge := tech.nextEvent(t)
+	assert.Equal("TECH.AAPL", surge.Symbol)
+	assert.Equal(150.0, surge.PrevPrice)
+	assert.InDelta(10.0, surge.ChangePercent, 1e-9)
+	assert.Equal("苹果大涨", surge.Message)
+}
+
+// TestSSEHeartbeatAndDisconnect 测试空闲连接收到心跳，客户端断开后被清理
+func TestSSEHeartbeatAndDisconnect(t *testing.T) {
+	assert := assert.New(t)
+	handler := NewSSEHandler("sse", WithHeartbeat(10*time.Millisecond))
+	server := newSSEServer(t, handler)
+
+	stream := connectSSE(t, server.URL)
+	stream.next(t)
+	assert.Equal([]string{": heartbeat"}, stream.next(t))
+
+	stream.cancel()
+	assert.Eventually(func() bool { return handler.Clients() == 0 }, time.Second, 5*time.Millisecond)
+
+	// 没有客户端时通知不会阻塞
+	handler.Update(newTestEvent("AAPL", 10), "无人收听")
+}
+
+// TestSSESlowClientBuffer 测试读取缓慢的客户端只丢弃最旧的事件，不阻塞通知
+func TestSSESlowClientBuffer(t *testing.T) {
+	assert := assert.New(t)
+	handler := NewSSEHandler("sse", WithClientBuffer(2))
+
+	slow := &sseClient{topics: []string{AllSymbols}, events: make(chan sseEvent, 2)}
+	assert.True(handler.addClient(slow))
+	for i := 1; i <= 5; i++ {
+		handler.Update(newTestEvent("AAPL", float64(i)), "")
+	}
+
+	assert.Equal(uint64(3), handler.Dropped())
+	assert.Equal(4.0, (<-slow.events).Price, "缓冲区保留最新的事件")
+	assert.Equal(5.0, (<-slow.events).Price)
+
+	handler.removeClient(slow)
+	assert.Equal(uint64(3), handler.Dropped(), "断开的客户端丢弃数仍计入总数")
+}
+
+// TestSSEClose 测试关闭适配器后断开所有客户端并拒绝新的连接
+func TestSSEClose(t *testing.T) {
+	assert := assert.New(t)
+	handler := NewSSEHandler("sse")
+	server := newSSEServer(t, handler)
+
+	resp, err := http.Get(server.URL + "?symbols=TECH.[A")
+	assert.NoError(err)
+	assert.Equal(http.StatusBadRequest, resp.StatusCode)
+	resp.Body.Close()
+
+	stream := connectSSE(t, server.URL)
+	stream.next(t)
+	handler.Close()
+	_, err = stream.reader.ReadString('\n')
+	assert.Error(err, "关闭后服务端应结束响应")
+	assert.Eventually(func() bool { return handler.Clients() == 0 }, time.Second, 5*time.Millisecond)
+
+	resp, err = http.Get(server.URL)
+	assert.NoError(err)
+	assert.Equal(http.StatusServiceUnavailable, resp.StatusCode)
+	resp.Body.Close()
+}
@@ -0,0 +1,173 @@
+package observer
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// AllSymbols 是订阅所有股票的通配模式，Register 注册的观察者默认订阅它
+const AllSymbols = "*"
+
+// ErrInvalidTopic 表示订阅的股票代码或通配模式无效
+var ErrInvalidTopic = errors.New("无效的订阅主题")
+
+// isPattern 检查订阅主题是否为通配模式
+func isPattern(topic string) bool {
+	return strings.ContainsAny(topic, "*?[")
+}
+
+// validateTopic 检查订阅主题是否有效
+func validateTopic(topic string) error {
+	if topic == "" {
+		return fmt.Errorf("%w: 主题不能为空", ErrInvalidTopic)
+	}
+	if _, err := path.Match(topic, ""); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidTopic, topic)
+	}
+	return nil
+}
+
+// matchTopic 检查股票代码是否匹配订阅主题
+// 通配模式使用 path.Match 语法，例如 "TECH.*" 匹配 "TECH.AAPL"
+func matchTopic(topic, symbol string) bool {
+	if !isPattern(topic) {
+		return topic == symbol
+	}
+	matched, _ := path.Match(topic, symbol)
+	return matched
+}
+
+// Subscribe 为观察者订阅指定的股票代码或通配模式，如 "AAPL"、"TECH.*"
+// 未注册的观察者会被注册，但只会收到所订阅股票的通知
+func (s *StockMarket) Subscribe(observer Observer, topics ...string) error {
+	for _, topic := range topics {
+		if err := validateTopic(topic); err != nil {
+			return err
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	id := observer.GetID()
+	if !s.HasObserverUnsafe(observer) {
+		s.addObserverUnsafe(observer, topics...)
+	} else {
+		for _, topic := range topics {
+			s.topics[id][topic] = true
+		}
+		s.invalidateUnsafe()
+	}
+	fmt.Printf("观察者 %s 订阅了 %s\n", id, strings.Join(topics, ", "))
+	return nil
+}
+
+// Unsubscribe 取消观察者对指定主题的订阅
+// 取消后不再订阅任何主题的观察者会被注销
+func (s *StockMarket) Unsubscribe(observer Observer, topics ...string) {
+	s.mutex.Lock()
+	subscribed, exists := s.topics[observer.GetID()]
+	if !exists {
+		s.mutex.Unlock()
+		return
+	}
+	for _, topic := range topics {
+		delete(subscribed, topic)
+	}
+	s.invalidateUnsafe()
+	empty := len(subscribed) == 0
+	s.mutex.Unlock()
+
+	if empty {
+		s.Deregister(observer)
+	}
+}
+
+// Subscriptions 返回观察者订阅的所有主题
+func (s *StockMarket) Subscriptions(observerID string) []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	topics := make([]string, 0, len(s.topics[observerID]))
+	for topic := range s.topics[observerID] {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// SubscriberIDs 返回订阅了指定股票的观察者ID，按注册顺序排列
+func (s *StockMarket) SubscriberIDs(symbol string) []string {
+	observers := s.subscribersFor(symbol)
+	ids := make([]string, len(observers))
+	for i, observer := range observers {
+		ids[i] = observer.GetID()
+	}
+	return ids
+}
+
+// subscribersFor 返回订阅了指定股票的观察者
+// 结果按股票代码缓存，通知时只需遍历真正的订阅者；订阅变化时缓存失效
+// 返回的切片是共享的，调用者不能修改
+func (s *StockMarket) subscribersFor(symbol string) []Observer {
+	s.mutex.RLock()
+	observers, cached := s.resolved[symbol]
+	s.mutex.RUnlock()
+	if cached {
+		return observers
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if observers, cached := s.resolved[symbol]; cached {
+		return observers
+	}
+
+	observers = make([]Observer, 0)
+	for _, observer := range s.observers {
+		for topic := range s.topics[observer.GetID()] {
+			if matchTopic(topic, symbol) {
+				observers = append(observers, observer)
+				break
+			}
+		}
+	}
+	s.resolved[symbol] = observers
+	return observers
+}
+
+// addObserverUnsafe 注册观察者并记录其订阅主题（需持有写锁）
+func (s *StockMarket) addObserverUnsafe(observer Observer, topics ...string) {
+	s.observers = append(s.observers, observer)
+
+	subscribed := make(map[string]bool, len(topics))
+	for _, topic := range topics {
+		subscribed[topic] = true
+	}
+	s.topics[observer.GetID()] = subscribed
+	s.invalidateUnsafe()
+}
+
+// removeObserverUnsafe 移除观察者及其订阅，返回被移除的观察者（需持有写锁）
+func (s *StockMarket) removeObserverUnsafe(id string) Observer {
+	for i, obs := range s.observers {
+		if obs.GetID() == id {
+			s.observers = append(s.observers[:i], s.observers[i+1:]...)
+			delete(s.topics, id)
+			s.invalidateUnsafe()
+			return obs
+		}
+	}
+	return nil
+}
+
+// invalidateUnsafe 清空订阅者索引（需持有写锁）
+func (s *StockMarket) invalidateUnsafe() {
+	if len(s.resolved) > 0 {
+		s.resolved = make(map[string][]Observer)
+	}
+}
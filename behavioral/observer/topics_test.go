@@ -0,0 +1,135 @@
+package observer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newRecordingObserver 创建记录收到的股票代码的观察者
+func newRecordingObserver(id string, received *[]string) *testObserver {
+	return &testObserver{
+		id: id,
+		updateFn: func(event StockEvent, message string) {
+			*received = append(*received, event.Symbol)
+		},
+	}
+}
+
+// TestMatchTopic 测试主题匹配规则
+func TestMatchTopic(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(matchTopic("AAPL", "AAPL"))
+	assert.False(matchTopic("AAPL", "AAPL2"))
+	assert.True(matchTopic("TECH.*", "TECH.AAPL"))
+	assert.False(matchTopic("TECH.*", "BANK.ICBC"))
+	assert.True(matchTopic(AllSymbols, "ANY"))
+	assert.True(matchTopic("600???", "600519"))
+
+	assert.ErrorIs(validateTopic(""), ErrInvalidTopic)
+	assert.ErrorIs(validateTopic("TECH.[A"), ErrInvalidTopic)
+}
+
+// TestSymbolSubscriptions 测试按股票代码和通配模式订阅
+func TestSymbolSubscriptions(t *testing.T) {
+	assert := assert.New(t)
+	market := NewStockMarket()
+
+	var all, tech, apple []string
+	captureOutput(func() {
+		market.Register(newRecordingObserver("all", &all))
+		assert.NoError(market.Subscribe(newRecordingObserver("tech", &tech), "TECH.*"))
+		assert.NoError(market.Subscribe(newRecordingObserver("apple", &apple), "TECH.AAPL", "BANK.ICBC"))
+
+		market.Notify(newTestEvent("TECH.AAPL", 10), "苹果")
+		market.Notify(newTestEvent("TECH.MSFT", 10), "微软")
+		market.Notify(newTestEvent("BANK.ICBC", 10), "工行")
+		market.Notify(newTestEvent("ENERGY.XOM", 10), "埃克森")
+	})
+
+	assert.Equal([]string{"TECH.AAPL", "TECH.MSFT", "BANK.ICBC", "ENERGY.XOM"}, all, "Register 的观察者应收到所有股票")
+	assert.Equal([]string{"TECH.AAPL", "TECH.MSFT"}, tech, "通配订阅只收到匹配的股票")
+	assert.Equal([]string{"TECH.AAPL", "BANK.ICBC"}, apple, "精确订阅只收到指定的股票")
+
+	assert.Equal([]string{"all", "tech", "apple"}, market.SubscriberIDs("TECH.AAPL"), "订阅者应按注册顺序排列")
+	assert.Equal([]string{"all"}, market.SubscriberIDs("ENERGY.XOM"))
+	assert.Equal([]string{"BANK.ICBC", "TECH.AAPL"}, market.Subscriptions("apple"))
+	assert.Equal([]string{AllSymbols}, market.Subscriptions("all"))
+	assert.Equal(3, market.CountObservers())
+}
+
+// TestSubscriptionManagement 测试订阅的增加、取消和索引失效
+func TestSubscriptionManagement(t *testing.T) {
+	assert := assert.New(t)
+	market := NewStockMarket()
+
+	var received []string
+	observer := newRecordingObserver("trader", &received)
+
+	captureOutput(func() {
+		assert.NoError(market.Subscribe(observer, "TECH.AAPL"))
+		market.Notify(newTestEvent("TECH.MSFT", 10), "")
+
+		// 增加订阅后索引应立即更新
+		assert.NoError(market.Subscribe(observer, "TECH.*"))
+		market.Notify(newTestEvent("TECH.MSFT", 11), "")
+
+		// 取消通配订阅后只保留精确订阅
+		market.Unsubscribe(observer, "TECH.*")
+		market.Notify(newTestEvent("TECH.MSFT", 12), "")
+		market.Notify(newTestEvent("TECH.AAPL", 12), "")
+	})
+
+	assert.Equal([]string{"TECH.MSFT", "TECH.AAPL"}, received)
+	assert.True(market.HasObserver(observer))
+
+	// 取消全部订阅后观察者被注销
+	captureOutput(func() {
+		market.Unsubscribe(observer, "TECH.AAPL")
+	})
+	assert.False(market.HasObserver(observer))
+	assert.Empty(market.SubscriberIDs("TECH.AAPL"))
+	assert.Empty(market.Subscriptions("trader"))
+
+	// 无效主题不会产生任何订阅
+	assert.ErrorIs(market.Subscribe(observer, "TECH.AAPL", ""), ErrInvalidTopic)
+	assert.False(market.HasObserver(observer))
+}
+
+// TestDeregisterClearsIndex 测试注销后订阅索引被清理
+func TestDeregisterClearsIndex(t *testing.T) {
+	assert := assert.New(t)
+	market := NewStockMarket()
+
+	var received []string
+	observer := newRecordingObserver("a", &received)
+	captureOutput(func() {
+		market.Subscribe(observer, "AAPL")
+		market.Notify(newTestEvent("AAPL", 10), "")
+		market.Deregister(observer)
+		market.Notify(newTestEvent("AAPL", 11), "")
+	})
+
+	assert.Equal([]string{"AAPL"}, received)
+	assert.Empty(market.SubscriberIDs("AAPL"))
+}
+
+// BenchmarkNotifySubscribed 大量观察者只订阅各自股票时的通知开销
+func BenchmarkNotifySubscribed(b *testing.B) {
+	market := NewStockMarket()
+	captureOutput(func() {
+		for i := 0; i < 1000; i++ {
+			market.Subscribe(&testObserver{id: fmt.Sprintf("obs-%d", i)}, fmt.Sprintf("SYM%d", i%100))
+		}
+	})
+	event := newTestEvent("SYM7", 10)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, observer := range market.subscribersFor(event.Symbol) {
+			observer.Update(event, "")
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrInitFailed 表示异步服务初始化失败
+var ErrInitFailed = errors.New("服务初始化失败")
+
+// AsyncServiceCreator 定义了异步创建服务实例的函数类型，返回错误表示初始化失败
+type AsyncServiceCreator func() (interface{}, error)
+
+// ReadyState 表示服务的就绪状态
+type ReadyState int
+
+const (
+	// StateInitializing 服务正在后台初始化
+	StateInitializing ReadyState = iota
+	// StateReady 服务已就绪
+	StateReady
+	// StateFailed 服务初始化失败
+	StateFailed
+)
+
+// String 返回就绪状态的名称
+func (s ReadyState) String() string {
+	switch s {
+	case StateInitializing:
+		return "initializing"
+	case StateReady:
+		return "ready"
+	case StateFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// AsyncService 是异步服务的句柄，Get 异步服务时立即返回它，初始化在后台进行
+type AsyncService struct {
+	key   string
+	done  chan struct{} // 初始化结束时关闭，关闭后 value 和 err 不再改变
+	value interface{}
+	err   error
+}
+
+// startAsync 在后台执行 creator 并返回句柄
+func startAsync(key string, creator AsyncServiceCreator) *AsyncService {
+	s := &AsyncService{key: key, done: make(chan struct{})}
+	go func() {
+		defer close(s.done)
+		defer func() {
+			if r := recover(); r != nil {
+				s.value, s.err = nil, fmt.Errorf("panic: %v", r)
+			}
+		}()
+
+		value, err := creator()
+		if err == nil && value == nil {
+			err = fmt.Errorf("工厂方法返回nil对象")
+		}
+		s.value, s.err = value, err
+	}()
+	return s
+}
+
+// Key 返回服务注册时使用的键
+func (s *AsyncService) Key() string {
+	return s.key
+}
+
+// Done 返回初始化结束(成功或失败)时关闭的通道
+func (s *AsyncService) Done() <-chan struct{} {
+	return s.done
+}
+
+// State 返回服务当前的就绪状态
+func (s *AsyncService) State() ReadyState {
+	select {
+	case <-s.done:
+		if s.err != nil {
+			return StateFailed
+		}
+		return StateReady
+	default:
+		return StateInitializing
+	}
+}
+
+// Err 返回初始化错误，仍在初始化或初始化成功时返回 nil
+func (s *AsyncService) Err() error {
+	if s.State() != StateFailed {
+		return nil
+	}
+	return fmt.Errorf("%w: '%s': %w", ErrInitFailed, s.key, s.err)
+}
+
+// Wait 等待初始化结束并返回服务实例，ctx 结束时返回 ctx 的错误
+func (s *AsyncService) Wait(ctx context.Context) (interface{}, error) {
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return s.value, nil
+}
+
+// RegisterAsyncFactory 注册一个异步初始化的服务，creator 立即在后台开始执行
+// 初始化期间 Get 返回 *AsyncService 句柄而不阻塞，可以通过 WaitReady 或句柄的 Wait 等待就绪
+func (r *Registry) RegisterAsyncFactory(key string, creator AsyncServiceCreator) error {
+	if creator == nil {
+		return fmt.Errorf("不能注册nil创建函数")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.hasKeyUnsafe(key) {
+		return fmt.Errorf("服务 '%s' 已经注册", key)
+	}
+	if _, exists := r.aliases[key]; exists {
+		return fmt.Errorf("'%s' 已经是别名", key)
+	}
+
+	r.mutateUnsafe()
+	r.services[key] = startAsync(key, creator)
+	return nil
+}
+
+// Readiness 返回服务的就绪状态，初始化失败时同时返回失败原因
+// 同步注册的服务和懒加载服务总是就绪的；key 也可以是别名
+func (r *Registry) Readiness(key string) (ReadyState, error) {
+	r.mutex.RLock()
+	key = r.targetUnsafe(key)
+	service := r.services[key]
+	registered := r.hasKeyUnsafe(key)
+	r.mutex.RUnlock()
+
+	if !registered {
+		return StateFailed, fmt.Errorf("服务 '%s' 未注册", key)
+	}
+	if async, ok := service.(*AsyncService); ok {
+		return async.State(), async.Err()
+	}
+	return StateReady, nil
+}
+
+// WaitReady 等待指定的异步服务全部初始化结束，不指定 key 时等待所有异步服务
+// 所有服务就绪时返回 nil；有服务初始化失败时返回包含所有失败原因的错误(可用 ErrInitFailed 判断)；
+// ctx 结束时立即返回 ctx 的错误
+func (r *Registry) WaitReady(ctx context.Context, keys ...string) error {
+	pending, err := r.asyncServices(keys)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, async := range pending {
+		select {
+		case <-async.Done():
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if err := async.Err(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// asyncServices 返回指定键对应的异步服务句柄，keys 为空时返回所有异步服务(按键排序)
+func (r *Registry) asyncServices(keys []string) ([]*AsyncService, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var result []*AsyncService
+	if len(keys) == 0 {
+		for _, service := range r.services {
+			if async, ok := service.(*AsyncService); ok {
+				result = append(result, async)
+			}
+		}
+		sort.Slice(result, func(i, j int) bool { return result[i].key < result[j].key })
+		return result, nil
+	}
+
+	for _, key := range keys {
+		target := r.targetUnsafe(key)
+		if !r.hasKeyUnsafe(target) {
+			return nil, fmt.Errorf("服务 '%s' 未注册", key)
+		}
+		// 同步注册的服务和懒加载服务不需要等待
+		if async, ok := r.services[target].(*AsyncService); ok {
+			result = append(result, async)
+		}
+	}
+	return result, nil
+}
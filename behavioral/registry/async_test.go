@@ -0,0 +1,112 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingCreator 返回在 release 关闭前阻塞的异步工厂
+func blockingCreator(release <-chan struct{}, service interface{}, err error) AsyncServiceCreator {
+	return func() (interface{}, error) {
+		<-release
+		return service, err
+	}
+}
+
+func TestAsyncFactory_GetDoesNotBlock(t *testing.T) {
+	registry := NewRegistry()
+	release := make(chan struct{})
+	assert.NoError(t, registry.RegisterAsyncFactory("search", blockingCreator(release, &TestService{Name: "Search"}, nil)))
+
+	// 初始化期间 Get 立即返回句柄
+	service, err := registry.Get("search")
+	assert.NoError(t, err)
+	handle, ok := service.(*AsyncService)
+	assert.True(t, ok, "异步服务应返回句柄")
+	assert.Equal(t, "search", handle.Key())
+	assert.Equal(t, StateInitializing, handle.State())
+
+	state, err := registry.Readiness("search")
+	assert.Equal(t, StateInitializing, state)
+	assert.NoError(t, err)
+
+	close(release)
+	value, err := handle.Wait(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "Search", value.(*TestService).Name)
+
+	state, err = registry.Readiness("search")
+	assert.Equal(t, StateReady, state)
+	assert.NoError(t, err)
+}
+
+func TestAsyncFactory_Failure(t *testing.T) {
+	registry := NewRegistry()
+	errConnect := errors.New("connection refused")
+	assert.NoError(t, registry.RegisterAsyncFactory("cache", func() (interface{}, error) {
+		return nil, errConnect
+	}))
+	assert.NoError(t, registry.RegisterAsyncFactory("broken", func() (interface{}, error) {
+		panic("boom")
+	}))
+	assert.NoError(t, registry.RegisterAsyncFactory("empty", func() (interface{}, error) {
+		return nil, nil
+	}))
+
+	err := registry.WaitReady(context.Background())
+	assert.ErrorIs(t, err, ErrInitFailed)
+	assert.ErrorIs(t, err, errConnect)
+	assert.Contains(t, err.Error(), "boom", "panic 应被记录为初始化失败")
+	assert.Contains(t, err.Error(), "empty", "返回nil对象应视为初始化失败")
+
+	state, err := registry.Readiness("cache")
+	assert.Equal(t, StateFailed, state)
+	assert.ErrorIs(t, err, errConnect)
+	assert.Equal(t, "failed", state.String())
+
+	handle := registry.MustGet("cache").(*AsyncService)
+	_, err = handle.Wait(context.Background())
+	assert.ErrorIs(t, err, ErrInitFailed)
+}
+
+func TestWaitReady(t *testing.T) {
+	registry := NewRegistry()
+	fast := make(chan struct{})
+	slow := make(chan struct{})
+	assert.NoError(t, registry.RegisterAsyncFactory("db", blockingCreator(fast, &TestService{Name: "DB"}, nil)))
+	assert.NoError(t, registry.RegisterAsyncFactory("index", blockingCreator(slow, &TestService{Name: "Index"}, nil)))
+	assert.NoError(t, registry.Register("config", &TestService{Name: "Config"}))
+	assert.NoError(t, registry.Alias("database", "db"))
+	close(fast)
+
+	// 只等待指定的服务，同步服务总是就绪的，别名同样可用
+	assert.NoError(t, registry.WaitReady(context.Background(), "database", "config"))
+
+	// 慢服务未就绪时等待超时
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, registry.WaitReady(ctx), context.DeadlineExceeded)
+
+	close(slow)
+	assert.NoError(t, registry.WaitReady(context.Background()))
+
+	err := registry.WaitReady(context.Background(), "missing")
+	assert.Error(t, err)
+	_, err = registry.Readiness("missing")
+	assert.Error(t, err)
+
+	state, err := registry.Readiness("config")
+	assert.Equal(t, StateReady, state)
+	assert.NoError(t, err)
+}
+
+func TestAsyncFactory_Duplicate(t *testing.T) {
+	registry := NewRegistry()
+	assert.NoError(t, registry.Register("db", &TestService{Name: "DB"}))
+	assert.Error(t, registry.RegisterAsyncFactory("db", func() (interface{}, error) { return 1, nil }))
+	assert.Error(t, registry.RegisterAsyncFactory("nil", nil))
+}
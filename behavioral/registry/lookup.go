@@ -0,0 +1,144 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// 按类型查找相关错误
+var (
+	// ErrNoImplementation 表示没有找到指定类型的实现
+	ErrNoImplementation = errors.New("没有找到实现")
+
+	// ErrAmbiguousService 表示指定类型存在多个实现，无法确定使用哪一个
+	ErrAmbiguousService = errors.New("存在多个实现")
+)
+
+// Alias 为已注册的服务添加别名，通过别名获取到的是同一个服务
+// 别名指向另一个别名时会解析到最终的服务；newKey 不能与已有的服务或别名重名
+func (r *Registry) Alias(newKey, existingKey string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.hasKeyUnsafe(newKey) {
+		return fmt.Errorf("服务 '%s' 已经注册", newKey)
+	}
+	if _, exists := r.aliases[newKey]; exists {
+		return fmt.Errorf("别名 '%s' 已经存在", newKey)
+	}
+
+	target := r.targetUnsafe(existingKey)
+	if !r.hasKeyUnsafe(target) {
+		return fmt.Errorf("服务 '%s' 未注册", existingKey)
+	}
+
+	r.mutateUnsafe()
+	r.aliases[newKey] = target
+	return nil
+}
+
+// Aliases 返回指向指定服务的所有别名，按字典序排列
+func (r *Registry) Aliases(key string) []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	key = r.targetUnsafe(key)
+	var aliases []string
+	for alias, target := range r.aliases {
+		if target == key {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// targetUnsafe 把别名解析为服务键，不是别名时原样返回，调用方需持有锁
+// Alias 总是保存最终的服务键，因此只需解析一次
+func (r *Registry) targetUnsafe(key string) string {
+	if target, ok := r.aliases[key]; ok {
+		return target
+	}
+	return key
+}
+
+// hasKeyUnsafe 检查服务键是否已注册（不解析别名），调用方需持有锁
+func (r *Registry) hasKeyUnsafe(key string) bool {
+	_, existsService := r.services[key]
+	_, existsFactory := r.factories[key]
+	return existsService || existsFactory
+}
+
+// typeOf 返回类型参数对应的反射类型，T 为接口时同样适用
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// RegisterInterface 在全局注册表中把 impl 注册为类型 T 的实现
+func RegisterInterface[T any](impl T) error {
+	return RegisterInterfaceTo(GetRegistry(), impl)
+}
+
+// Resolve 从全局注册表中获取类型 T 的唯一实现
+func Resolve[T any]() (T, error) {
+	return ResolveFrom[T](GetRegistry())
+}
+
+// RegisterInterfaceTo 把 impl 注册为类型 T 的实现，T 通常是接口类型
+// 同一个类型可以注册多个实现，此时 ResolveFrom 会返回 ErrAmbiguousService
+func RegisterInterfaceTo[T any](r *Registry, impl T) error {
+	value := any(impl)
+	if value == nil {
+		return fmt.Errorf("不能注册nil服务")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.mutateUnsafe()
+	t := typeOf[T]()
+	// 总是创建新的切片，避免修改快照仍在引用的底层数组
+	r.typed[t] = slices.Concat(r.typed[t], []any{value})
+	return nil
+}
+
+// ResolveFrom 获取类型 T 的唯一实现
+//
+// 优先使用通过 RegisterInterfaceTo 注册的实现；没有时在按键注册且已实例化的服务中
+// 查找实现了 T 的服务（尚未实例化的懒加载服务无法得知类型，不参与查找）。
+// 找不到时返回 ErrNoImplementation，找到多个时返回 ErrAmbiguousService。
+func ResolveFrom[T any](r *Registry) (T, error) {
+	var zero T
+	t := typeOf[T]()
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	switch impls := r.typed[t]; len(impls) {
+	case 0:
+	case 1:
+		return impls[0].(T), nil
+	default:
+		return zero, fmt.Errorf("%w: 类型 %v 注册了 %d 个实现", ErrAmbiguousService, t, len(impls))
+	}
+
+	var keys []string
+	for key, service := range r.services {
+		if _, ok := service.(T); ok {
+			keys = append(keys, key)
+		}
+	}
+	switch len(keys) {
+	case 0:
+		return zero, fmt.Errorf("%w: %v", ErrNoImplementation, t)
+	case 1:
+		return r.services[keys[0]].(T), nil
+	default:
+		sort.Strings(keys)
+		return zero, fmt.Errorf("%w: 类型 %v 匹配服务 %s", ErrAmbiguousService, t, strings.Join(keys, ", "))
+	}
+}
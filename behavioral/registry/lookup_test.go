@@ -0,0 +1,158 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Logger 用于按接口查找的测试接口
+type Logger interface {
+	Log(msg string) string
+}
+
+type consoleLogger struct{ prefix string }
+
+func (l *consoleLogger) Log(msg string) string { return l.prefix + msg }
+
+// Namer 用于测试按键注册的服务也能按接口查找
+type Namer interface {
+	GetName() string
+}
+
+func TestAlias(t *testing.T) {
+	registry := NewRegistry()
+	created := 0
+	assert.NoError(t, registry.RegisterFactory("db.primary", func() interface{} {
+		created++
+		return &TestService{Name: "Primary"}
+	}))
+
+	assert.NoError(t, registry.Alias("db", "db.primary"))
+	assert.NoError(t, registry.Alias("database", "db")) // 指向别名的别名解析到最终服务
+	assert.True(t, registry.Has("database"))
+
+	viaAlias, err := registry.Get("database")
+	assert.NoError(t, err)
+	direct, err := registry.Get("db.primary")
+	assert.NoError(t, err)
+	assert.Same(t, direct, viaAlias, "别名应返回同一个服务")
+	assert.Equal(t, 1, created, "懒加载服务只应创建一次")
+
+	assert.Equal(t, []string{"database", "db"}, registry.Aliases("db"))
+	assert.Equal(t, []string{"db.primary"}, registry.Keys(), "Keys 不包含别名")
+
+	// 重名与不存在的目标
+	assert.Error(t, registry.Alias("db", "db.primary"))
+	assert.Error(t, registry.Alias("db.primary", "db"))
+	assert.Error(t, registry.Alias("cache", "missing"))
+	assert.Error(t, registry.Register("db", &TestService{}))
+	assert.Error(t, registry.RegisterFactory("db", func() interface{} { return &TestService{} }))
+
+	// 删除别名只影响别名本身
+	registry.Unregister("db")
+	assert.False(t, registry.Has("db"))
+	assert.True(t, registry.Has("database"))
+
+	// 删除服务时别名一并删除
+	registry.Unregister("db.primary")
+	assert.False(t, registry.Has("database"))
+	_, err = registry.Get("database")
+	assert.Error(t, err)
+}
+
+func TestAliasSnapshot(t *testing.T) {
+	registry := NewRegistry()
+	assert.NoError(t, registry.Register("mailer.smtp", &TestService{Name: "SMTP"}))
+	snap := registry.Snapshot()
+
+	assert.NoError(t, registry.Alias("mailer", "mailer.smtp"))
+	assert.False(t, snap.Has("mailer"), "快照之后添加的别名不影响快照")
+
+	assert.NoError(t, registry.Restore(snap))
+	assert.False(t, registry.Has("mailer"))
+
+	assert.NoError(t, registry.Alias("mailer", "mailer.smtp"))
+	snap = registry.Snapshot()
+	assert.True(t, snap.Has("mailer"))
+	registry.Clear()
+	assert.NoError(t, registry.Restore(snap))
+	assert.True(t, registry.Has("mailer"))
+}
+
+func TestResolveByInterface(t *testing.T) {
+	registry := NewRegistry()
+
+	_, err := ResolveFrom[Logger](registry)
+	assert.ErrorIs(t, err, ErrNoImplementation)
+
+	logger := &consoleLogger{prefix: "[app] "}
+	assert.NoError(t, RegisterInterfaceTo[Logger](registry, logger))
+
+	resolved, err := ResolveFrom[Logger](registry)
+	assert.NoError(t, err)
+	assert.Same(t, logger, resolved)
+	assert.Equal(t, "[app] 启动", resolved.Log("启动"))
+
+	// 注册第二个实现后无法确定使用哪一个
+	snap := registry.Snapshot()
+	assert.NoError(t, RegisterInterfaceTo[Logger](registry, &consoleLogger{prefix: "[audit] "}))
+	_, err = ResolveFrom[Logger](registry)
+	assert.ErrorIs(t, err, ErrAmbiguousService)
+
+	// 快照不受之后注册的实现影响
+	assert.NoError(t, registry.Restore(snap))
+	resolved, err = ResolveFrom[Logger](registry)
+	assert.NoError(t, err)
+	assert.Same(t, logger, resolved)
+
+	var nilLogger Logger
+	assert.Error(t, RegisterInterfaceTo(registry, nilLogger))
+}
+
+func TestResolveFromKeyedServices(t *testing.T) {
+	registry := NewRegistry()
+	service := &TestService{Name: "User"}
+	assert.NoError(t, registry.Register("userService", service))
+	assert.NoError(t, registry.RegisterFactory("lazy", func() interface{} {
+		return &TestService{Name: "Lazy"}
+	}))
+
+	// 只有已实例化的服务参与查找
+	namer, err := ResolveFrom[Namer](registry)
+	assert.NoError(t, err)
+	assert.Same(t, service, namer)
+
+	// 具体类型同样可以查找
+	concrete, err := ResolveFrom[*TestService](registry)
+	assert.NoError(t, err)
+	assert.Same(t, service, concrete)
+
+	_, err = registry.Get("lazy")
+	assert.NoError(t, err)
+	_, err = ResolveFrom[Namer](registry)
+	assert.ErrorIs(t, err, ErrAmbiguousService)
+	assert.Contains(t, err.Error(), "lazy, userService")
+
+	// 显式注册的实现优先
+	preferred := &TestService{Name: "Preferred"}
+	assert.NoError(t, RegisterInterfaceTo[Namer](registry, preferred))
+	namer, err = ResolveFrom[Namer](registry)
+	assert.NoError(t, err)
+	assert.Same(t, preferred, namer)
+}
+
+func TestResolveGlobal(t *testing.T) {
+	type clock interface{ Now() string }
+	type fixedClock struct{ clock }
+
+	impl := fixedClock{}
+	assert.NoError(t, RegisterInterface[clock](impl))
+	t.Cleanup(func() {
+		GetRegistry().Clear()
+	})
+
+	resolved, err := Resolve[clock]()
+	assert.NoError(t, err)
+	assert.Equal(t, impl, resolved)
+}
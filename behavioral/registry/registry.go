@@ -2,7 +2,10 @@ package registry
 
 import (
 	"fmt"
+	"reflect"
 	"sync"
+
+	"github.com/XiaoluCoding626/go-design-pattern/concurrency/singleflight"
 )
 
 // ServiceCreator 定义了创建服务实例的函数类型
@@ -13,6 +16,13 @@ type Registry struct {
 	mutex     sync.RWMutex              // 用于并发安全
 	services  map[string]interface{}    // 存储已实例化的服务
 	factories map[string]ServiceCreator // 存储服务工厂函数
+	aliases   map[string]string         // 别名到目标键的映射
+	typed     map[reflect.Type][]any    // 按接口类型注册的实现
+
+	generation uint64 // 每次修改递增的代数
+	shared     bool   // 当前映射是否被快照共享，为 true 时修改前需要先复制
+
+	flight *singleflight.Group[interface{}] // 非 nil 时工厂在锁外执行，并发的首次获取合并为一次调用
 }
 
 // NewRegistry 创建一个新的注册表实例
@@ -20,6 +30,8 @@ func NewRegistry() *Registry {
 	return &Registry{
 		services:  make(map[string]interface{}),
 		factories: make(map[string]ServiceCreator),
+		aliases:   make(map[string]string),
+		typed:     make(map[reflect.Type][]any),
 	}
 }
 
@@ -50,6 +62,11 @@ func (r *Registry) Register(key string, service interface{}) error {
 		return fmt.Errorf("服务 '%s' 已经注册", key)
 	}
 
+	if _, exists := r.aliases[key]; exists {
+		return fmt.Errorf("'%s' 已经是别名", key)
+	}
+
+	r.mutateUnsafe()
 	r.services[key] = service
 	return nil
 }
@@ -71,24 +88,37 @@ func (r *Registry) RegisterFactory(key string, creator ServiceCreator) error {
 		return fmt.Errorf("服务工厂 '%s' 已经注册", key)
 	}
 
+	if _, exists := r.aliases[key]; exists {
+		return fmt.Errorf("'%s' 已经是别名", key)
+	}
+
+	r.mutateUnsafe()
 	r.factories[key] = creator
 	return nil
 }
 
-// Get 方法用于从注册表中检索对象
+// Get 方法用于从注册表中检索对象，key 也可以是别名
 func (r *Registry) Get(key string) (interface{}, error) {
 	r.mutex.RLock()
-	service, exists := r.services[key]
+	target := r.targetUnsafe(key)
+	service, exists := r.services[target]
+	flight := r.flight
 	r.mutex.RUnlock()
 
 	if exists {
 		return service, nil
 	}
+	if flight != nil {
+		return r.getSingleFlight(flight, target)
+	}
 
 	// 检查是否有工厂可以创建此服务
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
+	// 别名可能在获取锁期间被修改，重新解析
+	key = r.targetUnsafe(key)
+
 	// 二次检查，确保没有在获取锁期间被其他goroutine创建
 	if service, exists := r.services[key]; exists {
 		return service, nil
@@ -100,6 +130,7 @@ func (r *Registry) Get(key string) (interface{}, error) {
 		if service == nil {
 			return nil, fmt.Errorf("工厂方法返回nil对象")
 		}
+		r.mutateUnsafe()
 		r.services[key] = service
 		return service, nil
 	}
@@ -135,22 +166,39 @@ func (r *Registry) GetTyped(key string, target interface{}) error {
 }
 
 // Unregister 从注册表中删除服务
+// key 是别名时只删除别名；删除服务时指向它的别名也一并删除
 func (r *Registry) Unregister(key string) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
+	if _, isAlias := r.aliases[key]; isAlias {
+		r.mutateUnsafe()
+		delete(r.aliases, key)
+		return
+	}
+
+	_, existsService := r.services[key]
+	_, existsFactory := r.factories[key]
+	if !existsService && !existsFactory {
+		return
+	}
+
+	r.mutateUnsafe()
 	delete(r.services, key)
 	delete(r.factories, key)
+	for alias, target := range r.aliases {
+		if target == key {
+			delete(r.aliases, alias)
+		}
+	}
 }
 
-// Has 检查服务是否已注册
+// Has 检查服务是否已注册，key 也可以是别名
 func (r *Registry) Has(key string) bool {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	_, existsService := r.services[key]
-	_, existsFactory := r.factories[key]
-	return existsService || existsFactory
+	return r.hasKeyUnsafe(r.targetUnsafe(key))
 }
 
 // Clear 清空所有已注册的服务
@@ -160,9 +208,13 @@ func (r *Registry) Clear() {
 
 	r.services = make(map[string]interface{})
 	r.factories = make(map[string]ServiceCreator)
+	r.aliases = make(map[string]string)
+	r.typed = make(map[reflect.Type][]any)
+	r.shared = false
+	r.generation++
 }
 
-// Keys 返回所有已注册的服务键
+// Keys 返回所有已注册的服务键，不包含别名
 func (r *Registry) Keys() []string {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
@@ -0,0 +1,58 @@
+package registry
+
+import (
+	"fmt"
+
+	"github.com/XiaoluCoding626/go-design-pattern/concurrency/singleflight"
+)
+
+// EnableSingleFlight 让懒加载工厂在注册表的锁之外执行
+//
+// 默认情况下工厂在写锁内执行，较慢的工厂会阻塞所有服务的获取。
+// 启用后同一个键的并发首次获取合并为一次工厂调用，其他键的获取不受影响；
+// 工厂发生 panic 时返回包装了 singleflight.ErrPanicked 的错误，而不是向调用者传播 panic。
+func (r *Registry) EnableSingleFlight() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.flight == nil {
+		r.flight = &singleflight.Group[interface{}]{}
+	}
+}
+
+// getSingleFlight 通过单飞组实例化懒加载服务，key 必须是已经解析过别名的键
+func (r *Registry) getSingleFlight(flight *singleflight.Group[interface{}], key string) (interface{}, error) {
+	service, err, _ := flight.Do(key, func() (interface{}, error) {
+		r.mutex.RLock()
+		service, exists := r.services[key]
+		factory, hasFactory := r.factories[key]
+		r.mutex.RUnlock()
+
+		// 上一次合并的调用可能刚刚完成实例化
+		if exists {
+			return service, nil
+		}
+		if !hasFactory {
+			return nil, fmt.Errorf("服务 '%s' 未注册", key)
+		}
+
+		service = factory()
+		if service == nil {
+			return nil, fmt.Errorf("工厂方法返回nil对象")
+		}
+
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		if existing, exists := r.services[key]; exists {
+			return existing, nil
+		}
+		// 工厂执行期间服务可能已被注销，此时只返回实例而不保存
+		if _, stillRegistered := r.factories[key]; stillRegistered {
+			r.mutateUnsafe()
+			r.services[key] = service
+		}
+		return service, nil
+	})
+	return service, err
+}
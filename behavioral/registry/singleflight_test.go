@@ -0,0 +1,96 @@
+package registry
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/XiaoluCoding626/go-design-pattern/concurrency/singleflight"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSingleFlight_CoalescesFactoryCalls(t *testing.T) {
+	registry := NewRegistry()
+	registry.EnableSingleFlight()
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	assert.NoError(t, registry.RegisterFactory("db", func() interface{} {
+		calls.Add(1)
+		<-release
+		return &TestService{Name: "DB"}
+	}))
+	assert.NoError(t, registry.Register("config", &TestService{Name: "Config"}))
+	assert.NoError(t, registry.Alias("database", "db"))
+
+	var wg sync.WaitGroup
+	results := make([]interface{}, 20)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := "db"
+			if i%2 == 0 {
+				key = "database" // 别名与原键合并为同一次调用
+			}
+			service, err := registry.Get(key)
+			assert.NoError(t, err)
+			results[i] = service
+		}(i)
+	}
+
+	// 工厂执行期间不持有注册表的锁，其他服务可以正常获取
+	assert.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+	config, err := registry.Get("config")
+	assert.NoError(t, err)
+	assert.Equal(t, "Config", config.(*TestService).Name)
+	assert.True(t, registry.Has("db"))
+
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load(), "并发的首次获取只调用一次工厂")
+	for _, service := range results {
+		assert.Same(t, results[0], service)
+	}
+	again, err := registry.Get("db")
+	assert.NoError(t, err)
+	assert.Same(t, results[0], again)
+}
+
+func TestSingleFlight_Errors(t *testing.T) {
+	registry := NewRegistry()
+	registry.EnableSingleFlight()
+
+	_, err := registry.Get("missing")
+	assert.Error(t, err)
+
+	assert.NoError(t, registry.RegisterFactory("nil", func() interface{} { return nil }))
+	_, err = registry.Get("nil")
+	assert.EqualError(t, err, "工厂方法返回nil对象")
+	assert.False(t, registry.Has("missing"))
+
+	assert.NoError(t, registry.RegisterFactory("panic", func() interface{} { panic("boom") }))
+	_, err = registry.Get("panic")
+	assert.ErrorIs(t, err, singleflight.ErrPanicked)
+
+	// 工厂执行期间被注销的服务不会被保存
+	started := make(chan struct{})
+	release := make(chan struct{})
+	assert.NoError(t, registry.RegisterFactory("temp", func() interface{} {
+		close(started)
+		<-release
+		return &TestService{Name: "Temp"}
+	}))
+	done := make(chan error)
+	go func() {
+		_, err := registry.Get("temp")
+		done <- err
+	}()
+	<-started
+	registry.Unregister("temp")
+	close(release)
+	assert.NoError(t, <-done)
+	assert.False(t, registry.Has("temp"))
+}
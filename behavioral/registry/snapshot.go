@@ -0,0 +1,113 @@
+package registry
+
+import (
+	"errors"
+	"maps"
+	"reflect"
+	"sort"
+)
+
+// ErrForeignSnapshot 表示快照不属于当前注册表
+var ErrForeignSnapshot = errors.New("快照不属于该注册表")
+
+// RegistrySnapshot 是注册表在某一代的只读快照
+// 快照与注册表共享底层映射，注册表在下一次修改前才复制（写时复制），因此创建快照的开销是常数级的
+type RegistrySnapshot struct {
+	owner      *Registry
+	generation uint64
+	services   map[string]interface{}
+	factories  map[string]ServiceCreator
+	aliases    map[string]string
+	typed      map[reflect.Type][]any
+}
+
+// Generation 返回快照对应的注册表代数
+func (s RegistrySnapshot) Generation() uint64 {
+	return s.generation
+}
+
+// Has 检查快照中是否包含指定服务，key 也可以是别名
+func (s RegistrySnapshot) Has(key string) bool {
+	if target, ok := s.aliases[key]; ok {
+		key = target
+	}
+	_, existsService := s.services[key]
+	_, existsFactory := s.factories[key]
+	return existsService || existsFactory
+}
+
+// Keys 返回快照中所有服务键，按字典序排列，不包含别名
+func (s RegistrySnapshot) Keys() []string {
+	keys := make([]string, 0, len(s.services)+len(s.factories))
+	for k := range s.services {
+		keys = append(keys, k)
+	}
+	for k := range s.factories {
+		if _, exists := s.services[k]; !exists {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Len 返回快照中的服务数量
+func (s RegistrySnapshot) Len() int {
+	return len(s.Keys())
+}
+
+// Snapshot 捕获当前注册的服务和工厂
+func (r *Registry) Snapshot() RegistrySnapshot {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// 标记映射为共享，之后的修改会先复制映射，快照内容保持不变
+	r.shared = true
+	return RegistrySnapshot{
+		owner:      r,
+		generation: r.generation,
+		services:   r.services,
+		factories:  r.factories,
+		aliases:    r.aliases,
+		typed:      r.typed,
+	}
+}
+
+// Restore 原子地把注册表恢复到快照时的状态
+// 快照之后注册的服务会被移除，注销的服务会被恢复；快照时尚未实例化的懒加载服务恢复后仍为未实例化状态
+func (r *Registry) Restore(snap RegistrySnapshot) error {
+	if snap.owner != r {
+		return ErrForeignSnapshot
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// 快照继续持有这组映射，因此恢复后的注册表同样需要写时复制
+	r.services = snap.services
+	r.factories = snap.factories
+	r.aliases = snap.aliases
+	r.typed = snap.typed
+	r.shared = true
+	r.generation++
+	return nil
+}
+
+// Generation 返回注册表当前代数，每次修改都会使代数递增
+func (r *Registry) Generation() uint64 {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.generation
+}
+
+// mutateUnsafe 在修改前调用：映射被快照共享时先复制一份，并递增代数，调用方需持有写锁
+func (r *Registry) mutateUnsafe() {
+	if r.shared {
+		r.services = maps.Clone(r.services)
+		r.factories = maps.Clone(r.factories)
+		r.aliases = maps.Clone(r.aliases)
+		r.typed = maps.Clone(r.typed) // 实现列表只会整体替换，不会原地修改，浅复制即可
+		r.shared = false
+	}
+	r.generation++
+}
@@ -0,0 +1,101 @@
+package registry
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	registry := NewRegistry()
+	original := &TestService{Name: "Original"}
+	assert.NoError(t, registry.Register("original", original))
+	assert.NoError(t, registry.RegisterFactory("lazy", func() interface{} {
+		return &TestService{Name: "Lazy"}
+	}))
+
+	snap := registry.Snapshot()
+	assert.Equal(t, []string{"lazy", "original"}, snap.Keys())
+
+	// 快照之后的临时修改
+	assert.NoError(t, registry.Register("temp", &TestService{Name: "Temp"}))
+	registry.Unregister("original")
+	_, err := registry.Get("lazy")
+	assert.NoError(t, err)
+
+	// 修改不影响快照
+	assert.False(t, snap.Has("temp"))
+	assert.True(t, snap.Has("original"))
+	assert.Equal(t, 2, snap.Len())
+
+	// 恢复后回到快照时的状态
+	assert.NoError(t, registry.Restore(snap))
+	assert.False(t, registry.Has("temp"))
+	result, err := registry.Get("original")
+	assert.NoError(t, err)
+	assert.Same(t, original, result)
+	assert.ElementsMatch(t, []string{"lazy", "original"}, registry.Keys())
+
+	// 恢复后再修改也不会影响快照，同一个快照可以多次恢复
+	assert.NoError(t, registry.Register("again", &TestService{Name: "Again"}))
+	assert.False(t, snap.Has("again"))
+	assert.NoError(t, registry.Restore(snap))
+	assert.False(t, registry.Has("again"))
+}
+
+func TestSnapshotCopyOnWrite(t *testing.T) {
+	registry := NewRegistry()
+	assert.NoError(t, registry.Register("a", &TestService{Name: "A"}))
+
+	// 没有修改时多个快照共享同一组映射
+	first := registry.Snapshot()
+	second := registry.Snapshot()
+	assert.Equal(t, first.Generation(), second.Generation())
+	registry.mutex.RLock()
+	assert.True(t, registry.shared)
+	registry.mutex.RUnlock()
+
+	// 第一次修改复制映射，之后的修改直接写入
+	assert.NoError(t, registry.Register("b", &TestService{Name: "B"}))
+	registry.mutex.RLock()
+	assert.False(t, registry.shared)
+	registry.mutex.RUnlock()
+	assert.Greater(t, registry.Generation(), first.Generation())
+	assert.False(t, first.Has("b"))
+
+	// 注销不存在的服务不算修改
+	generation := registry.Generation()
+	registry.Unregister("missing")
+	assert.Equal(t, generation, registry.Generation())
+}
+
+func TestRestoreForeignSnapshot(t *testing.T) {
+	a := NewRegistry()
+	b := NewRegistry()
+	assert.ErrorIs(t, b.Restore(a.Snapshot()), ErrForeignSnapshot)
+	assert.ErrorIs(t, a.Restore(RegistrySnapshot{}), ErrForeignSnapshot)
+}
+
+func TestSnapshotConcurrent(t *testing.T) {
+	registry := NewRegistry()
+	base := registry.Snapshot()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := string(rune('a' + i))
+			_ = registry.Register(key, &TestService{Name: key})
+			snap := registry.Snapshot()
+			_ = snap.Keys()
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Len(t, registry.Keys(), 10)
+	assert.NoError(t, registry.Restore(base))
+	assert.Empty(t, registry.Keys())
+	assert.Equal(t, 0, base.Len())
+}
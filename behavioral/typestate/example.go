@@ -0,0 +1,43 @@
+package typestate
+
+import "fmt"
+
+// RunExample 运行类型状态示例：同一个"打开 → 发送 → 关闭"协议的三种实现
+func RunExample() {
+	fmt.Println("=== 运行时检查 ===")
+	wire := NewWire()
+	guarded := NewGuardedConn(wire, "db:5432")
+	fmt.Println("打开前发送:", guarded.Send("SELECT 1")) // 编译通过，运行时才发现
+	guarded.Open()
+	guarded.Send("SELECT 1")
+	guarded.Close()
+	fmt.Println("关闭后发送:", guarded.Send("SELECT 2"))
+	fmt.Println("线路:", wire.Frames())
+
+	fmt.Println("=== 分阶段类型 ===")
+	wire = NewWire()
+	idle := Dial(wire, "db:5432")
+	// idle.Send("SELECT 1") // 编译错误：IdleConn 没有 Send 方法
+	conn, err := idle.Open()
+	if err != nil {
+		fmt.Println("打开失败:", err)
+		return
+	}
+	conn.Send("SELECT 1")
+	closed, _ := conn.Close()
+	// closed.Send("SELECT 2") // 编译错误：ClosedConn 没有 Send 方法
+	fmt.Printf("%s 共发送 %d 条消息\n", closed.Addr(), closed.Sent())
+	// 类型系统无法阻止继续使用旧句柄，由运行时兜底
+	fmt.Println("使用关闭前的旧句柄发送:", conn.Send("SELECT 2"))
+	fmt.Println("线路:", wire.Frames())
+
+	fmt.Println("=== 幽灵类型 ===")
+	wire = NewWire()
+	session := NewSession(wire, "cache:6379")
+	// Send(session, "PING") // 编译错误：Session[Idle] 不能用作 Session[Opened]
+	opened, _ := Open(session)
+	Send(opened, "PING")
+	done, _ := Close(opened)
+	fmt.Printf("%s 共发送 %d 条消息\n", done.Addr(), done.Sent())
+	fmt.Println("线路:", wire.Frames())
+}
@@ -0,0 +1,111 @@
+package typestate
+
+import (
+	"fmt"
+	"sync"
+)
+
+// State 运行时检查方式中连接的状态
+type State int
+
+const (
+	StateIdle   State = iota // 已创建，尚未打开
+	StateOpen                // 已打开，可以发送
+	StateClosed              // 已关闭，不能再使用
+)
+
+// String 返回状态名称
+func (s State) String() string {
+	switch s {
+	case StateIdle:
+		return "未打开"
+	case StateOpen:
+		return "已打开"
+	case StateClosed:
+		return "已关闭"
+	default:
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+}
+
+// GuardedConn 运行时检查方式：一个类型拥有所有方法，每个方法先检查当前状态
+//
+// 调用顺序错误只能在运行时以 ErrInvalidState 的形式发现，需要测试覆盖每一条错误路径。
+type GuardedConn struct {
+	wire *Wire
+	addr string
+
+	mutex sync.Mutex
+	state State
+	sent  int
+}
+
+// NewGuardedConn 创建尚未打开的连接
+func NewGuardedConn(wire *Wire, addr string) *GuardedConn {
+	return &GuardedConn{wire: wire, addr: addr}
+}
+
+// State 返回当前状态
+func (c *GuardedConn) State() State {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.state
+}
+
+// Open 握手，只能在未打开时调用；握手失败时保持未打开，可以重试
+func (c *GuardedConn) Open() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if err := c.expectLocked(StateIdle, "打开"); err != nil {
+		return err
+	}
+	if err := c.wire.hello(c.addr); err != nil {
+		return err
+	}
+	c.state = StateOpen
+	return nil
+}
+
+// Send 发送消息，只能在已打开时调用
+func (c *GuardedConn) Send(msg string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if err := c.expectLocked(StateOpen, "发送"); err != nil {
+		return err
+	}
+	if err := c.wire.data(msg); err != nil {
+		return err
+	}
+	c.sent++
+	return nil
+}
+
+// Close 挥手并关闭，只能在已打开时调用
+func (c *GuardedConn) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if err := c.expectLocked(StateOpen, "关闭"); err != nil {
+		return err
+	}
+	c.wire.bye(c.addr)
+	c.state = StateClosed
+	return nil
+}
+
+// Sent 返回已发送的消息数，任何状态下都可以调用
+func (c *GuardedConn) Sent() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.sent
+}
+
+// expectLocked 检查当前状态，调用者需持有锁
+func (c *GuardedConn) expectLocked(want State, op string) error {
+	if c.state != want {
+		return fmt.Errorf("%w: 连接%s，不能%s", ErrInvalidState, c.state, op)
+	}
+	return nil
+}
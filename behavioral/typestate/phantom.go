@@ -0,0 +1,90 @@
+package typestate
+
+// 幽灵类型（phantom type）方式：状态是泛型参数，只出现在类型中，不占用任何内存
+//
+// 与分阶段类型相比，所有阶段共用一个 Session 实现，
+// 与状态无关的操作（如 Sent、Addr）只需写一次，泛型代码也可以接受任意阶段的会话。
+// Go 的方法不能针对某个类型参数特化，所以与状态相关的操作写成普通函数，由参数类型限定阶段。
+
+// Idle 会话尚未打开
+type Idle struct{}
+
+// Opened 会话已打开
+type Opened struct{}
+
+// Closed 会话已关闭
+type Closed struct{}
+
+// Phase 会话所有可能的阶段，类型参数只能是这三种之一
+type Phase interface {
+	Idle | Opened | Closed
+}
+
+// Session 阶段为 S 的会话
+type Session[S Phase] struct {
+	handle
+}
+
+// NewSession 创建尚未打开的会话
+func NewSession(wire *Wire, addr string) Session[Idle] {
+	return Session[Idle]{handle{link: &link{wire: wire, addr: addr}}}
+}
+
+// Open 握手，只接受尚未打开的会话；握手失败时原会话仍然有效
+func Open(s Session[Idle]) (Session[Opened], error) {
+	l, err := s.lock("打开")
+	if err != nil {
+		return Session[Opened]{}, err
+	}
+	defer l.mutex.Unlock()
+
+	if err := l.wire.hello(l.addr); err != nil {
+		return Session[Opened]{}, err
+	}
+	return Session[Opened]{l.advanceLocked()}, nil
+}
+
+// Send 发送消息，只接受已打开的会话
+func Send(s Session[Opened], msg string) error {
+	l, err := s.lock("发送")
+	if err != nil {
+		return err
+	}
+	defer l.mutex.Unlock()
+
+	if err := l.wire.data(msg); err != nil {
+		return err
+	}
+	l.sent++
+	return nil
+}
+
+// Close 挥手并关闭，只接受已打开的会话
+func Close(s Session[Opened]) (Session[Closed], error) {
+	l, err := s.lock("关闭")
+	if err != nil {
+		return Session[Closed]{}, err
+	}
+	defer l.mutex.Unlock()
+
+	l.wire.bye(l.addr)
+	return Session[Closed]{l.advanceLocked()}, nil
+}
+
+// Addr 返回会话地址，任意阶段都可以调用
+func (s Session[S]) Addr() string {
+	if s.link == nil {
+		return ""
+	}
+	return s.link.addr
+}
+
+// Sent 返回已发送的消息数，任意阶段都可以调用
+func (s Session[S]) Sent() int {
+	if s.link == nil {
+		return 0
+	}
+	s.link.mutex.Lock()
+	defer s.link.mutex.Unlock()
+	return s.link.sent
+}
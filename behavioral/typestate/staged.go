@@ -0,0 +1,118 @@
+package typestate
+
+import (
+	"fmt"
+	"sync"
+)
+
+// link 分阶段连接背后共享的连接状态
+//
+// Go 没有"移动语义"，状态转换之后旧的句柄仍然可以使用。
+// 每次转换都会递增 stage，持有旧 stage 的句柄在运行时被拒绝，作为类型系统管不到的部分的兜底。
+type link struct {
+	wire *Wire
+	addr string
+
+	mutex sync.Mutex
+	stage uint64
+	sent  int
+}
+
+// handle 某个阶段的连接句柄
+type handle struct {
+	link  *link
+	stage uint64
+}
+
+// lock 检查句柄是否仍然有效，有效时返回连接并持有锁
+func (h handle) lock(op string) (*link, error) {
+	if h.link == nil {
+		return nil, fmt.Errorf("%w: 零值句柄不能%s", ErrStaleHandle, op)
+	}
+	h.link.mutex.Lock()
+	if h.link.stage != h.stage {
+		h.link.mutex.Unlock()
+		return nil, fmt.Errorf("%w: 连接 %s 已进入下一阶段，不能%s", ErrStaleHandle, h.link.addr, op)
+	}
+	return h.link, nil
+}
+
+// advanceLocked 进入下一阶段，返回新阶段的句柄，调用者需持有锁
+func (l *link) advanceLocked() handle {
+	l.stage++
+	return handle{link: l, stage: l.stage}
+}
+
+// IdleConn 分阶段类型方式中尚未打开的连接：只有 Open 方法
+type IdleConn struct {
+	handle
+}
+
+// OpenConn 已打开的连接：只有 Send 和 Close 方法
+type OpenConn struct {
+	handle
+}
+
+// ClosedConn 已关闭的连接：只能查询发送统计，没有任何会产生网络操作的方法
+type ClosedConn struct {
+	addr string
+	sent int
+}
+
+// Dial 创建尚未打开的连接
+func Dial(wire *Wire, addr string) *IdleConn {
+	return &IdleConn{handle{link: &link{wire: wire, addr: addr}}}
+}
+
+// Open 握手，返回已打开的连接；之后这个 IdleConn 失效
+// 握手失败时 IdleConn 仍然有效，可以重试
+func (c *IdleConn) Open() (*OpenConn, error) {
+	l, err := c.lock("打开")
+	if err != nil {
+		return nil, err
+	}
+	defer l.mutex.Unlock()
+
+	if err := l.wire.hello(l.addr); err != nil {
+		return nil, err
+	}
+	return &OpenConn{l.advanceLocked()}, nil
+}
+
+// Send 发送消息
+func (c *OpenConn) Send(msg string) error {
+	l, err := c.lock("发送")
+	if err != nil {
+		return err
+	}
+	defer l.mutex.Unlock()
+
+	if err := l.wire.data(msg); err != nil {
+		return err
+	}
+	l.sent++
+	return nil
+}
+
+// Close 挥手并关闭，返回已关闭的连接；之后这个 OpenConn 失效
+func (c *OpenConn) Close() (*ClosedConn, error) {
+	l, err := c.lock("关闭")
+	if err != nil {
+		return nil, err
+	}
+	defer l.mutex.Unlock()
+
+	l.wire.bye(l.addr)
+	l.advanceLocked()
+	return &ClosedConn{addr: l.addr, sent: l.sent}, nil
+}
+
+// Addr 返回连接地址
+func (c *ClosedConn) Addr() string {
+	return c.addr
+}
+
+// Sent 返回连接打开期间发送的消息数
+func (c *ClosedConn) Sent() int {
+	return c.sent
+}
@@ -0,0 +1,198 @@
+package typestate
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// methods 返回类型的导出方法名，已排序
+func methods(v any) []string {
+	typ := reflect.TypeOf(v)
+	names := make([]string, typ.NumMethod())
+	for i := range names {
+		names[i] = typ.Method(i).Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TestStagedMethodSets 测试分阶段类型在编译期限定了每个阶段能调用的方法
+// 调用不存在的方法是编译错误，这里用反射检查方法集来代替无法编写的"编译失败"测试
+func TestStagedMethodSets(t *testing.T) {
+	assert.Equal(t, []string{"Open"}, methods(&IdleConn{}))
+	assert.Equal(t, []string{"Close", "Send"}, methods(&OpenConn{}))
+	assert.Equal(t, []string{"Addr", "Sent"}, methods(&ClosedConn{}))
+
+	// 对比：运行时检查方式的所有方法在任何状态下都存在
+	assert.Equal(t, []string{"Close", "Open", "Send", "Sent", "State"}, methods(&GuardedConn{}))
+}
+
+// TestPhantomSignatures 测试幽灵类型通过参数类型限定阶段
+func TestPhantomSignatures(t *testing.T) {
+	idle := reflect.TypeOf(Session[Idle]{})
+	opened := reflect.TypeOf(Session[Opened]{})
+	closed := reflect.TypeOf(Session[Closed]{})
+
+	sendParam := reflect.TypeOf(Send).In(0)
+	assert.True(t, opened.AssignableTo(sendParam))
+	assert.False(t, idle.AssignableTo(sendParam), "未打开的会话不能发送")
+	assert.False(t, closed.AssignableTo(sendParam), "已关闭的会话不能发送")
+
+	openParam := reflect.TypeOf(Open).In(0)
+	assert.True(t, idle.AssignableTo(openParam))
+	assert.False(t, closed.AssignableTo(openParam), "已关闭的会话不能重新打开")
+
+	closeParam := reflect.TypeOf(Close).In(0)
+	assert.False(t, idle.AssignableTo(closeParam))
+	assert.Equal(t, closed, reflect.TypeOf(Close).Out(0))
+}
+
+// TestProtocolEquivalence 测试三种实现按正确顺序调用时在线路上产生相同的帧
+func TestProtocolEquivalence(t *testing.T) {
+	want := []string{"HELLO db", "DATA a", "DATA b", "BYE db"}
+
+	wire := NewWire()
+	g := NewGuardedConn(wire, "db")
+	assert.NoError(t, g.Open())
+	assert.NoError(t, g.Send("a"))
+	assert.NoError(t, g.Send("b"))
+	assert.NoError(t, g.Close())
+	assert.Equal(t, want, wire.Frames())
+	assert.Equal(t, 2, g.Sent())
+
+	wire = NewWire()
+	conn, err := Dial(wire, "db").Open()
+	assert.NoError(t, err)
+	assert.NoError(t, conn.Send("a"))
+	assert.NoError(t, conn.Send("b"))
+	closed, err := conn.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, want, wire.Frames())
+	assert.Equal(t, 2, closed.Sent())
+
+	wire = NewWire()
+	s, err := Open(NewSession(wire, "db"))
+	assert.NoError(t, err)
+	assert.NoError(t, Send(s, "a"))
+	assert.NoError(t, Send(s, "b"))
+	done, err := Close(s)
+	assert.NoError(t, err)
+	assert.Equal(t, want, wire.Frames())
+	assert.Equal(t, 2, done.Sent())
+	assert.Equal(t, "db", done.Addr())
+}
+
+// TestGuardedRejectsWrongOrder 测试运行时检查方式：每一种错误顺序都要在运行时发现
+func TestGuardedRejectsWrongOrder(t *testing.T) {
+	wire := NewWire()
+	c := NewGuardedConn(wire, "db")
+	assert.Equal(t, StateIdle, c.State())
+	assert.ErrorIs(t, c.Send("x"), ErrInvalidState, "打开前发送")
+	assert.ErrorIs(t, c.Close(), ErrInvalidState, "打开前关闭")
+
+	wire.SetDown(true)
+	assert.ErrorIs(t, c.Open(), ErrWireDown)
+	assert.Equal(t, StateIdle, c.State(), "握手失败保持未打开")
+	wire.SetDown(false)
+
+	assert.NoError(t, c.Open())
+	assert.ErrorIs(t, c.Open(), ErrInvalidState, "重复打开")
+	assert.ErrorIs(t, c.Send(""), ErrEmptyMessage)
+	assert.NoError(t, c.Close())
+	assert.Equal(t, StateClosed, c.State())
+
+	err := c.Send("x")
+	assert.ErrorIs(t, err, ErrInvalidState, "关闭后发送")
+	assert.ErrorContains(t, err, "已关闭")
+	assert.ErrorIs(t, c.Open(), ErrInvalidState, "关闭后重新打开")
+	assert.ErrorIs(t, c.Close(), ErrInvalidState, "重复关闭")
+	assert.Equal(t, []string{"HELLO db", "BYE db"}, wire.Frames(), "被拒绝的操作不产生任何帧")
+}
+
+// TestStagedRejectsStaleHandles 测试分阶段类型中类型系统管不到的部分：旧句柄和零值句柄由运行时拒绝
+func TestStagedRejectsStaleHandles(t *testing.T) {
+	wire := NewWire()
+	idle := Dial(wire, "db")
+
+	wire.SetDown(true)
+	_, err := idle.Open()
+	assert.ErrorIs(t, err, ErrWireDown)
+	wire.SetDown(false)
+
+	conn, err := idle.Open()
+	assert.NoError(t, err, "握手失败后原句柄仍然有效")
+	_, err = idle.Open()
+	assert.ErrorIs(t, err, ErrStaleHandle, "同一个 IdleConn 不能打开两次")
+
+	_, err = conn.Close()
+	assert.NoError(t, err)
+	assert.ErrorIs(t, conn.Send("x"), ErrStaleHandle, "关闭后的旧句柄")
+	_, err = conn.Close()
+	assert.ErrorIs(t, err, ErrStaleHandle)
+
+	var zero OpenConn
+	assert.ErrorIs(t, zero.Send("x"), ErrStaleHandle, "绕过 Open 构造的零值")
+	assert.Equal(t, []string{"HELLO db", "BYE db"}, wire.Frames())
+
+	// 幽灵类型同样如此
+	session := NewSession(wire, "cache")
+	opened, err := Open(session)
+	assert.NoError(t, err)
+	_, err = Open(session)
+	assert.ErrorIs(t, err, ErrStaleHandle)
+	_, err = Close(opened)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, Send(opened, "x"), ErrStaleHandle)
+	assert.ErrorIs(t, Send(Session[Opened]{}, "x"), ErrStaleHandle)
+	assert.Equal(t, 0, Session[Closed]{}.Sent())
+}
+
+// TestConcurrentSendAndClose 测试并发发送与关闭：关闭之后的发送一律失败，不会出现在 BYE 之后
+func TestConcurrentSendAndClose(t *testing.T) {
+	wire := NewWire()
+	conn, err := Dial(wire, "db").Open()
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				conn.Send("x")
+			}
+		}()
+	}
+	closed, err := conn.Close()
+	assert.NoError(t, err)
+	wg.Wait()
+
+	frames := wire.Frames()
+	assert.Equal(t, "BYE db", frames[len(frames)-1])
+	assert.Equal(t, closed.Sent(), len(frames)-2)
+}
+
+// BenchmarkSend 比较两种方式发送一条消息的开销
+func BenchmarkSend(b *testing.B) {
+	b.Run("Guarded", func(b *testing.B) {
+		c := NewGuardedConn(NewWire(), "db")
+		c.Open()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			c.Send("x")
+		}
+	})
+	b.Run("Staged", func(b *testing.B) {
+		c, _ := Dial(NewWire(), "db").Open()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			c.Send("x")
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package typestate
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// 协议相关错误
+var (
+	ErrInvalidState = errors.New("当前状态不允许该操作")
+	ErrStaleHandle  = errors.New("连接句柄已失效")
+	ErrWireDown     = errors.New("线路不可用")
+	ErrEmptyMessage = errors.New("消息为空")
+)
+
+// Wire 内存中模拟的线路，记录连接发出的每一帧，三种连接实现共用
+// 协议：HELLO 地址 → 若干 DATA 消息 → BYE 地址
+type Wire struct {
+	mutex  sync.Mutex
+	frames []string
+	down   bool
+}
+
+// NewWire 创建线路
+func NewWire() *Wire {
+	return &Wire{}
+}
+
+// Frames 返回已发出的所有帧
+func (w *Wire) Frames() []string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return append([]string(nil), w.frames...)
+}
+
+// SetDown 模拟线路故障，故障期间握手失败
+func (w *Wire) SetDown(down bool) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.down = down
+}
+
+// hello 握手
+func (w *Wire) hello(addr string) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.down {
+		return fmt.Errorf("%w: 无法连接 %s", ErrWireDown, addr)
+	}
+	w.frames = append(w.frames, "HELLO "+addr)
+	return nil
+}
+
+// data 发送一条消息
+func (w *Wire) data(msg string) error {
+	if msg == "" {
+		return ErrEmptyMessage
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.frames = append(w.frames, "DATA "+msg)
+	return nil
+}
+
+// bye 挥手
+func (w *Wire) bye(addr string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.frames = append(w.frames, "BYE "+addr)
+}
@@ -0,0 +1,201 @@
+package visitor
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 内置语言区域标识
+const (
+	LocaleZhCN = "zh-CN"
+	LocaleEnUS = "en-US"
+)
+
+// 消息目录中的键
+const (
+	MsgLeopardName        = "scenery.leopard.name"
+	MsgLeopardDescription = "scenery.leopard.description"
+	MsgDolphinName        = "scenery.dolphin.name"
+	MsgDolphinShowName    = "scenery.dolphin.show.name"
+	MsgDolphinDescription = "scenery.dolphin.description"
+	MsgAquariumName       = "scenery.aquarium.name"
+	MsgAquariumVIPName    = "scenery.aquarium.vip.name"
+	MsgAquariumDesc       = "scenery.aquarium.description"
+	MsgVisitorStudent     = "visitor.student"
+	MsgVisitorCommon      = "visitor.common"
+	MsgVisitorVIP         = "visitor.vip" // 参数: VIP 等级
+	MsgReceiptTitle       = "receipt.title"
+	MsgReceiptVisitor     = "receipt.visitor" // 参数: 访问者类型
+	MsgReceiptOriginal    = "receipt.original" // 参数: 格式化后的原价
+	MsgReceiptTotal       = "receipt.total"
+)
+
+// ErrInvalidLocale 表示要注册的语言区域不完整
+var ErrInvalidLocale = errors.New("invalid locale")
+
+// Catalog 消息目录，键为消息标识，值为可包含 fmt 占位符的翻译文本
+type Catalog map[string]string
+
+// Locale 语言区域，决定货币格式和翻译文本
+// 所有金额都以人民币元为单位，Locale 只改变展示方式，不做汇率换算
+type Locale struct {
+	Tag            string  // 语言区域标识，如 "zh-CN"
+	CurrencySymbol string  // 货币符号
+	SymbolAfter    bool    // 货币符号是否放在数字之后
+	Decimals       int     // 小数位数
+	DecimalSep     string  // 小数点
+	GroupSep       string  // 千位分隔符，空字符串表示不分组
+	Messages       Catalog // 消息目录
+}
+
+// T 翻译消息，找不到时依次回退到默认语言区域和消息键本身
+func (l Locale) T(key string, args ...any) string {
+	text, ok := l.Messages[key]
+	if !ok {
+		if fallback, found := LookupLocale(LocaleZhCN); found && l.Tag != LocaleZhCN {
+			text, ok = fallback.Messages[key]
+		}
+	}
+	if !ok {
+		text = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+// FormatNumber 按语言区域格式化整数金额的数字部分
+func (l Locale) FormatNumber(amount int) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := fmt.Sprint(amount)
+	if l.GroupSep != "" {
+		var b strings.Builder
+		for i, r := range digits {
+			if i > 0 && (len(digits)-i)%3 == 0 {
+				b.WriteString(l.GroupSep)
+			}
+			b.WriteRune(r)
+		}
+		digits = b.String()
+	}
+	if l.Decimals > 0 {
+		digits += l.DecimalSep + strings.Repeat("0", l.Decimals)
+	}
+	return sign + digits
+}
+
+// FormatMoney 按语言区域格式化金额（单位: 元）
+func (l Locale) FormatMoney(amount int) string {
+	if l.SymbolAfter {
+		return l.FormatNumber(amount) + l.CurrencySymbol
+	}
+	return l.CurrencySymbol + l.FormatNumber(amount)
+}
+
+// 语言区域注册表
+var (
+	localesMu sync.RWMutex
+	locales   = map[string]Locale{
+		LocaleZhCN: {
+			Tag:            LocaleZhCN,
+			CurrencySymbol: "元",
+			SymbolAfter:    true,
+			DecimalSep:     ".",
+			GroupSep:       ",",
+			Messages: Catalog{
+				MsgLeopardName:        "豹子馆",
+				MsgLeopardDescription: "观赏猎豹、美洲豹等各种豹科动物",
+				MsgDolphinName:        "海豚馆",
+				MsgDolphinShowName:    "海豚馆(含表演)",
+				MsgDolphinDescription: "观赏海豚并可能欣赏精彩表演",
+				MsgAquariumName:       "水族馆",
+				MsgAquariumVIPName:    "水族馆(含VIP区)",
+				MsgAquariumDesc:       "欣赏各种海洋生物",
+				MsgVisitorStudent:     "学生",
+				MsgVisitorCommon:      "普通",
+				MsgVisitorVIP:         "VIP-%d",
+				MsgReceiptTitle:       "参观小票",
+				MsgReceiptVisitor:     "游客类型: %s",
+				MsgReceiptOriginal:    "原价 %s",
+				MsgReceiptTotal:       "合计",
+			},
+		},
+		LocaleEnUS: {
+			Tag:            LocaleEnUS,
+			CurrencySymbol: "CN¥",
+			Decimals:       2,
+			DecimalSep:     ".",
+			GroupSep:       ",",
+			Messages: Catalog{
+				MsgLeopardName:        "Leopard House",
+				MsgLeopardDescription: "Cheetahs, jaguars and other big cats",
+				MsgDolphinName:        "Dolphin Pool",
+				MsgDolphinShowName:    "Dolphin Pool (with show)",
+				MsgDolphinDescription: "Watch dolphins and maybe catch a show",
+				MsgAquariumName:       "Aquarium",
+				MsgAquariumVIPName:    "Aquarium (with VIP area)",
+				MsgAquariumDesc:       "Marine life from around the world",
+				MsgVisitorStudent:     "Student",
+				MsgVisitorCommon:      "Regular",
+				MsgVisitorVIP:         "VIP level %d",
+				MsgReceiptTitle:       "Visit Receipt",
+				MsgReceiptVisitor:     "Visitor: %s",
+				MsgReceiptOriginal:    "was %s",
+				MsgReceiptTotal:       "Total",
+			},
+		},
+	}
+)
+
+// RegisterLocale 注册或替换一个语言区域
+// 消息目录可以只包含部分键，缺失的键回退到 zh-CN
+func RegisterLocale(locale Locale) error {
+	if locale.Tag == "" {
+		return fmt.Errorf("%w: empty tag", ErrInvalidLocale)
+	}
+	if locale.Decimals > 0 && locale.DecimalSep == "" {
+		return fmt.Errorf("%w: %s has decimals but no decimal separator", ErrInvalidLocale, locale.Tag)
+	}
+
+	// 复制消息目录，避免调用方之后修改影响已注册的语言区域
+	messages := make(Catalog, len(locale.Messages))
+	for k, v := range locale.Messages {
+		messages[k] = v
+	}
+	locale.Messages = messages
+
+	localesMu.Lock()
+	defer localesMu.Unlock()
+	locales[locale.Tag] = locale
+	return nil
+}
+
+// LookupLocale 按标识查找语言区域
+func LookupLocale(tag string) (Locale, bool) {
+	localesMu.RLock()
+	defer localesMu.RUnlock()
+	locale, ok := locales[tag]
+	return locale, ok
+}
+
+// Locales 返回所有已注册的语言区域标识，按字典序排列
+func Locales() []string {
+	localesMu.RLock()
+	defer localesMu.RUnlock()
+
+	tags := make([]string, 0, len(locales))
+	for tag := range locales {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
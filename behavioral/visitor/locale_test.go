@@ -0,0 +1,120 @@
+package visitor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLocaleFormatMoney 测试货币格式化
+func TestLocaleFormatMoney(t *testing.T) {
+	assert := assert.New(t)
+
+	zh, ok := LookupLocale(LocaleZhCN)
+	assert.True(ok)
+	en, ok := LookupLocale(LocaleEnUS)
+	assert.True(ok)
+
+	assert.Equal("25元", zh.FormatMoney(25))
+	assert.Equal("1,234,567元", zh.FormatMoney(1234567))
+	assert.Equal("CN¥25.00", en.FormatMoney(25))
+	assert.Equal("CN¥1,000.00", en.FormatMoney(1000))
+	assert.Equal("CN¥-5.00", en.FormatMoney(-5))
+	assert.Equal("-123", Locale{}.FormatNumber(-123))
+}
+
+// TestReceiptLocalized 测试生成中英文小票
+func TestReceiptLocalized(t *testing.T) {
+	assert := assert.New(t)
+	zoo := newTestZoo()
+
+	zh, _ := LookupLocale(LocaleZhCN)
+	en, _ := LookupLocale(LocaleEnUS)
+
+	var zhReceipt, enReceipt Receipt
+	captureOutput(func() {
+		zhReceipt = zoo.Receipt(NewStudentVisitor(true), zh)
+		enReceipt = zoo.Receipt(NewVIPVisitor(2), en)
+	})
+
+	// 学生半价: 12+22+25
+	assert.Equal("学生", zhReceipt.VisitorType)
+	assert.Len(zhReceipt.Lines, 3)
+	assert.Equal("豹子馆", zhReceipt.Lines[0].Scenery)
+	assert.Equal("海豚馆(含表演)", zhReceipt.Lines[1].Scenery)
+	assert.Equal(59, zhReceipt.Total)
+	assert.Equal(
+		"=== 参观小票 ===\n"+
+			"游客类型: 学生\n"+
+			"豹子馆: 12元 (原价 25元)\n"+
+			"海豚馆(含表演): 22元 (原价 45元)\n"+
+			"水族馆(含VIP区): 25元 (原价 50元)\n"+
+			"合计: 59元\n",
+		zhReceipt.String())
+
+	// VIP-2 八折: 20+36+40
+	assert.Equal("VIP level 2", enReceipt.VisitorType)
+	assert.Equal("Aquarium (with VIP area)", enReceipt.Lines[2].Scenery)
+	assert.Equal("Marine life from around the world", enReceipt.Lines[2].Description)
+	assert.Equal(96, enReceipt.Total)
+	assert.Contains(enReceipt.String(), "Leopard House: CN¥20.00 (was CN¥25.00)")
+	assert.Contains(enReceipt.String(), "Total: CN¥96.00")
+}
+
+// TestReceiptVisitorDelegates 测试小票访问者不改变被包装访问者的计价
+func TestReceiptVisitorDelegates(t *testing.T) {
+	zoo := newTestZoo()
+	common := NewCommonVisitor(false)
+	en, _ := LookupLocale(LocaleEnUS)
+
+	var receipt Receipt
+	captureOutput(func() {
+		receipt = zoo.Receipt(common, en)
+	})
+
+	assert.Equal(t, 120, common.GetTotalExpense())
+	assert.Equal(t, common.GetTotalExpense(), receipt.Total)
+	assert.NotContains(t, receipt.String(), "was", "原价与实付相同时不显示原价")
+}
+
+// TestRegisterLocale 测试注册自定义语言区域
+func TestRegisterLocale(t *testing.T) {
+	assert := assert.New(t)
+
+	catalog := Catalog{
+		MsgLeopardName:    "Leopardenhaus",
+		MsgVisitorStudent: "Student(in)",
+		MsgReceiptTotal:   "Summe",
+	}
+	err := RegisterLocale(Locale{
+		Tag:            "de-DE",
+		CurrencySymbol: " CNY",
+		SymbolAfter:    true,
+		Decimals:       2,
+		DecimalSep:     ",",
+		GroupSep:       ".",
+		Messages:       catalog,
+	})
+	assert.NoError(err)
+	assert.Contains(Locales(), "de-DE")
+
+	// 注册后修改原目录不影响已注册的语言区域
+	catalog[MsgLeopardName] = "geändert"
+
+	de, ok := LookupLocale("de-DE")
+	assert.True(ok)
+	assert.Equal("1.250,00 CNY", de.FormatMoney(1250))
+	assert.Equal("Leopardenhaus", de.T(MsgLeopardName))
+	assert.Equal("海豚馆", de.T(MsgDolphinName), "缺失的键应回退到 zh-CN")
+	assert.Equal("unknown.key", de.T("unknown.key"))
+
+	var receipt Receipt
+	captureOutput(func() {
+		receipt = newTestZoo().Receipt(NewStudentVisitor(false), de)
+	})
+	assert.Equal("Student(in)", receipt.VisitorType)
+	assert.Contains(receipt.String(), "Summe: 96,00 CNY")
+
+	assert.ErrorIs(RegisterLocale(Locale{}), ErrInvalidLocale)
+	assert.ErrorIs(RegisterLocale(Locale{Tag: "xx", Decimals: 2}), ErrInvalidLocale)
+}
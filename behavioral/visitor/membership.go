@@ -0,0 +1,290 @@
+package visitor
+
+import (
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+)
+
+// Membership 会员权益 - 任何访问者都可以持有，由动物园在 Accept 时检查
+type Membership interface {
+	// Name 会员类型名称，用于统计
+	Name() string
+	// Admit 在进入景点时调用，price 为访问者按自身规则算出的票价；
+	// 返回会员实际应付的票价，以及会员权益是否生效
+	Admit(scenery Scenery, price int, at time.Time) (charged int, applied bool)
+}
+
+// PassKind 季票类型
+type PassKind int
+
+const (
+	PassMonthly PassKind = iota // 月卡：有效期内所有景点半价
+	PassAnnual                  // 年卡：有效期内所有景点免票
+)
+
+// String 返回季票类型名称
+func (k PassKind) String() string {
+	switch k {
+	case PassMonthly:
+		return "月卡"
+	case PassAnnual:
+		return "年卡"
+	default:
+		return "未知季票"
+	}
+}
+
+// SeasonPass 季票 - 在有效期内减免票价
+type SeasonPass struct {
+	kind       PassKind
+	validFrom  time.Time
+	validUntil time.Time
+}
+
+// NewMonthlyPass 创建从 start 起一个月内有效的月卡
+func NewMonthlyPass(start time.Time) *SeasonPass {
+	return &SeasonPass{kind: PassMonthly, validFrom: start, validUntil: start.AddDate(0, 1, 0)}
+}
+
+// NewAnnualPass 创建从 start 起一年内有效的年卡
+func NewAnnualPass(start time.Time) *SeasonPass {
+	return &SeasonPass{kind: PassAnnual, validFrom: start, validUntil: start.AddDate(1, 0, 0)}
+}
+
+// Name 返回季票类型名称
+func (p *SeasonPass) Name() string {
+	return p.kind.String()
+}
+
+// Kind 返回季票类型
+func (p *SeasonPass) Kind() PassKind {
+	return p.kind
+}
+
+// ValidUntil 返回失效时间
+func (p *SeasonPass) ValidUntil() time.Time {
+	return p.validUntil
+}
+
+// Valid 检查季票在指定时间是否有效
+func (p *SeasonPass) Valid(at time.Time) bool {
+	return !at.Before(p.validFrom) && at.Before(p.validUntil)
+}
+
+// Admit 有效期内年卡免票、月卡半价，过期后按原价支付
+func (p *SeasonPass) Admit(_ Scenery, price int, at time.Time) (int, bool) {
+	if !p.Valid(at) {
+		return price, false
+	}
+	if p.kind == PassAnnual {
+		return 0, true
+	}
+	return price / 2, true
+}
+
+// PunchCard 次卡 - 每进入一个景点消耗一次，次数用完后按原价支付
+// 次卡可以被多位访问者共用（例如家庭卡），因此是并发安全的
+type PunchCard struct {
+	mu        sync.Mutex
+	total     int
+	remaining int
+}
+
+// NewPunchCard 创建可以使用 punches 次的次卡
+func NewPunchCard(punches int) *PunchCard {
+	if punches < 0 {
+		punches = 0
+	}
+	return &PunchCard{total: punches, remaining: punches}
+}
+
+// Name 返回会员类型名称
+func (c *PunchCard) Name() string {
+	return "次卡"
+}
+
+// Remaining 返回剩余次数
+func (c *PunchCard) Remaining() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.remaining
+}
+
+// Used 返回已使用的次数
+func (c *PunchCard) Used() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.total - c.remaining
+}
+
+// Admit 还有剩余次数时消耗一次并免票
+func (c *PunchCard) Admit(_ Scenery, price int, _ time.Time) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.remaining <= 0 {
+		return price, false
+	}
+	c.remaining--
+	return 0, true
+}
+
+// SetMembership 设置访问者持有的会员权益，传入 nil 表示取消
+func (bv *BaseVisitor) SetMembership(m Membership) {
+	bv.membership = m
+}
+
+// Membership 返回访问者持有的会员权益
+func (bv *BaseVisitor) Membership() Membership {
+	return bv.membership
+}
+
+// charge 记录一次景点消费，动物园接待期间由会员权益决定实际票价
+func (bv *BaseVisitor) charge(scenery Scenery, price int) int {
+	if bv.admit != nil {
+		price = bv.admit(scenery, price)
+	}
+	bv.totalExpense += price
+	return price
+}
+
+// member 持有会员权益的访问者，内置的访问者都通过 BaseVisitor 实现了该接口
+type member interface {
+	Membership() Membership
+	setAdmit(admit func(scenery Scenery, price int) int)
+}
+
+// setAdmit 设置接待期间的票价结算函数
+func (bv *BaseVisitor) setAdmit(admit func(scenery Scenery, price int) int) {
+	bv.admit = admit
+}
+
+// Unwrap 返回被包装的访问者，使动物园能检查被包装者的会员权益
+func (r *ReceiptVisitor) Unwrap() Visitor {
+	return r.inner
+}
+
+// findMember 沿包装链查找持有会员权益的访问者
+func findMember(v Visitor) (member, bool) {
+	for v != nil {
+		if m, ok := v.(member); ok {
+			return m, m.Membership() != nil
+		}
+		wrapper, ok := v.(interface{ Unwrap() Visitor })
+		if !ok {
+			break
+		}
+		v = wrapper.Unwrap()
+	}
+	return nil, false
+}
+
+// MembershipUsage 会员权益的使用情况
+type MembershipUsage struct {
+	Admissions int // 持会员进入景点的次数
+	Waived     int // 免票次数
+	Reduced    int // 减价次数
+	Declined   int // 权益未生效（过期或次数用完）的次数
+	Savings    int // 减免的总金额（元）
+}
+
+// Utilization 返回权益生效的比例，没有入场记录时为 0
+func (u MembershipUsage) Utilization() float64 {
+	if u.Admissions == 0 {
+		return 0
+	}
+	return float64(u.Waived+u.Reduced) / float64(u.Admissions)
+}
+
+// add 累加一次入场记录
+func (u *MembershipUsage) add(price, charged int, applied bool) {
+	u.Admissions++
+	switch {
+	case !applied:
+		u.Declined++
+	case charged == 0 && price > 0:
+		u.Waived++
+	default:
+		u.Reduced++
+	}
+	u.Savings += price - charged
+}
+
+// MembershipStats 动物园的会员使用统计
+type MembershipStats struct {
+	MembershipUsage                            // 所有会员类型的合计
+	ByMembership    map[string]MembershipUsage // 按会员类型统计
+}
+
+// String 格式化输出会员统计
+func (s MembershipStats) String() string {
+	return fmt.Sprintf("会员入场 %d 次，权益生效率 %.0f%%，共减免 %d 元",
+		s.Admissions, s.Utilization()*100, s.Savings)
+}
+
+// membershipLedger 动物园记录的会员使用情况，AcceptAll 会并发写入
+type membershipLedger struct {
+	mu     sync.Mutex
+	total  MembershipUsage
+	byName map[string]MembershipUsage
+}
+
+// record 记录一次入场
+func (l *membershipLedger) record(name string, price, charged int, applied bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.byName == nil {
+		l.byName = make(map[string]MembershipUsage)
+	}
+	usage := l.byName[name]
+	usage.add(price, charged, applied)
+	l.byName[name] = usage
+	l.total.add(price, charged, applied)
+}
+
+// MembershipStats 返回会员使用统计的快照
+func (z *Zoo) MembershipStats() MembershipStats {
+	z.members.mu.Lock()
+	defer z.members.mu.Unlock()
+
+	byName := maps.Clone(z.members.byName)
+	if byName == nil {
+		byName = make(map[string]MembershipUsage)
+	}
+	return MembershipStats{MembershipUsage: z.members.total, ByMembership: byName}
+}
+
+// admitMembers 在接待期间让访问者的票价经过会员权益结算，返回恢复函数
+func (z *Zoo) admitMembers(v Visitor) (restore func()) {
+	m, ok := findMember(v)
+	if !ok {
+		return func() {}
+	}
+
+	membership := m.Membership()
+	at := z.clock()
+	saved := 0
+	fmt.Printf("%s 游客持有%s\n", v.GetVisitorType(), membership.Name())
+
+	m.setAdmit(func(scenery Scenery, price int) int {
+		charged, applied := membership.Admit(scenery, price, at)
+		z.members.record(membership.Name(), price, charged, applied)
+		saved += price - charged
+		return charged
+	})
+	return func() {
+		m.setAdmit(nil)
+		fmt.Printf("%s 本次为 %s 游客减免 %d 元\n", membership.Name(), v.GetVisitorType(), saved)
+	}
+}
+
+// clock 返回动物园的当前时间
+func (z *Zoo) clock() time.Time {
+	if z.now != nil {
+		return z.now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,144 @@
+package visitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newMembershipZoo 创建使用固定时钟的动物园，包含三个景点，原价合计 25+45+50=120 元
+func newMembershipZoo(now time.Time) *Zoo {
+	zoo := NewZoo("会员测试动物园")
+	zoo.now = func() time.Time { return now }
+	captureOutput(func() {
+		zoo.Add(NewLeopardSpot())
+		zoo.Add(NewDolphinSpot(true))
+		zoo.Add(NewAquarium(true))
+	})
+	return zoo
+}
+
+// TestSeasonPass 测试月卡和年卡在有效期内减免票价
+func TestSeasonPass(t *testing.T) {
+	assert := assert.New(t)
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	zoo := newMembershipZoo(start.AddDate(0, 0, 10))
+
+	annual := NewCommonVisitor(false)
+	annual.SetMembership(NewAnnualPass(start))
+	monthly := NewCommonVisitor(false)
+	monthly.SetMembership(NewMonthlyPass(start))
+	student := NewStudentVisitor(true)
+	student.SetMembership(NewMonthlyPass(start))
+
+	output := captureOutput(func() {
+		zoo.Accept(annual)
+		zoo.Accept(monthly)
+		zoo.Accept(student)
+	})
+
+	assert.Equal(0, annual.GetTotalExpense(), "年卡应免票")
+	assert.Equal(12+22+25, monthly.GetTotalExpense(), "月卡应半价，不足 1 元的部分舍去")
+	assert.Equal(6+11+12, student.GetTotalExpense(), "月卡在学生价的基础上再半价")
+	assert.Contains(output, "普通 游客持有年卡")
+	assert.Contains(output, "年卡 本次为 普通 游客减免 120 元")
+
+	// 过期后按原价支付
+	expired := newMembershipZoo(start.AddDate(0, 2, 0))
+	late := NewCommonVisitor(false)
+	late.SetMembership(NewMonthlyPass(start))
+	captureOutput(func() { expired.Accept(late) })
+	assert.Equal(120, late.GetTotalExpense())
+	assert.Equal(MembershipUsage{Admissions: 3, Declined: 3}, expired.MembershipStats().MembershipUsage)
+
+	pass := NewAnnualPass(start)
+	assert.Equal(PassAnnual, pass.Kind())
+	assert.True(pass.Valid(start))
+	assert.False(pass.Valid(pass.ValidUntil()))
+}
+
+// TestPunchCard 测试次卡按景点消耗次数，用完后按原价支付
+func TestPunchCard(t *testing.T) {
+	assert := assert.New(t)
+	zoo := newMembershipZoo(time.Now())
+
+	// 两位游客共用一张 4 次的家庭次卡
+	card := NewPunchCard(4)
+	parent := NewCommonVisitor(false)
+	parent.SetMembership(card)
+	child := NewStudentVisitor(true)
+	child.SetMembership(card)
+
+	captureOutput(func() {
+		zoo.Accept(parent)
+		zoo.Accept(child)
+	})
+
+	assert.Equal(0, parent.GetTotalExpense())
+	assert.Equal(22+25, child.GetTotalExpense(), "第 4 次免票，之后按学生价支付")
+	assert.Equal(0, card.Remaining())
+	assert.Equal(4, card.Used())
+
+	stats := zoo.MembershipStats()
+	assert.Equal(MembershipUsage{Admissions: 6, Waived: 4, Declined: 2, Savings: 120 + 12}, stats.ByMembership["次卡"])
+	assert.InDelta(4.0/6.0, stats.Utilization(), 1e-9)
+}
+
+// TestMembershipOnlyAppliesInZoo 测试会员权益只在动物园接待时生效，且不影响其他游客
+func TestMembershipOnlyAppliesInZoo(t *testing.T) {
+	assert := assert.New(t)
+	zoo := newMembershipZoo(time.Now())
+
+	vip := NewVIPVisitor(2)
+	vip.SetMembership(NewAnnualPass(time.Now().Add(-time.Hour)))
+
+	// 直接参观景点不经过动物园，不检查会员
+	captureOutput(func() { NewLeopardSpot().Accept(vip) })
+	assert.Equal(20, vip.GetTotalExpense())
+
+	captureOutput(func() { zoo.Accept(vip) })
+	assert.Equal(20, vip.GetTotalExpense(), "动物园接待时年卡免票")
+
+	// 接待结束后结算函数被清除
+	captureOutput(func() { NewLeopardSpot().Accept(vip) })
+	assert.Equal(40, vip.GetTotalExpense())
+
+	// 没有会员的游客不产生统计
+	captureOutput(func() { zoo.Accept(NewCommonVisitor(false)) })
+	stats := zoo.MembershipStats()
+	assert.Equal(3, stats.Admissions)
+	assert.Equal(3, stats.Waived)
+	assert.Equal(96, stats.Savings)
+	assert.Contains(stats.String(), "权益生效率 100%")
+}
+
+// TestMembershipWithWrappersAndConcurrency 测试小票包装和并发接待时会员权益同样生效
+func TestMembershipWithWrappersAndConcurrency(t *testing.T) {
+	assert := assert.New(t)
+	zoo := newMembershipZoo(time.Now())
+
+	monthly := NewCommonVisitor(false)
+	monthly.SetMembership(NewMonthlyPass(time.Now().Add(-time.Hour)))
+	zh, _ := LookupLocale(LocaleZhCN)
+
+	var receipt Receipt
+	captureOutput(func() { receipt = zoo.Receipt(monthly, zh) })
+	assert.Equal(59, receipt.Total)
+	assert.Equal(12, receipt.Lines[0].Price)
+	assert.Equal(25, receipt.Lines[0].BasePrice)
+
+	card := NewPunchCard(10)
+	visitors := make([]Visitor, 0, 3)
+	for i := 0; i < 3; i++ {
+		v := NewCommonVisitor(false)
+		v.SetMembership(card)
+		visitors = append(visitors, v)
+	}
+	var report ZooReport
+	captureOutput(func() { report = zoo.AcceptAll(visitors) })
+
+	assert.Equal(0, report.TotalRevenue)
+	assert.Equal(1, card.Remaining())
+	assert.Equal(9, zoo.MembershipStats().ByMembership["次卡"].Waived)
+}
@@ -0,0 +1,101 @@
+package visitor
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// VisitReport 单个访问者的参观记录
+type VisitReport struct {
+	VisitorType string // 访问者类型
+	Expense     int    // 本次参观的花费
+	TotalSpent  int    // 参观结束后访问者的累计花费
+	Sceneries   int    // 参观的景点数量
+}
+
+// ZooReport 一批访问者并发参观后的汇总报告
+type ZooReport struct {
+	Visits       []VisitReport  // 与传入访问者顺序一致的参观记录
+	TotalRevenue int            // 本批次总收入
+	ByType       map[string]int // 按访问者类型统计的收入
+	Duration     time.Duration  // 处理耗时
+}
+
+// String 格式化输出汇总报告
+func (r ZooReport) String() string {
+	return fmt.Sprintf("共接待 %d 位游客，总收入 %d 元，耗时 %v",
+		len(r.Visits), r.TotalRevenue, r.Duration)
+}
+
+// AcceptAll 并发接待多个访问者，返回汇总报告
+// 每个访问者实例只会在一个协程中被访问：同一个访问者在列表中出现多次时，
+// 它的多次参观会在同一个协程内按顺序进行，从而保证花费累加不存在数据竞争
+func (z *Zoo) AcceptAll(visitors []Visitor) ZooReport {
+	start := time.Now()
+	visits := make([]VisitReport, len(visitors))
+
+	// 按访问者实例分组，保持每组内的原始顺序
+	groups := make(map[Visitor][]int)
+	order := make([]Visitor, 0, len(visitors))
+	for i, v := range visitors {
+		if _, exists := groups[v]; !exists {
+			order = append(order, v)
+		}
+		groups[v] = append(groups[v], i)
+	}
+
+	var wg sync.WaitGroup
+	for _, v := range order {
+		wg.Add(1)
+		go func(v Visitor, indexes []int) {
+			defer wg.Done()
+			for _, i := range indexes {
+				visits[i] = z.visit(v)
+			}
+		}(v, groups[v])
+	}
+	wg.Wait()
+
+	report := ZooReport{
+		Visits: visits,
+		ByType: make(map[string]int),
+	}
+	for _, visit := range visits {
+		report.TotalRevenue += visit.Expense
+		report.ByType[visit.VisitorType] += visit.Expense
+	}
+	report.Duration = time.Since(start)
+	return report
+}
+
+// AcceptSequential 按顺序接待多个访问者，返回与 AcceptAll 相同格式的报告
+func (z *Zoo) AcceptSequential(visitors []Visitor) ZooReport {
+	start := time.Now()
+	report := ZooReport{
+		Visits: make([]VisitReport, 0, len(visitors)),
+		ByType: make(map[string]int),
+	}
+	for _, v := range visitors {
+		visit := z.visit(v)
+		report.Visits = append(report.Visits, visit)
+		report.TotalRevenue += visit.Expense
+		report.ByType[visit.VisitorType] += visit.Expense
+	}
+	report.Duration = time.Since(start)
+	return report
+}
+
+// visit 接待一位访问者并记录本次参观的花费
+func (z *Zoo) visit(v Visitor) VisitReport {
+	before := v.GetTotalExpense()
+	z.Accept(v)
+	after := v.GetTotalExpense()
+
+	return VisitReport{
+		VisitorType: v.GetVisitorType(),
+		Expense:     after - before,
+		TotalSpent:  after,
+		Sceneries:   len(z.Sceneries),
+	}
+}
@@ -0,0 +1,148 @@
+package visitor
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestZoo 创建包含三个景点的测试动物园
+func newTestZoo() *Zoo {
+	var zoo *Zoo
+	captureOutput(func() {
+		zoo = NewZoo("并发动物园")
+		zoo.Add(NewLeopardSpot())
+		zoo.Add(NewDolphinSpot(true))
+		zoo.Add(NewAquarium(true))
+	})
+	return zoo
+}
+
+// TestZooAcceptAll 测试并发接待多个访问者
+func TestZooAcceptAll(t *testing.T) {
+	assert := assert.New(t)
+	zoo := newTestZoo()
+
+	student := NewStudentVisitor(true)
+	common := NewCommonVisitor(false)
+	vip := NewVIPVisitor(2)
+
+	var report ZooReport
+	captureOutput(func() {
+		report = zoo.AcceptAll([]Visitor{student, common, vip})
+	})
+
+	// 学生半价: 12+22+25，普通原价: 25+45+50，VIP-2八折: 20+36+40
+	assert.Len(report.Visits, 3)
+	assert.Equal(59, report.Visits[0].Expense, "学生花费错误")
+	assert.Equal(120, report.Visits[1].Expense, "普通游客花费错误")
+	assert.Equal(96, report.Visits[2].Expense, "VIP花费错误")
+	assert.Equal("普通", report.Visits[1].VisitorType, "报告顺序应与传入顺序一致")
+	assert.Equal(275, report.TotalRevenue, "总收入错误")
+	assert.Equal(96, report.ByType["VIP-2"])
+	assert.Equal(3, report.Visits[0].Sceneries)
+
+	// 访问者自身的累计花费也被正确更新
+	assert.Equal(59, student.GetTotalExpense())
+	assert.Equal(120, common.GetTotalExpense())
+	assert.Equal(96, vip.GetTotalExpense())
+	assert.Contains(report.String(), "共接待 3 位游客，总收入 275 元")
+}
+
+// TestZooAcceptAllSameVisitor 测试同一访问者多次出现时不会产生数据竞争
+func TestZooAcceptAllSameVisitor(t *testing.T) {
+	assert := assert.New(t)
+	zoo := newTestZoo()
+
+	student := NewStudentVisitor(true)
+	visitors := make([]Visitor, 0, 50)
+	for i := 0; i < 50; i++ {
+		visitors = append(visitors, student, NewCommonVisitor(i%2 == 0))
+	}
+
+	var report ZooReport
+	captureOutput(func() {
+		report = zoo.AcceptAll(visitors)
+	})
+
+	assert.Equal(50*59, student.GetTotalExpense(), "同一学生参观50次的累计花费错误")
+	for i := 0; i < len(visitors); i += 2 {
+		assert.Equal(59, report.Visits[i].Expense, "每次参观的花费应为59元")
+	}
+	assert.Equal(50*59, report.Visits[len(visitors)-2].TotalSpent, "最后一次参观后的累计花费错误")
+}
+
+// TestZooAcceptAllMatchesSequential 测试并发与顺序处理结果一致
+func TestZooAcceptAllMatchesSequential(t *testing.T) {
+	assert := assert.New(t)
+	zoo := newTestZoo()
+
+	build := func() []Visitor {
+		return []Visitor{NewStudentVisitor(false), NewCommonVisitor(true), NewVIPVisitor(1), NewVIPVisitor(3)}
+	}
+
+	var parallel, sequential ZooReport
+	captureOutput(func() {
+		parallel = zoo.AcceptAll(build())
+		sequential = zoo.AcceptSequential(build())
+	})
+
+	assert.Equal(sequential.TotalRevenue, parallel.TotalRevenue)
+	assert.Equal(sequential.ByType, parallel.ByType)
+	for i := range sequential.Visits {
+		assert.Equal(sequential.Visits[i].Expense, parallel.Visits[i].Expense)
+	}
+
+	empty := zoo.AcceptAll(nil)
+	assert.Empty(empty.Visits)
+	assert.Equal(0, empty.TotalRevenue)
+}
+
+// benchmarkVisitors 创建基准测试使用的访问者
+func benchmarkVisitors(n int) []Visitor {
+	visitors := make([]Visitor, 0, n)
+	for i := 0; i < n; i++ {
+		switch i % 3 {
+		case 0:
+			visitors = append(visitors, NewStudentVisitor(true))
+		case 1:
+			visitors = append(visitors, NewCommonVisitor(false))
+		default:
+			visitors = append(visitors, NewVIPVisitor(2))
+		}
+	}
+	return visitors
+}
+
+// BenchmarkZooAcceptAll 并发接待的基准测试
+func BenchmarkZooAcceptAll(b *testing.B) {
+	zoo := newTestZoo()
+	visitors := benchmarkVisitors(100)
+
+	devNull, _ := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	oldStdout := os.Stdout
+	os.Stdout = devNull
+	defer func() { os.Stdout = oldStdout }()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		zoo.AcceptAll(visitors)
+	}
+}
+
+// BenchmarkZooAcceptSequential 顺序接待的基准测试
+func BenchmarkZooAcceptSequential(b *testing.B) {
+	zoo := newTestZoo()
+	visitors := benchmarkVisitors(100)
+
+	devNull, _ := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	oldStdout := os.Stdout
+	os.Stdout = devNull
+	defer func() { os.Stdout = oldStdout }()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		zoo.AcceptSequential(visitors)
+	}
+}
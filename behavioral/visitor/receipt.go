@@ -0,0 +1,140 @@
+package visitor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ReceiptLine 小票上的一行，对应一个景点
+type ReceiptLine struct {
+	Scenery     string // 本地化的景点名称
+	Description string // 本地化的景点描述
+	Price       int    // 实际支付金额（元）
+	BasePrice   int    // 原价（元）
+}
+
+// Receipt 本地化的参观小票
+type Receipt struct {
+	Locale      Locale        // 小票使用的语言区域
+	VisitorType string        // 本地化的访问者类型
+	Lines       []ReceiptLine // 按参观顺序排列的明细
+	Total       int           // 合计金额（元）
+}
+
+// String 按语言区域渲染小票
+func (r Receipt) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "=== %s ===\n", r.Locale.T(MsgReceiptTitle))
+	fmt.Fprintln(&b, r.Locale.T(MsgReceiptVisitor, r.VisitorType))
+	for _, line := range r.Lines {
+		fmt.Fprintf(&b, "%s: %s", line.Scenery, r.Locale.FormatMoney(line.Price))
+		if line.Price != line.BasePrice {
+			fmt.Fprintf(&b, " (%s)", r.Locale.T(MsgReceiptOriginal, r.Locale.FormatMoney(line.BasePrice)))
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "%s: %s\n", r.Locale.T(MsgReceiptTotal), r.Locale.FormatMoney(r.Total))
+	return b.String()
+}
+
+// ReceiptVisitor 本地化小票访问者 - 包装另一个访问者，由被包装者计算价格，
+// 自己负责把每个景点的名称、描述和金额按语言区域记录到小票上
+type ReceiptVisitor struct {
+	inner   Visitor
+	locale  Locale
+	receipt Receipt
+}
+
+// NewReceiptVisitor 创建本地化小票访问者
+func NewReceiptVisitor(inner Visitor, locale Locale) *ReceiptVisitor {
+	return &ReceiptVisitor{
+		inner:  inner,
+		locale: locale,
+		receipt: Receipt{
+			Locale:      locale,
+			VisitorType: localizedVisitorType(inner, locale),
+		},
+	}
+}
+
+// VisitLeopardSpot 记录豹子馆的本地化明细
+func (r *ReceiptVisitor) VisitLeopardSpot(leopard *LeopardSpot) {
+	price := r.charge(func() { r.inner.VisitLeopardSpot(leopard) })
+	r.addLine(MsgLeopardName, MsgLeopardDescription, price, leopard.Price())
+}
+
+// VisitDolphinSpot 记录海豚馆的本地化明细
+func (r *ReceiptVisitor) VisitDolphinSpot(dolphin *DolphinSpot) {
+	price := r.charge(func() { r.inner.VisitDolphinSpot(dolphin) })
+	name := MsgDolphinName
+	if dolphin.HasShow() {
+		name = MsgDolphinShowName
+	}
+	r.addLine(name, MsgDolphinDescription, price, dolphin.Price())
+}
+
+// VisitAquarium 记录水族馆的本地化明细
+func (r *ReceiptVisitor) VisitAquarium(aquarium *Aquarium) {
+	price := r.charge(func() { r.inner.VisitAquarium(aquarium) })
+	name := MsgAquariumName
+	if aquarium.HasVipArea() {
+		name = MsgAquariumVIPName
+	}
+	r.addLine(name, MsgAquariumDesc, price, aquarium.Price())
+}
+
+// GetTotalExpense 返回被包装访问者的总花费
+func (r *ReceiptVisitor) GetTotalExpense() int {
+	return r.inner.GetTotalExpense()
+}
+
+// GetVisitorType 返回被包装访问者的类型，保持与其他报告一致
+func (r *ReceiptVisitor) GetVisitorType() string {
+	return r.inner.GetVisitorType()
+}
+
+// Receipt 返回当前的小票
+func (r *ReceiptVisitor) Receipt() Receipt {
+	receipt := r.receipt
+	receipt.Lines = append([]ReceiptLine(nil), r.receipt.Lines...)
+	return receipt
+}
+
+// charge 执行被包装访问者的访问，返回本次产生的花费
+func (r *ReceiptVisitor) charge(visit func()) int {
+	before := r.inner.GetTotalExpense()
+	visit()
+	return r.inner.GetTotalExpense() - before
+}
+
+// addLine 追加一行本地化明细
+func (r *ReceiptVisitor) addLine(nameKey, descriptionKey string, price, basePrice int) {
+	r.receipt.Lines = append(r.receipt.Lines, ReceiptLine{
+		Scenery:     r.locale.T(nameKey),
+		Description: r.locale.T(descriptionKey),
+		Price:       price,
+		BasePrice:   basePrice,
+	})
+	r.receipt.Total += price
+}
+
+// localizedVisitorType 返回访问者类型的本地化名称，未知类型使用其原始名称
+func localizedVisitorType(v Visitor, locale Locale) string {
+	switch visitor := v.(type) {
+	case *StudentVisitor:
+		return locale.T(MsgVisitorStudent)
+	case *CommonVisitor:
+		return locale.T(MsgVisitorCommon)
+	case *VIPVisitor:
+		return locale.T(MsgVisitorVIP, visitor.vipLevel)
+	default:
+		return v.GetVisitorType()
+	}
+}
+
+// Receipt 接待一位访问者并生成指定语言区域的小票
+func (z *Zoo) Receipt(v Visitor, locale Locale) Receipt {
+	rv := NewReceiptVisitor(v, locale)
+	z.Accept(rv)
+	return rv.Receipt()
+}
@@ -0,0 +1,276 @@
+package visitor
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 预约相关错误
+var (
+	ErrSoldOut         = errors.New("时段已约满")
+	ErrNoReservation   = errors.New("没有预约")
+	ErrUnknownScenery  = errors.New("动物园没有该景点")
+	ErrInvalidSlotSize = errors.New("时段长度必须为正数")
+)
+
+// defaultSlotDuration 默认的预约时段长度
+const defaultSlotDuration = time.Hour
+
+// Reservation 一次景点预约
+type Reservation struct {
+	Visitor Visitor   // 预约的访问者
+	Scenery string    // 景点名称
+	Slot    time.Time // 时段的开始时间
+}
+
+// SlotOccupancy 某个景点在一个时段内的占用情况
+type SlotOccupancy struct {
+	Scenery  string    // 景点名称
+	Slot     time.Time // 时段的开始时间
+	Capacity int       // 时段容量，0 表示不限
+	Reserved int       // 已预约的人数（包括已入场的）
+	Admitted int       // 已入场的人数
+	Rejected int       // 因约满或没有预约被拒绝的次数
+}
+
+// Available 返回时段剩余的名额，不限容量时返回 -1
+func (o SlotOccupancy) Available() int {
+	if o.Capacity == 0 {
+		return -1
+	}
+	return o.Capacity - o.Reserved
+}
+
+// String 格式化输出占用情况
+func (o SlotOccupancy) String() string {
+	capacity := "不限"
+	if o.Capacity > 0 {
+		capacity = fmt.Sprintf("%d", o.Capacity)
+	}
+	return fmt.Sprintf("%s %s 容量 %s，已预约 %d，已入场 %d，拒绝 %d",
+		o.Slot.Format("2006-01-02 15:04"), o.Scenery, capacity, o.Reserved, o.Admitted, o.Rejected)
+}
+
+// OccupancyReport 按时段和景点排序的占用报告
+type OccupancyReport []SlotOccupancy
+
+// String 每行输出一个时段的占用情况
+func (r OccupancyReport) String() string {
+	var sb strings.Builder
+	for _, o := range r {
+		sb.WriteString(o.String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// slotKey 景点和时段
+type slotKey struct {
+	scenery string
+	slot    int64 // 时段开始时间的 Unix 纳秒
+}
+
+// slotState 一个时段的预约状态
+type slotState struct {
+	occupancy SlotOccupancy
+	holders   map[Visitor]int // 访问者持有的未使用预约数
+}
+
+// reservationBook 动物园的预约簿，AcceptAll 会并发访问
+type reservationBook struct {
+	mu           sync.Mutex
+	capacities   map[string]int
+	slots        map[slotKey]*slotState
+	slotDuration time.Duration
+	manual       bool // 为 true 时不自动预约，没有预约的访问者会被拒绝
+}
+
+// SetCapacity 设置景点每个时段的容量，capacity <= 0 表示不限
+// 只有设置了容量的景点需要预约
+func (z *Zoo) SetCapacity(scenery string, capacity int) {
+	z.reservations.mu.Lock()
+	defer z.reservations.mu.Unlock()
+
+	if z.reservations.capacities == nil {
+		z.reservations.capacities = make(map[string]int)
+	}
+	if capacity <= 0 {
+		delete(z.reservations.capacities, scenery)
+		return
+	}
+	z.reservations.capacities[scenery] = capacity
+}
+
+// SetSlotDuration 设置预约时段的长度，默认为一小时；应在接受预约之前设置
+func (z *Zoo) SetSlotDuration(d time.Duration) error {
+	if d <= 0 {
+		return ErrInvalidSlotSize
+	}
+	z.reservations.mu.Lock()
+	defer z.reservations.mu.Unlock()
+	z.reservations.slotDuration = d
+	return nil
+}
+
+// SetAutoReserve 设置接待时是否自动为没有预约的访问者预约当前时段，默认开启
+// 关闭后，没有预约的访问者无法进入限流景点
+func (z *Zoo) SetAutoReserve(enabled bool) {
+	z.reservations.mu.Lock()
+	defer z.reservations.mu.Unlock()
+	z.reservations.manual = !enabled
+}
+
+// Reserve 为访问者预约景点在 at 所在时段的名额，时段约满时返回 ErrSoldOut
+// 包装访问者（如 ReceiptVisitor）与被包装者共用预约
+func (z *Zoo) Reserve(v Visitor, scenery string, at time.Time) (Reservation, error) {
+	if !z.hasScenery(scenery) {
+		return Reservation{}, fmt.Errorf("%w: %s", ErrUnknownScenery, scenery)
+	}
+
+	z.reservations.mu.Lock()
+	defer z.reservations.mu.Unlock()
+
+	state := z.reservations.slotUnsafe(scenery, at)
+	if err := z.reservations.reserveUnsafe(state, visitorIdentity(v)); err != nil {
+		return Reservation{}, err
+	}
+	return Reservation{Visitor: v, Scenery: scenery, Slot: state.occupancy.Slot}, nil
+}
+
+// Cancel 取消一个未使用的预约，释放名额
+func (z *Zoo) Cancel(r Reservation) error {
+	z.reservations.mu.Lock()
+	defer z.reservations.mu.Unlock()
+
+	state, ok := z.reservations.slots[slotKey{r.Scenery, r.Slot.UnixNano()}]
+	identity := visitorIdentity(r.Visitor)
+	if !ok || state.holders[identity] == 0 {
+		return fmt.Errorf("%w: %s %s", ErrNoReservation, r.Scenery, r.Slot.Format("15:04"))
+	}
+	state.holders[identity]--
+	state.occupancy.Reserved--
+	return nil
+}
+
+// Occupancy 返回景点在 at 所在时段的占用情况
+func (z *Zoo) Occupancy(scenery string, at time.Time) SlotOccupancy {
+	z.reservations.mu.Lock()
+	defer z.reservations.mu.Unlock()
+
+	slot := z.reservations.slotStart(at)
+	if state, ok := z.reservations.slots[slotKey{scenery, slot.UnixNano()}]; ok {
+		return state.occupancy
+	}
+	return SlotOccupancy{Scenery: scenery, Slot: slot, Capacity: z.reservations.capacities[scenery]}
+}
+
+// OccupancyReport 返回所有有预约记录的时段的占用情况，按时段和景点名称排序
+func (z *Zoo) OccupancyReport() OccupancyReport {
+	z.reservations.mu.Lock()
+	defer z.reservations.mu.Unlock()
+
+	report := make(OccupancyReport, 0, len(z.reservations.slots))
+	for _, state := range z.reservations.slots {
+		report = append(report, state.occupancy)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if !report[i].Slot.Equal(report[j].Slot) {
+			return report[i].Slot.Before(report[j].Slot)
+		}
+		return report[i].Scenery < report[j].Scenery
+	})
+	return report
+}
+
+// admit 接待访问者进入景点前检查预约，不限流的景点直接放行
+// 访问者没有预约时按设置自动预约当前时段，使用一个预约名额
+func (z *Zoo) admit(v Visitor, scenery Scenery, at time.Time) error {
+	z.reservations.mu.Lock()
+	defer z.reservations.mu.Unlock()
+
+	name := scenery.GetName()
+	if _, limited := z.reservations.capacities[name]; !limited {
+		return nil
+	}
+
+	state := z.reservations.slotUnsafe(name, at)
+	identity := visitorIdentity(v)
+	if state.holders[identity] == 0 {
+		if z.reservations.manual {
+			state.occupancy.Rejected++
+			return fmt.Errorf("%w: %s %s", ErrNoReservation, name, state.occupancy.Slot.Format("15:04"))
+		}
+		if err := z.reservations.reserveUnsafe(state, identity); err != nil {
+			state.occupancy.Rejected++
+			return err
+		}
+	}
+	state.holders[identity]--
+	state.occupancy.Admitted++
+	return nil
+}
+
+// slotStart 返回 at 所在时段的开始时间
+func (b *reservationBook) slotStart(at time.Time) time.Time {
+	d := b.slotDuration
+	if d == 0 {
+		d = defaultSlotDuration
+	}
+	return at.Truncate(d)
+}
+
+// slotUnsafe 返回景点在 at 所在时段的状态，不存在时创建，调用时必须持有锁
+func (b *reservationBook) slotUnsafe(scenery string, at time.Time) *slotState {
+	slot := b.slotStart(at)
+	key := slotKey{scenery, slot.UnixNano()}
+	if b.slots == nil {
+		b.slots = make(map[slotKey]*slotState)
+	}
+	state, ok := b.slots[key]
+	if !ok {
+		state = &slotState{
+			occupancy: SlotOccupancy{Scenery: scenery, Slot: slot},
+			holders:   make(map[Visitor]int),
+		}
+		b.slots[key] = state
+	}
+	// 容量可能在时段创建后被修改
+	state.occupancy.Capacity = b.capacities[scenery]
+	return state
+}
+
+// reserveUnsafe 占用时段的一个名额，调用时必须持有锁
+func (b *reservationBook) reserveUnsafe(state *slotState, identity Visitor) error {
+	o := &state.occupancy
+	if o.Capacity > 0 && o.Reserved >= o.Capacity {
+		return fmt.Errorf("%w: %s %s", ErrSoldOut, o.Scenery, o.Slot.Format("15:04"))
+	}
+	o.Reserved++
+	state.holders[identity]++
+	return nil
+}
+
+// hasScenery 检查动物园是否有指定名称的景点
+func (z *Zoo) hasScenery(name string) bool {
+	for _, scenery := range z.Sceneries {
+		if scenery.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// visitorIdentity 沿包装链找到最内层的访问者，作为预约的持有者
+func visitorIdentity(v Visitor) Visitor {
+	for {
+		wrapper, ok := v.(interface{ Unwrap() Visitor })
+		if !ok {
+			return v
+		}
+		v = wrapper.Unwrap()
+	}
+}
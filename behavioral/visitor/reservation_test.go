@@ -0,0 +1,159 @@
+package visitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newReservationZoo 创建使用固定时钟的动物园，海豚馆每个时段限 2 人
+func newReservationZoo(now time.Time) (*Zoo, *DolphinSpot) {
+	zoo := NewZoo("预约测试动物园")
+	zoo.now = func() time.Time { return now }
+	dolphin := NewDolphinSpot(true)
+	captureOutput(func() {
+		zoo.Add(NewLeopardSpot())
+		zoo.Add(dolphin)
+	})
+	zoo.SetCapacity(dolphin.GetName(), 2)
+	return zoo, dolphin
+}
+
+// TestReserveSoldOut 测试预约约满和取消
+func TestReserveSoldOut(t *testing.T) {
+	assert := assert.New(t)
+	morning := time.Date(2024, 5, 1, 10, 15, 0, 0, time.UTC)
+	zoo, dolphin := newReservationZoo(morning)
+	name := dolphin.GetName()
+
+	first, second, third := NewCommonVisitor(false), NewStudentVisitor(true), NewVIPVisitor(1)
+	r1, err := zoo.Reserve(first, name, morning)
+	assert.NoError(err)
+	assert.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), r1.Slot, "预约按小时分段")
+	_, err = zoo.Reserve(second, name, morning.Add(30*time.Minute))
+	assert.NoError(err, "同一小时内的另一时刻属于同一时段")
+
+	_, err = zoo.Reserve(third, name, morning)
+	assert.ErrorIs(err, ErrSoldOut)
+	_, err = zoo.Reserve(third, name, morning.Add(time.Hour))
+	assert.NoError(err, "下一个时段仍有名额")
+
+	assert.Equal(0, zoo.Occupancy(name, morning).Available())
+	assert.NoError(zoo.Cancel(r1))
+	assert.ErrorIs(zoo.Cancel(r1), ErrNoReservation, "预约只能取消一次")
+	assert.Equal(1, zoo.Occupancy(name, morning).Available())
+
+	_, err = zoo.Reserve(first, "熊猫馆", morning)
+	assert.ErrorIs(err, ErrUnknownScenery)
+
+	// 不限流的景点可以预约，但不限制人数
+	leopard := NewLeopardSpot().GetName()
+	for i := 0; i < 5; i++ {
+		_, err := zoo.Reserve(NewCommonVisitor(false), leopard, morning)
+		assert.NoError(err)
+	}
+	assert.Equal(-1, zoo.Occupancy(leopard, morning).Available())
+}
+
+// TestAcceptWithReservations 测试接待时使用预约和自动预约
+func TestAcceptWithReservations(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2024, 5, 1, 14, 20, 0, 0, time.UTC)
+	zoo, dolphin := newReservationZoo(now)
+	name := dolphin.GetName()
+
+	booked := NewCommonVisitor(false)
+	_, err := zoo.Reserve(booked, name, now)
+	assert.NoError(err)
+	walkIn := NewCommonVisitor(false)
+	late := NewCommonVisitor(false)
+
+	var lateErr error
+	output := captureOutput(func() {
+		assert.NoError(zoo.TryAccept(booked), "使用已有的预约")
+		assert.NoError(zoo.TryAccept(walkIn), "自动预约最后一个名额")
+		lateErr = zoo.TryAccept(late)
+	})
+	assert.ErrorIs(lateErr, ErrSoldOut)
+	assert.Contains(output, "普通 游客无法参观 海豚馆(含表演)")
+
+	assert.Equal(25+45, booked.GetTotalExpense())
+	assert.Equal(25+45, walkIn.GetTotalExpense())
+	assert.Equal(25, late.GetTotalExpense(), "被拒绝的景点不收费，其余景点照常参观")
+
+	assert.Equal(SlotOccupancy{
+		Scenery:  name,
+		Slot:     time.Date(2024, 5, 1, 14, 0, 0, 0, time.UTC),
+		Capacity: 2,
+		Reserved: 2,
+		Admitted: 2,
+		Rejected: 1,
+	}, zoo.Occupancy(name, now))
+
+	// 预约用完后再次参观需要新的名额
+	captureOutput(func() {
+		assert.ErrorIs(zoo.TryAccept(booked), ErrSoldOut)
+	})
+}
+
+// TestManualReservation 测试关闭自动预约后必须先预约
+func TestManualReservation(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	zoo, dolphin := newReservationZoo(now)
+	zoo.SetAutoReserve(false)
+	assert.ErrorIs(zoo.SetSlotDuration(0), ErrInvalidSlotSize)
+	assert.NoError(zoo.SetSlotDuration(30 * time.Minute))
+
+	visitor := NewStudentVisitor(true)
+	zh, _ := LookupLocale(LocaleZhCN)
+	receipt := NewReceiptVisitor(visitor, zh)
+	captureOutput(func() {
+		assert.ErrorIs(zoo.TryAccept(receipt), ErrNoReservation)
+	})
+
+	// 为被包装的访问者预约，包装访问者同样可以使用
+	r, err := zoo.Reserve(visitor, dolphin.GetName(), now.Add(10*time.Minute))
+	assert.NoError(err)
+	assert.Equal(now, r.Slot)
+	captureOutput(func() {
+		assert.NoError(zoo.TryAccept(receipt))
+	})
+	assert.Len(receipt.Receipt().Lines, 2+1)
+}
+
+// TestOccupancyReport 测试占用报告以及并发接待
+func TestOccupancyReport(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC)
+	zoo, dolphin := newReservationZoo(now)
+	zoo.SetCapacity(dolphin.GetName(), 10)
+
+	_, err := zoo.Reserve(NewCommonVisitor(false), dolphin.GetName(), now.Add(2*time.Hour))
+	assert.NoError(err)
+
+	visitors := make([]Visitor, 15)
+	for i := range visitors {
+		visitors[i] = NewCommonVisitor(false)
+	}
+	var report ZooReport
+	captureOutput(func() { report = zoo.AcceptAll(visitors) })
+	assert.Equal(15*25+10*45, report.TotalRevenue, "约满后的游客只参观猎豹馆")
+
+	occupancy := zoo.OccupancyReport()
+	if assert.Len(occupancy, 2) {
+		assert.Equal(now, occupancy[0].Slot, "按时段排序")
+		assert.Equal(10, occupancy[0].Admitted)
+		assert.Equal(5, occupancy[0].Rejected)
+		assert.Equal(1, occupancy[1].Reserved)
+		assert.Equal(0, occupancy[1].Admitted)
+	}
+	assert.Contains(occupancy.String(), "2024-05-01 11:00 海豚馆(含表演) 容量 10，已预约 10，已入场 10，拒绝 5")
+
+	// 取消限流后不再检查预约
+	zoo.SetCapacity(dolphin.GetName(), 0)
+	late := NewCommonVisitor(false)
+	captureOutput(func() { assert.NoError(zoo.TryAccept(late)) })
+	assert.Equal(70, late.GetTotalExpense())
+}
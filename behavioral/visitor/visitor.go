@@ -1,6 +1,7 @@
 package visitor
 
 import (
+	"errors"
 	"fmt"
 	"time"
 )
@@ -27,6 +28,10 @@ type Zoo struct {
 	Name      string     // 动物园名称
 	Sceneries []Scenery  // 动物园包含的景点
 	OpenTime  *time.Time // 开放时间
+
+	now          func() time.Time // 时钟，用于检查会员有效期
+	members      membershipLedger // 会员使用统计
+	reservations reservationBook  // 景点容量和时段预约
 }
 
 // NewZoo 创建一个新的动物园
@@ -36,6 +41,7 @@ func NewZoo(name string) *Zoo {
 		Name:      name,
 		Sceneries: make([]Scenery, 0),
 		OpenTime:  &now,
+		now:       time.Now,
 	}
 }
 
@@ -46,12 +52,29 @@ func (z *Zoo) Add(scenery Scenery) {
 }
 
 // Accept 动物园接待游客，游客将参观所有景点
+// 游客持有会员权益时，各景点的票价由会员权益结算；限流景点约满时跳过该景点
 func (z *Zoo) Accept(v Visitor) {
+	z.TryAccept(v)
+}
+
+// TryAccept 与 Accept 相同，返回因约满或没有预约而无法参观的景点的错误
+// 被拒绝的景点不收费，其余景点照常参观
+func (z *Zoo) TryAccept(v Visitor) error {
 	fmt.Printf("\n%s 欢迎 %s 游客参观！\n", z.Name, v.GetVisitorType())
+	restore := z.admitMembers(v)
+	at := z.clock()
+	var errs []error
 	for _, scenery := range z.Sceneries {
+		if err := z.admit(v, scenery, at); err != nil {
+			fmt.Printf("%s 游客无法参观 %s: %v\n", v.GetVisitorType(), scenery.GetName(), err)
+			errs = append(errs, err)
+			continue
+		}
 		scenery.Accept(v)
 	}
+	restore()
 	fmt.Printf("%s 游客参观完成，总花费: %d 元\n", v.GetVisitorType(), v.GetTotalExpense())
+	return errors.Join(errs...)
 }
 
 // LeopardSpot 豹子馆实现
@@ -190,6 +213,9 @@ func (a *Aquarium) HasVipArea() bool {
 type BaseVisitor struct {
 	totalExpense int    // 总花费
 	visitorType  string // 访问者类型
+
+	membership Membership                           // 持有的会员权益
+	admit      func(scenery Scenery, price int) int // 动物园接待期间的票价结算
 }
 
 // GetTotalExpense 获取总花费
@@ -229,16 +255,14 @@ func (s *StudentVisitor) calculateDiscount(originalPrice int) int {
 
 // VisitLeopardSpot 学生访问豹子馆
 func (s *StudentVisitor) VisitLeopardSpot(leopard *LeopardSpot) {
-	price := s.calculateDiscount(leopard.Price())
-	s.totalExpense += price
+	price := s.charge(leopard, s.calculateDiscount(leopard.Price()))
 	fmt.Printf("学生游客参观%s，详情: %s，票价: %d元 (原价: %d元)\n",
 		leopard.GetName(), leopard.GetDescription(), price, leopard.Price())
 }
 
 // VisitDolphinSpot 学生访问海豚馆
 func (s *StudentVisitor) VisitDolphinSpot(dolphin *DolphinSpot) {
-	price := s.calculateDiscount(dolphin.Price())
-	s.totalExpense += price
+	price := s.charge(dolphin, s.calculateDiscount(dolphin.Price()))
 	showInfo := ""
 	if dolphin.HasShow() {
 		showInfo = "，今日有精彩表演"
@@ -249,8 +273,7 @@ func (s *StudentVisitor) VisitDolphinSpot(dolphin *DolphinSpot) {
 
 // VisitAquarium 学生访问水族馆
 func (s *StudentVisitor) VisitAquarium(aquarium *Aquarium) {
-	price := s.calculateDiscount(aquarium.Price())
-	s.totalExpense += price
+	price := s.charge(aquarium, s.calculateDiscount(aquarium.Price()))
 	vipInfo := ""
 	if aquarium.HasVipArea() {
 		vipInfo = "，包含VIP珍稀鱼类区域"
@@ -286,16 +309,14 @@ func (c *CommonVisitor) calculatePrice(originalPrice int) int {
 
 // VisitLeopardSpot 普通游客访问豹子馆
 func (c *CommonVisitor) VisitLeopardSpot(leopard *LeopardSpot) {
-	price := c.calculatePrice(leopard.Price())
-	c.totalExpense += price
+	price := c.charge(leopard, c.calculatePrice(leopard.Price()))
 	fmt.Printf("普通游客参观%s，详情: %s，票价: %d元\n",
 		leopard.GetName(), leopard.GetDescription(), price)
 }
 
 // VisitDolphinSpot 普通游客访问海豚馆
 func (c *CommonVisitor) VisitDolphinSpot(dolphin *DolphinSpot) {
-	price := c.calculatePrice(dolphin.Price())
-	c.totalExpense += price
+	price := c.charge(dolphin, c.calculatePrice(dolphin.Price()))
 	showInfo := ""
 	if dolphin.HasShow() {
 		showInfo = "，今日有精彩表演"
@@ -306,8 +327,7 @@ func (c *CommonVisitor) VisitDolphinSpot(dolphin *DolphinSpot) {
 
 // VisitAquarium 普通游客访问水族馆
 func (c *CommonVisitor) VisitAquarium(aquarium *Aquarium) {
-	price := c.calculatePrice(aquarium.Price())
-	c.totalExpense += price
+	price := c.charge(aquarium, c.calculatePrice(aquarium.Price()))
 	vipInfo := ""
 	if aquarium.HasVipArea() {
 		vipInfo = "，包含VIP珍稀鱼类区域"
@@ -354,16 +374,14 @@ func (v *VIPVisitor) calculateDiscount(originalPrice int) int {
 
 // VisitLeopardSpot VIP游客访问豹子馆
 func (v *VIPVisitor) VisitLeopardSpot(leopard *LeopardSpot) {
-	price := v.calculateDiscount(leopard.Price())
-	v.totalExpense += price
+	price := v.charge(leopard, v.calculateDiscount(leopard.Price()))
 	fmt.Printf("VIP-%d游客参观%s，详情: %s，享受专属讲解，票价: %d元 (原价: %d元)\n",
 		v.vipLevel, leopard.GetName(), leopard.GetDescription(), price, leopard.Price())
 }
 
 // VisitDolphinSpot VIP游客访问海豚馆
 func (v *VIPVisitor) VisitDolphinSpot(dolphin *DolphinSpot) {
-	price := v.calculateDiscount(dolphin.Price())
-	v.totalExpense += price
+	price := v.charge(dolphin, v.calculateDiscount(dolphin.Price()))
 	showInfo := ""
 	if dolphin.HasShow() {
 		showInfo = "，安排前排观看表演"
@@ -374,8 +392,7 @@ func (v *VIPVisitor) VisitDolphinSpot(dolphin *DolphinSpot) {
 
 // VisitAquarium VIP游客访问水族馆
 func (v *VIPVisitor) VisitAquarium(aquarium *Aquarium) {
-	price := v.calculateDiscount(aquarium.Price())
-	v.totalExpense += price
+	price := v.charge(aquarium, v.calculateDiscount(aquarium.Price()))
 	vipInfo := ""
 	if aquarium.HasVipArea() {
 		vipInfo = "，专享VIP区域导览"
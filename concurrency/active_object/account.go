@@ -0,0 +1,109 @@
+package active_object
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidAmount 金额必须为正数
+var ErrInvalidAmount = errors.New("金额必须大于0")
+
+// account 被代理的银行账户（Servant），只在调度协程中访问，因此没有任何锁
+type account struct {
+	owner   string
+	balance int
+	history []string
+}
+
+// AccountProxy 账户代理：对外提供与账户相同的方法，
+// 每次调用都被转换为方法请求交给调度器，调用者立即得到 Future
+type AccountProxy struct {
+	servant   *account
+	scheduler *Scheduler
+}
+
+// NewAccountProxy 创建账户及其代理，调度器选项用于配置限流
+func NewAccountProxy(owner string, opts ...Option) *AccountProxy {
+	return &AccountProxy{
+		servant:   &account{owner: owner},
+		scheduler: NewScheduler(opts...),
+	}
+}
+
+// Deposit 存款，返回存款后的余额
+func (p *AccountProxy) Deposit(amount int) *Future[int] {
+	return Invoke(p.scheduler, func() (int, error) {
+		if amount <= 0 {
+			return 0, ErrInvalidAmount
+		}
+		p.servant.balance += amount
+		p.servant.history = append(p.servant.history, fmt.Sprintf("存入 %d", amount))
+		return p.servant.balance, nil
+	})
+}
+
+// Withdraw 取款，余额不足时请求在队列中等待后续存款，返回取款后的余额
+// 关闭时余额仍然不足的取款以 ErrGuardNeverMet 失败
+func (p *AccountProxy) Withdraw(amount int) *Future[int] {
+	return Invoke(p.scheduler, func() (int, error) {
+		if amount <= 0 {
+			return 0, ErrInvalidAmount
+		}
+		p.servant.balance -= amount
+		p.servant.history = append(p.servant.history, fmt.Sprintf("取出 %d", amount))
+		return p.servant.balance, nil
+	}, WithGuard(func() bool { return amount <= 0 || p.servant.balance >= amount }))
+}
+
+// Balance 查询余额，以高优先级执行，排在已经提交的存取款之前
+func (p *AccountProxy) Balance() *Future[int] {
+	return Invoke(p.scheduler, func() (int, error) {
+		return p.servant.balance, nil
+	}, WithPriority(PriorityHigh))
+}
+
+// History 查询交易记录，以低优先级执行，不影响存取款
+func (p *AccountProxy) History() *Future[[]string] {
+	return Invoke(p.scheduler, func() ([]string, error) {
+		return append([]string(nil), p.servant.history...), nil
+	}, WithPriority(PriorityLow))
+}
+
+// Stats 返回调度器的统计信息
+func (p *AccountProxy) Stats() SchedulerStats {
+	return p.scheduler.Stats()
+}
+
+// Shutdown 优雅关闭代理，等待已提交的请求执行完毕
+func (p *AccountProxy) Shutdown(ctx context.Context) error {
+	return p.scheduler.Shutdown(ctx)
+}
+
+// RunExample 运行主动对象模式示例
+func RunExample() {
+	proxy := NewAccountProxy("张三", WithThrottle(10*time.Millisecond))
+
+	// 余额不足的取款先提交，等存款到账后才执行
+	withdraw := proxy.Withdraw(80)
+	deposits := []*Future[int]{proxy.Deposit(50), proxy.Deposit(50)}
+	history := proxy.History()
+
+	for i, future := range deposits {
+		balance, _ := future.Get()
+		fmt.Printf("第 %d 笔存款后余额: %d\n", i+1, balance)
+	}
+	balance, _ := withdraw.Get()
+	fmt.Printf("取款后余额: %d\n", balance)
+
+	records, _ := history.Get()
+	fmt.Printf("交易记录: %v\n", records)
+
+	if err := proxy.Shutdown(context.Background()); err != nil {
+		fmt.Printf("关闭失败: %v\n", err)
+	}
+	if _, err := proxy.Deposit(10).Get(); err != nil {
+		fmt.Printf("关闭后存款失败: %v\n", err)
+	}
+}
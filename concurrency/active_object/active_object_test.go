@@ -0,0 +1,223 @@
+package active_object
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blockScheduler 提交一个阻塞调度协程的请求，关闭返回的通道后放行
+func blockScheduler(t *testing.T, s *Scheduler) (chan struct{}, *Future[int]) {
+	t.Helper()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	future := Invoke(s, func() (int, error) {
+		close(started)
+		<-release
+		return 0, nil
+	})
+	<-started
+	return release, future
+}
+
+// TestAccountProxy 测试代理的方法调用在调度协程中依次执行
+func TestAccountProxy(t *testing.T) {
+	proxy := NewAccountProxy("张三")
+	defer proxy.Shutdown(context.Background())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := proxy.Deposit(10).Get()
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	balance, err := proxy.Balance().Get()
+	assert.NoError(t, err)
+	assert.Equal(t, 1000, balance, "并发存款不丢失更新")
+
+	_, err = proxy.Deposit(-1).Get()
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+}
+
+// TestGuardedWithdraw 测试执行条件不满足的请求等待后续请求改变状态
+func TestGuardedWithdraw(t *testing.T) {
+	proxy := NewAccountProxy("李四")
+
+	withdraw := proxy.Withdraw(80)
+	first, err := proxy.Deposit(50).Get()
+	assert.NoError(t, err)
+	assert.Equal(t, 50, first)
+	assert.False(t, withdraw.Ready(), "余额不足时取款保持排队")
+
+	proxy.Deposit(50)
+	balance, err := withdraw.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, 20, balance)
+
+	records, err := proxy.History().Get()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"存入 50", "存入 50", "取出 80"}, records)
+
+	// 关闭时余额仍然不足的取款失败
+	stuck := proxy.Withdraw(1000)
+	assert.NoError(t, proxy.Shutdown(context.Background()))
+	_, err = stuck.Get()
+	assert.ErrorIs(t, err, ErrGuardNeverMet)
+	assert.Equal(t, 1, proxy.Stats().Cancelled)
+}
+
+// TestPriority 测试高优先级请求先执行，同优先级按提交顺序执行
+func TestPriority(t *testing.T) {
+	s := NewScheduler()
+	release, _ := blockScheduler(t, s)
+
+	var mutex sync.Mutex
+	var order []string
+	record := func(name string) func() (string, error) {
+		return func() (string, error) {
+			mutex.Lock()
+			order = append(order, name)
+			mutex.Unlock()
+			return name, nil
+		}
+	}
+
+	Invoke(s, record("low"), WithPriority(PriorityLow))
+	Invoke(s, record("normal-1"))
+	Invoke(s, record("high"), WithPriority(PriorityHigh))
+	Invoke(s, record("normal-2"))
+	assert.Equal(t, 4, s.Stats().Pending)
+
+	close(release)
+	assert.NoError(t, s.Shutdown(context.Background()))
+	assert.Equal(t, []string{"high", "normal-1", "normal-2", "low"}, order)
+	assert.Equal(t, 5, s.Stats().Executed)
+}
+
+// TestThrottle 测试队列长度限制和执行间隔限制
+func TestThrottle(t *testing.T) {
+	s := NewScheduler(WithMaxPending(2))
+	release, _ := blockScheduler(t, s)
+
+	a := Invoke(s, func() (int, error) { return 1, nil })
+	b := Invoke(s, func() (int, error) { return 2, nil })
+	rejected := Invoke(s, func() (int, error) { return 3, nil })
+	assert.True(t, rejected.Ready(), "被拒绝的请求立即完成")
+	_, err := rejected.Get()
+	assert.ErrorIs(t, err, ErrQueueFull)
+
+	close(release)
+	for _, future := range []*Future[int]{a, b} {
+		_, err := future.Get()
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, s.Shutdown(context.Background()))
+	assert.Equal(t, 1, s.Stats().Rejected)
+
+	const interval = 20 * time.Millisecond
+	throttled := NewScheduler(WithThrottle(interval))
+	defer throttled.Stop()
+	// 返回调度器记录的开始时间：限流按这个时间计算，请求里再取 time.Now() 会带上调度的抖动
+	startedAt := func() (time.Time, error) {
+		throttled.mutex.Lock()
+		defer throttled.mutex.Unlock()
+		return throttled.lastRun, nil
+	}
+	var futures []*Future[time.Time]
+	for i := 0; i < 3; i++ {
+		futures = append(futures, Invoke(throttled, startedAt))
+	}
+	var starts []time.Time
+	for _, future := range futures {
+		start, err := future.Get()
+		assert.NoError(t, err)
+		starts = append(starts, start)
+	}
+	for i := 1; i < len(starts); i++ {
+		assert.GreaterOrEqual(t, starts[i].Sub(starts[i-1]), interval, "相邻请求至少间隔 interval")
+	}
+}
+
+// TestGracefulShutdown 测试优雅关闭执行完队列中的请求，并拒绝新请求
+func TestGracefulShutdown(t *testing.T) {
+	s := NewScheduler()
+	release, _ := blockScheduler(t, s)
+	queued := Invoke(s, func() (string, error) { return "done", nil })
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- s.Shutdown(context.Background()) }()
+
+	// 等待调度器进入关闭状态后再提交
+	assert.Eventually(t, func() bool {
+		_, err := Invoke(s, func() (int, error) { return 0, nil }).Get()
+		return errors.Is(err, ErrShutdown)
+	}, time.Second, time.Millisecond)
+
+	close(release)
+	assert.NoError(t, <-shutdown)
+	value, err := queued.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "done", value)
+}
+
+// TestShutdownTimeout 测试关闭超时后强制停止，取消剩余请求
+func TestShutdownTimeout(t *testing.T) {
+	s := NewScheduler()
+	release, running := blockScheduler(t, s)
+	queued := Invoke(s, func() (int, error) { return 1, nil })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	go func() {
+		<-s.stop // 强制停止之后再放行正在执行的请求
+		close(release)
+	}()
+
+	assert.ErrorIs(t, s.Shutdown(ctx), context.DeadlineExceeded)
+	_, err := running.Get()
+	assert.NoError(t, err, "正在执行的请求会执行完")
+	_, err = queued.Get()
+	assert.ErrorIs(t, err, ErrShutdown)
+	assert.Equal(t, 1, s.Stats().Cancelled)
+}
+
+// TestRequestPanic 测试请求中的 panic 通过 Future 返回，调度协程继续工作
+func TestRequestPanic(t *testing.T) {
+	s := NewScheduler()
+	defer s.Stop()
+
+	_, err := Invoke(s, func() (int, error) { panic("boom") }).Get()
+	assert.ErrorIs(t, err, ErrRequestPanic)
+	assert.Contains(t, err.Error(), "boom")
+
+	value, err := Invoke(s, func() (int, error) { return 42, nil }).Get()
+	assert.NoError(t, err)
+	assert.Equal(t, 42, value)
+	assert.Equal(t, 1, s.Stats().Panicked)
+}
+
+// TestFutureWithContext 测试放弃等待 Future
+func TestFutureWithContext(t *testing.T) {
+	s := NewScheduler()
+	release, running := blockScheduler(t, s)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := running.GetWithContext(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+	<-running.Done()
+	_, err = running.GetWithContext(context.Background())
+	assert.NoError(t, err)
+	s.Stop()
+}
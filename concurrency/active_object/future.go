@@ -0,0 +1,61 @@
+package active_object
+
+import (
+	"context"
+	"sync"
+)
+
+// Future 方法请求的异步结果，调用者可以在任意时刻等待或查询结果
+type Future[T any] struct {
+	once  sync.Once
+	done  chan struct{}
+	value T
+	err   error
+}
+
+// newFuture 创建尚未完成的 Future
+func newFuture[T any]() *Future[T] {
+	return &Future[T]{done: make(chan struct{})}
+}
+
+// complete 设置结果并唤醒所有等待者，只有第一次调用生效
+func (f *Future[T]) complete(value T, err error) {
+	f.once.Do(func() {
+		f.value = value
+		f.err = err
+		close(f.done)
+	})
+}
+
+// Done 返回在结果就绪时关闭的通道，可以与 select 配合使用
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Ready 结果是否已经就绪
+func (f *Future[T]) Ready() bool {
+	select {
+	case <-f.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Get 阻塞等待并返回结果
+func (f *Future[T]) Get() (T, error) {
+	<-f.done
+	return f.value, f.err
+}
+
+// GetWithContext 等待结果，上下文先结束时返回上下文的错误
+// 放弃等待不会取消已经排队的方法请求
+func (f *Future[T]) GetWithContext(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
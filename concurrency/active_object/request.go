@@ -0,0 +1,61 @@
+package active_object
+
+// request 通用的方法请求，执行函数的返回值通过 Future 交给调用者
+type request[T any] struct {
+	guard  func() bool
+	call   func() (T, error)
+	future *Future[T]
+}
+
+// Guard 没有设置执行条件时总是可以执行
+func (r *request[T]) Guard() bool {
+	return r.guard == nil || r.guard()
+}
+
+// Call 执行请求并完成 Future
+func (r *request[T]) Call() {
+	r.future.complete(r.call())
+}
+
+// Cancel 用错误完成 Future
+func (r *request[T]) Cancel(err error) {
+	var zero T
+	r.future.complete(zero, err)
+}
+
+// requestConfig 方法请求的配置
+type requestConfig struct {
+	priority Priority
+	guard    func() bool
+}
+
+// RequestOption 方法请求配置选项
+type RequestOption func(*requestConfig)
+
+// WithPriority 设置请求优先级，默认为 PriorityNormal
+func WithPriority(priority Priority) RequestOption {
+	return func(c *requestConfig) {
+		c.priority = priority
+	}
+}
+
+// WithGuard 设置执行条件，条件满足之前请求留在队列中，后提交的请求可以先执行
+// 条件在调度协程中检查，可以直接读取被代理对象的状态
+func WithGuard(guard func() bool) RequestOption {
+	return func(c *requestConfig) {
+		c.guard = guard
+	}
+}
+
+// Invoke 把函数封装成方法请求提交给调度器，立即返回 Future
+// 请求被拒绝时 Future 已经以 ErrQueueFull 或 ErrShutdown 完成
+func Invoke[T any](s *Scheduler, call func() (T, error), opts ...RequestOption) *Future[T] {
+	config := requestConfig{priority: PriorityNormal}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	future := newFuture[T]()
+	s.Submit(&request[T]{guard: config.guard, call: call, future: future}, config.priority)
+	return future
+}
@@ -0,0 +1,301 @@
+package active_object
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 调度器相关错误
+var (
+	ErrQueueFull     = errors.New("激活队列已满")
+	ErrShutdown      = errors.New("调度器已关闭")
+	ErrRequestPanic  = errors.New("方法请求发生 panic")
+	ErrGuardNeverMet = errors.New("关闭时执行条件仍未满足")
+)
+
+// Priority 方法请求的优先级，数值越大越先执行，相同优先级按提交顺序执行
+type Priority int
+
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+// MethodRequest 方法请求：代理把一次方法调用封装成的对象
+type MethodRequest interface {
+	Guard() bool      // 是否满足执行条件，由调度协程调用，不满足时请求留在队列中
+	Call()            // 在调度协程中执行请求，并完成对应的 Future
+	Cancel(err error) // 请求不会再执行时调用，用错误完成对应的 Future
+}
+
+// activation 激活队列中的一项
+type activation struct {
+	request  MethodRequest
+	priority Priority
+}
+
+// schedulerState 调度器的生命周期
+type schedulerState int
+
+const (
+	stateRunning  schedulerState = iota // 接受并执行请求
+	stateDraining                       // 不再接受请求，执行完队列中的请求后退出
+	stateStopped                        // 取消队列中剩余的请求并退出
+)
+
+// Option 调度器配置选项
+type Option func(*Scheduler)
+
+// WithMaxPending 限制排队的请求数，超过时新的请求以 ErrQueueFull 失败，0 表示不限制
+func WithMaxPending(n int) Option {
+	return func(s *Scheduler) {
+		if n > 0 {
+			s.maxPending = n
+		}
+	}
+}
+
+// WithThrottle 限制两个请求开始执行的最小间隔，用于保护下游资源
+func WithThrottle(interval time.Duration) Option {
+	return func(s *Scheduler) {
+		if interval > 0 {
+			s.interval = interval
+		}
+	}
+}
+
+// SchedulerStats 调度器统计信息
+type SchedulerStats struct {
+	Pending   int // 队列中等待执行的请求
+	Executed  int // 已执行的请求
+	Rejected  int // 因队列已满或已关闭而被拒绝的请求
+	Cancelled int // 关闭时被取消的请求
+	Panicked  int // 执行时发生 panic 的请求
+}
+
+// Scheduler 调度器：在专用的调度协程中按优先级依次执行方法请求
+// 所有请求都在同一个协程中执行，因此被代理的对象（Servant）不需要加锁
+type Scheduler struct {
+	mutex      sync.Mutex
+	queue      []activation // 激活队列，按优先级从高到低、提交顺序从早到晚排列
+	state      schedulerState
+	maxPending int
+	interval   time.Duration
+	lastRun    time.Time
+	stats      SchedulerStats
+
+	wake chan struct{} // 有新请求或状态变化时通知调度协程
+	stop chan struct{} // 强制停止时关闭，打断限流等待
+	done chan struct{} // 调度协程退出时关闭
+}
+
+// NewScheduler 创建调度器并启动调度协程
+func NewScheduler(opts ...Option) *Scheduler {
+	s := &Scheduler{
+		wake: make(chan struct{}, 1),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	go s.run()
+	return s
+}
+
+// Submit 把方法请求放入激活队列
+// 队列已满时返回 ErrQueueFull，调度器关闭后返回 ErrShutdown，被拒绝的请求会以同样的错误取消
+func (s *Scheduler) Submit(request MethodRequest, priority Priority) error {
+	s.mutex.Lock()
+	if err := s.admitLocked(); err != nil {
+		s.stats.Rejected++
+		s.mutex.Unlock()
+		request.Cancel(err)
+		return err
+	}
+
+	// 插入到同优先级请求的末尾，保持提交顺序
+	item := activation{request: request, priority: priority}
+	i := sort.Search(len(s.queue), func(i int) bool { return s.queue[i].priority < priority })
+	s.queue = append(s.queue, activation{})
+	copy(s.queue[i+1:], s.queue[i:])
+	s.queue[i] = item
+	s.mutex.Unlock()
+
+	s.signal()
+	return nil
+}
+
+// admitLocked 检查是否可以接受新请求，调用者需持有锁
+func (s *Scheduler) admitLocked() error {
+	if s.state != stateRunning {
+		return ErrShutdown
+	}
+	if s.maxPending > 0 && len(s.queue) >= s.maxPending {
+		return fmt.Errorf("%w: 最多排队 %d 个请求", ErrQueueFull, s.maxPending)
+	}
+	return nil
+}
+
+// signal 唤醒调度协程，通知已经在途时不重复发送
+func (s *Scheduler) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run 调度协程的主循环
+func (s *Scheduler) run() {
+	defer close(s.done)
+	for {
+		s.mutex.Lock()
+		item, ok := s.nextLocked()
+		if !ok {
+			if s.state != stateRunning {
+				// 关闭时队列中剩下的请求要么被强制停止，要么执行条件再也不会满足
+				err := ErrShutdown
+				if s.state == stateDraining {
+					err = ErrGuardNeverMet
+				}
+				s.cancelAllLocked(err)
+				s.mutex.Unlock()
+				return
+			}
+			s.mutex.Unlock()
+			<-s.wake
+			continue
+		}
+		wait := s.throttleLocked()
+		s.mutex.Unlock()
+
+		if wait > 0 && !s.sleep(wait) {
+			s.mutex.Lock()
+			s.cancelLocked(item, ErrShutdown)
+			s.mutex.Unlock()
+			continue
+		}
+		s.execute(item)
+	}
+}
+
+// nextLocked 取出优先级最高且满足执行条件的请求，调用者需持有锁
+// 执行条件不满足的请求保留在队列中，等其他请求改变了对象状态后再检查
+func (s *Scheduler) nextLocked() (activation, bool) {
+	if s.state == stateStopped {
+		return activation{}, false
+	}
+	for i, item := range s.queue {
+		if item.request.Guard() {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			return item, true
+		}
+	}
+	return activation{}, false
+}
+
+// throttleLocked 计算距离下一次允许执行还需等待的时间，调用者需持有锁
+func (s *Scheduler) throttleLocked() time.Duration {
+	if s.interval <= 0 || s.lastRun.IsZero() {
+		return 0
+	}
+	return time.Until(s.lastRun.Add(s.interval))
+}
+
+// sleep 限流等待，被强制停止打断时返回 false
+func (s *Scheduler) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-s.stop:
+		return false
+	}
+}
+
+// execute 执行一个请求，请求中的 panic 会被恢复并通过 Future 返回
+func (s *Scheduler) execute(item activation) {
+	s.mutex.Lock()
+	s.lastRun = time.Now()
+	s.mutex.Unlock()
+
+	panicked := true
+	defer func() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		s.stats.Executed++
+		if r := recover(); panicked {
+			s.stats.Panicked++
+			item.request.Cancel(fmt.Errorf("%w: %v", ErrRequestPanic, r))
+		}
+	}()
+	item.request.Call()
+	panicked = false
+}
+
+// cancelLocked 取消一个请求，调用者需持有锁
+func (s *Scheduler) cancelLocked(item activation, err error) {
+	s.stats.Cancelled++
+	item.request.Cancel(err)
+}
+
+// cancelAllLocked 取消队列中所有请求，调用者需持有锁
+func (s *Scheduler) cancelAllLocked(err error) {
+	for _, item := range s.queue {
+		s.cancelLocked(item, err)
+	}
+	s.queue = nil
+}
+
+// Shutdown 优雅关闭：不再接受新请求，等待队列中的请求执行完毕
+// 上下文先结束时强制停止，正在执行的请求会执行完，剩余请求以 ErrShutdown 取消，并返回上下文的错误
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.mutex.Lock()
+	if s.state == stateRunning {
+		s.state = stateDraining
+	}
+	s.mutex.Unlock()
+	s.signal()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		s.Stop()
+		return ctx.Err()
+	}
+}
+
+// Stop 强制停止：正在执行的请求执行完后退出，队列中的请求以 ErrShutdown 取消
+// 不能在方法请求中调用，否则调度协程会等待自己退出
+func (s *Scheduler) Stop() {
+	s.mutex.Lock()
+	if s.state != stateStopped {
+		s.state = stateStopped
+		close(s.stop)
+	}
+	s.mutex.Unlock()
+	s.signal()
+	<-s.done
+}
+
+// Done 返回调度协程退出时关闭的通道
+func (s *Scheduler) Done() <-chan struct{} {
+	return s.done
+}
+
+// Stats 返回调度器的统计信息
+func (s *Scheduler) Stats() SchedulerStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stats := s.stats
+	stats.Pending = len(s.queue)
+	return stats
+}
@@ -0,0 +1,56 @@
+package async_io
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RunExample 运行前摄器示例：写入若干文件，写完后在完成处理器中接着读取并统计单词数
+func RunExample() {
+	dir, err := os.MkdirTemp("", "async_io")
+	if err != nil {
+		fmt.Println("创建临时目录失败:", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	p := NewProactor(WithWorkers(3), WithOrderedCompletion())
+	ctx := context.Background()
+
+	// 完成处理器都在分发协程中执行，累加统计不需要加锁
+	words := 0
+	documents := map[string]string{
+		"a.txt": "the quick brown fox",
+		"b.txt": "jumps over the lazy dog",
+		"c.txt": "proactor pattern demo",
+	}
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		path := filepath.Join(dir, name)
+		p.Submit(ctx, Write(path, []byte(documents[name])), func(c Completion) {
+			if c.Err != nil {
+				fmt.Printf("#%d 写入 %s 失败: %v\n", c.ID, name, c.Err)
+				return
+			}
+			fmt.Printf("#%d 写入 %s 完成，%d 字节\n", c.ID, name, c.N)
+
+			// 操作链：写完之后提交读操作，调用者不需要等待
+			p.Submit(ctx, Read(path), func(c Completion) {
+				n := len(strings.Fields(string(c.Data)))
+				words += n
+				fmt.Printf("#%d 读取 %s 完成，%d 个单词\n", c.ID, name, n)
+			})
+		})
+	}
+
+	// 取消一个操作：被取消的操作同样会调用完成处理器
+	cancelled, _ := p.Submit(ctx, Read(filepath.Join(dir, "missing.txt")), func(c Completion) {
+		fmt.Printf("#%d 读取 missing.txt: %v\n", c.ID, c.Err)
+	})
+	cancelled.Cancel()
+
+	p.Close(ctx)
+	fmt.Printf("单词总数: %d\n", words)
+}
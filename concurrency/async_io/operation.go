@@ -0,0 +1,125 @@
+package async_io
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// OpKind 操作类型
+type OpKind int
+
+const (
+	OpRead  OpKind = iota // 读取整个文件
+	OpWrite               // 写入整个文件，先写临时文件再重命名，被取消时不留下写了一半的文件
+)
+
+// String 返回操作类型名称
+func (k OpKind) String() string {
+	switch k {
+	case OpRead:
+		return "读"
+	case OpWrite:
+		return "写"
+	default:
+		return fmt.Sprintf("OpKind(%d)", int(k))
+	}
+}
+
+// Operation 一个异步文件操作
+type Operation struct {
+	Kind OpKind
+	Path string
+	Data []byte // 写操作要写入的内容
+}
+
+// Read 创建读取文件的操作
+func Read(path string) Operation {
+	return Operation{Kind: OpRead, Path: path}
+}
+
+// Write 创建写入文件的操作
+func Write(path string, data []byte) Operation {
+	return Operation{Kind: OpWrite, Path: path, Data: data}
+}
+
+// perform 在工作协程中同步执行操作
+func (p *Proactor) perform(ctx context.Context, op Operation) Completion {
+	result := Completion{Op: op}
+	if err := ctx.Err(); err != nil {
+		result.Err = err
+		return result
+	}
+
+	switch op.Kind {
+	case OpRead:
+		result.Data, result.Err = p.readFile(ctx, op.Path)
+		result.N = len(result.Data)
+	case OpWrite:
+		result.N, result.Err = p.writeFile(ctx, op.Path, op.Data)
+	default:
+		result.Err = fmt.Errorf("不支持的操作类型: %v", op.Kind)
+	}
+	return result
+}
+
+// readFile 分块读取文件，每块之间检查是否被取消
+func (p *Proactor) readFile(ctx context.Context, path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var data []byte
+	if info, err := f.Stat(); err == nil {
+		data = make([]byte, 0, info.Size())
+	}
+	chunk := make([]byte, p.chunkSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		n, err := f.Read(chunk)
+		data = append(data, chunk[:n]...)
+		if errors.Is(err, io.EOF) {
+			return data, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// writeFile 分块写入临时文件，全部写完后重命名为目标文件
+func (p *Proactor) writeFile(ctx context.Context, path string, data []byte) (n int, err error) {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(tmp)
+		}
+	}()
+
+	for n < len(data) {
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+		end := min(n+p.chunkSize, len(data))
+		written, err := f.Write(data[n:end])
+		n += written
+		if err != nil {
+			return n, err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return n, err
+	}
+	return n, os.Rename(tmp, path)
+}
@@ -0,0 +1,301 @@
+package async_io
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrClosed 处理器已关闭，不再接受新的操作
+var ErrClosed = errors.New("异步处理器已关闭")
+
+// Completion 一个异步操作的完成结果，交给完成处理器
+type Completion struct {
+	ID       uint64        // 提交时分配的编号，从 1 开始按提交顺序递增
+	Op       Operation     // 完成的操作
+	Data     []byte        // 读操作读取到的内容
+	N        int           // 读写的字节数
+	Err      error         // 操作失败或被取消时的错误
+	Duration time.Duration // 操作本身的耗时，不包括排队时间
+}
+
+// Handler 完成处理器：操作完成后由分发协程调用
+// 同一个处理器的所有完成处理器在同一个协程中依次执行，不需要加锁；
+// 完成处理器中可以继续提交新的操作，但不能调用 Close 或等待其他操作的 Handle
+type Handler func(Completion)
+
+// Option 处理器配置选项
+type Option func(*Proactor)
+
+// WithWorkers 设置执行操作的工作协程数，默认 4
+func WithWorkers(n int) Option {
+	return func(p *Proactor) {
+		if n > 0 {
+			p.workers = n
+		}
+	}
+}
+
+// WithQueueSize 设置等待执行的操作的缓冲区大小，缓冲区满时 Submit 阻塞，默认 64
+func WithQueueSize(n int) Option {
+	return func(p *Proactor) {
+		if n >= 0 {
+			p.queueSize = n
+		}
+	}
+}
+
+// WithOrderedCompletion 按提交顺序调用完成处理器
+// 默认按完成顺序调用；有序模式下先完成的操作要等之前提交的操作都完成后才被分发
+func WithOrderedCompletion() Option {
+	return func(p *Proactor) {
+		p.ordered = true
+	}
+}
+
+// WithChunkSize 设置读写文件时每次读写的字节数，操作在两次读写之间检查是否被取消，默认 32KB
+func WithChunkSize(n int) Option {
+	return func(p *Proactor) {
+		if n > 0 {
+			p.chunkSize = n
+		}
+	}
+}
+
+// request 一个已提交的操作
+type request struct {
+	id      uint64
+	op      Operation
+	handler Handler
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	result Completion
+	done   chan struct{} // 完成处理器返回后关闭
+}
+
+// Handle 已提交操作的句柄
+type Handle struct {
+	request *request
+}
+
+// ID 返回操作的编号
+func (h *Handle) ID() uint64 {
+	return h.request.id
+}
+
+// Cancel 取消操作：尚未开始的操作不会执行，正在执行的操作在下一次读写之前停止
+// 被取消的操作仍然会调用完成处理器，错误为 context.Canceled
+func (h *Handle) Cancel() {
+	h.request.cancel()
+}
+
+// Done 返回完成处理器返回后关闭的通道
+func (h *Handle) Done() <-chan struct{} {
+	return h.request.done
+}
+
+// Wait 等待完成处理器返回，并返回完成结果
+func (h *Handle) Wait() Completion {
+	<-h.request.done
+	return h.request.result
+}
+
+// Proactor 前摄器：异步执行文件读写，操作完成后通过完成处理器通知调用者
+//
+// 调用者提交操作后立即返回，不会阻塞在 I/O 上；工作协程（异步操作处理器）执行操作，
+// 分发协程（完成分发器）把结果交给操作附带的完成处理器。
+type Proactor struct {
+	workers   int
+	queueSize int
+	ordered   bool
+	chunkSize int
+
+	mutex   sync.RWMutex // 保护 closed，Submit 持有读锁发送，Close 持有写锁关闭通道
+	closed  bool
+	nextID  atomic.Uint64 // 最近一次分配的编号
+	pending atomic.Int64  // 已提交但完成处理器尚未返回的操作数
+	idle    chan struct{} // pending 降为 0 时通知 Close
+
+	requests chan *request
+	running  sync.WaitGroup // 工作协程
+
+	completionMutex sync.Mutex
+	completions     []*request    // 等待分发的完成结果，不设上限，保证工作协程不会因为分发缓慢而阻塞
+	workersDone     bool          // 工作协程全部退出后不会再有新的完成结果
+	notify          chan struct{} // 有新的完成结果时通知分发协程
+	dispatched      chan struct{} // 分发协程退出时关闭
+}
+
+// NewProactor 创建前摄器并启动工作协程和分发协程
+func NewProactor(opts ...Option) *Proactor {
+	p := &Proactor{
+		workers:    4,
+		queueSize:  64,
+		chunkSize:  32 * 1024,
+		idle:       make(chan struct{}, 1),
+		notify:     make(chan struct{}, 1),
+		dispatched: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	p.requests = make(chan *request, p.queueSize)
+	for i := 0; i < p.workers; i++ {
+		p.running.Add(1)
+		go p.work()
+	}
+	go p.dispatch()
+	return p
+}
+
+// Submit 提交一个异步操作，操作完成（或被取消）后调用 handler
+// 缓冲区满时阻塞；等待期间 ctx 被取消时，操作以 ctx 的错误完成，同样会调用 handler
+func (p *Proactor) Submit(ctx context.Context, op Operation, handler Handler) (*Handle, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if p.closed {
+		return nil, ErrClosed
+	}
+
+	id := p.nextID.Add(1)
+	p.pending.Add(1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	r := &request{
+		id:      id,
+		op:      op,
+		handler: handler,
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+
+	select {
+	case p.requests <- r:
+	case <-ctx.Done():
+		r.result = Completion{ID: id, Op: op, Err: ctx.Err()}
+		p.complete(r)
+	}
+	return &Handle{request: r}, nil
+}
+
+// Close 等待已提交的操作全部完成、完成处理器全部返回后关闭处理器
+// 完成处理器中提交的后续操作同样会被等待；ctx 到期时返回 ctx 的错误，处理器保持打开
+func (p *Proactor) Close(ctx context.Context) error {
+	for {
+		p.mutex.Lock()
+		if p.closed || p.pending.Load() == 0 {
+			if !p.closed {
+				p.closed = true
+				close(p.requests)
+			}
+			p.mutex.Unlock()
+			break
+		}
+		p.mutex.Unlock()
+
+		select {
+		case <-p.idle:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	select {
+	case <-p.dispatched:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// work 工作协程：执行操作并把结果放入完成队列
+func (p *Proactor) work() {
+	defer p.running.Done()
+
+	for r := range p.requests {
+		start := time.Now()
+		r.result = p.perform(r.ctx, r.op)
+		r.result.ID = r.id
+		r.result.Duration = time.Since(start)
+		p.complete(r)
+	}
+}
+
+// complete 把完成的操作放入完成队列并通知分发协程
+func (p *Proactor) complete(r *request) {
+	p.completionMutex.Lock()
+	p.completions = append(p.completions, r)
+	p.completionMutex.Unlock()
+
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+}
+
+// dispatch 分发协程：依次调用完成处理器
+func (p *Proactor) dispatch() {
+	defer close(p.dispatched)
+
+	// 工作协程全部退出后标记完成队列不会再增长
+	go func() {
+		p.running.Wait()
+		p.completionMutex.Lock()
+		p.workersDone = true
+		p.completionMutex.Unlock()
+		select {
+		case p.notify <- struct{}{}:
+		default:
+		}
+	}()
+
+	next := uint64(1)                    // 有序模式下下一个应该分发的编号
+	waiting := make(map[uint64]*request) // 有序模式下提前完成、等待分发的操作
+	for {
+		p.completionMutex.Lock()
+		batch := p.completions
+		p.completions = nil
+		finished := p.workersDone // 工作协程退出后不会再有新的结果，这是最后一批
+		p.completionMutex.Unlock()
+
+		for _, r := range batch {
+			if !p.ordered {
+				p.deliver(r)
+				continue
+			}
+			waiting[r.id] = r
+			for ready, ok := waiting[next]; ok; ready, ok = waiting[next] {
+				delete(waiting, next)
+				next++
+				p.deliver(ready)
+			}
+		}
+
+		if finished {
+			return
+		}
+		<-p.notify
+	}
+}
+
+// deliver 调用完成处理器
+func (p *Proactor) deliver(r *request) {
+	defer close(r.done)
+	r.cancel()
+
+	if r.handler != nil {
+		r.handler(r.result)
+	}
+	if p.pending.Add(-1) == 0 {
+		select {
+		case p.idle <- struct{}{}:
+		default:
+		}
+	}
+}
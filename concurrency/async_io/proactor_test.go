@@ -0,0 +1,163 @@
+package async_io
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func closeProactor(t *testing.T, p *Proactor) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, p.Close(ctx))
+}
+
+// bigFile 创建一个较大的文件，配合很小的分块让读操作持续一段时间
+func bigFile(t *testing.T, dir string) string {
+	t.Helper()
+	path := filepath.Join(dir, "big.bin")
+	assert.NoError(t, os.WriteFile(path, bytes.Repeat([]byte("x"), 8<<20), 0o644))
+	return path
+}
+
+// TestWriteThenRead 测试写入和读取，以及在完成处理器中提交后续操作
+func TestWriteThenRead(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "note.txt")
+	p := NewProactor(WithChunkSize(4))
+
+	var read Completion
+	h, err := p.Submit(context.Background(), Write(path, []byte("hello proactor")), func(c Completion) {
+		assert.NoError(t, c.Err)
+		assert.Equal(t, 14, c.N)
+		// 完成处理器中提交的读操作会被 Close 等待
+		_, err := p.Submit(context.Background(), Read(path), func(c Completion) { read = c })
+		assert.NoError(t, err)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), h.ID())
+
+	closeProactor(t, p)
+	assert.NoError(t, read.Err)
+	assert.Equal(t, "hello proactor", string(read.Data))
+	assert.Equal(t, uint64(2), read.ID)
+	assert.Equal(t, OpRead, read.Op.Kind)
+
+	_, err = os.Stat(path + ".tmp")
+	assert.True(t, os.IsNotExist(err), "临时文件已被重命名")
+
+	_, err = p.Submit(context.Background(), Read(path), nil)
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.NoError(t, p.Close(context.Background()), "重复关闭是安全的")
+}
+
+// TestOrderedCompletion 测试有序模式按提交顺序分发，完成处理器不会并发执行
+func TestOrderedCompletion(t *testing.T) {
+	dir := t.TempDir()
+	big := bigFile(t, dir)
+	small := filepath.Join(dir, "small.txt")
+	assert.NoError(t, os.WriteFile(small, []byte("s"), 0o644))
+
+	for _, ordered := range []bool{true, false} {
+		opts := []Option{WithWorkers(4), WithChunkSize(64 * 1024)}
+		if ordered {
+			opts = append(opts, WithOrderedCompletion())
+		}
+		p := NewProactor(opts...)
+
+		var ids []uint64
+		var active sync.Mutex // 完成处理器并发执行时 TryLock 会失败
+		handler := func(c Completion) {
+			assert.True(t, active.TryLock(), "完成处理器不应并发执行")
+			defer active.Unlock()
+			assert.NoError(t, c.Err)
+			ids = append(ids, c.ID)
+		}
+
+		// 第一个操作最慢，之后的小文件先完成
+		p.Submit(context.Background(), Read(big), handler)
+		for i := 0; i < 20; i++ {
+			p.Submit(context.Background(), Read(small), handler)
+		}
+		closeProactor(t, p)
+
+		assert.Len(t, ids, 21)
+		if ordered {
+			for i, id := range ids {
+				assert.Equal(t, uint64(i+1), id, "有序模式按提交顺序分发")
+			}
+		} else {
+			assert.ElementsMatch(t, []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21}, ids)
+		}
+	}
+}
+
+// TestCancel 测试取消排队中和执行中的操作
+func TestCancel(t *testing.T) {
+	dir := t.TempDir()
+	big := bigFile(t, dir)
+	p := NewProactor(WithWorkers(1), WithChunkSize(16))
+	defer closeProactor(t, p)
+
+	running, _ := p.Submit(context.Background(), Read(big), nil)
+	queued, _ := p.Submit(context.Background(), Read(big), nil)
+	queued.Cancel()
+	running.Cancel()
+
+	c := running.Wait()
+	assert.ErrorIs(t, c.Err, context.Canceled, "执行中的操作在下一次读之前停止")
+	assert.Nil(t, c.Data)
+	c = queued.Wait()
+	assert.ErrorIs(t, c.Err, context.Canceled, "排队中的操作不会执行")
+	assert.Nil(t, c.Data)
+
+	// 被取消的写操作不留下文件
+	target := filepath.Join(dir, "partial.bin")
+	ctx, cancel := context.WithCancel(context.Background())
+	h, _ := p.Submit(ctx, Write(target, bytes.Repeat([]byte("y"), 8<<20)), nil)
+	cancel()
+	assert.ErrorIs(t, h.Wait().Err, context.Canceled)
+	for _, path := range []string{target, target + ".tmp"} {
+		_, err := os.Stat(path)
+		assert.True(t, os.IsNotExist(err), path)
+	}
+}
+
+// TestSubmitBackpressure 测试缓冲区已满时 Submit 阻塞，ctx 到期后操作以错误完成
+func TestSubmitBackpressure(t *testing.T) {
+	dir := t.TempDir()
+	big := bigFile(t, dir)
+	p := NewProactor(WithWorkers(1), WithQueueSize(0), WithChunkSize(16))
+
+	busy, _ := p.Submit(context.Background(), Read(big), nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	var handled Completion
+	h, err := p.Submit(ctx, Read(big), func(c Completion) { handled = c })
+	assert.NoError(t, err)
+	assert.ErrorIs(t, h.Wait().Err, context.DeadlineExceeded)
+	assert.Equal(t, h.ID(), handled.ID, "完成处理器同样被调用")
+
+	busy.Cancel()
+	closeProactor(t, p)
+}
+
+// TestErrors 测试操作失败时错误交给完成处理器
+func TestErrors(t *testing.T) {
+	p := NewProactor()
+	defer closeProactor(t, p)
+
+	h, _ := p.Submit(context.Background(), Read(filepath.Join(t.TempDir(), "missing")), nil)
+	assert.ErrorIs(t, h.Wait().Err, os.ErrNotExist)
+
+	h, _ = p.Submit(context.Background(), Operation{Kind: OpKind(9)}, nil)
+	assert.ErrorContains(t, h.Wait().Err, "OpKind(9)")
+}
@@ -0,0 +1,296 @@
+package backpressure
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/XiaoluCoding626/go-design-pattern/architectural/metrics"
+)
+
+// allStrategies 返回每种策略的一个新实例，有界策略的容量为 capacity
+func allStrategies[T any](capacity int) []Strategy[T] {
+	return []Strategy[T]{
+		NewBlocking[T](capacity),
+		NewDropping[T](capacity, DropNewest),
+		NewDropping[T](capacity, DropOldest),
+		NewCredit[T](capacity),
+		NewUnbounded[T](),
+	}
+}
+
+// drain 取出通道中剩余的所有数据
+func drain[T any](t *testing.T, s Strategy[T]) []T {
+	var got []T
+	for {
+		v, err := s.Receive(context.Background())
+		if err != nil {
+			assert.ErrorIs(t, err, ErrClosed)
+			return got
+		}
+		got = append(got, v)
+		s.Done()
+	}
+}
+
+func TestDeliverInOrder(t *testing.T) {
+	for _, s := range allStrategies[int](8) {
+		t.Run(s.Name(), func(t *testing.T) {
+			for i := 0; i < 5; i++ {
+				assert.NoError(t, s.Send(context.Background(), i))
+			}
+			s.Close()
+
+			// 关闭后仍然可以取完剩余的数据
+			assert.Equal(t, []int{0, 1, 2, 3, 4}, drain(t, s))
+			_, err := s.Receive(context.Background())
+			assert.ErrorIs(t, err, ErrClosed)
+
+			st := s.Stats()
+			assert.Equal(t, uint64(5), st.Sent)
+			assert.Equal(t, uint64(5), st.Received)
+			assert.Equal(t, uint64(5), st.Processed)
+			assert.Equal(t, 0, st.Buffered)
+			assert.Equal(t, 5, st.MaxBuffered)
+		})
+	}
+}
+
+func TestSendAfterClose(t *testing.T) {
+	// Blocking 与 channel 一样不允许关闭后发送，其余策略返回 ErrClosed
+	for _, s := range allStrategies[int](4)[1:] {
+		s.Close()
+		assert.ErrorIs(t, s.Send(context.Background(), 1), ErrClosed, s.Name())
+	}
+}
+
+func TestReceiveCanceled(t *testing.T) {
+	for _, s := range allStrategies[int](4) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		_, err := s.Receive(ctx)
+		cancel()
+		assert.ErrorIs(t, err, context.DeadlineExceeded, s.Name())
+	}
+}
+
+func TestBlockingWaitsForSpace(t *testing.T) {
+	b := NewBlocking[int](2)
+	ctx := context.Background()
+	assert.NoError(t, b.Send(ctx, 1))
+	assert.NoError(t, b.Send(ctx, 2))
+
+	// 缓冲区满，超时返回且不计入 Sent
+	timeout, cancel := context.WithTimeout(ctx, 5*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, b.Send(timeout, 3), context.DeadlineExceeded)
+	assert.Equal(t, uint64(2), b.Stats().Sent)
+	assert.Equal(t, uint64(1), b.Stats().Waits)
+
+	// 消费者取走一条后，等待中的发送完成
+	sent := make(chan error)
+	go func() { sent <- b.Send(ctx, 3) }()
+	time.Sleep(5 * time.Millisecond)
+	v, err := b.Receive(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+	assert.NoError(t, <-sent)
+
+	b.Close()
+	assert.Equal(t, []int{2, 3}, drain(t, b))
+	assert.GreaterOrEqual(t, b.Stats().WaitTime, 5*time.Millisecond)
+}
+
+func TestDropping(t *testing.T) {
+	ctx := context.Background()
+
+	newest := NewDropping[int](2, DropNewest)
+	for i := 0; i < 5; i++ {
+		err := newest.Send(ctx, i)
+		if i < 2 {
+			assert.NoError(t, err)
+		} else {
+			assert.ErrorIs(t, err, ErrDropped)
+		}
+	}
+	newest.Close()
+	assert.Equal(t, []int{0, 1}, drain(t, newest))
+	assert.Equal(t, uint64(3), newest.Stats().Dropped)
+
+	oldest := NewDropping[int](2, DropOldest)
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, oldest.Send(ctx, i))
+	}
+	oldest.Close()
+	assert.Equal(t, []int{3, 4}, drain(t, oldest), "保留最新的数据")
+	st := oldest.Stats()
+	assert.Equal(t, uint64(3), st.Dropped)
+	assert.Equal(t, uint64(5), st.Sent)
+	assert.Equal(t, 2, st.MaxBuffered)
+}
+
+func TestCreditReturnedOnDone(t *testing.T) {
+	c := NewCredit[int](1)
+	ctx := context.Background()
+	assert.NoError(t, c.Send(ctx, 1))
+
+	trySend := func() error {
+		timeout, cancel := context.WithTimeout(ctx, 5*time.Millisecond)
+		defer cancel()
+		return c.Send(timeout, 2)
+	}
+	assert.ErrorIs(t, trySend(), context.DeadlineExceeded, "额度用尽")
+
+	// 取出但没有处理完，额度仍未归还；这是与有界 channel 的区别
+	_, err := c.Receive(ctx)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, trySend(), context.DeadlineExceeded, "取出后额度仍未归还")
+
+	c.Done()
+	assert.NoError(t, trySend())
+	assert.Equal(t, uint64(2), c.Stats().Waits)
+
+	// 关闭时唤醒等待额度的生产者
+	sent := make(chan error)
+	go func() { sent <- c.Send(ctx, 3) }()
+	time.Sleep(5 * time.Millisecond)
+	c.Close()
+	assert.ErrorIs(t, <-sent, ErrClosed)
+}
+
+func TestCreditBoundsInFlight(t *testing.T) {
+	const window = 4
+	c := NewCredit[int](window)
+
+	var mutex sync.Mutex
+	inFlight, peak := 0, 0
+	report := Run(context.Background(), c, Config{Items: 100, Consumers: 3, ConsumeTime: time.Millisecond},
+		func(i int) int { return i },
+		func(int) {
+			// 正在处理的加上缓冲区中的数据
+			mutex.Lock()
+			inFlight++
+			peak = max(peak, inFlight+c.Stats().Buffered)
+			mutex.Unlock()
+			time.Sleep(time.Millisecond)
+			mutex.Lock()
+			inFlight--
+			mutex.Unlock()
+		})
+
+	assert.Equal(t, 100, report.Consumed)
+	assert.LessOrEqual(t, peak, window)
+	assert.LessOrEqual(t, report.Stats.MaxBuffered, window)
+}
+
+// TestMemoryBoundedUnderOverload 生产远快于消费时，有界策略的内存占用保持在容量附近
+func TestMemoryBoundedUnderOverload(t *testing.T) {
+	const (
+		items    = 200
+		itemSize = 256 << 10 // 全部保留时约 50 MiB
+		capacity = 8
+	)
+	cfg := Config{Items: items, ConsumeTime: 100 * time.Microsecond}
+
+	for _, s := range allStrategies[[]byte](capacity)[:4] {
+		t.Run(s.Name(), func(t *testing.T) {
+			runtime.GC()
+			var ms runtime.MemStats
+			runtime.ReadMemStats(&ms)
+			baseline := ms.HeapAlloc
+
+			var peak uint64
+			consumed := 0
+			report := Run(context.Background(), s, cfg,
+				func(int) []byte { return make([]byte, itemSize) },
+				func([]byte) {
+					consumed++
+					if consumed%4 != 0 {
+						return
+					}
+					runtime.GC()
+					runtime.ReadMemStats(&ms)
+					if ms.HeapAlloc > baseline {
+						peak = max(peak, ms.HeapAlloc-baseline)
+					}
+				})
+
+			assert.LessOrEqual(t, report.Stats.MaxBuffered, capacity)
+			// 缓冲区、消费者手中和生产者手中的数据合计不超过 capacity+2 条，留出余量
+			assert.Less(t, peak, uint64(4*capacity*itemSize), "峰值 %d MiB", peak>>20)
+			if _, ok := s.(*Dropping[[]byte]); !ok {
+				assert.Zero(t, report.Lost(), "阻塞型策略不丢数据")
+			}
+		})
+	}
+}
+
+func TestUnboundedGrowsWithoutBackpressure(t *testing.T) {
+	report := Run(context.Background(), NewUnbounded[int](), Config{Items: 500, ConsumeTime: 100 * time.Microsecond},
+		func(i int) int { return i }, nil)
+	assert.Equal(t, 500, report.Consumed)
+	assert.Equal(t, uint64(0), report.Stats.Waits)
+	// 单核下生产者在消费者开始之前就发送完毕；多核下也远超有界策略的容量
+	assert.Greater(t, report.Stats.MaxBuffered, 100)
+}
+
+func TestRunSendTimeout(t *testing.T) {
+	report := Run(context.Background(), NewBlocking[int](2), Config{
+		Items:       20,
+		ConsumeTime: 5 * time.Millisecond,
+		SendTimeout: time.Millisecond,
+	}, nil, nil)
+
+	assert.Greater(t, report.Rejected, 0)
+	assert.Equal(t, report.Rejected, report.Lost())
+	assert.Equal(t, 20, report.Produced)
+}
+
+func TestRunCanceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	report := Run(ctx, NewCredit[int](2), Config{Items: 1000, ConsumeTime: time.Millisecond}, nil, nil)
+	assert.Less(t, report.Produced, 1000)
+	assert.Less(t, report.Elapsed, time.Second)
+}
+
+func TestStatsCollector(t *testing.T) {
+	s := NewDropping[int](1, DropNewest)
+	s.Send(context.Background(), 1)
+	s.Send(context.Background(), 2)
+
+	registry := metrics.NewRegistry()
+	registry.Register(statsCollector[int](s))
+	sample, ok := registry.Snapshot().Get("backpressure_dropped_total", metrics.Labels{"strategy": "drop-newest"})
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, sample.Value)
+}
+
+func BenchmarkStrategies(b *testing.B) {
+	for i, strategy := range allStrategies[int](64) {
+		b.Run(strategy.Name(), func(b *testing.B) {
+			// 基准函数会运行多次，每次使用新的通道
+			s := allStrategies[int](64)[i]
+			ctx := context.Background()
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for {
+					if _, err := s.Receive(ctx); err != nil {
+						return
+					}
+					s.Done()
+				}
+			}()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				s.Send(ctx, i)
+			}
+			s.Close()
+			<-done
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package backpressure
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/XiaoluCoding626/go-design-pattern/architectural/metrics"
+)
+
+// statsCollector 把通道的统计导出为指标，丢弃条数是丢弃型策略最需要告警的指标
+func statsCollector[T any](s Strategy[T]) metrics.Collector {
+	labels := metrics.Labels{"strategy": s.Name()}
+	return metrics.CollectorFunc(func(emit func(metrics.Sample)) {
+		st := s.Stats()
+		emit(metrics.CounterSample("backpressure_sent_total", labels, float64(st.Sent)))
+		emit(metrics.CounterSample("backpressure_dropped_total", labels, float64(st.Dropped)))
+		emit(metrics.GaugeSample("backpressure_buffered_max", labels, float64(st.MaxBuffered)))
+	})
+}
+
+// RunExample 运行背压示例：生产远快于消费，比较各个策略的表现
+func RunExample() {
+	ctx := context.Background()
+	cfg := Config{
+		Items:       200,
+		ConsumeTime: time.Millisecond,
+	}
+	registry := metrics.NewRegistry()
+
+	fmt.Println("== 全速生产 200 条，每条处理 1 毫秒 ==")
+	strategies := []Strategy[int]{
+		NewUnbounded[int](),
+		NewBlocking[int](16),
+		NewDropping[int](16, DropNewest),
+		NewDropping[int](16, DropOldest),
+		NewCredit[int](16),
+	}
+	for _, s := range strategies {
+		registry.Register(statsCollector(s))
+		fmt.Println(" ", Run(ctx, s, cfg, func(i int) int { return i }, nil))
+	}
+
+	fmt.Println("== 丢弃指标 ==")
+	for _, sample := range registry.Snapshot().Samples {
+		if sample.Name == "backpressure_dropped_total" && sample.Value > 0 {
+			fmt.Printf("  %s = %v\n", sample.ID(), sample.Value)
+		}
+	}
+
+	fmt.Println("== DropOldest 保留最新的数据 ==")
+	var last []int
+	Run(ctx, NewDropping[int](4, DropOldest), Config{Items: 50, ConsumeTime: time.Millisecond},
+		func(i int) int { return i },
+		func(v int) { last = append(last, v) })
+	fmt.Println("  处理的数据:", last)
+
+	fmt.Println("== 生产者等待超时：阻塞一段时间后放弃 ==")
+	fmt.Println(" ", Run(ctx, NewBlocking[int](4), Config{
+		Items:       50,
+		ConsumeTime: 2 * time.Millisecond,
+		SendTimeout: 500 * time.Microsecond,
+	}, nil, nil))
+}
@@ -0,0 +1,105 @@
+package backpressure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Config 生产者和消费者的参数，通过调整生产和消费速度模拟不同的负载
+type Config struct {
+	Items        int           // 生产的数据条数
+	ProduceEvery time.Duration // 生产间隔，0 表示全速生产
+	ConsumeTime  time.Duration // 处理一条数据的时间，0 表示立即处理完
+	Consumers    int           // 消费者个数，默认 1
+	SendTimeout  time.Duration // 单次 Send 的最长等待时间，超时视为丢弃，0 表示一直等待
+}
+
+// Report 一次运行的结果
+type Report struct {
+	Strategy string
+	Produced int           // 生产者尝试发送的条数
+	Rejected int           // Send 返回错误（被丢弃或超时）的条数
+	Consumed int           // 消费者处理完的条数
+	Elapsed  time.Duration // 从开始生产到全部处理完的时间
+	Stats    Stats         // 通道的统计信息
+}
+
+// Lost 返回生产了但没有被处理的条数，包括 Send 被拒绝的和在缓冲区中被挤掉的
+func (r Report) Lost() int {
+	return r.Produced - r.Consumed
+}
+
+// String 格式化结果
+func (r Report) String() string {
+	return fmt.Sprintf("%-12s 生产 %d，处理 %d，丢失 %d，最大缓冲 %d，生产者等待 %d 次，耗时 %v",
+		r.Strategy, r.Produced, r.Consumed, r.Lost(), r.Stats.MaxBuffered, r.Stats.Waits, r.Elapsed.Round(time.Millisecond))
+}
+
+// Run 用给定的策略连接一个生产者和若干消费者，运行到数据全部处理完或 ctx 取消
+// produce 生成第 i 条数据，consume 处理一条数据；两者都可以为 nil
+func Run[T any](ctx context.Context, s Strategy[T], cfg Config, produce func(i int) T, consume func(v T)) Report {
+	consumers := max(cfg.Consumers, 1)
+	report := Report{Strategy: s.Name()}
+	start := time.Now()
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for c := 0; c < consumers; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				v, err := s.Receive(ctx)
+				if err != nil {
+					return
+				}
+				if consume != nil {
+					consume(v)
+				}
+				if cfg.ConsumeTime > 0 {
+					time.Sleep(cfg.ConsumeTime)
+				}
+				s.Done()
+				mutex.Lock()
+				report.Consumed++
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	for i := 0; i < cfg.Items && ctx.Err() == nil; i++ {
+		var v T
+		if produce != nil {
+			v = produce(i)
+		}
+		report.Produced++
+		if err := send(ctx, s, v, cfg.SendTimeout); err != nil {
+			report.Rejected++
+			if !errors.Is(err, ErrDropped) && !errors.Is(err, context.DeadlineExceeded) {
+				break
+			}
+		}
+		if cfg.ProduceEvery > 0 {
+			time.Sleep(cfg.ProduceEvery)
+		}
+	}
+	s.Close()
+	wg.Wait()
+
+	report.Elapsed = time.Since(start)
+	report.Stats = s.Stats()
+	return report
+}
+
+// send 发送一条数据，设置了超时时最多等待 timeout
+func send[T any](ctx context.Context, s Strategy[T], v T, timeout time.Duration) error {
+	if timeout <= 0 {
+		return s.Send(ctx, v)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return s.Send(ctx, v)
+}
@@ -0,0 +1,104 @@
+package backpressure
+
+import (
+	"context"
+	"sync"
+)
+
+// queue 互斥锁保护的环形队列，供需要在锁内决定丢弃或等待额度的策略使用
+//
+// 任何状态变化都关闭并替换 changed 来唤醒所有等待者，等待者醒来后重新检查条件
+type queue[T any] struct {
+	mutex   sync.Mutex
+	buf     []T
+	head    int
+	n       int
+	closed  bool
+	changed chan struct{}
+}
+
+// newQueue 创建初始容量为 capacity 的队列，容量不足时自动扩容
+func newQueue[T any](capacity int) *queue[T] {
+	return &queue[T]{
+		buf:     make([]T, max(capacity, 1)),
+		changed: make(chan struct{}),
+	}
+}
+
+// pushLocked 追加到队尾，调用时必须持有锁
+func (q *queue[T]) pushLocked(v T) {
+	if q.n == len(q.buf) {
+		grown := make([]T, 2*len(q.buf))
+		for i := 0; i < q.n; i++ {
+			grown[i] = q.buf[(q.head+i)%len(q.buf)]
+		}
+		q.buf, q.head = grown, 0
+	}
+	q.buf[(q.head+q.n)%len(q.buf)] = v
+	q.n++
+	q.broadcastLocked()
+}
+
+// popLocked 取出队首，调用时必须持有锁且队列非空
+func (q *queue[T]) popLocked() T {
+	var zero T
+	v := q.buf[q.head]
+	q.buf[q.head] = zero // 释放引用，被取走的数据可以被回收
+	q.head = (q.head + 1) % len(q.buf)
+	q.n--
+	q.broadcastLocked()
+	return v
+}
+
+// broadcastLocked 唤醒所有等待者，调用时必须持有锁
+func (q *queue[T]) broadcastLocked() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+// waitLocked 等待 cond 成立，调用时必须持有锁，返回时仍然持有锁
+func (q *queue[T]) waitLocked(ctx context.Context, cond func() bool) error {
+	for !cond() {
+		changed := q.changed
+		q.mutex.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			q.mutex.Lock()
+			return ctx.Err()
+		}
+		q.mutex.Lock()
+	}
+	return nil
+}
+
+// receive 取出一条数据，队列为空时等待，关闭且取完后返回 ErrClosed
+func (q *queue[T]) receive(ctx context.Context) (T, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	var zero T
+	if err := q.waitLocked(ctx, func() bool { return q.n > 0 || q.closed }); err != nil {
+		return zero, err
+	}
+	if q.n == 0 {
+		return zero, ErrClosed
+	}
+	return q.popLocked(), nil
+}
+
+// close 关闭队列
+func (q *queue[T]) close() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if !q.closed {
+		q.closed = true
+		q.broadcastLocked()
+	}
+}
+
+// len 返回当前的数据条数
+func (q *queue[T]) len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.n
+}
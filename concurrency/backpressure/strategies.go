@@ -0,0 +1,254 @@
+package backpressure
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Blocking 有界 channel：缓冲区满时 Send 阻塞，直到消费者取走数据
+//
+// 背压沿着阻塞直接传递给生产者，生产速度被压到消费速度，不丢数据，内存占用不超过容量
+type Blocking[T any] struct {
+	ch        chan T
+	closeOnce sync.Once
+	counters
+}
+
+// NewBlocking 创建容量为 capacity 的有界通道
+func NewBlocking[T any](capacity int) *Blocking[T] {
+	return &Blocking[T]{ch: make(chan T, max(capacity, 0))}
+}
+
+// Name 返回策略名称
+func (b *Blocking[T]) Name() string { return "blocking" }
+
+// Send 缓冲区满时阻塞，ctx 取消时返回其错误
+func (b *Blocking[T]) Send(ctx context.Context, v T) error {
+	// 先计数，保证 Sent 不会落后于消费者的 Received
+	b.sent.Add(1)
+	select {
+	case b.ch <- v:
+	default:
+		start := time.Now()
+		select {
+		case b.ch <- v:
+			b.observeWait(start)
+		case <-ctx.Done():
+			b.observeWait(start)
+			b.sent.Add(^uint64(0))
+			return ctx.Err()
+		}
+	}
+	b.observeBuffered(len(b.ch))
+	return nil
+}
+
+// Receive 取出一条数据
+func (b *Blocking[T]) Receive(ctx context.Context) (T, error) {
+	select {
+	case v, ok := <-b.ch:
+		if !ok {
+			return v, ErrClosed
+		}
+		b.received.Add(1)
+		return v, nil
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// Done 记录一条数据处理完
+func (b *Blocking[T]) Done() { b.processed.Add(1) }
+
+// Close 关闭通道，与关闭 channel 一样，之后不能再 Send
+func (b *Blocking[T]) Close() {
+	b.closeOnce.Do(func() { close(b.ch) })
+}
+
+// Stats 返回统计信息
+func (b *Blocking[T]) Stats() Stats { return b.stats(len(b.ch)) }
+
+// DropPolicy 缓冲区满时丢弃哪一条数据
+type DropPolicy int
+
+const (
+	DropNewest DropPolicy = iota // 丢弃新到的数据，Send 返回 ErrDropped
+	DropOldest                   // 丢弃最早的数据，为新数据腾出位置，适合只关心最新值的场景
+)
+
+// Dropping 有界缓冲区，满时丢弃数据并计数，Send 从不阻塞
+//
+// 生产者不受消费速度影响，代价是丢数据；丢弃的条数记录在 Stats.Dropped 中，应当作为指标导出并告警
+type Dropping[T any] struct {
+	q        *queue[T]
+	capacity int
+	policy   DropPolicy
+	counters
+}
+
+// NewDropping 创建容量为 capacity 的丢弃型通道
+func NewDropping[T any](capacity int, policy DropPolicy) *Dropping[T] {
+	capacity = max(capacity, 1)
+	return &Dropping[T]{q: newQueue[T](capacity), capacity: capacity, policy: policy}
+}
+
+// Name 返回策略名称
+func (d *Dropping[T]) Name() string {
+	if d.policy == DropOldest {
+		return "drop-oldest"
+	}
+	return "drop-newest"
+}
+
+// Send 缓冲区满时按策略丢弃数据，DropNewest 丢弃 v 本身并返回 ErrDropped
+func (d *Dropping[T]) Send(_ context.Context, v T) error {
+	d.q.mutex.Lock()
+	defer d.q.mutex.Unlock()
+	if d.q.closed {
+		return ErrClosed
+	}
+	if d.q.n >= d.capacity {
+		d.dropped.Add(1)
+		if d.policy == DropNewest {
+			return ErrDropped
+		}
+		d.q.popLocked()
+	}
+	d.sent.Add(1)
+	d.q.pushLocked(v)
+	d.observeBuffered(d.q.n)
+	return nil
+}
+
+// Receive 取出一条数据
+func (d *Dropping[T]) Receive(ctx context.Context) (T, error) {
+	v, err := d.q.receive(ctx)
+	if err == nil {
+		d.received.Add(1)
+	}
+	return v, err
+}
+
+// Done 记录一条数据处理完
+func (d *Dropping[T]) Done() { d.processed.Add(1) }
+
+// Close 关闭通道
+func (d *Dropping[T]) Close() { d.q.close() }
+
+// Stats 返回统计信息，DropOldest 时 Dropped 包括已经进入缓冲区后被挤掉的数据
+func (d *Dropping[T]) Stats() Stats { return d.stats(d.q.len()) }
+
+// Credit 基于额度的流控：生产者每发送一条消耗一个额度，消费者处理完一条（Done）才归还
+//
+// 与有界 channel 的区别在于额度在处理完之后而不是取出时归还，
+// 因此限制的是缓冲区中加上正在处理的全部数据（在途数据），而不仅仅是缓冲区。
+// 这与 HTTP/2 的流量窗口、Reactive Streams 的 request(n) 是同一个思路
+type Credit[T any] struct {
+	q       *queue[T]
+	credits int // 剩余额度，由 q.mutex 保护
+	counters
+}
+
+// NewCredit 创建初始额度为 window 的通道，在途数据最多 window 条
+func NewCredit[T any](window int) *Credit[T] {
+	window = max(window, 1)
+	return &Credit[T]{q: newQueue[T](window), credits: window}
+}
+
+// Name 返回策略名称
+func (c *Credit[T]) Name() string { return "credit" }
+
+// Send 等待并消耗一个额度，ctx 取消时返回其错误
+func (c *Credit[T]) Send(ctx context.Context, v T) error {
+	c.q.mutex.Lock()
+	defer c.q.mutex.Unlock()
+	if c.credits == 0 && !c.q.closed {
+		start := time.Now()
+		err := c.q.waitLocked(ctx, func() bool { return c.credits > 0 || c.q.closed })
+		c.observeWait(start)
+		if err != nil {
+			return err
+		}
+	}
+	if c.q.closed {
+		return ErrClosed
+	}
+	c.credits--
+	c.sent.Add(1)
+	c.q.pushLocked(v)
+	c.observeBuffered(c.q.n)
+	return nil
+}
+
+// Receive 取出一条数据，额度此时还没有归还
+func (c *Credit[T]) Receive(ctx context.Context) (T, error) {
+	v, err := c.q.receive(ctx)
+	if err == nil {
+		c.received.Add(1)
+	}
+	return v, err
+}
+
+// Done 归还一个额度
+func (c *Credit[T]) Done() {
+	c.q.mutex.Lock()
+	defer c.q.mutex.Unlock()
+	c.credits++
+	c.processed.Add(1)
+	c.q.broadcastLocked()
+}
+
+// Close 关闭通道
+func (c *Credit[T]) Close() { c.q.close() }
+
+// Stats 返回统计信息
+func (c *Credit[T]) Stats() Stats { return c.stats(c.q.len()) }
+
+// Unbounded 无界缓冲区，Send 从不阻塞也不丢弃，即没有背压
+//
+// 仅作为对照：消费跟不上时缓冲区无限增长，最终耗尽内存
+type Unbounded[T any] struct {
+	q *queue[T]
+	counters
+}
+
+// NewUnbounded 创建无界通道
+func NewUnbounded[T any]() *Unbounded[T] {
+	return &Unbounded[T]{q: newQueue[T](16)}
+}
+
+// Name 返回策略名称
+func (u *Unbounded[T]) Name() string { return "unbounded" }
+
+// Send 追加数据，从不阻塞
+func (u *Unbounded[T]) Send(_ context.Context, v T) error {
+	u.q.mutex.Lock()
+	defer u.q.mutex.Unlock()
+	if u.q.closed {
+		return ErrClosed
+	}
+	u.sent.Add(1)
+	u.q.pushLocked(v)
+	u.observeBuffered(u.q.n)
+	return nil
+}
+
+// Receive 取出一条数据
+func (u *Unbounded[T]) Receive(ctx context.Context) (T, error) {
+	v, err := u.q.receive(ctx)
+	if err == nil {
+		u.received.Add(1)
+	}
+	return v, err
+}
+
+// Done 记录一条数据处理完
+func (u *Unbounded[T]) Done() { u.processed.Add(1) }
+
+// Close 关闭通道
+func (u *Unbounded[T]) Close() { u.q.close() }
+
+// Stats 返回统计信息
+func (u *Unbounded[T]) Stats() Stats { return u.stats(u.q.len()) }
@@ -0,0 +1,86 @@
+package backpressure
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// 背压相关错误
+var (
+	ErrClosed  = errors.New("通道已关闭")
+	ErrDropped = errors.New("缓冲区已满，数据被丢弃")
+)
+
+// Strategy 生产者与消费者之间的通道，决定消费跟不上时如何对生产者施加背压
+//
+// 生产者调用 Send，全部发送完后调用 Close；消费者调用 Receive 取出数据，处理完一条后调用 Done。
+// Close 之后消费者仍能取完缓冲区中剩余的数据，之后 Receive 返回 ErrClosed。
+type Strategy[T any] interface {
+	// Name 返回策略名称
+	Name() string
+	// Send 发送一条数据，可能阻塞（等待空位或额度）或丢弃数据，取决于策略
+	Send(ctx context.Context, v T) error
+	// Receive 取出一条数据，没有数据时阻塞
+	Receive(ctx context.Context) (T, error)
+	// Done 通知一条数据已经处理完
+	Done()
+	// Close 表示不会再发送数据，只能由生产者在最后一次 Send 之后调用
+	Close()
+	// Stats 返回统计信息
+	Stats() Stats
+}
+
+// Stats 通道的统计信息
+type Stats struct {
+	Sent        uint64        // 进入缓冲区的数据条数
+	Dropped     uint64        // 被丢弃的数据条数
+	Received    uint64        // 消费者取出的数据条数
+	Processed   uint64        // 消费者处理完的数据条数
+	Buffered    int           // 当前缓冲的数据条数
+	MaxBuffered int           // 缓冲数据条数的最大值，衡量内存占用
+	Waits       uint64        // Send 因为背压而等待的次数
+	WaitTime    time.Duration // Send 等待的总时间
+}
+
+// counters 各个策略共用的统计计数
+type counters struct {
+	sent        atomic.Uint64
+	dropped     atomic.Uint64
+	received    atomic.Uint64
+	processed   atomic.Uint64
+	maxBuffered atomic.Int64
+	waits       atomic.Uint64
+	waitTime    atomic.Int64
+}
+
+// observeBuffered 记录缓冲数据条数的最大值
+func (c *counters) observeBuffered(n int) {
+	for {
+		old := c.maxBuffered.Load()
+		if int64(n) <= old || c.maxBuffered.CompareAndSwap(old, int64(n)) {
+			return
+		}
+	}
+}
+
+// observeWait 记录一次等待
+func (c *counters) observeWait(start time.Time) {
+	c.waits.Add(1)
+	c.waitTime.Add(int64(time.Since(start)))
+}
+
+// stats 生成统计信息
+func (c *counters) stats(buffered int) Stats {
+	return Stats{
+		Sent:        c.sent.Load(),
+		Dropped:     c.dropped.Load(),
+		Received:    c.received.Load(),
+		Processed:   c.processed.Load(),
+		Buffered:    buffered,
+		MaxBuffered: int(c.maxBuffered.Load()),
+		Waits:       c.waits.Load(),
+		WaitTime:    time.Duration(c.waitTime.Load()),
+	}
+}
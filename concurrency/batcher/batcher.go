@@ -0,0 +1,263 @@
+package batcher
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrClosed 批处理器已关闭
+var ErrClosed = errors.New("批处理器已关闭")
+
+// Sink 接收一批元素的回调，例如批量写入数据库或批量发送请求
+type Sink[T any] func(ctx context.Context, batch []T) error
+
+// Option 批处理器配置选项
+type Option func(*config)
+
+// config 批处理器配置，与元素类型无关
+type config struct {
+	maxSize    int
+	maxLatency time.Duration
+	queueSize  int
+	onError    func(size int, err error)
+}
+
+// WithMaxSize 一批最多包含的元素数，攒够后立即提交，默认 100
+func WithMaxSize(n int) Option {
+	return func(c *config) {
+		if n > 0 {
+			c.maxSize = n
+		}
+	}
+}
+
+// WithMaxLatency 一批中第一个元素最多等待的时间，超时后即使没攒够也提交，默认 100 毫秒
+func WithMaxLatency(d time.Duration) Option {
+	return func(c *config) {
+		if d > 0 {
+			c.maxLatency = d
+		}
+	}
+}
+
+// WithQueueSize 等待进入批次的元素的缓冲区大小，默认等于最大批次大小
+// 接收端处理缓慢时缓冲区会被填满，Add 随之阻塞，形成背压
+func WithQueueSize(n int) Option {
+	return func(c *config) {
+		if n >= 0 {
+			c.queueSize = n
+		}
+	}
+}
+
+// WithErrorHandler 接收端返回错误时调用，参数为失败批次的大小
+func WithErrorHandler(fn func(size int, err error)) Option {
+	return func(c *config) {
+		c.onError = fn
+	}
+}
+
+// Stats 批处理器统计信息
+type Stats struct {
+	Items     int // 已提交的元素数
+	Batches   int // 已提交的批次数
+	BySize    int // 因攒够元素提交的批次数
+	ByLatency int // 因等待超时提交的批次数
+	Failed    int // 接收端返回错误的批次数
+}
+
+// Batcher 批处理器：把逐个到达的元素攒成批次，按数量或等待时间（先到者为准）提交给接收端
+//
+// 接收端在单个协程中依次调用，不会并发执行；
+// 接收端处理缓慢时，Add 会在缓冲区满后阻塞，把压力传回生产者。
+type Batcher[T any] struct {
+	config config
+	sink   Sink[T]
+
+	mutex  sync.RWMutex // 保护 closed，Add 持有读锁发送，Close 持有写锁关闭通道
+	closed bool
+
+	items   chan T
+	flushes chan chan struct{} // 手动提交请求，提交完成后关闭回复通道
+	done    chan struct{}      // 处理协程退出时关闭
+
+	statsMutex sync.Mutex
+	stats      Stats
+}
+
+// NewBatcher 创建批处理器并启动处理协程
+func NewBatcher[T any](sink Sink[T], opts ...Option) *Batcher[T] {
+	c := config{maxSize: 100, maxLatency: 100 * time.Millisecond, queueSize: -1}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.queueSize < 0 {
+		c.queueSize = c.maxSize
+	}
+
+	b := &Batcher[T]{
+		config:  c,
+		sink:    sink,
+		items:   make(chan T, c.queueSize),
+		flushes: make(chan chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// Add 添加一个元素，缓冲区已满时阻塞直到有空间或 ctx 被取消
+func (b *Batcher[T]) Add(ctx context.Context, item T) error {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+	select {
+	case b.items <- item:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Flush 立即提交已攒下的元素，等待接收端处理完成后返回
+func (b *Batcher[T]) Flush(ctx context.Context) error {
+	reply := make(chan struct{})
+	select {
+	case b.flushes <- reply:
+	case <-b.done:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-reply:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close 停止接收新元素，提交剩余的元素并等待处理协程退出
+// ctx 到期时不再等待，剩余的元素仍会在后台提交
+func (b *Batcher[T]) Close(ctx context.Context) error {
+	b.mutex.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.items)
+	}
+	b.mutex.Unlock()
+
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats 返回统计信息
+func (b *Batcher[T]) Stats() Stats {
+	b.statsMutex.Lock()
+	defer b.statsMutex.Unlock()
+
+	return b.stats
+}
+
+// flushReason 提交批次的原因
+type flushReason int
+
+const (
+	reasonSize flushReason = iota
+	reasonLatency
+	reasonManual
+)
+
+// run 处理协程：攒批并调用接收端
+func (b *Batcher[T]) run() {
+	defer close(b.done)
+
+	batch := make([]T, 0, b.config.maxSize)
+	timer := time.NewTimer(b.config.maxLatency)
+	timer.Stop()
+	var deadline <-chan time.Time // 批次为空时为 nil，不会触发
+
+	flush := func(reason flushReason) {
+		timer.Stop()
+		deadline = nil
+		if len(batch) == 0 {
+			return
+		}
+		b.submit(batch, reason)
+		// 接收端可能保留了批次的引用，使用新的切片
+		batch = make([]T, 0, b.config.maxSize)
+	}
+
+	add := func(item T) {
+		if len(batch) == 0 {
+			timer.Reset(b.config.maxLatency)
+			deadline = timer.C
+		}
+		batch = append(batch, item)
+		if len(batch) >= b.config.maxSize {
+			flush(reasonSize)
+		}
+	}
+
+	for {
+		select {
+		case item, ok := <-b.items:
+			if !ok {
+				flush(reasonManual)
+				return
+			}
+			add(item)
+		case <-deadline:
+			flush(reasonLatency)
+		case reply := <-b.flushes:
+			// 先取出缓冲区中已经添加的元素，保证 Flush 之前 Add 的元素都被提交
+			for drained := false; !drained; {
+				select {
+				case item, ok := <-b.items:
+					if ok {
+						add(item)
+					} else {
+						drained = true
+					}
+				default:
+					drained = true
+				}
+			}
+			flush(reasonManual)
+			close(reply)
+		}
+	}
+}
+
+// submit 调用接收端并更新统计信息
+func (b *Batcher[T]) submit(batch []T, reason flushReason) {
+	err := b.sink(context.Background(), batch)
+
+	b.statsMutex.Lock()
+	b.stats.Items += len(batch)
+	b.stats.Batches++
+	switch reason {
+	case reasonSize:
+		b.stats.BySize++
+	case reasonLatency:
+		b.stats.ByLatency++
+	}
+	if err != nil {
+		b.stats.Failed++
+	}
+	b.statsMutex.Unlock()
+
+	if err != nil && b.config.onError != nil {
+		b.config.onError(len(batch), err)
+	}
+}
@@ -0,0 +1,180 @@
+package batcher
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder 记录接收端收到的批次
+type recorder struct {
+	mutex   sync.Mutex
+	batches [][]int
+}
+
+func (r *recorder) sink(_ context.Context, batch []int) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.batches = append(r.batches, batch)
+	return nil
+}
+
+func (r *recorder) snapshot() [][]int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([][]int(nil), r.batches...)
+}
+
+func addAll(t *testing.T, b *Batcher[int], items ...int) {
+	t.Helper()
+	for _, item := range items {
+		assert.NoError(t, b.Add(context.Background(), item))
+	}
+}
+
+// TestFlushBySize 测试攒够元素后立即提交，Flush 提交不足一批的元素
+func TestFlushBySize(t *testing.T) {
+	var r recorder
+	b := NewBatcher(r.sink, WithMaxSize(3), WithMaxLatency(time.Hour))
+	defer b.Close(context.Background())
+
+	addAll(t, b, 1, 2, 3, 4, 5, 6, 7)
+	assert.Eventually(t, func() bool { return len(r.snapshot()) == 2 }, time.Second, time.Millisecond)
+	assert.NoError(t, b.Flush(context.Background()))
+
+	assert.Equal(t, [][]int{{1, 2, 3}, {4, 5, 6}, {7}}, r.snapshot())
+	assert.Equal(t, Stats{Items: 7, Batches: 3, BySize: 2}, b.Stats())
+
+	assert.NoError(t, b.Flush(context.Background()), "没有元素时 Flush 不提交空批次")
+	assert.Len(t, r.snapshot(), 3)
+}
+
+// TestFlushByLatency 测试没攒够时等待超时后提交
+func TestFlushByLatency(t *testing.T) {
+	var r recorder
+	b := NewBatcher(r.sink, WithMaxSize(100), WithMaxLatency(20*time.Millisecond))
+	defer b.Close(context.Background())
+
+	start := time.Now()
+	addAll(t, b, 1, 2)
+	assert.Eventually(t, func() bool { return len(r.snapshot()) == 1 }, time.Second, time.Millisecond)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	assert.Equal(t, [][]int{{1, 2}}, r.snapshot())
+
+	// 计时从新批次的第一个元素开始
+	addAll(t, b, 3)
+	assert.Eventually(t, func() bool { return len(r.snapshot()) == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, Stats{Items: 3, Batches: 2, ByLatency: 2}, b.Stats())
+}
+
+// TestBackpressure 测试接收端缓慢时 Add 阻塞
+func TestBackpressure(t *testing.T) {
+	gate := make(chan struct{})
+	var received atomic.Int32
+	b := NewBatcher(func(_ context.Context, batch []int) error {
+		<-gate
+		received.Add(int32(len(batch)))
+		return nil
+	}, WithMaxSize(1), WithQueueSize(2))
+
+	// 第一个元素进入接收端并阻塞，随后两个元素填满缓冲区
+	addAll(t, b, 1, 2, 3)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, b.Add(ctx, 4), context.DeadlineExceeded, "缓冲区已满时 Add 阻塞")
+
+	close(gate)
+	addAll(t, b, 4)
+	assert.NoError(t, b.Close(context.Background()))
+	assert.Equal(t, int32(4), received.Load())
+}
+
+// TestClose 测试关闭时提交剩余元素，关闭后拒绝新元素
+func TestClose(t *testing.T) {
+	errSink := errors.New("写入失败")
+	var failed []int
+	b := NewBatcher(func(context.Context, []int) error { return errSink },
+		WithMaxSize(10), WithMaxLatency(time.Hour),
+		WithErrorHandler(func(size int, err error) {
+			assert.ErrorIs(t, err, errSink)
+			failed = append(failed, size)
+		}))
+
+	addAll(t, b, 1, 2, 3)
+	assert.NoError(t, b.Close(context.Background()))
+	assert.NoError(t, b.Close(context.Background()), "重复关闭是安全的")
+	assert.Equal(t, []int{3}, failed)
+	assert.Equal(t, Stats{Items: 3, Batches: 1, Failed: 1}, b.Stats())
+
+	assert.ErrorIs(t, b.Add(context.Background(), 4), ErrClosed)
+	assert.ErrorIs(t, b.Flush(context.Background()), ErrClosed)
+}
+
+// TestConcurrentProducers 测试多个生产者并发添加时元素不丢失，批次不超过上限
+func TestConcurrentProducers(t *testing.T) {
+	var r recorder
+	b := NewBatcher(r.sink, WithMaxSize(16), WithMaxLatency(5*time.Millisecond))
+
+	var wg sync.WaitGroup
+	for p := 0; p < 8; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				assert.NoError(t, b.Add(context.Background(), p*100+i))
+			}
+		}(p)
+	}
+	wg.Wait()
+	assert.NoError(t, b.Close(context.Background()))
+
+	seen := make(map[int]bool)
+	for _, batch := range r.snapshot() {
+		assert.LessOrEqual(t, len(batch), 16)
+		for _, item := range batch {
+			assert.False(t, seen[item], "元素重复: %d", item)
+			seen[item] = true
+		}
+	}
+	assert.Len(t, seen, 800)
+}
+
+// TestDebounce 测试防抖器合并连续的触发，只在安静之后执行一次
+func TestDebounce(t *testing.T) {
+	type call struct {
+		value     string
+		coalesced int
+	}
+	calls := make(chan call, 10)
+	d := Debounce(30*time.Millisecond, func(value string, coalesced int) {
+		calls <- call{value, coalesced}
+	})
+
+	start := time.Now()
+	for _, v := range []string{"a", "ab", "abc"} {
+		d.Trigger(v)
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, call{"abc", 3}, <-calls)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond, "最后一次触发之后安静了 wait 时间才执行")
+	assert.Zero(t, d.Pending())
+
+	// Flush 立即执行，计时器不会再执行一次
+	d.Trigger("x")
+	assert.True(t, d.Flush())
+	assert.Equal(t, call{"x", 1}, <-calls)
+	assert.False(t, d.Flush())
+
+	// Stop 丢弃尚未执行的触发
+	d.Trigger("y")
+	d.Trigger("z")
+	assert.Equal(t, 2, d.Stop())
+	d.Trigger("ignored")
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, calls)
+}
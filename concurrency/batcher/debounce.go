@@ -0,0 +1,111 @@
+package batcher
+
+import (
+	"sync"
+	"time"
+)
+
+// Debouncer 防抖器：在一连串的触发中，只在最后一次触发之后安静了 wait 时间才执行一次（后沿触发）
+//
+// 每次触发都会重新计时，执行时使用最后一次触发的值，中间的值被合并掉。
+// 适用于搜索框联想、窗口大小变化、配置文件监听等高频事件。
+type Debouncer[T any] struct {
+	wait time.Duration
+	fn   func(value T, coalesced int)
+
+	mutex    sync.Mutex
+	timer    *time.Timer
+	value    T
+	deadline time.Time // 最后一次触发之后 wait 时间
+	pending  int       // 自上次执行以来的触发次数
+	stopped  bool      // 停止后忽略触发
+}
+
+// Debounce 创建防抖器，fn 的参数为最后一次触发的值和被合并的触发次数
+// fn 在计时器的协程中执行，同一防抖器的 fn 可能并发执行，需要时由 fn 自行同步
+func Debounce[T any](wait time.Duration, fn func(value T, coalesced int)) *Debouncer[T] {
+	return &Debouncer[T]{wait: wait, fn: fn}
+}
+
+// Trigger 触发一次，重新开始计时
+func (d *Debouncer[T]) Trigger(value T) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.stopped {
+		return
+	}
+	d.value = value
+	d.pending++
+	d.deadline = time.Now().Add(d.wait)
+	if d.timer == nil {
+		d.timer = time.AfterFunc(d.wait, d.fire)
+	} else {
+		d.timer.Reset(d.wait)
+	}
+}
+
+// Flush 不再等待，立即执行尚未执行的触发，没有待执行的触发时返回 false
+func (d *Debouncer[T]) Flush() bool {
+	d.mutex.Lock()
+	value, coalesced, ok := d.takeLocked()
+	d.mutex.Unlock()
+
+	if ok {
+		d.fn(value, coalesced)
+	}
+	return ok
+}
+
+// Stop 停止防抖器，丢弃尚未执行的触发，返回被丢弃的触发次数
+func (d *Debouncer[T]) Stop() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.stopped = true
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	dropped := d.pending
+	d.pending = 0
+	return dropped
+}
+
+// Pending 返回尚未执行的触发次数
+func (d *Debouncer[T]) Pending() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.pending
+}
+
+// fire 计时结束时执行
+func (d *Debouncer[T]) fire() {
+	d.mutex.Lock()
+	// 计时器触发后、获得锁之前可能又有新的触发，此时继续等待剩余的时间
+	if remaining := time.Until(d.deadline); remaining > 0 && d.pending > 0 && !d.stopped {
+		d.timer.Reset(remaining)
+		d.mutex.Unlock()
+		return
+	}
+	value, coalesced, ok := d.takeLocked()
+	d.mutex.Unlock()
+
+	if ok {
+		d.fn(value, coalesced)
+	}
+}
+
+// takeLocked 取出待执行的值并清空计数，调用者需持有锁
+func (d *Debouncer[T]) takeLocked() (T, int, bool) {
+	var zero T
+	if d.pending == 0 {
+		return zero, 0, false
+	}
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	value, coalesced := d.value, d.pending
+	d.value, d.pending = zero, 0
+	return value, coalesced, true
+}
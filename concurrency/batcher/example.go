@@ -0,0 +1,41 @@
+package batcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RunExample 运行批处理和防抖示例
+func RunExample() {
+	// 日志批量写入：攒够 5 条或等待 50 毫秒就写一次
+	sink := func(_ context.Context, lines []string) error {
+		fmt.Printf("写入 %d 条日志: %v\n", len(lines), lines)
+		time.Sleep(10 * time.Millisecond) // 模拟较慢的存储
+		return nil
+	}
+	b := NewBatcher(sink, WithMaxSize(5), WithMaxLatency(50*time.Millisecond))
+
+	ctx := context.Background()
+	for i := 1; i <= 12; i++ {
+		b.Add(ctx, fmt.Sprintf("log-%d", i))
+	}
+	time.Sleep(80 * time.Millisecond) // 剩余的 2 条在等待超时后写入
+	b.Add(ctx, "log-13")
+	b.Close(ctx) // 关闭时写入剩余的日志
+
+	stats := b.Stats()
+	fmt.Printf("共 %d 批 %d 条，攒满提交 %d 批，超时提交 %d 批\n", stats.Batches, stats.Items, stats.BySize, stats.ByLatency)
+
+	// 搜索联想：用户停止输入 30 毫秒后才发起查询
+	done := make(chan struct{})
+	search := Debounce(30*time.Millisecond, func(query string, coalesced int) {
+		fmt.Printf("查询 %q（合并了 %d 次输入）\n", query, coalesced)
+		close(done)
+	})
+	for _, query := range []string{"g", "go", "gol", "gola", "golang"} {
+		search.Trigger(query)
+		time.Sleep(5 * time.Millisecond)
+	}
+	<-done
+}
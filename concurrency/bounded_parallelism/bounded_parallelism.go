@@ -35,6 +35,9 @@ type BoundedExecutor[T any] struct {
 	cancel    context.CancelFunc // 取消函数
 	closed    bool               // 是否已关闭
 	mu        sync.Mutex         // 保护 closed 字段的互斥锁
+
+	panicCounters panicCounters // panic 处理策略与计数
+	taskCounters  taskCounters  // 任务统计
 }
 
 // NewBoundedExecutor 创建一个新的有界执行器
@@ -64,26 +67,33 @@ func NewBoundedExecutor[T any](maxConcurrent int, queueSize int) *BoundedExecuto
 // startWorkers 启动工作协程池
 func (e *BoundedExecutor[T]) startWorkers(count int) {
 	for i := 0; i < count; i++ {
-		e.wg.Add(1)
-		go func(workerID int) {
-			defer e.wg.Done()
-			for {
-				select {
-				case task, ok := <-e.tasks:
-					if !ok {
-						return // 任务通道已关闭，退出
-					}
-					e.executeTask(workerID, task)
-				case <-e.ctx.Done():
-					return // 上下文被取消，退出
+		e.startWorker(i + 1)
+	}
+}
+
+// startWorker 启动一个工作协程
+func (e *BoundedExecutor[T]) startWorker(workerID int) {
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		for {
+			select {
+			case task, ok := <-e.tasks:
+				if !ok {
+					return // 任务通道已关闭，退出
+				}
+				if panicked := e.executeTask(workerID, task); panicked && e.handlePanic(workerID) {
+					return
 				}
+			case <-e.ctx.Done():
+				return // 上下文被取消，退出
 			}
-		}(i + 1)
-	}
+		}
+	}()
 }
 
-// executeTask 执行单个任务并处理结果
-func (e *BoundedExecutor[T]) executeTask(workerID int, task Task[T]) {
+// executeTask 执行单个任务并处理结果，返回任务是否发生了 panic
+func (e *BoundedExecutor[T]) executeTask(workerID int, task Task[T]) (panicked bool) {
 	e.semaphore <- struct{}{}        // 获取信号量
 	defer func() { <-e.semaphore }() // 释放信号量
 
@@ -98,26 +108,38 @@ func (e *BoundedExecutor[T]) executeTask(workerID int, task Task[T]) {
 		taskCtx, cancel := context.WithTimeout(e.ctx, task.Timeout)
 		defer cancel()
 
-		// 在单独的goroutine中执行任务
-		done := make(chan struct{})
+		// 在单独的goroutine中执行任务，超时后任务协程的结果被丢弃
+		type outcome struct {
+			value T
+			err   error
+		}
+		done := make(chan outcome, 1)
 		go func() {
-			result.Value, result.Err = task.Execute()
-			close(done)
+			value, err := e.runTask(task)
+			done <- outcome{value: value, err: err}
 		}()
 
 		// 等待任务完成或超时
 		select {
-		case <-done:
+		case out := <-done:
 			// 任务正常完成
+			result.Value, result.Err = out.value, out.err
 		case <-taskCtx.Done():
 			result.Err = errors.New("任务执行超时")
 		}
 	} else {
 		// 无超时的任务直接执行
-		result.Value, result.Err = task.Execute()
+		result.Value, result.Err = e.runTask(task)
+	}
+
+	var panicErr *PanicError
+	if errors.As(result.Err, &panicErr) {
+		panicked = true
+		fmt.Printf("工作者 %d 执行任务 %s 时发生 panic: %v\n", workerID, task.ID, panicErr.Value)
 	}
 
 	result.EndTime = time.Now()
+	e.recordResult(result)
 
 	// 安全地发送结果，防止因通道关闭导致panic
 	sendResult := func() (sent bool) {
@@ -151,6 +173,7 @@ func (e *BoundedExecutor[T]) executeTask(workerID int, task Task[T]) {
 
 	fmt.Printf("工作者 %d 完成任务: %s, 耗时: %v, 结果已发送: %v\n",
 		workerID, task.ID, result.EndTime.Sub(result.StartTime), sent)
+	return panicked
 }
 
 // Submit 提交一个任务到执行队列
@@ -166,6 +189,7 @@ func (e *BoundedExecutor[T]) Submit(task Task[T]) error {
 	// 使用非阻塞发送尝试提交任务
 	select {
 	case e.tasks <- task:
+		e.taskCounters.submitted.Add(1)
 		return nil
 	case <-e.ctx.Done():
 		return errors.New("执行器已关闭")
@@ -174,6 +198,7 @@ func (e *BoundedExecutor[T]) Submit(task Task[T]) error {
 		// 阻塞发送，但仍然可以被取消
 		select {
 		case e.tasks <- task:
+			e.taskCounters.submitted.Add(1)
 			return nil
 		case <-e.ctx.Done():
 			return errors.New("执行器已关闭")
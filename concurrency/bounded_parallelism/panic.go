@@ -0,0 +1,121 @@
+package bounded_parallelism
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+// ErrTaskPanicked 表示任务执行过程中发生了 panic，可以通过 errors.Is 判断
+var ErrTaskPanicked = errors.New("任务发生 panic")
+
+// PanicPolicy 任务发生 panic 后执行器的处理策略
+// 无论哪种策略，panic 都会被恢复并转换为该任务结果中的 *PanicError
+type PanicPolicy int32
+
+const (
+	// PanicFailTask 只让该任务失败，工作者继续处理后续任务（默认）
+	PanicFailTask PanicPolicy = iota
+	// PanicRestartWorker 任务失败后退出当前工作者并启动一个新的工作者替代它，
+	// 适合任务可能破坏工作者协程局部状态的场景
+	PanicRestartWorker
+	// PanicShutdown 任务失败后立即关闭执行器，等同于调用 ShutdownNow
+	PanicShutdown
+)
+
+// String 返回策略名称
+func (p PanicPolicy) String() string {
+	switch p {
+	case PanicFailTask:
+		return "任务失败"
+	case PanicRestartWorker:
+		return "重启工作者"
+	case PanicShutdown:
+		return "关闭执行器"
+	default:
+		return "未知策略"
+	}
+}
+
+// PanicError 任务 panic 转换成的错误，保留 panic 的值和发生时的调用栈
+type PanicError struct {
+	TaskID string // 发生 panic 的任务
+	Value  any    // recover 得到的值
+	Stack  []byte // panic 发生时的调用栈
+}
+
+// Error 返回错误描述，不包含调用栈
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("任务 %s 发生 panic: %v", e.TaskID, e.Value)
+}
+
+// Unwrap 使 errors.Is(err, ErrTaskPanicked) 成立；panic 的值本身是 error 时也可以被匹配
+func (e *PanicError) Unwrap() []error {
+	if err, ok := e.Value.(error); ok {
+		return []error{ErrTaskPanicked, err}
+	}
+	return []error{ErrTaskPanicked}
+}
+
+// PanicStats 执行器观察到的 panic 统计
+type PanicStats struct {
+	Panics         uint64 // 被恢复的 panic 总数，包括任务超时后才发生的 panic
+	WorkerRestarts uint64 // 因 PanicRestartWorker 策略重启的工作者数量
+}
+
+// panicCounters 执行器内部的 panic 计数器
+type panicCounters struct {
+	policy   atomic.Int32
+	panics   atomic.Uint64
+	restarts atomic.Uint64
+}
+
+// SetPanicPolicy 设置任务 panic 后的处理策略，对之后完成的任务生效
+func (e *BoundedExecutor[T]) SetPanicPolicy(policy PanicPolicy) {
+	e.panicCounters.policy.Store(int32(policy))
+}
+
+// PanicPolicy 返回当前的 panic 处理策略
+func (e *BoundedExecutor[T]) PanicPolicy() PanicPolicy {
+	return PanicPolicy(e.panicCounters.policy.Load())
+}
+
+// PanicStats 返回 panic 统计的快照
+func (e *BoundedExecutor[T]) PanicStats() PanicStats {
+	return PanicStats{
+		Panics:         e.panicCounters.panics.Load(),
+		WorkerRestarts: e.panicCounters.restarts.Load(),
+	}
+}
+
+// runTask 执行任务函数，把 panic 恢复为 *PanicError
+func (e *BoundedExecutor[T]) runTask(task Task[T]) (value T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e.panicCounters.panics.Add(1)
+			var zero T
+			value = zero
+			err = &PanicError{TaskID: task.ID, Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return task.Execute()
+}
+
+// handlePanic 按策略处理发生过 panic 的工作者，返回工作者是否应当退出
+func (e *BoundedExecutor[T]) handlePanic(workerID int) (exit bool) {
+	switch e.PanicPolicy() {
+	case PanicRestartWorker:
+		e.panicCounters.restarts.Add(1)
+		fmt.Printf("工作者 %d 因任务 panic 被重启\n", workerID)
+		// 先启动替代者再退出，保证 WaitGroup 计数不会在中途归零
+		e.startWorker(workerID)
+		return true
+	case PanicShutdown:
+		fmt.Printf("工作者 %d 的任务发生 panic，执行器即将关闭\n", workerID)
+		e.ShutdownNow()
+		return true
+	default:
+		return false
+	}
+}
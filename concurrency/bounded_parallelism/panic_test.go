@@ -0,0 +1,146 @@
+package bounded_parallelism
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// collectResults 从结果通道读取 n 个结果，超时则测试失败
+func collectResults[T any](t *testing.T, executor *BoundedExecutor[T], n int) map[string]Result[T] {
+	t.Helper()
+	results := make(map[string]Result[T], n)
+	timeout := time.After(2 * time.Second)
+	for len(results) < n {
+		select {
+		case result, ok := <-executor.Results():
+			if !ok {
+				t.Fatalf("结果通道提前关闭，只收到 %d 个结果", len(results))
+			}
+			results[result.TaskID] = result
+		case <-timeout:
+			t.Fatalf("等待结果超时，只收到 %d 个结果", len(results))
+		}
+	}
+	return results
+}
+
+// TestPanicFailsTask 测试默认策略下 panic 只让该任务失败，工作者继续处理后续任务
+func TestPanicFailsTask(t *testing.T) {
+	executor := NewBoundedExecutor[int](1, 5)
+	assert.Equal(t, PanicFailTask, executor.PanicPolicy())
+
+	errBoom := errors.New("boom")
+	assert.NoError(t, executor.Submit(Task[int]{ID: "panic-string", Execute: func() (int, error) { panic("崩溃了") }}))
+	assert.NoError(t, executor.Submit(Task[int]{ID: "panic-error", Execute: func() (int, error) { panic(errBoom) }}))
+	assert.NoError(t, executor.Submit(Task[int]{ID: "ok", Execute: func() (int, error) { return 42, nil }}))
+
+	results := collectResults(t, executor, 3)
+	executor.Shutdown()
+
+	var panicErr *PanicError
+	err := results["panic-string"].Err
+	assert.ErrorIs(t, err, ErrTaskPanicked)
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("错误应为 *PanicError，实际为 %T", err)
+	}
+	assert.Equal(t, "panic-string", panicErr.TaskID)
+	assert.Equal(t, "崩溃了", panicErr.Value)
+	assert.Contains(t, string(panicErr.Stack), "TestPanicFailsTask", "调用栈应包含 panic 发生的位置")
+	assert.Equal(t, "任务 panic-string 发生 panic: 崩溃了", err.Error())
+
+	// panic 的值本身是 error 时可以直接匹配
+	assert.ErrorIs(t, results["panic-error"].Err, ErrTaskPanicked)
+	assert.ErrorIs(t, results["panic-error"].Err, errBoom)
+
+	// 同一个工作者继续执行了后续任务
+	assert.NoError(t, results["ok"].Err)
+	assert.Equal(t, 42, results["ok"].Value)
+
+	assert.Equal(t, PanicStats{Panics: 2}, executor.PanicStats())
+}
+
+// TestPanicRestartWorker 测试重启策略下工作者被替换，执行器继续可用
+func TestPanicRestartWorker(t *testing.T) {
+	executor := NewBoundedExecutor[string](2, 10)
+	executor.SetPanicPolicy(PanicRestartWorker)
+
+	for i := 1; i <= 3; i++ {
+		id := fmt.Sprintf("panic-%d", i)
+		assert.NoError(t, executor.Submit(Task[string]{ID: id, Execute: func() (string, error) { panic(id) }}))
+	}
+	for i := 1; i <= 4; i++ {
+		id := fmt.Sprintf("ok-%d", i)
+		assert.NoError(t, executor.Submit(Task[string]{ID: id, Execute: func() (string, error) { return id, nil }}))
+	}
+
+	results := collectResults(t, executor, 7)
+	executor.Shutdown() // 重启的工作者也会被等待，结果通道随后关闭
+
+	for i := 1; i <= 3; i++ {
+		assert.ErrorIs(t, results[fmt.Sprintf("panic-%d", i)].Err, ErrTaskPanicked)
+	}
+	for i := 1; i <= 4; i++ {
+		id := fmt.Sprintf("ok-%d", i)
+		assert.NoError(t, results[id].Err)
+		assert.Equal(t, id, results[id].Value)
+	}
+	assert.Equal(t, PanicStats{Panics: 3, WorkerRestarts: 3}, executor.PanicStats())
+
+	_, ok := <-executor.Results()
+	assert.False(t, ok, "Shutdown 后结果通道应关闭")
+}
+
+// TestPanicShutdown 测试关闭策略下 panic 会关闭执行器
+func TestPanicShutdown(t *testing.T) {
+	executor := NewBoundedExecutor[int](1, 5)
+	executor.SetPanicPolicy(PanicShutdown)
+
+	assert.NoError(t, executor.Submit(Task[int]{ID: "panic", Execute: func() (int, error) { panic("致命错误") }}))
+
+	results := collectResults(t, executor, 1)
+	assert.ErrorIs(t, results["panic"].Err, ErrTaskPanicked)
+
+	// 结果通道随执行器关闭
+	select {
+	case _, ok := <-executor.Results():
+		assert.False(t, ok, "执行器关闭后不应再有结果")
+	case <-time.After(time.Second):
+		t.Fatal("执行器应在任务 panic 后关闭")
+	}
+	assert.Error(t, executor.Submit(Task[int]{ID: "late", Execute: func() (int, error) { return 0, nil }}))
+	assert.Equal(t, PanicStats{Panics: 1}, executor.PanicStats())
+
+	executor.Shutdown() // 重复关闭是安全的
+}
+
+// TestPanicWithTimeout 测试带超时的任务 panic 同样被恢复，超时后才发生的 panic 只被计数
+func TestPanicWithTimeout(t *testing.T) {
+	executor := NewBoundedExecutor[int](2, 5)
+
+	assert.NoError(t, executor.Submit(Task[int]{
+		ID:      "panic-fast",
+		Timeout: time.Second,
+		Execute: func() (int, error) { panic("立即崩溃") },
+	}))
+	assert.NoError(t, executor.Submit(Task[int]{
+		ID:      "panic-late",
+		Timeout: 20 * time.Millisecond,
+		Execute: func() (int, error) {
+			time.Sleep(60 * time.Millisecond)
+			panic(io.ErrUnexpectedEOF)
+		},
+	}))
+
+	results := collectResults(t, executor, 2)
+	assert.ErrorIs(t, results["panic-fast"].Err, ErrTaskPanicked)
+	assert.EqualError(t, results["panic-late"].Err, "任务执行超时")
+
+	assert.Eventually(t, func() bool { return executor.PanicStats().Panics == 2 }, time.Second, 10*time.Millisecond)
+	executor.Shutdown()
+	assert.Equal(t, PanicRestartWorker.String(), "重启工作者")
+}
@@ -0,0 +1,38 @@
+package bounded_parallelism
+
+import "sync/atomic"
+
+// Stats 执行器的任务统计
+type Stats struct {
+	Submitted uint64 // 成功提交的任务数
+	Completed uint64 // 执行完成的任务数，包括失败的任务
+	Failed    uint64 // 返回错误、超时或发生 panic 的任务数
+	Queued    int    // 在队列中等待执行的任务数
+	Running   int    // 正在执行的任务数
+}
+
+// taskCounters 执行器内部的任务计数器
+type taskCounters struct {
+	submitted atomic.Uint64
+	completed atomic.Uint64
+	failed    atomic.Uint64
+}
+
+// Stats 返回任务统计的快照，各字段分别读取，并发执行时彼此之间不保证严格一致
+func (e *BoundedExecutor[T]) Stats() Stats {
+	return Stats{
+		Submitted: e.taskCounters.submitted.Load(),
+		Completed: e.taskCounters.completed.Load(),
+		Failed:    e.taskCounters.failed.Load(),
+		Queued:    len(e.tasks),
+		Running:   len(e.semaphore),
+	}
+}
+
+// recordResult 记录一个任务的执行结果
+func (e *BoundedExecutor[T]) recordResult(result Result[T]) {
+	e.taskCounters.completed.Add(1)
+	if result.Err != nil {
+		e.taskCounters.failed.Add(1)
+	}
+}
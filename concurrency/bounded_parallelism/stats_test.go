@@ -0,0 +1,40 @@
+package bounded_parallelism
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestStats 测试任务统计：提交、完成、失败、排队和执行中的任务数
+func TestStats(t *testing.T) {
+	executor := NewBoundedExecutor[int](1, 5)
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	assert.NoError(t, executor.Submit(Task[int]{ID: "blocked", Execute: func() (int, error) {
+		close(started)
+		<-release
+		return 1, nil
+	}}))
+	<-started
+	assert.NoError(t, executor.Submit(Task[int]{ID: "failed", Execute: func() (int, error) { return 0, errors.New("失败") }}))
+	assert.NoError(t, executor.Submit(Task[int]{ID: "panic", Execute: func() (int, error) { panic("崩溃了") }}))
+
+	stats := executor.Stats()
+	assert.Equal(t, uint64(3), stats.Submitted)
+	assert.Equal(t, uint64(0), stats.Completed)
+	assert.Equal(t, 1, stats.Running)
+	assert.Equal(t, 2, stats.Queued)
+
+	close(release)
+	collectResults(t, executor, 3)
+	executor.Shutdown()
+
+	stats = executor.Stats()
+	assert.Equal(t, uint64(3), stats.Completed)
+	assert.Equal(t, uint64(2), stats.Failed, "错误和 panic 都计为失败")
+	assert.Equal(t, 0, stats.Running)
+	assert.Equal(t, 0, stats.Queued)
+}
@@ -0,0 +1,338 @@
+package leader_election
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// 选举相关错误
+var (
+	ErrDuplicateCandidate = errors.New("候选者已经加入选举")
+	ErrElectorStopped     = errors.New("选举器已停止")
+)
+
+// Config 选举配置
+type Config struct {
+	LeaseDuration time.Duration // 租约有效期，领导者超过这个时间没有续约，其他候选者才能接任
+	RenewInterval time.Duration // 领导者续约的间隔，必须小于租约有效期
+	RetryInterval time.Duration // 跟随者尝试获取租约的间隔
+
+	OnElected  func(id string, term uint64) // 候选者成为领导者时调用
+	OnResigned func(id string, term uint64) // 领导者卸任时调用：主动退出或续约失败；崩溃的领导者不会调用
+}
+
+// withDefaults 填充默认值并保证续约间隔小于租约有效期
+func (c Config) withDefaults() Config {
+	if c.LeaseDuration <= 0 {
+		c.LeaseDuration = time.Second
+	}
+	if c.RenewInterval <= 0 || c.RenewInterval >= c.LeaseDuration {
+		c.RenewInterval = c.LeaseDuration / 3
+	}
+	if c.RetryInterval <= 0 {
+		c.RetryInterval = c.RenewInterval
+	}
+	return c
+}
+
+// lease 领导者租约
+type lease struct {
+	holder  string    // 持有者，空字符串表示没有领导者
+	term    uint64    // 任期，每次换届加一，可以作为防护令牌（fencing token）拒绝旧领导者的写入
+	expires time.Time // 到期时间
+}
+
+// Elector 进程内的领导者选举：多个候选者竞争同一份租约，持有租约的候选者是领导者
+//
+// 领导者定期续约；领导者崩溃或卡住导致租约过期后，跟随者获取租约接任。
+// 任意时刻最多只有一个候选者的 IsLeader 返回 true。
+type Elector struct {
+	config Config
+
+	mutex      sync.Mutex
+	lease      lease
+	candidates map[string]*Candidate
+	stopped    bool
+
+	now func() time.Time // 时间来源，便于测试
+}
+
+// NewElector 创建选举器
+func NewElector(config Config) *Elector {
+	return &Elector{
+		config:     config.withDefaults(),
+		candidates: make(map[string]*Candidate),
+		now:        time.Now,
+	}
+}
+
+// Join 加入一个候选者并开始参与选举
+func (e *Elector) Join(id string) (*Candidate, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.stopped {
+		return nil, ErrElectorStopped
+	}
+	if _, exists := e.candidates[id]; exists {
+		return nil, fmt.Errorf("%w: %s", ErrDuplicateCandidate, id)
+	}
+
+	c := &Candidate{
+		id:      id,
+		elector: e,
+		resign:  make(chan struct{}),
+		kill:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	e.candidates[id] = c
+	go c.run()
+	return c, nil
+}
+
+// Leader 返回当前的领导者和任期，租约已过期时返回 false
+func (e *Elector) Leader() (string, uint64, bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if !e.validLocked() {
+		return "", e.lease.term, false
+	}
+	return e.lease.holder, e.lease.term, true
+}
+
+// Candidates 返回仍在参与选举的候选者数量
+func (e *Elector) Candidates() int {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return len(e.candidates)
+}
+
+// Stop 让所有候选者主动退出，领导者会释放租约并调用 OnResigned
+func (e *Elector) Stop() {
+	e.mutex.Lock()
+	e.stopped = true
+	candidates := make([]*Candidate, 0, len(e.candidates))
+	for _, c := range e.candidates {
+		candidates = append(candidates, c)
+	}
+	e.mutex.Unlock()
+
+	for _, c := range candidates {
+		c.Resign()
+	}
+}
+
+// validLocked 租约当前是否有效，调用者需持有锁
+func (e *Elector) validLocked() bool {
+	return e.lease.holder != "" && e.now().Before(e.lease.expires)
+}
+
+// tryAcquire 没有有效租约时获取租约，成功时返回新的任期
+func (e *Elector) tryAcquire(id string) (uint64, bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.validLocked() {
+		return 0, false
+	}
+	e.lease = lease{holder: id, term: e.lease.term + 1, expires: e.now().Add(e.config.LeaseDuration)}
+	return e.lease.term, true
+}
+
+// renew 在租约到期前续约，租约已过期或已被接任时返回 false
+func (e *Elector) renew(id string, term uint64) bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if !e.holdsLocked(id, term) {
+		return false
+	}
+	e.lease.expires = e.now().Add(e.config.LeaseDuration)
+	return true
+}
+
+// release 主动释放租约，让跟随者无需等待租约到期即可接任
+func (e *Elector) release(id string, term uint64) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.holdsLocked(id, term) {
+		e.lease.holder = ""
+	}
+}
+
+// holds 候选者是否持有指定任期的有效租约
+func (e *Elector) holds(id string, term uint64) bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return e.holdsLocked(id, term)
+}
+
+// holdsLocked 同 holds，调用者需持有锁
+func (e *Elector) holdsLocked(id string, term uint64) bool {
+	return e.validLocked() && e.lease.holder == id && e.lease.term == term
+}
+
+// leave 候选者退出时从选举器中移除
+func (e *Elector) leave(c *Candidate) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.candidates[c.id] == c {
+		delete(e.candidates, c.id)
+	}
+}
+
+// Candidate 参与选举的候选者
+type Candidate struct {
+	id      string
+	elector *Elector
+
+	mutex sync.Mutex
+	term  uint64 // 当选时的任期，0 表示不是领导者
+
+	resignOnce sync.Once
+	killOnce   sync.Once
+	resign     chan struct{} // 主动退出
+	kill       chan struct{} // 模拟崩溃
+	done       chan struct{} // 选举协程退出时关闭
+}
+
+// ID 返回候选者标识
+func (c *Candidate) ID() string {
+	return c.id
+}
+
+// IsLeader 候选者当前是否是领导者，同时检查租约是否仍然有效
+func (c *Candidate) IsLeader() bool {
+	term := c.Term()
+	return term != 0 && c.elector.holds(c.id, term)
+}
+
+// Term 返回候选者当选时的任期，不是领导者时返回 0
+func (c *Candidate) Term() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.term
+}
+
+// Resign 主动退出选举：领导者释放租约并调用 OnResigned，跟随者直接退出
+func (c *Candidate) Resign() {
+	c.resignOnce.Do(func() { close(c.resign) })
+	<-c.done
+}
+
+// Kill 模拟候选者崩溃：选举协程立即停止，不释放租约也不调用任何回调，
+// 跟随者要等租约到期才能接任
+func (c *Candidate) Kill() {
+	c.killOnce.Do(func() { close(c.kill) })
+	<-c.done
+}
+
+// Done 返回选举协程退出时关闭的通道
+func (c *Candidate) Done() <-chan struct{} {
+	return c.done
+}
+
+// run 候选者的选举循环
+func (c *Candidate) run() {
+	defer close(c.done)
+	defer c.elector.leave(c)
+
+	config := c.elector.config
+	for {
+		wait := config.RetryInterval
+		if term := c.Term(); term == 0 {
+			if term, ok := c.elector.tryAcquire(c.id); ok {
+				c.setTerm(term)
+				c.callback(config.OnElected, term)
+				wait = config.RenewInterval
+			}
+		} else if c.elector.renew(c.id, term) {
+			wait = config.RenewInterval
+		} else {
+			// 续约失败：租约已经过期，可能已经被其他候选者接任
+			c.setTerm(0)
+			c.callback(config.OnResigned, term)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-c.kill:
+			// 崩溃的进程不再对外提供服务，但租约保留到自然过期
+			timer.Stop()
+			c.setTerm(0)
+			return
+		case <-c.resign:
+			timer.Stop()
+			if term := c.Term(); term != 0 {
+				c.elector.release(c.id, term)
+				c.setTerm(0)
+				c.callback(config.OnResigned, term)
+			}
+			return
+		}
+	}
+}
+
+// setTerm 设置当选的任期
+func (c *Candidate) setTerm(term uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.term = term
+}
+
+// callback 调用回调函数
+func (c *Candidate) callback(fn func(string, uint64), term uint64) {
+	if fn != nil {
+		fn(c.id, term)
+	}
+}
+
+// RunExample 运行领导者选举示例
+func RunExample() {
+	elector := NewElector(Config{
+		LeaseDuration: 150 * time.Millisecond,
+		RenewInterval: 50 * time.Millisecond,
+		RetryInterval: 20 * time.Millisecond,
+		OnElected: func(id string, term uint64) {
+			fmt.Printf("%s 当选为领导者，任期 %d\n", id, term)
+		},
+		OnResigned: func(id string, term uint64) {
+			fmt.Printf("%s 卸任，任期 %d\n", id, term)
+		},
+	})
+	defer elector.Stop()
+
+	candidates := make(map[string]*Candidate)
+	for _, id := range []string{"worker-1", "worker-2", "worker-3"} {
+		c, _ := elector.Join(id)
+		candidates[id] = c
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	leader, term, _ := elector.Leader()
+	fmt.Printf("当前领导者: %s，任期 %d\n", leader, term)
+
+	// 领导者崩溃：不会释放租约，跟随者在租约到期后接任
+	fmt.Printf("%s 崩溃\n", leader)
+	candidates[leader].Kill()
+	time.Sleep(250 * time.Millisecond)
+
+	// 新的领导者主动退出：立即释放租约，跟随者无需等待租约到期
+	if next, _, ok := elector.Leader(); ok {
+		candidates[next].Resign()
+		time.Sleep(50 * time.Millisecond)
+	}
+	if last, term, ok := elector.Leader(); ok {
+		fmt.Printf("最终领导者: %s，任期 %d\n", last, term)
+	}
+}
@@ -0,0 +1,265 @@
+package leader_election
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock 手动推进的时钟，用于精确控制租约到期
+type fakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// events 记录回调
+type events struct {
+	mutex    sync.Mutex
+	elected  []string
+	resigned []string
+	terms    []uint64
+}
+
+func (e *events) config(c Config) Config {
+	c.OnElected = func(id string, term uint64) {
+		e.mutex.Lock()
+		defer e.mutex.Unlock()
+		e.elected = append(e.elected, id)
+		e.terms = append(e.terms, term)
+	}
+	c.OnResigned = func(id string, term uint64) {
+		e.mutex.Lock()
+		defer e.mutex.Unlock()
+		e.resigned = append(e.resigned, fmt.Sprintf("%s@%d", id, term))
+	}
+	return c
+}
+
+func (e *events) snapshot() (elected, resigned []string, terms []uint64) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return append([]string(nil), e.elected...), append([]string(nil), e.resigned...), append([]uint64(nil), e.terms...)
+}
+
+// leaderOf 返回候选者中当前的领导者，没有领导者时返回 nil
+func leaderOf(candidates []*Candidate) *Candidate {
+	for _, c := range candidates {
+		if c.IsLeader() {
+			return c
+		}
+	}
+	return nil
+}
+
+func joinAll(t *testing.T, elector *Elector, ids ...string) []*Candidate {
+	t.Helper()
+	candidates := make([]*Candidate, 0, len(ids))
+	for _, id := range ids {
+		c, err := elector.Join(id)
+		assert.NoError(t, err)
+		candidates = append(candidates, c)
+	}
+	return candidates
+}
+
+// TestSingleLeaderAndResign 测试只有一个领导者，主动退出的领导者立即释放租约
+func TestSingleLeaderAndResign(t *testing.T) {
+	var log events
+	elector := NewElector(log.config(Config{
+		LeaseDuration: 10 * time.Second, // 足够长，接任只可能来自主动释放
+		RenewInterval: 5 * time.Millisecond,
+		RetryInterval: 2 * time.Millisecond,
+	}))
+	candidates := joinAll(t, elector, "a", "b", "c")
+
+	assert.Eventually(t, func() bool { return leaderOf(candidates) != nil }, time.Second, time.Millisecond)
+	first := leaderOf(candidates)
+	id, term, ok := elector.Leader()
+	assert.True(t, ok)
+	assert.Equal(t, first.ID(), id)
+	assert.Equal(t, uint64(1), term)
+
+	_, err := elector.Join(first.ID())
+	assert.ErrorIs(t, err, ErrDuplicateCandidate)
+
+	first.Resign()
+	assert.False(t, first.IsLeader())
+	assert.Eventually(t, func() bool { return leaderOf(candidates) != nil }, time.Second, time.Millisecond)
+	second := leaderOf(candidates)
+	assert.NotEqual(t, first.ID(), second.ID())
+	assert.Equal(t, uint64(2), second.Term())
+	assert.Equal(t, 2, elector.Candidates())
+
+	elector.Stop()
+	_, err = elector.Join("d")
+	assert.ErrorIs(t, err, ErrElectorStopped)
+
+	elected, resigned, terms := log.snapshot()
+	assert.Equal(t, []string{first.ID(), second.ID()}, elected)
+	assert.Equal(t, []uint64{1, 2}, terms)
+	assert.Equal(t, []string{first.ID() + "@1", second.ID() + "@2"}, resigned)
+	_, _, ok = elector.Leader()
+	assert.False(t, ok, "停止后没有领导者")
+}
+
+// TestKilledLeaderLeaseExpiry 测试崩溃的领导者不释放租约，跟随者在租约到期后才接任
+func TestKilledLeaderLeaseExpiry(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	var log events
+	elector := NewElector(log.config(Config{
+		LeaseDuration: time.Minute,
+		RenewInterval: time.Millisecond,
+		RetryInterval: time.Millisecond,
+	}))
+	elector.now = clock.Now
+	defer elector.Stop()
+	candidates := joinAll(t, elector, "a", "b")
+
+	assert.Eventually(t, func() bool { return leaderOf(candidates) != nil }, time.Second, time.Millisecond)
+	crashed := leaderOf(candidates)
+	crashed.Kill()
+
+	// 租约仍然有效，跟随者无法接任
+	time.Sleep(20 * time.Millisecond)
+	id, _, ok := elector.Leader()
+	assert.True(t, ok)
+	assert.Equal(t, crashed.ID(), id, "崩溃的领导者在租约到期前仍然持有租约")
+	assert.Nil(t, leaderOf(candidates), "崩溃的候选者不再认为自己是领导者")
+
+	clock.Advance(time.Minute)
+	assert.Eventually(t, func() bool { return leaderOf(candidates) != nil }, time.Second, time.Millisecond)
+	successor := leaderOf(candidates)
+	assert.NotEqual(t, crashed.ID(), successor.ID())
+	assert.Equal(t, uint64(2), successor.Term())
+
+	_, resigned, _ := log.snapshot()
+	assert.Empty(t, resigned, "崩溃的领导者不会调用 OnResigned")
+}
+
+// TestStalledLeaderStepsDown 测试领导者没能及时续约时卸任
+func TestStalledLeaderStepsDown(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	var log events
+	elector := NewElector(log.config(Config{
+		LeaseDuration: time.Minute,
+		RenewInterval: time.Millisecond,
+		RetryInterval: time.Millisecond,
+	}))
+	elector.now = clock.Now
+	defer elector.Stop()
+	leader := joinAll(t, elector, "solo")[0]
+	assert.Eventually(t, leader.IsLeader, time.Second, time.Millisecond)
+
+	// 时钟跳过整个租约期，相当于领导者卡住没有续约
+	clock.Advance(2 * time.Minute)
+	assert.False(t, leader.IsLeader(), "租约过期后立即不再是领导者")
+
+	// 续约失败后卸任，随后重新竞选，以新的任期当选
+	assert.Eventually(t, func() bool { return leader.Term() == 2 }, time.Second, time.Millisecond)
+	elected, resigned, _ := log.snapshot()
+	assert.Equal(t, []string{"solo", "solo"}, elected)
+	assert.Equal(t, []string{"solo@1"}, resigned)
+}
+
+// TestChaos 随机杀死或撤下领导者，检查任意时刻最多一个领导者、任期单调递增且总能选出新的领导者
+func TestChaos(t *testing.T) {
+	const leaseDuration = 40 * time.Millisecond
+	var log events
+	elector := NewElector(log.config(Config{
+		LeaseDuration: leaseDuration,
+		RenewInterval: 5 * time.Millisecond,
+		RetryInterval: 2 * time.Millisecond,
+	}))
+	defer elector.Stop()
+
+	var mutex sync.Mutex
+	candidates := joinAll(t, elector, "w0", "w1", "w2", "w3", "w4")
+	current := func() []*Candidate {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]*Candidate(nil), candidates...)
+	}
+
+	// 监视器：持续检查同时认为自己是领导者的候选者数量
+	var violations atomic.Int32
+	stop := make(chan struct{})
+	var monitor sync.WaitGroup
+	monitor.Add(1)
+	go func() {
+		defer monitor.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			leaders := 0
+			for _, c := range current() {
+				if c.IsLeader() {
+					leaders++
+				}
+			}
+			if leaders > 1 {
+				violations.Add(1)
+			}
+		}
+	}()
+
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	next := len(candidates)
+	for round := 0; round < 15; round++ {
+		var leader *Candidate
+		assert.Eventually(t, func() bool {
+			leader = leaderOf(current())
+			return leader != nil
+		}, 10*leaseDuration, time.Millisecond, "第 %d 轮没有选出领导者", round)
+		if leader == nil {
+			break
+		}
+
+		if random.Intn(2) == 0 {
+			leader.Kill()
+		} else {
+			leader.Resign()
+		}
+
+		// 用新的候选者替换退出的候选者
+		replacement, err := elector.Join(fmt.Sprintf("w%d", next))
+		assert.NoError(t, err)
+		next++
+		mutex.Lock()
+		for i, c := range candidates {
+			if c == leader {
+				candidates[i] = replacement
+			}
+		}
+		mutex.Unlock()
+	}
+
+	close(stop)
+	monitor.Wait()
+	assert.Zero(t, violations.Load(), "同一时刻出现了多个领导者")
+
+	_, _, terms := log.snapshot()
+	assert.GreaterOrEqual(t, len(terms), 15)
+	for i := 1; i < len(terms); i++ {
+		assert.Greater(t, terms[i], terms[i-1], "任期必须单调递增")
+	}
+}
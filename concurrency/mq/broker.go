@@ -0,0 +1,195 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 消息队列相关错误
+var (
+	ErrClosed        = errors.New("消息代理已关闭")
+	ErrQueueExists   = errors.New("队列已存在")
+	ErrQueueNotFound = errors.New("队列不存在")
+	ErrStaleReceipt  = errors.New("投递回执已失效")
+)
+
+// deadLetterSuffix 死信队列名称的后缀
+const deadLetterSuffix = ".dlq"
+
+// Message 消息
+type Message struct {
+	ID          uint64
+	Body        []byte // 同一条消息的各个消费组共享 Body，消费者不能修改
+	PublishedAt time.Time
+	Attempts    int // 在当前消费组中的投递次数，首次投递为 1
+
+	// 以下字段只在死信队列中的消息上设置
+	From   string // 原队列和消费组，形如 "orders/billing"
+	Reason string // 成为死信的原因
+}
+
+// QueueOption 队列配置选项
+type QueueOption func(*queueConfig)
+
+// queueConfig 队列配置
+type queueConfig struct {
+	visibilityTimeout time.Duration
+	maxDeliveries     int
+	retryDelay        time.Duration
+}
+
+// WithVisibilityTimeout 消息被取走后多久没有确认就重新投递，默认 30 秒
+func WithVisibilityTimeout(d time.Duration) QueueOption {
+	return func(c *queueConfig) {
+		if d > 0 {
+			c.visibilityTimeout = d
+		}
+	}
+}
+
+// WithMaxDeliveries 一条消息在一个消费组中最多投递的次数，超过后转入死信队列，默认 5 次，0 表示不限制
+func WithMaxDeliveries(n int) QueueOption {
+	return func(c *queueConfig) {
+		if n >= 0 {
+			c.maxDeliveries = n
+		}
+	}
+}
+
+// WithRetryDelay Consume 中处理失败的消息延迟多久重新投递，默认立即重新投递
+func WithRetryDelay(d time.Duration) QueueOption {
+	return func(c *queueConfig) {
+		if d >= 0 {
+			c.retryDelay = d
+		}
+	}
+}
+
+// Broker 进程内的消息代理：管理命名队列
+type Broker struct {
+	mutex  sync.Mutex
+	queues map[string]*Queue
+	nextID uint64
+	closed bool
+	done   chan struct{} // 关闭时关闭，唤醒所有等待中的消费者
+	now    func() time.Time
+}
+
+// NewBroker 创建消息代理
+func NewBroker() *Broker {
+	return &Broker{
+		queues: make(map[string]*Queue),
+		done:   make(chan struct{}),
+		now:    time.Now,
+	}
+}
+
+// Declare 声明队列，同时创建名为 name+".dlq" 的死信队列
+func (b *Broker) Declare(name string, opts ...QueueOption) (*Queue, error) {
+	cfg := queueConfig{visibilityTimeout: 30 * time.Second, maxDeliveries: 5}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+	if _, exists := b.queues[name]; exists {
+		return nil, fmt.Errorf("%w: %s", ErrQueueExists, name)
+	}
+	if _, exists := b.queues[name+deadLetterSuffix]; exists {
+		return nil, fmt.Errorf("%w: %s", ErrQueueExists, name+deadLetterSuffix)
+	}
+
+	// 死信队列不再有自己的死信队列，消息会一直保留到被确认
+	dlq := newQueue(b, name+deadLetterSuffix, queueConfig{visibilityTimeout: cfg.visibilityTimeout}, nil)
+	q := newQueue(b, name, cfg, dlq)
+	b.queues[dlq.name] = dlq
+	b.queues[name] = q
+	return q, nil
+}
+
+// Queue 返回已声明的队列
+func (b *Broker) Queue(name string) (*Queue, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	q, exists := b.queues[name]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrQueueNotFound, name)
+	}
+	return q, nil
+}
+
+// Queues 返回所有队列的名称，已排序
+func (b *Broker) Queues() []string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	names := make([]string, 0, len(b.queues))
+	for name := range b.queues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Publish 向指定队列发布消息，返回消息编号
+func (b *Broker) Publish(queue string, body []byte) (uint64, error) {
+	q, err := b.Queue(queue)
+	if err != nil {
+		return 0, err
+	}
+	return q.Publish(body)
+}
+
+// Close 关闭消息代理，等待中的 Receive 返回 ErrClosed，之后的发布和投递都会失败
+func (b *Broker) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+	close(b.done)
+}
+
+// isClosed 是否已关闭
+func (b *Broker) isClosed() bool {
+	select {
+	case <-b.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// newMessage 分配消息编号并创建消息
+func (b *Broker) newMessage(body []byte) (Message, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return Message{}, ErrClosed
+	}
+	b.nextID++
+	return Message{ID: b.nextID, Body: body, PublishedAt: b.now()}, nil
+}
+
+// Consume 在队列的消费组上启动 workers 个消费者，阻塞直到 ctx 结束或消息代理关闭
+// 便捷函数，等价于 broker.Queue(queue) 之后调用 Group(group).Consume
+func (b *Broker) Consume(ctx context.Context, queue, group string, workers int, handler Handler) error {
+	q, err := b.Queue(queue)
+	if err != nil {
+		return err
+	}
+	return q.Group(group).Consume(ctx, workers, handler)
+}
@@ -0,0 +1,80 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Handler 消息处理函数，返回 nil 时确认消息，返回错误时按队列的重试延迟重新投递
+// 返回 Permanent 包装的错误表示不必重试，消息直接转入死信队列
+type Handler func(ctx context.Context, d *Delivery) error
+
+// permanentError 不可重试的错误
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent 把错误标记为不可重试，例如消息格式错误
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// Consume 启动 workers 个消费者分担组内的消息，阻塞直到 ctx 结束或消息代理关闭
+// 处理函数中的 panic 被当作处理失败，消息会重新投递
+func (g *Group) Consume(ctx context.Context, workers int, handler Handler) error {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				d, err := g.Receive(ctx)
+				if err != nil {
+					return
+				}
+				g.handle(ctx, d, handler)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return ErrClosed
+}
+
+// handle 调用处理函数并根据结果确认、重试或拒绝消息
+// 回执失效（处理时间超过可见性超时）时消息已经重新投递，忽略确认的错误
+func (g *Group) handle(ctx context.Context, d *Delivery, handler Handler) {
+	err := safeHandle(ctx, d, handler)
+
+	var permanent *permanentError
+	switch {
+	case err == nil:
+		d.Ack()
+	case errors.As(err, &permanent):
+		d.Reject(permanent.err)
+	default:
+		d.requeue(g.queue.config.retryDelay, "处理失败: "+err.Error())
+	}
+}
+
+// safeHandle 调用处理函数，把 panic 转换为错误
+func safeHandle(ctx context.Context, d *Delivery, handler Handler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("处理消息 %d 时发生panic: %v", d.ID, r)
+		}
+	}()
+	return handler(ctx, d)
+}
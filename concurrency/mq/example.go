@@ -0,0 +1,90 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RunExample 运行消息队列示例：订单队列有计费和发货两个消费组，演示重试、可见性超时和死信队列
+func RunExample() {
+	broker := NewBroker()
+	defer broker.Close()
+
+	orders, _ := broker.Declare("orders",
+		WithVisibilityTimeout(50*time.Millisecond),
+		WithMaxDeliveries(3),
+		WithRetryDelay(10*time.Millisecond),
+	)
+	// 先创建消费组，之后发布的消息每个组都会收到一份
+	billing, shipping := orders.Group("billing"), orders.Group("shipping")
+
+	for _, body := range []string{"order-1", "order-2", "order-3:坏数据", "order-4"} {
+		orders.Publish([]byte(body))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	var mutex sync.Mutex
+	log := func(format string, args ...any) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		fmt.Printf(format+"\n", args...)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+
+	// 计费组：两个消费者分担消息；order-2 第一次处理失败，重试后成功
+	go func() {
+		defer wg.Done()
+		billing.Consume(ctx, 2, func(ctx context.Context, d *Delivery) error {
+			body := string(d.Body)
+			if strings.Contains(body, "坏数据") {
+				return Permanent(errors.New("无法解析订单"))
+			}
+			if body == "order-2" && d.Attempts == 1 {
+				log("  [billing] %s 第 %d 次处理失败，稍后重试", body, d.Attempts)
+				return errors.New("支付网关超时")
+			}
+			log("  [billing] %s 计费完成（第 %d 次投递）", body, d.Attempts)
+			return nil
+		})
+	}()
+
+	// 发货组：order-4 第一次取走后没有确认，可见性超时后重新投递
+	go func() {
+		defer wg.Done()
+		crashed := false
+		shipping.Consume(ctx, 1, func(ctx context.Context, d *Delivery) error {
+			if string(d.Body) == "order-4" && !crashed {
+				crashed = true
+				log("  [shipping] 取走 %s 后卡住，超过可见性超时", d.Body)
+				time.Sleep(80 * time.Millisecond)
+				return nil // 回执已失效，确认会被忽略
+			}
+			log("  [shipping] %s 已发货（第 %d 次投递）", d.Body, d.Attempts)
+			return nil
+		})
+	}()
+
+	// 死信队列的消费者：记录无法处理的消息
+	go func() {
+		defer wg.Done()
+		broker.Consume(ctx, "orders.dlq", "alerts", 1, func(ctx context.Context, d *Delivery) error {
+			log("  [dlq] %s 来自 %s: %s", d.Body, d.From, d.Reason)
+			return nil
+		})
+	}()
+	wg.Wait()
+
+	for _, g := range []*Group{billing, shipping} {
+		s := g.Stats()
+		fmt.Printf("%s: 投递 %d 次，重新投递 %d 次，确认 %d 次，超时 %d 次，死信 %d 条\n",
+			g.Name(), s.Delivered, s.Redelivered, s.Acked, s.Expired, s.DeadLettered)
+	}
+}
@@ -0,0 +1,253 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func receive(t *testing.T, g *Group) *Delivery {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	d, err := g.Receive(ctx)
+	assert.NoError(t, err)
+	return d
+}
+
+func assertEmpty(t *testing.T, g *Group) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := g.Receive(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// TestDeclare 测试声明队列和死信队列
+func TestDeclare(t *testing.T) {
+	b := NewBroker()
+	q, err := b.Declare("orders")
+	assert.NoError(t, err)
+	assert.Equal(t, "orders.dlq", q.DeadLetter().Name())
+	assert.Nil(t, q.DeadLetter().DeadLetter())
+	assert.Equal(t, []string{"orders", "orders.dlq"}, b.Queues())
+
+	_, err = b.Declare("orders")
+	assert.ErrorIs(t, err, ErrQueueExists)
+	_, err = b.Publish("missing", nil)
+	assert.ErrorIs(t, err, ErrQueueNotFound)
+
+	b.Close()
+	_, err = q.Publish([]byte("x"))
+	assert.ErrorIs(t, err, ErrClosed)
+	_, err = q.Group("g").Receive(context.Background())
+	assert.ErrorIs(t, err, ErrClosed)
+	_, err = b.Declare("other")
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+// TestConsumerGroups 测试每个消费组都收到全部消息，组内的消费者分担消息
+func TestConsumerGroups(t *testing.T) {
+	b := NewBroker()
+	defer b.Close()
+	q, _ := b.Declare("events")
+
+	// 没有消费组时发布的消息交给第一个消费组
+	q.Publish([]byte("early"))
+	assert.Equal(t, 1, q.Backlog())
+	first := q.Group("audit")
+	assert.Zero(t, q.Backlog())
+	d := receive(t, first)
+	assert.Equal(t, "early", string(d.Body))
+	assert.Equal(t, 1, d.Attempts)
+	assert.NoError(t, d.Ack())
+
+	second := q.Group("search")
+	assert.Same(t, second, q.Group("search"))
+	assert.Equal(t, []string{"audit", "search"}, q.Groups())
+
+	const n = 100
+	for i := 0; i < n; i++ {
+		q.Publish([]byte{byte(i)})
+	}
+
+	var mutex sync.Mutex
+	byWorker := map[int]int{}
+	var seen []int
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+				d, err := second.Receive(ctx)
+				cancel()
+				if err != nil {
+					return
+				}
+				mutex.Lock()
+				byWorker[w]++
+				seen = append(seen, int(d.Body[0]))
+				mutex.Unlock()
+				time.Sleep(time.Millisecond) // 模拟处理耗时，让其他消费者有机会取到消息
+				d.Ack()
+			}
+		}()
+	}
+	wg.Wait()
+
+	sort.Ints(seen)
+	assert.Len(t, seen, n, "组内每条消息只投递一次")
+	for i, v := range seen {
+		assert.Equal(t, i, v)
+	}
+	assert.Greater(t, len(byWorker), 1, "多个消费者分担消息")
+
+	stats := first.Stats()
+	assert.Equal(t, n+1, stats.Published, "另一个组同样收到全部消息")
+	assert.Equal(t, n, stats.Ready)
+	assert.Equal(t, n, second.Stats().Acked)
+}
+
+// TestVisibilityTimeout 测试未确认的消息在可见性超时后重新投递，旧回执失效
+func TestVisibilityTimeout(t *testing.T) {
+	b := NewBroker()
+	defer b.Close()
+	q, _ := b.Declare("jobs", WithVisibilityTimeout(30*time.Millisecond), WithMaxDeliveries(0))
+	g := q.Group("workers")
+	q.Publish([]byte("job"))
+
+	d1 := receive(t, g)
+	assert.Equal(t, 1, g.Stats().InFlight)
+	assertEmpty(t, g)
+
+	d2 := receive(t, g)
+	assert.Equal(t, d1.ID, d2.ID)
+	assert.Equal(t, 2, d2.Attempts)
+	assert.ErrorIs(t, d1.Ack(), ErrStaleReceipt, "超时后旧回执失效")
+	assert.NoError(t, d2.Ack())
+	assert.ErrorIs(t, d2.Ack(), ErrStaleReceipt, "不能重复确认")
+
+	stats := g.Stats()
+	assert.Equal(t, 1, stats.Expired)
+	assert.Equal(t, 1, stats.Redelivered)
+	assert.Equal(t, 1, stats.Acked)
+	assert.Zero(t, stats.InFlight)
+
+	// Extend 延长可见性超时
+	q.Publish([]byte("long"))
+	d := receive(t, g)
+	for i := 0; i < 4; i++ {
+		time.Sleep(15 * time.Millisecond)
+		assert.NoError(t, d.Extend(30*time.Millisecond))
+	}
+	assert.NoError(t, d.Ack(), "持续延长的消息不会被重新投递")
+	assert.Equal(t, 1, g.Stats().Expired)
+}
+
+// TestDeadLetter 测试投递次数用完和拒绝的消息转入死信队列
+func TestDeadLetter(t *testing.T) {
+	b := NewBroker()
+	defer b.Close()
+	q, _ := b.Declare("mail", WithVisibilityTimeout(time.Hour), WithMaxDeliveries(2))
+	g := q.Group("sender")
+	dead := q.DeadLetter().Group("ops")
+
+	q.Publish([]byte("bounce"))
+	d := receive(t, g)
+	assert.NoError(t, d.Nack(0))
+	d = receive(t, g)
+	assert.Equal(t, 2, d.Attempts)
+	assert.NoError(t, d.Nack(time.Hour), "投递次数用完时忽略延迟")
+	assertEmpty(t, g)
+
+	dl := receive(t, dead)
+	assert.Equal(t, "bounce", string(dl.Body))
+	assert.Equal(t, "mail/sender", dl.From)
+	assert.Equal(t, "处理失败，已投递 2 次", dl.Reason)
+	assert.Equal(t, 1, dl.Attempts)
+	assert.NoError(t, dl.Ack())
+
+	q.Publish([]byte("spam"))
+	d = receive(t, g)
+	assert.NoError(t, d.Reject(errors.New("格式错误")))
+	dl = receive(t, dead)
+	assert.Equal(t, "被拒绝: 格式错误", dl.Reason)
+
+	// 死信队列中的消息被拒绝时丢弃
+	assert.NoError(t, dl.Reject(nil))
+	assert.Equal(t, 1, dead.Stats().Dropped)
+	assert.Equal(t, 2, g.Stats().DeadLettered)
+
+	// 延迟重试
+	q.Publish([]byte("later"))
+	d = receive(t, g)
+	start := time.Now()
+	assert.NoError(t, d.Nack(30*time.Millisecond))
+	assert.Equal(t, 1, g.Stats().Delayed)
+	d = receive(t, g)
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+	assert.Equal(t, "later", string(d.Body))
+	assert.Zero(t, g.Stats().Delayed)
+}
+
+// TestConsume 测试 Consume 根据处理结果确认、重试或拒绝消息
+func TestConsume(t *testing.T) {
+	b := NewBroker()
+	q, _ := b.Declare("tasks", WithMaxDeliveries(3))
+	g := q.Group("workers")
+	for _, body := range []string{"ok", "flaky", "poison", "panic", "broken"} {
+		q.Publish([]byte(body))
+	}
+
+	var mutex sync.Mutex
+	attempts := map[string]int{}
+	done := make(chan error)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		done <- g.Consume(ctx, 3, func(ctx context.Context, d *Delivery) error {
+			mutex.Lock()
+			attempts[string(d.Body)]++
+			mutex.Unlock()
+			switch string(d.Body) {
+			case "flaky":
+				if d.Attempts < 2 {
+					return errors.New("暂时失败")
+				}
+			case "poison":
+				return Permanent(errors.New("无法解析"))
+			case "panic":
+				if d.Attempts == 1 {
+					panic("处理函数崩溃")
+				}
+			case "broken":
+				return errors.New("一直失败")
+			}
+			return nil
+		})
+	}()
+
+	dead := q.DeadLetter().Group("ops")
+	reasons := map[string]string{}
+	for i := 0; i < 2; i++ {
+		d := receive(t, dead)
+		reasons[string(d.Body)] = d.Reason
+		d.Ack()
+	}
+	assert.Equal(t, "被拒绝: 无法解析", reasons["poison"])
+	assert.Equal(t, "处理失败: 一直失败，已投递 3 次", reasons["broken"])
+
+	b.Close()
+	assert.ErrorIs(t, <-done, ErrClosed)
+	assert.Equal(t, map[string]int{"ok": 1, "flaky": 2, "poison": 1, "panic": 2, "broken": 3}, attempts)
+	stats := g.Stats()
+	assert.Equal(t, 3, stats.Acked)
+	assert.Equal(t, 2, stats.DeadLettered)
+}
@@ -0,0 +1,378 @@
+package mq
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Queue 命名队列
+//
+// 每条消息会投递给队列的每一个消费组，同一个消费组中的多个消费者分担消息，每条消息只交给其中一个。
+// 还没有任何消费组时发布的消息暂存起来，交给第一个创建的消费组。
+type Queue struct {
+	broker *Broker
+	name   string
+	config queueConfig
+	dlq    *Queue // 死信队列，死信队列自身为 nil
+
+	mutex   sync.Mutex // 保护队列和所有消费组的状态
+	groups  map[string]*Group
+	backlog []Message // 没有消费组时发布的消息
+	receipt uint64
+}
+
+// newQueue 创建队列
+func newQueue(b *Broker, name string, cfg queueConfig, dlq *Queue) *Queue {
+	return &Queue{broker: b, name: name, config: cfg, dlq: dlq, groups: make(map[string]*Group)}
+}
+
+// Name 返回队列名称
+func (q *Queue) Name() string {
+	return q.name
+}
+
+// DeadLetter 返回死信队列，死信队列本身返回 nil
+func (q *Queue) DeadLetter() *Queue {
+	return q.dlq
+}
+
+// Publish 发布消息，返回消息编号
+func (q *Queue) Publish(body []byte) (uint64, error) {
+	msg, err := q.broker.newMessage(body)
+	if err != nil {
+		return 0, err
+	}
+	q.enqueue(msg)
+	return msg.ID, nil
+}
+
+// enqueue 把消息放入每个消费组，没有消费组时暂存
+func (q *Queue) enqueue(msg Message) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if len(q.groups) == 0 {
+		q.backlog = append(q.backlog, msg)
+		return
+	}
+	for _, g := range q.groups {
+		g.stats.Published++
+		g.pushLocked(msg)
+	}
+}
+
+// Group 返回消费组，不存在时创建
+func (q *Queue) Group(name string) *Group {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if g, exists := q.groups[name]; exists {
+		return g
+	}
+	g := &Group{
+		queue:    q,
+		name:     name,
+		notify:   make(chan struct{}, 1),
+		inflight: make(map[uint64]*inflight),
+	}
+	if len(q.groups) == 0 {
+		// 第一个消费组接收暂存的消息
+		for _, msg := range q.backlog {
+			g.stats.Published++
+			g.pushLocked(msg)
+		}
+		q.backlog = nil
+	}
+	q.groups[name] = g
+	return g
+}
+
+// Groups 返回所有消费组的名称，已排序
+func (q *Queue) Groups() []string {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	names := make([]string, 0, len(q.groups))
+	for name := range q.groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Backlog 返回暂存的消息数
+func (q *Queue) Backlog() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return len(q.backlog)
+}
+
+// GroupStats 消费组统计信息
+type GroupStats struct {
+	Ready        int // 等待投递的消息数
+	InFlight     int // 已投递、尚未确认的消息数
+	Delayed      int // 处理失败后等待延迟重新投递的消息数
+	Published    int // 进入消费组的消息数
+	Delivered    int // 投递次数，包括重新投递
+	Redelivered  int // 重新投递的次数
+	Acked        int // 确认次数
+	Expired      int // 可见性超时的次数
+	DeadLettered int // 转入死信队列的消息数
+	Dropped      int // 死信队列中被拒绝而丢弃的消息数
+}
+
+// Group 消费组：组内的消费者分担消息，每条消息在确认之前至少投递一次
+type Group struct {
+	queue *Queue
+	name  string
+
+	// 以下字段由 queue.mutex 保护
+	ready    []Message
+	inflight map[uint64]*inflight // 按回执索引
+	delayed  int
+	stats    GroupStats
+
+	notify chan struct{} // 有新消息时发信号，容量为 1，消费者取走消息后如果还有剩余会继续传递信号
+}
+
+// inflight 已投递、尚未确认的消息
+type inflight struct {
+	msg      Message
+	deadline time.Time
+	timer    *time.Timer
+}
+
+// Name 返回消费组名称
+func (g *Group) Name() string {
+	return g.name
+}
+
+// Stats 返回统计信息
+func (g *Group) Stats() GroupStats {
+	g.queue.mutex.Lock()
+	defer g.queue.mutex.Unlock()
+
+	stats := g.stats
+	stats.Ready = len(g.ready)
+	stats.InFlight = len(g.inflight)
+	stats.Delayed = g.delayed
+	return stats
+}
+
+// Receive 取走一条消息，没有消息时阻塞，直到 ctx 结束或消息代理关闭
+// 消息在可见性超时之内没有确认就会重新投递给组内的其他消费者
+func (g *Group) Receive(ctx context.Context) (*Delivery, error) {
+	q := g.queue
+	for {
+		if q.broker.isClosed() {
+			return nil, ErrClosed
+		}
+
+		q.mutex.Lock()
+		if len(g.ready) > 0 {
+			d := g.deliverLocked()
+			if len(g.ready) > 0 {
+				g.signal()
+			}
+			q.mutex.Unlock()
+			return d, nil
+		}
+		q.mutex.Unlock()
+
+		select {
+		case <-g.notify:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-q.broker.done:
+			return nil, ErrClosed
+		}
+	}
+}
+
+// deliverLocked 取出队首的消息并开始计算可见性超时，调用者需持有锁
+func (g *Group) deliverLocked() *Delivery {
+	q := g.queue
+	msg := g.ready[0]
+	g.ready = g.ready[1:]
+
+	msg.Attempts++
+	g.stats.Delivered++
+	if msg.Attempts > 1 {
+		g.stats.Redelivered++
+	}
+
+	q.receipt++
+	receipt := q.receipt
+	f := &inflight{msg: msg, deadline: q.broker.now().Add(q.config.visibilityTimeout)}
+	f.timer = time.AfterFunc(q.config.visibilityTimeout, func() { g.expire(receipt) })
+	g.inflight[receipt] = f
+	return &Delivery{Message: msg, group: g, receipt: receipt}
+}
+
+// expire 可见性超时：消息重新变为可投递
+func (g *Group) expire(receipt uint64) {
+	q := g.queue
+	q.mutex.Lock()
+	f, exists := g.inflight[receipt]
+	// Extend 之后旧的定时器可能已经触发并在等锁，按截止时间重新检查
+	if !exists || q.broker.now().Before(f.deadline) {
+		q.mutex.Unlock()
+		return
+	}
+	delete(g.inflight, receipt)
+	g.stats.Expired++
+	dead := g.retryLocked(f.msg, "可见性超时")
+	q.mutex.Unlock()
+
+	g.deadLetter(dead)
+}
+
+// retryLocked 重新投递消息；投递次数已经用完时返回需要转入死信队列的消息，调用者需持有锁
+func (g *Group) retryLocked(msg Message, reason string) *Message {
+	if limit := g.queue.config.maxDeliveries; limit > 0 && msg.Attempts >= limit {
+		msg.Reason = fmt.Sprintf("%s，已投递 %d 次", reason, msg.Attempts)
+		return &msg
+	}
+	g.pushLocked(msg)
+	return nil
+}
+
+// deadLetter 把消息转入死信队列，在锁外调用，避免同时持有两个队列的锁
+func (g *Group) deadLetter(msg *Message) {
+	if msg == nil {
+		return
+	}
+	q := g.queue
+
+	q.mutex.Lock()
+	if q.dlq == nil {
+		g.stats.Dropped++
+		q.mutex.Unlock()
+		return
+	}
+	g.stats.DeadLettered++
+	q.mutex.Unlock()
+
+	dead := *msg
+	dead.Attempts = 0
+	dead.From = q.name + "/" + g.name
+	q.dlq.enqueue(dead)
+}
+
+// pushLocked 消息进入待投递列表并通知消费者，调用者需持有锁
+func (g *Group) pushLocked(msg Message) {
+	g.ready = append(g.ready, msg)
+	g.signal()
+}
+
+// signal 非阻塞地通知一个等待中的消费者
+func (g *Group) signal() {
+	select {
+	case g.notify <- struct{}{}:
+	default:
+	}
+}
+
+// settleLocked 结束一次投递，回执已失效时返回 ErrStaleReceipt，调用者需持有锁
+func (g *Group) settleLocked(receipt uint64) (*inflight, error) {
+	f, exists := g.inflight[receipt]
+	if !exists {
+		return nil, fmt.Errorf("%w: 消息可能已超时重新投递或已被确认", ErrStaleReceipt)
+	}
+	f.timer.Stop()
+	delete(g.inflight, receipt)
+	return f, nil
+}
+
+// Delivery 一次投递，消费者处理完后必须调用 Ack、Nack 或 Reject 之一
+type Delivery struct {
+	Message
+	group   *Group
+	receipt uint64
+}
+
+// Ack 确认消息已处理，消息从消费组中删除
+// 可见性超时之后再确认会返回 ErrStaleReceipt，此时消息已经或即将被重新投递
+func (d *Delivery) Ack() error {
+	q := d.group.queue
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if _, err := d.group.settleLocked(d.receipt); err != nil {
+		return err
+	}
+	d.group.stats.Acked++
+	return nil
+}
+
+// Nack 放弃处理，消息在 delay 之后重新投递；投递次数用完时转入死信队列
+func (d *Delivery) Nack(delay time.Duration) error {
+	return d.requeue(delay, "处理失败")
+}
+
+// requeue 重新投递或转入死信队列
+func (d *Delivery) requeue(delay time.Duration, reason string) error {
+	g := d.group
+	q := g.queue
+	q.mutex.Lock()
+	f, err := g.settleLocked(d.receipt)
+	if err != nil {
+		q.mutex.Unlock()
+		return err
+	}
+
+	var dead *Message
+	if limit := q.config.maxDeliveries; delay > 0 && (limit == 0 || f.msg.Attempts < limit) {
+		g.delayed++
+		time.AfterFunc(delay, func() {
+			q.mutex.Lock()
+			defer q.mutex.Unlock()
+			g.delayed--
+			g.pushLocked(f.msg)
+		})
+	} else {
+		dead = g.retryLocked(f.msg, reason)
+	}
+	q.mutex.Unlock()
+
+	g.deadLetter(dead)
+	return nil
+}
+
+// Reject 拒绝消息，不再重试，直接转入死信队列；死信队列中的消息被拒绝时丢弃
+func (d *Delivery) Reject(reason error) error {
+	g := d.group
+	q := g.queue
+	q.mutex.Lock()
+	f, err := g.settleLocked(d.receipt)
+	q.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	msg := f.msg
+	msg.Reason = "被拒绝"
+	if reason != nil {
+		msg.Reason += ": " + reason.Error()
+	}
+	g.deadLetter(&msg)
+	return nil
+}
+
+// Extend 延长可见性超时，处理耗时较长的消息时定期调用，避免被重新投递
+func (d *Delivery) Extend(timeout time.Duration) error {
+	q := d.group.queue
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	f, exists := d.group.inflight[d.receipt]
+	if !exists {
+		return fmt.Errorf("%w: 消息可能已超时重新投递或已被确认", ErrStaleReceipt)
+	}
+	f.deadline = q.broker.now().Add(timeout)
+	f.timer.Reset(timeout)
+	return nil
+}
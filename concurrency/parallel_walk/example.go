@@ -0,0 +1,50 @@
+package parallel_walk
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RunExample 运行并行遍历示例：统计当前目录下各类文件的数量和大小，并演示提前取消
+func RunExample() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	skip := WithSkipDir(Names(".git", "node_modules", "vendor"))
+	start := time.Now()
+	stats, err := Summarize(ctx, ".", WithConcurrency(4), skip)
+	if err != nil {
+		fmt.Println("遍历出错:", err)
+	}
+	fmt.Printf("共 %d 个目录，%d 个文件，%d 字节，用时 %v\n",
+		stats.Dirs, stats.Files, stats.Bytes, time.Since(start).Round(time.Millisecond))
+	for i, es := range stats.ByExtension() {
+		if i == 5 {
+			break
+		}
+		ext := es.Ext
+		if ext == "" {
+			ext = "(无扩展名)"
+		}
+		fmt.Printf("  %-10s %4d 个文件 %8d 字节\n", ext, es.Files, es.Bytes)
+	}
+	if stats.Largest.Info != nil {
+		fmt.Printf("最大的文件: %s (%d 字节)\n", stats.Largest.Path, stats.Largest.Info.Size())
+	}
+
+	// 找到第一个 Go 文件后取消遍历，其余协程尽快退出
+	findCtx, stop := context.WithCancel(ctx)
+	w := Walk(findCtx, ".", skip, WithFilter(Extensions(".go")))
+	first, ok := <-w.Entries()
+	stop()
+	for range w.Entries() {
+		// 取消之后通道很快关闭，读完缓冲区中剩余的项
+	}
+	if ok {
+		fmt.Println("找到第一个 Go 文件:", first.Path)
+	}
+	if err := w.Err(); err != nil {
+		fmt.Println("遍历已提前结束:", err)
+	}
+}
@@ -0,0 +1,52 @@
+package parallel_walk
+
+import (
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// FilesOnly 只输出文件
+func FilesOnly() Filter {
+	return func(_ string, d fs.DirEntry) bool {
+		return !d.IsDir()
+	}
+}
+
+// Extensions 只输出指定扩展名的文件，扩展名不区分大小写，如 ".go"
+func Extensions(exts ...string) Filter {
+	lower := make([]string, len(exts))
+	for i, ext := range exts {
+		lower[i] = strings.ToLower(ext)
+	}
+	return func(path string, d fs.DirEntry) bool {
+		return !d.IsDir() && slices.Contains(lower, extension(path))
+	}
+}
+
+// Names 匹配指定名称，常用于跳过 .git、node_modules 等目录
+func Names(names ...string) Filter {
+	return func(_ string, d fs.DirEntry) bool {
+		return slices.Contains(names, d.Name())
+	}
+}
+
+// Hidden 匹配以点开头的隐藏文件和目录
+func Hidden() Filter {
+	return func(_ string, d fs.DirEntry) bool {
+		return strings.HasPrefix(d.Name(), ".")
+	}
+}
+
+// Not 取反
+func Not(f Filter) Filter {
+	return func(path string, d fs.DirEntry) bool {
+		return !f(path, d)
+	}
+}
+
+// extension 小写的扩展名，没有扩展名时为空字符串
+func extension(path string) string {
+	return strings.ToLower(filepath.Ext(path))
+}
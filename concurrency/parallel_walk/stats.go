@@ -0,0 +1,83 @@
+package parallel_walk
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// ExtStats 某一种扩展名的统计
+type ExtStats struct {
+	Ext   string // 小写的扩展名，没有扩展名的文件为空字符串
+	Files int
+	Bytes int64
+}
+
+// Stats 遍历结果的统计
+type Stats struct {
+	Dirs    int
+	Files   int
+	Bytes   int64
+	Largest Entry // 最大的文件
+	byExt   map[string]*ExtStats
+}
+
+// Add 把一项计入统计
+func (s *Stats) Add(e Entry) {
+	if e.Info.IsDir() {
+		s.Dirs++
+		return
+	}
+
+	size := e.Info.Size()
+	s.Files++
+	s.Bytes += size
+	if s.Largest.Info == nil || size > s.Largest.Info.Size() {
+		s.Largest = e
+	}
+
+	if s.byExt == nil {
+		s.byExt = make(map[string]*ExtStats)
+	}
+	ext := extension(e.Path)
+	es, exists := s.byExt[ext]
+	if !exists {
+		es = &ExtStats{Ext: ext}
+		s.byExt[ext] = es
+	}
+	es.Files++
+	es.Bytes += size
+}
+
+// Ext 返回某一种扩展名的统计，ext 不区分大小写
+func (s *Stats) Ext(ext string) ExtStats {
+	if es, exists := s.byExt[strings.ToLower(ext)]; exists {
+		return *es
+	}
+	return ExtStats{Ext: ext}
+}
+
+// ByExtension 返回按总字节数从大到小排序的扩展名统计
+func (s *Stats) ByExtension() []ExtStats {
+	list := make([]ExtStats, 0, len(s.byExt))
+	for _, es := range s.byExt {
+		list = append(list, *es)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Bytes != list[j].Bytes {
+			return list[i].Bytes > list[j].Bytes
+		}
+		return list[i].Ext < list[j].Ext
+	})
+	return list
+}
+
+// Summarize 并行遍历 root 并汇总统计，统计在单个协程中完成，不需要加锁
+func Summarize(ctx context.Context, root string, opts ...Option) (Stats, error) {
+	w := Walk(ctx, root, opts...)
+	var stats Stats
+	for e := range w.Entries() {
+		stats.Add(e)
+	}
+	return stats, w.Err()
+}
@@ -0,0 +1,230 @@
+package parallel_walk
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/XiaoluCoding626/go-design-pattern/synchronization/semaphore"
+)
+
+// Entry 遍历到的文件或目录
+type Entry struct {
+	Path  string
+	Depth int // 根目录的直接子项为 1
+	Info  fs.FileInfo
+}
+
+// Filter 判断是否处理某一项，path 为完整路径
+type Filter func(path string, d fs.DirEntry) bool
+
+// Option 遍历选项
+type Option func(*Walker)
+
+// WithConcurrency 设置同时读取目录的协程数，默认为 CPU 核数
+func WithConcurrency(n int) Option {
+	return func(w *Walker) {
+		if n > 0 {
+			w.concurrency = n
+		}
+	}
+}
+
+// WithFilter 只输出满足条件的项，可以多次使用，所有条件都满足才输出
+// 过滤不影响遍历：不满足条件的目录仍然会进入，剪枝请使用 WithSkipDir
+func WithFilter(f Filter) Option {
+	return func(w *Walker) {
+		w.filters = append(w.filters, f)
+	}
+}
+
+// WithSkipDir 跳过满足条件的目录，既不输出也不进入
+func WithSkipDir(f Filter) Option {
+	return func(w *Walker) {
+		w.skipDirs = append(w.skipDirs, f)
+	}
+}
+
+// WithMaxDepth 限制遍历深度，0 表示不限制
+func WithMaxDepth(depth int) Option {
+	return func(w *Walker) {
+		if depth >= 0 {
+			w.maxDepth = depth
+		}
+	}
+}
+
+// WithBufferSize 设置输出通道的缓冲区大小，默认为 64
+func WithBufferSize(n int) Option {
+	return func(w *Walker) {
+		if n >= 0 {
+			w.bufferSize = n
+		}
+	}
+}
+
+// Walker 并行目录遍历器
+//
+// 每个目录由一个持有信号量票证的协程读取，遇到子目录时如果还有空闲票证就交给新协程，
+// 否则在当前协程中继续递归。因此同时工作的协程数永远不超过信号量的容量，
+// 不会因为目录过多而堆积大量等待中的协程。
+type Walker struct {
+	concurrency int
+	filters     []Filter
+	skipDirs    []Filter
+	maxDepth    int
+	bufferSize  int
+
+	sem     *semaphore.Semaphore
+	entries chan Entry
+	done    chan struct{}
+
+	mutex sync.Mutex
+	errs  []error
+}
+
+// Walk 开始并行遍历 root 下的所有文件和目录（不包括 root 本身），立即返回
+//
+// 结果从 Entries 返回的通道中读取，输出顺序不确定。调用者必须读完通道或者取消 ctx，
+// 否则遍历协程会阻塞在发送上。符号链接不会被跟随。
+func Walk(ctx context.Context, root string, opts ...Option) *Walker {
+	w := &Walker{
+		concurrency: runtime.NumCPU(),
+		bufferSize:  64,
+		done:        make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	w.sem = semaphore.New(w.concurrency)
+	w.entries = make(chan Entry, w.bufferSize)
+
+	go w.run(ctx, root)
+	return w
+}
+
+// Entries 返回输出通道，遍历结束后关闭
+func (w *Walker) Entries() <-chan Entry {
+	return w.entries
+}
+
+// Err 等待遍历结束，返回遍历期间遇到的所有错误
+// 读取某个目录失败不会中断遍历，ctx 取消时返回 ctx 的错误
+func (w *Walker) Err() error {
+	<-w.done
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return errors.Join(w.errs...)
+}
+
+// run 遍历根目录，等待所有协程结束后关闭输出通道
+func (w *Walker) run(ctx context.Context, root string) {
+	defer close(w.done)
+	defer close(w.entries)
+
+	info, err := os.Stat(root)
+	if err != nil {
+		w.addError(err)
+		return
+	}
+	if !info.IsDir() {
+		w.addError(&fs.PathError{Op: "walk", Path: root, Err: errors.New("不是目录")})
+		return
+	}
+
+	if err := w.sem.Acquire(ctx); err != nil {
+		w.addError(err)
+		return
+	}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go w.walkDir(ctx, &wg, root, 0)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		w.addError(err)
+	}
+}
+
+// walkDir 在新协程中遍历目录，调用前已经获取了票证
+func (w *Walker) walkDir(ctx context.Context, wg *sync.WaitGroup, dir string, depth int) {
+	defer wg.Done()
+	defer w.sem.Release()
+	w.visit(ctx, wg, dir, depth)
+}
+
+// visit 读取目录，输出满足条件的项，并继续遍历子目录
+func (w *Walker) visit(ctx context.Context, wg *sync.WaitGroup, dir string, depth int) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	// 读取失败时 ReadDir 仍然返回已经读到的部分
+	children, err := os.ReadDir(dir)
+	if err != nil {
+		w.addError(err)
+	}
+
+	childDepth := depth + 1
+	for _, d := range children {
+		path := filepath.Join(dir, d.Name())
+		if d.IsDir() && matchAny(w.skipDirs, path, d) {
+			continue
+		}
+
+		if matchAll(w.filters, path, d) {
+			info, err := d.Info()
+			if err != nil {
+				// 读取目录之后文件可能已被删除
+				w.addError(err)
+				continue
+			}
+			select {
+			case w.entries <- Entry{Path: path, Depth: childDepth, Info: info}:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if !d.IsDir() || (w.maxDepth > 0 && childDepth >= w.maxDepth) {
+			continue
+		}
+		if w.sem.TryAcquire() {
+			wg.Add(1)
+			go w.walkDir(ctx, wg, path, childDepth)
+		} else {
+			w.visit(ctx, wg, path, childDepth)
+		}
+	}
+}
+
+// addError 记录错误
+func (w *Walker) addError(err error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.errs = append(w.errs, err)
+}
+
+// matchAll 所有条件都满足
+func matchAll(filters []Filter, path string, d fs.DirEntry) bool {
+	for _, f := range filters {
+		if !f(path, d) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchAny 任意条件满足
+func matchAny(filters []Filter, path string, d fs.DirEntry) bool {
+	for _, f := range filters {
+		if f(path, d) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package parallel_walk
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// makeTree 创建测试目录树：width 个子目录嵌套 depth 层，每个目录下有 .go、.txt 和无扩展名的文件
+func makeTree(t *testing.T, width, depth int) string {
+	t.Helper()
+	root := t.TempDir()
+	var build func(dir string, level int)
+	build = func(dir string, level int) {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0o644))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "NOTES.TXT"), []byte("notes"), 0o644))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "Makefile"), []byte("all:"), 0o644))
+		if level == depth {
+			return
+		}
+		for i := 0; i < width; i++ {
+			sub := filepath.Join(dir, fmt.Sprintf("d%d", i))
+			assert.NoError(t, os.Mkdir(sub, 0o755))
+			build(sub, level+1)
+		}
+	}
+	build(root, 0)
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, ".git", "objects"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, ".git", "objects", "blob"), []byte("x"), 0o644))
+	return root
+}
+
+// reference 使用 filepath.WalkDir 串行遍历，作为对照
+func reference(t *testing.T, root string, keep func(path string, d fs.DirEntry) bool) []string {
+	var paths []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if path != root && keep(path, d) {
+			paths = append(paths, path)
+		}
+		return err
+	})
+	assert.NoError(t, err)
+	return paths
+}
+
+func collect(w *Walker) []string {
+	var paths []string
+	for e := range w.Entries() {
+		paths = append(paths, e.Path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// TestWalkMatchesReference 测试并行遍历的结果与串行遍历一致
+func TestWalkMatchesReference(t *testing.T) {
+	root := makeTree(t, 3, 3)
+	for _, n := range []int{1, 2, 8} {
+		w := Walk(context.Background(), root, WithConcurrency(n), WithBufferSize(0))
+		assert.Equal(t, reference(t, root, func(string, fs.DirEntry) bool { return true }), collect(w), "concurrency=%d", n)
+		assert.NoError(t, w.Err())
+	}
+}
+
+// TestFiltersAndDepth 测试过滤、剪枝和深度限制
+func TestFiltersAndDepth(t *testing.T) {
+	root := makeTree(t, 2, 3)
+
+	w := Walk(context.Background(), root, WithFilter(Extensions(".GO", ".txt")), WithSkipDir(Hidden()))
+	want := reference(t, root, func(path string, d fs.DirEntry) bool {
+		return !d.IsDir() && (strings.HasSuffix(path, ".go") || strings.HasSuffix(path, ".TXT"))
+	})
+	assert.Equal(t, want, collect(w))
+	assert.Len(t, want, 2*15, "每个目录 2 个匹配文件")
+
+	// 过滤条件不阻止进入目录：只输出目录以外的项，.git 下的文件也会被找到
+	w = Walk(context.Background(), root, WithFilter(FilesOnly()), WithFilter(Not(Names("main.go", "NOTES.TXT", "Makefile"))))
+	assert.Equal(t, []string{filepath.Join(root, ".git", "objects", "blob")}, collect(w))
+
+	w = Walk(context.Background(), root, WithMaxDepth(1), WithSkipDir(Names(".git")))
+	assert.Equal(t, []string{"Makefile", "NOTES.TXT", "d0", "d1", "main.go"}, names(root, collect(w)))
+
+	var depths []int
+	for e := range Walk(context.Background(), root, WithMaxDepth(2), WithFilter(Names("main.go"))).Entries() {
+		depths = append(depths, e.Depth)
+	}
+	sort.Ints(depths)
+	assert.Equal(t, []int{1, 2, 2}, depths)
+}
+
+func names(root string, paths []string) []string {
+	for i, p := range paths {
+		paths[i], _ = filepath.Rel(root, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// TestBoundedConcurrency 测试同时读取目录的协程数不超过限制
+func TestBoundedConcurrency(t *testing.T) {
+	root := makeTree(t, 4, 3)
+	var active, peak atomic.Int32
+	slow := func(path string, d fs.DirEntry) bool {
+		if d.Name() != "main.go" {
+			return false
+		}
+		// 每个目录恰好经过一次 main.go，以它近似目录处理的并发度
+		n := active.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		active.Add(-1)
+		return true
+	}
+
+	w := Walk(context.Background(), root, WithConcurrency(3), WithFilter(slow))
+	assert.Equal(t, 1+4+16+64, len(collect(w)), "每个目录一个 main.go")
+	assert.NoError(t, w.Err())
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+	assert.Greater(t, peak.Load(), int32(1), "确实并行执行")
+}
+
+// TestCancel 测试提前取消
+func TestCancel(t *testing.T) {
+	root := makeTree(t, 4, 4)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := Walk(ctx, root, WithConcurrency(4), WithBufferSize(0))
+
+	got := 0
+	for range w.Entries() {
+		got++
+		if got == 10 {
+			cancel()
+		}
+	}
+	assert.ErrorIs(t, w.Err(), context.Canceled)
+	assert.Less(t, got, 50, "取消后很快停止")
+}
+
+// TestRootErrors 测试根目录不存在或不是目录
+func TestRootErrors(t *testing.T) {
+	dir := t.TempDir()
+	w := Walk(context.Background(), filepath.Join(dir, "missing"))
+	assert.Empty(t, collect(w))
+	assert.ErrorIs(t, w.Err(), fs.ErrNotExist)
+
+	file := filepath.Join(dir, "file")
+	assert.NoError(t, os.WriteFile(file, nil, 0o644))
+	w = Walk(context.Background(), file)
+	assert.Empty(t, collect(w))
+	assert.ErrorContains(t, w.Err(), "不是目录")
+}
+
+// TestSummarize 测试按扩展名统计
+func TestSummarize(t *testing.T) {
+	root := makeTree(t, 2, 2)
+	stats, err := Summarize(context.Background(), root, WithSkipDir(Names(".git")))
+	assert.NoError(t, err)
+	assert.Equal(t, 6, stats.Dirs)
+	assert.Equal(t, 21, stats.Files)
+	assert.Equal(t, int64(7*(12+5+4)), stats.Bytes)
+	assert.Equal(t, ExtStats{Ext: ".txt", Files: 7, Bytes: 35}, stats.Ext(".TXT"))
+	assert.Equal(t, ExtStats{Ext: "", Files: 7, Bytes: 28}, stats.Ext(""))
+	assert.Equal(t, ExtStats{Ext: ".md"}, stats.Ext(".md"))
+	assert.Equal(t, []string{".go", ".txt", ""}, []string{
+		stats.ByExtension()[0].Ext, stats.ByExtension()[1].Ext, stats.ByExtension()[2].Ext,
+	})
+	assert.Equal(t, "main.go", stats.Largest.Info.Name())
+}
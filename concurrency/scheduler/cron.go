@@ -0,0 +1,190 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCron cron 表达式格式错误
+var ErrInvalidCron = errors.New("无效的 cron 表达式")
+
+// cronField cron 表达式中一个字段的取值范围
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = [5]cronField{
+	{"分钟", 0, 59},
+	{"小时", 0, 23},
+	{"日", 1, 31},
+	{"月", 1, 12},
+	{"星期", 0, 6},
+}
+
+// cronDescriptors 常用表达式的简写
+var cronDescriptors = map[string]string{
+	"@yearly":  "0 0 1 1 *",
+	"@monthly": "0 0 1 * *",
+	"@weekly":  "0 0 * * 0",
+	"@daily":   "0 0 * * *",
+	"@hourly":  "0 * * * *",
+}
+
+// Cron 按 cron 表达式触发的调度规则
+//
+// 支持标准的五个字段：分钟 小时 日 月 星期（0 表示星期日），
+// 每个字段支持 *、数字、范围 a-b、列表 a,b 和步长 */n、a-b/n，
+// 以及 @hourly、@daily、@weekly、@monthly、@yearly 简写。
+// 与标准 cron 相同，日和星期都不是 * 时，满足任意一个即触发。
+type Cron struct {
+	expr                          string
+	minute, hour, dom, month, dow uint64 // 每个字段允许的取值，第 i 位表示取值 i
+	domRestricted, dowRestricted  bool   // 日、星期字段是否不是 *
+	location                      *time.Location
+}
+
+// ParseCron 解析 cron 表达式，按本地时区计算触发时间
+func ParseCron(expr string) (*Cron, error) {
+	spec := strings.TrimSpace(expr)
+	if full, ok := cronDescriptors[spec]; ok {
+		spec = full
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("%w: %q 需要 %d 个字段", ErrInvalidCron, expr, len(cronFields))
+	}
+
+	var bits [5]uint64
+	for i, part := range parts {
+		b, err := parseCronField(part, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrInvalidCron, expr, err)
+		}
+		bits[i] = b
+	}
+
+	return &Cron{
+		expr:          expr,
+		minute:        bits[0],
+		hour:          bits[1],
+		dom:           bits[2],
+		month:         bits[3],
+		dow:           bits[4],
+		domRestricted: parts[2] != "*",
+		dowRestricted: parts[4] != "*",
+		location:      time.Local,
+	}, nil
+}
+
+// MustParseCron 同 ParseCron，表达式错误时 panic，用于固定的表达式
+func MustParseCron(expr string) *Cron {
+	c, err := ParseCron(expr)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// In 返回按指定时区计算触发时间的副本
+func (c *Cron) In(location *time.Location) *Cron {
+	copied := *c
+	copied.location = location
+	return &copied
+}
+
+// String 返回原始表达式
+func (c *Cron) String() string {
+	return c.expr
+}
+
+// parseCronField 解析一个字段，返回允许取值的位图
+func parseCronField(field string, spec cronField) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(field, ",") {
+		rangePart, step := item, 1
+		if i := strings.IndexByte(item, '/'); i >= 0 {
+			n, err := strconv.Atoi(item[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%s字段的步长无效: %s", spec.name, item)
+			}
+			rangePart, step = item[:i], n
+		}
+
+		lo, hi := spec.min, spec.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(bounds[0])
+			hi, err2 = strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil || lo > hi {
+				return 0, fmt.Errorf("%s字段的范围无效: %s", spec.name, item)
+			}
+		default:
+			n, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("%s字段的取值无效: %s", spec.name, item)
+			}
+			lo, hi = n, n
+			if step > 1 {
+				// 与常见实现一致，n/step 表示从 n 开始到最大值
+				hi = spec.max
+			}
+		}
+		if lo < spec.min || hi > spec.max {
+			return 0, fmt.Errorf("%s字段超出范围 %d-%d: %s", spec.name, spec.min, spec.max, item)
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// Next 返回 after 之后的下一个触发时间，五年内都不会触发时返回零值
+func (c *Cron) Next(after time.Time) time.Time {
+	t := after.In(c.location).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if !has(c.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, c.location)
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, c.location)
+			continue
+		}
+		if !has(c.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, c.location)
+			continue
+		}
+		if !has(c.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches 日期是否满足日和星期字段
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom, dow := has(c.dom, t.Day()), has(c.dow, int(t.Weekday()))
+	if c.domRestricted && c.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// has 位图中是否包含 v
+func has(bits uint64, v int) bool {
+	return bits&(1<<uint(v)) != 0
+}
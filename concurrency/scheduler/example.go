@@ -0,0 +1,59 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// RunExample 运行任务调度器示例
+func RunExample() {
+	s := NewScheduler()
+
+	// 周期任务：每 40 毫秒上报一次心跳，加入抖动避免与其他实例同时上报
+	var beats atomic.Int32
+	s.Add("心跳", Every(40*time.Millisecond), func(context.Context) error {
+		beats.Add(1)
+		return nil
+	}, WithJitter(5*time.Millisecond))
+
+	// 执行时间超过间隔的任务：跳过重叠的执行，避免任务越积越多
+	s.Add("生成报表", Every(30*time.Millisecond), func(ctx context.Context) error {
+		select {
+		case <-time.After(70 * time.Millisecond):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}, WithOverlap(OverlapSkip))
+
+	// 一次性任务
+	s.Add("预热缓存", After(20*time.Millisecond), func(context.Context) error {
+		fmt.Println("缓存预热完成")
+		return nil
+	})
+
+	// cron 表达式：工作日 9 点到 17 点每 15 分钟
+	cron := MustParseCron("*/15 9-17 * * 1-5")
+	next := time.Date(2024, 3, 15, 17, 50, 0, 0, time.Local) // 星期五
+	fmt.Printf("cron %q 的下三次触发时间:\n", cron)
+	for i := 0; i < 3; i++ {
+		next = cron.Next(next)
+		fmt.Println(" ", next.Format("2006-01-02 Mon 15:04"))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	s.Run(ctx)
+
+	fmt.Printf("心跳次数: %d\n", beats.Load())
+	history, _ := s.History("生成报表")
+	for _, run := range history {
+		if run.Skipped {
+			fmt.Printf("生成报表: %s 跳过\n", run.Scheduled.Format("15:04:05.000"))
+		} else {
+			fmt.Printf("生成报表: %s 执行 %v，错误: %v\n", run.Scheduled.Format("15:04:05.000"), run.Duration.Round(time.Millisecond), run.Err)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package scheduler
+
+import "time"
+
+// Schedule 调度规则：决定任务下一次在什么时候执行
+type Schedule interface {
+	// Next 返回 after 之后的下一个执行时间，返回零值表示不再执行
+	Next(after time.Time) time.Time
+}
+
+// at 在指定时间执行一次
+type at struct {
+	time time.Time
+}
+
+// At 创建在指定时间执行一次的调度规则，时间已经过去时任务无法添加
+func At(t time.Time) Schedule {
+	return at{time: t}
+}
+
+// After 创建在 d 之后执行一次的调度规则，从调用 After 时开始计时
+func After(d time.Duration) Schedule {
+	return at{time: time.Now().Add(d)}
+}
+
+// Next 返回执行时间，已经过了执行时间时返回零值
+func (a at) Next(after time.Time) time.Time {
+	if after.Before(a.time) {
+		return a.time
+	}
+	return time.Time{}
+}
+
+// every 按固定间隔重复执行
+type every struct {
+	interval time.Duration
+}
+
+// Every 创建按固定间隔重复执行的调度规则，间隔必须大于 0
+func Every(interval time.Duration) Schedule {
+	if interval <= 0 {
+		panic("scheduler: 间隔必须大于 0")
+	}
+	return every{interval: interval}
+}
+
+// Next 返回 after 加上间隔
+func (e every) Next(after time.Time) time.Time {
+	return after.Add(e.interval)
+}
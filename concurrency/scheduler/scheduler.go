@@ -0,0 +1,377 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 调度器相关错误
+var (
+	ErrDuplicateJob   = errors.New("任务已存在")
+	ErrJobNotFound    = errors.New("任务不存在")
+	ErrNoFutureRun    = errors.New("调度规则不会再触发")
+	ErrAlreadyRunning = errors.New("调度器已在运行")
+	ErrJobPanic       = errors.New("任务发生 panic")
+)
+
+// OverlapPolicy 任务到了执行时间、上一次执行却还没结束时的处理策略
+type OverlapPolicy int
+
+const (
+	OverlapSkip       OverlapPolicy = iota // 跳过这一次执行，并在历史中记录为跳过（默认）
+	OverlapQueue                           // 排队，上一次执行结束后立即执行
+	OverlapConcurrent                      // 与上一次执行并发执行
+)
+
+// String 返回策略名称
+func (p OverlapPolicy) String() string {
+	switch p {
+	case OverlapSkip:
+		return "跳过"
+	case OverlapQueue:
+		return "排队"
+	case OverlapConcurrent:
+		return "并发"
+	default:
+		return fmt.Sprintf("OverlapPolicy(%d)", int(p))
+	}
+}
+
+// Job 任务函数，调度器关闭时 ctx 会被取消
+type Job func(ctx context.Context) error
+
+// Run 任务的一次执行记录
+type Run struct {
+	Scheduled time.Time     // 计划执行时间（包含抖动）
+	Started   time.Time     // 实际开始时间，跳过的执行为零值
+	Duration  time.Duration // 执行耗时
+	Err       error         // 任务返回的错误
+	Skipped   bool          // 是否因为上一次执行尚未结束而跳过
+}
+
+// JobOption 任务配置选项
+type JobOption func(*job)
+
+// WithOverlap 设置任务的重叠策略
+func WithOverlap(policy OverlapPolicy) JobOption {
+	return func(j *job) {
+		j.overlap = policy
+	}
+}
+
+// WithJitter 在每次计划执行时间上增加 [0, jitter) 的随机延迟，避免大量任务同时触发
+func WithJitter(jitter time.Duration) JobOption {
+	return func(j *job) {
+		if jitter > 0 {
+			j.jitter = jitter
+		}
+	}
+}
+
+// WithHistoryLimit 设置保留的执行记录条数，默认 100
+func WithHistoryLimit(n int) JobOption {
+	return func(j *job) {
+		if n > 0 {
+			j.historyLimit = n
+		}
+	}
+}
+
+// job 调度器中的一个任务
+type job struct {
+	name         string
+	schedule     Schedule
+	fn           Job
+	overlap      OverlapPolicy
+	jitter       time.Duration
+	historyLimit int
+
+	base    time.Time   // 调度规则给出的计划时间，不含抖动，作为计算下一次时间的起点
+	next    time.Time   // 下一次执行时间（含抖动），零值表示不再执行
+	active  int         // 正在执行的次数
+	queued  []time.Time // 排队等待执行的计划时间
+	history []Run
+}
+
+// Scheduler 任务调度器：按调度规则执行一次性任务和周期任务
+//
+// 调度协程只负责计算时间和分发，任务在各自的协程中执行，
+// 一个任务执行缓慢不会推迟其他任务。
+type Scheduler struct {
+	mutex   sync.Mutex
+	jobs    map[string]*job
+	running bool
+	runs    sync.WaitGroup // 正在执行的任务
+
+	wake chan struct{} // 添加或删除任务时通知调度协程重新计算等待时间
+
+	now    func() time.Time    // 时间来源，便于测试
+	random func(n int64) int64 // 抖动的随机数来源，便于测试
+}
+
+// NewScheduler 创建调度器
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		jobs:   make(map[string]*job),
+		wake:   make(chan struct{}, 1),
+		now:    time.Now,
+		random: rand.Int64N,
+	}
+}
+
+// Add 添加任务，可以在调度器运行前或运行中添加
+func (s *Scheduler) Add(name string, schedule Schedule, fn Job, opts ...JobOption) error {
+	j := &job{
+		name:         name,
+		schedule:     schedule,
+		fn:           fn,
+		historyLimit: 100,
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.jobs[name]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateJob, name)
+	}
+	j.base = schedule.Next(s.now())
+	if j.base.IsZero() {
+		return fmt.Errorf("%w: %s", ErrNoFutureRun, name)
+	}
+	j.next = s.jitterLocked(j)
+	s.jobs[name] = j
+	s.notify()
+	return nil
+}
+
+// Remove 删除任务，正在执行的任务不受影响，排队中的执行被丢弃
+func (s *Scheduler) Remove(name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	j, exists := s.jobs[name]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	}
+	j.next, j.queued = time.Time{}, nil
+	delete(s.jobs, name)
+	s.notify()
+	return nil
+}
+
+// Jobs 返回所有任务的名称
+func (s *Scheduler) Jobs() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	names := make([]string, 0, len(s.jobs))
+	for name := range s.jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NextRun 返回任务的下一次执行时间，不再执行时返回零值
+func (s *Scheduler) NextRun(name string) (time.Time, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	j, exists := s.jobs[name]
+	if !exists {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	}
+	return j.next, nil
+}
+
+// History 返回任务的执行记录：跳过的执行在到期时记录，其余执行在结束时记录
+func (s *Scheduler) History(name string) ([]Run, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	j, exists := s.jobs[name]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	}
+	return append([]Run(nil), j.history...), nil
+}
+
+// Run 运行调度器直到 ctx 被取消
+//
+// ctx 取消后不再开始新的执行，排队中的执行被丢弃，正在执行的任务收到取消信号；
+// Run 等所有正在执行的任务结束后才返回。
+func (s *Scheduler) Run(ctx context.Context) error {
+	s.mutex.Lock()
+	if s.running {
+		s.mutex.Unlock()
+		return ErrAlreadyRunning
+	}
+	s.running = true
+	s.mutex.Unlock()
+
+	defer func() {
+		s.runs.Wait()
+		s.mutex.Lock()
+		s.running = false
+		s.mutex.Unlock()
+	}()
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	for {
+		wait, ok := s.dispatchDue(ctx)
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		var fire <-chan time.Time
+		if ok {
+			timer.Reset(wait)
+			fire = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			s.dropQueued()
+			return nil
+		case <-s.wake:
+		case <-fire:
+		}
+	}
+}
+
+// dispatchDue 分发所有到期的任务，返回距离最近一次执行的等待时间，没有待执行的任务时返回 false
+func (s *Scheduler) dispatchDue(ctx context.Context) (time.Duration, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	var earliest time.Time
+	for _, j := range s.jobs {
+		if j.next.IsZero() {
+			continue
+		}
+		if !j.next.After(now) {
+			s.dispatchLocked(ctx, j, j.next)
+			s.advanceLocked(j, now)
+		}
+		if !j.next.IsZero() && (earliest.IsZero() || j.next.Before(earliest)) {
+			earliest = j.next
+		}
+	}
+
+	if earliest.IsZero() {
+		return 0, false
+	}
+	return earliest.Sub(now), true
+}
+
+// dispatchLocked 按重叠策略执行、排队或跳过一次到期的执行，调用者需持有锁
+func (s *Scheduler) dispatchLocked(ctx context.Context, j *job, scheduled time.Time) {
+	if j.active == 0 || j.overlap == OverlapConcurrent {
+		s.startLocked(ctx, j, scheduled)
+		return
+	}
+
+	switch j.overlap {
+	case OverlapQueue:
+		j.queued = append(j.queued, scheduled)
+	default:
+		s.recordLocked(j, Run{Scheduled: scheduled, Skipped: true})
+	}
+}
+
+// advanceLocked 计算任务的下一次执行时间，调用者需持有锁
+// 调度器落后太多时（例如系统休眠）不补执行错过的周期，而是从当前时间重新计算
+func (s *Scheduler) advanceLocked(j *job, now time.Time) {
+	base := j.schedule.Next(j.base)
+	if !base.IsZero() && !base.After(now) {
+		base = j.schedule.Next(now)
+	}
+	j.base = base
+	if base.IsZero() {
+		j.next = time.Time{}
+		return
+	}
+	j.next = s.jitterLocked(j)
+}
+
+// jitterLocked 在计划时间上增加随机抖动，调用者需持有锁
+func (s *Scheduler) jitterLocked(j *job) time.Time {
+	if j.jitter <= 0 {
+		return j.base
+	}
+	return j.base.Add(time.Duration(s.random(int64(j.jitter))))
+}
+
+// startLocked 在新的协程中执行任务，调用者需持有锁
+func (s *Scheduler) startLocked(ctx context.Context, j *job, scheduled time.Time) {
+	j.active++
+	s.runs.Add(1)
+	go func() {
+		defer s.runs.Done()
+
+		started := s.now()
+		err := call(ctx, j.fn)
+		run := Run{Scheduled: scheduled, Started: started, Duration: s.now().Sub(started), Err: err}
+
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		j.active--
+		s.recordLocked(j, run)
+		// 排队策略：上一次执行结束后立即开始下一次
+		if len(j.queued) > 0 && ctx.Err() == nil {
+			next := j.queued[0]
+			j.queued = j.queued[1:]
+			s.startLocked(ctx, j, next)
+		}
+	}()
+}
+
+// recordLocked 记录一次执行，超出保留条数时丢弃最早的记录，调用者需持有锁
+func (s *Scheduler) recordLocked(j *job, run Run) {
+	j.history = append(j.history, run)
+	if over := len(j.history) - j.historyLimit; over > 0 {
+		j.history = append(j.history[:0:0], j.history[over:]...)
+	}
+}
+
+// dropQueued 丢弃所有排队中的执行
+func (s *Scheduler) dropQueued() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, j := range s.jobs {
+		j.queued = nil
+	}
+}
+
+// notify 唤醒调度协程
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// call 执行任务并把 panic 转换为错误
+func call(ctx context.Context, fn Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrJobPanic, r)
+		}
+	}()
+	return fn(ctx)
+}
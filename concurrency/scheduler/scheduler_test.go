@@ -0,0 +1,255 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// start 在后台运行调度器，返回停止函数，停止函数等 Run 返回后才返回
+func start(t *testing.T, s *Scheduler) func() {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+	return func() {
+		cancel()
+		assert.NoError(t, <-done)
+	}
+}
+
+// concurrency 记录任务的最大并发执行数
+type concurrency struct {
+	active, peak atomic.Int32
+}
+
+func (c *concurrency) job(d time.Duration) Job {
+	return func(context.Context) error {
+		n := c.active.Add(1)
+		defer c.active.Add(-1)
+		for {
+			peak := c.peak.Load()
+			if n <= peak || c.peak.CompareAndSwap(peak, n) {
+				break
+			}
+		}
+		time.Sleep(d)
+		return nil
+	}
+}
+
+func countSkipped(history []Run) int {
+	skipped := 0
+	for _, run := range history {
+		if run.Skipped {
+			skipped++
+		}
+	}
+	return skipped
+}
+
+// TestCronNext 测试 cron 表达式计算下一次触发时间
+func TestCronNext(t *testing.T) {
+	base := time.Date(2024, 3, 15, 10, 7, 30, 0, time.UTC) // 星期五
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 3, 15, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 3, 15, 10, 15, 0, 0, time.UTC)},
+		{"5 * * * *", time.Date(2024, 3, 15, 11, 5, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2024, 3, 15, 13, 0, 0, 0, time.UTC)},
+		{"30 8 * * 1-5", time.Date(2024, 3, 18, 8, 30, 0, 0, time.UTC)}, // 跳过周末
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},    // 闰年
+		{"0 12 1,15 * *", time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)},
+		{"0 8 1,15 * *", time.Date(2024, 4, 1, 8, 0, 0, 0, time.UTC)},
+		{"0 0 1 * 1", time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC)}, // 日和星期满足任意一个
+		{"@monthly", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 3, 15, 11, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr)
+		if assert.NoError(t, err, tt.expr) {
+			assert.Equal(t, tt.want, c.In(time.UTC).Next(base), tt.expr)
+		}
+	}
+
+	assert.True(t, MustParseCron("0 0 31 2 *").Next(base).IsZero(), "永远不会触发的表达式返回零值")
+}
+
+// TestParseCronInvalid 测试无效的 cron 表达式
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 7", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err := ParseCron(expr)
+		assert.ErrorIs(t, err, ErrInvalidCron, expr)
+	}
+	assert.Panics(t, func() { MustParseCron("bad") })
+}
+
+// TestRecurringAndOneShot 测试周期任务和一次性任务
+func TestRecurringAndOneShot(t *testing.T) {
+	s := NewScheduler()
+	var ticks, once atomic.Int32
+	assert.NoError(t, s.Add("tick", Every(10*time.Millisecond), func(context.Context) error {
+		ticks.Add(1)
+		return nil
+	}))
+	assert.NoError(t, s.Add("once", After(20*time.Millisecond), func(context.Context) error {
+		once.Add(1)
+		return nil
+	}))
+	assert.ErrorIs(t, s.Add("tick", Every(time.Second), nil), ErrDuplicateJob)
+	assert.ErrorIs(t, s.Add("past", At(time.Now().Add(-time.Second)), nil), ErrNoFutureRun)
+	assert.Equal(t, []string{"once", "tick"}, s.Jobs())
+
+	stop := start(t, s)
+	assert.Eventually(t, func() bool { return ticks.Load() >= 5 && once.Load() == 1 }, time.Second, time.Millisecond)
+	stop()
+
+	assert.Equal(t, int32(1), once.Load(), "一次性任务只执行一次")
+	next, err := s.NextRun("once")
+	assert.NoError(t, err)
+	assert.True(t, next.IsZero())
+
+	history, err := s.History("tick")
+	assert.NoError(t, err)
+	assert.Equal(t, int(ticks.Load()), len(history))
+	for _, run := range history {
+		assert.False(t, run.Skipped)
+		assert.NoError(t, run.Err)
+		assert.False(t, run.Started.Before(run.Scheduled), "不会在计划时间之前执行")
+	}
+
+	assert.NoError(t, s.Remove("tick"))
+	assert.ErrorIs(t, s.Remove("tick"), ErrJobNotFound)
+	_, err = s.History("tick")
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
+
+// TestOverlapPolicies 测试执行时间超过间隔时的三种重叠策略
+func TestOverlapPolicies(t *testing.T) {
+	run := func(policy OverlapPolicy) (*concurrency, []Run) {
+		s := NewScheduler()
+		var c concurrency
+		assert.NoError(t, s.Add("slow", Every(10*time.Millisecond), c.job(35*time.Millisecond), WithOverlap(policy)))
+		stop := start(t, s)
+		time.Sleep(120 * time.Millisecond)
+		stop()
+		history, _ := s.History("slow")
+		return &c, history
+	}
+
+	t.Run(OverlapSkip.String(), func(t *testing.T) {
+		c, history := run(OverlapSkip)
+		assert.Equal(t, int32(1), c.peak.Load())
+		assert.Greater(t, countSkipped(history), 0, "重叠的执行被跳过")
+	})
+
+	t.Run(OverlapQueue.String(), func(t *testing.T) {
+		c, history := run(OverlapQueue)
+		assert.Equal(t, int32(1), c.peak.Load())
+		assert.Zero(t, countSkipped(history))
+		for i := 1; i < len(history); i++ {
+			prev := history[i-1]
+			assert.False(t, history[i].Started.Before(prev.Started.Add(prev.Duration)), "排队的执行在上一次结束后开始")
+		}
+	})
+
+	t.Run(OverlapConcurrent.String(), func(t *testing.T) {
+		c, history := run(OverlapConcurrent)
+		assert.Greater(t, c.peak.Load(), int32(1))
+		assert.Zero(t, countSkipped(history))
+	})
+}
+
+// TestJitter 测试抖动只推迟计划时间
+func TestJitter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewScheduler()
+	s.now = func() time.Time { return now }
+	s.random = func(n int64) int64 {
+		assert.Equal(t, int64(time.Second), n)
+		return int64(300 * time.Millisecond)
+	}
+
+	assert.NoError(t, s.Add("jittered", Every(time.Minute), func(context.Context) error { return nil }, WithJitter(time.Second)))
+	next, _ := s.NextRun("jittered")
+	assert.Equal(t, now.Add(time.Minute+300*time.Millisecond), next)
+
+	assert.NoError(t, s.Add("plain", Every(time.Minute), func(context.Context) error { return nil }))
+	next, _ = s.NextRun("plain")
+	assert.Equal(t, now.Add(time.Minute), next)
+}
+
+// TestShutdown 测试取消上下文后正在执行的任务收到取消信号，Run 等待其结束后返回
+func TestShutdown(t *testing.T) {
+	s := NewScheduler()
+	started := make(chan struct{})
+	var cancelled atomic.Bool
+	var once sync.Once
+	assert.NoError(t, s.Add("long", Every(5*time.Millisecond), func(ctx context.Context) error {
+		once.Do(func() { close(started) })
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond) // 收到取消后的清理工作
+		cancelled.Store(true)
+		return ctx.Err()
+	}, WithOverlap(OverlapQueue)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+	<-started
+	time.Sleep(20 * time.Millisecond) // 让后续的执行排队
+	assert.ErrorIs(t, s.Run(ctx), ErrAlreadyRunning)
+
+	cancel()
+	assert.NoError(t, <-done)
+	assert.True(t, cancelled.Load(), "Run 在任务结束后才返回")
+
+	history, _ := s.History("long")
+	assert.Len(t, history, 1, "排队中的执行被丢弃")
+	assert.ErrorIs(t, history[0].Err, context.Canceled)
+	assert.GreaterOrEqual(t, history[0].Duration, 10*time.Millisecond)
+}
+
+// TestPanicAndHistoryLimit 测试任务 panic 被记录为错误，历史记录按上限保留
+func TestPanicAndHistoryLimit(t *testing.T) {
+	s := NewScheduler()
+	var calls atomic.Int32
+	errOdd := errors.New("奇数次执行失败")
+	assert.NoError(t, s.Add("flaky", Every(5*time.Millisecond), func(context.Context) error {
+		switch n := calls.Add(1); {
+		case n == 1:
+			panic("boom")
+		case n%2 == 1:
+			return errOdd
+		}
+		return nil
+	}, WithHistoryLimit(3)))
+
+	stop := start(t, s)
+	assert.Eventually(t, func() bool { return calls.Load() >= 6 }, time.Second, time.Millisecond)
+	stop()
+
+	history, _ := s.History("flaky")
+	assert.Len(t, history, 3)
+	for _, run := range history {
+		assert.NotErrorIs(t, run.Err, ErrJobPanic, "最早的记录已被丢弃")
+	}
+
+	s = NewScheduler()
+	assert.NoError(t, s.Add("panic", After(time.Millisecond), func(context.Context) error { panic("boom") }))
+	stop = start(t, s)
+	assert.Eventually(t, func() bool {
+		history, _ := s.History("panic")
+		return len(history) == 1
+	}, time.Second, time.Millisecond)
+	stop()
+	history, _ = s.History("panic")
+	assert.ErrorIs(t, history[0].Err, ErrJobPanic)
+}
@@ -0,0 +1,67 @@
+package singleflight
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// QuoteService 行情查询服务：查询开销很大，同一只股票的并发查询只访问一次后端
+type QuoteService struct {
+	group   Group[float64]
+	fetch   func(symbol string) (float64, error) // 实际访问后端的查询
+	fetches atomic.Int64                         // 实际访问后端的次数
+}
+
+// NewQuoteService 创建行情查询服务
+func NewQuoteService(fetch func(symbol string) (float64, error)) *QuoteService {
+	return &QuoteService{fetch: fetch}
+}
+
+// Price 查询股票价格，并发的重复查询共享同一次后端访问的结果
+func (s *QuoteService) Price(symbol string) (float64, bool, error) {
+	price, err, shared := s.group.Do(symbol, func() (float64, error) {
+		s.fetches.Add(1)
+		return s.fetch(symbol)
+	})
+	return price, shared, err
+}
+
+// Refresh 放弃等待正在进行的查询，强制重新访问后端
+func (s *QuoteService) Refresh(symbol string) (float64, error) {
+	s.group.Forget(symbol)
+	price, _, err := s.Price(symbol)
+	return price, err
+}
+
+// Fetches 返回实际访问后端的次数
+func (s *QuoteService) Fetches() int64 {
+	return s.fetches.Load()
+}
+
+// RunExample 运行单飞模式示例
+func RunExample() {
+	service := NewQuoteService(func(symbol string) (float64, error) {
+		time.Sleep(50 * time.Millisecond) // 模拟较慢的后端查询
+		return 188.5, nil
+	})
+
+	var wg sync.WaitGroup
+	var shared atomic.Int64
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, isShared, err := service.Price("AAPL"); err == nil && isShared {
+				shared.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	fmt.Printf("10 次并发查询，实际访问后端 %d 次，%d 次共享了结果\n", service.Fetches(), shared.Load())
+
+	result := <-service.group.DoChan("MSFT", func() (float64, error) { return 415.2, nil })
+	fmt.Printf("DoChan 查询 MSFT: %.1f, 错误: %v\n", result.Val, result.Err)
+}
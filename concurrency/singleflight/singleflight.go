@@ -0,0 +1,121 @@
+package singleflight
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrPanicked 表示执行函数发生了 panic，等待同一个键的所有调用者都会得到该错误
+var ErrPanicked = errors.New("执行函数发生 panic")
+
+// Result DoChan 返回的结果
+type Result[T any] struct {
+	Val    T
+	Err    error
+	Shared bool // 结果是否被多个调用者共享
+}
+
+// call 某个键正在进行中的一次调用
+type call[T any] struct {
+	wg    sync.WaitGroup
+	val   T
+	err   error
+	dups  int                // 合并到这次调用的重复请求数
+	chans []chan<- Result[T] // 通过 DoChan 等待结果的调用者
+}
+
+// Group 单飞组：同一个键同时只执行一次函数，重复的请求等待并共享这次执行的结果
+// 零值可以直接使用
+type Group[T any] struct {
+	mutex sync.Mutex
+	calls map[string]*call[T]
+}
+
+// Do 执行并返回 fn 的结果
+// 同一个键已经有调用在进行时，不会再次执行 fn，而是等待并返回那次调用的结果；
+// shared 表示结果是否同时返回给了多个调用者
+func (g *Group[T]) Do(key string, fn func() (T, error)) (v T, err error, shared bool) {
+	g.mutex.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call[T])
+	}
+	if c, ok := g.calls[key]; ok {
+		c.dups++
+		g.mutex.Unlock()
+		c.wg.Wait()
+		return c.val, c.err, true
+	}
+	c := new(call[T])
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mutex.Unlock()
+
+	g.doCall(c, key, fn)
+	return c.val, c.err, c.dups > 0
+}
+
+// DoChan 与 Do 相同，但立即返回一个通道，结果就绪时发送到通道中
+// 通道有缓冲，调用者不读取也不会阻塞执行
+func (g *Group[T]) DoChan(key string, fn func() (T, error)) <-chan Result[T] {
+	ch := make(chan Result[T], 1)
+
+	g.mutex.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call[T])
+	}
+	if c, ok := g.calls[key]; ok {
+		c.dups++
+		c.chans = append(c.chans, ch)
+		g.mutex.Unlock()
+		return ch
+	}
+	c := &call[T]{chans: []chan<- Result[T]{ch}}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mutex.Unlock()
+
+	go g.doCall(c, key, fn)
+	return ch
+}
+
+// doCall 执行函数并把结果交给所有等待者，fn 中的 panic 会被转换为 ErrPanicked
+func (g *Group[T]) doCall(c *call[T], key string, fn func() (T, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			var zero T
+			c.val, c.err = zero, fmt.Errorf("%w: %v", ErrPanicked, r)
+		}
+
+		g.mutex.Lock()
+		defer g.mutex.Unlock()
+
+		c.wg.Done()
+		// 调用期间可能已经被 Forget，新的调用占用了这个键
+		if g.calls[key] == c {
+			delete(g.calls, key)
+		}
+		for _, ch := range c.chans {
+			ch <- Result[T]{Val: c.val, Err: c.err, Shared: c.dups > 0}
+		}
+	}()
+
+	c.val, c.err = fn()
+}
+
+// Forget 忘记键上正在进行的调用，之后对该键的请求会重新执行函数，
+// 而不是等待正在进行的调用；已经在等待的调用者仍然得到原来的结果
+func (g *Group[T]) Forget(key string) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	delete(g.calls, key)
+}
+
+// InFlight 返回正在进行调用的键的数量
+func (g *Group[T]) InFlight() int {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	return len(g.calls)
+}
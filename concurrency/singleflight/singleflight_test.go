@@ -0,0 +1,169 @@
+package singleflight
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDoSuppressesDuplicates 测试同一个键的并发请求只执行一次
+func TestDoSuppressesDuplicates(t *testing.T) {
+	var g Group[string]
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	const n = 20
+	var wg sync.WaitGroup
+	var sharedCount atomic.Int32
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err, shared := g.Do("user:1", func() (string, error) {
+				calls.Add(1)
+				<-release
+				return "张三", nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, "张三", v)
+			if shared {
+				sharedCount.Add(1)
+			}
+		}()
+	}
+
+	// 等待所有请求都合并到进行中的调用后再放行
+	assert.Eventually(t, func() bool {
+		g.mutex.Lock()
+		defer g.mutex.Unlock()
+		c, ok := g.calls["user:1"]
+		return ok && c.dups == n-1
+	}, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, int32(n), sharedCount.Load(), "所有调用者都知道结果被共享")
+	assert.Equal(t, 0, g.InFlight())
+
+	// 调用结束后再次请求会重新执行，且不是共享的结果
+	v, err, shared := g.Do("user:1", func() (string, error) { return "李四", nil })
+	assert.NoError(t, err)
+	assert.Equal(t, "李四", v)
+	assert.False(t, shared)
+}
+
+// TestDoErrorAndPanic 测试错误和 panic 都会返回给所有等待者
+func TestDoErrorAndPanic(t *testing.T) {
+	var g Group[int]
+	errNotFound := errors.New("未找到")
+
+	_, err, _ := g.Do("a", func() (int, error) { return 0, errNotFound })
+	assert.ErrorIs(t, err, errNotFound)
+
+	_, err, _ = g.Do("b", func() (int, error) { panic("boom") })
+	assert.ErrorIs(t, err, ErrPanicked)
+	assert.Contains(t, err.Error(), "boom")
+	assert.Equal(t, 0, g.InFlight(), "panic 后键被释放")
+}
+
+// TestDoChan 测试通过通道获取结果，并与 Do 合并
+func TestDoChan(t *testing.T) {
+	var g Group[int]
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fn := func() (int, error) {
+		calls.Add(1)
+		<-release
+		return 7, nil
+	}
+
+	first := g.DoChan("k", fn)
+	second := g.DoChan("k", fn)
+	done := make(chan Result[int])
+	go func() {
+		v, err, shared := g.Do("k", fn)
+		done <- Result[int]{Val: v, Err: err, Shared: shared}
+	}()
+
+	assert.Eventually(t, func() bool {
+		g.mutex.Lock()
+		defer g.mutex.Unlock()
+		return g.calls["k"].dups == 2
+	}, time.Second, time.Millisecond)
+	close(release)
+
+	for _, ch := range []<-chan Result[int]{first, second} {
+		result := <-ch
+		assert.Equal(t, Result[int]{Val: 7, Shared: true}, result)
+	}
+	assert.Equal(t, Result[int]{Val: 7, Shared: true}, <-done)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+// TestForget 测试忘记键之后的请求不再等待进行中的调用
+func TestForget(t *testing.T) {
+	var g Group[string]
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	slow := g.DoChan("config", func() (string, error) {
+		close(started)
+		<-release
+		return "旧配置", nil
+	})
+	<-started
+
+	g.Forget("config")
+	v, err, shared := g.Do("config", func() (string, error) { return "新配置", nil })
+	assert.NoError(t, err)
+	assert.Equal(t, "新配置", v)
+	assert.False(t, shared)
+
+	// 被忘记的调用结束时不会删除新调用占用的键
+	inFlight := g.DoChan("config", func() (string, error) {
+		<-release
+		return "第三次", nil
+	})
+	close(release)
+	assert.Equal(t, "旧配置", (<-slow).Val)
+	assert.Equal(t, "第三次", (<-inFlight).Val)
+	assert.Equal(t, 0, g.InFlight())
+}
+
+// TestQuoteService 测试较慢的查询被合并
+func TestQuoteService(t *testing.T) {
+	release := make(chan struct{})
+	service := NewQuoteService(func(symbol string) (float64, error) {
+		<-release
+		return 100, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			price, _, err := service.Price("AAPL")
+			assert.NoError(t, err)
+			assert.Equal(t, 100.0, price)
+		}()
+	}
+	assert.Eventually(t, func() bool {
+		service.group.mutex.Lock()
+		defer service.group.mutex.Unlock()
+		c, ok := service.group.calls["AAPL"]
+		return ok && c.dups == 9
+	}, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int64(1), service.Fetches())
+
+	_, err := service.Refresh("AAPL")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), service.Fetches())
+}
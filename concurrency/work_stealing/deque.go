@@ -0,0 +1,77 @@
+package work_stealing
+
+import "sync"
+
+// Deque 工作窃取使用的双端队列
+//
+// 所有者从前端压入和弹出（后进先出），最近压入的任务最先执行，数据还在缓存中；
+// 窃取者从后端取走（先进先出），拿到的是最早压入的任务。在分治算法中，
+// 越早压入的任务对应的子问题越大，一次窃取就能带走一大块工作，窃取的次数因此很少。
+//
+// 实现使用互斥锁保护的环形缓冲区。所有者和窃取者操作队列的两端，
+// 无锁的 Chase-Lev 队列可以让两端几乎不冲突，这里选择更容易验证正确性的加锁实现
+type Deque[T any] struct {
+	mutex sync.Mutex
+	buf   []T
+	head  int // 前端元素的下标
+	size  int
+}
+
+// Push 所有者把元素压入前端
+func (d *Deque[T]) Push(v T) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.size == len(d.buf) {
+		d.growLocked()
+	}
+	d.head = (d.head - 1 + len(d.buf)) % len(d.buf)
+	d.buf[d.head] = v
+	d.size++
+}
+
+// Pop 所有者从前端弹出最近压入的元素
+func (d *Deque[T]) Pop() (T, bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	var zero T
+	if d.size == 0 {
+		return zero, false
+	}
+	v := d.buf[d.head]
+	d.buf[d.head] = zero // 释放引用
+	d.head = (d.head + 1) % len(d.buf)
+	d.size--
+	return v, true
+}
+
+// Steal 窃取者从后端取走最早压入的元素
+func (d *Deque[T]) Steal() (T, bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	var zero T
+	if d.size == 0 {
+		return zero, false
+	}
+	tail := (d.head + d.size - 1) % len(d.buf)
+	v := d.buf[tail]
+	d.buf[tail] = zero
+	d.size--
+	return v, true
+}
+
+// Len 返回队列中的元素个数
+func (d *Deque[T]) Len() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.size
+}
+
+// growLocked 把缓冲区扩大一倍，调用时必须持有锁
+func (d *Deque[T]) growLocked() {
+	buf := make([]T, max(2*len(d.buf), 16))
+	for i := 0; i < d.size; i++ {
+		buf[i] = d.buf[(d.head+i)%len(d.buf)]
+	}
+	d.buf = buf
+	d.head = 0
+}
@@ -0,0 +1,56 @@
+package work_stealing
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// fib 用 Spawn/Join 递归计算斐波那契数，演示任务嵌套派生和等待子任务
+func fib(w *Worker, n int) int {
+	if n < 15 {
+		return serialFib(n)
+	}
+	x := Spawn(w, func(w *Worker) int { return fib(w, n-1) })
+	y := fib(w, n-2)
+	return x.Join(w) + y
+}
+
+// serialFib 串行计算斐波那契数
+func serialFib(n int) int {
+	if n < 2 {
+		return n
+	}
+	return serialFib(n-1) + serialFib(n-2)
+}
+
+// RunExample 运行工作窃取示例：嵌套的分治任务和并行归并排序
+func RunExample() {
+	pool := New(4)
+	defer pool.Close()
+
+	fmt.Println("== 斐波那契（嵌套派生子任务）==")
+	n, _ := Run(pool, func(w *Worker) int { return fib(w, 30) })
+	fmt.Printf("fib(30) = %d\n", n)
+	stats := pool.Stats()
+	fmt.Printf("派生 %d 个子任务，其中 %d 个被窃取，%d 个在 Join 等待期间执行\n",
+		stats.Spawned, stats.Stolen, stats.Helped)
+
+	fmt.Println("== 并行归并排序 ==")
+	rng := rand.New(rand.NewPCG(1, 2))
+	data := make([]int, 1_000_000)
+	for i := range data {
+		data[i] = rng.IntN(1_000_000)
+	}
+	start := time.Now()
+	if err := MergeSort(pool, data, DefaultCutoff); err != nil {
+		fmt.Println("排序失败:", err)
+		return
+	}
+	fmt.Printf("排序 %d 个元素耗时 %v，结果有序: %v\n", len(data), time.Since(start), slices.IsSorted(data))
+
+	after := pool.Stats()
+	fmt.Printf("本次排序派生 %d 个子任务，窃取 %d 次\n",
+		after.Spawned-stats.Spawned, after.Stolen-stats.Stolen)
+}
@@ -0,0 +1,273 @@
+package work_stealing
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// ErrClosed 池已关闭，不再接受新任务
+	ErrClosed = errors.New("工作窃取池已关闭")
+	// ErrPanicked 任务发生了 panic，panic 沿 Join 逐层传播到 Run 后转换为该错误
+	ErrPanicked = errors.New("任务发生 panic")
+)
+
+// job 队列中的任务，由执行它的工作者调用
+type job func(w *Worker)
+
+// Stats 池的运行统计
+type Stats struct {
+	Workers  int   // 工作者数量
+	Executed int64 // 已执行的任务数
+	Spawned  int64 // 通过 Spawn 创建的子任务数
+	Stolen   int64 // 从其他工作者的队列中窃取的任务数
+	Helped   int64 // 在 Join 等待期间代为执行的任务数
+}
+
+// Pool 工作窃取池
+//
+// 每个工作者有自己的双端队列：Spawn 把子任务压入当前工作者队列的前端，
+// 工作者优先从自己队列的前端取任务；自己的队列空了，就随机挑选其他工作者，
+// 从其队列的后端窃取任务。Join 等待子任务时不会闲着，而是继续执行队列中的任务，
+// 所以任务可以任意嵌套地派生和等待子任务，而不会像固定大小的执行器那样因为
+// 所有工作者都在等待子任务而死锁
+type Pool struct {
+	workers []*Worker
+	inject  Deque[job]     // Run 提交的根任务
+	wake    chan struct{}  // 有新任务时唤醒空闲的工作者
+	quit    chan struct{}  // 关闭时通知工作者退出
+	pending sync.WaitGroup // 已入队但尚未执行完的任务
+	wg      sync.WaitGroup // 工作者协程
+
+	mutex  sync.Mutex // 保护 closed
+	closed bool
+
+	executed atomic.Int64
+	spawned  atomic.Int64
+	stolen   atomic.Int64
+	helped   atomic.Int64
+}
+
+// Worker 池中的一个工作者
+//
+// 任务函数收到的 *Worker 就是正在执行它的工作者，只能在该任务中使用，
+// 不能保存下来在其他协程中调用 Spawn 或 Join
+type Worker struct {
+	id    int
+	pool  *Pool
+	deque Deque[job]
+	rng   *rand.Rand // 选择窃取对象，只由所属的工作者协程使用
+}
+
+// New 创建有 workers 个工作者的池，workers 不大于 0 时使用 GOMAXPROCS
+func New(workers int) *Pool {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	p := &Pool{
+		workers: make([]*Worker, workers),
+		wake:    make(chan struct{}, workers),
+		quit:    make(chan struct{}),
+	}
+	for i := range p.workers {
+		p.workers[i] = &Worker{id: i, pool: p, rng: rand.New(rand.NewPCG(uint64(i), 0))}
+	}
+	p.wg.Add(workers)
+	for _, w := range p.workers {
+		go w.loop()
+	}
+	return p
+}
+
+// Run 在池中执行根任务 fn 并等待其结果，fn 中可以用 Spawn 和 Join 并行地处理子问题
+// 可以在多个协程中同时调用；fn 或其子任务发生 panic 时返回 ErrPanicked
+func Run[T any](p *Pool, fn func(w *Worker) T) (T, error) {
+	h := newHandle[T]()
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		var zero T
+		return zero, ErrClosed
+	}
+	p.pending.Add(1)
+	p.mutex.Unlock()
+
+	p.inject.Push(func(w *Worker) { h.execute(w, fn) })
+	p.signal()
+
+	<-h.done
+	if h.panicked {
+		return h.value, fmt.Errorf("%w: %v", ErrPanicked, h.panicValue)
+	}
+	return h.value, nil
+}
+
+// Workers 返回工作者数量
+func (p *Pool) Workers() int {
+	return len(p.workers)
+}
+
+// Stats 返回运行统计
+func (p *Pool) Stats() Stats {
+	return Stats{
+		Workers:  len(p.workers),
+		Executed: p.executed.Load(),
+		Spawned:  p.spawned.Load(),
+		Stolen:   p.stolen.Load(),
+		Helped:   p.helped.Load(),
+	}
+}
+
+// Close 关闭池：不再接受新的 Run，等待已提交的任务全部完成后停止工作者，重复调用无副作用
+func (p *Pool) Close() {
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return
+	}
+	p.closed = true
+	p.mutex.Unlock()
+
+	p.pending.Wait()
+	close(p.quit)
+	p.wg.Wait()
+}
+
+// signal 唤醒一个空闲的工作者；缓冲区满说明已有足够多的唤醒在途，直接放弃
+func (p *Pool) signal() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// ID 返回工作者编号，从 0 开始
+func (w *Worker) ID() int {
+	return w.id
+}
+
+// loop 工作者主循环：找到任务就执行，找不到就等待唤醒
+func (w *Worker) loop() {
+	defer w.pool.wg.Done()
+	for {
+		if j, ok := w.find(); ok {
+			w.execute(j)
+			continue
+		}
+		select {
+		case <-w.pool.wake:
+		case <-w.pool.quit:
+			return
+		}
+	}
+}
+
+// find 依次从自己的队列、根任务队列和其他工作者的队列中寻找任务
+func (w *Worker) find() (job, bool) {
+	if j, ok := w.deque.Pop(); ok {
+		return j, true
+	}
+	if j, ok := w.pool.inject.Steal(); ok {
+		return j, true
+	}
+	return w.steal()
+}
+
+// steal 从随机位置开始轮询其他工作者，从其队列后端窃取一个任务
+func (w *Worker) steal() (job, bool) {
+	workers := w.pool.workers
+	start := w.rng.IntN(len(workers))
+	for i := range workers {
+		victim := workers[(start+i)%len(workers)]
+		if victim == w {
+			continue
+		}
+		if j, ok := victim.deque.Steal(); ok {
+			w.pool.stolen.Add(1)
+			return j, true
+		}
+	}
+	return nil, false
+}
+
+// execute 执行一个任务并记录统计
+func (w *Worker) execute(j job) {
+	defer w.pool.pending.Done()
+	w.pool.executed.Add(1)
+	j(w)
+}
+
+// Handle 派生出的子任务，通过 Join 等待其结果
+type Handle[T any] struct {
+	done       chan struct{}
+	value      T
+	panicked   bool
+	panicValue any
+}
+
+// newHandle 创建未完成的子任务句柄
+func newHandle[T any]() *Handle[T] {
+	return &Handle[T]{done: make(chan struct{})}
+}
+
+// Spawn 把子任务 fn 压入当前工作者队列的前端，返回的句柄用于等待结果
+// 空闲的工作者可能把它窃取走并行执行；没有被窃取时，Join 会在当前工作者上直接执行它
+func Spawn[T any](w *Worker, fn func(w *Worker) T) *Handle[T] {
+	h := newHandle[T]()
+	p := w.pool
+	p.pending.Add(1)
+	p.spawned.Add(1)
+	w.deque.Push(func(w *Worker) { h.execute(w, fn) })
+	p.signal()
+	return h
+}
+
+// Join 等待子任务完成并返回其结果，w 是调用方任务所在的工作者
+//
+// 等待期间先执行自己队列中的任务（通常就是要等待的子任务本身），
+// 再尝试从其他工作者窃取任务；都没有任务可做时才阻塞。子任务发生 panic 时，
+// Join 以同样的值重新 panic，使 panic 像普通函数调用一样向上传播
+func (h *Handle[T]) Join(w *Worker) T {
+	for {
+		select {
+		case <-h.done:
+			return h.result()
+		default:
+		}
+		j, ok := w.deque.Pop()
+		if !ok {
+			j, ok = w.steal()
+		}
+		if !ok {
+			// 只有所有者会向自己的队列压入任务，队列已空说明子任务已被其他工作者窃取并正在执行，
+			// 阻塞等待不会死锁
+			<-h.done
+			return h.result()
+		}
+		w.pool.helped.Add(1)
+		w.execute(j)
+	}
+}
+
+// execute 执行 fn 并保存结果，panic 被恢复后由 Join 或 Run 重新抛出
+func (h *Handle[T]) execute(w *Worker, fn func(w *Worker) T) {
+	defer close(h.done)
+	defer func() {
+		if r := recover(); r != nil {
+			h.panicked, h.panicValue = true, r
+		}
+	}()
+	h.value = fn(w)
+}
+
+// result 返回结果，子任务发生 panic 时重新 panic
+func (h *Handle[T]) result() T {
+	if h.panicked {
+		panic(h.panicValue)
+	}
+	return h.value
+}
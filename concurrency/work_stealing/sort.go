@@ -0,0 +1,61 @@
+package work_stealing
+
+import (
+	"cmp"
+	"slices"
+)
+
+// DefaultCutoff 并行归并排序的默认阈值，更短的子数组直接串行排序
+const DefaultCutoff = 2048
+
+// MergeSort 在池中对 data 做并行归并排序
+//
+// 每一层把数组一分为二：左半部分 Spawn 成子任务，右半部分在当前工作者上继续递归，
+// 然后 Join 左半部分并合并。长度不超过 cutoff 的子数组直接串行排序，
+// cutoff 不大于 0 时使用 DefaultCutoff
+func MergeSort[T cmp.Ordered](p *Pool, data []T, cutoff int) error {
+	if cutoff <= 0 {
+		cutoff = DefaultCutoff
+	}
+	buf := make([]T, len(data))
+	_, err := Run(p, func(w *Worker) struct{} {
+		mergeSort(w, data, buf, cutoff)
+		return struct{}{}
+	})
+	return err
+}
+
+// mergeSort 递归排序 data，buf 是与 data 等长的临时空间
+func mergeSort[T cmp.Ordered](w *Worker, data, buf []T, cutoff int) {
+	if len(data) <= cutoff {
+		slices.Sort(data)
+		return
+	}
+	mid := len(data) / 2
+	left := Spawn(w, func(w *Worker) struct{} {
+		mergeSort(w, data[:mid], buf[:mid], cutoff)
+		return struct{}{}
+	})
+	mergeSort(w, data[mid:], buf[mid:], cutoff)
+	left.Join(w)
+
+	merge(data[:mid], data[mid:], buf)
+	copy(data, buf)
+}
+
+// merge 把两个有序切片合并到 out，相等的元素 a 中的排在前面
+func merge[T cmp.Ordered](a, b, out []T) {
+	i, j, k := 0, 0, 0
+	for i < len(a) && j < len(b) {
+		if cmp.Less(b[j], a[i]) {
+			out[k] = b[j]
+			j++
+		} else {
+			out[k] = a[i]
+			i++
+		}
+		k++
+	}
+	k += copy(out[k:], a[i:])
+	copy(out[k:], b[j:])
+}
@@ -0,0 +1,386 @@
+package work_stealing
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/XiaoluCoding626/go-design-pattern/concurrency/bounded_parallelism"
+)
+
+// TestDequeOrder 测试所有者后进先出、窃取者先进先出，以及环形缓冲区扩容
+func TestDequeOrder(t *testing.T) {
+	var d Deque[int]
+	_, ok := d.Pop()
+	assert.False(t, ok)
+	_, ok = d.Steal()
+	assert.False(t, ok)
+
+	// 先制造环绕再扩容
+	for i := 0; i < 10; i++ {
+		d.Push(i)
+	}
+	for i := 0; i < 5; i++ {
+		v, _ := d.Steal()
+		assert.Equal(t, i, v, "窃取最早压入的元素")
+	}
+	for i := 10; i < 40; i++ {
+		d.Push(i)
+	}
+	assert.Equal(t, 35, d.Len())
+
+	v, _ := d.Pop()
+	assert.Equal(t, 39, v, "所有者弹出最近压入的元素")
+	v, _ = d.Steal()
+	assert.Equal(t, 5, v)
+
+	var rest []int
+	for {
+		v, ok := d.Pop()
+		if !ok {
+			break
+		}
+		rest = append(rest, v)
+	}
+	want := make([]int, 0, 33)
+	for i := 38; i >= 10; i-- {
+		want = append(want, i)
+	}
+	for i := 9; i >= 6; i-- {
+		want = append(want, i)
+	}
+	assert.Equal(t, want, rest)
+}
+
+// TestDequeConcurrent 测试所有者与多个窃取者并发操作时每个元素恰好被取出一次
+func TestDequeConcurrent(t *testing.T) {
+	const n = 20000
+	var d Deque[int]
+	seen := make([]int, n)
+	var mutex sync.Mutex
+	record := func(v int) {
+		mutex.Lock()
+		seen[v]++
+		mutex.Unlock()
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if v, ok := d.Steal(); ok {
+					record(v)
+					continue
+				}
+				select {
+				case <-done:
+					return
+				default:
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		d.Push(i)
+		if i%3 == 0 {
+			if v, ok := d.Pop(); ok {
+				record(v)
+			}
+		}
+	}
+	for {
+		v, ok := d.Pop()
+		if !ok {
+			break
+		}
+		record(v)
+	}
+	close(done)
+	wg.Wait()
+
+	for i, c := range seen {
+		if c != 1 {
+			t.Fatalf("元素 %d 被取出 %d 次", i, c)
+		}
+	}
+}
+
+// TestRunNested 测试嵌套派生和等待子任务的结果与统计
+func TestRunNested(t *testing.T) {
+	pool := New(4)
+	defer pool.Close()
+	assert.Equal(t, 4, pool.Workers())
+
+	n, err := Run(pool, func(w *Worker) int { return fib(w, 25) })
+	assert.NoError(t, err)
+	assert.Equal(t, serialFib(25), n)
+
+	stats := pool.Stats()
+	// fib(k) 在 k >= 15 时派生一个子任务
+	spawns := 0
+	var count func(k int)
+	count = func(k int) {
+		if k < 15 {
+			return
+		}
+		spawns++
+		count(k - 1)
+		count(k - 2)
+	}
+	count(25)
+	assert.Equal(t, int64(spawns), stats.Spawned)
+	assert.Equal(t, int64(spawns+1), stats.Executed, "每个子任务和根任务各执行一次")
+}
+
+// TestRunConcurrent 测试多个协程同时向同一个池提交根任务
+func TestRunConcurrent(t *testing.T) {
+	pool := New(3)
+	defer pool.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := Run(pool, func(w *Worker) int { return fib(w, 18+i%4) })
+			assert.NoError(t, err)
+			assert.Equal(t, serialFib(18+i%4), n)
+		}()
+	}
+	wg.Wait()
+}
+
+// TestMergeSort 测试并行归并排序与标准库排序结果一致
+func TestMergeSort(t *testing.T) {
+	pool := New(4)
+	defer pool.Close()
+
+	rng := rand.New(rand.NewPCG(3, 4))
+	for _, size := range []int{0, 1, 7, 100, 5000, 100_000} {
+		data := make([]int, size)
+		for i := range data {
+			data[i] = rng.IntN(size/2 + 1) // 包含大量重复值
+		}
+		want := slices.Clone(data)
+		slices.Sort(want)
+
+		assert.NoError(t, MergeSort(pool, data, 64))
+		assert.Equal(t, want, data, "长度 %d", size)
+	}
+
+	words := []string{"pear", "apple", "fig", "kiwi", "banana"}
+	assert.NoError(t, MergeSort(pool, words, 1))
+	assert.Equal(t, []string{"apple", "banana", "fig", "kiwi", "pear"}, words)
+}
+
+// TestPanicPropagates 测试子任务的 panic 沿 Join 传播到 Run，池仍可继续使用
+func TestPanicPropagates(t *testing.T) {
+	pool := New(2)
+	defer pool.Close()
+
+	_, err := Run(pool, func(w *Worker) int {
+		h := Spawn(w, func(w *Worker) int { panic("boom") })
+		return h.Join(w)
+	})
+	assert.ErrorIs(t, err, ErrPanicked)
+	assert.Contains(t, err.Error(), "boom")
+
+	n, err := Run(pool, func(w *Worker) int { return 42 })
+	assert.NoError(t, err)
+	assert.Equal(t, 42, n)
+}
+
+// TestClose 测试关闭后拒绝新任务，关闭前提交的任务全部完成
+func TestClose(t *testing.T) {
+	pool := New(2)
+	results := make(chan int, 1)
+	started := make(chan struct{})
+	go func() {
+		n, _ := Run(pool, func(w *Worker) int {
+			close(started)
+			return fib(w, 22)
+		})
+		results <- n
+	}()
+	<-started
+
+	pool.Close()
+	pool.Close()
+	assert.Equal(t, serialFib(22), <-results)
+
+	_, err := Run(pool, func(w *Worker) int { return 0 })
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+// quiet 在基准测试期间丢弃标准输出，有界执行器会为每个任务打印日志
+func quiet(b *testing.B) {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = devNull
+	b.Cleanup(func() {
+		os.Stdout = stdout
+		devNull.Close()
+	})
+}
+
+// executorRun 用有界执行器并行执行一组任务并等待全部完成
+func executorRun(workers int, tasks []func()) {
+	executor := bounded_parallelism.NewBoundedExecutor[struct{}](workers, len(tasks))
+	for i, task := range tasks {
+		executor.Submit(bounded_parallelism.Task[struct{}]{
+			ID: fmt.Sprint(i),
+			Execute: func() (struct{}, error) {
+				task()
+				return struct{}{}, nil
+			},
+		})
+	}
+	for range tasks {
+		<-executor.Results()
+	}
+	executor.Shutdown()
+}
+
+// executorMergeSort 固定执行器上的归并排序
+// 执行器的任务不能等待子任务，只能预先切成 workers 块并行排序，再逐轮两两合并
+func executorMergeSort(workers int, data []int) {
+	buf := make([]int, len(data))
+	size := (len(data) + workers - 1) / workers
+	var tasks []func()
+	for lo := 0; lo < len(data); lo += size {
+		chunk := data[lo:min(lo+size, len(data))]
+		tasks = append(tasks, func() { slices.Sort(chunk) })
+	}
+	executorRun(workers, tasks)
+
+	for ; size < len(data); size *= 2 {
+		tasks = tasks[:0]
+		for lo := 0; lo+size < len(data); lo += 2 * size {
+			mid, hi := lo+size, min(lo+2*size, len(data))
+			tasks = append(tasks, func() {
+				merge(data[lo:mid], data[mid:hi], buf[lo:hi])
+				copy(data[lo:hi], buf[lo:hi])
+			})
+		}
+		executorRun(workers, tasks)
+	}
+}
+
+// benchData 基准测试的输入，每次迭代前复制一份
+func benchData(n int) []int {
+	rng := rand.New(rand.NewPCG(5, 6))
+	data := make([]int, n)
+	for i := range data {
+		data[i] = rng.Int()
+	}
+	return data
+}
+
+// BenchmarkMergeSort 比较串行排序、工作窃取和固定执行器上的归并排序
+// 固定执行器的合并阶段越往后任务越少，最后一轮只有一个任务在工作
+func BenchmarkMergeSort(b *testing.B) {
+	quiet(b)
+	input := benchData(1 << 20)
+	data := make([]int, len(input))
+	workers := runtime.GOMAXPROCS(0)
+
+	b.Run("Serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			copy(data, input)
+			slices.Sort(data)
+		}
+	})
+	b.Run("WorkStealing", func(b *testing.B) {
+		pool := New(workers)
+		defer pool.Close()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			copy(data, input)
+			MergeSort(pool, data, DefaultCutoff)
+		}
+	})
+	b.Run("BoundedExecutor", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			copy(data, input)
+			executorMergeSort(workers, data)
+		}
+	})
+}
+
+// skewedCost 第 i 个元素的计算量，前 1/8 的元素是其余元素的 64 倍
+func skewedCost(i, n int) int {
+	rounds := 1
+	if i < n/8 {
+		rounds = 64
+	}
+	x := i
+	for r := 0; r < rounds*16; r++ {
+		x = x*1103515245 + 12345
+	}
+	return x & 1
+}
+
+// parallelSum 用工作窃取递归二分区间，子区间足够小时串行计算
+func parallelSum(w *Worker, lo, hi, n int) int {
+	if hi-lo <= 256 {
+		sum := 0
+		for i := lo; i < hi; i++ {
+			sum += skewedCost(i, n)
+		}
+		return sum
+	}
+	mid := (lo + hi) / 2
+	left := Spawn(w, func(w *Worker) int { return parallelSum(w, lo, mid, n) })
+	right := parallelSum(w, mid, hi, n)
+	return left.Join(w) + right
+}
+
+// BenchmarkSkewed 比较计算量分布不均时的负载均衡
+// 固定执行器按工作者数量均匀切块，计算量集中的那一块决定了总耗时；
+// 工作窃取把大块任务不断二分，空闲的工作者随时能窃取到剩余的工作
+func BenchmarkSkewed(b *testing.B) {
+	quiet(b)
+	const n = 1 << 16
+	workers := runtime.GOMAXPROCS(0)
+
+	b.Run("WorkStealing", func(b *testing.B) {
+		pool := New(workers)
+		defer pool.Close()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			Run(pool, func(w *Worker) int { return parallelSum(w, 0, n, n) })
+		}
+	})
+	b.Run("BoundedExecutor", func(b *testing.B) {
+		size := (n + workers - 1) / workers
+		for i := 0; i < b.N; i++ {
+			var tasks []func()
+			var total atomic.Int64
+			for lo := 0; lo < n; lo += size {
+				hi := min(lo+size, n)
+				tasks = append(tasks, func() {
+					sum := 0
+					for j := lo; j < hi; j++ {
+						sum += skewedCost(j, n)
+					}
+					total.Add(int64(sum))
+				})
+			}
+			executorRun(workers, tasks)
+		}
+	})
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 )
 
+// ErrMissingField 表示构建汽车时缺少必要的组件
+var ErrMissingField = errors.New("缺少必要的组件")
+
 // CarType 定义汽车类型
 type CarType string
 
@@ -17,19 +20,20 @@ const (
 
 // ICar 汽车接口，定义汽车应该具备的能力
 type ICar interface {
-	Speed() int                            // 获取最大速度
-	Brand() string                         // 获取品牌
-	Type() CarType                         // 获取汽车类型
-	Brief()                                // 打印汽车简介
-	GetAttributes() map[string]interface{} // 获取所有属性
+	Speed() int                                     // 获取最大速度
+	Brand() string                                  // 获取品牌
+	Type() CarType                                  // 获取汽车类型
+	Brief()                                         // 打印汽车简介
+	GetAttributes() map[string]interface{}          // 获取所有属性
+	Equals(other ICar, ignoreFields ...string) bool // 比较两辆车是否相同，可忽略指定字段
 }
 
 // ICarBuilder 汽车建造者接口，定义建造一辆车所需的步骤
 type ICarBuilder interface {
 	SetType(carType CarType) ICarBuilder                          // 设置车型
-	SetWheel(size int, brand string) ICarBuilder                  // 设置车轮
-	SetEngine(engine string, power int) ICarBuilder               // 设置引擎
-	SetSpeed(max int) ICarBuilder                                 // 设置最大速度
+	SetWheelSize(size WheelSize, brand string) ICarBuilder        // 设置车轮
+	SetEnginePower(engine string, power Power) ICarBuilder        // 设置引擎
+	SetMaxSpeed(max Speed) ICarBuilder                            // 设置最大速度
 	SetBrand(brand string) ICarBuilder                            // 设置品牌
 	SetColor(color string) ICarBuilder                            // 设置颜色
 	SetSeats(seats int) ICarBuilder                               // 设置座位数
@@ -37,6 +41,13 @@ type ICarBuilder interface {
 	AddFeature(featureName string, value interface{}) ICarBuilder // 添加特性
 	Reset() ICarBuilder                                           // 重置构建器
 	Build() (ICar, error)                                         // 构建汽车
+
+	// Deprecated: 使用 SetWheelSize
+	SetWheel(size int, brand string) ICarBuilder
+	// Deprecated: 使用 SetEnginePower
+	SetEngine(engine string, power int) ICarBuilder
+	// Deprecated: 使用 SetMaxSpeed
+	SetSpeed(max int) ICarBuilder
 }
 
 // Car 具体的汽车结构体
@@ -52,6 +63,7 @@ type Car struct {
 	seats      int                    // 座位数
 	fuelType   string                 // 燃料类型
 	features   map[string]interface{} // 额外特性
+	locale     string                 // Brief 使用的语言区域，为空时使用默认语言区域
 }
 
 // Speed 返回汽车最大速度
@@ -69,18 +81,19 @@ func (c *Car) Type() CarType {
 	return c.carType
 }
 
-// Brief 打印汽车简介
+// Brief 打印汽车简介，使用建造者的语言区域
 func (c *Car) Brief() {
-	fmt.Printf("这是一辆%s的%s\n", c.brandName, c.carType)
-	fmt.Printf("车轮: %d英寸 %s品牌\n", c.wheelSize, c.wheelBrand)
-	fmt.Printf("引擎: %s (%d马力)\n", c.engine, c.power)
-	fmt.Printf("最大速度: %d公里/小时\n", c.maxSpeed)
-	fmt.Printf("颜色: %s\n", c.color)
-	fmt.Printf("座位数: %d\n", c.seats)
-	fmt.Printf("燃料类型: %s\n", c.fuelType)
+	l := c.locale
+	fmt.Println(message(l, MsgBriefTitle, c.brandName, carTypeName(l, c.carType)))
+	fmt.Println(message(l, MsgBriefWheel, message(l, MsgUnitWheelSize, c.wheelSize), c.wheelBrand))
+	fmt.Println(message(l, MsgBriefEngine, c.engine, message(l, MsgUnitPower, c.power)))
+	fmt.Println(message(l, MsgBriefMaxSpeed, message(l, MsgUnitSpeed, c.maxSpeed)))
+	fmt.Println(message(l, MsgBriefColor, c.color))
+	fmt.Println(message(l, MsgBriefSeats, c.seats))
+	fmt.Println(message(l, MsgBriefFuelType, c.fuelType))
 
 	if len(c.features) > 0 {
-		fmt.Println("额外特性:")
+		fmt.Println(message(l, MsgBriefFeatures))
 		for name, value := range c.features {
 			fmt.Printf("  - %s: %v\n", name, value)
 		}
@@ -106,7 +119,9 @@ func (c *Car) GetAttributes() map[string]interface{} {
 
 // CarBuilder 汽车建造者具体实现
 type CarBuilder struct {
-	car *Car // 正在构建的汽车
+	car    *Car   // 正在构建的汽车
+	err    error  // 设置过程中出现的校验错误，在 Build 时返回
+	locale string // 错误信息和简介使用的语言区域，为空时使用默认语言区域
 }
 
 // NewCarBuilder 创建新的汽车建造者实例
@@ -122,26 +137,53 @@ func (b *CarBuilder) SetType(carType CarType) ICarBuilder {
 	return b
 }
 
-// SetWheel 设置车轮大小和品牌
-func (b *CarBuilder) SetWheel(size int, brand string) ICarBuilder {
-	b.car.wheelSize = size
+// SetWheelSize 设置车轮尺寸和品牌，尺寸超出范围时记录错误并保留原值
+func (b *CarBuilder) SetWheelSize(size WheelSize, brand string) ICarBuilder {
+	if b.check(size.validate(b.locale)) {
+		b.car.wheelSize = int(size)
+	}
 	b.car.wheelBrand = brand
 	return b
 }
 
-// SetEngine 设置引擎型号和功率
-func (b *CarBuilder) SetEngine(engine string, power int) ICarBuilder {
+// SetEnginePower 设置引擎型号和功率，功率超出范围时记录错误并保留原值
+func (b *CarBuilder) SetEnginePower(engine string, power Power) ICarBuilder {
 	b.car.engine = engine
-	b.car.power = power
+	if b.check(power.validate(b.locale)) {
+		b.car.power = int(power)
+	}
 	return b
 }
 
-// SetSpeed 设置最大速度
-func (b *CarBuilder) SetSpeed(max int) ICarBuilder {
-	b.car.maxSpeed = max
+// SetMaxSpeed 设置最大速度，速度超出范围时记录错误并保留原值
+func (b *CarBuilder) SetMaxSpeed(max Speed) ICarBuilder {
+	if b.check(max.validate(b.locale)) {
+		b.car.maxSpeed = int(max)
+	}
 	return b
 }
 
+// SetWheel 设置车轮大小(英寸)和品牌
+//
+// Deprecated: 使用 SetWheelSize
+func (b *CarBuilder) SetWheel(size int, brand string) ICarBuilder {
+	return b.SetWheelSize(WheelSize(size), brand)
+}
+
+// SetEngine 设置引擎型号和功率(马力)
+//
+// Deprecated: 使用 SetEnginePower
+func (b *CarBuilder) SetEngine(engine string, power int) ICarBuilder {
+	return b.SetEnginePower(engine, Power(power))
+}
+
+// SetSpeed 设置最大速度(公里/小时)
+//
+// Deprecated: 使用 SetMaxSpeed
+func (b *CarBuilder) SetSpeed(max int) ICarBuilder {
+	return b.SetMaxSpeed(Speed(max))
+}
+
 // SetBrand 设置品牌
 func (b *CarBuilder) SetBrand(brand string) ICarBuilder {
 	b.car.brandName = brand
@@ -172,31 +214,46 @@ func (b *CarBuilder) AddFeature(featureName string, value interface{}) ICarBuild
 	return b
 }
 
+// SetLocale 设置错误信息和所建汽车的简介使用的语言区域，空字符串表示使用默认语言区域
+// 语言区域不会被 Reset 清除
+func (b *CarBuilder) SetLocale(locale string) error {
+	if locale != "" && !localeRegistered(locale) {
+		return fmt.Errorf("%w: %s", ErrUnknownLocale, locale)
+	}
+	b.locale = locale
+	return nil
+}
+
 // Reset 重置构建器
 func (b *CarBuilder) Reset() ICarBuilder {
 	b.car = &Car{
 		features: make(map[string]interface{}),
 	}
+	b.err = nil
 	return b
 }
 
 // Build 构建并返回汽车
 func (b *CarBuilder) Build() (ICar, error) {
+	// 设置过程中的校验错误优先返回
+	if b.err != nil {
+		return nil, b.err
+	}
 	// 验证必要的组件是否已设置
 	if b.car.carType == "" {
-		return nil, errors.New("必须设置汽车类型")
+		return nil, b.missing(MsgFieldCarType)
 	}
 	if b.car.wheelSize == 0 {
-		return nil, errors.New("必须设置车轮尺寸")
+		return nil, b.missing(MsgFieldWheelSize)
 	}
 	if b.car.engine == "" {
-		return nil, errors.New("必须设置引擎型号")
+		return nil, b.missing(MsgFieldEngine)
 	}
 	if b.car.maxSpeed == 0 {
-		return nil, errors.New("必须设置最大速度")
+		return nil, b.missing(MsgFieldMaxSpeed)
 	}
 	if b.car.brandName == "" {
-		return nil, errors.New("必须设置品牌")
+		return nil, b.missing(MsgFieldBrand)
 	}
 
 	// 创建一个新的汽车实例，避免修改正在构建的实例
@@ -212,6 +269,7 @@ func (b *CarBuilder) Build() (ICar, error) {
 		seats:      b.car.seats,
 		fuelType:   b.car.fuelType,
 		features:   make(map[string]interface{}),
+		locale:     b.locale,
 	}
 
 	// 复制特性
@@ -254,9 +312,9 @@ func (d *Director) ChangeBuilder(builder ICarBuilder) {
 func (d *Director) BuildSedan(brand string) (ICar, error) {
 	return d.builder.Reset().
 		SetType(SedanType).
-		SetWheel(17, "米其林").
-		SetEngine("2.0L 涡轮增压", 180).
-		SetSpeed(220).
+		SetWheelSize(17, "米其林").
+		SetEnginePower("2.0L 涡轮增压", 180).
+		SetMaxSpeed(220).
 		SetBrand(brand).
 		SetSeats(5).
 		SetColor("银色").
@@ -270,9 +328,9 @@ func (d *Director) BuildSedan(brand string) (ICar, error) {
 func (d *Director) BuildSUV(brand string) (ICar, error) {
 	return d.builder.Reset().
 		SetType(SUVType).
-		SetWheel(19, "固特异").
-		SetEngine("2.5L V6", 220).
-		SetSpeed(200).
+		SetWheelSize(19, "固特异").
+		SetEnginePower("2.5L V6", 220).
+		SetMaxSpeed(200).
 		SetBrand(brand).
 		SetSeats(7).
 		SetColor("黑色").
@@ -286,9 +344,9 @@ func (d *Director) BuildSUV(brand string) (ICar, error) {
 func (d *Director) BuildSportsCar(brand string) (ICar, error) {
 	return d.builder.Reset().
 		SetType(SportType).
-		SetWheel(21, "倍耐力").
-		SetEngine("4.0L V8 双涡轮", 580).
-		SetSpeed(330).
+		SetWheelSize(21, "倍耐力").
+		SetEnginePower("4.0L V8 双涡轮", 580).
+		SetMaxSpeed(330).
 		SetBrand(brand).
 		SetSeats(2).
 		SetColor("红色").
@@ -303,9 +361,9 @@ func (d *Director) BuildSportsCar(brand string) (ICar, error) {
 func (d *Director) BuildLuxuryCar(brand string) (ICar, error) {
 	return d.builder.Reset().
 		SetType(LuxuryType).
-		SetWheel(20, "马牌").
-		SetEngine("3.0L 直列六缸 混合动力", 400).
-		SetSpeed(250).
+		SetWheelSize(20, "马牌").
+		SetEnginePower("3.0L 直列六缸 混合动力", 400).
+		SetMaxSpeed(250).
 		SetBrand(brand).
 		SetSeats(5).
 		SetColor("深蓝色").
@@ -0,0 +1,172 @@
+package builder
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// carFields 按固定顺序列出参与比较的汽车属性
+var carFields = []string{
+	"type", "brand", "wheelSize", "wheelBrand", "engine",
+	"power", "maxSpeed", "color", "seats", "fuelType",
+}
+
+// FeaturesField 是特性映射在比较时使用的字段名
+// 忽略 "features" 会忽略全部特性，忽略 "features.<名称>" 只忽略单个特性
+const FeaturesField = "features"
+
+// FieldDiff 表示一个字段的差异
+type FieldDiff struct {
+	Field string      // 字段名，特性使用 "features.<名称>"
+	Left  interface{} // 第一辆车的值，特性不存在时为 nil
+	Right interface{} // 第二辆车的值，特性不存在时为 nil
+}
+
+// String 格式化字段差异
+func (d FieldDiff) String() string {
+	return fmt.Sprintf("%s: %v -> %v", d.Field, d.Left, d.Right)
+}
+
+// CarDiff 表示两辆车之间逐字段的差异
+type CarDiff struct {
+	Fields          []FieldDiff            // 基本属性的差异，按 carFields 的顺序排列
+	FeaturesAdded   map[string]interface{} // 只有第二辆车拥有的特性
+	FeaturesRemoved map[string]interface{} // 只有第一辆车拥有的特性
+	FeaturesChanged []FieldDiff            // 两辆车都有但取值不同的特性，按名称排序
+}
+
+// CompareCars 逐字段比较两辆车，返回结构化的差异
+func CompareCars(a, b ICar) CarDiff {
+	left, right := carAttributes(a), carAttributes(b)
+
+	diff := CarDiff{
+		FeaturesAdded:   make(map[string]interface{}),
+		FeaturesRemoved: make(map[string]interface{}),
+	}
+	for _, field := range carFields {
+		if !reflect.DeepEqual(left[field], right[field]) {
+			diff.Fields = append(diff.Fields, FieldDiff{Field: field, Left: left[field], Right: right[field]})
+		}
+	}
+
+	leftFeatures, rightFeatures := carFeatures(left), carFeatures(right)
+	for name, value := range leftFeatures {
+		other, exists := rightFeatures[name]
+		switch {
+		case !exists:
+			diff.FeaturesRemoved[name] = value
+		case !reflect.DeepEqual(value, other):
+			diff.FeaturesChanged = append(diff.FeaturesChanged,
+				FieldDiff{Field: FeaturesField + "." + name, Left: value, Right: other})
+		}
+	}
+	for name, value := range rightFeatures {
+		if _, exists := leftFeatures[name]; !exists {
+			diff.FeaturesAdded[name] = value
+		}
+	}
+	sort.Slice(diff.FeaturesChanged, func(i, j int) bool {
+		return diff.FeaturesChanged[i].Field < diff.FeaturesChanged[j].Field
+	})
+	return diff
+}
+
+// IsEmpty 返回两辆车是否没有任何差异
+func (d CarDiff) IsEmpty() bool {
+	return len(d.Fields) == 0 && len(d.FeaturesAdded) == 0 &&
+		len(d.FeaturesRemoved) == 0 && len(d.FeaturesChanged) == 0
+}
+
+// Ignoring 返回去掉指定字段后的差异
+// 字段名可以是基本属性（如 "color"）、"features" 或 "features.<名称>"
+func (d CarDiff) Ignoring(fields ...string) CarDiff {
+	ignored := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		ignored[field] = true
+	}
+	skipFeature := func(name string) bool {
+		return ignored[FeaturesField] || ignored[FeaturesField+"."+name]
+	}
+
+	result := CarDiff{
+		FeaturesAdded:   make(map[string]interface{}),
+		FeaturesRemoved: make(map[string]interface{}),
+	}
+	for _, fd := range d.Fields {
+		if !ignored[fd.Field] {
+			result.Fields = append(result.Fields, fd)
+		}
+	}
+	for name, value := range d.FeaturesAdded {
+		if !skipFeature(name) {
+			result.FeaturesAdded[name] = value
+		}
+	}
+	for name, value := range d.FeaturesRemoved {
+		if !skipFeature(name) {
+			result.FeaturesRemoved[name] = value
+		}
+	}
+	for _, fd := range d.FeaturesChanged {
+		if !skipFeature(strings.TrimPrefix(fd.Field, FeaturesField+".")) {
+			result.FeaturesChanged = append(result.FeaturesChanged, fd)
+		}
+	}
+	return result
+}
+
+// String 格式化输出差异，没有差异时返回"无差异"
+func (d CarDiff) String() string {
+	if d.IsEmpty() {
+		return "无差异"
+	}
+
+	var sb strings.Builder
+	for _, fd := range d.Fields {
+		sb.WriteString(fd.String() + "\n")
+	}
+	for _, name := range sortedKeys(d.FeaturesRemoved) {
+		sb.WriteString(fmt.Sprintf("- %s.%s: %v\n", FeaturesField, name, d.FeaturesRemoved[name]))
+	}
+	for _, name := range sortedKeys(d.FeaturesAdded) {
+		sb.WriteString(fmt.Sprintf("+ %s.%s: %v\n", FeaturesField, name, d.FeaturesAdded[name]))
+	}
+	for _, fd := range d.FeaturesChanged {
+		sb.WriteString(fd.String() + "\n")
+	}
+	return sb.String()
+}
+
+// Equals 比较两辆车是否相同，ignoreFields 中的字段不参与比较
+func (c *Car) Equals(other ICar, ignoreFields ...string) bool {
+	return CompareCars(c, other).Ignoring(ignoreFields...).IsEmpty()
+}
+
+// carAttributes 返回汽车属性，nil 汽车返回空映射
+func carAttributes(car ICar) map[string]interface{} {
+	if car == nil {
+		return map[string]interface{}{}
+	}
+	if v := reflect.ValueOf(car); v.Kind() == reflect.Ptr && v.IsNil() {
+		return map[string]interface{}{}
+	}
+	return car.GetAttributes()
+}
+
+// carFeatures 从属性中取出特性映射
+func carFeatures(attributes map[string]interface{}) map[string]interface{} {
+	features, _ := attributes[FeaturesField].(map[string]interface{})
+	return features
+}
+
+// sortedKeys 返回排序后的映射键
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
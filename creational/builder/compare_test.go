@@ -0,0 +1,119 @@
+package builder
+
+import (
+	"strings"
+	"testing"
+)
+
+// 测试相同配置的车辆没有差异
+func TestCompareIdenticalCars(t *testing.T) {
+	director := NewDirector(NewCarBuilder())
+	a, _ := director.BuildSedan("丰田")
+	b, _ := director.BuildSedan("丰田")
+
+	diff := CompareCars(a, b)
+	if !diff.IsEmpty() {
+		t.Errorf("相同配置的车辆不应有差异: %s", diff)
+	}
+	if diff.String() != "无差异" {
+		t.Errorf("无差异时的描述错误: %s", diff)
+	}
+	if !a.Equals(b) {
+		t.Error("相同配置的车辆应该相等")
+	}
+}
+
+// 测试基本属性和特性的逐字段差异
+func TestCompareCarsDiff(t *testing.T) {
+	base := func() ICarBuilder {
+		return NewCarBuilder().
+			SetType(SedanType).
+			SetWheel(17, "米其林").
+			SetEngine("2.0T", 200).
+			SetSpeed(220).
+			SetBrand("奥迪").
+			AddFeature("导航系统", true).
+			AddFeature("自动驾驶", "L2")
+	}
+
+	a, _ := base().SetColor("白色").AddFeature("天窗", true).Build()
+	b, _ := base().SetColor("黑色").SetEngine("2.0T", 250).
+		AddFeature("自动驾驶", "L3").AddFeature("抬头显示", true).Build()
+
+	diff := CompareCars(a, b)
+
+	if len(diff.Fields) != 2 {
+		t.Fatalf("应有2个基本属性差异，实际为: %v", diff.Fields)
+	}
+	if diff.Fields[0].Field != "power" || diff.Fields[0].Left != 200 || diff.Fields[0].Right != 250 {
+		t.Errorf("功率差异错误: %v", diff.Fields[0])
+	}
+	if diff.Fields[1].Field != "color" {
+		t.Errorf("差异应按固定字段顺序排列，实际为: %v", diff.Fields)
+	}
+
+	if _, ok := diff.FeaturesRemoved["天窗"]; !ok || len(diff.FeaturesRemoved) != 1 {
+		t.Errorf("移除的特性错误: %v", diff.FeaturesRemoved)
+	}
+	if _, ok := diff.FeaturesAdded["抬头显示"]; !ok || len(diff.FeaturesAdded) != 1 {
+		t.Errorf("新增的特性错误: %v", diff.FeaturesAdded)
+	}
+	if len(diff.FeaturesChanged) != 1 || diff.FeaturesChanged[0].Field != "features.自动驾驶" {
+		t.Errorf("变化的特性错误: %v", diff.FeaturesChanged)
+	}
+
+	text := diff.String()
+	for _, want := range []string{"color: 白色 -> 黑色", "- features.天窗: true", "+ features.抬头显示: true", "features.自动驾驶: L2 -> L3"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("差异描述缺少 %q:\n%s", want, text)
+		}
+	}
+}
+
+// 测试比较时忽略指定字段
+func TestCarEqualsIgnoreFields(t *testing.T) {
+	fleet, err := NewFleetBuilder(sedanTemplate).
+		WithColors("红色", "蓝色").
+		WithFeatureToggle("天窗", true, EveryNth(2)).
+		Build(2)
+	if err != nil {
+		t.Fatalf("构建车队失败: %v", err)
+	}
+	a, b := fleet.Units[0].Car, fleet.Units[1].Car
+
+	if a.Equals(b) {
+		t.Error("颜色和特性不同的车辆不应相等")
+	}
+	if a.Equals(b, "color") {
+		t.Error("只忽略颜色时特性仍然不同")
+	}
+	if !a.Equals(b, "color", "features.天窗") {
+		t.Error("忽略颜色和天窗后车队中的车辆应相等")
+	}
+	if !a.Equals(b, "color", FeaturesField) {
+		t.Error("忽略全部特性后车辆应相等")
+	}
+
+	diff := CompareCars(a, b).Ignoring("color")
+	if len(diff.Fields) != 0 || len(diff.FeaturesAdded) != 1 || len(diff.FeaturesChanged) != 0 {
+		t.Errorf("忽略颜色后的差异错误: %s", diff)
+	}
+}
+
+// 测试与空车辆比较
+func TestCompareWithNilCar(t *testing.T) {
+	car, _ := NewDirector(NewCarBuilder()).BuildSUV("路虎")
+
+	diff := CompareCars(car, nil)
+	if len(diff.Fields) != len(carFields) {
+		t.Errorf("与空车辆比较时所有字段都应不同，实际为%d个", len(diff.Fields))
+	}
+	if len(diff.FeaturesRemoved) != 2 {
+		t.Errorf("与空车辆比较时所有特性都应被移除: %v", diff.FeaturesRemoved)
+	}
+
+	var nilCar *Car
+	if car.Equals(nilCar) {
+		t.Error("车辆不应等于空车辆")
+	}
+}
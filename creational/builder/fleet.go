@@ -0,0 +1,211 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+)
+
+// CarTemplate 车队模板，在建造者上设置所有车辆共享的基础配置
+type CarTemplate func(builder ICarBuilder) ICarBuilder
+
+// CostEstimator 单车成本估算函数
+type CostEstimator func(car ICar) float64
+
+// 各车型的基础价格(元)
+var baseCarPrices = map[CarType]float64{
+	SedanType:  150000,
+	SUVType:    220000,
+	SportType:  600000,
+	LuxuryType: 500000,
+}
+
+// DefaultCostEstimator 默认成本估算：车型基础价 + 每马力500元 + 每项特性2000元
+func DefaultCostEstimator(car ICar) float64 {
+	attrs := car.GetAttributes()
+	cost := baseCarPrices[car.Type()]
+	if power, ok := attrs["power"].(int); ok {
+		cost += float64(power) * 500
+	}
+	if features, ok := attrs["features"].(map[string]interface{}); ok {
+		cost += float64(len(features)) * 2000
+	}
+	return cost
+}
+
+// featureToggle 按条件为部分车辆开启的特性
+type featureToggle struct {
+	name    string
+	value   interface{}
+	enabled func(index int) bool
+}
+
+// FleetUnit 车队中的一辆车
+// 编号只保存在这里，不写入汽车的特性：特性参与成本估算和特性统计，编号不是车辆的配置
+type FleetUnit struct {
+	ID   string  // 类VIN的唯一编号
+	Car  ICar    // 构建好的汽车
+	Cost float64 // 估算成本
+}
+
+// FleetStats 车队汇总统计
+type FleetStats struct {
+	Count      int             // 车辆总数
+	TotalPower int             // 总功率(马力)
+	TotalCost  float64         // 总成本估算(元)
+	ByColor    map[string]int  // 按颜色统计
+	ByFeature  map[string]int  // 按特性统计
+	ByType     map[CarType]int // 按车型统计
+}
+
+// AveragePower 返回平均功率
+func (s FleetStats) AveragePower() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return float64(s.TotalPower) / float64(s.Count)
+}
+
+// Fleet 车队，包含所有车辆和汇总统计
+type Fleet struct {
+	Units []FleetUnit
+	Stats FleetStats
+}
+
+// Find 根据编号查找车辆
+func (f *Fleet) Find(id string) (FleetUnit, bool) {
+	for _, unit := range f.Units {
+		if unit.ID == id {
+			return unit, true
+		}
+	}
+	return FleetUnit{}, false
+}
+
+// FleetBuilder 车队建造者，复用同一个建造者和模板批量生产汽车
+type FleetBuilder struct {
+	builder   ICarBuilder
+	template  CarTemplate
+	idPrefix  string
+	colors    []string
+	toggles   []featureToggle
+	estimator CostEstimator
+}
+
+// NewFleetBuilder 创建车队建造者，template 用于设置车队的基础配置
+func NewFleetBuilder(template CarTemplate) *FleetBuilder {
+	return &FleetBuilder{
+		builder:   NewCarBuilder(),
+		template:  template,
+		idPrefix:  "VIN",
+		estimator: DefaultCostEstimator,
+	}
+}
+
+// WithBuilder 更换底层使用的建造者
+func (f *FleetBuilder) WithBuilder(builder ICarBuilder) *FleetBuilder {
+	f.builder = builder
+	return f
+}
+
+// WithIDPrefix 设置车辆编号前缀
+func (f *FleetBuilder) WithIDPrefix(prefix string) *FleetBuilder {
+	f.idPrefix = prefix
+	return f
+}
+
+// WithColors 设置颜色轮换列表，车辆按顺序循环使用这些颜色
+func (f *FleetBuilder) WithColors(colors ...string) *FleetBuilder {
+	f.colors = colors
+	return f
+}
+
+// WithFeatureToggle 为满足条件的车辆添加特性，enabled 接收车辆序号(从0开始)
+func (f *FleetBuilder) WithFeatureToggle(name string, value interface{}, enabled func(index int) bool) *FleetBuilder {
+	f.toggles = append(f.toggles, featureToggle{name: name, value: value, enabled: enabled})
+	return f
+}
+
+// WithCostEstimator 设置成本估算函数
+func (f *FleetBuilder) WithCostEstimator(estimator CostEstimator) *FleetBuilder {
+	f.estimator = estimator
+	return f
+}
+
+// EveryNth 返回每隔 n 辆开启一次的条件(第 n、2n... 辆)
+func EveryNth(n int) func(index int) bool {
+	return func(index int) bool {
+		return n > 0 && (index+1)%n == 0
+	}
+}
+
+// Build 构建 n 辆车组成的车队
+func (f *FleetBuilder) Build(n int) (*Fleet, error) {
+	if n <= 0 {
+		return nil, errors.New("车队数量必须大于0")
+	}
+	if f.template == nil {
+		return nil, errors.New("必须设置车队模板")
+	}
+
+	fleet := &Fleet{
+		Units: make([]FleetUnit, 0, n),
+		Stats: FleetStats{
+			ByColor:   make(map[string]int),
+			ByFeature: make(map[string]int),
+			ByType:    make(map[CarType]int),
+		},
+	}
+
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("%s%06d", f.idPrefix, i+1)
+		car, err := f.buildUnit(i)
+		if err != nil {
+			return nil, fmt.Errorf("构建第%d辆车失败: %w", i+1, err)
+		}
+
+		unit := FleetUnit{ID: id, Car: car}
+		if f.estimator != nil {
+			unit.Cost = f.estimator(car)
+		}
+		fleet.Units = append(fleet.Units, unit)
+		fleet.Stats.add(unit)
+	}
+
+	return fleet, nil
+}
+
+// buildUnit 在模板的基础上应用单车差异并构建
+func (f *FleetBuilder) buildUnit(index int) (ICar, error) {
+	b := f.template(f.builder.Reset())
+
+	if len(f.colors) > 0 {
+		b = b.SetColor(f.colors[index%len(f.colors)])
+	}
+	for _, toggle := range f.toggles {
+		if toggle.enabled == nil || toggle.enabled(index) {
+			b = b.AddFeature(toggle.name, toggle.value)
+		}
+	}
+
+	return b.Build()
+}
+
+// add 将一辆车计入统计
+func (s *FleetStats) add(unit FleetUnit) {
+	attrs := unit.Car.GetAttributes()
+
+	s.Count++
+	s.TotalCost += unit.Cost
+	s.ByType[unit.Car.Type()]++
+	if power, ok := attrs["power"].(int); ok {
+		s.TotalPower += power
+	}
+	if color, ok := attrs["color"].(string); ok {
+		s.ByColor[color]++
+	}
+	if features, ok := attrs["features"].(map[string]interface{}); ok {
+		for name := range features {
+			s.ByFeature[name]++
+		}
+	}
+}
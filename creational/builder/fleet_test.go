@@ -0,0 +1,201 @@
+package builder
+
+import (
+	"strings"
+	"testing"
+)
+
+// 测试用的车队模板
+func sedanTemplate(b ICarBuilder) ICarBuilder {
+	return b.SetType(SedanType).
+		SetWheel(17, "米其林").
+		SetEngine("1.5T", 150).
+		SetSpeed(200).
+		SetBrand("车队品牌")
+}
+
+// 测试车队的基本构建
+func TestFleetBuilderBuild(t *testing.T) {
+	fleet, err := NewFleetBuilder(sedanTemplate).
+		WithIDPrefix("TAXI").
+		WithColors("黄色", "绿色", "白色").
+		WithFeatureToggle("计价器", true, nil).
+		WithFeatureToggle("充电桩接口", "快充", EveryNth(2)).
+		Build(5)
+	if err != nil {
+		t.Fatalf("构建车队失败: %v", err)
+	}
+
+	if len(fleet.Units) != 5 {
+		t.Fatalf("车队数量错误: 得到 %d, 期望 %d", len(fleet.Units), 5)
+	}
+
+	// 验证编号
+	if fleet.Units[0].ID != "TAXI000001" || fleet.Units[4].ID != "TAXI000005" {
+		t.Errorf("车辆编号错误: %s ... %s", fleet.Units[0].ID, fleet.Units[4].ID)
+	}
+
+	// 验证颜色轮换
+	expectedColors := []string{"黄色", "绿色", "白色", "黄色", "绿色"}
+	for i, unit := range fleet.Units {
+		color := unit.Car.GetAttributes()["color"]
+		if color != expectedColors[i] {
+			t.Errorf("第%d辆车颜色错误: 得到 %v, 期望 %v", i+1, color, expectedColors[i])
+		}
+	}
+
+	// 验证特性开关
+	for i, unit := range fleet.Units {
+		features := unit.Car.GetAttributes()["features"].(map[string]interface{})
+		if features["计价器"] != true {
+			t.Errorf("第%d辆车应有计价器", i+1)
+		}
+		_, hasCharger := features["充电桩接口"]
+		if hasCharger != ((i+1)%2 == 0) {
+			t.Errorf("第%d辆车充电桩接口开关错误: %v", i+1, hasCharger)
+		}
+		expectedFeatures := 1
+		if hasCharger {
+			expectedFeatures = 2
+		}
+		if _, ok := features["车辆识别码"]; ok || len(features) != expectedFeatures {
+			t.Errorf("第%d辆车只应有开关添加的特性，编号不计入特性: %v", i+1, features)
+		}
+	}
+
+	// 验证每辆车都是独立实例
+	if fleet.Units[0].Car == fleet.Units[1].Car {
+		t.Error("车队中的车辆应为独立实例")
+	}
+}
+
+// 测试车队统计
+func TestFleetStats(t *testing.T) {
+	fleet, err := NewFleetBuilder(sedanTemplate).
+		WithColors("红色", "蓝色").
+		WithFeatureToggle("天窗", true, EveryNth(3)).
+		Build(6)
+	if err != nil {
+		t.Fatalf("构建车队失败: %v", err)
+	}
+
+	stats := fleet.Stats
+	if stats.Count != 6 {
+		t.Errorf("统计数量错误: 得到 %d, 期望 %d", stats.Count, 6)
+	}
+	if stats.TotalPower != 900 {
+		t.Errorf("总功率错误: 得到 %d, 期望 %d", stats.TotalPower, 900)
+	}
+	if stats.AveragePower() != 150 {
+		t.Errorf("平均功率错误: 得到 %v, 期望 %v", stats.AveragePower(), 150)
+	}
+	if stats.ByColor["红色"] != 3 || stats.ByColor["蓝色"] != 3 {
+		t.Errorf("颜色统计错误: %v", stats.ByColor)
+	}
+	if len(stats.ByFeature) != 1 || stats.ByFeature["天窗"] != 2 {
+		t.Errorf("特性统计错误: 得到 %v, 期望只有 天窗:2", stats.ByFeature)
+	}
+	if stats.ByType[SedanType] != 6 {
+		t.Errorf("车型统计错误: %v", stats.ByType)
+	}
+
+	// 默认成本：150000 + 150*500 = 225000，有天窗的车再加 2000
+	for i, unit := range fleet.Units {
+		expected := 225000.0
+		if (i+1)%3 == 0 {
+			expected = 227000
+		}
+		if unit.Cost != expected {
+			t.Errorf("第%d辆车成本错误: 得到 %.2f, 期望 %.2f", i+1, unit.Cost, expected)
+		}
+	}
+	if stats.TotalCost != 6*225000+2*2000 {
+		t.Errorf("总成本错误: 得到 %.2f, 期望 %.2f", stats.TotalCost, 6*225000.0+2*2000)
+	}
+}
+
+// 测试自定义成本估算
+func TestFleetBuilderCustomEstimator(t *testing.T) {
+	fleet, err := NewFleetBuilder(sedanTemplate).
+		WithCostEstimator(func(car ICar) float64 { return 1000 }).
+		Build(3)
+	if err != nil {
+		t.Fatalf("构建车队失败: %v", err)
+	}
+
+	if fleet.Stats.TotalCost != 3000 {
+		t.Errorf("总成本错误: 得到 %.2f, 期望 %.2f", fleet.Stats.TotalCost, 3000.0)
+	}
+
+	unit, ok := fleet.Find("VIN000002")
+	if !ok {
+		t.Fatal("应能按编号找到车辆")
+	}
+	if unit.Cost != 1000 {
+		t.Errorf("单车成本错误: 得到 %.2f", unit.Cost)
+	}
+	if _, ok := fleet.Find("不存在"); ok {
+		t.Error("不存在的编号不应找到车辆")
+	}
+}
+
+// 测试车队构建的错误情况
+func TestFleetBuilderErrors(t *testing.T) {
+	t.Run("数量非法", func(t *testing.T) {
+		_, err := NewFleetBuilder(sedanTemplate).Build(0)
+		if err == nil {
+			t.Error("数量为0时应返回错误")
+		}
+	})
+
+	t.Run("缺少模板", func(t *testing.T) {
+		_, err := NewFleetBuilder(nil).Build(1)
+		if err == nil {
+			t.Error("缺少模板时应返回错误")
+		}
+	})
+
+	t.Run("模板不完整", func(t *testing.T) {
+		incomplete := func(b ICarBuilder) ICarBuilder {
+			return b.SetType(SUVType)
+		}
+		_, err := NewFleetBuilder(incomplete).Build(2)
+		if err == nil {
+			t.Fatal("模板不完整时应返回错误")
+		}
+		if !strings.Contains(err.Error(), "构建第1辆车失败") || !strings.Contains(err.Error(), "车轮尺寸") {
+			t.Errorf("错误信息不正确: %v", err)
+		}
+	})
+}
+
+// 测试大规模构建时建造者的复用
+func TestFleetBuilderAtScale(t *testing.T) {
+	fleet, err := NewFleetBuilder(sedanTemplate).
+		WithColors("白色", "黑色", "银色", "灰色").
+		Build(1000)
+	if err != nil {
+		t.Fatalf("构建车队失败: %v", err)
+	}
+
+	seen := make(map[string]bool, len(fleet.Units))
+	for _, unit := range fleet.Units {
+		if seen[unit.ID] {
+			t.Fatalf("车辆编号重复: %s", unit.ID)
+		}
+		seen[unit.ID] = true
+	}
+	if fleet.Stats.ByColor["银色"] != 250 {
+		t.Errorf("颜色分布错误: %v", fleet.Stats.ByColor)
+	}
+}
+
+// 基准测试：车队构建
+func BenchmarkFleetBuilder(b *testing.B) {
+	fb := NewFleetBuilder(sedanTemplate).WithColors("白色", "黑色")
+	for i := 0; i < b.N; i++ {
+		if _, err := fb.Build(100); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// 内置的语言区域
+const (
+	LocaleZhCN = "zh-CN" // 简体中文，默认语言区域
+	LocaleEnUS = "en-US" // 美式英语
+)
+
+// ErrUnknownLocale 表示语言区域没有注册
+var ErrUnknownLocale = errors.New("未注册的语言区域")
+
+// MessageKey 消息目录中的消息键
+type MessageKey string
+
+// 消息目录中的消息键，注册新的语言区域时为这些键提供翻译，缺少的键使用简体中文
+const (
+	MsgOutOfRange     MessageKey = "error.outOfRange"   // ErrOutOfRange 的描述
+	MsgRangeDetail    MessageKey = "error.rangeDetail"  // 参数: 字段、带单位的值、最小值、带单位的最大值
+	MsgMissingField   MessageKey = "error.missingField" // 参数: 字段
+	MsgFieldCarType   MessageKey = "field.carType"      // 字段名: 汽车类型
+	MsgFieldWheelSize MessageKey = "field.wheelSize"    // 字段名: 车轮尺寸
+	MsgFieldEngine    MessageKey = "field.engine"       // 字段名: 引擎型号
+	MsgFieldPower     MessageKey = "field.power"        // 字段名: 引擎功率
+	MsgFieldMaxSpeed  MessageKey = "field.maxSpeed"     // 字段名: 最大速度
+	MsgFieldBrand     MessageKey = "field.brand"        // 字段名: 品牌
+	MsgUnitSpeed      MessageKey = "unit.speed"         // 参数: 数值
+	MsgUnitPower      MessageKey = "unit.power"         // 参数: 数值
+	MsgUnitWheelSize  MessageKey = "unit.wheelSize"     // 参数: 数值
+	MsgBriefTitle     MessageKey = "brief.title"        // 参数: 品牌、汽车类型
+	MsgBriefWheel     MessageKey = "brief.wheel"        // 参数: 带单位的尺寸、车轮品牌
+	MsgBriefEngine    MessageKey = "brief.engine"       // 参数: 引擎型号、带单位的功率
+	MsgBriefMaxSpeed  MessageKey = "brief.maxSpeed"     // 参数: 带单位的速度
+	MsgBriefColor     MessageKey = "brief.color"        // 参数: 颜色
+	MsgBriefSeats     MessageKey = "brief.seats"        // 参数: 座位数
+	MsgBriefFuelType  MessageKey = "brief.fuelType"     // 参数: 燃料类型
+	MsgBriefFeatures  MessageKey = "brief.features"     // 额外特性的标题
+	MsgCarTypeSedan   MessageKey = "carType.sedan"      // 车型名称: 轿车
+	MsgCarTypeSUV     MessageKey = "carType.suv"        // 车型名称: SUV
+	MsgCarTypeSport   MessageKey = "carType.sport"      // 车型名称: 跑车
+	MsgCarTypeLuxury  MessageKey = "carType.luxury"     // 车型名称: 豪华车
+)
+
+// Catalog 一个语言区域的消息目录，值是 fmt 格式字符串，可以使用 %[n]s 调整参数顺序
+type Catalog map[MessageKey]string
+
+// zhCN 简体中文消息目录，也是其他语言区域缺少翻译时的后备
+var zhCN = Catalog{
+	MsgOutOfRange:     "数值超出合理范围",
+	MsgRangeDetail:    "%s %s 不在 %d-%s 之间",
+	MsgMissingField:   "必须设置%s",
+	MsgFieldCarType:   "汽车类型",
+	MsgFieldWheelSize: "车轮尺寸",
+	MsgFieldEngine:    "引擎型号",
+	MsgFieldPower:     "引擎功率",
+	MsgFieldMaxSpeed:  "最大速度",
+	MsgFieldBrand:     "品牌",
+	MsgUnitSpeed:      "%d公里/小时",
+	MsgUnitPower:      "%d马力",
+	MsgUnitWheelSize:  "%d英寸",
+	MsgBriefTitle:     "这是一辆%s的%s",
+	MsgBriefWheel:     "车轮: %s %s品牌",
+	MsgBriefEngine:    "引擎: %s (%s)",
+	MsgBriefMaxSpeed:  "最大速度: %s",
+	MsgBriefColor:     "颜色: %s",
+	MsgBriefSeats:     "座位数: %d",
+	MsgBriefFuelType:  "燃料类型: %s",
+	MsgBriefFeatures:  "额外特性:",
+	MsgCarTypeSedan:   "轿车",
+	MsgCarTypeSUV:     "SUV",
+	MsgCarTypeSport:   "跑车",
+	MsgCarTypeLuxury:  "豪华车",
+}
+
+// enUS 美式英语消息目录
+var enUS = Catalog{
+	MsgOutOfRange:     "value out of range",
+	MsgRangeDetail:    "%s %s is not between %d and %s",
+	MsgMissingField:   "%s must be set",
+	MsgFieldCarType:   "car type",
+	MsgFieldWheelSize: "wheel size",
+	MsgFieldEngine:    "engine model",
+	MsgFieldPower:     "engine power",
+	MsgFieldMaxSpeed:  "max speed",
+	MsgFieldBrand:     "brand",
+	MsgUnitSpeed:      "%d km/h",
+	MsgUnitPower:      "%d hp",
+	MsgUnitWheelSize:  "%d in",
+	MsgBriefTitle:     "This is a %[2]s made by %[1]s",
+	MsgBriefWheel:     "Wheels: %s, brand %s",
+	MsgBriefEngine:    "Engine: %s (%s)",
+	MsgBriefMaxSpeed:  "Max speed: %s",
+	MsgBriefColor:     "Color: %s",
+	MsgBriefSeats:     "Seats: %d",
+	MsgBriefFuelType:  "Fuel type: %s",
+	MsgBriefFeatures:  "Features:",
+	MsgCarTypeSedan:   "sedan",
+	MsgCarTypeSUV:     "SUV",
+	MsgCarTypeSport:   "sports car",
+	MsgCarTypeLuxury:  "luxury car",
+}
+
+// carTypeKeys 内置车型对应的消息键，其他车型按原样输出
+var carTypeKeys = map[CarType]MessageKey{
+	SedanType:  MsgCarTypeSedan,
+	SUVType:    MsgCarTypeSUV,
+	SportType:  MsgCarTypeSport,
+	LuxuryType: MsgCarTypeLuxury,
+}
+
+// localeRegistry 已注册的语言区域和当前的默认语言区域
+var localeRegistry = struct {
+	sync.RWMutex
+	catalogs      map[string]Catalog
+	defaultLocale string
+}{
+	catalogs:      map[string]Catalog{LocaleZhCN: zhCN, LocaleEnUS: enUS},
+	defaultLocale: LocaleZhCN,
+}
+
+// RegisterLocale 注册或替换一个语言区域的消息目录，缺少的消息使用简体中文
+// 内置的 zh-CN 是所有语言区域的后备，不能被替换
+func RegisterLocale(locale string, catalog Catalog) error {
+	if locale == "" {
+		return fmt.Errorf("%w: 语言区域名称不能为空", ErrUnknownLocale)
+	}
+	if locale == LocaleZhCN {
+		return fmt.Errorf("不能替换后备语言区域 %s", LocaleZhCN)
+	}
+
+	copied := make(Catalog, len(catalog))
+	for key, format := range catalog {
+		copied[key] = format
+	}
+
+	localeRegistry.Lock()
+	defer localeRegistry.Unlock()
+	localeRegistry.catalogs[locale] = copied
+	return nil
+}
+
+// Locales 返回所有已注册的语言区域，按字典序排列
+func Locales() []string {
+	localeRegistry.RLock()
+	defer localeRegistry.RUnlock()
+
+	locales := make([]string, 0, len(localeRegistry.catalogs))
+	for locale := range localeRegistry.catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// SetDefaultLocale 设置没有指定语言区域的建造者和物理量使用的语言区域
+func SetDefaultLocale(locale string) error {
+	localeRegistry.Lock()
+	defer localeRegistry.Unlock()
+
+	if _, ok := localeRegistry.catalogs[locale]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownLocale, locale)
+	}
+	localeRegistry.defaultLocale = locale
+	return nil
+}
+
+// DefaultLocale 返回当前的默认语言区域
+func DefaultLocale() string {
+	localeRegistry.RLock()
+	defer localeRegistry.RUnlock()
+	return localeRegistry.defaultLocale
+}
+
+// localeRegistered 检查语言区域是否已注册
+func localeRegistered(locale string) bool {
+	localeRegistry.RLock()
+	defer localeRegistry.RUnlock()
+	_, ok := localeRegistry.catalogs[locale]
+	return ok
+}
+
+// message 按语言区域格式化消息，locale 为空时使用默认语言区域，缺少翻译时使用简体中文
+func message(locale string, key MessageKey, args ...any) string {
+	localeRegistry.RLock()
+	if locale == "" {
+		locale = localeRegistry.defaultLocale
+	}
+	format, ok := localeRegistry.catalogs[locale][key]
+	localeRegistry.RUnlock()
+
+	if !ok {
+		format = zhCN[key]
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// carTypeName 返回车型在指定语言区域中的名称
+func carTypeName(locale string, carType CarType) string {
+	if key, ok := carTypeKeys[carType]; ok {
+		return message(locale, key)
+	}
+	return string(carType)
+}
+
+// localizedError 已按语言区域格式化的错误，Unwrap 返回对应的哨兵错误，便于使用 errors.Is 判断
+type localizedError struct {
+	msg string
+	err error
+}
+
+func (e *localizedError) Error() string {
+	return e.msg
+}
+
+func (e *localizedError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,136 @@
+package builder
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// captureBrief 捕获汽车简介的输出
+func captureBrief(t *testing.T, car ICar) string {
+	t.Helper()
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("创建管道失败: %v", err)
+	}
+	os.Stdout = w
+	car.Brief()
+	w.Close()
+	os.Stdout = stdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	return buf.String()
+}
+
+// 测试默认语言区域下的错误信息和简介保持简体中文
+func TestDefaultLocaleIsChinese(t *testing.T) {
+	if DefaultLocale() != LocaleZhCN {
+		t.Fatalf("默认语言区域应为 %s，实际为 %s", LocaleZhCN, DefaultLocale())
+	}
+
+	_, err := NewCarBuilder().SetType(SedanType).Build()
+	if !errors.Is(err, ErrMissingField) || err.Error() != "必须设置车轮尺寸" {
+		t.Errorf("缺少组件的错误不正确: %v", err)
+	}
+
+	car, err := NewDirector(NewCarBuilder()).BuildSedan("测试品牌")
+	if err != nil {
+		t.Fatalf("构建汽车失败: %v", err)
+	}
+	brief := captureBrief(t, car)
+	for _, want := range []string{"这是一辆测试品牌的轿车", "车轮: 17英寸 米其林品牌", "引擎: 2.0L 涡轮增压 (180马力)"} {
+		if !strings.Contains(brief, want) {
+			t.Errorf("简介中缺少 %q:\n%s", want, brief)
+		}
+	}
+}
+
+// 测试为建造者设置英文
+func TestBuilderLocaleEnglish(t *testing.T) {
+	builder := NewCarBuilder().(*CarBuilder)
+	if err := builder.SetLocale(LocaleEnUS); err != nil {
+		t.Fatalf("设置语言区域失败: %v", err)
+	}
+
+	_, err := builder.SetType(SportType).SetMaxSpeed(KMH(900)).Build()
+	if !errors.Is(err, ErrOutOfRange) {
+		t.Fatalf("应返回范围错误: %v", err)
+	}
+	if want := "value out of range: max speed 900 km/h is not between 1 and 500 km/h"; err.Error() != want {
+		t.Errorf("错误信息不正确:\n期望 %s\n实际 %s", want, err)
+	}
+
+	// 语言区域不会被 Reset 清除
+	builder.Reset()
+	_, err = builder.SetType(SportType).Build()
+	if !errors.Is(err, ErrMissingField) || err.Error() != "wheel size must be set" {
+		t.Errorf("缺少组件的错误不正确: %v", err)
+	}
+
+	car, err := NewDirector(builder).BuildSportsCar("Acme")
+	if err != nil {
+		t.Fatalf("构建汽车失败: %v", err)
+	}
+	brief := captureBrief(t, car)
+	for _, want := range []string{"This is a sports car made by Acme", "Wheels: 21 in, brand 倍耐力", "Engine: 4.0L V8 双涡轮 (580 hp)", "Max speed: 330 km/h", "Seats: 2", "Features:"} {
+		if !strings.Contains(brief, want) {
+			t.Errorf("简介中缺少 %q:\n%s", want, brief)
+		}
+	}
+
+	if err := builder.SetLocale("xx-YY"); !errors.Is(err, ErrUnknownLocale) {
+		t.Errorf("未注册的语言区域应返回 ErrUnknownLocale: %v", err)
+	}
+}
+
+// 测试注册新的语言区域和切换默认语言区域
+func TestRegisterLocale(t *testing.T) {
+	err := RegisterLocale("fr-FR", Catalog{
+		MsgMissingField: "%s doit être défini",
+		MsgFieldBrand:   "la marque",
+		MsgUnitSpeed:    "%d km/h",
+	})
+	if err != nil {
+		t.Fatalf("注册语言区域失败: %v", err)
+	}
+	if err := RegisterLocale(LocaleZhCN, Catalog{}); err == nil {
+		t.Error("不应允许替换后备语言区域")
+	}
+	if got := strings.Join(Locales(), ","); got != "en-US,fr-FR,zh-CN" {
+		t.Errorf("已注册的语言区域不正确: %s", got)
+	}
+
+	if err := SetDefaultLocale("fr-FR"); err != nil {
+		t.Fatalf("设置默认语言区域失败: %v", err)
+	}
+	t.Cleanup(func() { SetDefaultLocale(LocaleZhCN) })
+
+	// 没有设置语言区域的建造者和物理量使用默认语言区域
+	_, err = NewCarBuilder().
+		SetType(SedanType).
+		SetWheelSize(Inches(17), "米其林").
+		SetEnginePower("2.0T", HP(200)).
+		SetMaxSpeed(KMH(200)).
+		Build()
+	if err == nil || err.Error() != "la marque doit être défini" {
+		t.Errorf("错误信息应使用默认语言区域: %v", err)
+	}
+	if KMH(120).String() != "120 km/h" {
+		t.Errorf("物理量应使用默认语言区域: %s", KMH(120))
+	}
+
+	// 缺少的翻译使用简体中文
+	if got := Inches(18).String(); got != "18英寸" {
+		t.Errorf("缺少翻译时应使用简体中文: %s", got)
+	}
+
+	if err := SetDefaultLocale("xx-YY"); !errors.Is(err, ErrUnknownLocale) {
+		t.Errorf("未注册的语言区域应返回 ErrUnknownLocale: %v", err)
+	}
+}
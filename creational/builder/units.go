@@ -0,0 +1,118 @@
+package builder
+
+import "errors"
+
+// ErrOutOfRange 表示数值超出了合理范围
+var ErrOutOfRange = errors.New("数值超出合理范围")
+
+// Speed 最大速度，单位为公里/小时
+type Speed int
+
+// Power 引擎功率，单位为马力
+type Power int
+
+// WheelSize 车轮尺寸，单位为英寸
+type WheelSize int
+
+// 各物理量的合理范围（闭区间）
+const (
+	MinSpeed     Speed     = 1
+	MaxSpeed     Speed     = 500 // 量产车的最高速度不会超过 500 公里/小时
+	MinPower     Power     = 1
+	MaxPower     Power     = 2000
+	MinWheelSize WheelSize = 12
+	MaxWheelSize WheelSize = 26
+)
+
+// KMH 返回以公里/小时表示的速度
+func KMH(v int) Speed {
+	return Speed(v)
+}
+
+// HP 返回以马力表示的功率
+func HP(v int) Power {
+	return Power(v)
+}
+
+// Inches 返回以英寸表示的车轮尺寸
+func Inches(v int) WheelSize {
+	return WheelSize(v)
+}
+
+// String 返回使用默认语言区域的带单位的速度
+func (s Speed) String() string {
+	return message("", MsgUnitSpeed, int(s))
+}
+
+// Validate 检查速度是否在合理范围内，错误信息使用默认语言区域
+func (s Speed) Validate() error {
+	return s.validate("")
+}
+
+// validate 检查速度是否在合理范围内，错误信息使用指定的语言区域
+func (s Speed) validate(locale string) error {
+	return checkRange(locale, MsgFieldMaxSpeed, MsgUnitSpeed, int(s), int(MinSpeed), int(MaxSpeed))
+}
+
+// String 返回使用默认语言区域的带单位的功率
+func (p Power) String() string {
+	return message("", MsgUnitPower, int(p))
+}
+
+// Validate 检查功率是否在合理范围内，错误信息使用默认语言区域
+func (p Power) Validate() error {
+	return p.validate("")
+}
+
+// validate 检查功率是否在合理范围内，错误信息使用指定的语言区域
+func (p Power) validate(locale string) error {
+	return checkRange(locale, MsgFieldPower, MsgUnitPower, int(p), int(MinPower), int(MaxPower))
+}
+
+// String 返回使用默认语言区域的带单位的车轮尺寸
+func (w WheelSize) String() string {
+	return message("", MsgUnitWheelSize, int(w))
+}
+
+// Validate 检查车轮尺寸是否在合理范围内，错误信息使用默认语言区域
+func (w WheelSize) Validate() error {
+	return w.validate("")
+}
+
+// validate 检查车轮尺寸是否在合理范围内，错误信息使用指定的语言区域
+func (w WheelSize) validate(locale string) error {
+	return checkRange(locale, MsgFieldWheelSize, MsgUnitWheelSize, int(w), int(MinWheelSize), int(MaxWheelSize))
+}
+
+// checkRange 检查 value 是否在 [min, max] 范围内，返回的错误包装 ErrOutOfRange
+func checkRange(locale string, field, unit MessageKey, value, min, max int) error {
+	if value < min || value > max {
+		detail := message(locale, MsgRangeDetail,
+			message(locale, field), message(locale, unit, value), min, message(locale, unit, max))
+		return &localizedError{msg: message(locale, MsgOutOfRange) + ": " + detail, err: ErrOutOfRange}
+	}
+	return nil
+}
+
+// check 记录校验错误，返回值是否有效
+// 出错后建造者进入错误状态，后续设置照常进行，Build 时返回所有累积的错误
+func (b *CarBuilder) check(err error) bool {
+	if err != nil {
+		b.err = errors.Join(b.err, err)
+		return false
+	}
+	return true
+}
+
+// Err 返回设置过程中累积的校验错误，没有错误时返回 nil
+func (b *CarBuilder) Err() error {
+	return b.err
+}
+
+// missing 返回缺少必要组件的错误，包装 ErrMissingField
+func (b *CarBuilder) missing(field MessageKey) error {
+	return &localizedError{
+		msg: message(b.locale, MsgMissingField, message(b.locale, field)),
+		err: ErrMissingField,
+	}
+}
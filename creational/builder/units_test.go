@@ -0,0 +1,114 @@
+package builder
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// 测试物理量的范围校验和单位
+func TestQuantityValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		wantErr bool
+	}{
+		{"合理速度", KMH(220).Validate(), false},
+		{"速度上限", MaxSpeed.Validate(), false},
+		{"速度过高", KMH(900).Validate(), true},
+		{"速度为0", KMH(0).Validate(), true},
+		{"合理功率", HP(300).Validate(), false},
+		{"功率为负", HP(-5).Validate(), true},
+		{"合理轮径", Inches(18).Validate(), false},
+		{"轮径过小", Inches(8).Validate(), true},
+		{"轮径过大", Inches(30).Validate(), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if (tt.err != nil) != tt.wantErr {
+				t.Fatalf("校验结果错误: 得到 %v, 期望出错 %v", tt.err, tt.wantErr)
+			}
+			if tt.err != nil && !errors.Is(tt.err, ErrOutOfRange) {
+				t.Errorf("错误应包装 ErrOutOfRange: %v", tt.err)
+			}
+		})
+	}
+
+	if got := KMH(900).Validate().Error(); !strings.Contains(got, "最大速度 900公里/小时 不在 1-500公里/小时 之间") {
+		t.Errorf("错误信息不正确: %s", got)
+	}
+	if KMH(220).String() != "220公里/小时" || HP(300).String() != "300马力" || Inches(18).String() != "18英寸" {
+		t.Error("物理量应带单位输出")
+	}
+}
+
+// 测试使用带单位的方法构建汽车
+func TestCarBuilderTypedQuantities(t *testing.T) {
+	car, err := NewCarBuilder().
+		SetType(SedanType).
+		SetWheelSize(Inches(17), "米其林").
+		SetEnginePower("2.0T", HP(200)).
+		SetMaxSpeed(KMH(230)).
+		SetBrand("测试品牌").
+		Build()
+	if err != nil {
+		t.Fatalf("构建汽车失败: %v", err)
+	}
+
+	attrs := car.GetAttributes()
+	if attrs["wheelSize"] != 17 || attrs["power"] != 200 || car.Speed() != 230 {
+		t.Errorf("属性设置错误: %v", attrs)
+	}
+}
+
+// 测试超出范围的值使建造者进入错误状态，并在 Build 时返回
+func TestCarBuilderRangeErrors(t *testing.T) {
+	builder := NewCarBuilder().(*CarBuilder)
+
+	builder.
+		SetType(SportType).
+		SetWheelSize(Inches(40), "倍耐力").
+		SetEnginePower("V12", HP(800)).
+		SetSpeed(1200). // 旧接口同样会校验
+		SetBrand("测试品牌")
+
+	if builder.Err() == nil {
+		t.Fatal("设置超出范围的值后建造者应处于错误状态")
+	}
+	if builder.car.wheelSize != 0 || builder.car.maxSpeed != 0 {
+		t.Error("超出范围的值不应被保存")
+	}
+	if builder.car.power != 800 {
+		t.Error("合理的值应照常保存")
+	}
+
+	_, err := builder.Build()
+	if !errors.Is(err, ErrOutOfRange) {
+		t.Fatalf("Build 应返回范围错误: %v", err)
+	}
+	for _, field := range []string{"车轮尺寸", "最大速度"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("错误信息应包含所有出错的字段 %s: %v", field, err)
+		}
+	}
+	if strings.Contains(err.Error(), "引擎功率") {
+		t.Errorf("合理的功率不应报错: %v", err)
+	}
+
+	// 重置后清除错误状态
+	builder.Reset()
+	if builder.Err() != nil {
+		t.Error("重置后应清除错误状态")
+	}
+	_, err = builder.
+		SetType(SportType).
+		SetWheel(20, "倍耐力").
+		SetEngine("V12", 800).
+		SetSpeed(340).
+		SetBrand("测试品牌").
+		Build()
+	if err != nil {
+		t.Errorf("重置后应能正常构建: %v", err)
+	}
+}
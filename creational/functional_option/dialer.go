@@ -0,0 +1,104 @@
+package functional_option
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ErrConflictingOptions 表示同时设置了互相排斥的选项
+var ErrConflictingOptions = errors.New("选项互相冲突")
+
+// defaultDialTimeout 建立连接的超时时间
+const defaultDialTimeout = 30 * time.Second
+
+// DialContextFunc 自定义拨号函数，签名与 net.Dialer.DialContext 一致
+type DialContextFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// WithUnixSocket 通过 Unix 域套接字连接服务端，适用于 Docker 这类只监听本地套接字的守护进程
+// 请求 URL 中的主机名只用于 Host 请求头，所有连接都会拨向 path
+func WithUnixSocket(path string) Option {
+	return func(o *HTTPClientOptions) {
+		if path != "" {
+			o.UnixSocket = path
+		}
+	}
+}
+
+// WithDialContext 使用自定义拨号函数建立连接，例如自定义 DNS 解析或 Happy Eyeballs 拨号器
+func WithDialContext(dialer DialContextFunc) Option {
+	return func(o *HTTPClientOptions) {
+		if dialer != nil {
+			o.DialContext = dialer
+		}
+	}
+}
+
+// Validate 检查选项之间是否存在冲突
+// Unix 套接字、自定义拨号函数和代理都决定了连接拨向哪里，三者只能设置其一；
+// 自定义 Transport 会忽略拨号相关的选项，因此也不能与前两者同时使用
+func (o HTTPClientOptions) Validate() error {
+	if o.UnixSocket != "" && o.DialContext != nil {
+		return fmt.Errorf("%w: WithUnixSocket 与 WithDialContext 不能同时使用", ErrConflictingOptions)
+	}
+
+	dialOption := ""
+	switch {
+	case o.UnixSocket != "":
+		dialOption = "WithUnixSocket"
+	case o.DialContext != nil:
+		dialOption = "WithDialContext"
+	default:
+		return nil
+	}
+
+	if o.Proxy != nil {
+		return fmt.Errorf("%w: %s 不能与代理选项同时使用", ErrConflictingOptions, dialOption)
+	}
+	if o.Transport != nil {
+		return fmt.Errorf("%w: %s 不能与 WithCustomTransport 同时使用", ErrConflictingOptions, dialOption)
+	}
+	return nil
+}
+
+// dialer 根据选项返回传输层使用的拨号函数
+func (o HTTPClientOptions) dialer() DialContextFunc {
+	if o.DialContext != nil {
+		return o.DialContext
+	}
+
+	netDialer := &net.Dialer{
+		Timeout:   defaultDialTimeout,
+		KeepAlive: o.KeepAlive,
+	}
+	if o.UnixSocket != "" {
+		path := o.UnixSocket
+		return func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return netDialer.DialContext(ctx, "unix", path)
+		}
+	}
+	return netDialer.DialContext
+}
+
+// BuildHTTPClient 与 NewHTTPClient 相同，但会先校验选项，存在冲突时返回 ErrConflictingOptions
+//
+// 示例:
+//
+//	client, err := BuildHTTPClient(
+//	    WithUnixSocket("/var/run/docker.sock"),
+//	    WithTimeout(5 * time.Second),
+//	)
+//	resp, err := client.Get("http://docker/version")
+func BuildHTTPClient(opts ...Option) (*http.Client, error) {
+	options := defaultHTTPClientOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	return NewHTTPClient(opts...), nil
+}
@@ -0,0 +1,145 @@
+package functional_option
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+// 测试通过 Unix 域套接字访问本地服务
+func TestWithUnixSocket(t *testing.T) {
+	dir, err := os.MkdirTemp("", "fo")
+	if err != nil {
+		t.Fatalf("创建临时目录失败: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	socket := filepath.Join(dir, "daemon.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("当前环境不支持 Unix 域套接字: %v", err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "host="+r.Host)
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	client, err := BuildHTTPClient(WithUnixSocket(socket))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+
+	resp, err := client.Get("http://docker/version")
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "host=docker" {
+		t.Errorf("URL 中的主机名应只用于 Host 请求头，实际响应为 %q", body)
+	}
+
+	// 空路径被忽略
+	options := defaultHTTPClientOptions()
+	WithUnixSocket("")(&options)
+	if options.UnixSocket != "" {
+		t.Errorf("空路径应被忽略")
+	}
+}
+
+// 测试自定义拨号函数
+func TestWithDialContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	// 模拟自定义 DNS：把所有地址解析到测试服务器
+	var dialed atomic.Int32
+	var dialedAddr atomic.Value
+	dialer := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed.Add(1)
+		dialedAddr.Store(addr)
+		var d net.Dialer
+		return d.DialContext(ctx, network, server.Listener.Addr().String())
+	}
+
+	client, err := BuildHTTPClient(WithDialContext(dialer))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+
+	resp, err := client.Get("http://api.internal:8080/")
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+
+	if dialed.Load() != 1 {
+		t.Errorf("自定义拨号函数应被调用一次，实际为 %d", dialed.Load())
+	}
+	if addr := dialedAddr.Load(); addr != "api.internal:8080" {
+		t.Errorf("拨号函数应收到原始地址，实际为 %v", addr)
+	}
+
+	// ConfigureHTTPClient 同样支持
+	configured := ConfigureHTTPClient(&http.Client{}, WithDialContext(dialer))
+	resp, err = configured.Get("http://another.internal/")
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+	if dialed.Load() != 2 {
+		t.Errorf("配置后的客户端应使用自定义拨号函数")
+	}
+}
+
+// 测试互斥选项的校验
+func TestDialOptionsMutualExclusion(t *testing.T) {
+	dialer := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, errors.New("不应被调用")
+	}
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr bool
+	}{
+		{"仅Unix套接字", []Option{WithUnixSocket("/tmp/a.sock")}, false},
+		{"仅拨号函数", []Option{WithDialContext(dialer)}, false},
+		{"仅代理", []Option{WithProxyURL("http://proxy:8080")}, false},
+		{"Unix套接字与拨号函数", []Option{WithUnixSocket("/tmp/a.sock"), WithDialContext(dialer)}, true},
+		{"Unix套接字与代理", []Option{WithUnixSocket("/tmp/a.sock"), WithProxyURL("http://proxy:8080")}, true},
+		{"拨号函数与代理", []Option{WithProxy(http.ProxyFromEnvironment), WithDialContext(dialer)}, true},
+		{"拨号函数与自定义Transport", []Option{WithDialContext(dialer), WithCustomTransport(&http.Transport{})}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := BuildHTTPClient(tt.opts...)
+			if tt.wantErr {
+				if !errors.Is(err, ErrConflictingOptions) {
+					t.Errorf("应返回 ErrConflictingOptions，实际为 %v", err)
+				}
+				if client != nil {
+					t.Errorf("出错时不应返回客户端")
+				}
+				return
+			}
+			if err != nil || client == nil {
+				t.Errorf("不应出错，实际为 %v", err)
+			}
+		})
+	}
+}
@@ -4,7 +4,6 @@ package functional_option
 
 import (
 	"crypto/tls"
-	"net"
 	"net/http"
 	"net/url"
 	"time"
@@ -27,6 +26,9 @@ type HTTPClientOptions struct {
 	RetryMax           int                                        // 最大重试次数
 	RetryWaitMin       time.Duration                              // 重试最小等待时间
 	RetryWaitMax       time.Duration                              // 重试最大等待时间
+	Headers            http.Header                                // 每个请求默认携带的请求头（仅 Client 使用）
+	UnixSocket         string                                     // Unix 域套接字路径，设置后所有连接都拨向该套接字
+	DialContext        DialContextFunc                            // 自定义拨号函数
 }
 
 // defaultHTTPClientOptions 返回具有合理默认值的配置
@@ -167,6 +169,19 @@ func WithRetry(maxRetries int, minWait, maxWait time.Duration) Option {
 	}
 }
 
+// WithDefaultHeader 设置每个请求默认携带的请求头，仅对 Client.Do 发出的请求生效
+func WithDefaultHeader(key, value string) Option {
+	return func(o *HTTPClientOptions) {
+		if key == "" {
+			return
+		}
+		if o.Headers == nil {
+			o.Headers = make(http.Header)
+		}
+		o.Headers.Set(key, value)
+	}
+}
+
 // WithCustomTransport 设置自定义传输配置
 func WithCustomTransport(transport *http.Transport) Option {
 	return func(o *HTTPClientOptions) {
@@ -177,6 +192,7 @@ func WithCustomTransport(transport *http.Transport) Option {
 }
 
 // NewHTTPClient 使用功能选项模式创建并配置HTTP客户端
+// 它不检查选项之间的冲突，需要校验时使用 BuildHTTPClient
 //
 // 示例:
 //
@@ -199,11 +215,8 @@ func NewHTTPClient(opts ...Option) *http.Client {
 	transport := options.Transport
 	if transport == nil {
 		transport = &http.Transport{
-			Proxy: options.Proxy,
-			DialContext: (&net.Dialer{
-				Timeout:   30 * time.Second, // 连接超时
-				KeepAlive: options.KeepAlive,
-			}).DialContext,
+			Proxy:                  options.Proxy,
+			DialContext:            options.dialer(),
 			MaxIdleConns:           options.MaxIdleConns,
 			IdleConnTimeout:        options.IdleConnTimeout,
 			TLSClientConfig:        options.TLSConfig,
@@ -278,18 +291,12 @@ func ConfigureHTTPClient(client *http.Client, opts ...Option) *http.Client {
 		transport.Proxy = options.Proxy
 		transport.TLSClientConfig = options.TLSConfig
 
-		// 创建一个新的DialContext以应用KeepAlive设置
-		transport.DialContext = (&net.Dialer{
-			Timeout:   30 * time.Second,
-			KeepAlive: options.KeepAlive,
-		}).DialContext
+		// 创建一个新的DialContext以应用KeepAlive和拨号设置
+		transport.DialContext = options.dialer()
 	} else {
 		client.Transport = &http.Transport{
-			Proxy: options.Proxy,
-			DialContext: (&net.Dialer{
-				Timeout:   30 * time.Second,
-				KeepAlive: options.KeepAlive,
-			}).DialContext,
+			Proxy:                  options.Proxy,
+			DialContext:            options.dialer(),
 			MaxIdleConns:           options.MaxIdleConns,
 			IdleConnTimeout:        options.IdleConnTimeout,
 			TLSClientConfig:        options.TLSConfig,
@@ -0,0 +1,227 @@
+package functional_option
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RequestOptions 包含单个请求的可配置选项
+// 未被请求级选项覆盖的字段继承自客户端级选项
+type RequestOptions struct {
+	Timeout      time.Duration // 本次请求的超时时间（包含所有重试）
+	Headers      http.Header   // 本次请求的请求头
+	Query        url.Values    // 追加到URL上的查询参数
+	RetryMax     int           // 最大重试次数
+	RetryWaitMin time.Duration // 重试最小等待时间
+	RetryWaitMax time.Duration // 重试最大等待时间
+}
+
+// RequestOption 定义修改RequestOptions的函数类型
+type RequestOption func(*RequestOptions)
+
+// WithRequestTimeout 覆盖本次请求的超时时间
+func WithRequestTimeout(timeout time.Duration) RequestOption {
+	return func(o *RequestOptions) {
+		if timeout > 0 {
+			o.Timeout = timeout
+		}
+	}
+}
+
+// WithHeader 设置本次请求的请求头，会覆盖客户端默认的同名请求头
+func WithHeader(key, value string) RequestOption {
+	return func(o *RequestOptions) {
+		if key != "" {
+			o.Headers.Set(key, value)
+		}
+	}
+}
+
+// WithQuery 为本次请求追加查询参数
+func WithQuery(key, value string) RequestOption {
+	return func(o *RequestOptions) {
+		if key != "" {
+			o.Query.Add(key, value)
+		}
+	}
+}
+
+// WithRequestRetry 覆盖本次请求的重试策略，等待时间为0时沿用客户端配置
+func WithRequestRetry(maxRetries int, minWait, maxWait time.Duration) RequestOption {
+	return func(o *RequestOptions) {
+		if maxRetries >= 0 {
+			o.RetryMax = maxRetries
+		}
+		if minWait > 0 {
+			o.RetryWaitMin = minWait
+		}
+		if maxWait > 0 {
+			o.RetryWaitMax = maxWait
+		}
+	}
+}
+
+// WithNoRetry 禁用本次请求的重试
+func WithNoRetry() RequestOption {
+	return WithRequestRetry(0, 0, 0)
+}
+
+// Client 在 http.Client 之上提供两层函数选项：
+// 创建时的 Option 作为所有请求的默认值，每次调用 Do 时的 RequestOption 只影响当次请求
+type Client struct {
+	httpClient *http.Client
+	defaults   HTTPClientOptions
+}
+
+// NewClient 使用客户端级选项创建Client
+//
+// 示例:
+//
+//	client := NewClient(
+//	    WithTimeout(5*time.Second),
+//	    WithRetry(2, 100*time.Millisecond, time.Second),
+//	    WithDefaultHeader("User-Agent", "demo/1.0"),
+//	)
+//	resp, err := client.Do(ctx, http.MethodGet, "https://example.com/search", nil,
+//	    WithQuery("q", "golang"),
+//	    WithRequestTimeout(time.Second),
+//	)
+func NewClient(opts ...Option) *Client {
+	defaults := defaultHTTPClientOptions()
+	for _, opt := range opts {
+		opt(&defaults)
+	}
+
+	httpClient := NewHTTPClient(opts...)
+	// 超时由每个请求的上下文控制，否则请求级超时无法超过客户端超时
+	httpClient.Timeout = 0
+
+	return &Client{
+		httpClient: httpClient,
+		defaults:   defaults,
+	}
+}
+
+// HTTPClient 返回底层的 http.Client
+func (c *Client) HTTPClient() *http.Client {
+	return c.httpClient
+}
+
+// RequestOptions 返回客户端默认值叠加请求级选项后的最终配置
+func (c *Client) RequestOptions(reqOpts ...RequestOption) RequestOptions {
+	options := RequestOptions{
+		Timeout:      c.defaults.Timeout,
+		Headers:      c.defaults.Headers.Clone(),
+		Query:        make(url.Values),
+		RetryMax:     c.defaults.RetryMax,
+		RetryWaitMin: c.defaults.RetryWaitMin,
+		RetryWaitMax: c.defaults.RetryWaitMax,
+	}
+	if options.Headers == nil {
+		options.Headers = make(http.Header)
+	}
+
+	for _, opt := range reqOpts {
+		opt(&options)
+	}
+	return options
+}
+
+// Do 发送请求，网络错误和5xx响应会按照重试策略重试
+// 返回的响应体必须由调用者关闭
+func (c *Client) Do(ctx context.Context, method, rawURL string, body io.Reader, reqOpts ...RequestOption) (*http.Response, error) {
+	options := c.RequestOptions(reqOpts...)
+
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("解析URL失败: %w", err)
+	}
+	if len(options.Query) > 0 {
+		query := target.Query()
+		for key, values := range options.Query {
+			for _, value := range values {
+				query.Add(key, value)
+			}
+		}
+		target.RawQuery = query.Encode()
+	}
+
+	// 读取请求体以便在重试时重新发送
+	var payload []byte
+	if body != nil {
+		if payload, err = io.ReadAll(body); err != nil {
+			return nil, fmt.Errorf("读取请求体失败: %w", err)
+		}
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if options.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(payload))
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("创建请求失败: %w", err)
+		}
+		if payload == nil {
+			req.Body = http.NoBody
+		}
+		req.Header = options.Headers.Clone()
+
+		resp, err := c.httpClient.Do(req)
+		retryable := err != nil || resp.StatusCode >= http.StatusInternalServerError
+		if !retryable || attempt >= options.RetryMax {
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			// 响应体关闭时才取消上下文，否则调用者无法读取响应体
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-time.After(options.backoff(attempt)):
+		case <-ctx.Done():
+			cancel()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// backoff 返回第 attempt 次重试前的等待时间，从最小等待时间开始指数增长
+func (o RequestOptions) backoff(attempt int) time.Duration {
+	wait := o.RetryWaitMin
+	for i := 0; i < attempt && wait < o.RetryWaitMax; i++ {
+		wait *= 2
+	}
+	if o.RetryWaitMax > 0 && wait > o.RetryWaitMax {
+		wait = o.RetryWaitMax
+	}
+	return wait
+}
+
+// cancelOnClose 在响应体关闭时释放请求上下文
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close 关闭响应体并取消上下文
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
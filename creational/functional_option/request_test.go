@@ -0,0 +1,164 @@
+package functional_option
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 测试请求级选项叠加在客户端默认值之上
+func TestRequestOptionsLayering(t *testing.T) {
+	client := NewClient(
+		WithTimeout(5*time.Second),
+		WithRetry(3, 10*time.Millisecond, 100*time.Millisecond),
+		WithDefaultHeader("User-Agent", "demo/1.0"),
+		WithDefaultHeader("Accept", "application/json"),
+	)
+
+	// 不传请求级选项时完全继承客户端默认值
+	defaults := client.RequestOptions()
+	if defaults.Timeout != 5*time.Second || defaults.RetryMax != 3 {
+		t.Errorf("应继承客户端默认值，实际为%+v", defaults)
+	}
+	if defaults.Headers.Get("User-Agent") != "demo/1.0" {
+		t.Errorf("应继承默认请求头，实际为%v", defaults.Headers)
+	}
+
+	options := client.RequestOptions(
+		WithRequestTimeout(time.Minute),
+		WithHeader("Accept", "text/plain"),
+		WithRequestRetry(1, 0, 0),
+	)
+	if options.Timeout != time.Minute {
+		t.Errorf("请求级超时应为1分钟，实际为%v", options.Timeout)
+	}
+	if options.Headers.Get("Accept") != "text/plain" || options.Headers.Get("User-Agent") != "demo/1.0" {
+		t.Errorf("请求头叠加错误: %v", options.Headers)
+	}
+	if options.RetryMax != 1 || options.RetryWaitMin != 10*time.Millisecond {
+		t.Errorf("重试配置叠加错误: %+v", options)
+	}
+
+	// 请求级选项不能修改客户端默认值
+	if client.RequestOptions().Headers.Get("Accept") != "application/json" {
+		t.Error("请求级选项不应影响客户端默认请求头")
+	}
+	if client.HTTPClient().Timeout != 0 {
+		t.Error("底层客户端的超时应交由请求上下文控制")
+	}
+}
+
+// 测试请求头和查询参数被发送到服务端
+func TestClientDoHeadersAndQuery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Agent", r.Header.Get("User-Agent"))
+		w.Header().Set("X-Trace", r.Header.Get("X-Trace"))
+		io.WriteString(w, r.Method+" "+r.URL.RawQuery+" "+string(body))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithDefaultHeader("User-Agent", "demo/1.0"))
+	resp, err := client.Do(context.Background(), http.MethodPost, server.URL+"/items?page=1",
+		strings.NewReader("payload"),
+		WithHeader("X-Trace", "abc"),
+		WithQuery("q", "go"),
+		WithQuery("q", "design"),
+	)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "POST page=1&q=go&q=design payload" {
+		t.Errorf("服务端收到的请求不正确: %s", body)
+	}
+	if resp.Header.Get("X-Agent") != "demo/1.0" || resp.Header.Get("X-Trace") != "abc" {
+		t.Errorf("请求头未正确发送: %v", resp.Header)
+	}
+}
+
+// 测试重试策略及请求级覆盖
+func TestClientDoRetry(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, string(body))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithRetry(2, time.Millisecond, 5*time.Millisecond))
+
+	// 客户端默认重试2次，第3次成功，且每次都重新发送请求体
+	resp, err := client.Do(context.Background(), http.MethodPut, server.URL, strings.NewReader("data"))
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "data" || calls.Load() != 3 {
+		t.Errorf("重试结果不正确: 状态码=%d, 响应=%s, 调用次数=%d", resp.StatusCode, body, calls.Load())
+	}
+
+	// 请求级禁用重试时直接返回5xx响应
+	calls.Store(0)
+	resp, err = client.Do(context.Background(), http.MethodGet, server.URL, nil, WithNoRetry())
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 {
+		t.Errorf("禁用重试后应只请求一次，状态码=%d, 调用次数=%d", resp.StatusCode, calls.Load())
+	}
+}
+
+// 测试请求级超时可以比客户端超时更短或更长
+func TestClientDoTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(100 * time.Millisecond):
+			io.WriteString(w, "slow")
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(WithTimeout(20 * time.Millisecond))
+
+	_, err := client.Do(context.Background(), http.MethodGet, server.URL, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("客户端默认超时应生效，实际错误: %v", err)
+	}
+
+	resp, err := client.Do(context.Background(), http.MethodGet, server.URL, nil, WithRequestTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("放宽请求级超时后请求应成功: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "slow" {
+		t.Errorf("响应不正确: %s", body)
+	}
+}
+
+// 测试重试等待时间的指数增长
+func TestRequestBackoff(t *testing.T) {
+	options := RequestOptions{RetryWaitMin: 10 * time.Millisecond, RetryWaitMax: 50 * time.Millisecond}
+	expected := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 50 * time.Millisecond}
+	for attempt, want := range expected {
+		if got := options.backoff(attempt); got != want {
+			t.Errorf("第%d次重试等待时间应为%v，实际为%v", attempt, want, got)
+		}
+	}
+}
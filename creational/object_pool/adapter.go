@@ -0,0 +1,112 @@
+package object_pool
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// SyncPool 是 sync.Pool 风格的 Get/Put 接口，*sync.Pool 和 *PoolAdapter 都实现了它
+// 依赖该接口的代码可以在两者之间切换而不需要修改
+type SyncPool interface {
+	Get() any
+	Put(x any)
+}
+
+// AdapterStats 记录适配器的调用统计，Pool 为底层对象池的统计信息
+type AdapterStats struct {
+	// Get 调用总数
+	Gets int64
+
+	// 从对象池取到对象的次数
+	Hits int64
+
+	// 对象池没有可用对象时调用 New 的次数
+	Fallbacks int64
+
+	// 对象池没有可用对象且没有 New 时返回 nil 的次数
+	Misses int64
+
+	// Put 调用总数(不包括 nil)
+	Puts int64
+
+	// 不是 Object 或不属于对象池而被丢弃的次数
+	Dropped int64
+
+	// 底层对象池的统计信息
+	Pool PoolStats
+}
+
+// PoolAdapter 以 sync.Pool 的 Get/Put 语义包装 ObjectPool
+// 对象仍然经过池的校验、重置和容量限制，统计信息也保留在池中
+type PoolAdapter struct {
+	// New 在对象池没有可用对象时创建临时对象，与 sync.Pool.New 相同，可以为 nil
+	// New 创建的对象不属于池，Put 时会被丢弃
+	New func() any
+
+	// Timeout 是 Get 等待空闲对象的最长时间，0 表示不等待，与 sync.Pool 一样从不阻塞
+	Timeout time.Duration
+
+	pool *ObjectPool
+
+	gets      atomic.Int64
+	hits      atomic.Int64
+	fallbacks atomic.Int64
+	misses    atomic.Int64
+	puts      atomic.Int64
+	dropped   atomic.Int64
+}
+
+// NewPoolAdapter 创建包装 pool 的适配器，New 和 Timeout 应在使用前设置
+func NewPoolAdapter(pool *ObjectPool) *PoolAdapter {
+	return &PoolAdapter{pool: pool}
+}
+
+// Get 从对象池获取对象，池已满、超时或已关闭时返回 New 的结果，没有 New 时返回 nil
+func (a *PoolAdapter) Get() any {
+	a.gets.Add(1)
+
+	obj, err := a.pool.AcquireWithTimeout(a.Timeout)
+	if err == nil {
+		a.hits.Add(1)
+		return obj
+	}
+
+	if a.New != nil {
+		a.fallbacks.Add(1)
+		return a.New()
+	}
+	a.misses.Add(1)
+	return nil
+}
+
+// Put 将对象归还给对象池，与 sync.Pool 一样忽略 nil
+// 不是 Object、不属于池或池已关闭时对象被丢弃并计入 Dropped，校验失败的对象由池销毁
+func (a *PoolAdapter) Put(x any) {
+	if x == nil {
+		return
+	}
+	a.puts.Add(1)
+
+	obj, ok := x.(Object)
+	if !ok || a.pool.ReleaseObject(obj) != nil {
+		a.dropped.Add(1)
+	}
+}
+
+// Pool 返回底层的对象池
+func (a *PoolAdapter) Pool() *ObjectPool {
+	return a.pool
+}
+
+// Stats 返回适配器和底层对象池的统计信息
+func (a *PoolAdapter) Stats() AdapterStats {
+	return AdapterStats{
+		Gets:      a.gets.Load(),
+		Hits:      a.hits.Load(),
+		Fallbacks: a.fallbacks.Load(),
+		Misses:    a.misses.Load(),
+		Puts:      a.puts.Load(),
+		Dropped:   a.dropped.Load(),
+		Pool:      a.pool.Stats(),
+	}
+}
@@ -0,0 +1,143 @@
+package object_pool
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// *sync.Pool 和 *PoolAdapter 可以互换使用
+var (
+	_ SyncPool = (*sync.Pool)(nil)
+	_ SyncPool = (*PoolAdapter)(nil)
+)
+
+// newAdapterTestPool 创建最多 2 个对象的池
+func newAdapterTestPool(t *testing.T) *ObjectPool {
+	t.Helper()
+
+	var nextID atomic.Int32
+	config := DefaultPoolConfig(func() (Object, error) {
+		return NewSimpleObject(int(nextID.Add(1))), nil
+	})
+	config.InitialSize = 1
+	config.MaxSize = 2
+	config.MaxIdle = 2
+
+	pool, err := NewObjectPool(config)
+	if err != nil {
+		t.Fatalf("创建对象池失败: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// 测试 Get/Put 复用池中的对象，池满时回退到 New
+func TestPoolAdapterGetPut(t *testing.T) {
+	adapter := NewPoolAdapter(newAdapterTestPool(t))
+
+	first := adapter.Get()
+	second := adapter.Get()
+	if first == nil || second == nil || first == second {
+		t.Fatalf("应从池中取到两个不同的对象: %v, %v", first, second)
+	}
+	if got := adapter.Get(); got != nil {
+		t.Errorf("池满且没有 New 时应返回 nil，得到 %v", got)
+	}
+
+	adapter.New = func() any { return NewSimpleObject(-1) }
+	temp := adapter.Get()
+	if obj, ok := temp.(*SimpleObject); !ok || obj.ID() != -1 {
+		t.Errorf("池满时应使用 New 创建临时对象，得到 %v", temp)
+	}
+
+	adapter.Put(first)
+	if got := adapter.Get(); got != first {
+		t.Errorf("归还的对象应被复用，得到 %v", got)
+	}
+
+	adapter.Put(temp)  // 不属于池
+	adapter.Put("字符串") // 不是 Object
+	adapter.Put(nil)   // 与 sync.Pool 一样忽略
+	adapter.Put(second)
+	adapter.Put(second) // 重复归还
+
+	stats := adapter.Stats()
+	want := AdapterStats{Gets: 5, Hits: 3, Fallbacks: 1, Misses: 1, Puts: 5, Dropped: 3}
+	stats.Pool = PoolStats{}
+	if stats != want {
+		t.Errorf("适配器统计不正确:\n得到 %+v\n期望 %+v", stats, want)
+	}
+	if pool := adapter.Stats().Pool; pool.Acquired != 3 || pool.Released != 2 {
+		t.Errorf("底层对象池统计应保留: %+v", pool)
+	}
+}
+
+// 测试归还的对象仍然经过池的校验
+func TestPoolAdapterValidation(t *testing.T) {
+	adapter := NewPoolAdapter(newAdapterTestPool(t))
+
+	obj := adapter.Get().(*SimpleObject)
+	obj.valid = false
+	adapter.Put(obj)
+
+	if stats := adapter.Stats(); stats.Dropped != 0 || stats.Pool.Destroyed != 1 {
+		t.Errorf("无效对象应由池销毁而不是被适配器丢弃: %+v", stats)
+	}
+	if active, idle, total := adapter.Pool().Status(); active != 0 || idle != 0 || total != 0 {
+		t.Errorf("无效对象不应回到池中: active=%d idle=%d total=%d", active, idle, total)
+	}
+	if got := adapter.Get(); got == nil || got == any(obj) {
+		t.Errorf("销毁后应创建新对象，得到 %v", got)
+	}
+}
+
+// 测试池关闭后的行为
+func TestPoolAdapterClosed(t *testing.T) {
+	pool := newAdapterTestPool(t)
+	adapter := NewPoolAdapter(pool)
+	obj := adapter.Get()
+	pool.Close()
+
+	if got := adapter.Get(); got != nil {
+		t.Errorf("池关闭后应返回 nil，得到 %v", got)
+	}
+	adapter.Put(obj)
+	if stats := adapter.Stats(); stats.Misses != 1 || stats.Dropped != 1 {
+		t.Errorf("池关闭后归还的对象应被丢弃: %+v", stats)
+	}
+}
+
+// benchmarkSyncPool 并发地 Get/Put，用于对比不同的 SyncPool 实现
+func benchmarkSyncPool(b *testing.B, pool SyncPool) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			obj := pool.Get().(Object)
+			_ = obj.Validate()
+			pool.Put(obj)
+		}
+	})
+}
+
+// BenchmarkSyncPoolCompare 对比 sync.Pool 与 PoolAdapter 的开销
+func BenchmarkSyncPoolCompare(b *testing.B) {
+	b.Run("sync.Pool", func(b *testing.B) {
+		pool := &sync.Pool{New: func() any { return NewSimpleObject(0) }}
+		benchmarkSyncPool(b, pool)
+	})
+
+	b.Run("PoolAdapter", func(b *testing.B) {
+		config := DefaultPoolConfig(SimpleObjectFactory)
+		config.InitialSize = 10
+		config.MaxSize = 50
+		config.MaxIdle = 50
+		pool, _ := NewObjectPool(config)
+		defer pool.Close()
+
+		adapter := NewPoolAdapter(pool)
+		adapter.New = func() any { return NewSimpleObject(-1) }
+		benchmarkSyncPool(b, adapter)
+	})
+}
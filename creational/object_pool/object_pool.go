@@ -50,6 +50,27 @@ type PoolConfig struct {
 
 	// ValidationInterval 是验证空闲对象的时间间隔
 	ValidationInterval time.Duration
+
+	// AsyncWarmup 为 true 时 InitialSize 个对象在后台并行预创建，NewObjectPool 立即返回
+	// 此时 Factory 会被多个协程同时调用，必须是并发安全的
+	AsyncWarmup bool
+
+	// WarmupConcurrency 是异步预热时并行创建对象的最大协程数
+	WarmupConcurrency int
+
+	// MinWarmObjects 是获取对象前至少需要预热完成的对象数量，0 表示不等待
+	MinWarmObjects int
+
+	// OnWarmupComplete 在预热结束时被调用(同步和异步预热都会调用)
+	OnWarmupComplete func(WarmupResult)
+
+	// PriorityAging 是按优先级排队的请求被提升前的最长等待时间，0 表示使用 DefaultPriorityAging
+	PriorityAging time.Duration
+
+	// MaxBurst 大于 0 时开启分层模式：最多 MaxIdle 个对象组成预热并缓存的热层，
+	// 热层用完时立即创建溢出层对象应对突发流量，对象总数不超过 MaxBurst(此时忽略 MaxSize)，
+	// 溢出层对象归还时直接销毁而不缓存
+	MaxBurst int
 }
 
 // DefaultPoolConfig 返回具有合理默认值的池配置
@@ -92,12 +113,25 @@ type ObjectPool struct {
 
 	// 统计信息
 	stats PoolStats
+
+	// 预热状态
+	warmup warmupState
+
+	// 按优先级排队的等待者
+	waiters waitQueue
+
+	// 各优先级通道的统计信息
+	lanes [numPriorities]LaneStats
+
+	// 各层级的统计信息
+	tiers [numTiers]TierStats
 }
 
 // poolObject 表示对象池中的一个对象及其状态
 type poolObject struct {
 	obj    Object
 	active bool
+	tier   Tier
 }
 
 // PoolStats 记录池的使用统计信息
@@ -144,26 +178,39 @@ func NewObjectPool(config PoolConfig) (*ObjectPool, error) {
 		config.MaxIdle = config.MaxSize
 	}
 
+	normalizeTiers(&config)
+
+	if config.MinWarmObjects > config.InitialSize {
+		config.MinWarmObjects = config.InitialSize
+	}
+
 	pool := &ObjectPool{
 		config:      config,
 		idle:        make(chan Object, config.MaxSize),
 		objects:     make(map[int]poolObject),
 		lastReturn:  make(map[int]time.Time),
 		stopCleaner: make(chan struct{}),
+		warmup:      newWarmupState(),
 	}
 
-	// 初始化对象
-	for i := 0; i < config.InitialSize; i++ {
-		obj, err := config.Factory()
-		if err != nil {
-			// 如果创建失败，释放已创建的对象
-			pool.Close()
-			return nil, err
-		}
+	if config.AsyncWarmup {
+		// 异步预热，在后台并行创建初始对象
+		go pool.warmUp()
+	} else {
+		// 初始化对象
+		start := time.Now()
+		for i := 0; i < config.InitialSize; i++ {
+			obj, err := config.Factory()
+			if err != nil {
+				// 如果创建失败，释放已创建的对象
+				pool.Close()
+				return nil, err
+			}
 
-		pool.idle <- obj
-		pool.objects[obj.ID()] = poolObject{obj: obj, active: false}
-		pool.stats.Created++
+			pool.idle <- obj
+			pool.trackLocked(obj, false)
+		}
+		pool.finishWarmup(WarmupResult{Created: config.InitialSize, Duration: time.Since(start)})
 	}
 
 	// 启动后台清理协程
@@ -212,9 +259,7 @@ func (p *ObjectPool) evictExpiredObjects() {
 			lastUsed, exists := p.lastReturn[obj.ID()]
 			// 如果对象长时间未使用,或者无效,则销毁它
 			if !exists || now.Sub(lastUsed) > p.config.MinEvictableIdleTime || !obj.Validate() {
-				delete(p.objects, obj.ID())
-				delete(p.lastReturn, obj.ID())
-				p.stats.Destroyed++
+				p.untrackLocked(obj)
 			} else {
 				// 对象仍然有效,放回通道
 				p.idle <- obj
@@ -234,36 +279,39 @@ func (p *ObjectPool) AcquireWithTimeout(timeout time.Duration) (Object, error) {
 
 	startTime := time.Now()
 
-	// 尝试从空闲对象池获取
+	// 等待预热达到最少对象数
+	if !p.waitWarm(timeout) {
+		p.mu.Lock()
+		p.stats.Timeouts++
+		p.mu.Unlock()
+		return nil, ErrPoolTimeout
+	}
+	timeout -= time.Since(startTime)
+
+	// 快速路径：有空闲对象时直接获取，避免创建定时器
 	select {
 	case obj, ok := <-p.idle:
-		if !ok {
-			return nil, ErrPoolClosed
-		}
+		return p.takeIdle(obj, ok, startTime)
+	default:
+	}
 
-		// 更新对象状态和统计信息
-		p.mu.Lock()
-		info := p.objects[obj.ID()]
-		info.active = true
-		p.objects[obj.ID()] = info
-		p.activeCount++
-		waitTime := time.Since(startTime)
-		p.stats.WaitTime += waitTime
-		p.stats.Acquired++
-		if waitTime > p.stats.MaxWaitTime {
-			p.stats.MaxWaitTime = waitTime
+	// 分层模式下热层用完时立即创建溢出层对象，不等待
+	if p.Tiered() {
+		obj, err := p.createNewObject()
+		if err != ErrPoolAtMaxCapacity {
+			return obj, err
 		}
-		p.mu.Unlock()
+	}
 
-		// 验证对象并在必要时重置
-		if !obj.Validate() {
-			p.discardObject(obj)
-			return p.createNewObject()
-		}
+	// 尝试从空闲对象池获取
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
 
-		return obj, nil
+	select {
+	case obj, ok := <-p.idle:
+		return p.takeIdle(obj, ok, startTime)
 
-	case <-time.After(timeout):
+	case <-timer.C:
 		// 尝试创建新对象(如果池未满)
 		p.mu.Lock()
 		canCreate := len(p.objects) < p.config.MaxSize
@@ -281,6 +329,36 @@ func (p *ObjectPool) AcquireWithTimeout(timeout time.Duration) (Object, error) {
 	}
 }
 
+// takeIdle 将从空闲通道取出的对象标记为活跃
+func (p *ObjectPool) takeIdle(obj Object, ok bool, startTime time.Time) (Object, error) {
+	if !ok {
+		return nil, ErrPoolClosed
+	}
+
+	// 更新对象状态和统计信息
+	p.mu.Lock()
+	info := p.objects[obj.ID()]
+	info.active = true
+	p.objects[obj.ID()] = info
+	p.activeCount++
+	waitTime := time.Since(startTime)
+	p.stats.WaitTime += waitTime
+	p.stats.Acquired++
+	p.tiers[info.tier].Acquired++
+	if waitTime > p.stats.MaxWaitTime {
+		p.stats.MaxWaitTime = waitTime
+	}
+	p.mu.Unlock()
+
+	// 验证对象并在必要时重置
+	if !obj.Validate() {
+		p.discardObject(obj)
+		return p.createNewObject()
+	}
+
+	return obj, nil
+}
+
 // createNewObject 创建一个新对象并添加到池中
 func (p *ObjectPool) createNewObject() (Object, error) {
 	p.mu.Lock()
@@ -299,10 +377,7 @@ func (p *ObjectPool) createNewObject() (Object, error) {
 	}
 
 	// 记录新对象
-	p.objects[obj.ID()] = poolObject{obj: obj, active: true}
-	p.activeCount++
-	p.stats.Created++
-	p.stats.Acquired++
+	p.trackLocked(obj, true)
 
 	return obj, nil
 }
@@ -336,8 +411,14 @@ func (p *ObjectPool) ReleaseObject(obj Object) error {
 	p.activeCount--
 	p.lastReturn[obj.ID()] = time.Now()
 	p.stats.Released++
+	p.tiers[info.tier].Released++
 	p.mu.Unlock()
 
+	// 溢出层对象不缓存
+	if info.tier == TierOverflow {
+		return p.releaseOverflow(obj)
+	}
+
 	// 重置对象状态
 	if err := obj.Reset(); err != nil {
 		return p.discardObject(obj)
@@ -348,12 +429,26 @@ func (p *ObjectPool) ReleaseObject(obj Object) error {
 		return p.discardObject(obj)
 	}
 
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ErrPoolClosed
+	}
+
+	// 优先交给按优先级排队的等待者
+	if p.handOffLocked(obj) {
+		p.mu.Unlock()
+		return nil
+	}
+
 	// 将对象归还到池中
 	select {
 	case p.idle <- obj:
+		p.mu.Unlock()
 		return nil
 	default:
 		// 如果通道已满,丢弃对象
+		p.mu.Unlock()
 		return p.discardObject(obj)
 	}
 }
@@ -363,9 +458,10 @@ func (p *ObjectPool) discardObject(obj Object) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	delete(p.objects, obj.ID())
-	delete(p.lastReturn, obj.ID())
-	p.stats.Destroyed++
+	p.untrackLocked(obj)
+
+	// 腾出的容量优先用于满足排队中的请求
+	p.replenishLocked()
 	return nil
 }
 
@@ -381,6 +477,9 @@ func (p *ObjectPool) Close() {
 	p.closed = true
 	close(p.stopCleaner)
 
+	// 唤醒所有排队的请求
+	p.waiters.closeAll()
+
 	// 清空通道
 	close(p.idle)
 	// 修复"declared and not used"错误: 使用匿名变量接收通道值
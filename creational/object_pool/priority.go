@@ -0,0 +1,336 @@
+package object_pool
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultPriorityAging 未配置时低优先级请求被提升前的最长等待时间
+const DefaultPriorityAging = 100 * time.Millisecond
+
+// Priority 表示获取对象的优先级通道
+type Priority int
+
+const (
+	// PriorityLow 低优先级，适合批处理等对延迟不敏感的任务
+	PriorityLow Priority = iota
+	// PriorityNormal 普通优先级
+	PriorityNormal
+	// PriorityHigh 高优先级，适合交互式请求
+	PriorityHigh
+
+	numPriorities = int(PriorityHigh) + 1
+)
+
+// String 返回优先级名称
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	default:
+		return fmt.Sprintf("Priority(%d)", int(p))
+	}
+}
+
+// valid 判断优先级是否合法
+func (p Priority) valid() bool {
+	return p >= PriorityLow && p <= PriorityHigh
+}
+
+// LaneStats 记录单个优先级通道的统计信息
+type LaneStats struct {
+	// 通过该通道获取的对象总数
+	Acquired int
+
+	// 需要排队等待的次数
+	Waits int
+
+	// 等待超时的次数
+	Timeouts int
+
+	// 因等待过久被提升、先于更高优先级请求获得对象的次数
+	Promoted int
+
+	// 排队等待的总时间
+	WaitTime time.Duration
+
+	// 最大排队等待时间
+	MaxWaitTime time.Duration
+}
+
+// AverageWait 返回排队请求的平均等待时间
+func (s LaneStats) AverageWait() time.Duration {
+	if s.Waits == 0 {
+		return 0
+	}
+	return s.WaitTime / time.Duration(s.Waits)
+}
+
+// priorityWaiter 表示一个排队等待对象的请求
+type priorityWaiter struct {
+	priority Priority
+	enqueued time.Time
+	// 对象通过该通道直接交给等待者，缓冲为 1，发送方不会阻塞
+	ch chan Object
+}
+
+// waitQueue 按优先级分通道的等待队列，每个通道内先进先出
+type waitQueue struct {
+	lanes [numPriorities][]*priorityWaiter
+}
+
+// len 返回所有通道中等待者的总数
+func (q *waitQueue) len() int {
+	n := 0
+	for _, lane := range q.lanes {
+		n += len(lane)
+	}
+	return n
+}
+
+// push 将等待者加入对应通道的队尾
+func (q *waitQueue) push(w *priorityWaiter) {
+	q.lanes[w.priority] = append(q.lanes[w.priority], w)
+}
+
+// remove 从队列中移除等待者，返回是否找到
+func (q *waitQueue) remove(w *priorityWaiter) bool {
+	lane := q.lanes[w.priority]
+	for i, candidate := range lane {
+		if candidate == w {
+			q.lanes[w.priority] = append(lane[:i], lane[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// pop 取出下一个应当获得对象的等待者
+// 低于最高优先级的通道中，等待超过 aging 的请求最先被服务（防止饥饿）；
+// 否则按优先级从高到低、同一通道内先进先出。promoted 表示该等待者越过了更高优先级的请求
+func (q *waitQueue) pop(now time.Time, aging time.Duration) (w *priorityWaiter, promoted bool) {
+	// 每个通道的队首就是该通道中等待最久的请求
+	var aged *priorityWaiter
+	for p := PriorityLow; p < PriorityHigh; p++ {
+		lane := q.lanes[p]
+		if len(lane) == 0 || now.Sub(lane[0].enqueued) < aging {
+			continue
+		}
+		if aged == nil || lane[0].enqueued.Before(aged.enqueued) {
+			aged = lane[0]
+		}
+	}
+	if aged != nil {
+		for p := aged.priority + 1; p <= PriorityHigh; p++ {
+			if len(q.lanes[p]) > 0 {
+				promoted = true
+				break
+			}
+		}
+		q.lanes[aged.priority] = q.lanes[aged.priority][1:]
+		return aged, promoted
+	}
+
+	for p := PriorityHigh; p >= PriorityLow; p-- {
+		if lane := q.lanes[p]; len(lane) > 0 {
+			q.lanes[p] = lane[1:]
+			return lane[0], false
+		}
+	}
+	return nil, false
+}
+
+// closeAll 唤醒所有等待者，通知池已关闭
+func (q *waitQueue) closeAll() {
+	for p := range q.lanes {
+		for _, w := range q.lanes[p] {
+			close(w.ch)
+		}
+		q.lanes[p] = nil
+	}
+}
+
+// AcquireWithPriority 以指定优先级在超时时间内获取对象
+// 没有空闲对象且池已满时请求进入对应的优先级通道排队；对象归还时优先交给高优先级的等待者，
+// 等待超过 PriorityAging 的低优先级请求会被提升，避免在持续的高优先级负载下饿死。
+// 注意：AcquireObject/AcquireWithTimeout 不参与排队，归还的对象总是先满足排队中的请求
+func (p *ObjectPool) AcquireWithPriority(priority Priority, timeout time.Duration) (Object, error) {
+	if !priority.valid() {
+		return nil, fmt.Errorf("invalid priority %d", int(priority))
+	}
+	if p.closed {
+		return nil, ErrPoolClosed
+	}
+
+	startTime := time.Now()
+	if !p.waitWarm(timeout) {
+		p.mu.Lock()
+		p.stats.Timeouts++
+		p.lanes[priority].Timeouts++
+		p.mu.Unlock()
+		return nil, ErrPoolTimeout
+	}
+	timeout -= time.Since(startTime)
+
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return nil, ErrPoolClosed
+		}
+
+		// 没有人排队时才直接取空闲对象，避免插队
+		if p.waiters.len() == 0 {
+			select {
+			case obj, ok := <-p.idle:
+				p.lanes[priority].Acquired++
+				p.mu.Unlock()
+				return p.takeIdle(obj, ok, startTime)
+			default:
+			}
+
+			// 池未满时立即创建新对象
+			if len(p.objects) < p.config.MaxSize {
+				p.mu.Unlock()
+				obj, err := p.createNewObject()
+				if err == ErrPoolAtMaxCapacity {
+					// 与其他请求竞争失败，重新检查
+					continue
+				}
+				if err == nil {
+					p.mu.Lock()
+					p.lanes[priority].Acquired++
+					p.mu.Unlock()
+				}
+				return obj, err
+			}
+		}
+		break
+	}
+
+	// 进入优先级通道排队，仍持有锁
+	waiter := &priorityWaiter{
+		priority: priority,
+		enqueued: time.Now(),
+		ch:       make(chan Object, 1),
+	}
+	p.waiters.push(waiter)
+	p.lanes[priority].Waits++
+	p.stats.Waits++
+	p.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case obj, ok := <-waiter.ch:
+		return p.receiveHandOff(obj, ok)
+	case <-timer.C:
+	}
+
+	p.mu.Lock()
+	if !p.waiters.remove(waiter) {
+		// 超时与交付同时发生，对象已经在通道中
+		p.mu.Unlock()
+		obj, ok := <-waiter.ch
+		return p.receiveHandOff(obj, ok)
+	}
+	p.stats.Timeouts++
+	p.lanes[priority].Timeouts++
+	p.mu.Unlock()
+	return nil, ErrPoolTimeout
+}
+
+// receiveHandOff 处理从等待通道收到的对象
+func (p *ObjectPool) receiveHandOff(obj Object, ok bool) (Object, error) {
+	if !ok {
+		return nil, ErrPoolClosed
+	}
+	return obj, nil
+}
+
+// handOffLocked 把可用对象直接交给下一个等待者，调用方需持有锁
+// 返回 false 表示没有等待者，对象应放回空闲队列
+func (p *ObjectPool) handOffLocked(obj Object) bool {
+	now := time.Now()
+	w, promoted := p.waiters.pop(now, p.priorityAging())
+	if w == nil {
+		return false
+	}
+
+	// 对象保持活跃状态，所有权直接转移给等待者
+	info := p.objects[obj.ID()]
+	if !info.active {
+		info.active = true
+		p.activeCount++
+	}
+	info.obj = obj
+	p.objects[obj.ID()] = info
+
+	waitTime := now.Sub(w.enqueued)
+	lane := &p.lanes[w.priority]
+	lane.Acquired++
+	lane.WaitTime += waitTime
+	if waitTime > lane.MaxWaitTime {
+		lane.MaxWaitTime = waitTime
+	}
+	if promoted {
+		lane.Promoted++
+	}
+	p.stats.Acquired++
+	p.tiers[info.tier].Acquired++
+	p.stats.WaitTime += waitTime
+	if waitTime > p.stats.MaxWaitTime {
+		p.stats.MaxWaitTime = waitTime
+	}
+
+	w.ch <- obj
+	return true
+}
+
+// replenishLocked 对象被丢弃后，如果有等待者且池未满，创建新对象交给等待者，调用方需持有锁
+func (p *ObjectPool) replenishLocked() {
+	if p.closed || p.waiters.len() == 0 || len(p.objects) >= p.config.MaxSize {
+		return
+	}
+	obj, err := p.config.Factory()
+	if err != nil {
+		return
+	}
+	p.trackLocked(obj, false)
+	p.handOffLocked(obj)
+}
+
+// priorityAging 返回低优先级请求被提升前的最长等待时间
+func (p *ObjectPool) priorityAging() time.Duration {
+	if p.config.PriorityAging > 0 {
+		return p.config.PriorityAging
+	}
+	return DefaultPriorityAging
+}
+
+// PriorityStats 返回各优先级通道的统计信息
+func (p *ObjectPool) PriorityStats() map[Priority]LaneStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make(map[Priority]LaneStats, numPriorities)
+	for i, lane := range p.lanes {
+		stats[Priority(i)] = lane
+	}
+	return stats
+}
+
+// Waiting 返回指定优先级通道中正在排队的请求数量
+func (p *ObjectPool) Waiting(priority Priority) int {
+	if !priority.valid() {
+		return 0
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.waiters.lanes[priority])
+}
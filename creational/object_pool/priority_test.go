@@ -0,0 +1,188 @@
+package object_pool
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newPriorityTestPool 创建只有一个对象的池，并取出该对象，使后续请求必须排队
+func newPriorityTestPool(t *testing.T, aging time.Duration) (*ObjectPool, Object) {
+	t.Helper()
+
+	var nextID atomic.Int32
+	config := DefaultPoolConfig(func() (Object, error) {
+		return NewSimpleObject(int(nextID.Add(1))), nil
+	})
+	config.InitialSize = 1
+	config.MaxSize = 1
+	config.MaxIdle = 1
+	config.PriorityAging = aging
+
+	pool, err := NewObjectPool(config)
+	if err != nil {
+		t.Fatalf("创建对象池失败: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	obj, err := pool.AcquireWithPriority(PriorityNormal, time.Second)
+	if err != nil {
+		t.Fatalf("获取对象失败: %v", err)
+	}
+	return pool, obj
+}
+
+// acquireAsync 在后台以指定优先级获取对象，并等待请求进入队列
+func acquireAsync(t *testing.T, pool *ObjectPool, priority Priority, order chan<- Priority) {
+	t.Helper()
+
+	before := pool.Waiting(priority)
+	go func() {
+		obj, err := pool.AcquireWithPriority(priority, 5*time.Second)
+		if err != nil {
+			return
+		}
+		order <- priority
+		_ = pool.ReleaseObject(obj)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for pool.Waiting(priority) == before {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s 优先级请求未进入队列", priority)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPriorityOrdering(t *testing.T) {
+	pool, obj := newPriorityTestPool(t, time.Hour)
+
+	order := make(chan Priority, 3)
+	acquireAsync(t, pool, PriorityLow, order)
+	acquireAsync(t, pool, PriorityNormal, order)
+	acquireAsync(t, pool, PriorityHigh, order)
+
+	if err := pool.ReleaseObject(obj); err != nil {
+		t.Fatalf("归还对象失败: %v", err)
+	}
+
+	want := []Priority{PriorityHigh, PriorityNormal, PriorityLow}
+	for i, expected := range want {
+		select {
+		case got := <-order:
+			if got != expected {
+				t.Errorf("第 %d 个获得对象的应是 %s，实际为 %s", i+1, expected, got)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("等待第 %d 个请求超时", i+1)
+		}
+	}
+
+	stats := pool.PriorityStats()
+	for _, priority := range want {
+		if stats[priority].Waits != 1 {
+			t.Errorf("%s 通道应排队 1 次，实际为 %d", priority, stats[priority].Waits)
+		}
+	}
+	if stats[PriorityLow].WaitTime < stats[PriorityHigh].WaitTime {
+		t.Errorf("低优先级的等待时间应不少于高优先级")
+	}
+}
+
+func TestPriorityStarvationProtection(t *testing.T) {
+	pool, obj := newPriorityTestPool(t, 20*time.Millisecond)
+
+	order := make(chan Priority, 2)
+	acquireAsync(t, pool, PriorityLow, order)
+	time.Sleep(30 * time.Millisecond) // 低优先级请求等待超过提升阈值
+	acquireAsync(t, pool, PriorityHigh, order)
+
+	if err := pool.ReleaseObject(obj); err != nil {
+		t.Fatalf("归还对象失败: %v", err)
+	}
+
+	if got := <-order; got != PriorityLow {
+		t.Errorf("等待过久的低优先级请求应先获得对象，实际为 %s", got)
+	}
+	if got := <-order; got != PriorityHigh {
+		t.Errorf("随后应轮到高优先级请求，实际为 %s", got)
+	}
+	if promoted := pool.PriorityStats()[PriorityLow].Promoted; promoted != 1 {
+		t.Errorf("低优先级通道应记录 1 次提升，实际为 %d", promoted)
+	}
+}
+
+func TestPriorityTimeout(t *testing.T) {
+	pool, _ := newPriorityTestPool(t, time.Hour)
+
+	_, err := pool.AcquireWithPriority(PriorityLow, 20*time.Millisecond)
+	if !errors.Is(err, ErrPoolTimeout) {
+		t.Fatalf("应返回 ErrPoolTimeout，实际为 %v", err)
+	}
+	if pool.Waiting(PriorityLow) != 0 {
+		t.Errorf("超时的请求应离开队列")
+	}
+	if timeouts := pool.PriorityStats()[PriorityLow].Timeouts; timeouts != 1 {
+		t.Errorf("低优先级通道应记录 1 次超时，实际为 %d", timeouts)
+	}
+}
+
+func TestPriorityCloseWakesWaiters(t *testing.T) {
+	pool, _ := newPriorityTestPool(t, time.Hour)
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := pool.AcquireWithPriority(PriorityHigh, 5*time.Second)
+		errCh <- err
+	}()
+	for pool.Waiting(PriorityHigh) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	pool.Close()
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, ErrPoolClosed) {
+			t.Errorf("关闭池后排队请求应返回 ErrPoolClosed，实际为 %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("关闭池后排队请求未被唤醒")
+	}
+}
+
+func TestPriorityDiscardReplenishesWaiter(t *testing.T) {
+	pool, obj := newPriorityTestPool(t, time.Hour)
+
+	done := make(chan Object, 1)
+	go func() {
+		got, err := pool.AcquireWithPriority(PriorityNormal, 5*time.Second)
+		if err == nil {
+			done <- got
+		}
+	}()
+	for pool.Waiting(PriorityNormal) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// 归还失效的对象，池应创建新对象交给等待者
+	obj.(*SimpleObject).valid = false
+	_ = pool.ReleaseObject(obj)
+
+	select {
+	case got := <-done:
+		if got.ID() == obj.ID() {
+			t.Errorf("等待者不应拿到失效的对象")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("对象被丢弃后等待者未获得新对象")
+	}
+}
+
+func TestPriorityInvalid(t *testing.T) {
+	pool, _ := newPriorityTestPool(t, time.Hour)
+	if _, err := pool.AcquireWithPriority(Priority(42), time.Millisecond); err == nil {
+		t.Error("非法优先级应返回错误")
+	}
+}
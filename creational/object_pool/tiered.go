@@ -0,0 +1,148 @@
+package object_pool
+
+// Tier 表示对象所属的层级
+type Tier int
+
+const (
+	// TierHot 热层：预热并缓存的对象，最多 MaxIdle 个，归还后放回空闲队列重用
+	TierHot Tier = iota
+	// TierOverflow 溢出层：热层用完时临时创建的对象，归还后直接销毁
+	TierOverflow
+
+	numTiers = 2
+)
+
+// String 返回层级名称
+func (t Tier) String() string {
+	switch t {
+	case TierHot:
+		return "hot"
+	case TierOverflow:
+		return "overflow"
+	default:
+		return "unknown"
+	}
+}
+
+// TierStats 记录单个层级的统计信息
+type TierStats struct {
+	// Live 是该层级当前存在的对象数量
+	Live int
+
+	// Active 是该层级当前被借出的对象数量
+	Active int
+
+	// Peak 是该层级同时存在的对象数量的峰值
+	Peak int
+
+	// Created 是该层级创建的对象总数
+	Created int
+
+	// Acquired 是从该层级获取对象的总次数
+	Acquired int
+
+	// Released 是归还到该层级的对象总数
+	Released int
+
+	// Destroyed 是该层级销毁的对象总数，溢出层的对象在归还时销毁
+	Destroyed int
+}
+
+// Tiered 返回池是否工作在分层模式
+func (p *ObjectPool) Tiered() bool {
+	return p.config.MaxBurst > 0
+}
+
+// TierStats 返回各层级的统计信息，非分层模式下所有对象都属于热层
+func (p *ObjectPool) TierStats() map[Tier]TierStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make(map[Tier]TierStats, numTiers)
+	for tier := Tier(0); tier < numTiers; tier++ {
+		stats[tier] = p.tiers[tier]
+	}
+	for _, info := range p.objects {
+		s := stats[info.tier]
+		if info.active {
+			s.Active++
+		}
+		stats[info.tier] = s
+	}
+	return stats
+}
+
+// normalizeTiers 规范分层模式的配置：热层容量为 MaxIdle，池的总容量为 MaxBurst
+func normalizeTiers(config *PoolConfig) {
+	if config.MaxBurst <= 0 {
+		return
+	}
+	if config.MaxIdle <= 0 || config.MaxIdle > config.MaxSize {
+		config.MaxIdle = config.MaxSize
+	}
+	if config.MaxBurst < config.MaxIdle {
+		config.MaxBurst = config.MaxIdle
+	}
+	config.MaxSize = config.MaxBurst
+	// 只有热层的对象会被预热
+	if config.InitialSize > config.MaxIdle {
+		config.InitialSize = config.MaxIdle
+	}
+}
+
+// trackLocked 登记新创建的对象并决定其层级，调用方需持有锁
+// 分层模式下热层未满时对象属于热层，否则属于溢出层
+func (p *ObjectPool) trackLocked(obj Object, active bool) {
+	tier := TierHot
+	if p.Tiered() && p.tiers[TierHot].Live >= p.config.MaxIdle {
+		tier = TierOverflow
+	}
+
+	p.objects[obj.ID()] = poolObject{obj: obj, active: active, tier: tier}
+	p.stats.Created++
+
+	s := &p.tiers[tier]
+	s.Created++
+	s.Live++
+	if s.Live > s.Peak {
+		s.Peak = s.Live
+	}
+	if active {
+		p.activeCount++
+		p.stats.Acquired++
+		s.Acquired++
+	}
+}
+
+// untrackLocked 移除被销毁的对象，调用方需持有锁
+func (p *ObjectPool) untrackLocked(obj Object) {
+	info, exists := p.objects[obj.ID()]
+	delete(p.objects, obj.ID())
+	delete(p.lastReturn, obj.ID())
+	p.stats.Destroyed++
+
+	if exists {
+		s := &p.tiers[info.tier]
+		s.Live--
+		s.Destroyed++
+	}
+}
+
+// releaseOverflow 处理归还的溢出层对象：优先交给排队中的请求，否则直接销毁而不放回空闲队列
+func (p *ObjectPool) releaseOverflow(obj Object) error {
+	reusable := obj.Reset() == nil && obj.Validate()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrPoolClosed
+	}
+	if reusable && p.handOffLocked(obj) {
+		return nil
+	}
+	p.untrackLocked(obj)
+	// 无效的对象被销毁后，腾出的容量用于满足排队中的请求
+	p.replenishLocked()
+	return nil
+}
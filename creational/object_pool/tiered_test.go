@@ -0,0 +1,185 @@
+package object_pool
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTieredTestPool 创建热层 2 个对象、最多突发到 5 个对象的分层池
+func newTieredTestPool(t *testing.T) *ObjectPool {
+	t.Helper()
+
+	var nextID atomic.Int32
+	config := DefaultPoolConfig(func() (Object, error) {
+		return NewSimpleObject(int(nextID.Add(1))), nil
+	})
+	config.InitialSize = 2
+	config.MaxIdle = 2
+	config.MaxBurst = 5
+
+	pool, err := NewObjectPool(config)
+	if err != nil {
+		t.Fatalf("创建对象池失败: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// 测试热层用完后创建溢出层对象，总数不超过 MaxBurst
+func TestTieredPoolOverflow(t *testing.T) {
+	pool := newTieredTestPool(t)
+	if !pool.Tiered() {
+		t.Fatal("设置 MaxBurst 后应工作在分层模式")
+	}
+
+	objs := make([]Object, 0, 5)
+	for i := 0; i < 5; i++ {
+		obj, err := pool.AcquireWithTimeout(100 * time.Millisecond)
+		if err != nil {
+			t.Fatalf("第 %d 次获取对象失败: %v", i+1, err)
+		}
+		objs = append(objs, obj)
+	}
+
+	stats := pool.TierStats()
+	hot, overflow := stats[TierHot], stats[TierOverflow]
+	if hot.Live != 2 || hot.Active != 2 || hot.Created != 2 {
+		t.Errorf("热层应有 2 个被借出的预热对象: %+v", hot)
+	}
+	if overflow.Live != 3 || overflow.Active != 3 || overflow.Created != 3 || overflow.Peak != 3 {
+		t.Errorf("溢出层应临时创建 3 个对象: %+v", overflow)
+	}
+
+	if _, err := pool.AcquireWithTimeout(50 * time.Millisecond); !errors.Is(err, ErrPoolTimeout) {
+		t.Errorf("超过 MaxBurst 后应超时，得到 %v", err)
+	}
+
+	for _, obj := range objs {
+		if err := pool.ReleaseObject(obj); err != nil {
+			t.Fatalf("归还对象失败: %v", err)
+		}
+	}
+}
+
+// 测试溢出层对象归还时被销毁，热层对象归还后放回空闲队列
+func TestTieredPoolRelease(t *testing.T) {
+	pool := newTieredTestPool(t)
+
+	objs := make([]Object, 0, 4)
+	for i := 0; i < 4; i++ {
+		obj, err := pool.AcquireObject()
+		if err != nil {
+			t.Fatalf("获取对象失败: %v", err)
+		}
+		objs = append(objs, obj)
+	}
+
+	// 前两个对象来自热层，后两个来自溢出层
+	for _, obj := range objs[2:] {
+		if err := pool.ReleaseObject(obj); err != nil {
+			t.Fatalf("归还溢出层对象失败: %v", err)
+		}
+	}
+	if _, idle, total := pool.Status(); idle != 0 || total != 2 {
+		t.Errorf("溢出层对象不应被缓存: idle=%d total=%d", idle, total)
+	}
+	overflow := pool.TierStats()[TierOverflow]
+	if overflow.Live != 0 || overflow.Released != 2 || overflow.Destroyed != 2 || overflow.Peak != 2 {
+		t.Errorf("溢出层统计错误: %+v", overflow)
+	}
+
+	for _, obj := range objs[:2] {
+		if err := pool.ReleaseObject(obj); err != nil {
+			t.Fatalf("归还热层对象失败: %v", err)
+		}
+	}
+	if _, idle, total := pool.Status(); idle != 2 || total != 2 {
+		t.Errorf("热层对象应放回空闲队列: idle=%d total=%d", idle, total)
+	}
+	hot := pool.TierStats()[TierHot]
+	if hot.Live != 2 || hot.Released != 2 || hot.Destroyed != 0 {
+		t.Errorf("热层统计错误: %+v", hot)
+	}
+
+	// 热层对象被重用，不会创建新对象
+	obj, err := pool.AcquireObject()
+	if err != nil {
+		t.Fatalf("获取对象失败: %v", err)
+	}
+	if got := pool.TierStats()[TierHot]; got.Created != 2 || got.Acquired != 3 {
+		t.Errorf("应重用热层对象: %+v", got)
+	}
+	_ = pool.ReleaseObject(obj)
+}
+
+// 测试归还的溢出层对象优先交给排队中的请求
+func TestTieredPoolHandOff(t *testing.T) {
+	pool := newTieredTestPool(t)
+
+	objs := make([]Object, 0, 5)
+	for i := 0; i < 5; i++ {
+		obj, err := pool.AcquireObject()
+		if err != nil {
+			t.Fatalf("获取对象失败: %v", err)
+		}
+		objs = append(objs, obj)
+	}
+
+	got := make(chan Object, 1)
+	go func() {
+		obj, err := pool.AcquireWithPriority(PriorityHigh, 2*time.Second)
+		if err != nil {
+			got <- nil
+			return
+		}
+		got <- obj
+	}()
+	deadline := time.Now().Add(time.Second)
+	for pool.Waiting(PriorityHigh) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := pool.ReleaseObject(objs[4]); err != nil {
+		t.Fatalf("归还对象失败: %v", err)
+	}
+	obj := <-got
+	if obj == nil || obj.ID() != objs[4].ID() {
+		t.Fatalf("排队中的请求应直接拿到归还的溢出层对象")
+	}
+	if overflow := pool.TierStats()[TierOverflow]; overflow.Destroyed != 0 || overflow.Acquired != 4 {
+		t.Errorf("交接的对象不应被销毁: %+v", overflow)
+	}
+}
+
+// 测试未设置 MaxBurst 时所有对象都属于热层
+func TestTierStatsWithoutTiering(t *testing.T) {
+	config := DefaultPoolConfig(SimpleObjectFactory)
+	config.InitialSize = 1
+	config.MaxSize = 3
+	config.MaxIdle = 1
+
+	pool, err := NewObjectPool(config)
+	if err != nil {
+		t.Fatalf("创建对象池失败: %v", err)
+	}
+	defer pool.Close()
+
+	if pool.Tiered() {
+		t.Error("未设置 MaxBurst 时不应工作在分层模式")
+	}
+	a, _ := pool.AcquireObject()
+	b, _ := pool.AcquireObject()
+
+	stats := pool.TierStats()
+	if stats[TierHot].Live != 2 || stats[TierHot].Active != 2 || stats[TierOverflow] != (TierStats{}) {
+		t.Errorf("所有对象应属于热层: %+v", stats)
+	}
+	_ = pool.ReleaseObject(a)
+	_ = pool.ReleaseObject(b)
+
+	if TierHot.String() != "hot" || TierOverflow.String() != "overflow" || Tier(9).String() != "unknown" {
+		t.Error("层级名称错误")
+	}
+}
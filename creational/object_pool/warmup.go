@@ -0,0 +1,200 @@
+package object_pool
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultWarmupConcurrency 未配置时异步预热使用的并行度
+const defaultWarmupConcurrency = 4
+
+// WarmupResult 记录一次预热的结果
+type WarmupResult struct {
+	// Created 是成功预创建的对象数量
+	Created int
+
+	// Failed 是创建失败的对象数量
+	Failed int
+
+	// Err 是预热过程中遇到的第一个错误
+	Err error
+
+	// Duration 是预热耗时
+	Duration time.Duration
+}
+
+// warmupState 保存预热过程的状态
+type warmupState struct {
+	// 预热完成时关闭
+	ready chan struct{}
+
+	// 预热对象数达到 MinWarmObjects 或预热结束时关闭
+	minReached chan struct{}
+	minOnce    sync.Once
+
+	// 已预热的对象数量
+	warmed int
+
+	// 预热结果，ready 关闭后有效
+	result WarmupResult
+}
+
+// newWarmupState 创建预热状态
+func newWarmupState() warmupState {
+	return warmupState{
+		ready:      make(chan struct{}),
+		minReached: make(chan struct{}),
+	}
+}
+
+// warmUp 以有限的并行度异步创建 InitialSize 个对象
+func (p *ObjectPool) warmUp() {
+	start := time.Now()
+
+	concurrency := p.config.WarmupConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultWarmupConcurrency
+	}
+	if concurrency > p.config.InitialSize {
+		concurrency = p.config.InitialSize
+	}
+
+	jobs := make(chan struct{}, p.config.InitialSize)
+	for i := 0; i < p.config.InitialSize; i++ {
+		jobs <- struct{}{}
+	}
+	close(jobs)
+
+	var resultMu sync.Mutex
+	var result WarmupResult
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range jobs {
+				obj, err := p.config.Factory()
+				added := err == nil && p.addWarmObject(obj)
+
+				resultMu.Lock()
+				switch {
+				case err != nil:
+					result.Failed++
+					if result.Err == nil {
+						result.Err = err
+					}
+				case added:
+					result.Created++
+				}
+				resultMu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	result.Duration = time.Since(start)
+	p.finishWarmup(result)
+}
+
+// addWarmObject 将预热创建的对象放入空闲队列
+func (p *ObjectPool) addWarmObject(obj Object) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// 池已关闭或已被按需创建的对象占满时放弃该对象，分层模式下只预热热层
+	if p.closed || len(p.objects) >= p.config.MaxSize ||
+		(p.Tiered() && p.tiers[TierHot].Live >= p.config.MaxIdle) {
+		return false
+	}
+
+	p.trackLocked(obj, false)
+	if !p.handOffLocked(obj) {
+		select {
+		case p.idle <- obj:
+		default:
+			p.untrackLocked(obj)
+			return false
+		}
+	}
+
+	p.warmup.warmed++
+	if p.warmup.warmed >= p.config.MinWarmObjects {
+		p.warmup.minOnce.Do(func() { close(p.warmup.minReached) })
+	}
+	return true
+}
+
+// finishWarmup 记录预热结果并通知等待者
+func (p *ObjectPool) finishWarmup(result WarmupResult) {
+	p.mu.Lock()
+	p.warmup.result = result
+	p.warmup.warmed = result.Created
+	p.mu.Unlock()
+
+	// 预热结束后无论是否达到最少对象数都不再阻塞获取
+	p.warmup.minOnce.Do(func() { close(p.warmup.minReached) })
+	close(p.warmup.ready)
+
+	if p.config.OnWarmupComplete != nil {
+		p.config.OnWarmupComplete(result)
+	}
+}
+
+// waitWarm 等待预热达到最少对象数，超时返回 false
+func (p *ObjectPool) waitWarm(timeout time.Duration) bool {
+	if p.config.MinWarmObjects <= 0 {
+		return true
+	}
+
+	select {
+	case <-p.warmup.minReached:
+		return true
+	default:
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-p.warmup.minReached:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// Ready 返回一个在预热完成时关闭的通道
+func (p *ObjectPool) Ready() <-chan struct{} {
+	return p.warmup.ready
+}
+
+// WaitReady 阻塞直到预热完成或超时，返回是否已完成
+func (p *ObjectPool) WaitReady(timeout time.Duration) bool {
+	select {
+	case <-p.warmup.ready:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// WarmupResult 返回预热结果，预热完成前第二个返回值为 false
+func (p *ObjectPool) WarmupResult() (WarmupResult, bool) {
+	select {
+	case <-p.warmup.ready:
+	default:
+		return WarmupResult{}, false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.warmup.result, true
+}
+
+// WarmedCount 返回已预热完成的对象数量
+func (p *ObjectPool) WarmedCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.warmup.warmed
+}
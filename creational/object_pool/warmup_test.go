@@ -0,0 +1,209 @@
+package object_pool
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 创建并发安全且带有创建延迟的工厂
+func createSlowFactory(delay time.Duration, created *atomic.Int32) ObjectFactory {
+	var id atomic.Int32
+	return func() (Object, error) {
+		time.Sleep(delay)
+		if created != nil {
+			created.Add(1)
+		}
+		return NewSimpleObject(int(id.Add(1))), nil
+	}
+}
+
+// TestSyncWarmup 测试同步预热同样会报告就绪
+func TestSyncWarmup(t *testing.T) {
+	var result WarmupResult
+	config := DefaultPoolConfig(createSlowFactory(0, nil))
+	config.InitialSize = 3
+	config.OnWarmupComplete = func(r WarmupResult) { result = r }
+
+	pool, err := NewObjectPool(config)
+	if err != nil {
+		t.Fatalf("创建对象池失败: %v", err)
+	}
+	defer pool.Close()
+
+	select {
+	case <-pool.Ready():
+	default:
+		t.Fatal("同步预热结束后应立即就绪")
+	}
+	if result.Created != 3 {
+		t.Errorf("回调应报告创建了3个对象，实际为%d", result.Created)
+	}
+	if pool.WarmedCount() != 3 {
+		t.Errorf("期望预热对象数为3，实际为%d", pool.WarmedCount())
+	}
+}
+
+// TestAsyncWarmup 测试异步并行预热
+func TestAsyncWarmup(t *testing.T) {
+	var created atomic.Int32
+	callback := make(chan WarmupResult, 1)
+
+	config := DefaultPoolConfig(createSlowFactory(50*time.Millisecond, &created))
+	config.InitialSize = 8
+	config.MaxSize = 10
+	config.AsyncWarmup = true
+	config.WarmupConcurrency = 4
+	config.OnWarmupComplete = func(r WarmupResult) { callback <- r }
+
+	start := time.Now()
+	pool, err := NewObjectPool(config)
+	if err != nil {
+		t.Fatalf("创建对象池失败: %v", err)
+	}
+	defer pool.Close()
+
+	if time.Since(start) > 20*time.Millisecond {
+		t.Error("异步预热时 NewObjectPool 应立即返回")
+	}
+	if _, done := pool.WarmupResult(); done {
+		t.Error("预热完成前不应返回结果")
+	}
+
+	if !pool.WaitReady(time.Second) {
+		t.Fatal("预热应在超时前完成")
+	}
+	elapsed := time.Since(start)
+
+	// 8个对象、并行度4、每个50ms，约需100ms；串行需要400ms
+	if elapsed > 300*time.Millisecond {
+		t.Errorf("并行预热耗时过长: %v", elapsed)
+	}
+
+	result := <-callback
+	if result.Created != 8 || result.Failed != 0 {
+		t.Errorf("预热结果不正确: %+v", result)
+	}
+	if stored, done := pool.WarmupResult(); !done || stored.Created != 8 {
+		t.Errorf("WarmupResult 返回不正确: %+v, %v", stored, done)
+	}
+
+	_, idle, total := pool.Status()
+	if idle != 8 || total != 8 {
+		t.Errorf("期望8个空闲对象，实际空闲%d，总数%d", idle, total)
+	}
+}
+
+// TestMinWarmObjects 测试获取对象前等待最少预热数量
+func TestMinWarmObjects(t *testing.T) {
+	var created atomic.Int32
+
+	config := DefaultPoolConfig(createSlowFactory(30*time.Millisecond, &created))
+	config.InitialSize = 6
+	config.MaxSize = 10
+	config.AsyncWarmup = true
+	config.WarmupConcurrency = 1
+	config.MinWarmObjects = 2
+
+	pool, err := NewObjectPool(config)
+	if err != nil {
+		t.Fatalf("创建对象池失败: %v", err)
+	}
+	defer pool.Close()
+
+	obj, err := pool.AcquireWithTimeout(time.Second)
+	if err != nil {
+		t.Fatalf("获取对象失败: %v", err)
+	}
+	if created.Load() < 2 {
+		t.Errorf("获取对象前应至少预热2个对象，实际为%d", created.Load())
+	}
+	pool.ReleaseObject(obj)
+
+	t.Run("等待超时", func(t *testing.T) {
+		config := DefaultPoolConfig(createSlowFactory(200*time.Millisecond, nil))
+		config.InitialSize = 4
+		config.AsyncWarmup = true
+		config.WarmupConcurrency = 1
+		config.MinWarmObjects = 3
+
+		pool, err := NewObjectPool(config)
+		if err != nil {
+			t.Fatalf("创建对象池失败: %v", err)
+		}
+		defer pool.Close()
+
+		_, err = pool.AcquireWithTimeout(50 * time.Millisecond)
+		if err != ErrPoolTimeout {
+			t.Errorf("预热未达标时应超时，实际错误: %v", err)
+		}
+		if pool.Stats().Timeouts != 1 {
+			t.Errorf("期望超时次数为1，实际为%d", pool.Stats().Timeouts)
+		}
+	})
+}
+
+// TestAsyncWarmupFailures 测试预热过程中的创建失败
+func TestAsyncWarmupFailures(t *testing.T) {
+	var calls atomic.Int32
+	factory := func() (Object, error) {
+		n := calls.Add(1)
+		if n%2 == 0 {
+			return nil, errors.New("factory error")
+		}
+		return NewSimpleObject(int(n)), nil
+	}
+
+	config := DefaultPoolConfig(factory)
+	config.InitialSize = 6
+	config.AsyncWarmup = true
+	config.MinWarmObjects = 6
+
+	pool, err := NewObjectPool(config)
+	if err != nil {
+		t.Fatalf("异步预热时工厂错误不应导致创建失败: %v", err)
+	}
+	defer pool.Close()
+
+	if !pool.WaitReady(time.Second) {
+		t.Fatal("预热应在超时前结束")
+	}
+
+	result, _ := pool.WarmupResult()
+	if result.Created != 3 || result.Failed != 3 || result.Err == nil {
+		t.Errorf("预热结果不正确: %+v", result)
+	}
+
+	// 预热结束后即使未达到最少对象数也不再阻塞获取
+	obj, err := pool.AcquireWithTimeout(100 * time.Millisecond)
+	if err != nil {
+		t.Fatalf("预热结束后获取对象失败: %v", err)
+	}
+	pool.ReleaseObject(obj)
+}
+
+// TestCloseDuringWarmup 测试预热过程中关闭对象池
+func TestCloseDuringWarmup(t *testing.T) {
+	config := DefaultPoolConfig(createSlowFactory(20*time.Millisecond, nil))
+	config.InitialSize = 10
+	config.MaxSize = 10
+	config.AsyncWarmup = true
+	config.WarmupConcurrency = 2
+
+	pool, err := NewObjectPool(config)
+	if err != nil {
+		t.Fatalf("创建对象池失败: %v", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	pool.Close()
+
+	if !pool.WaitReady(time.Second) {
+		t.Fatal("关闭后预热协程应正常结束")
+	}
+	result, _ := pool.WarmupResult()
+	if result.Created >= 10 {
+		t.Errorf("关闭后不应继续向池中添加对象，实际添加了%d个", result.Created)
+	}
+}
@@ -0,0 +1,190 @@
+package prototype
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/XiaoluCoding626/go-design-pattern/creational/object_pool"
+)
+
+// ErrNotFromPool 表示归还的克隆不属于该克隆池
+var ErrNotFromPool = errors.New("克隆不属于该克隆池")
+
+// cloneIDs 为所有克隆池中的对象分配唯一ID
+var cloneIDs atomic.Int64
+
+// PooledShape 是从克隆池中借出的形状
+// 它实现了 object_pool.Object 接口，归还时会被重置为原型的状态
+type PooledShape struct {
+	id        int
+	shape     Shape
+	prototype Shape
+	pool      *ClonePool
+}
+
+// Shape 返回借出的形状，可以自由修改，归还后不应再使用
+func (p *PooledShape) Shape() Shape {
+	return p.shape
+}
+
+// Reset 将形状恢复为原型的状态
+// 内置形状直接在原对象上覆写字段，不产生新的内存分配；其他形状退化为重新深克隆
+func (p *PooledShape) Reset() error {
+	if !restoreShape(p.shape, p.prototype) {
+		p.shape = p.prototype.DeepClone()
+	}
+	return nil
+}
+
+// Validate 检查形状是否可以被重用
+func (p *PooledShape) Validate() bool {
+	return p.shape != nil
+}
+
+// ID 返回对象的唯一标识符
+func (p *PooledShape) ID() int {
+	return p.id
+}
+
+// ClonePool 结合了原型模式和对象池模式：
+// 根据注册的原型预先克隆出一批实例，借出后归还时重置为原型状态以便重用，
+// 从而避免频繁克隆带来的内存分配
+type ClonePool struct {
+	prototype Shape
+	pool      *object_pool.ObjectPool
+	timeout   time.Duration
+
+	mu     sync.Mutex
+	clones int
+}
+
+// NewClonePool 创建克隆池，预先从原型克隆 size 个实例，最多持有 maxSize 个实例
+// 原型会被深克隆保存，之后对传入原型的修改不会影响克隆池
+func NewClonePool(prototype Shape, size, maxSize int) (*ClonePool, error) {
+	if prototype == nil {
+		return nil, errors.New("原型不能为空")
+	}
+	if maxSize < size {
+		maxSize = size
+	}
+
+	cp := &ClonePool{
+		prototype: prototype.DeepClone(),
+		timeout:   time.Second,
+	}
+
+	config := object_pool.DefaultPoolConfig(cp.newClone)
+	config.InitialSize = size
+	config.MaxSize = maxSize
+	config.MaxIdle = maxSize
+
+	pool, err := object_pool.NewObjectPool(config)
+	if err != nil {
+		return nil, fmt.Errorf("创建克隆池失败: %w", err)
+	}
+	cp.pool = pool
+	return cp, nil
+}
+
+// NewClonePoolFromCache 使用原型管理器中注册的原型创建克隆池
+func NewClonePoolFromCache(cache *ShapeCache, id string, size, maxSize int) (*ClonePool, error) {
+	prototype := cache.Get(id)
+	if prototype == nil {
+		return nil, fmt.Errorf("原型 %s 不存在", id)
+	}
+	return NewClonePool(prototype, size, maxSize)
+}
+
+// newClone 是对象池使用的工厂函数，每次调用都会深克隆一次原型
+func (cp *ClonePool) newClone() (object_pool.Object, error) {
+	cp.mu.Lock()
+	cp.clones++
+	cp.mu.Unlock()
+
+	return &PooledShape{
+		id:        int(cloneIDs.Add(1)),
+		shape:     cp.prototype.DeepClone(),
+		prototype: cp.prototype,
+		pool:      cp,
+	}, nil
+}
+
+// SetTimeout 设置借出实例时的最长等待时间
+func (cp *ClonePool) SetTimeout(timeout time.Duration) {
+	cp.timeout = timeout
+}
+
+// Acquire 从克隆池中借出一个处于原型状态的实例
+func (cp *ClonePool) Acquire() (*PooledShape, error) {
+	obj, err := cp.pool.AcquireWithTimeout(cp.timeout)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*PooledShape), nil
+}
+
+// Release 归还实例，实例会被重置为原型状态
+func (cp *ClonePool) Release(shape *PooledShape) error {
+	if shape == nil || shape.pool != cp {
+		return ErrNotFromPool
+	}
+	return cp.pool.ReleaseObject(shape)
+}
+
+// Prototype 返回原型的深克隆
+func (cp *ClonePool) Prototype() Shape {
+	return cp.prototype.DeepClone()
+}
+
+// Clones 返回克隆池实际执行克隆的次数
+func (cp *ClonePool) Clones() int {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return cp.clones
+}
+
+// Stats 返回底层对象池的统计信息
+func (cp *ClonePool) Stats() object_pool.PoolStats {
+	return cp.pool.Stats()
+}
+
+// Close 关闭克隆池
+func (cp *ClonePool) Close() {
+	cp.pool.Close()
+}
+
+// restoreShape 将 dst 的字段覆写为 src 的值，不分配新的内存
+// 两者类型不同、不是内置形状或坐标指针被置空时返回 false
+func restoreShape(dst, src Shape) bool {
+	switch d := dst.(type) {
+	case *Circle:
+		s, ok := src.(*Circle)
+		if !ok || d.Center == nil {
+			return false
+		}
+		d.BaseShape = s.BaseShape
+		d.Radius = s.Radius
+		*d.Center = *s.Center
+	case *Rectangle:
+		s, ok := src.(*Rectangle)
+		if !ok || d.Position == nil {
+			return false
+		}
+		d.BaseShape = s.BaseShape
+		d.Width, d.Height = s.Width, s.Height
+		*d.Position = *s.Position
+	case *Triangle:
+		s, ok := src.(*Triangle)
+		if !ok || d.A == nil || d.B == nil || d.C == nil {
+			return false
+		}
+		d.BaseShape = s.BaseShape
+		*d.A, *d.B, *d.C = *s.A, *s.B, *s.C
+	default:
+		return false
+	}
+	return true
+}
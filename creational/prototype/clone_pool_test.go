@@ -0,0 +1,188 @@
+package prototype
+
+import (
+	"sync"
+	"testing"
+)
+
+// 测试克隆池预先克隆实例并在归还时重置为原型状态
+func TestClonePoolAcquireRelease(t *testing.T) {
+	prototype := NewCircle(10, 5, 5)
+	pool, err := NewClonePool(prototype, 3, 5)
+	if err != nil {
+		t.Fatalf("创建克隆池失败: %v", err)
+	}
+	defer pool.Close()
+
+	if pool.Clones() != 3 {
+		t.Errorf("期望预先克隆3个实例，实际为%d", pool.Clones())
+	}
+
+	// 修改传入的原型不影响克隆池
+	prototype.Radius = 99
+
+	pooled, err := pool.Acquire()
+	if err != nil {
+		t.Fatalf("借出实例失败: %v", err)
+	}
+	circle := pooled.Shape().(*Circle)
+	if circle.Radius != 10 {
+		t.Errorf("借出的实例应处于原型状态，实际半径为%.1f", circle.Radius)
+	}
+
+	// 借出后随意修改
+	circle.Radius = 50
+	circle.SetColor(Black)
+	circle.Center.X = 100
+
+	if err := pool.Release(pooled); err != nil {
+		t.Fatalf("归还实例失败: %v", err)
+	}
+	if circle.Radius != 10 || circle.GetColor() != Blue || circle.Center.X != 5 {
+		t.Errorf("归还后实例应被重置为原型状态: %v", circle)
+	}
+	if pool.Clones() != 3 {
+		t.Errorf("重用实例不应产生新的克隆，实际克隆次数为%d", pool.Clones())
+	}
+	if pool.Prototype().(*Circle).Center == circle.Center {
+		t.Error("池中实例不应与原型共享坐标指针")
+	}
+}
+
+// 测试各种内置形状以及坐标指针被置空时的重置
+func TestClonePoolResetShapes(t *testing.T) {
+	shapes := []Shape{
+		NewRectangle(20, 10, 1, 2),
+		NewTriangle(0, 0, 10, 0, 5, 10),
+	}
+
+	for _, prototype := range shapes {
+		pool, err := NewClonePool(prototype, 1, 1)
+		if err != nil {
+			t.Fatalf("创建克隆池失败: %v", err)
+		}
+
+		pooled, _ := pool.Acquire()
+		switch s := pooled.Shape().(type) {
+		case *Rectangle:
+			s.Width = 0
+			s.Position = nil
+		case *Triangle:
+			s.A.X = 42
+		}
+		pool.Release(pooled)
+
+		if pooled.Shape().String() != prototype.String() {
+			t.Errorf("重置后的形状应与原型一致: %v != %v", pooled.Shape(), prototype)
+		}
+		pool.Close()
+	}
+}
+
+// 测试归还不属于该池的实例
+func TestClonePoolReleaseForeign(t *testing.T) {
+	poolA, _ := NewClonePool(NewCircle(1, 0, 0), 1, 1)
+	poolB, _ := NewClonePool(NewCircle(1, 0, 0), 1, 1)
+	defer poolA.Close()
+	defer poolB.Close()
+
+	pooled, _ := poolA.Acquire()
+	if err := poolB.Release(pooled); err != ErrNotFromPool {
+		t.Errorf("归还到其他池应返回 ErrNotFromPool，实际为: %v", err)
+	}
+	if err := poolA.Release(nil); err != ErrNotFromPool {
+		t.Errorf("归还 nil 应返回 ErrNotFromPool，实际为: %v", err)
+	}
+	if _, err := NewClonePool(nil, 1, 1); err == nil {
+		t.Error("原型为空时应返回错误")
+	}
+}
+
+// 测试从原型管理器创建克隆池
+func TestClonePoolFromCache(t *testing.T) {
+	cache := NewShapeCache()
+	cache.LoadCache()
+
+	pool, err := NewClonePoolFromCache(cache, "redCircle", 2, 4)
+	if err != nil {
+		t.Fatalf("创建克隆池失败: %v", err)
+	}
+	defer pool.Close()
+
+	pooled, _ := pool.Acquire()
+	if pooled.Shape().GetColor() != Red {
+		t.Errorf("期望借出红色圆形，实际为%v", pooled.Shape())
+	}
+
+	if _, err := NewClonePoolFromCache(cache, "hexagon", 1, 1); err == nil {
+		t.Error("原型不存在时应返回错误")
+	}
+}
+
+// 测试并发借出和归还
+func TestClonePoolConcurrent(t *testing.T) {
+	pool, err := NewClonePool(NewTriangle(0, 0, 3, 0, 0, 4), 4, 4)
+	if err != nil {
+		t.Fatalf("创建克隆池失败: %v", err)
+	}
+	defer pool.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				pooled, err := pool.Acquire()
+				if err != nil {
+					t.Errorf("借出实例失败: %v", err)
+					return
+				}
+				triangle := pooled.Shape().(*Triangle)
+				if triangle.GetArea() < 5.99 || triangle.GetArea() > 6.01 {
+					t.Errorf("借出的实例状态被污染: %v", triangle)
+				}
+				triangle.C.Y = float64(i + j)
+				pool.Release(pooled)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if pool.Clones() != 4 {
+		t.Errorf("并发重用不应产生额外克隆，实际克隆次数为%d", pool.Clones())
+	}
+}
+
+// benchmarkSink 防止编译器优化掉基准测试中的克隆
+var benchmarkSink Shape
+
+// 每次都深克隆原型的基准测试
+func BenchmarkDeepCloneTriangle(b *testing.B) {
+	prototype := NewTriangle(0, 0, 10, 0, 5, 10)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		shape := prototype.DeepClone().(*Triangle)
+		shape.A.X = float64(i)
+		benchmarkSink = shape
+	}
+}
+
+// 从克隆池借出并归还的基准测试
+func BenchmarkClonePoolTriangle(b *testing.B) {
+	pool, err := NewClonePool(NewTriangle(0, 0, 10, 0, 5, 10), 1, 1)
+	if err != nil {
+		b.Fatalf("创建克隆池失败: %v", err)
+	}
+	defer pool.Close()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pooled, _ := pool.Acquire()
+		shape := pooled.Shape().(*Triangle)
+		shape.A.X = float64(i)
+		benchmarkSink = shape
+		pool.Release(pooled)
+	}
+}
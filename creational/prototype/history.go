@@ -0,0 +1,95 @@
+package prototype
+
+import (
+	"fmt"
+
+	"github.com/XiaoluCoding626/go-design-pattern/behavioral/history"
+)
+
+// ShapeEditor 支持撤销和重做的形状编辑器
+//
+// Shape 实现了 DeepClone，历史管理器保存的每个快照都是一份深克隆，
+// 编辑器交出去的形状也是克隆，调用方修改它不会影响编辑历史。
+type ShapeEditor struct {
+	history *history.HistoryManager[Shape]
+}
+
+// NewShapeEditor 以 shape 的克隆为初始状态创建编辑器，最多保留 capacity 步历史，0 表示不限制
+func NewShapeEditor(shape Shape, capacity int) *ShapeEditor {
+	return &ShapeEditor{history: history.New(shape, history.WithCapacity(capacity))}
+}
+
+// Shape 返回当前形状的克隆
+func (e *ShapeEditor) Shape() Shape {
+	return e.history.Current()
+}
+
+// Edit 在当前形状的克隆上应用变换，结果作为快照记入历史
+func (e *ShapeEditor) Edit(label string, mutators ...Mutator) Shape {
+	next := e.history.Current().CloneWith(mutators...)
+	e.history.Push(label, next)
+	return e.history.Current()
+}
+
+// Move 平移当前形状，只记录位移而不是完整的形状
+// 连续的平移在 Compact 时合并为一次
+func (e *ShapeEditor) Move(dx, dy float64) (Shape, error) {
+	return e.history.Apply(fmt.Sprintf("平移 (%g, %g)", dx, dy), translation{dx: dx, dy: dy})
+}
+
+// Undo 撤销上一步编辑
+func (e *ShapeEditor) Undo() (Shape, error) {
+	return e.history.Undo()
+}
+
+// Redo 重做上一步撤销的编辑
+func (e *ShapeEditor) Redo() (Shape, error) {
+	return e.history.Redo()
+}
+
+// Steps 返回可撤销的编辑名称，从旧到新
+func (e *ShapeEditor) Steps() []string {
+	entries := e.history.UndoEntries()
+	steps := make([]string, len(entries))
+	for i, entry := range entries {
+		steps[i] = entry.Label
+	}
+	return steps
+}
+
+// Compact 合并较旧的历史，只保留最近 keep 步的撤销粒度，返回减少的步数
+func (e *ShapeEditor) Compact(keep int) int {
+	return e.history.Compact(keep)
+}
+
+// translation 平移的增量：原地平移当前形状，撤销时反向平移
+type translation struct {
+	dx, dy float64
+}
+
+func (d translation) Apply(s Shape) (Shape, error) {
+	return d.translate(s, d.dx, d.dy)
+}
+
+func (d translation) Revert(s Shape) (Shape, error) {
+	return d.translate(s, -d.dx, -d.dy)
+}
+
+// translate 平移形状，不支持几何变换的形状返回错误
+func (d translation) translate(s Shape, dx, dy float64) (Shape, error) {
+	t, ok := s.(Transformable)
+	if !ok {
+		return s, fmt.Errorf("%s 不支持平移", s.GetType())
+	}
+	t.Translate(dx, dy)
+	return s, nil
+}
+
+// Merge 连续的平移合并为一次
+func (d translation) Merge(next history.Diff[Shape]) (history.Diff[Shape], bool) {
+	n, ok := next.(translation)
+	if !ok {
+		return nil, false
+	}
+	return translation{dx: d.dx + n.dx, dy: d.dy + n.dy}, true
+}
@@ -0,0 +1,84 @@
+package prototype
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/XiaoluCoding626/go-design-pattern/behavioral/history"
+)
+
+// 测试编辑器保存深克隆快照，撤销和重做恢复形状
+func TestShapeEditorUndoRedo(t *testing.T) {
+	original := NewCircle(10, 0, 0)
+	editor := NewShapeEditor(original, 0)
+	original.Radius = 99
+
+	editor.Edit("变红", WithColor(Red))
+	editor.Edit("放大", Scale(2))
+	if _, err := editor.Move(3, 4); err != nil {
+		t.Fatalf("平移失败: %v", err)
+	}
+
+	c := editor.Shape().(*Circle)
+	if c.Color != Red || c.Radius != 20 || c.Center.X != 3 || c.Center.Y != 4 {
+		t.Fatalf("编辑结果错误: %v", c)
+	}
+
+	// 修改交出去的形状不影响编辑器
+	c.Center.X = 100
+	if editor.Shape().(*Circle).Center.X != 3 {
+		t.Error("编辑器中的形状应与交出去的克隆互不影响")
+	}
+
+	want := []struct {
+		color  Color
+		radius float64
+		x      float64
+	}{{Red, 20, 0}, {Red, 10, 0}, {Blue, 10, 0}}
+	for i, w := range want {
+		s, err := editor.Undo()
+		if err != nil {
+			t.Fatalf("第 %d 次撤销失败: %v", i+1, err)
+		}
+		c := s.(*Circle)
+		if c.Color != w.color || c.Radius != w.radius || c.Center.X != w.x {
+			t.Errorf("第 %d 次撤销后形状错误: %v", i+1, c)
+		}
+	}
+	if _, err := editor.Undo(); !errors.Is(err, history.ErrNothingToUndo) {
+		t.Errorf("历史为空时应返回 ErrNothingToUndo，实际为 %v", err)
+	}
+
+	for range want {
+		if _, err := editor.Redo(); err != nil {
+			t.Fatalf("重做失败: %v", err)
+		}
+	}
+	if c := editor.Shape().(*Circle); c.Radius != 20 || c.Center.Y != 4 {
+		t.Errorf("重做后形状错误: %v", c)
+	}
+}
+
+// 测试连续平移在压缩时合并为一步
+func TestShapeEditorCompact(t *testing.T) {
+	editor := NewShapeEditor(NewRectangle(2, 3, 0, 0), 10)
+	editor.Move(1, 0)
+	editor.Move(1, 1)
+	editor.Move(0, 1)
+	editor.Edit("变黄", WithColor(Yellow))
+
+	if removed := editor.Compact(1); removed != 2 {
+		t.Errorf("应减少 2 步，实际为 %d", removed)
+	}
+	if steps := editor.Steps(); !reflect.DeepEqual(steps, []string{"平移 (0, 1)", "变黄"}) {
+		t.Errorf("压缩后的步骤错误: %v", steps)
+	}
+
+	editor.Undo()
+	s, _ := editor.Undo()
+	r := s.(*Rectangle)
+	if r.Position.X != 0 || r.Position.Y != 0 {
+		t.Errorf("一次撤销应回到平移之前，实际为 %v", r.Position)
+	}
+}
@@ -21,13 +21,14 @@ const (
 
 // Shape 接口定义了克隆方法和其他公共方法
 type Shape interface {
-	Clone() Shape         // 浅克隆
-	DeepClone() Shape     // 深克隆
-	GetType() string      // 获取形状类型
-	GetColor() Color      // 获取颜色
-	SetColor(color Color) // 设置颜色
-	GetArea() float64     // 计算面积
-	String() string       // 字符串表示
+	Clone() Shape                        // 浅克隆
+	DeepClone() Shape                    // 深克隆
+	CloneWith(mutators ...Mutator) Shape // 深克隆并在克隆上应用变换
+	GetType() string                     // 获取形状类型
+	GetColor() Color                     // 获取颜色
+	SetColor(color Color)                // 设置颜色
+	GetArea() float64                    // 计算面积
+	String() string                      // 字符串表示
 }
 
 // BaseShape 包含所有形状共有的属性
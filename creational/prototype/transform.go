@@ -0,0 +1,119 @@
+package prototype
+
+// Mutator 修改形状的函数，用于 CloneWith 在克隆上应用变换
+type Mutator func(Shape)
+
+// Transformable 支持几何变换的形状，内置的三种形状都实现了该接口
+type Transformable interface {
+	Translate(dx, dy float64) // 平移
+	Scale(factor float64)    // 以锚点为中心缩放
+}
+
+// cloneWith 深克隆形状并依次应用变换
+// 变换只作用在尚未交给调用方的克隆上，调用方拿到的要么是完成全部变换的克隆，
+// 要么（某个变换 panic 时）什么也拿不到，原型始终不受影响
+func cloneWith(shape Shape, mutators []Mutator) Shape {
+	clone := shape.DeepClone()
+	for _, mutate := range mutators {
+		if mutate != nil {
+			mutate(clone)
+		}
+	}
+	return clone
+}
+
+// CloneWith 深克隆圆形并应用变换
+func (c *Circle) CloneWith(mutators ...Mutator) Shape {
+	return cloneWith(c, mutators)
+}
+
+// CloneWith 深克隆矩形并应用变换
+func (r *Rectangle) CloneWith(mutators ...Mutator) Shape {
+	return cloneWith(r, mutators)
+}
+
+// CloneWith 深克隆三角形并应用变换
+func (t *Triangle) CloneWith(mutators ...Mutator) Shape {
+	return cloneWith(t, mutators)
+}
+
+// WithColor 返回设置颜色的变换
+func WithColor(color Color) Mutator {
+	return func(s Shape) {
+		s.SetColor(color)
+	}
+}
+
+// Translate 返回平移的变换，不支持几何变换的形状保持不变
+func Translate(dx, dy float64) Mutator {
+	return func(s Shape) {
+		if t, ok := s.(Transformable); ok {
+			t.Translate(dx, dy)
+		}
+	}
+}
+
+// Scale 返回缩放的变换，factor 必须为正数，否则变换不生效
+func Scale(factor float64) Mutator {
+	return func(s Shape) {
+		if factor <= 0 {
+			return
+		}
+		if t, ok := s.(Transformable); ok {
+			t.Scale(factor)
+		}
+	}
+}
+
+// Translate 平移圆心
+func (c *Circle) Translate(dx, dy float64) {
+	c.Center.X += dx
+	c.Center.Y += dy
+}
+
+// Scale 以圆心为锚点缩放半径
+func (c *Circle) Scale(factor float64) {
+	c.Radius *= factor
+}
+
+// Translate 平移矩形位置
+func (r *Rectangle) Translate(dx, dy float64) {
+	r.Position.X += dx
+	r.Position.Y += dy
+}
+
+// Scale 以位置点为锚点缩放宽和高
+func (r *Rectangle) Scale(factor float64) {
+	r.Width *= factor
+	r.Height *= factor
+}
+
+// Translate 平移三个顶点
+func (t *Triangle) Translate(dx, dy float64) {
+	for _, p := range []*Point{t.A, t.B, t.C} {
+		p.X += dx
+		p.Y += dy
+	}
+}
+
+// Scale 以重心为锚点缩放三个顶点
+func (t *Triangle) Scale(factor float64) {
+	cx := (t.A.X + t.B.X + t.C.X) / 3
+	cy := (t.A.Y + t.B.Y + t.C.Y) / 3
+	for _, p := range []*Point{t.A, t.B, t.C} {
+		p.X = cx + (p.X-cx)*factor
+		p.Y = cy + (p.Y-cy)*factor
+	}
+}
+
+// GetWith 获取形状的克隆并应用变换，形状不存在时返回 nil
+func (sc *ShapeCache) GetWith(id string, mutators ...Mutator) Shape {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+
+	shape, ok := sc.shapes[id]
+	if !ok {
+		return nil
+	}
+	return shape.CloneWith(mutators...)
+}
@@ -0,0 +1,124 @@
+package prototype
+
+import "testing"
+
+// 测试克隆时应用颜色、平移和缩放变换
+func TestCloneWith(t *testing.T) {
+	original := NewCircle(10, 5, 5)
+
+	clone := original.CloneWith(WithColor(Red), Translate(3, -2), Scale(2))
+	circle, ok := clone.(*Circle)
+	if !ok {
+		t.Fatalf("CloneWith 应返回 *Circle，实际为 %T", clone)
+	}
+
+	if circle.Color != Red || circle.Radius != 20 || circle.Center.X != 8 || circle.Center.Y != 3 {
+		t.Errorf("变换结果错误: %v", circle)
+	}
+
+	// 原型不受影响，且不共享中心点
+	if original.Color != Blue || original.Radius != 10 || original.Center.X != 5 || original.Center.Y != 5 {
+		t.Errorf("原型被修改: %v", original)
+	}
+	if circle.Center == original.Center {
+		t.Error("CloneWith 应进行深克隆")
+	}
+
+	// 不带变换时等同于深克隆
+	plain := original.CloneWith()
+	if plain.String() != original.String() {
+		t.Errorf("无变换的克隆应与原型一致: %v", plain)
+	}
+}
+
+// 测试各形状的几何变换
+func TestShapeTransforms(t *testing.T) {
+	rectangle := NewRectangle(4, 2, 1, 1).CloneWith(Scale(1.5), Translate(1, 2)).(*Rectangle)
+	if rectangle.Width != 6 || rectangle.Height != 3 || rectangle.Position.X != 2 || rectangle.Position.Y != 3 {
+		t.Errorf("矩形变换结果错误: %v", rectangle)
+	}
+
+	original := NewTriangle(0, 0, 6, 0, 0, 6)
+	triangle := original.CloneWith(Scale(2)).(*Triangle)
+	// 以重心 (2,2) 为锚点缩放
+	if triangle.A.X != -2 || triangle.A.Y != -2 || triangle.B.X != 10 || triangle.C.Y != 10 {
+		t.Errorf("三角形缩放结果错误: %v", triangle)
+	}
+	if ratio := triangle.GetArea() / original.GetArea(); ratio < 3.99 || ratio > 4.01 {
+		t.Errorf("边长放大2倍面积应放大4倍，实际为%.2f倍", ratio)
+	}
+
+	moved := original.CloneWith(Translate(1, 1)).(*Triangle)
+	if moved.A.X != 1 || moved.B.X != 7 || moved.C.Y != 7 {
+		t.Errorf("三角形平移结果错误: %v", moved)
+	}
+	if original.A.X != 0 {
+		t.Error("原型被修改")
+	}
+}
+
+// 测试无效的变换参数和自定义变换
+func TestCloneWithCustomMutators(t *testing.T) {
+	original := NewCircle(10, 0, 0)
+
+	// 非正的缩放系数和 nil 变换被忽略
+	clone := original.CloneWith(Scale(0), Scale(-1), nil).(*Circle)
+	if clone.Radius != 10 {
+		t.Errorf("无效的缩放应被忽略，实际半径为%.2f", clone.Radius)
+	}
+
+	// 自定义变换可以直接写成 func(Shape)
+	var seen Shape
+	clone = original.CloneWith(func(s Shape) {
+		seen = s
+		s.(*Circle).Radius = 1
+	}).(*Circle)
+	if seen != Shape(clone) {
+		t.Error("变换应作用在返回的克隆上")
+	}
+	if clone.Radius != 1 || original.Radius != 10 {
+		t.Errorf("自定义变换结果错误: 克隆=%v, 原型=%v", clone, original)
+	}
+}
+
+// 测试变换失败时原型不受影响
+func TestCloneWithPanicLeavesPrototypeIntact(t *testing.T) {
+	original := NewRectangle(4, 2, 0, 0)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("应向调用方传播 panic")
+			}
+		}()
+		original.CloneWith(Translate(5, 5), func(Shape) { panic("变换失败") })
+	}()
+
+	if original.Position.X != 0 || original.Position.Y != 0 {
+		t.Errorf("变换失败不应影响原型: %v", original)
+	}
+}
+
+// 测试从原型管理器获取变换后的克隆
+func TestShapeCacheGetWith(t *testing.T) {
+	cache := NewShapeCache()
+	cache.LoadCache()
+
+	shape := cache.GetWith("circle", WithColor(Yellow), Scale(0.5))
+	circle, ok := shape.(*Circle)
+	if !ok {
+		t.Fatalf("应返回 *Circle，实际为 %T", shape)
+	}
+	if circle.Color != Yellow || circle.Radius != 5 {
+		t.Errorf("变换结果错误: %v", circle)
+	}
+
+	// 缓存中的原型不变
+	if cached := cache.Get("circle").(*Circle); cached.Color != Blue || cached.Radius != 10 {
+		t.Errorf("原型被修改: %v", cached)
+	}
+
+	if cache.GetWith("unknown", WithColor(Red)) != nil {
+		t.Error("不存在的形状应返回 nil")
+	}
+}
@@ -0,0 +1,232 @@
+package bulkhead
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/XiaoluCoding626/go-design-pattern/synchronization/semaphore"
+)
+
+// 舱壁相关错误
+var (
+	ErrBulkheadFull         = errors.New("舱壁已满")
+	ErrUnknownCompartment   = errors.New("舱室不存在")
+	ErrDuplicateCompartment = errors.New("舱室已存在")
+)
+
+// Config 舱室配置
+type Config struct {
+	MaxConcurrent int           // 同时执行的调用数上限，至少为 1
+	MaxQueue      int           // 执行名额用完时允许排队等待的调用数，0 表示不排队直接拒绝
+	MaxWait       time.Duration // 排队等待的最长时间，0 表示一直等到 ctx 结束
+}
+
+// Metrics 舱室的统计信息
+type Metrics struct {
+	Name      string
+	Active    int           // 正在执行的调用数
+	Queued    int           // 正在排队的调用数
+	Accepted  int           // 获得执行名额的调用数
+	Rejected  int           // 因舱室已满被拒绝的调用数，包括排队超时
+	Cancelled int           // 排队时 ctx 被取消的调用数
+	Failed    int           // 执行后返回错误的调用数
+	WaitTime  time.Duration // 获得执行名额前累计的排队时间
+}
+
+// Compartment 舱室：拥有独立并发上限和等待队列的隔离区
+//
+// 一个舱室的调用全部卡住时，只会耗尽它自己的名额和队列，
+// 其他舱室不受影响，就像船舱进水只淹没一个隔舱。
+type Compartment struct {
+	name   string
+	config Config
+	sem    *semaphore.Semaphore
+
+	mutex   sync.Mutex
+	metrics Metrics
+
+	now func() time.Time // 时间来源，便于测试
+}
+
+// NewCompartment 创建舱室
+func NewCompartment(name string, config Config) *Compartment {
+	if config.MaxConcurrent < 1 {
+		config.MaxConcurrent = 1
+	}
+	if config.MaxQueue < 0 {
+		config.MaxQueue = 0
+	}
+	return &Compartment{
+		name:    name,
+		config:  config,
+		sem:     semaphore.New(config.MaxConcurrent),
+		metrics: Metrics{Name: name},
+		now:     time.Now,
+	}
+}
+
+// Name 返回舱室名称
+func (c *Compartment) Name() string {
+	return c.name
+}
+
+// Execute 在舱室中执行 fn
+// 执行名额用完且队列已满时立即返回 ErrBulkheadFull；排队超过 MaxWait 同样返回 ErrBulkheadFull
+func (c *Compartment) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := c.acquire(ctx); err != nil {
+		return err
+	}
+	defer c.release()
+
+	err := fn(ctx)
+	if err != nil {
+		c.mutex.Lock()
+		c.metrics.Failed++
+		c.mutex.Unlock()
+	}
+	return err
+}
+
+// Metrics 返回统计信息
+func (c *Compartment) Metrics() Metrics {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.metrics
+}
+
+// acquire 获取执行名额，名额用完时排队
+func (c *Compartment) acquire(ctx context.Context) error {
+	if c.sem.TryAcquire() {
+		c.mutex.Lock()
+		c.admitLocked(0)
+		c.mutex.Unlock()
+		return nil
+	}
+
+	c.mutex.Lock()
+	if c.metrics.Queued >= c.config.MaxQueue {
+		c.metrics.Rejected++
+		c.mutex.Unlock()
+		return fmt.Errorf("%w: %s 有 %d 个调用在执行，%d 个在排队", ErrBulkheadFull, c.name, c.config.MaxConcurrent, c.config.MaxQueue)
+	}
+	c.metrics.Queued++
+	c.mutex.Unlock()
+
+	waitCtx := ctx
+	if c.config.MaxWait > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, c.config.MaxWait)
+		defer cancel()
+	}
+	start := c.now()
+	err := c.sem.Acquire(waitCtx)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.metrics.Queued--
+	switch {
+	case err == nil:
+		c.admitLocked(c.now().Sub(start))
+		return nil
+	case ctx.Err() != nil:
+		c.metrics.Cancelled++
+		return ctx.Err()
+	default:
+		c.metrics.Rejected++
+		return fmt.Errorf("%w: %s 排队超过 %v", ErrBulkheadFull, c.name, c.config.MaxWait)
+	}
+}
+
+// admitLocked 记录获得执行名额的调用，调用者需持有锁
+func (c *Compartment) admitLocked(wait time.Duration) {
+	c.metrics.Accepted++
+	c.metrics.Active++
+	c.metrics.WaitTime += wait
+}
+
+// release 归还执行名额
+func (c *Compartment) release() {
+	c.mutex.Lock()
+	c.metrics.Active--
+	c.mutex.Unlock()
+	c.sem.Release()
+}
+
+// Call 在舱室中执行有返回值的 fn
+func Call[T any](ctx context.Context, c *Compartment, fn func(ctx context.Context) (T, error)) (T, error) {
+	var result T
+	err := c.Execute(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = fn(ctx)
+		return err
+	})
+	return result, err
+}
+
+// Bulkhead 舱壁：按名称管理一组相互隔离的舱室
+type Bulkhead struct {
+	mutex        sync.RWMutex
+	compartments map[string]*Compartment
+}
+
+// New 创建舱壁
+func New() *Bulkhead {
+	return &Bulkhead{compartments: make(map[string]*Compartment)}
+}
+
+// Add 添加舱室
+func (b *Bulkhead) Add(name string, config Config) (*Compartment, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if _, exists := b.compartments[name]; exists {
+		return nil, fmt.Errorf("%w: %s", ErrDuplicateCompartment, name)
+	}
+	c := NewCompartment(name, config)
+	b.compartments[name] = c
+	return c, nil
+}
+
+// Compartment 按名称查找舱室
+func (b *Bulkhead) Compartment(name string) (*Compartment, error) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	c, exists := b.compartments[name]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCompartment, name)
+	}
+	return c, nil
+}
+
+// Execute 在指定的舱室中执行 fn
+func (b *Bulkhead) Execute(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	c, err := b.Compartment(name)
+	if err != nil {
+		return err
+	}
+	return c.Execute(ctx, fn)
+}
+
+// Metrics 返回所有舱室的统计信息，按名称排序
+func (b *Bulkhead) Metrics() []Metrics {
+	b.mutex.RLock()
+	compartments := make([]*Compartment, 0, len(b.compartments))
+	for _, c := range b.compartments {
+		compartments = append(compartments, c)
+	}
+	b.mutex.RUnlock()
+
+	metrics := make([]Metrics, 0, len(compartments))
+	for _, c := range compartments {
+		metrics = append(metrics, c.Metrics())
+	}
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].Name < metrics[j].Name })
+	return metrics
+}
@@ -0,0 +1,163 @@
+package bulkhead
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blockUntil 返回一直阻塞到 gate 关闭的任务
+func blockUntil(gate <-chan struct{}) func(context.Context) error {
+	return func(context.Context) error {
+		<-gate
+		return nil
+	}
+}
+
+// occupy 在后台占用舱室的名额，直到 gate 关闭
+func occupy(t *testing.T, c *Compartment, n int, gate <-chan struct{}) *sync.WaitGroup {
+	t.Helper()
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, c.Execute(context.Background(), blockUntil(gate)))
+		}()
+	}
+	assert.Eventually(t, func() bool { return c.Metrics().Active+c.Metrics().Queued == n }, time.Second, time.Millisecond)
+	return &wg
+}
+
+// TestRejectWhenFull 测试名额和队列都用完时立即拒绝
+func TestRejectWhenFull(t *testing.T) {
+	c := NewCompartment("reports", Config{MaxConcurrent: 2, MaxQueue: 1})
+	gate := make(chan struct{})
+	wg := occupy(t, c, 3, gate)
+
+	m := c.Metrics()
+	assert.Equal(t, 2, m.Active)
+	assert.Equal(t, 1, m.Queued)
+
+	start := time.Now()
+	err := c.Execute(context.Background(), blockUntil(gate))
+	assert.ErrorIs(t, err, ErrBulkheadFull)
+	assert.Less(t, time.Since(start), 50*time.Millisecond, "队列已满时不等待")
+
+	close(gate)
+	wg.Wait()
+	m = c.Metrics()
+	assert.Equal(t, Metrics{Name: "reports", Accepted: 3, Rejected: 1, WaitTime: m.WaitTime}, m)
+	assert.Greater(t, m.WaitTime, time.Duration(0), "排队的调用记录了等待时间")
+}
+
+// TestQueueTimeoutAndCancel 测试排队超时被拒绝，排队时 ctx 取消
+func TestQueueTimeoutAndCancel(t *testing.T) {
+	c := NewCompartment("payments", Config{MaxConcurrent: 1, MaxQueue: 1, MaxWait: 20 * time.Millisecond})
+	gate := make(chan struct{})
+	wg := occupy(t, c, 1, gate)
+
+	start := time.Now()
+	assert.ErrorIs(t, c.Execute(context.Background(), blockUntil(gate)), ErrBulkheadFull)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() { errs <- c.Execute(ctx, blockUntil(gate)) }()
+	assert.Eventually(t, func() bool { return c.Metrics().Queued == 1 }, time.Second, time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-errs, context.Canceled)
+
+	close(gate)
+	wg.Wait()
+	m := c.Metrics()
+	assert.Equal(t, 1, m.Accepted)
+	assert.Equal(t, 1, m.Rejected)
+	assert.Equal(t, 1, m.Cancelled)
+	assert.Zero(t, m.Queued)
+}
+
+// TestConcurrencyLimit 测试同时执行的调用数不超过上限
+func TestConcurrencyLimit(t *testing.T) {
+	c := NewCompartment("limited", Config{MaxConcurrent: 3, MaxQueue: 100})
+	var active, peak atomic.Int32
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, c.Execute(context.Background(), func(context.Context) error {
+				n := active.Add(1)
+				defer active.Add(-1)
+				for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+				}
+				time.Sleep(time.Millisecond)
+				return nil
+			}))
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+	assert.Equal(t, 50, c.Metrics().Accepted)
+}
+
+// TestIsolation 测试一个舱室被占满时其他舱室不受影响
+func TestIsolation(t *testing.T) {
+	service := NewPaymentService()
+	gate := make(chan struct{})
+	defer close(gate)
+
+	// 报表舱室被卡住的请求占满
+	occupy(t, service.reports, 4, gate)
+	_, err := service.Report(context.Background(), "2024-01")
+	assert.ErrorIs(t, err, ErrBulkheadFull)
+
+	// 支付照常处理
+	start := time.Now()
+	for i := 0; i < 8; i++ {
+		result, err := service.Pay(context.Background(), "order", 10)
+		assert.NoError(t, err)
+		assert.Contains(t, result, "成功")
+	}
+	assert.Less(t, time.Since(start), time.Second)
+
+	metrics := service.Metrics()
+	if assert.Len(t, metrics, 2) {
+		assert.Equal(t, "payments", metrics[0].Name)
+		assert.Equal(t, 8, metrics[0].Accepted)
+		assert.Zero(t, metrics[0].Rejected)
+		assert.Equal(t, "reports", metrics[1].Name)
+		assert.Equal(t, 1, metrics[1].Rejected)
+	}
+}
+
+// TestBulkhead 测试舱室的注册、查找、返回值和失败统计
+func TestBulkhead(t *testing.T) {
+	b := New()
+	_, err := b.Add("a", Config{MaxConcurrent: 1})
+	assert.NoError(t, err)
+	_, err = b.Add("a", Config{})
+	assert.ErrorIs(t, err, ErrDuplicateCompartment)
+	assert.ErrorIs(t, b.Execute(context.Background(), "missing", nil), ErrUnknownCompartment)
+
+	errBoom := errors.New("boom")
+	assert.ErrorIs(t, b.Execute(context.Background(), "a", func(context.Context) error { return errBoom }), errBoom)
+
+	c, err := b.Compartment("a")
+	assert.NoError(t, err)
+	v, err := Call(context.Background(), c, func(context.Context) (int, error) { return 42, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 42, v)
+
+	m := c.Metrics()
+	assert.Equal(t, 2, m.Accepted)
+	assert.Equal(t, 1, m.Failed)
+	assert.Zero(t, m.Active)
+}
@@ -0,0 +1,87 @@
+package bulkhead
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PaymentService 支付服务：支付和报表共用同一个进程，用舱壁隔离两类工作
+// 报表查询又慢又多，如果不隔离，会占满所有工作协程和数据库连接，导致支付也无法处理
+type PaymentService struct {
+	bulkhead *Bulkhead
+	payments *Compartment
+	reports  *Compartment
+}
+
+// NewPaymentService 创建支付服务
+func NewPaymentService() *PaymentService {
+	b := New()
+	payments, _ := b.Add("payments", Config{MaxConcurrent: 4, MaxQueue: 8, MaxWait: 50 * time.Millisecond})
+	reports, _ := b.Add("reports", Config{MaxConcurrent: 2, MaxQueue: 2})
+	return &PaymentService{bulkhead: b, payments: payments, reports: reports}
+}
+
+// Pay 处理一笔支付
+func (s *PaymentService) Pay(ctx context.Context, orderID string, amount float64) (string, error) {
+	return Call(ctx, s.payments, func(ctx context.Context) (string, error) {
+		time.Sleep(5 * time.Millisecond) // 调用支付网关
+		return fmt.Sprintf("%s 支付 %.2f 成功", orderID, amount), nil
+	})
+}
+
+// Report 生成一份报表
+func (s *PaymentService) Report(ctx context.Context, month string) (string, error) {
+	return Call(ctx, s.reports, func(ctx context.Context) (string, error) {
+		select {
+		case <-time.After(100 * time.Millisecond): // 大范围的数据库扫描
+			return month + " 报表", nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	})
+}
+
+// Metrics 返回各个舱室的统计信息
+func (s *PaymentService) Metrics() []Metrics {
+	return s.bulkhead.Metrics()
+}
+
+// RunExample 运行舱壁隔离示例
+func RunExample() {
+	service := NewPaymentService()
+	ctx := context.Background()
+
+	// 一波报表请求涌入：超出并发上限和队列的请求被立即拒绝
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	rejected := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := service.Report(ctx, fmt.Sprintf("2024-%02d", i+1)); errors.Is(err, ErrBulkheadFull) {
+				mutex.Lock()
+				rejected++
+				mutex.Unlock()
+			}
+		}(i)
+	}
+
+	// 与此同时，支付不受报表拖累
+	time.Sleep(10 * time.Millisecond)
+	start := time.Now()
+	for i := 1; i <= 3; i++ {
+		result, err := service.Pay(ctx, fmt.Sprintf("order-%d", i), float64(i)*99.9)
+		fmt.Println(result, err)
+	}
+	fmt.Printf("3 笔支付耗时 %v，报表仍在执行\n", time.Since(start).Round(time.Millisecond))
+
+	wg.Wait()
+	fmt.Printf("被拒绝的报表请求: %d\n", rejected)
+	for _, m := range service.Metrics() {
+		fmt.Printf("%-8s 接受 %d，拒绝 %d，失败 %d，累计排队 %v\n", m.Name, m.Accepted, m.Rejected, m.Failed, m.WaitTime.Round(time.Millisecond))
+	}
+}
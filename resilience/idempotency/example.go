@@ -0,0 +1,91 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrInvalidAmount 金额无效，重试也不会成功，因此缓存这个错误
+var ErrInvalidAmount = errors.New("金额无效")
+
+// Receipt 扣款凭证
+type Receipt struct {
+	ID     int
+	Amount int64
+}
+
+// PaymentService 示例中的支付服务：扣款请求携带幂等键，客户端超时重试不会重复扣款
+type PaymentService struct {
+	store   *Store[Receipt]
+	mutex   sync.Mutex
+	charged int64 // 实际扣款总额
+	nextID  int
+}
+
+// NewPaymentService 创建支付服务
+func NewPaymentService() *PaymentService {
+	return &PaymentService{
+		store: New[Receipt](
+			WithTTL(time.Hour),
+			WithErrorCaching(func(err error) bool { return errors.Is(err, ErrInvalidAmount) }),
+		),
+	}
+}
+
+// Charge 扣款，幂等键相同、金额不同的请求被拒绝
+func (p *PaymentService) Charge(ctx context.Context, key string, amount int64) (Receipt, error) {
+	return p.store.ExecuteRequest(ctx, key, fmt.Sprint(amount), func(ctx context.Context) (Receipt, error) {
+		if amount <= 0 {
+			return Receipt{}, fmt.Errorf("%w: %d", ErrInvalidAmount, amount)
+		}
+		time.Sleep(20 * time.Millisecond) // 模拟调用支付网关
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		p.nextID++
+		p.charged += amount
+		return Receipt{ID: p.nextID, Amount: amount}, nil
+	})
+}
+
+// Charged 返回实际扣款总额
+func (p *PaymentService) Charged() int64 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.charged
+}
+
+// RunExample 运行幂等键示例：并发的重复请求和事后的重试都只扣款一次
+func RunExample() {
+	service := NewPaymentService()
+	ctx := context.Background()
+
+	// 客户端以为请求超时，立即又发了两次
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			receipt, err := service.Charge(ctx, "order-1001", 9900)
+			fmt.Printf("并发请求: 凭证 %d, 错误 %v\n", receipt.ID, err)
+		}()
+	}
+	wg.Wait()
+
+	receipt, _ := service.Charge(ctx, "order-1001", 9900)
+	fmt.Printf("稍后重试: 凭证 %d\n", receipt.ID)
+
+	_, err := service.Charge(ctx, "order-1001", 100)
+	fmt.Println("复用幂等键但金额不同:", err)
+
+	_, err = service.Charge(ctx, "order-1002", -5)
+	fmt.Println("无效金额:", err)
+	_, err = service.Charge(ctx, "order-1002", -5)
+	fmt.Println("重试无效金额（缓存的错误）:", err)
+
+	stats := service.store.Stats()
+	fmt.Printf("实际扣款 %d 分; 执行 %d 次, 命中缓存 %d 次, 合并并发请求 %d 次, 冲突 %d 次\n",
+		service.Charged(), stats.Executions, stats.Hits, stats.Joined, stats.Conflicts)
+}
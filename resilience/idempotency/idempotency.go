@@ -0,0 +1,256 @@
+package idempotency
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// 幂等相关错误
+var (
+	ErrEmptyKey            = errors.New("幂等键不能为空")
+	ErrFingerprintMismatch = errors.New("幂等键已用于不同的请求")
+	ErrPanicked            = errors.New("操作发生 panic")
+)
+
+// Option 幂等存储配置选项
+type Option func(*config)
+
+// config 幂等存储配置，与结果类型无关
+type config struct {
+	ttl        time.Duration
+	capacity   int
+	cacheError func(err error) bool
+}
+
+// WithTTL 结果的保留时间，超过后同一个键会重新执行，默认 24 小时
+func WithTTL(d time.Duration) Option {
+	return func(c *config) {
+		if d > 0 {
+			c.ttl = d
+		}
+	}
+}
+
+// WithCapacity 最多保留的结果数，超过时淘汰最久未访问的结果，0 表示不限制，默认 10000
+// 正在执行的操作不计入也不会被淘汰
+func WithCapacity(n int) Option {
+	return func(c *config) {
+		if n >= 0 {
+			c.capacity = n
+		}
+	}
+}
+
+// WithErrorCaching 决定哪些错误像成功结果一样缓存
+// 默认不缓存任何错误：失败的操作没有产生副作用，重试时应该重新执行；
+// 参数校验失败这类重试也不会成功的错误可以缓存，重试时直接返回同样的错误
+func WithErrorCaching(cacheable func(err error) bool) Option {
+	return func(c *config) {
+		c.cacheError = cacheable
+	}
+}
+
+// Stats 幂等存储统计信息
+type Stats struct {
+	Executions  int // 实际执行操作的次数
+	Hits        int // 直接返回缓存结果的次数
+	Joined      int // 等待正在执行的同一操作并共享结果的次数
+	Conflicts   int // 幂等键已用于不同请求而被拒绝的次数
+	Evictions   int // 因容量不足被淘汰的结果数
+	Expirations int // 超过保留时间被移除的结果数
+	Size        int // 当前保存的键数，包括正在执行的
+}
+
+// record 一个幂等键的状态：执行中或已完成
+type record[T any] struct {
+	key         string
+	fingerprint string
+	done        chan struct{} // 执行完成时关闭
+	completed   bool
+	val         T
+	err         error
+	expiresAt   time.Time
+	elem        *list.Element // 完成后在 LRU 链表中的位置
+}
+
+// Store 幂等存储：保证同一个幂等键的操作只执行一次
+//
+// 重试在保留时间内直接得到第一次执行的结果；同一个键的并发请求只有一个真正执行，
+// 其余的等待并共享它的结果（单飞）。常用于支付、下单这类客户端超时后会重试的写操作。
+type Store[T any] struct {
+	config config
+
+	mutex   sync.Mutex
+	records map[string]*record[T]
+	lru     *list.List // 已完成的记录，最近访问的在前
+	stats   Stats
+
+	now func() time.Time // 时间来源，便于测试
+}
+
+// New 创建幂等存储
+func New[T any](opts ...Option) *Store[T] {
+	cfg := config{
+		ttl:      24 * time.Hour,
+		capacity: 10000,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Store[T]{
+		config:  cfg,
+		records: make(map[string]*record[T]),
+		lru:     list.New(),
+		now:     time.Now,
+	}
+}
+
+// Execute 以 key 为幂等键执行 fn，同一个键在保留时间内只执行一次
+func (s *Store[T]) Execute(ctx context.Context, key string, fn func(ctx context.Context) (T, error)) (T, error) {
+	return s.ExecuteRequest(ctx, key, "", fn)
+}
+
+// ExecuteRequest 与 Execute 相同，但额外比较请求指纹（例如请求体的哈希）
+// 同一个键携带不同的指纹说明客户端错误地复用了幂等键，返回 ErrFingerprintMismatch 而不是别人的结果
+//
+// 等待其他请求执行期间 ctx 结束时返回 ctx 的错误，正在执行的操作不受影响。
+// 执行者的 ctx 会传给 fn，fn 因此失败时等待者得到同样的错误，且默认不缓存，下一次重试会重新执行
+func (s *Store[T]) ExecuteRequest(ctx context.Context, key, fingerprint string, fn func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+	if key == "" {
+		return zero, ErrEmptyKey
+	}
+
+	s.mutex.Lock()
+	r, ok := s.lookupLocked(key)
+	if ok && r.fingerprint != fingerprint {
+		s.stats.Conflicts++
+		s.mutex.Unlock()
+		return zero, fmt.Errorf("%w: %s", ErrFingerprintMismatch, key)
+	}
+	if ok && r.completed {
+		s.stats.Hits++
+		s.lru.MoveToFront(r.elem)
+		s.mutex.Unlock()
+		return r.val, r.err
+	}
+	if ok {
+		s.stats.Joined++
+		s.mutex.Unlock()
+		select {
+		case <-r.done:
+			return r.val, r.err
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		}
+	}
+
+	r = &record[T]{key: key, fingerprint: fingerprint, done: make(chan struct{})}
+	s.records[key] = r
+	s.stats.Executions++
+	s.mutex.Unlock()
+
+	s.run(ctx, r, fn)
+	return r.val, r.err
+}
+
+// run 执行操作并保存结果，fn 中的 panic 转换为 ErrPanicked
+func (s *Store[T]) run(ctx context.Context, r *record[T], fn func(ctx context.Context) (T, error)) {
+	defer func() {
+		if p := recover(); p != nil {
+			var zero T
+			r.val, r.err = zero, fmt.Errorf("%w: %v", ErrPanicked, p)
+		}
+
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		if r.err != nil && (s.config.cacheError == nil || !s.config.cacheError(r.err)) {
+			// 不缓存的失败：移除记录，下一次请求重新执行
+			delete(s.records, r.key)
+		} else {
+			r.completed = true
+			r.expiresAt = s.now().Add(s.config.ttl)
+			r.elem = s.lru.PushFront(r)
+			s.evictLocked()
+		}
+		close(r.done)
+	}()
+
+	r.val, r.err = fn(ctx)
+}
+
+// lookupLocked 查找记录，已过期的记录视为不存在并移除，调用方持有锁
+func (s *Store[T]) lookupLocked(key string) (*record[T], bool) {
+	r, ok := s.records[key]
+	if ok && r.completed && !s.now().Before(r.expiresAt) {
+		s.removeLocked(r)
+		s.stats.Expirations++
+		return nil, false
+	}
+	return r, ok
+}
+
+// evictLocked 超出容量时淘汰最久未访问的结果，调用方持有锁
+func (s *Store[T]) evictLocked() {
+	if s.config.capacity == 0 {
+		return
+	}
+	for s.lru.Len() > s.config.capacity {
+		s.removeLocked(s.lru.Back().Value.(*record[T]))
+		s.stats.Evictions++
+	}
+}
+
+// removeLocked 移除已完成的记录，调用方持有锁
+func (s *Store[T]) removeLocked(r *record[T]) {
+	delete(s.records, r.key)
+	s.lru.Remove(r.elem)
+}
+
+// Forget 移除键上已完成的结果，之后的请求会重新执行，例如操作的结果已被回滚时
+// 正在执行的操作不受影响，返回是否移除了结果
+func (s *Store[T]) Forget(key string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	r, ok := s.records[key]
+	if !ok || !r.completed {
+		return false
+	}
+	s.removeLocked(r)
+	return true
+}
+
+// Purge 移除所有过期的结果，返回移除的数量
+// 过期的结果在被访问时也会移除，定期调用 Purge 可以回收不再被访问的结果占用的内存
+func (s *Store[T]) Purge() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	purged := 0
+	for elem := s.lru.Front(); elem != nil; {
+		next := elem.Next()
+		if r := elem.Value.(*record[T]); !now.Before(r.expiresAt) {
+			s.removeLocked(r)
+			purged++
+		}
+		elem = next
+	}
+	s.stats.Expirations += purged
+	return purged
+}
+
+// Stats 返回统计信息
+func (s *Store[T]) Stats() Stats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stats := s.stats
+	stats.Size = len(s.records)
+	return stats
+}
@@ -0,0 +1,277 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock 可以手动推进的时钟
+type fakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// newStore 创建使用假时钟的存储
+func newStore(opts ...Option) (*Store[int], *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s := New[int](opts...)
+	s.now = clock.Now
+	return s, clock
+}
+
+// counter 返回每次执行都加一的操作
+func counter(calls *atomic.Int64) func(context.Context) (int, error) {
+	return func(context.Context) (int, error) {
+		return int(calls.Add(1)), nil
+	}
+}
+
+// TestExecuteOnce 测试同一个键只执行一次，重试直接得到第一次的结果
+func TestExecuteOnce(t *testing.T) {
+	s, _ := newStore()
+	ctx := context.Background()
+	var calls atomic.Int64
+
+	for i := 0; i < 3; i++ {
+		v, err := s.Execute(ctx, "a", counter(&calls))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, v)
+	}
+	v, err := s.Execute(ctx, "b", counter(&calls))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v, "不同的键各自执行")
+
+	_, err = s.Execute(ctx, "", counter(&calls))
+	assert.ErrorIs(t, err, ErrEmptyKey)
+
+	assert.Equal(t, Stats{Executions: 2, Hits: 2, Size: 2}, s.Stats())
+}
+
+// TestTTL 测试结果超过保留时间后重新执行，Purge 回收过期的结果
+func TestTTL(t *testing.T) {
+	s, clock := newStore(WithTTL(time.Minute))
+	ctx := context.Background()
+	var calls atomic.Int64
+
+	s.Execute(ctx, "a", counter(&calls))
+	s.Execute(ctx, "b", counter(&calls))
+	clock.Advance(59 * time.Second)
+	v, _ := s.Execute(ctx, "a", counter(&calls))
+	assert.Equal(t, 1, v, "保留时间内返回缓存的结果")
+
+	clock.Advance(time.Second)
+	v, _ = s.Execute(ctx, "a", counter(&calls))
+	assert.Equal(t, 3, v, "过期后重新执行")
+	assert.Equal(t, 1, s.Stats().Expirations)
+
+	assert.Equal(t, 1, s.Purge(), "b 已过期")
+	assert.Equal(t, 0, s.Purge())
+	stats := s.Stats()
+	assert.Equal(t, 2, stats.Expirations)
+	assert.Equal(t, 1, stats.Size)
+}
+
+// TestConcurrentDuplicates 测试并发的重复请求只执行一次，全部得到同一个结果
+func TestConcurrentDuplicates(t *testing.T) {
+	s, _ := newStore()
+	gate := make(chan struct{})
+	var calls atomic.Int64
+
+	results := make([]int, 10)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := s.Execute(context.Background(), "pay", func(ctx context.Context) (int, error) {
+				<-gate
+				return int(calls.Add(1)), nil
+			})
+			assert.NoError(t, err)
+			results[i] = v
+		}()
+	}
+	assert.Eventually(t, func() bool {
+		stats := s.Stats()
+		return stats.Executions+stats.Joined == len(results)
+	}, time.Second, time.Millisecond)
+	close(gate)
+	wg.Wait()
+
+	assert.Equal(t, int64(1), calls.Load())
+	for _, v := range results {
+		assert.Equal(t, 1, v)
+	}
+	stats := s.Stats()
+	assert.Equal(t, 1, stats.Executions)
+	assert.Equal(t, 9, stats.Joined)
+}
+
+// TestWaiterCancel 测试等待者的 ctx 结束时不再等待，执行者不受影响
+func TestWaiterCancel(t *testing.T) {
+	s, _ := newStore()
+	gate := make(chan struct{})
+	done := make(chan int)
+	go func() {
+		v, _ := s.Execute(context.Background(), "a", func(context.Context) (int, error) {
+			<-gate
+			return 42, nil
+		})
+		done <- v
+	}()
+	assert.Eventually(t, func() bool { return s.Stats().Executions == 1 }, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := s.Execute(ctx, "a", func(context.Context) (int, error) { return 0, nil })
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(gate)
+	assert.Equal(t, 42, <-done)
+	v, err := s.Execute(context.Background(), "a", func(context.Context) (int, error) { return 0, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 42, v)
+}
+
+// TestErrors 测试失败默认不缓存，可缓存的错误和 panic 的处理
+func TestErrors(t *testing.T) {
+	errTimeout := errors.New("网关超时")
+	errInvalid := errors.New("参数无效")
+	s, _ := newStore(WithErrorCaching(func(err error) bool { return errors.Is(err, errInvalid) }))
+	ctx := context.Background()
+
+	attempts := 0
+	flaky := func(context.Context) (int, error) {
+		attempts++
+		if attempts < 3 {
+			return 0, errTimeout
+		}
+		return attempts, nil
+	}
+	for i := 0; i < 2; i++ {
+		_, err := s.Execute(ctx, "flaky", flaky)
+		assert.ErrorIs(t, err, errTimeout)
+	}
+	v, err := s.Execute(ctx, "flaky", flaky)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, v, "失败不缓存，重试时重新执行")
+	s.Execute(ctx, "flaky", flaky)
+	assert.Equal(t, 3, attempts, "成功之后不再执行")
+
+	invalid := 0
+	for i := 0; i < 3; i++ {
+		_, err = s.Execute(ctx, "invalid", func(context.Context) (int, error) {
+			invalid++
+			return 0, fmt.Errorf("金额为负: %w", errInvalid)
+		})
+		assert.ErrorIs(t, err, errInvalid)
+	}
+	assert.Equal(t, 1, invalid, "可缓存的错误只执行一次")
+
+	_, err = s.Execute(ctx, "panic", func(context.Context) (int, error) { panic("崩溃") })
+	assert.ErrorIs(t, err, ErrPanicked)
+	v, err = s.Execute(ctx, "panic", func(context.Context) (int, error) { return 7, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 7, v, "panic 后可以重试")
+}
+
+// TestFingerprintMismatch 测试同一个键用于不同请求时被拒绝
+func TestFingerprintMismatch(t *testing.T) {
+	s, _ := newStore()
+	ctx := context.Background()
+	var calls atomic.Int64
+
+	v, err := s.ExecuteRequest(ctx, "order-1", "amount=100", counter(&calls))
+	assert.NoError(t, err)
+	v2, err := s.ExecuteRequest(ctx, "order-1", "amount=100", counter(&calls))
+	assert.NoError(t, err)
+	assert.Equal(t, v, v2)
+
+	_, err = s.ExecuteRequest(ctx, "order-1", "amount=200", counter(&calls))
+	assert.ErrorIs(t, err, ErrFingerprintMismatch)
+	_, err = s.Execute(ctx, "order-1", counter(&calls))
+	assert.ErrorIs(t, err, ErrFingerprintMismatch, "没有指纹也是不同的请求")
+
+	// 正在执行时同样比较指纹
+	gate := make(chan struct{})
+	go s.ExecuteRequest(ctx, "order-2", "x", func(context.Context) (int, error) { <-gate; return 0, nil })
+	assert.Eventually(t, func() bool { return s.Stats().Executions == 2 }, time.Second, time.Millisecond)
+	_, err = s.ExecuteRequest(ctx, "order-2", "y", counter(&calls))
+	assert.ErrorIs(t, err, ErrFingerprintMismatch)
+	close(gate)
+
+	assert.Equal(t, int64(1), calls.Load())
+	assert.Equal(t, 3, s.Stats().Conflicts)
+}
+
+// TestCapacityAndForget 测试超出容量时淘汰最久未访问的结果，Forget 移除结果
+func TestCapacityAndForget(t *testing.T) {
+	s, _ := newStore(WithCapacity(2))
+	ctx := context.Background()
+	var calls atomic.Int64
+
+	s.Execute(ctx, "a", counter(&calls))
+	s.Execute(ctx, "b", counter(&calls))
+	s.Execute(ctx, "a", counter(&calls)) // 访问 a，b 成为最久未访问
+	s.Execute(ctx, "c", counter(&calls))
+
+	stats := s.Stats()
+	assert.Equal(t, 1, stats.Evictions)
+	assert.Equal(t, 2, stats.Size)
+	v, _ := s.Execute(ctx, "a", counter(&calls))
+	assert.Equal(t, 1, v, "a 被保留")
+	v, _ = s.Execute(ctx, "b", counter(&calls))
+	assert.Equal(t, 4, v, "b 被淘汰，重新执行")
+
+	assert.True(t, s.Forget("b"))
+	assert.False(t, s.Forget("b"))
+	assert.False(t, s.Forget("missing"))
+	v, _ = s.Execute(ctx, "b", counter(&calls))
+	assert.Equal(t, 5, v)
+}
+
+// BenchmarkExecute 比较命中缓存与实际执行的开销
+func BenchmarkExecute(b *testing.B) {
+	ctx := context.Background()
+	fn := func(context.Context) (int, error) { return 1, nil }
+	b.Run("Hit", func(b *testing.B) {
+		s := New[int]()
+		s.Execute(ctx, "key", fn)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			s.Execute(ctx, "key", fn)
+		}
+	})
+	b.Run("Miss", func(b *testing.B) {
+		s := New[int](WithCapacity(1000))
+		keys := make([]string, 4096)
+		for i := range keys {
+			keys[i] = fmt.Sprint("key-", i)
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			s.Execute(ctx, keys[i%len(keys)], fn)
+		}
+	})
+}
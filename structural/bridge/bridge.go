@@ -108,8 +108,9 @@ func (r *Radio) GetName() string {
 
 // BaseRemoteControl 是所有遥控器的基础实现
 type BaseRemoteControl struct {
-	device Device // 持有对Device的引用——这是桥接模式的核心
-	volume int    // 当前音量
+	device   Device          // 持有对Device的引用——这是桥接模式的核心
+	volume   int             // 当前音量
+	registry *DeviceRegistry // 可选的设备注册表，用于运行时切换设备
 }
 
 // NewBaseRemoteControl 创建一个新的基础遥控器
@@ -0,0 +1,436 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// 网络遥控器相关错误
+var (
+	ErrServerClosed = errors.New("设备服务器已关闭")
+	ErrTimeout      = errors.New("请求超时")
+	ErrNotConnected = errors.New("遥控器未连接")
+)
+
+// 设备指令操作类型
+const (
+	OpPing       = "ping"
+	OpPowerOn    = "power_on"
+	OpPowerOff   = "power_off"
+	OpVolumeUp   = "volume_up"
+	OpVolumeDown = "volume_down"
+	OpSetVolume  = "set_volume"
+)
+
+// DeviceCommand 通过网络发送的设备指令
+type DeviceCommand struct {
+	ID    uint64 `json:"id"`
+	Op    string `json:"op"`
+	Value int    `json:"value,omitempty"`
+}
+
+// DeviceResponse 设备服务器的应答
+type DeviceResponse struct {
+	ID     uint64 `json:"id"`
+	OK     bool   `json:"ok"`
+	Error  string `json:"error,omitempty"`
+	Device string `json:"device"`
+	Volume int    `json:"volume"`
+}
+
+// Transport 传输层接口，负责发送序列化后的指令并返回应答
+type Transport interface {
+	RoundTrip(ctx context.Context, payload []byte) ([]byte, error)
+}
+
+// ConnectionState 网络遥控器的连接状态
+type ConnectionState int
+
+const (
+	Disconnected ConnectionState = iota // 未连接
+	Connected                           // 已连接
+	Reconnecting                        // 重试中
+)
+
+// String 返回连接状态的名称
+func (s ConnectionState) String() string {
+	switch s {
+	case Disconnected:
+		return "未连接"
+	case Connected:
+		return "已连接"
+	case Reconnecting:
+		return "重试中"
+	default:
+		return "未知状态"
+	}
+}
+
+// serverRequest 服务器内部使用的请求
+type serverRequest struct {
+	payload []byte
+	reply   chan []byte
+}
+
+// DeviceServer 设备服务器（模拟红外发射器），接收网络指令并驱动实际设备
+type DeviceServer struct {
+	device   Device
+	volume   int
+	requests chan serverRequest
+	done     chan struct{}
+	once     sync.Once
+
+	mutex    sync.Mutex
+	dropNext int           // 丢弃接下来的若干个请求，用于模拟网络丢包
+	latency  time.Duration // 模拟的处理延迟
+	handled  int           // 已处理的请求数
+}
+
+// NewDeviceServer 创建设备服务器
+func NewDeviceServer(device Device) *DeviceServer {
+	return &DeviceServer{
+		device:   device,
+		volume:   10,
+		requests: make(chan serverRequest),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start 启动服务器的处理协程
+func (s *DeviceServer) Start() {
+	go s.serve()
+}
+
+// Stop 停止服务器
+func (s *DeviceServer) Stop() {
+	s.once.Do(func() {
+		close(s.done)
+	})
+}
+
+// DropNext 丢弃接下来的 n 个请求（不回复），用于模拟丢包
+func (s *DeviceServer) DropNext(n int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.dropNext = n
+}
+
+// SetLatency 设置模拟的处理延迟
+func (s *DeviceServer) SetLatency(latency time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.latency = latency
+}
+
+// Handled 返回已处理的请求数
+func (s *DeviceServer) Handled() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.handled
+}
+
+// Transport 返回连接到该服务器的通道传输层
+func (s *DeviceServer) Transport() Transport {
+	return &channelTransport{server: s}
+}
+
+// serve 处理请求的主循环
+func (s *DeviceServer) serve() {
+	for {
+		select {
+		case req := <-s.requests:
+			s.mutex.Lock()
+			drop := s.dropNext > 0
+			if drop {
+				s.dropNext--
+			}
+			latency := s.latency
+			s.mutex.Unlock()
+
+			if drop {
+				continue
+			}
+			if latency > 0 {
+				time.Sleep(latency)
+			}
+			// 回复通道带缓冲，客户端超时离开也不会阻塞服务器
+			req.reply <- s.handle(req.payload)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// handle 解析指令并驱动设备
+func (s *DeviceServer) handle(payload []byte) []byte {
+	var cmd DeviceCommand
+	resp := DeviceResponse{Device: s.device.GetName()}
+
+	if err := json.Unmarshal(payload, &cmd); err != nil {
+		resp.Error = fmt.Sprintf("无法解析指令: %v", err)
+		return mustMarshal(resp)
+	}
+	resp.ID = cmd.ID
+
+	switch cmd.Op {
+	case OpPing:
+	case OpPowerOn:
+		s.device.TurnOn()
+	case OpPowerOff:
+		s.device.TurnOff()
+	case OpVolumeUp:
+		s.setVolume(s.volume + 10)
+	case OpVolumeDown:
+		s.setVolume(s.volume - 10)
+	case OpSetVolume:
+		s.setVolume(cmd.Value)
+	default:
+		resp.Error = fmt.Sprintf("未知指令: %s", cmd.Op)
+		return mustMarshal(resp)
+	}
+
+	s.mutex.Lock()
+	s.handled++
+	s.mutex.Unlock()
+
+	resp.OK = true
+	resp.Volume = s.volume
+	return mustMarshal(resp)
+}
+
+// setVolume 设置音量并限制在0-100之间
+func (s *DeviceServer) setVolume(volume int) {
+	if volume < 0 {
+		volume = 0
+	} else if volume > 100 {
+		volume = 100
+	}
+	s.volume = volume
+	s.device.SetVolume(volume)
+}
+
+// mustMarshal 序列化应答，应答结构体总是可以序列化
+func mustMarshal(v any) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+// channelTransport 基于通道的进程内传输层
+type channelTransport struct {
+	server *DeviceServer
+}
+
+// RoundTrip 发送请求并等待应答
+func (t *channelTransport) RoundTrip(ctx context.Context, payload []byte) ([]byte, error) {
+	req := serverRequest{payload: payload, reply: make(chan []byte, 1)}
+
+	select {
+	case t.server.requests <- req:
+	case <-t.server.done:
+		return nil, ErrServerClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	select {
+	case resp := <-req.reply:
+		return resp, nil
+	case <-t.server.done:
+		return nil, ErrServerClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// NetworkRemoteControl 网络遥控器，通过传输层把指令发送给远端设备服务器
+// 它同样实现了 RemoteControl 接口，说明桥接的"实现部分"可以跨越进程边界
+type NetworkRemoteControl struct {
+	transport  Transport
+	timeout    time.Duration
+	retries    int
+	retryDelay time.Duration
+
+	mutex   sync.Mutex
+	nextID  uint64
+	state   ConnectionState
+	volume  int
+	device  string
+	lastErr error
+}
+
+// NewNetworkRemoteControl 创建网络遥控器，默认超时200毫秒、重试2次
+func NewNetworkRemoteControl(transport Transport) *NetworkRemoteControl {
+	return &NetworkRemoteControl{
+		transport:  transport,
+		timeout:    200 * time.Millisecond,
+		retries:    2,
+		retryDelay: 10 * time.Millisecond,
+		state:      Disconnected,
+	}
+}
+
+// SetTimeout 设置单次请求的超时时间
+func (n *NetworkRemoteControl) SetTimeout(timeout time.Duration) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.timeout = timeout
+}
+
+// SetRetries 设置失败后的重试次数
+func (n *NetworkRemoteControl) SetRetries(retries int) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.retries = retries
+}
+
+// Connect 通过 ping 指令与设备服务器建立连接
+func (n *NetworkRemoteControl) Connect() error {
+	_, err := n.send(OpPing, 0, true)
+	return err
+}
+
+// Disconnect 断开连接
+func (n *NetworkRemoteControl) Disconnect() {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.state = Disconnected
+}
+
+// State 返回当前连接状态
+func (n *NetworkRemoteControl) State() ConnectionState {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return n.state
+}
+
+// LastError 返回最近一次操作的错误
+func (n *NetworkRemoteControl) LastError() error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return n.lastErr
+}
+
+// Volume 返回远端设备最近一次报告的音量
+func (n *NetworkRemoteControl) Volume() int {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return n.volume
+}
+
+// DeviceName 返回远端设备的名称
+func (n *NetworkRemoteControl) DeviceName() string {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return n.device
+}
+
+// PowerOn 开启远端设备
+func (n *NetworkRemoteControl) PowerOn() {
+	n.Send(OpPowerOn, 0)
+}
+
+// PowerOff 关闭远端设备
+func (n *NetworkRemoteControl) PowerOff() {
+	n.Send(OpPowerOff, 0)
+}
+
+// VolumeUp 提高远端设备音量
+func (n *NetworkRemoteControl) VolumeUp() {
+	n.Send(OpVolumeUp, 0)
+}
+
+// VolumeDown 降低远端设备音量
+func (n *NetworkRemoteControl) VolumeDown() {
+	n.Send(OpVolumeDown, 0)
+}
+
+// SetVolume 直接设置远端设备音量
+func (n *NetworkRemoteControl) SetVolume(volume int) error {
+	_, err := n.Send(OpSetVolume, volume)
+	return err
+}
+
+// Send 发送指令并返回应答，需要先调用 Connect
+// RemoteControl 接口的方法没有返回值，错误可以通过 LastError 获取
+func (n *NetworkRemoteControl) Send(op string, value int) (DeviceResponse, error) {
+	return n.send(op, value, false)
+}
+
+// send 发送指令，失败时按配置重试
+func (n *NetworkRemoteControl) send(op string, value int, connecting bool) (DeviceResponse, error) {
+	n.mutex.Lock()
+	if !connecting && n.state == Disconnected {
+		n.lastErr = ErrNotConnected
+		n.mutex.Unlock()
+		return DeviceResponse{}, ErrNotConnected
+	}
+	n.nextID++
+	cmd := DeviceCommand{ID: n.nextID, Op: op, Value: value}
+	timeout, retries, retryDelay := n.timeout, n.retries, n.retryDelay
+	n.mutex.Unlock()
+
+	payload := mustMarshal(cmd)
+
+	var resp DeviceResponse
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			n.setState(Reconnecting)
+			time.Sleep(retryDelay)
+		}
+
+		resp, err = n.roundTrip(payload, timeout)
+		if err == nil || errors.Is(err, ErrServerClosed) {
+			break
+		}
+	}
+
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.lastErr = err
+	if err != nil {
+		n.state = Disconnected
+		return DeviceResponse{}, err
+	}
+
+	n.state = Connected
+	n.device = resp.Device
+	if !resp.OK {
+		n.lastErr = errors.New(resp.Error)
+		return resp, n.lastErr
+	}
+	n.volume = resp.Volume
+	return resp, nil
+}
+
+// roundTrip 发送一次请求并解析应答
+func (n *NetworkRemoteControl) roundTrip(payload []byte, timeout time.Duration) (DeviceResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	data, err := n.transport.RoundTrip(ctx, payload)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return DeviceResponse{}, fmt.Errorf("%w: %w", ErrTimeout, err)
+		}
+		return DeviceResponse{}, err
+	}
+
+	var resp DeviceResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return DeviceResponse{}, fmt.Errorf("无法解析应答: %w", err)
+	}
+	return resp, nil
+}
+
+// setState 更新连接状态
+func (n *NetworkRemoteControl) setState(state ConnectionState) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.state = state
+}
@@ -0,0 +1,170 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试网络遥控器的基本操作
+func TestNetworkRemoteControl(t *testing.T) {
+	assert := assert.New(t)
+
+	tv := NewTV("客厅")
+	server := NewDeviceServer(tv)
+	server.Start()
+	defer server.Stop()
+
+	var remote RemoteControl
+	network := NewNetworkRemoteControl(server.Transport())
+	remote = network
+
+	output := captureOutput(func() {
+		assert.NoError(network.Connect())
+		assert.Equal(Connected, network.State())
+		assert.Equal("客厅", network.DeviceName())
+
+		remote.PowerOn()
+		remote.VolumeUp()
+		remote.VolumeUp()
+		remote.VolumeDown()
+		remote.PowerOff()
+	})
+
+	assert.NoError(network.LastError())
+	assert.Contains(output, "客厅 电视机打开了")
+	assert.Contains(output, "客厅 电视机音量设置为：30")
+	assert.Contains(output, "客厅 电视机关闭了")
+	assert.Equal(20, network.Volume(), "遥控器应同步远端设备的音量")
+	assert.Equal(20, tv.volume)
+	assert.Equal(6, server.Handled(), "ping加5个操作共6个请求")
+}
+
+// 测试未连接时发送指令
+func TestNetworkRemoteControlNotConnected(t *testing.T) {
+	assert := assert.New(t)
+
+	server := NewDeviceServer(NewRadio("厨房"))
+	server.Start()
+	defer server.Stop()
+
+	remote := NewNetworkRemoteControl(server.Transport())
+	remote.PowerOn()
+
+	assert.ErrorIs(remote.LastError(), ErrNotConnected)
+	assert.Equal(0, server.Handled(), "未连接时不应发送任何请求")
+
+	captureOutput(func() {
+		assert.NoError(remote.Connect())
+		remote.Disconnect()
+	})
+	assert.Equal(Disconnected, remote.State())
+	_, err := remote.Send(OpPowerOn, 0)
+	assert.ErrorIs(err, ErrNotConnected)
+}
+
+// 测试超时与重试
+func TestNetworkRemoteControlRetry(t *testing.T) {
+	t.Run("丢包后重试成功", func(t *testing.T) {
+		assert := assert.New(t)
+
+		server := NewDeviceServer(NewTV("卧室"))
+		server.Start()
+		defer server.Stop()
+
+		remote := NewNetworkRemoteControl(server.Transport())
+		remote.SetTimeout(30 * time.Millisecond)
+		remote.SetRetries(2)
+
+		captureOutput(func() {
+			assert.NoError(remote.Connect())
+			server.DropNext(2)
+			assert.NoError(remote.SetVolume(55), "重试次数足够时应最终成功")
+		})
+
+		assert.Equal(Connected, remote.State())
+		assert.Equal(55, remote.Volume())
+	})
+
+	t.Run("重试耗尽后断开", func(t *testing.T) {
+		assert := assert.New(t)
+
+		server := NewDeviceServer(NewTV("书房"))
+		server.Start()
+		defer server.Stop()
+
+		remote := NewNetworkRemoteControl(server.Transport())
+		remote.SetTimeout(20 * time.Millisecond)
+		remote.SetRetries(1)
+
+		captureOutput(func() {
+			assert.NoError(remote.Connect())
+		})
+		server.DropNext(5)
+		remote.PowerOn()
+
+		assert.ErrorIs(remote.LastError(), ErrTimeout)
+		assert.Equal(Disconnected, remote.State(), "重试耗尽后应进入未连接状态")
+	})
+
+	t.Run("慢设备超时", func(t *testing.T) {
+		assert := assert.New(t)
+
+		server := NewDeviceServer(NewRadio("阳台"))
+		server.SetLatency(50 * time.Millisecond)
+		server.Start()
+		defer server.Stop()
+
+		remote := NewNetworkRemoteControl(server.Transport())
+		remote.SetTimeout(10 * time.Millisecond)
+		remote.SetRetries(0)
+
+		err := remote.Connect()
+		assert.ErrorIs(err, ErrTimeout)
+		assert.ErrorIs(err, context.DeadlineExceeded)
+	})
+}
+
+// 测试服务器关闭
+func TestDeviceServerStopped(t *testing.T) {
+	assert := assert.New(t)
+
+	server := NewDeviceServer(NewTV("客厅"))
+	server.Start()
+
+	remote := NewNetworkRemoteControl(server.Transport())
+	captureOutput(func() {
+		assert.NoError(remote.Connect())
+	})
+
+	server.Stop()
+	start := time.Now()
+	_, err := remote.Send(OpPowerOn, 0)
+
+	assert.True(errors.Is(err, ErrServerClosed), "服务器关闭后应返回 ErrServerClosed")
+	assert.Less(time.Since(start), 100*time.Millisecond, "服务器关闭时不应继续重试")
+	assert.Equal(Disconnected, remote.State())
+}
+
+// 测试未知指令
+func TestDeviceServerUnknownOp(t *testing.T) {
+	assert := assert.New(t)
+
+	server := NewDeviceServer(NewTV("客厅"))
+	server.Start()
+	defer server.Stop()
+
+	remote := NewNetworkRemoteControl(server.Transport())
+	captureOutput(func() {
+		assert.NoError(remote.Connect())
+	})
+
+	resp, err := remote.Send("rewind", 0)
+	assert.Error(err)
+	assert.False(resp.OK)
+	assert.Contains(err.Error(), "未知指令")
+	assert.Equal(Connected, remote.State(), "业务错误不影响连接状态")
+}
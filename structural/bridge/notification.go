@@ -0,0 +1,310 @@
+package bridge
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+)
+
+// 通知相关错误
+var (
+	ErrInvalidRecipient = errors.New("接收者格式不正确")
+	ErrTemplate         = errors.New("通知模板错误")
+)
+
+// 各渠道的内容长度限制（字符数）
+const (
+	smsMaxLength  = 70
+	pushMaxLength = 100
+)
+
+// Message 渲染后的通知内容，由通知（抽象部分）生成，交给发送器（实现部分）按渠道格式化
+type Message struct {
+	Kind   string // 通知类型，如"告警"
+	Title  string // 标题
+	Body   string // 正文
+	Urgent bool   // 是否紧急
+}
+
+// Delivery 一次发送记录
+type Delivery struct {
+	Channel   string // 发送渠道
+	Recipient string // 接收者
+	Content   string // 按渠道格式化后的内容
+}
+
+// MessageSender 消息发送器接口，这是通知示例中的"实现部分"
+type MessageSender interface {
+	Channel() string                          // 渠道名称
+	Format(msg Message) string                // 按渠道格式化消息
+	Send(recipient string, msg Message) error // 格式化并发送消息
+}
+
+// outbox 记录发送器已经发送的消息
+type outbox struct {
+	sent []Delivery
+}
+
+// record 打印并记录一次发送
+func (o *outbox) record(channel, recipient, content string) {
+	o.sent = append(o.sent, Delivery{Channel: channel, Recipient: recipient, Content: content})
+	fmt.Printf("[%s] 发送给 %s:\n%s\n", channel, recipient, content)
+}
+
+// Sent 返回已经发送的消息
+func (o *outbox) Sent() []Delivery {
+	return o.sent
+}
+
+// EmailSender 邮件发送器，内容包括主题、正文和签名
+type EmailSender struct {
+	outbox
+	from string
+}
+
+// NewEmailSender 创建邮件发送器，from 作为邮件签名
+func NewEmailSender(from string) *EmailSender {
+	return &EmailSender{from: from}
+}
+
+// Channel 返回渠道名称
+func (e *EmailSender) Channel() string {
+	return "邮件"
+}
+
+// Format 格式化为带主题行的邮件
+func (e *EmailSender) Format(msg Message) string {
+	subject := fmt.Sprintf("[%s] %s", msg.Kind, msg.Title)
+	if msg.Urgent {
+		subject = "[紧急]" + subject
+	}
+	return fmt.Sprintf("主题: %s\n\n%s\n\n-- %s", subject, msg.Body, e.from)
+}
+
+// Send 发送邮件，接收者必须是邮箱地址
+func (e *EmailSender) Send(recipient string, msg Message) error {
+	if !strings.Contains(recipient, "@") {
+		return fmt.Errorf("%w: %s 不是邮箱地址", ErrInvalidRecipient, recipient)
+	}
+	e.record(e.Channel(), recipient, e.Format(msg))
+	return nil
+}
+
+// SMSSender 短信发送器，标题和正文合并为一行并限制长度
+type SMSSender struct {
+	outbox
+	signature string
+}
+
+// NewSMSSender 创建短信发送器，signature 为短信签名，如"某某科技"
+func NewSMSSender(signature string) *SMSSender {
+	return &SMSSender{signature: signature}
+}
+
+// Channel 返回渠道名称
+func (s *SMSSender) Channel() string {
+	return "短信"
+}
+
+// Format 格式化为带签名的单行短信，超过 70 个字符时截断
+func (s *SMSSender) Format(msg Message) string {
+	content := fmt.Sprintf("【%s】%s: %s", s.signature, msg.Title, msg.Body)
+	return truncate(content, smsMaxLength)
+}
+
+// Send 发送短信，接收者必须是手机号码
+func (s *SMSSender) Send(recipient string, msg Message) error {
+	if !isPhoneNumber(recipient) {
+		return fmt.Errorf("%w: %s 不是手机号码", ErrInvalidRecipient, recipient)
+	}
+	s.record(s.Channel(), recipient, s.Format(msg))
+	return nil
+}
+
+// PushSender 推送发送器，标题单独一行，正文限制长度
+type PushSender struct {
+	outbox
+}
+
+// NewPushSender 创建推送发送器
+func NewPushSender() *PushSender {
+	return &PushSender{}
+}
+
+// Channel 返回渠道名称
+func (p *PushSender) Channel() string {
+	return "推送"
+}
+
+// Format 格式化为标题加正文的推送，紧急通知的标题带有提示符号，正文超过 100 个字符时截断
+func (p *PushSender) Format(msg Message) string {
+	title := msg.Title
+	if msg.Urgent {
+		title = "⚠ " + title
+	}
+	return title + "\n" + truncate(msg.Body, pushMaxLength)
+}
+
+// Send 发送推送，接收者为设备令牌，不能为空
+func (p *PushSender) Send(recipient string, msg Message) error {
+	if strings.TrimSpace(recipient) == "" {
+		return fmt.Errorf("%w: 设备令牌为空", ErrInvalidRecipient)
+	}
+	p.record(p.Channel(), recipient, p.Format(msg))
+	return nil
+}
+
+// Notification 通知的接口，这是通知示例中的"抽象部分"
+type Notification interface {
+	Notify(recipient string, data map[string]any) error // 用数据渲染模板并发送
+	SetSender(sender MessageSender)                     // 切换发送渠道
+}
+
+// BaseNotification 是所有通知的基础实现，负责模板渲染
+type BaseNotification struct {
+	sender MessageSender // 持有对MessageSender的引用——这是桥接模式的核心
+	kind   string
+	urgent bool
+	title  *template.Template
+	body   *template.Template
+}
+
+// newBaseNotification 使用内置模板创建基础通知，内置模板必须能够解析
+func newBaseNotification(sender MessageSender, kind string, urgent bool, title, body string) *BaseNotification {
+	n := &BaseNotification{sender: sender, kind: kind, urgent: urgent}
+	if err := n.SetTemplate(title, body); err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// SetSender 切换发送渠道，通知的类型和模板保持不变
+func (n *BaseNotification) SetSender(sender MessageSender) {
+	n.sender = sender
+}
+
+// Sender 返回当前的发送器
+func (n *BaseNotification) Sender() MessageSender {
+	return n.sender
+}
+
+// SetTemplate 设置标题和正文模板，使用 text/template 语法，如 "{{.Service}} 告警"
+// 渲染时模板引用的字段必须存在
+func (n *BaseNotification) SetTemplate(title, body string) error {
+	titleTmpl, err := template.New("title").Option("missingkey=error").Parse(title)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrTemplate, err)
+	}
+	bodyTmpl, err := template.New("body").Option("missingkey=error").Parse(body)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrTemplate, err)
+	}
+	n.title, n.body = titleTmpl, bodyTmpl
+	return nil
+}
+
+// Render 用数据渲染模板，生成与渠道无关的消息
+func (n *BaseNotification) Render(data map[string]any) (Message, error) {
+	var title, body strings.Builder
+	if err := n.title.Execute(&title, data); err != nil {
+		return Message{}, fmt.Errorf("%w: %v", ErrTemplate, err)
+	}
+	if err := n.body.Execute(&body, data); err != nil {
+		return Message{}, fmt.Errorf("%w: %v", ErrTemplate, err)
+	}
+	return Message{Kind: n.kind, Title: title.String(), Body: body.String(), Urgent: n.urgent}, nil
+}
+
+// Notify 渲染模板并通过当前发送器发送
+func (n *BaseNotification) Notify(recipient string, data map[string]any) error {
+	msg, err := n.Render(data)
+	if err != nil {
+		return err
+	}
+	return n.sender.Send(recipient, msg)
+}
+
+// Alert 告警通知，总是紧急的
+type Alert struct {
+	*BaseNotification
+}
+
+// NewAlert 创建告警通知，模板字段为 Service、Detail
+func NewAlert(sender MessageSender) *Alert {
+	return &Alert{
+		BaseNotification: newBaseNotification(sender, "告警", true,
+			"{{.Service}} 服务异常", "{{.Service}} 出现问题: {{.Detail}}，请立即处理"),
+	}
+}
+
+// Raise 发出告警
+func (a *Alert) Raise(recipient, service, detail string) error {
+	return a.Notify(recipient, map[string]any{"Service": service, "Detail": detail})
+}
+
+// Reminder 提醒通知
+type Reminder struct {
+	*BaseNotification
+}
+
+// NewReminder 创建提醒通知，模板字段为 Event、Time
+func NewReminder(sender MessageSender) *Reminder {
+	return &Reminder{
+		BaseNotification: newBaseNotification(sender, "提醒", false,
+			"{{.Event}} 即将开始", "{{.Event}} 将于 {{.Time}} 开始，请准时参加"),
+	}
+}
+
+// Remind 提醒接收者 at 时刻开始的事件
+func (r *Reminder) Remind(recipient, event string, at time.Time) error {
+	return r.Notify(recipient, map[string]any{"Event": event, "Time": at.Format("01-02 15:04")})
+}
+
+// Promotion 促销通知，可以群发
+type Promotion struct {
+	*BaseNotification
+}
+
+// NewPromotion 创建促销通知，模板字段为 Offer、Discount
+func NewPromotion(sender MessageSender) *Promotion {
+	return &Promotion{
+		BaseNotification: newBaseNotification(sender, "促销", false,
+			"{{.Offer}} 限时 {{.Discount}} 折", "{{.Offer}} 现在 {{.Discount}} 折，数量有限，先到先得"),
+	}
+}
+
+// Broadcast 向多个接收者发送促销，某个接收者失败不影响其他接收者，返回所有失败的错误
+func (p *Promotion) Broadcast(recipients []string, offer string, discount int) error {
+	var errs []error
+	for _, recipient := range recipients {
+		if err := p.Notify(recipient, map[string]any{"Offer": offer, "Discount": discount}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// truncate 把 s 截断到最多 limit 个字符，截断时以省略号结尾
+func truncate(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:limit-1]) + "…"
+}
+
+// isPhoneNumber 检查是否为 11 位手机号码
+func isPhoneNumber(s string) bool {
+	if len(s) != 11 || s[0] != '1' {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+package bridge
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNotificationChannels 测试同一条通知在不同渠道上的格式
+func TestNotificationChannels(t *testing.T) {
+	assert := assert.New(t)
+	email := NewEmailSender("运维团队")
+	sms := NewSMSSender("示例科技")
+	push := NewPushSender()
+
+	alert := NewAlert(email)
+	output := captureOutput(func() {
+		assert.NoError(alert.Raise("ops@example.com", "订单服务", "错误率 12%"))
+		alert.SetSender(sms)
+		assert.NoError(alert.Raise("13800138000", "订单服务", "错误率 12%"))
+		alert.SetSender(push)
+		assert.NoError(alert.Raise("device-token", "订单服务", "错误率 12%"))
+	})
+	assert.Contains(output, "[邮件] 发送给 ops@example.com")
+
+	assert.Equal("主题: [紧急][告警] 订单服务 服务异常\n\n订单服务 出现问题: 错误率 12%，请立即处理\n\n-- 运维团队",
+		email.Sent()[0].Content)
+	assert.Equal("【示例科技】订单服务 服务异常: 订单服务 出现问题: 错误率 12%，请立即处理", sms.Sent()[0].Content)
+	assert.Equal("⚠ 订单服务 服务异常\n订单服务 出现问题: 错误率 12%，请立即处理", push.Sent()[0].Content)
+	assert.Equal(Delivery{Channel: "推送", Recipient: "device-token", Content: push.Sent()[0].Content}, push.Sent()[0])
+}
+
+// TestNotificationKinds 测试提醒和促销通知
+func TestNotificationKinds(t *testing.T) {
+	assert := assert.New(t)
+	email := NewEmailSender("行政部")
+	sms := NewSMSSender("示例商城")
+
+	reminder := NewReminder(email)
+	captureOutput(func() {
+		assert.NoError(reminder.Remind("staff@example.com", "周会", time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)))
+	})
+	assert.Contains(email.Sent()[0].Content, "主题: [提醒] 周会 即将开始")
+	assert.Contains(email.Sent()[0].Content, "周会 将于 06-03 10:00 开始")
+	assert.NotContains(email.Sent()[0].Content, "紧急")
+
+	promotion := NewPromotion(sms)
+	var err error
+	captureOutput(func() {
+		err = promotion.Broadcast([]string{"13800138000", "12345", "13900139000"}, "夏季新品", 8)
+	})
+	assert.ErrorIs(err, ErrInvalidRecipient, "无效的号码返回错误")
+	assert.Len(sms.Sent(), 2, "其他接收者照常发送")
+	assert.Equal("13900139000", sms.Sent()[1].Recipient)
+	assert.Contains(sms.Sent()[0].Content, "夏季新品 限时 8 折")
+}
+
+// TestNotificationTemplate 测试自定义模板和长度限制
+func TestNotificationTemplate(t *testing.T) {
+	assert := assert.New(t)
+	push := NewPushSender()
+	alert := NewAlert(push)
+
+	assert.ErrorIs(alert.SetTemplate("{{.Service", "正文"), ErrTemplate)
+	assert.NoError(alert.SetTemplate("{{.Service}} 宕机", "{{.Detail}}（值班人: {{.OnCall}}）"))
+	assert.ErrorIs(alert.Raise("device-token", "支付服务", "无法连接数据库"), ErrTemplate,
+		"模板引用了缺失的字段")
+
+	var err error
+	captureOutput(func() {
+		err = alert.Notify("device-token", map[string]any{"Service": "支付服务", "Detail": "无法连接数据库", "OnCall": "小王"})
+	})
+	assert.NoError(err)
+	assert.Equal("⚠ 支付服务 宕机\n无法连接数据库（值班人: 小王）", push.Sent()[0].Content)
+	assert.ErrorIs(alert.Notify(" ", map[string]any{"Service": "a", "Detail": "b", "OnCall": "c"}), ErrInvalidRecipient)
+
+	// 短信超长时截断
+	sms := NewSMSSender("示例科技")
+	msg := Message{Title: "日报", Body: strings.Repeat("内容", 50)}
+	content := sms.Format(msg)
+	assert.Equal(70, utf8.RuneCountInString(content))
+	assert.Equal("…", string([]rune(content)[69:]))
+	assert.ErrorIs(NewEmailSender("x").Send("not-an-email", msg), ErrInvalidRecipient)
+}
@@ -0,0 +1,301 @@
+package bridge
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// 设备注册表相关错误
+var (
+	ErrDeviceNotFound     = errors.New("设备未注册")
+	ErrDeviceExists       = errors.New("设备名称已被占用")
+	ErrUnknownDeviceType  = errors.New("未知的设备类型")
+	ErrNoRegistry         = errors.New("遥控器未关联设备注册表")
+	ErrInvalidDeviceState = errors.New("无效的设备状态")
+)
+
+// 内置设备类型
+const (
+	DeviceTypeTV    = "tv"
+	DeviceTypeRadio = "radio"
+)
+
+// DeviceState 设备的可持久化状态
+type DeviceState struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	On     bool   `json:"on"`
+	Volume int    `json:"volume"`
+}
+
+// StatefulDevice 可以导出和恢复状态的设备，注册表只持久化实现了该接口的设备
+type StatefulDevice interface {
+	Device
+	State() DeviceState
+	Restore(state DeviceState)
+}
+
+// DeviceFactory 根据名称创建某种类型的设备，恢复状态时用于重建尚未注册的设备
+type DeviceFactory func(name string) Device
+
+// State 返回电视机的当前状态
+func (t *TV) State() DeviceState {
+	return DeviceState{Name: t.name, Type: DeviceTypeTV, On: t.isOn, Volume: t.volume}
+}
+
+// Restore 静默恢复电视机状态，不会像遥控操作那样输出提示
+func (t *TV) Restore(state DeviceState) {
+	t.isOn = state.On
+	t.volume = clampVolume(state.Volume)
+}
+
+// IsOn 返回电视机是否开启
+func (t *TV) IsOn() bool {
+	return t.isOn
+}
+
+// State 返回收音机的当前状态
+func (r *Radio) State() DeviceState {
+	return DeviceState{Name: r.name, Type: DeviceTypeRadio, On: r.isOn, Volume: r.volume}
+}
+
+// Restore 静默恢复收音机状态，不会像遥控操作那样输出提示
+func (r *Radio) Restore(state DeviceState) {
+	r.isOn = state.On
+	r.volume = clampVolume(state.Volume)
+}
+
+// IsOn 返回收音机是否开启
+func (r *Radio) IsOn() bool {
+	return r.isOn
+}
+
+// clampVolume 将音量限制在0-100之间
+func clampVolume(volume int) int {
+	if volume < 0 {
+		return 0
+	}
+	if volume > 100 {
+		return 100
+	}
+	return volume
+}
+
+// registeredDevice 注册表中的一项
+type registeredDevice struct {
+	device Device
+	kind   string
+}
+
+// DeviceRegistry 设备注册表，模拟万能遥控器的配对列表
+// 设备按名称和类型注册，遥控器可以在运行时通过名称切换到任意已注册的设备
+type DeviceRegistry struct {
+	mutex     sync.RWMutex
+	devices   map[string]registeredDevice
+	factories map[string]DeviceFactory
+}
+
+// NewDeviceRegistry 创建设备注册表，内置电视机和收音机两种类型
+func NewDeviceRegistry() *DeviceRegistry {
+	return &DeviceRegistry{
+		devices: make(map[string]registeredDevice),
+		factories: map[string]DeviceFactory{
+			DeviceTypeTV:    func(name string) Device { return NewTV(name) },
+			DeviceTypeRadio: func(name string) Device { return NewRadio(name) },
+		},
+	}
+}
+
+// RegisterType 注册或替换一种设备类型的工厂函数
+func (r *DeviceRegistry) RegisterType(kind string, factory DeviceFactory) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.factories[kind] = factory
+}
+
+// Register 以设备名称注册设备，kind 为空时根据设备的状态推断类型
+func (r *DeviceRegistry) Register(device Device, kind string) error {
+	if kind == "" {
+		if sd, ok := device.(StatefulDevice); ok {
+			kind = sd.State().Type
+		}
+	}
+	if kind == "" {
+		return fmt.Errorf("%w: 无法推断 %s 的类型", ErrUnknownDeviceType, device.GetName())
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	name := device.GetName()
+	if _, exists := r.devices[name]; exists {
+		return fmt.Errorf("%w: %s", ErrDeviceExists, name)
+	}
+	r.devices[name] = registeredDevice{device: device, kind: kind}
+	return nil
+}
+
+// Create 使用已注册的工厂创建设备并注册
+func (r *DeviceRegistry) Create(kind, name string) (Device, error) {
+	r.mutex.RLock()
+	factory, ok := r.factories[kind]
+	r.mutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDeviceType, kind)
+	}
+
+	device := factory(name)
+	if err := r.Register(device, kind); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+// Unregister 注销设备，返回设备是否存在
+func (r *DeviceRegistry) Unregister(name string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.devices[name]; !exists {
+		return false
+	}
+	delete(r.devices, name)
+	return true
+}
+
+// Lookup 按名称查找设备
+func (r *DeviceRegistry) Lookup(name string) (Device, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	entry, ok := r.devices[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrDeviceNotFound, name)
+	}
+	return entry.device, nil
+}
+
+// Names 返回所有已注册设备的名称，按字典序排列
+func (r *DeviceRegistry) Names() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	names := make([]string, 0, len(r.devices))
+	for name := range r.devices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NamesByType 返回指定类型的设备名称，按字典序排列
+func (r *DeviceRegistry) NamesByType(kind string) []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	names := make([]string, 0)
+	for name, entry := range r.devices {
+		if entry.kind == kind {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SaveState 将所有可持久化设备的状态序列化为 JSON，设备按名称排序
+func (r *DeviceRegistry) SaveState() ([]byte, error) {
+	r.mutex.RLock()
+	states := make([]DeviceState, 0, len(r.devices))
+	for name, entry := range r.devices {
+		sd, ok := entry.device.(StatefulDevice)
+		if !ok {
+			continue
+		}
+		state := sd.State()
+		state.Name = name
+		state.Type = entry.kind
+		states = append(states, state)
+	}
+	r.mutex.RUnlock()
+
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return json.MarshalIndent(states, "", "  ")
+}
+
+// RestoreState 从 JSON 恢复设备状态
+// 已注册的设备直接恢复状态，未注册的设备先用对应类型的工厂创建并注册；
+// 任何一项无效时不做任何修改
+func (r *DeviceRegistry) RestoreState(data []byte) error {
+	var states []DeviceState
+	if err := json.Unmarshal(data, &states); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidDeviceState, err)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// 先校验全部状态，保证恢复是原子的
+	for _, state := range states {
+		if state.Name == "" {
+			return fmt.Errorf("%w: 设备名称为空", ErrInvalidDeviceState)
+		}
+		if entry, exists := r.devices[state.Name]; exists {
+			if entry.kind != state.Type {
+				return fmt.Errorf("%w: %s 的类型是 %s 而不是 %s",
+					ErrInvalidDeviceState, state.Name, entry.kind, state.Type)
+			}
+			if _, ok := entry.device.(StatefulDevice); !ok {
+				return fmt.Errorf("%w: %s 不支持恢复状态", ErrInvalidDeviceState, state.Name)
+			}
+			continue
+		}
+		if _, ok := r.factories[state.Type]; !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownDeviceType, state.Type)
+		}
+	}
+
+	for _, state := range states {
+		entry, exists := r.devices[state.Name]
+		if !exists {
+			entry = registeredDevice{device: r.factories[state.Type](state.Name), kind: state.Type}
+			r.devices[state.Name] = entry
+		}
+		if sd, ok := entry.device.(StatefulDevice); ok {
+			sd.Restore(state)
+		}
+	}
+	return nil
+}
+
+// SetRegistry 为遥控器关联设备注册表
+func (r *BaseRemoteControl) SetRegistry(registry *DeviceRegistry) {
+	r.registry = registry
+}
+
+// Attach 将遥控器重新绑定到注册表中的指定设备，就像万能遥控器切换配对的设备
+// 如果设备可以导出状态，遥控器的音量会同步为设备的当前音量
+func (r *BaseRemoteControl) Attach(name string) error {
+	if r.registry == nil {
+		return ErrNoRegistry
+	}
+	device, err := r.registry.Lookup(name)
+	if err != nil {
+		return err
+	}
+
+	r.device = device
+	if sd, ok := device.(StatefulDevice); ok {
+		r.volume = sd.State().Volume
+	}
+	fmt.Printf("遥控器已切换到 %s\n", device.GetName())
+	return nil
+}
+
+// Device 返回遥控器当前控制的设备
+func (r *BaseRemoteControl) Device() Device {
+	return r.device
+}
@@ -0,0 +1,153 @@
+package bridge
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// plainDevice 不支持状态导出的设备
+type plainDevice struct {
+	name string
+}
+
+func (p *plainDevice) TurnOn()         {}
+func (p *plainDevice) TurnOff()        {}
+func (p *plainDevice) SetVolume(int)   {}
+func (p *plainDevice) GetName() string { return p.name }
+
+// TestDeviceRegistry 测试设备的注册、查找和按类型筛选
+func TestDeviceRegistry(t *testing.T) {
+	assert := assert.New(t)
+	registry := NewDeviceRegistry()
+
+	assert.NoError(registry.Register(NewTV("客厅"), ""))
+	assert.NoError(registry.Register(NewRadio("厨房"), ""))
+	_, err := registry.Create(DeviceTypeTV, "卧室")
+	assert.NoError(err)
+
+	assert.ErrorIs(registry.Register(NewRadio("客厅"), ""), ErrDeviceExists)
+	assert.ErrorIs(registry.Register(&plainDevice{name: "投影仪"}, ""), ErrUnknownDeviceType,
+		"不支持状态导出的设备必须显式指定类型")
+	assert.NoError(registry.Register(&plainDevice{name: "投影仪"}, "projector"))
+	_, err = registry.Create("speaker", "音箱")
+	assert.ErrorIs(err, ErrUnknownDeviceType)
+
+	assert.Equal([]string{"卧室", "厨房", "客厅", "投影仪"}, registry.Names())
+	assert.Equal([]string{"卧室", "客厅"}, registry.NamesByType(DeviceTypeTV))
+	assert.Equal([]string{"厨房"}, registry.NamesByType(DeviceTypeRadio))
+
+	device, err := registry.Lookup("厨房")
+	assert.NoError(err)
+	assert.Equal("厨房", device.GetName())
+
+	assert.True(registry.Unregister("厨房"))
+	assert.False(registry.Unregister("厨房"))
+	_, err = registry.Lookup("厨房")
+	assert.ErrorIs(err, ErrDeviceNotFound)
+}
+
+// TestRemoteAttach 测试遥控器在运行时切换到不同的设备
+func TestRemoteAttach(t *testing.T) {
+	assert := assert.New(t)
+	registry := NewDeviceRegistry()
+	tv := NewTV("客厅")
+	radio := NewRadio("厨房")
+	assert.NoError(registry.Register(tv, ""))
+	assert.NoError(registry.Register(radio, ""))
+
+	remote := NewAdvancedRemoteControl(tv)
+	assert.ErrorIs(remote.Attach("厨房"), ErrNoRegistry)
+
+	remote.SetRegistry(registry)
+	output := captureOutput(func() {
+		assert.NoError(remote.Attach("厨房"))
+		remote.PowerOn()
+		remote.VolumeUp()
+	})
+
+	assert.Contains(output, "遥控器已切换到 厨房")
+	assert.Contains(output, "厨房 收音机打开了")
+	assert.Same(radio, remote.Device())
+	assert.True(radio.IsOn())
+	assert.False(tv.IsOn(), "切换后不应再控制原设备")
+	assert.Equal(15, radio.State().Volume, "遥控器音量应从设备的当前音量继续调节")
+
+	err := remote.Attach("阳台")
+	assert.ErrorIs(err, ErrDeviceNotFound)
+	assert.Same(radio, remote.Device(), "切换失败时保持原绑定")
+
+	captureOutput(func() {
+		assert.NoError(remote.Attach("客厅"))
+		remote.Mute()
+	})
+	assert.Equal(0, tv.State().Volume)
+}
+
+// TestRegistryStatePersistence 测试设备状态的保存与恢复
+func TestRegistryStatePersistence(t *testing.T) {
+	assert := assert.New(t)
+	registry := NewDeviceRegistry()
+	tv := NewTV("客厅")
+	assert.NoError(registry.Register(tv, ""))
+	assert.NoError(registry.Register(NewRadio("厨房"), ""))
+	assert.NoError(registry.Register(&plainDevice{name: "投影仪"}, "projector"))
+
+	captureOutput(func() {
+		tv.TurnOn()
+		tv.SetVolume(35)
+	})
+
+	data, err := registry.SaveState()
+	assert.NoError(err)
+
+	var states []DeviceState
+	assert.NoError(json.Unmarshal(data, &states))
+	assert.Equal([]DeviceState{
+		{Name: "厨房", Type: DeviceTypeRadio, On: false, Volume: 5},
+		{Name: "客厅", Type: DeviceTypeTV, On: true, Volume: 35},
+	}, states, "只保存支持状态导出的设备")
+
+	// 恢复到已有设备
+	captureOutput(func() {
+		tv.TurnOff()
+		tv.SetVolume(80)
+	})
+	assert.NoError(registry.RestoreState(data))
+	assert.True(tv.IsOn())
+	assert.Equal(35, tv.State().Volume)
+
+	// 恢复到新的注册表时按类型重建设备
+	restored := NewDeviceRegistry()
+	assert.NoError(restored.RestoreState(data))
+	assert.Equal([]string{"厨房", "客厅"}, restored.Names())
+	device, err := restored.Lookup("客厅")
+	assert.NoError(err)
+	assert.Equal(tv.State(), device.(StatefulDevice).State())
+}
+
+// TestRegistryRestoreIsAtomic 测试无效的状态不会部分生效
+func TestRegistryRestoreIsAtomic(t *testing.T) {
+	assert := assert.New(t)
+	registry := NewDeviceRegistry()
+	tv := NewTV("客厅")
+	assert.NoError(registry.Register(tv, ""))
+
+	err := registry.RestoreState([]byte(`[
+		{"name": "客厅", "type": "tv", "on": true, "volume": 50},
+		{"name": "阳台", "type": "speaker", "on": true, "volume": 50}
+	]`))
+	assert.ErrorIs(err, ErrUnknownDeviceType)
+	assert.False(tv.IsOn(), "校验失败时不应修改任何设备")
+	assert.Equal([]string{"客厅"}, registry.Names())
+
+	err = registry.RestoreState([]byte(`[{"name": "客厅", "type": "radio"}]`))
+	assert.ErrorIs(err, ErrInvalidDeviceState, "类型不匹配")
+	assert.ErrorIs(registry.RestoreState([]byte(`[{"type": "tv"}]`)), ErrInvalidDeviceState)
+	assert.ErrorIs(registry.RestoreState([]byte(`not json`)), ErrInvalidDeviceState)
+
+	// 超出范围的音量会被限制
+	assert.NoError(registry.RestoreState([]byte(`[{"name": "客厅", "type": "tv", "volume": 300}]`)))
+	assert.Equal(100, tv.State().Volume)
+}
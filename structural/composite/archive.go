@@ -0,0 +1,314 @@
+package composite
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+)
+
+// 归档导入导出相关错误
+var (
+	ErrInvalidName  = errors.New("组件名称不能作为归档路径")
+	ErrUnsafePath   = errors.New("归档条目路径不安全")
+	ErrEntryExists  = errors.New("归档条目与已有组件冲突")
+	ErrNotDirectory = errors.New("归档条目的上级路径不是目录")
+)
+
+// archiveModTime 写入归档条目的修改时间
+// 内存中的组件没有时间信息，使用固定时间保证同一棵树总是生成相同的归档
+var archiveModTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// archiveEntry 归档中的一个条目，路径相对于导出的目录
+type archiveEntry struct {
+	name  string // 目录以 "/" 结尾
+	isDir bool
+	data  []byte
+}
+
+// collectEntries 按深度优先顺序收集目录下所有组件对应的归档条目，目录条目总在其内容之前
+func (d *Directory) collectEntries(prefix string, entries []archiveEntry) ([]archiveEntry, error) {
+	for _, child := range d.children {
+		name := child.Name()
+		if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidName, name)
+		}
+
+		switch c := child.(type) {
+		case *Directory:
+			entries = append(entries, archiveEntry{name: prefix + name + "/", isDir: true})
+			var err error
+			if entries, err = c.collectEntries(prefix+name+"/", entries); err != nil {
+				return nil, err
+			}
+		case *File:
+			entries = append(entries, archiveEntry{name: prefix + name, data: fileData(c)})
+		default:
+			// 其他叶子组件只能导出名称和大小
+			entries = append(entries, archiveEntry{name: prefix + name, data: make([]byte, child.Size())})
+		}
+	}
+	return entries, nil
+}
+
+// fileData 返回文件写入归档的内容
+// 只声明了大小、没有设置内容的文件以零字节填充，保证归档中的文件大小与 Size() 一致
+func fileData(f *File) []byte {
+	if f.content == "" && f.size > 0 {
+		return make([]byte, f.size)
+	}
+	return []byte(f.content)
+}
+
+// WriteZip 将目录下的层次结构导出为 zip 归档，条目路径相对于该目录（不包含目录自身）
+// 空目录也会以目录条目的形式保留
+func (d *Directory) WriteZip(w io.Writer) error {
+	entries, err := d.collectEntries("", nil)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	for _, entry := range entries {
+		header := &zip.FileHeader{
+			Name:     entry.name,
+			Method:   zip.Deflate,
+			Modified: archiveModTime,
+		}
+		if entry.isDir {
+			header.Method = zip.Store
+			header.SetMode(fs.ModeDir | 0o755)
+		} else {
+			header.SetMode(0o644)
+		}
+
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("写入 zip 条目 %s 失败: %w", entry.name, err)
+		}
+		if _, err := fw.Write(entry.data); err != nil {
+			return fmt.Errorf("写入 zip 条目 %s 失败: %w", entry.name, err)
+		}
+	}
+	return zw.Close()
+}
+
+// WriteTar 将目录下的层次结构导出为 tar 归档，规则与 WriteZip 相同
+func (d *Directory) WriteTar(w io.Writer) error {
+	entries, err := d.collectEntries("", nil)
+	if err != nil {
+		return err
+	}
+
+	tw := tar.NewWriter(w)
+	for _, entry := range entries {
+		header := &tar.Header{
+			Name:     entry.name,
+			Typeflag: tar.TypeReg,
+			Mode:     0o644,
+			Size:     int64(len(entry.data)),
+			ModTime:  archiveModTime,
+			Format:   tar.FormatPAX,
+		}
+		if entry.isDir {
+			header.Typeflag = tar.TypeDir
+			header.Mode = 0o755
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("写入 tar 条目 %s 失败: %w", entry.name, err)
+		}
+		if _, err := tw.Write(entry.data); err != nil {
+			return fmt.Errorf("写入 tar 条目 %s 失败: %w", entry.name, err)
+		}
+	}
+	return tw.Close()
+}
+
+// ReadZip 将 zip 归档中的文件和目录导入到当前目录下
+// 归档中的目录与已有的同名目录合并；与已有文件同名、或路径不安全（绝对路径、包含 ..）时返回错误，
+// 出错时当前目录保持不变
+func (d *Directory) ReadZip(r io.ReaderAt, size int64) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("读取 zip 归档失败: %w", err)
+	}
+
+	staging := NewDirectory(d.name)
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			if err := staging.stageEntry(f.Name, true, nil); err != nil {
+				return err
+			}
+			continue
+		}
+		if !f.Mode().IsRegular() {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("读取 zip 条目 %s 失败: %w", f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("读取 zip 条目 %s 失败: %w", f.Name, err)
+		}
+		if err := staging.stageEntry(f.Name, false, data); err != nil {
+			return err
+		}
+	}
+	return d.merge(staging)
+}
+
+// ReadTar 将 tar 归档中的文件和目录导入到当前目录下，规则与 ReadZip 相同
+// 符号链接等其他类型的条目会被跳过
+func (d *Directory) ReadTar(r io.Reader) error {
+	tr := tar.NewReader(r)
+	staging := NewDirectory(d.name)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("读取 tar 归档失败: %w", err)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = staging.stageEntry(header.Name, true, nil)
+		case tar.TypeReg:
+			var buf bytes.Buffer
+			if _, err = io.Copy(&buf, tr); err != nil {
+				return fmt.Errorf("读取 tar 条目 %s 失败: %w", header.Name, err)
+			}
+			err = staging.stageEntry(header.Name, false, buf.Bytes())
+		default:
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return d.merge(staging)
+}
+
+// splitArchivePath 校验并拆分归档条目路径
+func splitArchivePath(name string) ([]string, error) {
+	slashed := strings.ReplaceAll(name, `\`, "/")
+	trimmed := strings.TrimSuffix(slashed, "/")
+	if trimmed == "" || strings.HasPrefix(slashed, "/") {
+		return nil, fmt.Errorf("%w: %q", ErrUnsafePath, name)
+	}
+	for _, part := range strings.Split(trimmed, "/") {
+		if part == ".." {
+			return nil, fmt.Errorf("%w: %q", ErrUnsafePath, name)
+		}
+	}
+
+	cleaned := path.Clean(trimmed)
+	if cleaned == "." {
+		return nil, fmt.Errorf("%w: %q", ErrUnsafePath, name)
+	}
+	return strings.Split(cleaned, "/"), nil
+}
+
+// stageEntry 在暂存目录中创建条目及其所有上级目录
+func (d *Directory) stageEntry(name string, isDir bool, data []byte) error {
+	parts, err := splitArchivePath(name)
+	if err != nil {
+		return err
+	}
+
+	dir := d
+	for _, part := range parts[:len(parts)-1] {
+		if dir, err = dir.subdirectory(part, name); err != nil {
+			return err
+		}
+	}
+
+	last := parts[len(parts)-1]
+	if isDir {
+		_, err = dir.subdirectory(last, name)
+		return err
+	}
+	if dir.childNamed(last) != nil {
+		return fmt.Errorf("%w: %s", ErrEntryExists, name)
+	}
+	file := NewFile(last, 0)
+	file.SetContent(string(data))
+	dir.Add(file)
+	return nil
+}
+
+// subdirectory 返回指定名称的子目录，不存在时创建；同名组件不是目录时返回错误
+func (d *Directory) subdirectory(name, entry string) (*Directory, error) {
+	child := d.childNamed(name)
+	if child == nil {
+		dir := NewDirectory(name)
+		d.Add(dir)
+		return dir, nil
+	}
+	dir, ok := child.(*Directory)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotDirectory, entry)
+	}
+	return dir, nil
+}
+
+// childNamed 按名称查找直接子组件
+func (d *Directory) childNamed(name string) Component {
+	for _, child := range d.children {
+		if child.Name() == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// merge 把暂存目录的内容合并到当前目录，先检查冲突，确保出错时不做任何修改
+func (d *Directory) merge(staging *Directory) error {
+	if err := d.checkMerge(staging, ""); err != nil {
+		return err
+	}
+	d.applyMerge(staging)
+	return nil
+}
+
+// checkMerge 检查合并是否会产生冲突
+func (d *Directory) checkMerge(staging *Directory, prefix string) error {
+	for _, child := range staging.children {
+		existing := d.childNamed(child.Name())
+		if existing == nil {
+			continue
+		}
+		incoming, incomingIsDir := child.(*Directory)
+		current, currentIsDir := existing.(*Directory)
+		if !incomingIsDir || !currentIsDir {
+			return fmt.Errorf("%w: %s%s", ErrEntryExists, prefix, child.Name())
+		}
+		if err := current.checkMerge(incoming, prefix+child.Name()+"/"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyMerge 执行已检查过的合并，同名目录递归合并，其余组件直接移入
+func (d *Directory) applyMerge(staging *Directory) {
+	for _, child := range staging.children {
+		if current, ok := d.childNamed(child.Name()).(*Directory); ok {
+			current.applyMerge(child.(*Directory))
+			continue
+		}
+		d.Add(child)
+	}
+}
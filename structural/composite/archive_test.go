@@ -0,0 +1,246 @@
+package composite
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// buildArchiveTree 构建用于归档测试的目录树
+//
+//	root/
+//	  readme.txt
+//	  docs/
+//	    guide.md
+//	    images/        (空目录)
+//	  src/
+//	    main.go
+//	    blob.bin       (只声明大小)
+func buildArchiveTree() *Directory {
+	root := NewDirectory("root")
+
+	readme := NewFile("readme.txt", 0)
+	readme.SetContent("hello composite")
+	root.Add(readme)
+
+	docs := NewDirectory("docs")
+	guide := NewFile("guide.md", 0)
+	guide.SetContent("# 指南")
+	docs.Add(guide)
+	docs.Add(NewDirectory("images"))
+	root.Add(docs)
+
+	src := NewDirectory("src")
+	main := NewFile("main.go", 0)
+	main.SetContent("package main")
+	src.Add(main)
+	src.Add(NewFile("blob.bin", 8))
+	root.Add(src)
+
+	return root
+}
+
+// listEntries 以 "路径 -> 内容" 的形式列出目录树
+func listEntries(d *Directory, prefix string, out map[string]string) map[string]string {
+	if out == nil {
+		out = make(map[string]string)
+	}
+	for _, child := range d.Children() {
+		switch c := child.(type) {
+		case *Directory:
+			out[prefix+c.Name()+"/"] = ""
+			listEntries(c, prefix+c.Name()+"/", out)
+		case *File:
+			out[prefix+c.Name()] = c.GetContent()
+		}
+	}
+	return out
+}
+
+// TestZipRoundTrip 测试 zip 导出后能被标准库读取并完整导入
+func TestZipRoundTrip(t *testing.T) {
+	root := buildArchiveTree()
+
+	var buf bytes.Buffer
+	if err := root.WriteZip(&buf); err != nil {
+		t.Fatalf("意外的错误: %v", err)
+	}
+
+	// 标准库可以读取，目录条目先于其内容
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("意外的错误: %v", err)
+	}
+	names := make([]string, 0, len(zr.File))
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, []string{
+		"readme.txt", "docs/", "docs/guide.md", "docs/images/", "src/", "src/main.go", "src/blob.bin",
+	}, names)
+	assert.True(t, zr.File[1].FileInfo().IsDir())
+	assert.Equal(t, uint64(8), zr.File[6].UncompressedSize64, "只声明大小的文件应按大小填充")
+
+	imported := NewDirectory("copy")
+	if err := imported.ReadZip(bytes.NewReader(buf.Bytes()), int64(buf.Len())); err != nil {
+		t.Fatalf("意外的错误: %v", err)
+	}
+
+	expected := listEntries(root, "", nil)
+	expected["src/blob.bin"] = string(make([]byte, 8))
+	assert.Equal(t, expected, listEntries(imported, "", nil))
+	assert.Equal(t, root.Size(), imported.Size())
+
+	files, dirs := imported.Count()
+	assert.Equal(t, 4, files)
+	assert.Equal(t, 3, dirs)
+
+	guide := imported.Find("guide.md")
+	if len(guide) != 1 {
+		t.Fatalf("应找到 1 个组件，实际为 %d", len(guide))
+	}
+	assert.Equal(t, "/copy/docs/guide.md", guide[0].Path())
+}
+
+// TestTarRoundTrip 测试 tar 导出与导入
+func TestTarRoundTrip(t *testing.T) {
+	root := buildArchiveTree()
+
+	var buf bytes.Buffer
+	if err := root.WriteTar(&buf); err != nil {
+		t.Fatalf("意外的错误: %v", err)
+	}
+
+	tr := tar.NewReader(bytes.NewReader(buf.Bytes()))
+	types := make(map[string]byte)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("意外的错误: %v", err)
+		}
+		types[header.Name] = header.Typeflag
+	}
+	assert.Equal(t, byte(tar.TypeDir), types["docs/images/"])
+	assert.Equal(t, byte(tar.TypeReg), types["docs/guide.md"])
+
+	imported := NewDirectory("copy")
+	if err := imported.ReadTar(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatalf("意外的错误: %v", err)
+	}
+
+	expected := listEntries(root, "", nil)
+	expected["src/blob.bin"] = string(make([]byte, 8))
+	assert.Equal(t, expected, listEntries(imported, "", nil))
+
+	// 相同的树总是生成相同的归档
+	var again bytes.Buffer
+	if err := buildArchiveTree().WriteTar(&again); err != nil {
+		t.Fatalf("意外的错误: %v", err)
+	}
+	assert.Equal(t, buf.Bytes(), again.Bytes())
+}
+
+// TestReadTarMergesAndSkipsLinks 测试导入时与已有目录合并、自动补全上级目录并跳过符号链接
+func TestReadTarMergesAndSkipsLinks(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	writeTarFile(t, tw, "docs/extra.md", "额外文档")
+	writeTarFile(t, tw, "./deep/nested/file.txt", "深层文件") // 没有显式的目录条目
+	if err := tw.WriteHeader(&tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "docs"}); err != nil {
+		t.Fatalf("意外的错误: %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("意外的错误: %v", err)
+	}
+
+	root := buildArchiveTree()
+	if err := root.ReadTar(&buf); err != nil {
+		t.Fatalf("意外的错误: %v", err)
+	}
+
+	entries := listEntries(root, "", nil)
+	assert.Equal(t, "额外文档", entries["docs/extra.md"])
+	assert.Equal(t, "# 指南", entries["docs/guide.md"], "已有文件保持不变")
+	assert.Equal(t, "深层文件", entries["deep/nested/file.txt"])
+	assert.NotContains(t, entries, "link")
+
+	docs := root.Find("docs")
+	if len(docs) != 1 {
+		t.Fatalf("应找到 1 个组件，实际为 %d", len(docs))
+	}
+	assert.Len(t, docs[0].Children(), 3, "同名目录应合并而不是重复创建")
+}
+
+// TestReadArchiveRejectsUnsafeAndConflicting 测试不安全路径和冲突条目会被拒绝，且不修改目录
+func TestReadArchiveRejectsUnsafeAndConflicting(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries map[string]string
+		err     error
+	}{
+		{"上级目录", map[string]string{"../evil.txt": "x"}, ErrUnsafePath},
+		{"嵌套上级目录", map[string]string{"docs/../../evil.txt": "x"}, ErrUnsafePath},
+		{"绝对路径", map[string]string{"/etc/passwd": "x"}, ErrUnsafePath},
+		{"与已有文件冲突", map[string]string{"readme.txt": "覆盖"}, ErrEntryExists},
+		{"文件作为目录", map[string]string{"readme.txt/inner": "x"}, ErrEntryExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			zw := zip.NewWriter(&buf)
+			// 先写一个合法条目，验证失败时它也不会被导入
+			w, _ := zw.Create("ok/new.txt")
+			io.WriteString(w, "new")
+			for name, content := range tt.entries {
+				w, err := zw.Create(name)
+				if err != nil {
+					t.Fatalf("意外的错误: %v", err)
+				}
+				io.WriteString(w, content)
+			}
+			if err := zw.Close(); err != nil {
+				t.Fatalf("意外的错误: %v", err)
+			}
+
+			root := buildArchiveTree()
+			before := listEntries(root, "", nil)
+			err := root.ReadZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, before, listEntries(root, "", nil), "出错时目录应保持不变")
+		})
+	}
+}
+
+// TestWriteArchiveInvalidName 测试名称无法映射为归档路径时导出失败
+func TestWriteArchiveInvalidName(t *testing.T) {
+	root := NewDirectory("root")
+	root.Add(NewFile("a/b.txt", 1))
+
+	assert.ErrorIs(t, root.WriteZip(io.Discard), ErrInvalidName)
+	assert.ErrorIs(t, root.WriteTar(io.Discard), ErrInvalidName)
+}
+
+// writeTarFile 向 tar 归档写入一个普通文件
+func writeTarFile(t *testing.T, tw *tar.Writer, name, content string) {
+	t.Helper()
+	err := tw.WriteHeader(&tar.Header{
+		Name:     name,
+		Typeflag: tar.TypeReg,
+		Mode:     0o644,
+		Size:     int64(len(content)),
+	})
+	if err == nil {
+		_, err = io.WriteString(tw, content)
+	}
+	if err != nil {
+		t.Fatalf("写入 tar 条目 %s 失败: %v", name, err)
+	}
+}
@@ -194,34 +194,33 @@ func (d *Directory) Print(indent string) {
 	}
 }
 
-// Size 计算目录总大小（包括所有子组件）
+// Size 计算目录总大小（包括所有子组件），符号链接指向的内容也会计入，失效或形成环的链接被跳过
 func (d *Directory) Size() int {
-	total := 0
-	for _, child := range d.children {
-		total += child.Size()
-	}
+	total, _ := d.TotalSize(IgnoreLinkErrors())
 	return total
 }
 
-// Find 在目录中查找组件（支持通配符）
-func (d *Directory) Find(pattern string) []Component {
+// Find 在目录中查找名称包含 pattern 的组件（不区分大小写）
+// 默认跟随符号链接继续查找，通过链接找到的组件按链接指向的目标返回，同一组件只返回一次；
+// 失效或形成环的链接不再继续查找，名称匹配时返回链接本身；使用 NoFollow 时所有链接都按链接本身返回
+func (d *Directory) Find(pattern string, opts ...WalkOption) []Component {
 	results := []Component{}
+	seen := make(map[Component]bool)
+	pattern = strings.ToLower(pattern)
 
-	// 递归搜索所有子组件
-	for _, child := range d.children {
-		// 检查当前组件是否匹配
-		if strings.Contains(strings.ToLower(child.Name()), strings.ToLower(pattern)) {
-			results = append(results, child)
+	opts = append([]WalkOption{IgnoreLinkErrors()}, opts...)
+	d.Walk(func(p string, c Component) error {
+		if c == Component(d) {
+			return nil
 		}
-
-		// 如果是组合对象，继续递归搜索
-		if child.IsComposite() {
-			if dir, ok := child.(*Directory); ok {
-				childResults := dir.Find(pattern)
-				results = append(results, childResults...)
-			}
+		// 使用路径中的条目名称匹配，通过链接访问时是链接的名称
+		name := p[strings.LastIndex(p, "/")+1:]
+		if strings.Contains(strings.ToLower(name), pattern) && !seen[c] {
+			seen[c] = true
+			results = append(results, c)
 		}
-	}
+		return nil
+	}, opts...)
 
 	return results
 }
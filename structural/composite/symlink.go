@@ -0,0 +1,241 @@
+package composite
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 符号链接相关错误
+var (
+	ErrCycleDetected = errors.New("符号链接形成环")
+	ErrBrokenLink    = errors.New("符号链接的目标不存在")
+)
+
+// maxLinkHops 解析一个链接时最多跟随的链接数量，超过时视为环（与 Linux 的 ELOOP 类似）
+const maxLinkHops = 40
+
+// Symlink 表示指向树中另一条路径的符号链接，是叶子节点
+//
+// 目标路径以 "/" 开头时从根目录开始解析，格式与 Path() 相同，例如 "/root/docs/a.txt"；
+// 否则相对于链接所在的目录解析，支持 "." 和 ".."。链接只保存路径，目标被移动或删除后链接会失效
+type Symlink struct {
+	BaseComponent
+	target string
+}
+
+// NewSymlink 创建指向 target 的符号链接
+func NewSymlink(name, target string) *Symlink {
+	return &Symlink{
+		BaseComponent: NewBaseComponent(name),
+		target:        target,
+	}
+}
+
+// Target 返回链接保存的目标路径
+func (s *Symlink) Target() string {
+	return s.target
+}
+
+// IsComposite 符号链接不是组合对象
+func (s *Symlink) IsComposite() bool {
+	return false
+}
+
+// Print 打印链接及其目标路径，不跟随链接
+func (s *Symlink) Print(indent string) {
+	fmt.Printf("%s@ %s -> %s\n", indent, s.name, s.target)
+}
+
+// Size 返回链接目标的大小，链接失效或形成环时返回0
+func (s *Symlink) Size() int {
+	target, err := s.Resolve()
+	if err != nil {
+		return 0
+	}
+	return target.Size()
+}
+
+// Resolve 解析链接，返回最终指向的非链接组件
+// 目标路径中间和末尾的链接都会被继续解析，跟随次数超过限制时返回 ErrCycleDetected
+func (s *Symlink) Resolve() (Component, error) {
+	hops := 0
+	return s.resolve(&hops)
+}
+
+// resolve 解析链接，hops 记录本次解析已跟随的链接数量
+func (s *Symlink) resolve(hops *int) (Component, error) {
+	if *hops++; *hops > maxLinkHops {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrCycleDetected, s.Path(), s.target)
+	}
+
+	var current Component
+	segments := strings.Split(s.target, "/")
+	if strings.HasPrefix(s.target, "/") {
+		// 绝对路径的第一段是根目录的名称
+		current = s.root()
+		if len(segments) < 2 || segments[1] != current.Name() {
+			return nil, fmt.Errorf("%w: %s -> %s", ErrBrokenLink, s.Path(), s.target)
+		}
+		segments = segments[2:]
+	} else if current = s.parent; current == nil {
+		return nil, fmt.Errorf("%w: %s 不在任何目录中", ErrBrokenLink, s.name)
+	}
+
+	for _, segment := range segments {
+		// 中间的链接先解析为实际的目录
+		if link, ok := current.(*Symlink); ok {
+			var err error
+			if current, err = link.resolve(hops); err != nil {
+				return nil, err
+			}
+		}
+
+		switch segment {
+		case "", ".":
+			continue
+		case "..":
+			if current.Parent() != nil {
+				current = current.Parent()
+			}
+			continue
+		}
+
+		dir, ok := current.(*Directory)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s -> %s", ErrBrokenLink, s.Path(), s.target)
+		}
+		if current = dir.childNamed(segment); current == nil {
+			return nil, fmt.Errorf("%w: %s -> %s", ErrBrokenLink, s.Path(), s.target)
+		}
+	}
+
+	if link, ok := current.(*Symlink); ok {
+		return link.resolve(hops)
+	}
+	return current, nil
+}
+
+// root 返回链接所在树的根组件
+func (s *Symlink) root() Component {
+	var root Component = s
+	for root.Parent() != nil {
+		root = root.Parent()
+	}
+	return root
+}
+
+// WalkFunc 遍历时对每个组件调用的函数，path 是经过链接的逻辑路径
+// 跟随链接时 c 是链接指向的目标；不跟随链接或忽略链接错误时 c 是链接本身
+// 返回非 nil 错误会终止遍历，Walk 原样返回该错误
+type WalkFunc func(path string, c Component) error
+
+// walkOptions 遍历选项
+type walkOptions struct {
+	noFollow         bool
+	ignoreLinkErrors bool
+}
+
+// WalkOption 遍历的配置选项，适用于 Walk、Find 和 TotalSize
+type WalkOption func(*walkOptions)
+
+// NoFollow 不跟随符号链接，链接作为叶子节点出现在遍历中，不计入大小
+func NoFollow() WalkOption {
+	return func(o *walkOptions) {
+		o.noFollow = true
+	}
+}
+
+// IgnoreLinkErrors 跳过失效或形成环的链接，不返回错误，链接本身作为叶子节点出现在遍历中
+func IgnoreLinkErrors() WalkOption {
+	return func(o *walkOptions) {
+		o.ignoreLinkErrors = true
+	}
+}
+
+// walker 一次遍历的状态
+type walker struct {
+	fn      WalkFunc
+	options walkOptions
+	stack   []*Directory // 当前路径上正在遍历的目录，用于检测环
+}
+
+// Walk 按深度优先的先序遍历目录（包括目录自身）及其所有子组件
+//
+// 默认跟随符号链接：链接指向的目录会被继续遍历，链接指向当前路径上的祖先目录时返回 ErrCycleDetected，
+// 链接失效时返回 ErrBrokenLink。同一组件可能通过不同的链接被访问多次
+func (d *Directory) Walk(fn WalkFunc, opts ...WalkOption) error {
+	w := &walker{fn: fn}
+	for _, opt := range opts {
+		opt(&w.options)
+	}
+	return w.walk(d.Path(), d)
+}
+
+// walk 遍历组件 c 及其子组件
+func (w *walker) walk(path string, c Component) error {
+	if link, ok := c.(*Symlink); ok && !w.options.noFollow {
+		target, err := w.follow(path, link)
+		if err != nil {
+			if w.options.ignoreLinkErrors {
+				return w.fn(path, link)
+			}
+			return err
+		}
+		c = target
+	}
+
+	if err := w.fn(path, c); err != nil {
+		return err
+	}
+
+	dir, ok := c.(*Directory)
+	if !ok {
+		return nil
+	}
+	w.stack = append(w.stack, dir)
+	defer func() { w.stack = w.stack[:len(w.stack)-1] }()
+
+	for _, child := range dir.children {
+		if err := w.walk(path+"/"+child.Name(), child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// follow 解析遍历中遇到的链接，目标是当前路径上的目录时返回 ErrCycleDetected
+func (w *walker) follow(path string, link *Symlink) (Component, error) {
+	target, err := link.Resolve()
+	if err != nil {
+		return nil, err
+	}
+	if dir, ok := target.(*Directory); ok {
+		for _, visiting := range w.stack {
+			if visiting == dir {
+				return nil, fmt.Errorf("%w: %s -> %s", ErrCycleDetected, path, link.target)
+			}
+		}
+	}
+	return target, nil
+}
+
+// TotalSize 计算目录总大小，跟随链接时链接目标的大小也会计入，遇到失效或形成环的链接时返回错误
+// 链接本身不占用大小；Size() 等价于忽略链接错误的 TotalSize
+func (d *Directory) TotalSize(opts ...WalkOption) (int, error) {
+	total := 0
+	err := d.Walk(func(path string, c Component) error {
+		if _, ok := c.(*Directory); ok {
+			return nil
+		}
+		if _, ok := c.(*Symlink); ok {
+			return nil
+		}
+		total += c.Size()
+		return nil
+	}, opts...)
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
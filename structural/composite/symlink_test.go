@@ -0,0 +1,213 @@
+package composite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newLinkedTree 创建带有符号链接的测试目录树
+//
+//	/root
+//	  docs/
+//	    a.txt (100)
+//	    latest -> a.txt
+//	  photos/
+//	    p.jpg (1000)
+//	    docs -> /root/docs
+//	  shortcut -> photos/p.jpg
+func newLinkedTree() (root, docs, photos *Directory) {
+	root = NewDirectory("root")
+	docs = NewDirectory("docs")
+	photos = NewDirectory("photos")
+	root.Add(docs)
+	root.Add(photos)
+
+	docs.Add(NewFile("a.txt", 100))
+	docs.Add(NewSymlink("latest", "a.txt"))
+	photos.Add(NewFile("p.jpg", 1000))
+	photos.Add(NewSymlink("docs", "/root/docs"))
+	root.Add(NewSymlink("shortcut", "photos/p.jpg"))
+	return root, docs, photos
+}
+
+// 测试链接解析
+func TestSymlinkResolve(t *testing.T) {
+	assert := assert.New(t)
+	root, docs, photos := newLinkedTree()
+	a := docs.GetChild(0)
+
+	tests := []struct {
+		name   string
+		parent *Directory
+		target string
+		want   Component
+	}{
+		{"absolute", root, "/root/docs/a.txt", a},
+		{"relative", docs, "a.txt", a},
+		{"dot-dot", photos, "../docs/./a.txt", a},
+		{"through link", root, "photos/docs/a.txt", a},
+		{"chain", root, "docs/latest", a},
+		{"directory", root, "/root/photos", photos},
+		{"above root", root, "../../docs", docs},
+	}
+	for _, tt := range tests {
+		link := NewSymlink(tt.name, tt.target)
+		tt.parent.Add(link)
+		got, err := link.Resolve()
+		assert.NoError(err, tt.name)
+		assert.Same(tt.want, got, tt.name)
+		tt.parent.Remove(link)
+	}
+
+	assert.Equal(100, docs.GetChild(1).Size(), "链接的大小是目标的大小")
+
+	broken := []string{"missing.txt", "/other/docs", "/root/docs/a.txt/x", "/root/nothing/a.txt"}
+	for _, target := range broken {
+		link := NewSymlink("broken", target)
+		root.Add(link)
+		_, err := link.Resolve()
+		assert.ErrorIs(err, ErrBrokenLink, target)
+		assert.Equal(0, link.Size())
+		root.Remove(link)
+	}
+
+	_, err := NewSymlink("orphan", "a.txt").Resolve()
+	assert.ErrorIs(err, ErrBrokenLink, "不在目录中的相对链接无法解析")
+}
+
+// 测试互相指向的链接
+func TestSymlinkLoop(t *testing.T) {
+	assert := assert.New(t)
+	root := NewDirectory("root")
+	ping := NewSymlink("ping", "pong")
+	pong := NewSymlink("pong", "/root/ping")
+	self := NewSymlink("self", "self/x")
+	root.Add(ping)
+	root.Add(pong)
+	root.Add(self)
+
+	for _, link := range []*Symlink{ping, pong, self} {
+		_, err := link.Resolve()
+		assert.ErrorIs(err, ErrCycleDetected, link.Name())
+		assert.Equal(0, link.Size())
+	}
+	assert.Equal(0, root.Size())
+
+	_, err := root.TotalSize()
+	assert.ErrorIs(err, ErrCycleDetected)
+}
+
+// 测试遍历时跟随链接和不跟随链接
+func TestWalk(t *testing.T) {
+	assert := assert.New(t)
+	root, _, _ := newLinkedTree()
+
+	var followed []string
+	assert.NoError(root.Walk(func(path string, c Component) error {
+		followed = append(followed, path)
+		return nil
+	}))
+	assert.Equal([]string{
+		"/root",
+		"/root/docs", "/root/docs/a.txt", "/root/docs/latest",
+		"/root/photos", "/root/photos/p.jpg",
+		"/root/photos/docs", "/root/photos/docs/a.txt", "/root/photos/docs/latest",
+		"/root/shortcut",
+	}, followed)
+
+	var links []string
+	assert.NoError(root.Walk(func(path string, c Component) error {
+		if link, ok := c.(*Symlink); ok {
+			links = append(links, path+" -> "+link.Target())
+		}
+		return nil
+	}, NoFollow()))
+	assert.Equal([]string{
+		"/root/docs/latest -> a.txt",
+		"/root/photos/docs -> /root/docs",
+		"/root/shortcut -> photos/p.jpg",
+	}, links)
+
+	size, err := root.TotalSize()
+	assert.NoError(err)
+	assert.Equal(100+100+1000+100+100+1000, size, "通过链接访问的内容重复计入")
+	size, err = root.TotalSize(NoFollow())
+	assert.NoError(err)
+	assert.Equal(1100, size, "不跟随链接时链接不占用大小")
+	assert.Equal(2400, root.Size())
+}
+
+// 测试遍历指向祖先目录的链接
+func TestWalkCycle(t *testing.T) {
+	assert := assert.New(t)
+	root, docs, photos := newLinkedTree()
+	// docs/back -> /root/photos，photos/docs -> /root/docs，两个链接形成环
+	docs.Add(NewSymlink("back", "/root/photos"))
+
+	visited := 0
+	err := root.Walk(func(path string, c Component) error {
+		visited++
+		return nil
+	})
+	assert.ErrorIs(err, ErrCycleDetected)
+	assert.Less(visited, 20, "检测到环后应立即停止")
+
+	var skipped []string
+	assert.NoError(root.Walk(func(path string, c Component) error {
+		if _, ok := c.(*Symlink); ok {
+			skipped = append(skipped, path)
+		}
+		return nil
+	}, IgnoreLinkErrors()))
+	assert.Equal([]string{"/root/docs/back/docs", "/root/photos/docs/back"}, skipped)
+
+	// 删除环后改为链接到根目录
+	docs.Remove(docs.GetChild(2))
+	photos.Add(NewSymlink("up", ".."))
+	_, err = root.TotalSize()
+	assert.ErrorIs(err, ErrCycleDetected)
+	_, err = photos.TotalSize()
+	assert.ErrorIs(err, ErrCycleDetected, "经由根目录回到 photos 同样是环")
+	size, err := docs.TotalSize()
+	assert.NoError(err)
+	assert.Equal(200, size)
+	assert.Greater(root.Size(), 0, "Size 跳过形成环的链接")
+}
+
+// 测试通过链接查找
+func TestFindThroughLinks(t *testing.T) {
+	assert := assert.New(t)
+	root, docs, photos := newLinkedTree()
+	a := docs.GetChild(0)
+	p := photos.GetChild(0)
+
+	results := root.Find("a.txt")
+	assert.Equal([]Component{a}, results, "同一组件只返回一次")
+
+	results = root.Find("shortcut")
+	assert.Equal([]Component{p}, results, "通过链接名称找到的是链接目标")
+
+	results = root.Find("shortcut", NoFollow())
+	if assert.Len(results, 1) {
+		assert.IsType(&Symlink{}, results[0])
+	}
+
+	photos.Add(NewSymlink("loop", "/root"))
+	photos.Add(NewSymlink("dead", "nowhere"))
+	assert.Len(root.Find("loop"), 1, "形成环的链接返回链接本身")
+	assert.Len(root.Find("dead"), 1)
+	assert.Len(root.Find("p.jpg"), 1)
+}
+
+// 测试打印不跟随链接
+func TestSymlinkPrint(t *testing.T) {
+	root, _, _ := newLinkedTree()
+	root.Add(NewSymlink("loop", "/root"))
+
+	output := captureOutput(func() {
+		root.Print("")
+	})
+	assert.Contains(t, output, "  @ shortcut -> photos/p.jpg\n")
+	assert.Contains(t, output, "  @ loop -> /root\n")
+}
@@ -3,6 +3,7 @@ package flyweight
 import (
 	"fmt"
 	"strconv"
+	"time"
 )
 
 // Dress 是享元接口，定义了所有具体享元类需要实现的方法
@@ -89,13 +90,21 @@ const (
 type DressFactory struct {
 	dresses map[string]Dress // 享元对象池
 	count   map[string]int   // 跟踪每种皮肤使用次数
+
+	releasedAt  map[string]time.Time // 引用计数降为0的时间
+	gracePeriod time.Duration        // 无引用的享元在被回收前保留的时间
+	gcStats     GCStats              // 回收统计
+	now         func() time.Time     // 时钟，便于测试时替换
 }
 
 // NewDressFactory 创建并初始化一个新的皮肤工厂
 func NewDressFactory() *DressFactory {
 	return &DressFactory{
-		dresses: make(map[string]Dress),
-		count:   make(map[string]int),
+		dresses:     make(map[string]Dress),
+		count:       make(map[string]int),
+		releasedAt:  make(map[string]time.Time),
+		gracePeriod: DefaultGracePeriod,
+		now:         time.Now,
 	}
 }
 
@@ -104,6 +113,8 @@ func (f *DressFactory) GetDress(dressType string) (Dress, error) {
 	// 检查是否已有此类皮肤对象，如有则复用
 	if dress, exists := f.dresses[dressType]; exists {
 		f.count[dressType]++
+		// 重新被引用的享元不再等待回收
+		delete(f.releasedAt, dressType)
 		return dress, nil
 	}
 
@@ -147,6 +158,7 @@ type Player struct {
 	name       string // 外部状态 - 玩家名字是每个玩家特有的
 	dress      Dress  // 引用享元对象（内部状态）
 	playerType string // 外部状态 - 玩家类型
+	dressType  string // 外部状态 - 使用的皮肤类型，离开游戏时用于释放享元
 	x, y       int    // 外部状态 - 玩家位置坐标
 }
 
@@ -162,6 +174,7 @@ func NewPlayer(id int, name, playerType, dressType string, factory *DressFactory
 		name:       name,
 		dress:      dress,
 		playerType: playerType,
+		dressType:  dressType,
 		x:          x,
 		y:          y,
 	}, nil
@@ -177,6 +190,7 @@ type Game struct {
 	players   []*Player      // 所有玩家列表
 	factory   *DressFactory  // 皮肤工厂
 	teamCount map[string]int // 每个团队的玩家数量
+	nextID    int            // 下一个玩家ID
 }
 
 // NewGame 创建一个新的游戏实例
@@ -210,8 +224,9 @@ func (g *Game) AddPlayer(name, teamType string, x, y int) error {
 	}
 	g.teamCount[teamType]++
 
-	// 创建玩家ID
-	playerID := len(g.players) + 1
+	// 创建玩家ID，玩家离开后ID不会被复用
+	g.nextID++
+	playerID := g.nextID
 
 	// 创建玩家
 	player, err := NewPlayer(playerID, name, teamType, dressType, g.factory, x, y)
@@ -0,0 +1,96 @@
+package flyweight
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultGracePeriod 是无引用的享元在被回收前默认保留的时间
+// 保留一段时间可以避免玩家频繁进出时反复创建同一种皮肤
+const DefaultGracePeriod = 30 * time.Second
+
+// GCStats 记录享元回收的统计信息
+type GCStats struct {
+	Runs         int       // 回收执行次数
+	Evictions    int       // 累计回收的享元数量
+	LastEvicted  int       // 最近一次回收的享元数量
+	LastRun      time.Time // 最近一次回收的时间
+	Live         int       // 当前享元池中的享元数量
+	Unreferenced int       // 当前无引用、等待回收的享元数量
+}
+
+// ReleaseDress 释放一次对指定皮肤的引用
+// 引用计数降为0的享元不会立即删除，而是在宽限期过后由 CollectGarbage 回收
+func (f *DressFactory) ReleaseDress(dressType string) error {
+	if _, exists := f.dresses[dressType]; !exists {
+		return fmt.Errorf("皮肤 %s 不在享元池中", dressType)
+	}
+	if f.count[dressType] <= 0 {
+		return fmt.Errorf("皮肤 %s 没有被引用，无法释放", dressType)
+	}
+
+	f.count[dressType]--
+	if f.count[dressType] == 0 {
+		f.releasedAt[dressType] = f.now()
+	}
+	return nil
+}
+
+// SetGracePeriod 设置无引用享元的保留时间，0 表示下一次回收时立即删除
+func (f *DressFactory) SetGracePeriod(gracePeriod time.Duration) {
+	if gracePeriod >= 0 {
+		f.gracePeriod = gracePeriod
+	}
+}
+
+// CollectGarbage 回收引用计数为0且超过宽限期的享元，返回回收的数量
+func (f *DressFactory) CollectGarbage() int {
+	now := f.now()
+	evicted := 0
+
+	for dressType, releasedAt := range f.releasedAt {
+		if now.Sub(releasedAt) < f.gracePeriod {
+			continue
+		}
+		delete(f.dresses, dressType)
+		delete(f.count, dressType)
+		delete(f.releasedAt, dressType)
+		evicted++
+	}
+
+	f.gcStats.Runs++
+	f.gcStats.Evictions += evicted
+	f.gcStats.LastEvicted = evicted
+	f.gcStats.LastRun = now
+	return evicted
+}
+
+// GetGCStats 返回享元回收的统计信息
+func (f *DressFactory) GetGCStats() GCStats {
+	stats := f.gcStats
+	stats.Live = len(f.dresses)
+	stats.Unreferenced = len(f.releasedAt)
+	return stats
+}
+
+// RemovePlayer 将玩家移出游戏并释放其皮肤引用
+func (g *Game) RemovePlayer(id int) error {
+	for i, player := range g.players {
+		if player.id != id {
+			continue
+		}
+
+		if err := g.factory.ReleaseDress(player.dressType); err != nil {
+			return err
+		}
+		g.players = append(g.players[:i], g.players[i+1:]...)
+		g.teamCount[player.playerType]--
+		return nil
+	}
+	return fmt.Errorf("玩家 #%d 不存在", id)
+}
+
+// CollectUnusedDresses 回收已经没有玩家使用的皮肤，返回回收的数量
+func (g *Game) CollectUnusedDresses() int {
+	return g.factory.CollectGarbage()
+}
@@ -0,0 +1,132 @@
+package flyweight
+
+import (
+	"testing"
+	"time"
+)
+
+// newTestClock 返回可手动推进的时钟
+func newTestClock() (func() time.Time, func(time.Duration)) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time { return now }, func(d time.Duration) { now = now.Add(d) }
+}
+
+// TestReleaseDress 测试释放享元引用
+func TestReleaseDress(t *testing.T) {
+	factory := NewDressFactory()
+	factory.GetDress(TerroristDressType)
+	factory.GetDress(TerroristDressType)
+
+	if err := factory.ReleaseDress(TerroristDressType); err != nil {
+		t.Fatalf("释放皮肤失败: %v", err)
+	}
+	if factory.GetDressCount(TerroristDressType) != 1 {
+		t.Errorf("期望引用计数为1，实际为%d", factory.GetDressCount(TerroristDressType))
+	}
+
+	factory.ReleaseDress(TerroristDressType)
+	if err := factory.ReleaseDress(TerroristDressType); err == nil {
+		t.Error("引用计数为0时再次释放应返回错误")
+	}
+	if err := factory.ReleaseDress(EliteDressType); err == nil {
+		t.Error("释放不存在的皮肤应返回错误")
+	}
+}
+
+// TestCollectGarbage 测试宽限期过后回收无引用的享元
+func TestCollectGarbage(t *testing.T) {
+	now, advance := newTestClock()
+	factory := NewDressFactory()
+	factory.now = now
+	factory.SetGracePeriod(time.Minute)
+
+	factory.GetDress(TerroristDressType)
+	factory.GetDress(CounterTerroristDressType)
+	factory.ReleaseDress(TerroristDressType)
+
+	// 宽限期内不回收
+	advance(30 * time.Second)
+	if evicted := factory.CollectGarbage(); evicted != 0 {
+		t.Errorf("宽限期内不应回收，实际回收了%d个", evicted)
+	}
+	if stats := factory.GetGCStats(); stats.Unreferenced != 1 || stats.Live != 2 {
+		t.Errorf("回收统计不正确: %+v", stats)
+	}
+
+	// 宽限期过后回收，仍被引用的享元保留
+	advance(time.Minute)
+	if evicted := factory.CollectGarbage(); evicted != 1 {
+		t.Errorf("期望回收1个享元，实际回收了%d个", evicted)
+	}
+	if factory.GetTotalDressCount() != 1 {
+		t.Errorf("回收后应剩余1种皮肤，实际为%d", factory.GetTotalDressCount())
+	}
+	if _, exists := factory.GetDressUsageStats()[TerroristDressType]; exists {
+		t.Error("被回收的皮肤不应出现在使用统计中")
+	}
+
+	stats := factory.GetGCStats()
+	if stats.Runs != 2 || stats.Evictions != 1 || stats.LastEvicted != 1 || stats.Unreferenced != 0 {
+		t.Errorf("回收统计不正确: %+v", stats)
+	}
+}
+
+// TestReacquireDuringGracePeriod 测试宽限期内重新引用的享元不会被回收
+func TestReacquireDuringGracePeriod(t *testing.T) {
+	now, advance := newTestClock()
+	factory := NewDressFactory()
+	factory.now = now
+
+	first, _ := factory.GetDress(EliteDressType)
+	factory.ReleaseDress(EliteDressType)
+
+	advance(DefaultGracePeriod / 2)
+	second, _ := factory.GetDress(EliteDressType)
+	if first != second {
+		t.Error("宽限期内应复用同一个享元对象")
+	}
+
+	advance(DefaultGracePeriod)
+	if evicted := factory.CollectGarbage(); evicted != 0 {
+		t.Errorf("重新被引用的享元不应被回收，实际回收了%d个", evicted)
+	}
+}
+
+// TestGameRemovePlayer 测试玩家离开游戏后释放皮肤
+func TestGameRemovePlayer(t *testing.T) {
+	game := NewGame()
+	game.factory.SetGracePeriod(0)
+
+	game.AddTerroristPlayer("张三", 0, 0)
+	game.AddTerroristPlayer("李四", 10, 10)
+	game.AddElitePlayer("王五", 20, 20)
+
+	if err := game.RemovePlayer(3); err != nil {
+		t.Fatalf("移除玩家失败: %v", err)
+	}
+	if err := game.RemovePlayer(3); err == nil {
+		t.Error("移除不存在的玩家应返回错误")
+	}
+	if len(game.players) != 2 || game.teamCount["Elite"] != 0 {
+		t.Errorf("移除后玩家数或团队计数不正确: %d, %v", len(game.players), game.teamCount)
+	}
+
+	game.RemovePlayer(1)
+	if game.factory.GetDressCount(TerroristDressType) != 1 {
+		t.Errorf("恐怖分子皮肤应剩余1个引用，实际为%d", game.factory.GetDressCount(TerroristDressType))
+	}
+
+	if evicted := game.CollectUnusedDresses(); evicted != 1 {
+		t.Errorf("应回收精英皮肤，实际回收了%d个", evicted)
+	}
+
+	// 新玩家不会复用已离开玩家的ID
+	game.AddElitePlayer("赵六", 30, 30)
+	last := game.players[len(game.players)-1]
+	if last.id != 4 {
+		t.Errorf("新玩家ID应为4，实际为%d", last.id)
+	}
+	if game.factory.GetTotalDressCount() != 2 {
+		t.Errorf("精英皮肤应被重新创建，享元数应为2，实际为%d", game.factory.GetTotalDressCount())
+	}
+}
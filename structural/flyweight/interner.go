@@ -0,0 +1,148 @@
+package flyweight
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Interner 字符串驻留器，是享元模式在游戏之外的一个实际应用
+//
+// 日志字段名、HTTP 头、标签值等字符串往往大量重复，每次解析都会产生一份新的拷贝。
+// Interner 把内容相同的字符串映射到同一份共享的实例（内部状态），调用方丢弃自己的拷贝后
+// 只保留对共享实例的引用，从而减少内存占用。设置最大容量后，超出容量时淘汰最久未使用的字符串，
+// 被淘汰的字符串仍然有效，只是之后再次驻留时会存入新的实例。
+//
+// Interner 是并发安全的
+type Interner struct {
+	mu      sync.Mutex
+	strs    map[string]*list.Element // 字符串 -> 在 lru 中的位置
+	lru     *list.List               // 按最近使用排序，队首为最近使用
+	maxSize int                      // 最大驻留数量，0 表示不限制
+	stats   InternStats
+}
+
+// InternStats 记录字符串驻留的统计信息
+type InternStats struct {
+	Lookups    int // 驻留请求总次数
+	Hits       int // 复用已有实例的次数
+	Evictions  int // 因超出容量而淘汰的字符串数
+	Unique     int // 当前驻留的字符串数量
+	Bytes      int // 当前驻留的字符串占用的字节数
+	BytesSaved int // 复用已有实例而无需保存的重复字节数
+}
+
+// HitRate 返回复用率，没有请求时为 0
+func (s InternStats) HitRate() float64 {
+	if s.Lookups == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Lookups)
+}
+
+// String 返回统计信息的可读描述
+func (s InternStats) String() string {
+	return fmt.Sprintf("驻留 %d 个字符串(%d 字节)，请求 %d 次，复用 %d 次，节省 %d 字节，淘汰 %d 个",
+		s.Unique, s.Bytes, s.Lookups, s.Hits, s.BytesSaved, s.Evictions)
+}
+
+// InternerOption 是 Interner 的配置选项
+type InternerOption func(*Interner)
+
+// WithMaxSize 设置最多驻留的字符串数量，超出后淘汰最久未使用的字符串
+func WithMaxSize(n int) InternerOption {
+	return func(in *Interner) {
+		if n > 0 {
+			in.maxSize = n
+		}
+	}
+}
+
+// NewInterner 创建字符串驻留器，默认不限制容量
+func NewInterner(opts ...InternerOption) *Interner {
+	in := &Interner{
+		strs: make(map[string]*list.Element),
+		lru:  list.New(),
+	}
+	for _, opt := range opts {
+		opt(in)
+	}
+	return in
+}
+
+// Intern 返回与 s 内容相同的共享实例，首次出现时保存 s 的一份拷贝
+// 保存拷贝而不是 s 本身，避免 s 是大缓冲区的子串时让整个缓冲区无法被回收
+func (in *Interner) Intern(s string) string {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	if elem, ok := in.strs[s]; ok {
+		return in.hitLocked(elem)
+	}
+	return in.storeLocked(strings.Clone(s))
+}
+
+// InternBytes 与 Intern 相同，但直接接受字节切片，命中时不会分配新的字符串
+func (in *Interner) InternBytes(b []byte) string {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	// 编译器对 map 查找中的 string(b) 做了优化，不会产生拷贝
+	if elem, ok := in.strs[string(b)]; ok {
+		return in.hitLocked(elem)
+	}
+	return in.storeLocked(string(b))
+}
+
+// hitLocked 复用已驻留的实例并更新统计，调用方需持有锁
+func (in *Interner) hitLocked(elem *list.Element) string {
+	s := elem.Value.(string)
+	in.stats.Lookups++
+	in.stats.Hits++
+	in.stats.BytesSaved += len(s)
+	in.lru.MoveToFront(elem)
+	return s
+}
+
+// storeLocked 保存新的实例，超出容量时淘汰最久未使用的字符串，调用方需持有锁
+func (in *Interner) storeLocked(s string) string {
+	in.stats.Lookups++
+	in.strs[s] = in.lru.PushFront(s)
+	in.stats.Bytes += len(s)
+
+	for in.maxSize > 0 && in.lru.Len() > in.maxSize {
+		victim := in.lru.Remove(in.lru.Back()).(string)
+		delete(in.strs, victim)
+		in.stats.Bytes -= len(victim)
+		in.stats.Evictions++
+	}
+	return s
+}
+
+// Len 返回当前驻留的字符串数量
+func (in *Interner) Len() int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return len(in.strs)
+}
+
+// Stats 返回驻留统计信息
+func (in *Interner) Stats() InternStats {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	stats := in.stats
+	stats.Unique = len(in.strs)
+	return stats
+}
+
+// Reset 清空所有驻留的字符串和统计信息
+func (in *Interner) Reset() {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	in.strs = make(map[string]*list.Element)
+	in.lru.Init()
+	in.stats = InternStats{}
+}
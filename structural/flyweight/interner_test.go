@@ -0,0 +1,165 @@
+package flyweight
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"unsafe"
+)
+
+// sameInstance 判断两个字符串是否共享同一份底层数据
+func sameInstance(a, b string) bool {
+	return len(a) == len(b) && unsafe.StringData(a) == unsafe.StringData(b)
+}
+
+// TestInternReturnsSharedInstance 测试内容相同的字符串返回同一个实例
+func TestInternReturnsSharedInstance(t *testing.T) {
+	in := NewInterner()
+
+	// 从不同的缓冲区切出内容相同的字符串
+	a := in.Intern(strings.Repeat("ab", 4)[:6])
+	b := in.Intern(string([]byte("ababab")))
+	c := in.InternBytes([]byte("ababab"))
+
+	if a != "ababab" || !sameInstance(a, b) || !sameInstance(a, c) {
+		t.Fatal("内容相同的字符串应共享同一个实例")
+	}
+	if in.Intern("other") == a || in.Len() != 2 {
+		t.Errorf("期望驻留2个字符串，实际为%d", in.Len())
+	}
+
+	stats := in.Stats()
+	want := InternStats{Lookups: 4, Hits: 2, Unique: 2, Bytes: 11, BytesSaved: 12}
+	if stats != want {
+		t.Errorf("统计错误:\n期望 %+v\n实际 %+v", want, stats)
+	}
+	if stats.HitRate() != 0.5 {
+		t.Errorf("期望复用率为0.5，实际为%v", stats.HitRate())
+	}
+	if !strings.Contains(stats.String(), "节省 12 字节") {
+		t.Errorf("统计描述错误: %s", stats)
+	}
+}
+
+// TestInternCopiesSubstring 测试驻留子串时保存拷贝，不引用原缓冲区
+func TestInternCopiesSubstring(t *testing.T) {
+	in := NewInterner()
+	buf := strings.Repeat("x", 1024) + "key"
+	key := buf[1024:]
+
+	shared := in.Intern(key)
+	if shared != "key" || sameInstance(shared, key) {
+		t.Error("驻留的字符串应是原子串的拷贝")
+	}
+}
+
+// TestInternerEviction 测试超出容量时淘汰最久未使用的字符串
+func TestInternerEviction(t *testing.T) {
+	in := NewInterner(WithMaxSize(2))
+
+	first := in.Intern("a")
+	in.Intern("b")
+	in.Intern("a") // a 变为最近使用
+	in.Intern("c") // 淘汰 b
+
+	if in.Len() != 2 {
+		t.Fatalf("期望驻留2个字符串，实际为%d", in.Len())
+	}
+	if !sameInstance(in.Intern("a"), first) {
+		t.Error("最近使用的字符串不应被淘汰")
+	}
+
+	stats := in.Stats()
+	if stats.Evictions != 1 || stats.Bytes != 2 {
+		t.Errorf("期望淘汰1个字符串并剩余2字节，实际为 %+v", stats)
+	}
+
+	// 被淘汰的字符串再次驻留时存入新的实例
+	in.Intern("b")
+	if stats := in.Stats(); stats.Evictions != 2 || stats.Hits != 2 {
+		t.Errorf("再次驻留被淘汰的字符串应视为未命中: %+v", stats)
+	}
+
+	in.Reset()
+	if in.Len() != 0 || in.Stats() != (InternStats{}) {
+		t.Error("Reset 后应清空驻留的字符串和统计信息")
+	}
+}
+
+// TestInternerConcurrent 测试并发驻留
+func TestInternerConcurrent(t *testing.T) {
+	in := NewInterner(WithMaxSize(50))
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				s := fmt.Sprintf("key-%d", (i+g)%100)
+				if got := in.Intern(s); got != s {
+					t.Errorf("驻留结果错误: 期望 %s，实际 %s", s, got)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	stats := in.Stats()
+	if stats.Lookups != 8000 || stats.Unique > 50 {
+		t.Errorf("并发驻留统计错误: %+v", stats)
+	}
+}
+
+// newDuplicateLines 模拟从日志中逐行解析出的字段，100 个不同的值重复出现
+func newDuplicateLines(n int) [][]byte {
+	lines := make([][]byte, n)
+	for i := range lines {
+		lines[i] = []byte(fmt.Sprintf("service-%03d.request.latency", i%100))
+	}
+	return lines
+}
+
+// BenchmarkStringDuplication 每行都复制一份新的字符串，用于对比
+func BenchmarkStringDuplication(b *testing.B) {
+	lines := newDuplicateLines(10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		fields := make([]string, len(lines))
+		for j, line := range lines {
+			fields[j] = string(line)
+		}
+	}
+}
+
+// BenchmarkInterner 通过 Interner 共享重复的字符串，只有首次出现的值会分配内存
+func BenchmarkInterner(b *testing.B) {
+	lines := newDuplicateLines(10000)
+	in := NewInterner()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		fields := make([]string, len(lines))
+		for j, line := range lines {
+			fields[j] = in.InternBytes(line)
+		}
+	}
+}
+
+// BenchmarkInternerParallel 测试多个协程同时驻留时的性能
+func BenchmarkInternerParallel(b *testing.B) {
+	lines := newDuplicateLines(10000)
+	in := NewInterner()
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		j := 0
+		for pb.Next() {
+			in.InternBytes(lines[j%len(lines)])
+			j++
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package immutable
+
+import (
+	"fmt"
+)
+
+// Inventory 示例中的库存快照：商品的库存数量和按时间排列的操作日志
+type Inventory struct {
+	Stock Map[string, int]
+	Log   List[string]
+}
+
+// restock 返回补货后的新库存
+func (inv Inventory) restock(sku string, n int) Inventory {
+	current, _ := inv.Stock.Get(sku)
+	return Inventory{
+		Stock: inv.Stock.Set(sku, current+n),
+		Log:   inv.Log.Append(fmt.Sprintf("补货 %s +%d", sku, n)),
+	}
+}
+
+// discontinue 返回下架商品后的新库存
+func (inv Inventory) discontinue(sku string) Inventory {
+	return Inventory{
+		Stock: inv.Stock.Delete(sku),
+		Log:   inv.Log.Append("下架 " + sku),
+	}
+}
+
+// RunExample 运行不可变集合示例：每次修改产生新版本，所有历史版本都保持不变
+func RunExample() {
+	fmt.Println("== 列表 ==")
+	l1 := ListOf("a", "b", "c")
+	l2 := l1.Set(1, "B").Append("d")
+	l3 := l2.Remove(0)
+	fmt.Println("l1:", l1, "l2:", l2, "l3:", l3)
+
+	fmt.Println("== 映射 ==")
+	m1 := MapOf(map[string]int{"apple": 3, "pear": 5})
+	m2 := m1.Set("apple", 10).Set("plum", 1)
+	m3 := m2.Delete("pear")
+	fmt.Println("m1:", m1, "m2:", m2, "m3:", m3)
+
+	fmt.Println("== 版本历史 ==")
+	history := NewVersions(Inventory{})
+	history.Update(func(inv Inventory) Inventory { return inv.restock("SKU-1", 100) })
+	history.Update(func(inv Inventory) Inventory { return inv.restock("SKU-2", 20) })
+	history.Update(func(inv Inventory) Inventory { return inv.discontinue("SKU-1") })
+
+	for v := 0; v <= history.Version(); v++ {
+		inv, _ := history.At(v)
+		fmt.Printf("v%d: 库存 %v，日志 %v\n", v, inv.Stock, inv.Log)
+	}
+
+	v, _ := history.Revert(2)
+	inv := history.Current()
+	fmt.Printf("回退到 v2 产生 v%d: 库存 %v，日志共 %d 条\n", v, inv.Stock, inv.Log.Len())
+}
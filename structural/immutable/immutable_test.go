@@ -0,0 +1,340 @@
+package immutable
+
+import (
+	"maps"
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestListBasics 测试列表的基本操作，旧版本不受修改影响
+func TestListBasics(t *testing.T) {
+	var empty List[int]
+	assert.Equal(t, 0, empty.Len())
+	assert.Empty(t, empty.Slice())
+
+	l1 := ListOf(1, 2, 3)
+	l2 := l1.Set(0, 10)
+	l3 := l2.Append(4)
+	l4 := l3.Pop().Pop()
+	l5 := l3.Remove(1)
+
+	assert.Equal(t, []int{1, 2, 3}, l1.Slice())
+	assert.Equal(t, []int{10, 2, 3}, l2.Slice())
+	assert.Equal(t, []int{10, 2, 3, 4}, l3.Slice())
+	assert.Equal(t, []int{10, 2}, l4.Slice())
+	assert.Equal(t, []int{10, 3, 4}, l5.Slice())
+	assert.Equal(t, "[10 3 4]", l5.String())
+	assert.Equal(t, 0, ListOf(1).Pop().Len())
+
+	assert.Panics(t, func() { l1.Get(3) })
+	assert.Panics(t, func() { l1.Set(-1, 0) })
+	assert.Panics(t, func() { empty.Pop() })
+}
+
+// TestListLarge 测试跨越多层的列表：追加、修改、弹出到空
+func TestListLarge(t *testing.T) {
+	const n = width*width*width + 7 // 4 层
+	var l List[int]
+	for i := 0; i < n; i++ {
+		l = l.Append(i)
+	}
+	assert.Equal(t, n, l.Len())
+	assert.Equal(t, uint(3*levelBits), l.shift)
+
+	full := l
+	for i := 0; i < n; i += 97 {
+		l = l.Set(i, -i)
+	}
+	for i := 0; i < n; i++ {
+		assert.Equal(t, i, full.Get(i), "原列表不变")
+		if i%97 == 0 {
+			assert.Equal(t, -i, l.Get(i))
+		} else {
+			assert.Equal(t, i, l.Get(i))
+		}
+	}
+
+	for l.Len() > width {
+		l = l.Pop()
+	}
+	assert.Equal(t, uint(0), l.shift, "弹出后根节点降低层级")
+	for l.Len() > 0 {
+		l = l.Pop()
+	}
+	assert.Equal(t, List[int]{}, l)
+
+	count := 0
+	for i, v := range full.All() {
+		if i == 100 {
+			break
+		}
+		assert.Equal(t, i, v)
+		count++
+	}
+	assert.Equal(t, 100, count, "提前结束遍历")
+}
+
+// TestListStructuralSharing 测试修改只复制一条路径，其余叶子与旧版本共享
+func TestListStructuralSharing(t *testing.T) {
+	var l List[int]
+	for i := 0; i < width*width; i++ {
+		l = l.Append(i)
+	}
+	l2 := l.Set(0, -1)
+	shared := 0
+	for i := range l.root.children {
+		if l.root.children[i] == l2.root.children[i] {
+			shared++
+		}
+	}
+	assert.Equal(t, width-1, shared)
+}
+
+// TestListMatchesSlice 随机操作与切片对照，并检查所有历史版本保持不变
+func TestListMatchesSlice(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	var l List[int]
+	var model []int
+	type version struct {
+		list  List[int]
+		model []int
+	}
+	var history []version
+
+	for step := 0; step < 5000; step++ {
+		switch op := rng.Intn(10); {
+		case op < 5 || len(model) == 0:
+			v := rng.Int()
+			l, model = l.Append(v), append(slices.Clip(model), v)
+		case op < 8:
+			i, v := rng.Intn(len(model)), rng.Int()
+			l = l.Set(i, v)
+			model = slices.Clone(model)
+			model[i] = v
+		case op < 9:
+			l, model = l.Pop(), slices.Clip(model[:len(model)-1])
+		default:
+			i := rng.Intn(len(model))
+			l, model = l.Remove(i), slices.Delete(slices.Clone(model), i, i+1)
+		}
+		if step%50 == 0 {
+			history = append(history, version{l, model})
+		}
+	}
+	for _, h := range history {
+		assert.Equal(t, len(h.model), h.list.Len())
+		if len(h.model) == 0 {
+			continue
+		}
+		assert.Equal(t, h.model, h.list.Slice())
+	}
+}
+
+// TestMapBasics 测试映射的基本操作，旧版本不受修改影响
+func TestMapBasics(t *testing.T) {
+	var empty Map[string, int]
+	_, ok := empty.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, empty, empty.Delete("a"))
+
+	m1 := MapOf(map[string]int{"a": 1, "b": 2})
+	m2 := m1.Set("a", 10).Set("c", 3)
+	m3 := m2.Delete("b")
+
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, m1.ToMap())
+	assert.Equal(t, map[string]int{"a": 10, "b": 2, "c": 3}, m2.ToMap())
+	assert.Equal(t, map[string]int{"a": 10, "c": 3}, m3.ToMap())
+	assert.Equal(t, "map[a:10 c:3]", m3.String())
+	assert.Equal(t, 2, m3.Len())
+	assert.Equal(t, m3, m3.Delete("missing"), "删除不存在的键返回原映射")
+
+	v, ok := m2.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+
+	// 结构体键
+	type point struct{ x, y int }
+	pm := Map[point, string]{}.Set(point{1, 2}, "p")
+	v2, ok := pm.Get(point{1, 2})
+	assert.True(t, ok)
+	assert.Equal(t, "p", v2)
+}
+
+// TestMapCollisions 测试哈希值完全相同和前缀相同的键
+func TestMapCollisions(t *testing.T) {
+	var m Map[string, int]
+	// a、b 哈希值完全相同；c 与它们只在第 3 层的槽位上不同
+	const h = 0b10101_00011_00001
+	m = m.set(h, "a", 1).set(h, "b", 2).set(h|1<<11, "c", 3)
+	assert.Equal(t, 3, m.Len())
+
+	for key, want := range map[string]int{"a": 1, "b": 2} {
+		got, ok := m.get(h, key)
+		assert.True(t, ok)
+		assert.Equal(t, want, got)
+	}
+	got, ok := m.get(h|1<<11, "c")
+	assert.True(t, ok)
+	assert.Equal(t, 3, got)
+	_, ok = m.get(h, "c")
+	assert.False(t, ok, "键不同")
+	assert.Len(t, m.root.entries, 1, "前两层槽位相同，下沉为子节点")
+
+	m = m.set(h, "b", 20)
+	assert.Equal(t, 3, m.Len(), "覆盖碰撞链中的键")
+	got, _ = m.get(h, "b")
+	assert.Equal(t, 20, got)
+
+	m2 := m.delete(h, "a").delete(h|1<<11, "c")
+	assert.Equal(t, 1, m2.Len())
+	got, ok = m2.get(h, "b")
+	assert.True(t, ok)
+	assert.Equal(t, 20, got)
+	assert.Nil(t, m2.root.entries[0].node, "只剩一个叶子时提升到根节点")
+
+	m2 = m2.delete(h, "b")
+	assert.Equal(t, Map[string, int]{}, m2)
+	assert.Equal(t, 3, m.Len(), "原映射不变")
+}
+
+// TestMapMatchesBuiltin 随机操作与内建 map 对照，并检查所有历史版本保持不变
+func TestMapMatchesBuiltin(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	var m Map[int, int]
+	model := map[int]int{}
+	type version struct {
+		m     Map[int, int]
+		model map[int]int
+	}
+	var history []version
+
+	for step := 0; step < 20000; step++ {
+		key := rng.Intn(3000)
+		if rng.Intn(3) == 0 {
+			m = m.Delete(key)
+			model = maps.Clone(model)
+			delete(model, key)
+		} else {
+			m = m.Set(key, step)
+			model = maps.Clone(model)
+			model[key] = step
+		}
+		if step%500 == 0 {
+			history = append(history, version{m, model})
+		}
+	}
+	for _, h := range history {
+		assert.Equal(t, len(h.model), h.m.Len())
+		assert.Equal(t, h.model, h.m.ToMap())
+	}
+}
+
+// TestVersions 测试版本历史：并发更新不丢失，回退产生新版本
+func TestVersions(t *testing.T) {
+	v := NewVersions(Map[string, int]{})
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v.Update(func(m Map[string, int]) Map[string, int] {
+				n, _ := m.Get("count")
+				return m.Set("count", n+1)
+			})
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 50, v.Version())
+	n, _ := v.Current().Get("count")
+	assert.Equal(t, 50, n, "更新串行执行，不会丢失")
+	for i := 0; i <= 50; i++ {
+		m, err := v.At(i)
+		assert.NoError(t, err)
+		n, _ := m.Get("count")
+		assert.Equal(t, i, n, "每个历史版本保持不变")
+	}
+
+	version, err := v.Revert(10)
+	assert.NoError(t, err)
+	assert.Equal(t, 51, version)
+	n, _ = v.Current().Get("count")
+	assert.Equal(t, 10, n)
+
+	_, err = v.At(52)
+	assert.ErrorIs(t, err, ErrUnknownVersion)
+	_, err = v.Revert(-1)
+	assert.ErrorIs(t, err, ErrUnknownVersion)
+}
+
+// benchSize 基准测试中集合的大小
+const benchSize = 10000
+
+// BenchmarkMapSet 比较不可变映射与"复制整个 map 再修改"的开销
+func BenchmarkMapSet(b *testing.B) {
+	builtin := make(map[int]int, benchSize)
+	var persistent Map[int, int]
+	for i := 0; i < benchSize; i++ {
+		builtin[i] = i
+		persistent = persistent.Set(i, i)
+	}
+	b.Run("CopyBuiltin", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			m := maps.Clone(builtin)
+			m[i%benchSize] = -1
+		}
+	})
+	b.Run("Persistent", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = persistent.Set(i%benchSize, -1)
+		}
+	})
+}
+
+// BenchmarkMapGet 比较读取的开销，不可变映射的读取较慢，这是结构共享的代价
+func BenchmarkMapGet(b *testing.B) {
+	builtin := make(map[int]int, benchSize)
+	var persistent Map[int, int]
+	for i := 0; i < benchSize; i++ {
+		builtin[i] = i
+		persistent = persistent.Set(i, i)
+	}
+	b.Run("Builtin", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = builtin[i%benchSize]
+		}
+	})
+	b.Run("Persistent", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			persistent.Get(i % benchSize)
+		}
+	})
+}
+
+// BenchmarkListSet 比较不可变列表与"复制整个切片再修改"的开销
+func BenchmarkListSet(b *testing.B) {
+	slice := make([]int, benchSize)
+	var list List[int]
+	for i := range slice {
+		list = list.Append(i)
+	}
+	b.Run("CopySlice", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			s := slices.Clone(slice)
+			s[i%benchSize] = -1
+		}
+	})
+	b.Run("Persistent", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = list.Set(i%benchSize, -1)
+		}
+	})
+}
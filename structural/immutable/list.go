@@ -0,0 +1,224 @@
+package immutable
+
+import (
+	"fmt"
+	"iter"
+)
+
+// 列表节点的分支因子：每个节点最多 32 个子节点或元素
+const (
+	levelBits = 5
+	width     = 1 << levelBits
+	mask      = width - 1
+)
+
+// vnode 列表的树节点，叶子节点保存元素，内部节点保存子节点
+type vnode[T any] struct {
+	children []*vnode[T]
+	values   []T
+}
+
+// List 不可变列表（持久化向量）
+//
+// 元素按下标保存在一棵 32 叉树中，Get、Set、Append、Pop 都是 O(log32 n)，
+// 一百万个元素的树也只有 4 层。修改时只复制从根到目标叶子的一条路径，
+// 其余节点由新旧两个版本共享（结构共享），旧版本保持不变，可以继续使用。
+//
+// 零值是一个可以直接使用的空列表。List 本身不可变，可以在多个协程间安全地共享
+type List[T any] struct {
+	root  *vnode[T]
+	shift uint // 根节点的层级 * levelBits，只有一个叶子时为 0
+	size  int
+}
+
+// ListOf 用给定的元素创建列表
+func ListOf[T any](values ...T) List[T] {
+	var l List[T]
+	for _, v := range values {
+		l = l.Append(v)
+	}
+	return l
+}
+
+// Len 返回元素个数
+func (l List[T]) Len() int {
+	return l.size
+}
+
+// Get 返回下标 i 的元素，下标越界时 panic
+func (l List[T]) Get(i int) T {
+	l.check(i)
+	node := l.root
+	for shift := l.shift; shift > 0; shift -= levelBits {
+		node = node.children[(i>>shift)&mask]
+	}
+	return node.values[i&mask]
+}
+
+// Set 返回把下标 i 的元素替换为 v 的新列表，下标越界时 panic
+func (l List[T]) Set(i int, v T) List[T] {
+	l.check(i)
+	l.root = setPath(l.root, l.shift, i, v)
+	return l
+}
+
+// Append 返回在末尾追加 v 的新列表
+func (l List[T]) Append(v T) List[T] {
+	switch {
+	case l.size == 0:
+		l.root = newPath(0, v)
+	case l.size == width<<l.shift:
+		// 树已满，增加一层
+		l.root = &vnode[T]{children: []*vnode[T]{l.root, newPath(l.shift, v)}}
+		l.shift += levelBits
+	default:
+		l.root = appendPath(l.root, l.shift, l.size, v)
+	}
+	l.size++
+	return l
+}
+
+// Pop 返回去掉最后一个元素的新列表，列表为空时 panic
+func (l List[T]) Pop() List[T] {
+	if l.size == 0 {
+		panic("immutable: 从空列表中 Pop")
+	}
+	l.root = popPath(l.root, l.shift, l.size-1)
+	l.size--
+	if l.size == 0 {
+		return List[T]{}
+	}
+	// 根节点只剩一个子节点时降低一层
+	for l.shift > 0 && len(l.root.children) == 1 {
+		l.root = l.root.children[0]
+		l.shift -= levelBits
+	}
+	return l
+}
+
+// Remove 返回删除下标 i 的元素的新列表，下标越界时 panic
+// 下标 i 之前的部分与原列表共享，之后的元素需要重新追加，复杂度为 O((n-i) log n)
+func (l List[T]) Remove(i int) List[T] {
+	l.check(i)
+	result := l
+	for result.size > i {
+		result = result.Pop()
+	}
+	for j := i + 1; j < l.size; j++ {
+		result = result.Append(l.Get(j))
+	}
+	return result
+}
+
+// All 按下标顺序遍历所有元素
+func (l List[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		i := 0
+		var walk func(node *vnode[T]) bool
+		walk = func(node *vnode[T]) bool {
+			for _, child := range node.children {
+				if !walk(child) {
+					return false
+				}
+			}
+			for _, v := range node.values {
+				if !yield(i, v) {
+					return false
+				}
+				i++
+			}
+			return true
+		}
+		if l.root != nil {
+			walk(l.root)
+		}
+	}
+}
+
+// Slice 返回包含所有元素的切片，修改切片不影响列表
+func (l List[T]) Slice() []T {
+	values := make([]T, 0, l.size)
+	for _, v := range l.All() {
+		values = append(values, v)
+	}
+	return values
+}
+
+// String 返回列表的可读表示
+func (l List[T]) String() string {
+	return fmt.Sprint(l.Slice())
+}
+
+// check 检查下标是否越界
+func (l List[T]) check(i int) {
+	if i < 0 || i >= l.size {
+		panic(fmt.Sprintf("immutable: 下标 %d 越界，列表长度为 %d", i, l.size))
+	}
+}
+
+// newPath 创建从第 shift 层到叶子、只包含 v 的一条路径
+func newPath[T any](shift uint, v T) *vnode[T] {
+	if shift == 0 {
+		return &vnode[T]{values: []T{v}}
+	}
+	return &vnode[T]{children: []*vnode[T]{newPath(shift-levelBits, v)}}
+}
+
+// setPath 复制从 node 到下标 i 所在叶子的路径，并替换元素
+func setPath[T any](node *vnode[T], shift uint, i int, v T) *vnode[T] {
+	if shift == 0 {
+		values := clone(node.values, 0)
+		values[i&mask] = v
+		return &vnode[T]{values: values}
+	}
+	children := clone(node.children, 0)
+	idx := (i >> shift) & mask
+	children[idx] = setPath(children[idx], shift-levelBits, i, v)
+	return &vnode[T]{children: children}
+}
+
+// appendPath 复制到下标 i（当前长度）所在位置的路径并追加 v，调用方保证这一层还有空位
+func appendPath[T any](node *vnode[T], shift uint, i int, v T) *vnode[T] {
+	if shift == 0 {
+		values := clone(node.values, 1)
+		values[len(values)-1] = v
+		return &vnode[T]{values: values}
+	}
+	idx := (i >> shift) & mask
+	if idx < len(node.children) {
+		children := clone(node.children, 0)
+		children[idx] = appendPath(children[idx], shift-levelBits, i, v)
+		return &vnode[T]{children: children}
+	}
+	children := clone(node.children, 1)
+	children[idx] = newPath(shift-levelBits, v)
+	return &vnode[T]{children: children}
+}
+
+// popPath 复制到下标 i（最后一个元素）的路径并去掉该元素，节点变空时返回 nil
+func popPath[T any](node *vnode[T], shift uint, i int) *vnode[T] {
+	if shift == 0 {
+		if len(node.values) == 1 {
+			return nil
+		}
+		return &vnode[T]{values: node.values[: len(node.values)-1 : len(node.values)-1]}
+	}
+	idx := (i >> shift) & mask
+	child := popPath(node.children[idx], shift-levelBits, i)
+	if child == nil {
+		if idx == 0 {
+			return nil
+		}
+		return &vnode[T]{children: node.children[:idx:idx]}
+	}
+	children := clone(node.children, 0)
+	children[idx] = child
+	return &vnode[T]{children: children}
+}
+
+// clone 复制切片并在末尾预留 extra 个位置
+func clone[S ~[]E, E any](s S, extra int) S {
+	c := make(S, len(s)+extra)
+	copy(c, s)
+	return c
+}
@@ -0,0 +1,288 @@
+package immutable
+
+import (
+	"fmt"
+	"hash/maphash"
+	"iter"
+	"math/bits"
+	"sort"
+	"strings"
+)
+
+// seed 所有 Map 共用的哈希种子，使零值 Map 可以直接使用
+var seed = maphash.MakeSeed()
+
+// pair 键值对
+type pair[K comparable, V any] struct {
+	key K
+	val V
+}
+
+// hentry 哈希树节点中的一项：子节点，或者哈希值相同的一组键值对（叶子）
+type hentry[K comparable, V any] struct {
+	node  *hnode[K, V]
+	hash  uint64
+	pairs []pair[K, V] // 多于一个时说明发生了哈希碰撞
+}
+
+// hnode 哈希树节点：位图记录 32 个槽位中哪些有内容，entries 只保存有内容的槽位
+type hnode[K comparable, V any] struct {
+	bitmap  uint32
+	entries []hentry[K, V]
+}
+
+// Map 不可变映射（哈希数组映射字典树，HAMT）
+//
+// 键的哈希值每 5 位决定一层中的槽位，Get、Set、Delete 都是 O(log32 n)。
+// 修改时只复制从根到目标槽位的一条路径，其余节点由新旧版本共享，
+// 相比每次修改都复制整个 map，修改的开销与映射的大小基本无关。
+//
+// 零值是一个可以直接使用的空映射。Map 本身不可变，可以在多个协程间安全地共享
+type Map[K comparable, V any] struct {
+	root *hnode[K, V]
+	size int
+}
+
+// MapOf 用内建 map 的内容创建映射
+func MapOf[K comparable, V any](m map[K]V) Map[K, V] {
+	var result Map[K, V]
+	for k, v := range m {
+		result = result.Set(k, v)
+	}
+	return result
+}
+
+// Len 返回键值对的个数
+func (m Map[K, V]) Len() int {
+	return m.size
+}
+
+// Get 返回键对应的值
+func (m Map[K, V]) Get(key K) (V, bool) {
+	return m.get(maphash.Comparable(seed, key), key)
+}
+
+// Set 返回设置了 key 的新映射
+func (m Map[K, V]) Set(key K, val V) Map[K, V] {
+	return m.set(maphash.Comparable(seed, key), key, val)
+}
+
+// Delete 返回删除了 key 的新映射，key 不存在时返回 m 本身
+func (m Map[K, V]) Delete(key K) Map[K, V] {
+	return m.delete(maphash.Comparable(seed, key), key)
+}
+
+// All 遍历所有键值对，顺序不确定
+func (m Map[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		var walk func(n *hnode[K, V]) bool
+		walk = func(n *hnode[K, V]) bool {
+			for _, e := range n.entries {
+				if e.node != nil {
+					if !walk(e.node) {
+						return false
+					}
+					continue
+				}
+				for _, p := range e.pairs {
+					if !yield(p.key, p.val) {
+						return false
+					}
+				}
+			}
+			return true
+		}
+		if m.root != nil {
+			walk(m.root)
+		}
+	}
+}
+
+// ToMap 返回包含所有键值对的内建 map，修改它不影响映射
+func (m Map[K, V]) ToMap() map[K]V {
+	result := make(map[K]V, m.size)
+	for k, v := range m.All() {
+		result[k] = v
+	}
+	return result
+}
+
+// String 返回映射的可读表示，键按字符串排序
+func (m Map[K, V]) String() string {
+	items := make([]string, 0, m.size)
+	for k, v := range m.All() {
+		items = append(items, fmt.Sprintf("%v:%v", k, v))
+	}
+	sort.Strings(items)
+	return "map[" + strings.Join(items, " ") + "]"
+}
+
+// get 按哈希值查找
+func (m Map[K, V]) get(hash uint64, key K) (V, bool) {
+	n := m.root
+	for shift := uint(0); n != nil; shift += levelBits {
+		bit, idx := slot(n, hash, shift)
+		if n.bitmap&bit == 0 {
+			break
+		}
+		e := n.entries[idx]
+		if e.node != nil {
+			n = e.node
+			continue
+		}
+		if e.hash == hash {
+			for _, p := range e.pairs {
+				if p.key == key {
+					return p.val, true
+				}
+			}
+		}
+		break
+	}
+	var zero V
+	return zero, false
+}
+
+// set 按哈希值设置
+func (m Map[K, V]) set(hash uint64, key K, val V) Map[K, V] {
+	root, added := setNode(m.root, 0, hash, key, val)
+	m.root = root
+	if added {
+		m.size++
+	}
+	return m
+}
+
+// delete 按哈希值删除
+func (m Map[K, V]) delete(hash uint64, key K) Map[K, V] {
+	root, removed := deleteNode(m.root, 0, hash, key)
+	if !removed {
+		return m
+	}
+	m.root = root
+	m.size--
+	return m
+}
+
+// slot 返回哈希值在第 shift 层对应的位和在 entries 中的下标
+func slot[K comparable, V any](n *hnode[K, V], hash uint64, shift uint) (uint32, int) {
+	bit := uint32(1) << ((hash >> shift) & mask)
+	return bit, bits.OnesCount32(n.bitmap & (bit - 1))
+}
+
+// setNode 复制路径并设置键值，返回新节点和是否新增了键
+func setNode[K comparable, V any](n *hnode[K, V], shift uint, hash uint64, key K, val V) (*hnode[K, V], bool) {
+	leaf := hentry[K, V]{hash: hash, pairs: []pair[K, V]{{key, val}}}
+	if n == nil {
+		return &hnode[K, V]{bitmap: 1 << ((hash >> shift) & mask), entries: []hentry[K, V]{leaf}}, true
+	}
+
+	bit, idx := slot(n, hash, shift)
+	if n.bitmap&bit == 0 {
+		entries := make([]hentry[K, V], len(n.entries)+1)
+		copy(entries, n.entries[:idx])
+		entries[idx] = leaf
+		copy(entries[idx+1:], n.entries[idx:])
+		return &hnode[K, V]{bitmap: n.bitmap | bit, entries: entries}, true
+	}
+
+	entries := clone(n.entries, 0)
+	e := entries[idx]
+	added := false
+	switch {
+	case e.node != nil:
+		entries[idx].node, added = setNode(e.node, shift+levelBits, hash, key, val)
+	case e.hash == hash:
+		pairs := clone(e.pairs, 0)
+		found := false
+		for i := range pairs {
+			if pairs[i].key == key {
+				pairs[i].val = val
+				found = true
+				break
+			}
+		}
+		if !found {
+			pairs = append(pairs, pair[K, V]{key, val})
+			added = true
+		}
+		entries[idx].pairs = pairs
+	default:
+		// 槽位被哈希值不同的叶子占用，下沉一层把两者分开
+		entries[idx] = hentry[K, V]{node: mergeLeaves(shift+levelBits, e, leaf)}
+		added = true
+	}
+	return &hnode[K, V]{bitmap: n.bitmap, entries: entries}, added
+}
+
+// mergeLeaves 创建同时包含两个哈希值不同的叶子的子树
+func mergeLeaves[K comparable, V any](shift uint, a, b hentry[K, V]) *hnode[K, V] {
+	ia, ib := (a.hash>>shift)&mask, (b.hash>>shift)&mask
+	if ia == ib {
+		return &hnode[K, V]{bitmap: 1 << ia, entries: []hentry[K, V]{{node: mergeLeaves(shift+levelBits, a, b)}}}
+	}
+	if ia > ib {
+		a, b = b, a
+		ia, ib = ib, ia
+	}
+	return &hnode[K, V]{bitmap: 1<<ia | 1<<ib, entries: []hentry[K, V]{a, b}}
+}
+
+// deleteNode 复制路径并删除键，返回新节点（变空时为 nil）和是否删除了键
+// 子节点只剩一个叶子时把叶子提升到当前层，使同样内容的映射总是有同样的形状
+func deleteNode[K comparable, V any](n *hnode[K, V], shift uint, hash uint64, key K) (*hnode[K, V], bool) {
+	if n == nil {
+		return nil, false
+	}
+	bit, idx := slot(n, hash, shift)
+	if n.bitmap&bit == 0 {
+		return n, false
+	}
+
+	e := n.entries[idx]
+	var replacement *hentry[K, V] // nil 表示删除这个槽位
+	switch {
+	case e.node != nil:
+		child, removed := deleteNode(e.node, shift+levelBits, hash, key)
+		if !removed {
+			return n, false
+		}
+		if child != nil {
+			r := hentry[K, V]{node: child}
+			if len(child.entries) == 1 && child.entries[0].node == nil {
+				r = child.entries[0]
+			}
+			replacement = &r
+		}
+	case e.hash == hash:
+		i := -1
+		for j, p := range e.pairs {
+			if p.key == key {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			return n, false
+		}
+		if len(e.pairs) > 1 {
+			pairs := make([]pair[K, V], 0, len(e.pairs)-1)
+			pairs = append(append(pairs, e.pairs[:i]...), e.pairs[i+1:]...)
+			replacement = &hentry[K, V]{hash: hash, pairs: pairs}
+		}
+	default:
+		return n, false
+	}
+
+	if replacement != nil {
+		entries := clone(n.entries, 0)
+		entries[idx] = *replacement
+		return &hnode[K, V]{bitmap: n.bitmap, entries: entries}, true
+	}
+	if len(n.entries) == 1 {
+		return nil, true
+	}
+	entries := make([]hentry[K, V], 0, len(n.entries)-1)
+	entries = append(append(entries, n.entries[:idx]...), n.entries[idx+1:]...)
+	return &hnode[K, V]{bitmap: n.bitmap &^ bit, entries: entries}, true
+}
@@ -0,0 +1,71 @@
+package immutable
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrUnknownVersion 版本号不存在
+var ErrUnknownVersion = errors.New("版本不存在")
+
+// Versions 不可变值的版本历史
+//
+// 每次更新都由旧版本计算出新版本并追加到历史中，旧版本永远不变。
+// 配合 List、Map 的结构共享，保留全部历史的开销只是每次修改复制的那几条路径，
+// 而不是每个版本一份完整的拷贝。Versions 是并发安全的
+type Versions[T any] struct {
+	mutex    sync.RWMutex
+	versions []T
+}
+
+// NewVersions 以 initial 作为版本 0 创建版本历史
+func NewVersions[T any](initial T) *Versions[T] {
+	return &Versions[T]{versions: []T{initial}}
+}
+
+// Current 返回最新版本
+func (v *Versions[T]) Current() T {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+	return v.versions[len(v.versions)-1]
+}
+
+// Version 返回最新的版本号
+func (v *Versions[T]) Version() int {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+	return len(v.versions) - 1
+}
+
+// At 返回指定版本
+func (v *Versions[T]) At(version int) (T, error) {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+	if version < 0 || version >= len(v.versions) {
+		var zero T
+		return zero, fmt.Errorf("%w: %d，最新版本为 %d", ErrUnknownVersion, version, len(v.versions)-1)
+	}
+	return v.versions[version], nil
+}
+
+// Update 用 fn 由最新版本计算出新版本，返回新的版本号
+// 更新是串行的，fn 总是基于最新版本计算，不会丢失并发的更新；fn 不应修改传入的值
+func (v *Versions[T]) Update(fn func(T) T) int {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.versions = append(v.versions, fn(v.versions[len(v.versions)-1]))
+	return len(v.versions) - 1
+}
+
+// Revert 把指定版本作为新版本追加到历史中，返回新的版本号
+// 与 git revert 类似，回退本身也是一个版本，之后的历史不会丢失
+func (v *Versions[T]) Revert(version int) (int, error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if version < 0 || version >= len(v.versions) {
+		return 0, fmt.Errorf("%w: %d，最新版本为 %d", ErrUnknownVersion, version, len(v.versions)-1)
+	}
+	v.versions = append(v.versions, v.versions[version])
+	return len(v.versions) - 1, nil
+}
@@ -0,0 +1,189 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// 熔断器的默认参数
+const (
+	DefaultFailureThreshold = 3
+	DefaultCooldown         = 5 * time.Second
+)
+
+// ErrCircuitOpen 熔断器处于打开状态时，调用被直接拒绝
+var ErrCircuitOpen = errors.New("熔断器已打开，暂停购车请求")
+
+// CircuitState 熔断器状态
+type CircuitState int
+
+const (
+	// StateClosed 关闭状态：请求正常转发，统计连续失败次数
+	StateClosed CircuitState = iota
+	// StateOpen 打开状态：请求被快速拒绝，直到冷却时间结束
+	StateOpen
+	// StateHalfOpen 半开状态：放行一个试探请求，成功则关闭，失败则重新打开
+	StateHalfOpen
+)
+
+// String 返回状态名称
+func (s CircuitState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+// StateChangeFunc 状态变化回调，from 和 to 分别是变化前后的状态
+type StateChangeFunc func(from, to CircuitState)
+
+// CircuitBreaker 熔断器状态机，不绑定具体接口：调用前执行 Allow，调用结束后执行 Record
+// 连续失败达到阈值后打开；冷却时间结束后进入半开状态，只放行一个试探请求，
+// 试探成功则关闭，失败则重新打开。CircuitBreakerProxy 和 API 网关的熔断中间件都基于它实现
+type CircuitBreaker struct {
+	mu               sync.Mutex
+	state            CircuitState
+	failures         int
+	failureThreshold int
+	cooldown         time.Duration
+	openedAt         time.Time
+	probing          bool // 半开状态下是否已有试探请求在执行
+	onStateChange    StateChangeFunc
+	now              func() time.Time
+}
+
+// NewCircuitBreaker 创建熔断器
+// failureThreshold 为打开熔断器所需的连续失败次数，cooldown 为打开后的冷却时间，
+// 非正值时分别使用 DefaultFailureThreshold 和 DefaultCooldown
+func NewCircuitBreaker(failureThreshold int, cooldown time.Duration) *CircuitBreaker {
+	if failureThreshold <= 0 {
+		failureThreshold = DefaultFailureThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultCooldown
+	}
+	return &CircuitBreaker{
+		state:            StateClosed,
+		failureThreshold: failureThreshold,
+		cooldown:         cooldown,
+		now:              time.Now,
+	}
+}
+
+// OnStateChange 设置状态变化回调，回调在锁外同步执行
+func (c *CircuitBreaker) OnStateChange(fn StateChangeFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onStateChange = fn
+}
+
+// SetClock 替换时间来源，便于测试或在模拟时钟下运行
+func (c *CircuitBreaker) SetClock(now func() time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// State 返回熔断器当前状态
+// 打开状态下冷却时间已过时返回 StateHalfOpen，真正的状态切换发生在下一次 Allow 时
+func (c *CircuitBreaker) State() CircuitState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state == StateOpen && c.cooldownElapsed() {
+		return StateHalfOpen
+	}
+	return c.state
+}
+
+// Failures 返回当前连续失败次数
+func (c *CircuitBreaker) Failures() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.failures
+}
+
+// Reset 手动关闭熔断器并清零失败计数
+func (c *CircuitBreaker) Reset() {
+	c.mu.Lock()
+	from := c.state
+	c.failures = 0
+	c.probing = false
+	c.state = StateClosed
+	notify := c.onStateChange
+	c.mu.Unlock()
+
+	c.notify(notify, from, StateClosed)
+}
+
+// Allow 判断请求是否可以放行，必要时从打开状态切换到半开状态
+// 拒绝时返回剩余的冷却时间，半开状态下因试探请求正在执行而拒绝时为 0
+func (c *CircuitBreaker) Allow() (bool, time.Duration) {
+	c.mu.Lock()
+	from := c.state
+	switch c.state {
+	case StateOpen:
+		if remaining := c.cooldown - c.now().Sub(c.openedAt); remaining > 0 {
+			c.mu.Unlock()
+			return false, remaining
+		}
+		c.state = StateHalfOpen
+		c.probing = true
+	case StateHalfOpen:
+		// 半开状态只允许一个试探请求
+		if c.probing {
+			c.mu.Unlock()
+			return false, 0
+		}
+		c.probing = true
+	}
+	to := c.state
+	notify := c.onStateChange
+	c.mu.Unlock()
+
+	c.notify(notify, from, to)
+	return true, 0
+}
+
+// Record 记录一次调用的结果，每次 Allow 放行之后必须调用一次
+func (c *CircuitBreaker) Record(success bool) {
+	c.mu.Lock()
+	from := c.state
+	if c.state == StateHalfOpen {
+		c.probing = false
+	}
+	if success {
+		c.failures = 0
+		c.state = StateClosed
+	} else {
+		c.failures++
+		if c.state == StateHalfOpen || c.failures >= c.failureThreshold {
+			c.state = StateOpen
+			c.openedAt = c.now()
+		}
+	}
+	to := c.state
+	notify := c.onStateChange
+	c.mu.Unlock()
+
+	c.notify(notify, from, to)
+}
+
+// cooldownElapsed 判断冷却时间是否已过，调用方需持有锁
+func (c *CircuitBreaker) cooldownElapsed() bool {
+	return c.now().Sub(c.openedAt) >= c.cooldown
+}
+
+// notify 在状态确实发生变化时调用回调
+func (c *CircuitBreaker) notify(fn StateChangeFunc, from, to CircuitState) {
+	if fn != nil && from != to {
+		fn(from, to)
+	}
+}
@@ -0,0 +1,63 @@
+package proxy
+
+import (
+	"time"
+)
+
+// CircuitBreakerProxy 熔断代理 - 在被代理对象频繁失败时快速失败，保护下游
+// 连续失败达到阈值后打开熔断器；冷却时间结束后进入半开状态试探一次，
+// 试探成功则恢复正常，失败则继续熔断。状态机由 CircuitBreaker 实现
+type CircuitBreakerProxy struct {
+	realBuyer IBuyCar
+	breaker   *CircuitBreaker
+}
+
+// NewCircuitBreakerProxy 创建熔断代理
+// failureThreshold 为打开熔断器所需的连续失败次数，cooldown 为打开后的冷却时间，
+// 非正值时分别使用 DefaultFailureThreshold 和 DefaultCooldown
+func NewCircuitBreakerProxy(buyer IBuyCar, failureThreshold int, cooldown time.Duration) *CircuitBreakerProxy {
+	return &CircuitBreakerProxy{
+		realBuyer: buyer,
+		breaker:   NewCircuitBreaker(failureThreshold, cooldown),
+	}
+}
+
+// OnStateChange 设置状态变化回调，回调在锁外同步执行
+func (c *CircuitBreakerProxy) OnStateChange(fn StateChangeFunc) {
+	c.breaker.OnStateChange(fn)
+}
+
+// State 返回熔断器当前状态
+// 打开状态下冷却时间已过时返回 StateHalfOpen，真正的状态切换发生在下一次调用时
+func (c *CircuitBreakerProxy) State() CircuitState {
+	return c.breaker.State()
+}
+
+// Failures 返回当前连续失败次数
+func (c *CircuitBreakerProxy) Failures() int {
+	return c.breaker.Failures()
+}
+
+// Reset 手动关闭熔断器并清零失败计数
+func (c *CircuitBreakerProxy) Reset() {
+	c.breaker.Reset()
+}
+
+// BuyCar 代理购车方法，熔断器打开时直接返回 ErrCircuitOpen
+func (c *CircuitBreakerProxy) BuyCar() error {
+	if ok, _ := c.breaker.Allow(); !ok {
+		return ErrCircuitOpen
+	}
+	err := c.realBuyer.BuyCar()
+	c.breaker.Record(err == nil)
+	return err
+}
+
+// GetCarInfo 获取车辆信息
+// 查询信息不会失败，因此不参与熔断统计；熔断期间返回降级信息，避免打扰下游
+func (c *CircuitBreakerProxy) GetCarInfo() string {
+	if c.State() == StateOpen {
+		return "车辆信息暂不可用 (熔断中)"
+	}
+	return c.realBuyer.GetCarInfo()
+}
@@ -0,0 +1,261 @@
+package proxy
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyBuyer 按预设结果依次返回的购车者，用于模拟不稳定的下游
+type flakyBuyer struct {
+	mu      sync.Mutex
+	results []error
+	calls   int
+}
+
+func (f *flakyBuyer) BuyCar() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if len(f.results) == 0 {
+		return nil
+	}
+	err := f.results[0]
+	f.results = f.results[1:]
+	return err
+}
+
+func (f *flakyBuyer) GetCarInfo() string {
+	return "不稳定车型"
+}
+
+func (f *flakyBuyer) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+// fakeClock 可手动推进的时钟
+type fakeClock struct {
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time { return f.now }
+
+func (f *fakeClock) Advance(d time.Duration) { f.now = f.now.Add(d) }
+
+func newTestBreaker(buyer IBuyCar, threshold int, cooldown time.Duration) (*CircuitBreakerProxy, *fakeClock, *[]string) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	breaker := NewCircuitBreakerProxy(buyer, threshold, cooldown)
+	breaker.breaker.SetClock(clock.Now)
+
+	var transitions []string
+	breaker.OnStateChange(func(from, to CircuitState) {
+		transitions = append(transitions, from.String()+"->"+to.String())
+	})
+	return breaker, clock, &transitions
+}
+
+func TestCircuitBreakerProxy_OpensAfterThreshold(t *testing.T) {
+	errBoom := errors.New("下游故障")
+	buyer := &flakyBuyer{results: []error{errBoom, errBoom, errBoom}}
+	breaker, _, transitions := newTestBreaker(buyer, 3, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if err := breaker.BuyCar(); !errors.Is(err, errBoom) {
+			t.Fatalf("第 %d 次调用应返回下游错误，实际为 %v", i+1, err)
+		}
+	}
+	if breaker.State() != StateOpen {
+		t.Fatalf("连续失败 3 次后应处于打开状态，实际为 %s", breaker.State())
+	}
+
+	// 打开状态下快速失败，不再调用下游
+	if err := breaker.BuyCar(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("熔断期间应返回 ErrCircuitOpen，实际为 %v", err)
+	}
+	if buyer.Calls() != 3 {
+		t.Errorf("熔断期间不应调用下游，下游调用次数为 %d", buyer.Calls())
+	}
+	if got := breaker.GetCarInfo(); got != "车辆信息暂不可用 (熔断中)" {
+		t.Errorf("熔断期间应返回降级信息，实际为 %q", got)
+	}
+	if len(*transitions) != 1 || (*transitions)[0] != "closed->open" {
+		t.Errorf("状态变化记录不正确: %v", *transitions)
+	}
+}
+
+func TestCircuitBreakerProxy_SuccessResetsFailures(t *testing.T) {
+	errBoom := errors.New("下游故障")
+	buyer := &flakyBuyer{results: []error{errBoom, errBoom, nil, errBoom, errBoom}}
+	breaker, _, _ := newTestBreaker(buyer, 3, time.Minute)
+
+	for i := 0; i < 5; i++ {
+		_ = breaker.BuyCar()
+	}
+	if breaker.State() != StateClosed {
+		t.Errorf("失败不连续时不应打开熔断器，实际状态为 %s", breaker.State())
+	}
+	if breaker.Failures() != 2 {
+		t.Errorf("连续失败次数应为 2，实际为 %d", breaker.Failures())
+	}
+}
+
+func TestCircuitBreakerProxy_HalfOpen(t *testing.T) {
+	errBoom := errors.New("下游故障")
+
+	t.Run("试探成功后关闭", func(t *testing.T) {
+		buyer := &flakyBuyer{results: []error{errBoom, errBoom}}
+		breaker, clock, transitions := newTestBreaker(buyer, 2, time.Minute)
+		_ = breaker.BuyCar()
+		_ = breaker.BuyCar()
+
+		clock.Advance(time.Minute)
+		if breaker.State() != StateHalfOpen {
+			t.Fatalf("冷却结束后应处于半开状态，实际为 %s", breaker.State())
+		}
+		if err := breaker.BuyCar(); err != nil {
+			t.Fatalf("试探请求应成功，实际为 %v", err)
+		}
+		if breaker.State() != StateClosed {
+			t.Errorf("试探成功后应关闭熔断器，实际为 %s", breaker.State())
+		}
+
+		want := []string{"closed->open", "open->half-open", "half-open->closed"}
+		if len(*transitions) != len(want) {
+			t.Fatalf("状态变化应为 %v，实际为 %v", want, *transitions)
+		}
+		for i := range want {
+			if (*transitions)[i] != want[i] {
+				t.Errorf("状态变化应为 %v，实际为 %v", want, *transitions)
+				break
+			}
+		}
+	})
+
+	t.Run("试探失败后重新打开", func(t *testing.T) {
+		buyer := &flakyBuyer{results: []error{errBoom, errBoom, errBoom}}
+		breaker, clock, _ := newTestBreaker(buyer, 2, time.Minute)
+		_ = breaker.BuyCar()
+		_ = breaker.BuyCar()
+
+		clock.Advance(time.Minute)
+		if err := breaker.BuyCar(); !errors.Is(err, errBoom) {
+			t.Fatalf("试探请求应返回下游错误，实际为 %v", err)
+		}
+		if breaker.State() != StateOpen {
+			t.Errorf("试探失败后应重新打开，实际为 %s", breaker.State())
+		}
+
+		// 冷却时间重新计算
+		clock.Advance(30 * time.Second)
+		if err := breaker.BuyCar(); !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("新的冷却期内应快速失败，实际为 %v", err)
+		}
+	})
+
+	t.Run("半开状态只放行一个试探请求", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{})
+		slow := &blockingBuyer{started: started, release: release}
+		breaker, clock, _ := newTestBreaker(slow, 1, time.Minute)
+		breaker.breaker.state = StateOpen
+		breaker.breaker.openedAt = clock.Now()
+		clock.Advance(time.Minute)
+
+		done := make(chan error)
+		go func() { done <- breaker.BuyCar() }()
+		<-started
+
+		if err := breaker.BuyCar(); !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("试探请求执行期间应拒绝其他请求，实际为 %v", err)
+		}
+		close(release)
+		if err := <-done; err != nil {
+			t.Errorf("试探请求应成功，实际为 %v", err)
+		}
+	})
+}
+
+func TestCircuitBreakerProxy_Reset(t *testing.T) {
+	buyer := &flakyBuyer{results: []error{errors.New("下游故障")}}
+	breaker, _, transitions := newTestBreaker(buyer, 1, time.Hour)
+	_ = breaker.BuyCar()
+
+	breaker.Reset()
+	if breaker.State() != StateClosed || breaker.Failures() != 0 {
+		t.Errorf("重置后应处于关闭状态且失败计数清零")
+	}
+	if err := breaker.BuyCar(); err != nil {
+		t.Errorf("重置后请求应正常转发，实际为 %v", err)
+	}
+	if got := (*transitions)[len(*transitions)-1]; got != "open->closed" {
+		t.Errorf("最后一次状态变化应为 open->closed，实际为 %s", got)
+	}
+}
+
+func TestCircuitBreakerProxy_Chain(t *testing.T) {
+	// 熔断代理可以与其他代理组合
+	collector := newRecordingCollector()
+	buyer := NewRealBuyer("小王", 50000) // 余额不足，每次都会失败
+	breaker := NewCircuitBreakerProxy(NewMetricsProxy(buyer, collector), 2, time.Hour)
+
+	for i := 0; i < 5; i++ {
+		_ = breaker.BuyCar()
+	}
+
+	collector.mu.Lock()
+	calls := collector.calls[MethodBuyCar]
+	collector.mu.Unlock()
+	if calls != 2 {
+		t.Errorf("熔断后下游只应被调用 2 次，实际为 %d", calls)
+	}
+}
+
+// blockingBuyer 在 BuyCar 中阻塞，直到 release 被关闭
+type blockingBuyer struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingBuyer) BuyCar() error {
+	close(b.started)
+	<-b.release
+	return nil
+}
+
+func (b *blockingBuyer) GetCarInfo() string {
+	return "阻塞车型"
+}
+
+// 测试不绑定接口的熔断器：拒绝时返回剩余冷却时间，半开状态只放行一个试探请求
+func TestCircuitBreaker_AllowRecord(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	b := NewCircuitBreaker(1, time.Minute)
+	b.SetClock(clock.Now)
+
+	if ok, _ := b.Allow(); !ok {
+		t.Fatal("关闭状态应放行")
+	}
+	b.Record(false)
+	clock.Advance(20 * time.Second)
+	if ok, wait := b.Allow(); ok || wait != 40*time.Second {
+		t.Errorf("打开状态应拒绝并返回剩余冷却时间，实际为 %v %v", ok, wait)
+	}
+
+	clock.Advance(40 * time.Second)
+	if ok, _ := b.Allow(); !ok {
+		t.Fatal("冷却结束后应放行试探请求")
+	}
+	if b.State() != StateHalfOpen {
+		t.Errorf("试探期间应处于半开状态，实际为 %s", b.State())
+	}
+	if ok, wait := b.Allow(); ok || wait != 0 {
+		t.Errorf("试探期间其他请求应被拒绝，实际为 %v %v", ok, wait)
+	}
+	b.Record(true)
+	if ok, _ := b.Allow(); !ok || b.State() != StateClosed {
+		t.Errorf("试探成功后应关闭，实际为 %s", b.State())
+	}
+}
@@ -0,0 +1,284 @@
+package proxy
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 方法名常量，作为指标的维度
+const (
+	MethodBuyCar     = "BuyCar"
+	MethodGetCarInfo = "GetCarInfo"
+)
+
+// defaultMaxSamples 每个方法默认保留的延迟样本数量
+const defaultMaxSamples = 1024
+
+// Collector 指标收集器接口
+// MetricsProxy 只依赖该接口，便于替换为 Prometheus 等其他实现
+type Collector interface {
+	// IncCall 记录一次方法调用
+	IncCall(method string)
+	// IncError 记录一次方法调用失败
+	IncError(method string)
+	// ObserveLatency 记录一次方法调用的耗时
+	ObserveLatency(method string, d time.Duration)
+}
+
+// LatencyHistogram 延迟直方图，保留最近的若干个样本用于计算百分位数
+type LatencyHistogram struct {
+	mu         sync.Mutex
+	samples    []time.Duration
+	next       int
+	maxSamples int
+	count      int64
+	sum        time.Duration
+}
+
+// NewLatencyHistogram 创建延迟直方图，maxSamples 为保留的样本上限
+func NewLatencyHistogram(maxSamples int) *LatencyHistogram {
+	if maxSamples <= 0 {
+		maxSamples = defaultMaxSamples
+	}
+	return &LatencyHistogram{
+		samples:    make([]time.Duration, 0, maxSamples),
+		maxSamples: maxSamples,
+	}
+}
+
+// Observe 记录一个延迟样本，超过上限时覆盖最旧的样本
+func (h *LatencyHistogram) Observe(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.count++
+	h.sum += d
+	if len(h.samples) < h.maxSamples {
+		h.samples = append(h.samples, d)
+		return
+	}
+	h.samples[h.next] = d
+	h.next = (h.next + 1) % h.maxSamples
+}
+
+// Percentile 返回第 p 百分位的延迟（p 取值 0-100），没有样本时返回 0
+func (h *LatencyHistogram) Percentile(p float64) time.Duration {
+	h.mu.Lock()
+	sorted := make([]time.Duration, len(h.samples))
+	copy(sorted, h.samples)
+	h.mu.Unlock()
+
+	if len(sorted) == 0 {
+		return 0
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	if p <= 0 {
+		return sorted[0]
+	}
+	if p >= 100 {
+		return sorted[len(sorted)-1]
+	}
+	// 最近秩法计算百分位
+	rank := int(p/100*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+// Count 返回累计观测次数
+func (h *LatencyHistogram) Count() int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count
+}
+
+// Mean 返回累计平均延迟
+func (h *LatencyHistogram) Mean() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.count == 0 {
+		return 0
+	}
+	return h.sum / time.Duration(h.count)
+}
+
+// LatencySummary 延迟摘要，用于展示和导出
+type LatencySummary struct {
+	Count int64         `json:"count"`
+	Mean  time.Duration `json:"mean"`
+	P50   time.Duration `json:"p50"`
+	P90   time.Duration `json:"p90"`
+	P99   time.Duration `json:"p99"`
+}
+
+// Summary 生成当前直方图的摘要
+func (h *LatencyHistogram) Summary() LatencySummary {
+	return LatencySummary{
+		Count: h.Count(),
+		Mean:  h.Mean(),
+		P50:   h.Percentile(50),
+		P90:   h.Percentile(90),
+		P99:   h.Percentile(99),
+	}
+}
+
+// ExpvarCollector 基于 expvar 的默认指标收集器
+// 调用次数和错误次数存放在 expvar.Map 中，延迟以直方图摘要的形式导出
+type ExpvarCollector struct {
+	calls  *expvar.Map
+	errors *expvar.Map
+
+	mu         sync.Mutex
+	latencies  map[string]*LatencyHistogram
+	maxSamples int
+}
+
+// NewExpvarCollector 创建一个未发布的 expvar 收集器
+// 如需通过 /debug/vars 暴露，请调用 Publish
+func NewExpvarCollector() *ExpvarCollector {
+	return &ExpvarCollector{
+		calls:      new(expvar.Map).Init(),
+		errors:     new(expvar.Map).Init(),
+		latencies:  make(map[string]*LatencyHistogram),
+		maxSamples: defaultMaxSamples,
+	}
+}
+
+// IncCall 记录一次方法调用
+func (c *ExpvarCollector) IncCall(method string) {
+	c.calls.Add(method, 1)
+}
+
+// IncError 记录一次方法调用失败
+func (c *ExpvarCollector) IncError(method string) {
+	c.errors.Add(method, 1)
+}
+
+// ObserveLatency 记录一次方法调用的耗时
+func (c *ExpvarCollector) ObserveLatency(method string, d time.Duration) {
+	c.histogram(method).Observe(d)
+}
+
+// histogram 获取（必要时创建）指定方法的延迟直方图
+func (c *ExpvarCollector) histogram(method string) *LatencyHistogram {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	h, ok := c.latencies[method]
+	if !ok {
+		h = NewLatencyHistogram(c.maxSamples)
+		c.latencies[method] = h
+	}
+	return h
+}
+
+// Calls 返回指定方法的调用次数
+func (c *ExpvarCollector) Calls(method string) int64 {
+	return intValue(c.calls.Get(method))
+}
+
+// Errors 返回指定方法的失败次数
+func (c *ExpvarCollector) Errors(method string) int64 {
+	return intValue(c.errors.Get(method))
+}
+
+// Latency 返回指定方法的延迟摘要
+func (c *ExpvarCollector) Latency(method string) LatencySummary {
+	return c.histogram(method).Summary()
+}
+
+// String 以 JSON 格式输出所有指标，实现 expvar.Var 接口
+func (c *ExpvarCollector) String() string {
+	c.mu.Lock()
+	latency := make(map[string]LatencySummary, len(c.latencies))
+	for method, h := range c.latencies {
+		latency[method] = h.Summary()
+	}
+	c.mu.Unlock()
+
+	data, _ := json.Marshal(map[string]any{
+		"calls":   json.RawMessage(c.calls.String()),
+		"errors":  json.RawMessage(c.errors.String()),
+		"latency": latency,
+	})
+	return string(data)
+}
+
+// expvarMutex 串行化发布：expvar.Get 和 expvar.Publish 之间不能插入另一次同名发布，否则 expvar 会 panic
+var expvarMutex sync.Mutex
+
+// Publish 将收集器以指定名称发布到 expvar
+// expvar 不允许重复发布同名变量，因此名称冲突时返回错误而不是 panic
+func (c *ExpvarCollector) Publish(name string) error {
+	expvarMutex.Lock()
+	defer expvarMutex.Unlock()
+	if expvar.Get(name) != nil {
+		return fmt.Errorf("expvar 变量 %q 已存在", name)
+	}
+	expvar.Publish(name, c)
+	return nil
+}
+
+// intValue 从 expvar.Var 中取出整数值
+func intValue(v expvar.Var) int64 {
+	if iv, ok := v.(*expvar.Int); ok {
+		return iv.Value()
+	}
+	return 0
+}
+
+// MetricsProxy 指标代理 - 记录调用次数、错误次数和延迟分布
+// 与日志代理只打印信息不同，指标代理把数据写入可观测系统
+type MetricsProxy struct {
+	realBuyer IBuyCar
+	collector Collector
+	now       func() time.Time
+}
+
+// NewMetricsProxy 创建指标代理，collector 为 nil 时使用默认的 expvar 收集器
+func NewMetricsProxy(buyer IBuyCar, collector Collector) *MetricsProxy {
+	if collector == nil {
+		collector = NewExpvarCollector()
+	}
+	return &MetricsProxy{
+		realBuyer: buyer,
+		collector: collector,
+		now:       time.Now,
+	}
+}
+
+// Collector 返回代理使用的指标收集器
+func (m *MetricsProxy) Collector() Collector {
+	return m.collector
+}
+
+// BuyCar 代理购车方法，记录调用、错误和延迟
+func (m *MetricsProxy) BuyCar() error {
+	start := m.now()
+	err := m.realBuyer.BuyCar()
+	m.record(MethodBuyCar, start, err)
+	return err
+}
+
+// GetCarInfo 代理获取车辆信息的方法，记录调用和延迟
+func (m *MetricsProxy) GetCarInfo() string {
+	start := m.now()
+	info := m.realBuyer.GetCarInfo()
+	m.record(MethodGetCarInfo, start, nil)
+	return info
+}
+
+// record 写入一次调用的指标
+func (m *MetricsProxy) record(method string, start time.Time, err error) {
+	m.collector.IncCall(method)
+	if err != nil {
+		m.collector.IncError(method)
+	}
+	m.collector.ObserveLatency(method, m.now().Sub(start))
+}
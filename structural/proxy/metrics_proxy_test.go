@@ -0,0 +1,270 @@
+package proxy
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// expvarSeq 为每次发布生成不同的名称；expvar 是进程级的，go test -count=N 时同名发布会冲突
+var expvarSeq atomic.Int64
+
+// 记录调用的收集器，便于断言
+type recordingCollector struct {
+	mu        sync.Mutex
+	calls     map[string]int
+	errors    map[string]int
+	latencies map[string][]time.Duration
+}
+
+func newRecordingCollector() *recordingCollector {
+	return &recordingCollector{
+		calls:     make(map[string]int),
+		errors:    make(map[string]int),
+		latencies: make(map[string][]time.Duration),
+	}
+}
+
+func (r *recordingCollector) IncCall(method string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls[method]++
+}
+
+func (r *recordingCollector) IncError(method string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors[method]++
+}
+
+func (r *recordingCollector) ObserveLatency(method string, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latencies[method] = append(r.latencies[method], d)
+}
+
+// 总是失败的购买者
+type failingBuyer struct{}
+
+func (failingBuyer) BuyCar() error      { return errors.New("模拟失败") }
+func (failingBuyer) GetCarInfo() string { return "故障车辆" }
+
+// 测试指标代理
+func TestMetricsProxy(t *testing.T) {
+	t.Run("记录成功调用", func(t *testing.T) {
+		collector := newRecordingCollector()
+		proxy := NewMetricsProxy(NewRealBuyer("指标测试", 250000), collector)
+
+		// 使用固定步长的时钟，使延迟可预测
+		base := time.Now()
+		tick := 0
+		proxy.now = func() time.Time {
+			tick++
+			return base.Add(time.Duration(tick) * 10 * time.Millisecond)
+		}
+
+		captureOutput(func() {
+			if err := proxy.BuyCar(); err != nil {
+				t.Errorf("购车应该成功，但出现错误: %v", err)
+			}
+			if err := proxy.BuyCar(); err != nil {
+				t.Errorf("购车应该成功，但出现错误: %v", err)
+			}
+		})
+		info := proxy.GetCarInfo()
+
+		if info != "标准汽车型号XYZ" {
+			t.Errorf("指标代理不应修改返回值，但得到: %s", info)
+		}
+		if collector.calls[MethodBuyCar] != 2 {
+			t.Errorf("BuyCar 调用次数应为2，但得到: %d", collector.calls[MethodBuyCar])
+		}
+		if collector.calls[MethodGetCarInfo] != 1 {
+			t.Errorf("GetCarInfo 调用次数应为1，但得到: %d", collector.calls[MethodGetCarInfo])
+		}
+		if collector.errors[MethodBuyCar] != 0 {
+			t.Errorf("成功调用不应记录错误，但得到: %d", collector.errors[MethodBuyCar])
+		}
+		for _, d := range collector.latencies[MethodBuyCar] {
+			if d != 10*time.Millisecond {
+				t.Errorf("延迟应为10ms，但得到: %v", d)
+			}
+		}
+	})
+
+	t.Run("记录失败调用", func(t *testing.T) {
+		collector := newRecordingCollector()
+		proxy := NewMetricsProxy(failingBuyer{}, collector)
+
+		if err := proxy.BuyCar(); err == nil {
+			t.Error("应返回被代理对象的错误，但没有")
+		}
+
+		if collector.calls[MethodBuyCar] != 1 {
+			t.Errorf("调用次数应为1，但得到: %d", collector.calls[MethodBuyCar])
+		}
+		if collector.errors[MethodBuyCar] != 1 {
+			t.Errorf("错误次数应为1，但得到: %d", collector.errors[MethodBuyCar])
+		}
+		if len(collector.latencies[MethodBuyCar]) != 1 {
+			t.Errorf("失败调用也应记录延迟，但得到 %d 个样本", len(collector.latencies[MethodBuyCar]))
+		}
+	})
+
+	t.Run("默认使用expvar收集器", func(t *testing.T) {
+		proxy := NewMetricsProxy(failingBuyer{}, nil)
+		proxy.BuyCar()
+
+		collector, ok := proxy.Collector().(*ExpvarCollector)
+		if !ok {
+			t.Fatalf("默认收集器应为 *ExpvarCollector，但得到: %T", proxy.Collector())
+		}
+		if collector.Calls(MethodBuyCar) != 1 || collector.Errors(MethodBuyCar) != 1 {
+			t.Errorf("期望调用1次失败1次，但得到调用%d次失败%d次",
+				collector.Calls(MethodBuyCar), collector.Errors(MethodBuyCar))
+		}
+	})
+}
+
+// 测试延迟直方图
+func TestLatencyHistogram(t *testing.T) {
+	t.Run("计算百分位数", func(t *testing.T) {
+		h := NewLatencyHistogram(0)
+		for i := 1; i <= 100; i++ {
+			h.Observe(time.Duration(i) * time.Millisecond)
+		}
+
+		cases := map[float64]time.Duration{
+			0:   1 * time.Millisecond,
+			50:  50 * time.Millisecond,
+			90:  90 * time.Millisecond,
+			99:  99 * time.Millisecond,
+			100: 100 * time.Millisecond,
+		}
+		for p, expected := range cases {
+			if got := h.Percentile(p); got != expected {
+				t.Errorf("P%.0f 应为 %v，但得到: %v", p, expected, got)
+			}
+		}
+
+		if h.Count() != 100 {
+			t.Errorf("样本数应为100，但得到: %d", h.Count())
+		}
+		if h.Mean() != 50500*time.Microsecond {
+			t.Errorf("平均值应为50.5ms，但得到: %v", h.Mean())
+		}
+	})
+
+	t.Run("超过上限时覆盖最旧样本", func(t *testing.T) {
+		h := NewLatencyHistogram(3)
+		for _, d := range []time.Duration{100, 1, 2, 3} {
+			h.Observe(d)
+		}
+
+		if h.Percentile(100) != 3 {
+			t.Errorf("最旧的样本应被覆盖，最大值应为3，但得到: %v", h.Percentile(100))
+		}
+		if h.Count() != 4 {
+			t.Errorf("累计次数应包含被覆盖的样本，期望4，但得到: %d", h.Count())
+		}
+	})
+
+	t.Run("无样本时返回0", func(t *testing.T) {
+		h := NewLatencyHistogram(10)
+		if h.Percentile(50) != 0 || h.Mean() != 0 {
+			t.Error("无样本时百分位和平均值应为0")
+		}
+	})
+}
+
+// 测试expvar收集器
+func TestExpvarCollector(t *testing.T) {
+	t.Run("导出JSON", func(t *testing.T) {
+		c := NewExpvarCollector()
+		c.IncCall(MethodBuyCar)
+		c.IncError(MethodBuyCar)
+		c.ObserveLatency(MethodBuyCar, 5*time.Millisecond)
+
+		var data struct {
+			Calls   map[string]int64          `json:"calls"`
+			Errors  map[string]int64          `json:"errors"`
+			Latency map[string]LatencySummary `json:"latency"`
+		}
+		if err := json.Unmarshal([]byte(c.String()), &data); err != nil {
+			t.Fatalf("String() 应输出合法JSON，但解析失败: %v", err)
+		}
+
+		if data.Calls[MethodBuyCar] != 1 || data.Errors[MethodBuyCar] != 1 {
+			t.Errorf("导出的计数不正确: %+v", data)
+		}
+		if data.Latency[MethodBuyCar].P50 != 5*time.Millisecond {
+			t.Errorf("导出的P50应为5ms，但得到: %v", data.Latency[MethodBuyCar].P50)
+		}
+	})
+
+	t.Run("发布到expvar", func(t *testing.T) {
+		c := NewExpvarCollector()
+		name := fmt.Sprintf("%s_%d", t.Name(), expvarSeq.Add(1))
+
+		if err := c.Publish(name); err != nil {
+			t.Fatalf("首次发布应成功，但出现错误: %v", err)
+		}
+		if expvar.Get(name) != c {
+			t.Error("发布后应能通过 expvar.Get 获取收集器")
+		}
+
+		err := NewExpvarCollector().Publish(name)
+		if err == nil || !strings.Contains(err.Error(), "已存在") {
+			t.Errorf("重复发布应返回错误，但得到: %v", err)
+		}
+	})
+
+	t.Run("并发发布同名变量", func(t *testing.T) {
+		name := fmt.Sprintf("%s_%d", t.Name(), expvarSeq.Add(1))
+		var wg sync.WaitGroup
+		var published atomic.Int64
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if NewExpvarCollector().Publish(name) == nil {
+					published.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+		if published.Load() != 1 {
+			t.Errorf("并发发布同名变量应只有一次成功，但成功了 %d 次", published.Load())
+		}
+	})
+
+	t.Run("并发记录", func(t *testing.T) {
+		c := NewExpvarCollector()
+		proxy := NewMetricsProxy(failingBuyer{}, c)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				proxy.BuyCar()
+				proxy.GetCarInfo()
+			}()
+		}
+		wg.Wait()
+
+		if c.Calls(MethodBuyCar) != 50 || c.Calls(MethodGetCarInfo) != 50 {
+			t.Errorf("并发调用次数不正确: BuyCar=%d GetCarInfo=%d",
+				c.Calls(MethodBuyCar), c.Calls(MethodGetCarInfo))
+		}
+		if c.Latency(MethodBuyCar).Count != 50 {
+			t.Errorf("延迟样本数应为50，但得到: %d", c.Latency(MethodBuyCar).Count)
+		}
+	})
+}
@@ -485,10 +485,12 @@ func DemoProxyChain() {
 	// 创建实际购买者
 	buyer := NewRealBuyer("复合代理客户", 200000)
 
-	// 创建代理链：缓存代理 -> 保护代理 -> 日志代理 -> 4S店代理 -> 实际购买者
+	// 创建代理链：缓存代理 -> 保护代理 -> 指标代理 -> 日志代理 -> 4S店代理 -> 实际购买者
 	fourSProxy := NewFourSProxy(buyer)
 	loggingProxy := NewLoggingProxy(fourSProxy)
-	protectionProxy := NewProtectionProxy(loggingProxy, true)
+	collector := NewExpvarCollector()
+	metricsProxy := NewMetricsProxy(loggingProxy, collector)
+	protectionProxy := NewProtectionProxy(metricsProxy, true)
 	cachedProxy := NewCachedBuyerProxy(protectionProxy)
 
 	// 通过代理链获取车辆信息（第一次）
@@ -505,6 +507,12 @@ func DemoProxyChain() {
 	fmt.Println("\n=== 通过代理链购车 ===")
 	cachedProxy.BuyCar()
 
+	// 输出指标代理收集到的真实数据
+	fmt.Println("\n=== 指标 ===")
+	fmt.Printf("BuyCar 调用 %d 次，失败 %d 次，P99 延迟 %v\n",
+		collector.Calls(MethodBuyCar), collector.Errors(MethodBuyCar), collector.Latency(MethodBuyCar).P99)
+	fmt.Printf("GetCarInfo 调用 %d 次\n", collector.Calls(MethodGetCarInfo))
+
 	// 不会生成完全一致的Output，因为有时间戳
 	// 所以这里仅作为示例，不作为测试
 }
@@ -0,0 +1,92 @@
+package double_checked
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Initializer 延迟初始化的共享实例，三种实现的对外行为相同，区别在于同步的开销
+type Initializer[T any] interface {
+	Get() *T // 返回实例，第一次调用时创建
+}
+
+// MutexAlways 每次访问都加锁：简单、正确，但实例创建之后的每次读取仍然要竞争同一把锁
+type MutexAlways[T any] struct {
+	mutex    sync.Mutex
+	instance *T
+	create   func() *T
+}
+
+// NewMutexAlways 创建每次访问都加锁的延迟初始化实例
+func NewMutexAlways[T any](create func() *T) *MutexAlways[T] {
+	return &MutexAlways[T]{create: create}
+}
+
+// Get 返回实例
+func (m *MutexAlways[T]) Get() *T {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.instance == nil {
+		m.instance = m.create()
+	}
+	return m.instance
+}
+
+// DoubleChecked 正确的双重检查锁定
+//
+// 第一次检查在锁外通过原子读取完成，实例创建之后的访问不需要加锁（快速路径）；
+// 第一次检查失败时加锁并再次检查，保证只创建一次。
+// 关键在于实例指针必须通过 atomic.Pointer 发布：原子写入之前对实例的所有初始化，
+// 对通过原子读取看到该指针的协程都是可见的。用普通指针实现时，读者可能看到
+// 非 nil 的指针却读到尚未初始化完成的字段，参见 racy.go。
+type DoubleChecked[T any] struct {
+	mutex    sync.Mutex
+	instance atomic.Pointer[T]
+	create   func() *T
+}
+
+// NewDoubleChecked 创建双重检查锁定的延迟初始化实例
+func NewDoubleChecked[T any](create func() *T) *DoubleChecked[T] {
+	return &DoubleChecked[T]{create: create}
+}
+
+// Get 返回实例
+func (d *DoubleChecked[T]) Get() *T {
+	// 第一次检查：无锁的快速路径
+	if instance := d.instance.Load(); instance != nil {
+		return instance
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	// 第二次检查：等待锁期间其他协程可能已经创建了实例
+	if instance := d.instance.Load(); instance != nil {
+		return instance
+	}
+	instance := d.create()
+	d.instance.Store(instance) // 初始化完成后才发布
+	return instance
+}
+
+// Once 基于 sync.Once 的实现：Go 中双重检查锁定的惯用写法
+// sync.Once 内部正是"原子标志 + 互斥锁"的双重检查，大多数情况下应直接使用它
+type Once[T any] struct {
+	once     sync.Once
+	instance *T
+	create   func() *T
+}
+
+// NewOnce 创建基于 sync.Once 的延迟初始化实例
+func NewOnce[T any](create func() *T) *Once[T] {
+	return &Once[T]{create: create}
+}
+
+// Get 返回实例
+func (o *Once[T]) Get() *T {
+	o.once.Do(func() {
+		o.instance = o.create()
+	})
+	return o.instance
+}
@@ -0,0 +1,92 @@
+package double_checked
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// config 初始化开销较大的共享配置
+type config struct {
+	name    string
+	entries map[string]int
+}
+
+var created atomic.Int32
+
+func newConfig() *config {
+	created.Add(1)
+	entries := make(map[string]int, 100)
+	for i := 0; i < 100; i++ {
+		entries[string(rune('a'+i%26))] = i
+	}
+	return &config{name: "生产环境", entries: entries}
+}
+
+// assertSingleInstance 让多个协程同时第一次访问，检查只创建了一个完整初始化的实例
+func assertSingleInstance(t *testing.T, init Initializer[config]) {
+	t.Helper()
+	created.Store(0)
+
+	const n = 64
+	results := make([]*config, n)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			results[i] = init.Get()
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	if created.Load() != 1 {
+		t.Errorf("期望创建1次，但创建了 %d 次", created.Load())
+	}
+	for i, c := range results {
+		if c != results[0] {
+			t.Fatalf("第 %d 个协程得到了不同的实例", i)
+		}
+		if c.name != "生产环境" || len(c.entries) != 26 {
+			t.Fatalf("第 %d 个协程看到了未初始化完成的实例: %+v", i, c)
+		}
+	}
+}
+
+func TestMutexAlways(t *testing.T) {
+	assertSingleInstance(t, NewMutexAlways(newConfig))
+}
+
+func TestDoubleChecked(t *testing.T) {
+	assertSingleInstance(t, NewDoubleChecked(newConfig))
+}
+
+func TestOnce(t *testing.T) {
+	assertSingleInstance(t, NewOnce(newConfig))
+}
+
+// 对比实例创建之后的读取开销：每次加锁 vs 双重检查的无锁快速路径
+func BenchmarkGet(b *testing.B) {
+	implementations := []struct {
+		name string
+		init Initializer[config]
+	}{
+		{"MutexAlways", NewMutexAlways(newConfig)},
+		{"DoubleChecked", NewDoubleChecked(newConfig)},
+		{"Once", NewOnce(newConfig)},
+	}
+
+	for _, impl := range implementations {
+		impl.init.Get() // 预先创建，只测量快速路径
+		b.Run(impl.name, func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					impl.init.Get()
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,37 @@
+//go:build dcl_racy
+
+package double_checked
+
+import "sync"
+
+// RacyDoubleChecked 错误的双重检查锁定，仅用于教学，默认不参与编译
+//
+// 使用 -tags dcl_racy 编译，并用 go test -race -tags dcl_racy 观察数据竞争报告。
+// 问题出在锁外的第一次检查：普通指针的读写之间没有 happens-before 关系，
+// 编译器和 CPU 可以重排"初始化字段"和"写入指针"两个操作，
+// 于是读者可能看到非 nil 的指针，却读到尚未初始化完成的字段。
+type RacyDoubleChecked[T any] struct {
+	mutex    sync.Mutex
+	instance *T // 错误：应该使用 atomic.Pointer
+	create   func() *T
+}
+
+// NewRacyDoubleChecked 创建错误的双重检查锁定实例
+func NewRacyDoubleChecked[T any](create func() *T) *RacyDoubleChecked[T] {
+	return &RacyDoubleChecked[T]{create: create}
+}
+
+// Get 返回实例，锁外的读取与锁内的写入构成数据竞争
+func (r *RacyDoubleChecked[T]) Get() *T {
+	if r.instance != nil { // 数据竞争：没有同步的读取
+		return r.instance
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.instance == nil {
+		r.instance = r.create() // 数据竞争：与锁外的读取并发
+	}
+	return r.instance
+}
@@ -0,0 +1,12 @@
+//go:build dcl_racy
+
+package double_checked
+
+import "testing"
+
+// 在 -race 下运行时，竞争检测器会报告 RacyDoubleChecked 的数据竞争
+// go test -race -tags dcl_racy -run TestRacyDoubleChecked ./synchronization/double_checked
+func TestRacyDoubleChecked(t *testing.T) {
+	racy := NewRacyDoubleChecked(newConfig)
+	assertSingleInstance(t, racy)
+}
@@ -0,0 +1,44 @@
+package guarded
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotReady 前置条件不满足，操作被放弃
+var ErrNotReady = errors.New("对象尚未就绪")
+
+// Balking 犹豫（Balking）模式的包装器
+// 与保护性暂停不同，前置条件不满足时不等待，而是立即以 ErrNotReady 放弃操作，
+// 适合"现在做不了就算了"的场景：服务未预热时拒绝请求、没有修改时跳过保存等
+type Balking struct {
+	name  string
+	ready func() bool // 前置条件，必须是并发安全的
+}
+
+// NewBalking 创建犹豫包装器，name 出现在错误信息中
+func NewBalking(name string, ready func() bool) *Balking {
+	return &Balking{name: name, ready: ready}
+}
+
+// Ready 前置条件当前是否满足
+func (b *Balking) Ready() bool {
+	return b.ready()
+}
+
+// Do 前置条件满足时执行 op，否则立即返回 ErrNotReady
+func (b *Balking) Do(op func() error) error {
+	if !b.ready() {
+		return fmt.Errorf("%w: %s", ErrNotReady, b.name)
+	}
+	return op()
+}
+
+// Call 前置条件满足时执行 fn 并返回结果，否则立即返回 ErrNotReady
+func Call[T any](b *Balking, fn func() (T, error)) (T, error) {
+	if !b.ready() {
+		var zero T
+		return zero, fmt.Errorf("%w: %s", ErrNotReady, b.name)
+	}
+	return fn()
+}
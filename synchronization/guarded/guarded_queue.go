@@ -0,0 +1,111 @@
+package guarded
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrQueueClosed 队列已关闭且没有满足条件的元素
+var ErrQueueClosed = errors.New("队列已关闭")
+
+// GuardedQueue 保护性暂停（Guarded Suspension）队列
+// Get 在守护条件（队列中有满足条件的元素）成立之前挂起调用者，条件成立后再继续执行；
+// 与 sync.Cond 不同，等待可以通过上下文取消
+type GuardedQueue[T any] struct {
+	mutex   sync.Mutex
+	items   []T
+	closed  bool
+	changed chan struct{} // 队列变化时关闭并替换，用于唤醒所有等待者
+}
+
+// NewGuardedQueue 创建保护性暂停队列
+func NewGuardedQueue[T any]() *GuardedQueue[T] {
+	return &GuardedQueue[T]{changed: make(chan struct{})}
+}
+
+// Put 放入元素并唤醒等待者，队列关闭后返回 ErrQueueClosed
+func (q *GuardedQueue[T]) Put(item T) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.closed {
+		return ErrQueueClosed
+	}
+	q.items = append(q.items, item)
+	q.broadcastLocked()
+	return nil
+}
+
+// Get 取出队首元素，队列为空时挂起直到有元素、队列关闭或上下文结束
+func (q *GuardedQueue[T]) Get(ctx context.Context) (T, error) {
+	return q.GetMatching(ctx, func(T) bool { return true })
+}
+
+// GetMatching 取出第一个满足 match 的元素，没有时挂起直到有元素满足条件
+// 队列关闭后仍然可以取出剩余的元素，没有满足条件的元素时返回 ErrQueueClosed
+func (q *GuardedQueue[T]) GetMatching(ctx context.Context, match func(T) bool) (T, error) {
+	var zero T
+	for {
+		q.mutex.Lock()
+		// 守护条件：检查和取出在同一把锁内完成，避免检查之后条件又被其他调用者破坏
+		for i, item := range q.items {
+			if match(item) {
+				q.items = append(q.items[:i], q.items[i+1:]...)
+				q.mutex.Unlock()
+				return item, nil
+			}
+		}
+		if q.closed {
+			q.mutex.Unlock()
+			return zero, ErrQueueClosed
+		}
+		changed := q.changed
+		q.mutex.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// TryGet 不挂起：队列为空时立即返回 false
+func (q *GuardedQueue[T]) TryGet() (T, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	var zero T
+	if len(q.items) == 0 {
+		return zero, false
+	}
+	item := q.items[0]
+	q.items = q.items[1:]
+	return item, true
+}
+
+// Len 返回队列中的元素数
+func (q *GuardedQueue[T]) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return len(q.items)
+}
+
+// Close 关闭队列，唤醒所有等待者；已经在队列中的元素仍然可以取出
+func (q *GuardedQueue[T]) Close() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if !q.closed {
+		q.closed = true
+		q.broadcastLocked()
+	}
+}
+
+// broadcastLocked 唤醒所有等待者，调用者需持有锁
+func (q *GuardedQueue[T]) broadcastLocked() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
@@ -0,0 +1,259 @@
+package guarded
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 测试 Get 挂起直到有元素放入
+func TestGuardedQueueGetBlocks(t *testing.T) {
+	q := NewGuardedQueue[int]()
+	got := make(chan int)
+	go func() {
+		v, err := q.Get(context.Background())
+		if err != nil {
+			t.Errorf("期望没有错误，但得到: %v", err)
+		}
+		got <- v
+	}()
+
+	select {
+	case v := <-got:
+		t.Fatalf("队列为空时 Get 不应返回，但得到: %v", v)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	q.Put(7)
+	if v := <-got; v != 7 {
+		t.Errorf("期望7，但得到: %v", v)
+	}
+	if _, ok := q.TryGet(); ok {
+		t.Error("队列为空时 TryGet 应返回 false")
+	}
+}
+
+// 测试上下文取消和关闭队列会唤醒等待者
+func TestGuardedQueueCancelAndClose(t *testing.T) {
+	q := NewGuardedQueue[string]()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := q.Get(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("期望超时，但得到: %v", err)
+	}
+
+	q.Put("剩余")
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := q.GetMatching(context.Background(), func(s string) bool { return s == "不存在" })
+			errs <- err
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	q.Close()
+	for i := 0; i < 2; i++ {
+		if err := <-errs; !errors.Is(err, ErrQueueClosed) {
+			t.Errorf("关闭后等待者应得到 ErrQueueClosed，但得到: %v", err)
+		}
+	}
+
+	if err := q.Put("新元素"); !errors.Is(err, ErrQueueClosed) {
+		t.Errorf("关闭后 Put 应失败，但得到: %v", err)
+	}
+	if v, err := q.Get(context.Background()); err != nil || v != "剩余" {
+		t.Errorf("关闭后仍可取出剩余元素，但得到: %v, %v", v, err)
+	}
+	if _, err := q.Get(context.Background()); !errors.Is(err, ErrQueueClosed) {
+		t.Errorf("取完之后应返回 ErrQueueClosed，但得到: %v", err)
+	}
+}
+
+// 测试按条件取出元素，不满足条件的元素保留给其他消费者
+func TestGuardedQueueGetMatching(t *testing.T) {
+	q := NewGuardedQueue[int]()
+	even := make(chan int)
+	go func() {
+		v, _ := q.GetMatching(context.Background(), func(v int) bool { return v%2 == 0 })
+		even <- v
+	}()
+
+	q.Put(1)
+	q.Put(3)
+	q.Put(4)
+	if v := <-even; v != 4 {
+		t.Errorf("期望取出偶数4，但得到: %v", v)
+	}
+	if q.Len() != 2 {
+		t.Errorf("奇数应保留在队列中，但长度为: %v", q.Len())
+	}
+}
+
+// 测试多个生产者和消费者并发时元素不丢失、不重复
+func TestGuardedQueueConcurrent(t *testing.T) {
+	q := NewGuardedQueue[int]()
+	const producers, perProducer = 4, 100
+
+	var mutex sync.Mutex
+	var received []int
+	var consumers sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		consumers.Add(1)
+		go func() {
+			defer consumers.Done()
+			for {
+				v, err := q.Get(context.Background())
+				if err != nil {
+					return
+				}
+				mutex.Lock()
+				received = append(received, v)
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				q.Put(p*perProducer + i)
+			}
+		}(p)
+	}
+	wg.Wait()
+	q.Close()
+	consumers.Wait()
+
+	sort.Ints(received)
+	if len(received) != producers*perProducer {
+		t.Fatalf("期望收到 %d 个元素，但收到: %d", producers*perProducer, len(received))
+	}
+	for i, v := range received {
+		if v != i {
+			t.Fatalf("第 %d 个元素期望为 %d，但得到: %d", i, i, v)
+		}
+	}
+}
+
+// 测试犹豫包装器在前置条件不满足时立即返回
+func TestBalking(t *testing.T) {
+	var ready atomic.Bool
+	b := NewBalking("打印机", ready.Load)
+
+	called := false
+	err := b.Do(func() error { called = true; return nil })
+	if !errors.Is(err, ErrNotReady) || called {
+		t.Errorf("未就绪时应放弃操作，但得到: %v, 调用: %v", err, called)
+	}
+	if _, err := Call(b, func() (int, error) { return 1, nil }); !errors.Is(err, ErrNotReady) {
+		t.Errorf("未就绪时 Call 应返回 ErrNotReady，但得到: %v", err)
+	}
+
+	ready.Store(true)
+	if v, err := Call(b, func() (int, error) { return 1, nil }); err != nil || v != 1 {
+		t.Errorf("就绪后应执行操作，但得到: %v, %v", v, err)
+	}
+	if !b.Ready() {
+		t.Error("Ready 应返回 true")
+	}
+}
+
+// 测试需要预热的服务：未就绪时拒绝，等待者在预热后继续，预热只执行一次
+func TestCatalogServiceWarmup(t *testing.T) {
+	var loads atomic.Int32
+	release := make(chan struct{})
+	service := NewCatalogService(func() (map[string]string, error) {
+		loads.Add(1)
+		<-release
+		return map[string]string{"sku-1": "键盘"}, nil
+	})
+
+	if _, err := service.Lookup("sku-1"); !errors.Is(err, ErrNotReady) {
+		t.Errorf("未预热时应返回 ErrNotReady，但得到: %v", err)
+	}
+
+	waiters := make(chan string, 10)
+	for i := 0; i < 10; i++ {
+		go func() {
+			v, err := service.LookupWait(context.Background(), "sku-1")
+			if err != nil {
+				t.Errorf("等待者期望成功，但得到: %v", err)
+			}
+			waiters <- v
+		}()
+	}
+
+	done := make(chan bool)
+	go func() {
+		started, err := service.Warmup()
+		if err != nil {
+			t.Errorf("预热失败: %v", err)
+		}
+		done <- started
+	}()
+	for service.State() != StateWarming {
+		time.Sleep(time.Millisecond)
+	}
+	if started, _ := service.Warmup(); started {
+		t.Error("预热中再次预热应直接返回")
+	}
+	if _, err := service.Lookup("sku-1"); !errors.Is(err, ErrNotReady) {
+		t.Errorf("预热中应返回 ErrNotReady，但得到: %v", err)
+	}
+
+	close(release)
+	if !<-done {
+		t.Error("第一次预热应返回 true")
+	}
+	for i := 0; i < 10; i++ {
+		if v := <-waiters; v != "键盘" {
+			t.Errorf("期望键盘，但得到: %v", v)
+		}
+	}
+	if _, err := service.Lookup("sku-2"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("期望 ErrKeyNotFound，但得到: %v", err)
+	}
+	if loads.Load() != 1 {
+		t.Errorf("期望加载1次，但加载了 %d 次", loads.Load())
+	}
+}
+
+// 测试预热失败后可以重试，等待者可以超时放弃
+func TestCatalogServiceWarmupFailure(t *testing.T) {
+	errLoad := errors.New("数据库不可用")
+	var attempts atomic.Int32
+	service := NewCatalogService(func() (map[string]string, error) {
+		if attempts.Add(1) == 1 {
+			return nil, errLoad
+		}
+		return map[string]string{"k": "v"}, nil
+	})
+
+	if _, err := service.Warmup(); !errors.Is(err, errLoad) {
+		t.Errorf("期望预热失败，但得到: %v", err)
+	}
+	if service.State() != StateCold {
+		t.Errorf("失败后应回到 %v，但得到: %v", StateCold, service.State())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := service.LookupWait(ctx, "k"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("期望等待超时，但得到: %v", err)
+	}
+
+	if started, err := service.Warmup(); !started || err != nil {
+		t.Errorf("重试预热应成功，但得到: %v, %v", started, err)
+	}
+	if v, err := service.Lookup("k"); err != nil || v != "v" {
+		t.Errorf("期望 v，但得到: %v, %v", v, err)
+	}
+}
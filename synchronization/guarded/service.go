@@ -0,0 +1,155 @@
+package guarded
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrKeyNotFound 查询的键不存在
+var ErrKeyNotFound = errors.New("键不存在")
+
+// ServiceState 需要预热的服务的状态
+type ServiceState int
+
+const (
+	StateCold    ServiceState = iota // 尚未预热
+	StateWarming                     // 正在预热
+	StateReady                       // 预热完成，可以处理请求
+)
+
+// String 返回状态名称
+func (s ServiceState) String() string {
+	switch s {
+	case StateCold:
+		return "未预热"
+	case StateWarming:
+		return "预热中"
+	case StateReady:
+		return "就绪"
+	default:
+		return "未知状态"
+	}
+}
+
+// CatalogService 需要预热的目录服务：启动后必须先把数据加载到缓存中才能处理查询
+// Lookup 使用犹豫模式，未就绪时立即拒绝；LookupWait 使用保护性暂停，挂起直到就绪
+type CatalogService struct {
+	mutex sync.Mutex
+	state ServiceState
+	cache map[string]string
+	ready chan struct{} // 预热完成时关闭
+	load  func() (map[string]string, error)
+
+	balking *Balking
+}
+
+// NewCatalogService 创建目录服务，load 在预热时加载数据
+func NewCatalogService(load func() (map[string]string, error)) *CatalogService {
+	s := &CatalogService{load: load, ready: make(chan struct{})}
+	s.balking = NewBalking("目录服务", func() bool { return s.State() == StateReady })
+	return s
+}
+
+// State 返回服务状态
+func (s *CatalogService) State() ServiceState {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.state
+}
+
+// Warmup 预热服务，返回是否由本次调用完成了预热
+// 已经在预热或已经就绪时直接返回 false（犹豫），不会重复加载；加载失败时服务回到未预热状态，可以再次预热
+func (s *CatalogService) Warmup() (bool, error) {
+	s.mutex.Lock()
+	if s.state != StateCold {
+		s.mutex.Unlock()
+		return false, nil
+	}
+	s.state = StateWarming
+	s.mutex.Unlock()
+
+	data, err := s.load()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err != nil {
+		s.state = StateCold
+		return false, fmt.Errorf("预热失败: %w", err)
+	}
+	s.cache = data
+	s.state = StateReady
+	close(s.ready)
+	return true, nil
+}
+
+// Lookup 查询，服务未就绪时立即返回 ErrNotReady
+func (s *CatalogService) Lookup(key string) (string, error) {
+	return Call(s.balking, func() (string, error) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		value, ok := s.cache[key]
+		if !ok {
+			return "", fmt.Errorf("%w: %s", ErrKeyNotFound, key)
+		}
+		return value, nil
+	})
+}
+
+// LookupWait 查询，服务未就绪时挂起直到预热完成或上下文结束
+func (s *CatalogService) LookupWait(ctx context.Context, key string) (string, error) {
+	select {
+	case <-s.ready:
+		return s.Lookup(key)
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// RunExample 运行保护性暂停与犹豫模式示例
+func RunExample() {
+	service := NewCatalogService(func() (map[string]string, error) {
+		time.Sleep(50 * time.Millisecond) // 模拟加载数据
+		return map[string]string{"sku-1": "机械键盘", "sku-2": "显示器"}, nil
+	})
+
+	// 犹豫：未预热时立即拒绝
+	if _, err := service.Lookup("sku-1"); err != nil {
+		fmt.Printf("立即查询: %v\n", err)
+	}
+
+	go service.Warmup()
+
+	// 保护性暂停：等待预热完成
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	value, err := service.LookupWait(ctx, "sku-1")
+	fmt.Printf("等待后查询: %s, 错误: %v\n", value, err)
+
+	// 预热只执行一次，重复调用直接返回
+	started, _ := service.Warmup()
+	fmt.Printf("再次预热是否执行: %v\n", started)
+
+	// 保护性暂停队列：消费者挂起直到有订单
+	orders := NewGuardedQueue[string]()
+	go func() {
+		for _, order := range []string{"订单-1", "订单-2"} {
+			time.Sleep(10 * time.Millisecond)
+			orders.Put(order)
+		}
+		orders.Close()
+	}()
+	for {
+		order, err := orders.Get(ctx)
+		if err != nil {
+			fmt.Printf("队列结束: %v\n", err)
+			break
+		}
+		fmt.Printf("处理 %s\n", order)
+	}
+}
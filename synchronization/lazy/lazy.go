@@ -0,0 +1,149 @@
+package lazy
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Option 配置延迟初始化的选项
+type Option func(*config)
+
+// config 延迟初始化的配置
+type config struct {
+	retry bool // 初始化失败后是否允许重试
+}
+
+// WithRetry 初始化失败时不缓存错误，下一次访问重新初始化
+// 默认情况下错误与值一样只计算一次，之后的访问都返回同一个错误
+func WithRetry() Option {
+	return func(c *config) {
+		c.retry = true
+	}
+}
+
+// newConfig 应用选项
+func newConfig(opts []Option) config {
+	var c config
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// Lazy 线程安全的延迟初始化值，第一次访问时才调用初始化函数
+// 并发的第一次访问只会调用一次初始化函数，其余调用者等待并共享结果
+type Lazy[T any] struct {
+	init  func() (T, error)
+	retry bool
+
+	once  sync.Once   // 不重试时保证初始化函数只调用一次
+	mutex sync.Mutex  // 重试时串行化初始化
+	done  atomic.Bool // 结果是否已经缓存
+	value T
+	err   error
+}
+
+// NewLazy 创建延迟初始化值
+func NewLazy[T any](init func() (T, error), opts ...Option) *Lazy[T] {
+	c := newConfig(opts)
+	return &Lazy[T]{init: init, retry: c.retry}
+}
+
+// Get 返回值，第一次调用时执行初始化
+// 初始化函数 panic 时，不重试的 Lazy 会把零值当作结果缓存，重试的 Lazy 则在下一次访问时重新初始化
+func (l *Lazy[T]) Get() (T, error) {
+	if !l.retry {
+		l.once.Do(func() {
+			defer l.done.Store(true)
+			l.value, l.err = l.init()
+		})
+		return l.value, l.err
+	}
+
+	if l.done.Load() {
+		return l.value, nil
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// 等待锁期间其他调用者可能已经初始化成功
+	if l.done.Load() {
+		return l.value, nil
+	}
+	value, err := l.init()
+	if err != nil {
+		return value, err
+	}
+	l.value = value
+	l.done.Store(true)
+	return value, nil
+}
+
+// MustGet 返回值，初始化失败时 panic
+func (l *Lazy[T]) MustGet() T {
+	value, err := l.Get()
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// Initialized 结果是否已经缓存，不会触发初始化
+func (l *Lazy[T]) Initialized() bool {
+	return l.done.Load()
+}
+
+// lazyResult 一次初始化的结果
+type lazyResult[T any] struct {
+	value T
+	err   error
+}
+
+// ResettableLazy 可以重置的延迟初始化值，重置后的下一次访问重新初始化，
+// 适合缓存需要定期刷新的配置、连接等
+type ResettableLazy[T any] struct {
+	init  func() (T, error)
+	retry bool
+
+	mutex  sync.Mutex                    // 串行化初始化与重置
+	result atomic.Pointer[lazyResult[T]] // 当前缓存的结果，nil 表示尚未初始化
+}
+
+// NewResettableLazy 创建可以重置的延迟初始化值
+func NewResettableLazy[T any](init func() (T, error), opts ...Option) *ResettableLazy[T] {
+	c := newConfig(opts)
+	return &ResettableLazy[T]{init: init, retry: c.retry}
+}
+
+// Get 返回值，尚未初始化或已经重置时执行初始化
+func (l *ResettableLazy[T]) Get() (T, error) {
+	if r := l.result.Load(); r != nil {
+		return r.value, r.err
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if r := l.result.Load(); r != nil {
+		return r.value, r.err
+	}
+	value, err := l.init()
+	if err == nil || !l.retry {
+		l.result.Store(&lazyResult[T]{value: value, err: err})
+	}
+	return value, err
+}
+
+// Reset 丢弃缓存的结果，正在进行的初始化完成后才会重置
+func (l *ResettableLazy[T]) Reset() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.result.Store(nil)
+}
+
+// Initialized 结果是否已经缓存，不会触发初始化
+func (l *ResettableLazy[T]) Initialized() bool {
+	return l.result.Load() != nil
+}
@@ -0,0 +1,73 @@
+package lazy
+
+import "sync"
+
+// LazyMap 按键延迟初始化的映射，每个键第一次访问时调用初始化函数
+// 同一个键的并发访问只初始化一次（single-flight），不同键的初始化互不阻塞
+type LazyMap[K comparable, V any] struct {
+	init func(K) (V, error)
+	opts []Option
+
+	mutex   sync.Mutex
+	entries map[K]*Lazy[V]
+}
+
+// NewLazyMap 创建按键延迟初始化的映射，选项作用于每个键
+func NewLazyMap[K comparable, V any](init func(K) (V, error), opts ...Option) *LazyMap[K, V] {
+	return &LazyMap[K, V]{
+		init:    init,
+		opts:    opts,
+		entries: make(map[K]*Lazy[V]),
+	}
+}
+
+// Get 返回键对应的值，第一次访问该键时执行初始化
+// 只在查找条目时持有映射的锁，初始化在锁外进行
+func (m *LazyMap[K, V]) Get(key K) (V, error) {
+	return m.entry(key).Get()
+}
+
+// entry 返回键对应的延迟初始化条目，不存在时创建
+func (m *LazyMap[K, V]) entry(key K) *Lazy[V] {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	e, ok := m.entries[key]
+	if !ok {
+		e = NewLazy(func() (V, error) { return m.init(key) }, m.opts...)
+		m.entries[key] = e
+	}
+	return e
+}
+
+// Loaded 返回已经初始化的值，不会触发初始化
+// 键尚未初始化、正在初始化或初始化失败时返回 false
+func (m *LazyMap[K, V]) Loaded(key K) (V, bool) {
+	m.mutex.Lock()
+	e, ok := m.entries[key]
+	m.mutex.Unlock()
+
+	if !ok || !e.Initialized() {
+		var zero V
+		return zero, false
+	}
+	value, err := e.Get()
+	return value, err == nil
+}
+
+// Delete 删除键，下一次访问重新初始化
+// 正在等待该键初始化的调用者仍然会得到旧条目的结果
+func (m *LazyMap[K, V]) Delete(key K) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.entries, key)
+}
+
+// Len 返回已经访问过的键的数量，包括正在初始化的键
+func (m *LazyMap[K, V]) Len() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return len(m.entries)
+}
@@ -0,0 +1,197 @@
+package lazy
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+var errUnavailable = errors.New("服务不可用")
+
+// hammer 让 n 个协程同时开始执行 fn，并等待全部完成
+func hammer(n int, fn func(i int)) {
+	var ready, done sync.WaitGroup
+	start := make(chan struct{})
+	ready.Add(n)
+	done.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer done.Done()
+			ready.Done()
+			<-start
+			fn(i)
+		}(i)
+	}
+	ready.Wait()
+	close(start)
+	done.Wait()
+}
+
+// 测试并发的第一次访问只初始化一次，所有调用者得到同一个值
+func TestLazyConcurrentFirstAccess(t *testing.T) {
+	var calls atomic.Int32
+	l := NewLazy(func() (*int, error) {
+		calls.Add(1)
+		v := 42
+		return &v, nil
+	})
+	if l.Initialized() {
+		t.Fatal("访问之前不应初始化")
+	}
+
+	results := make([]*int, 100)
+	hammer(100, func(i int) {
+		v, err := l.Get()
+		if err != nil {
+			t.Errorf("期望没有错误，但得到: %v", err)
+		}
+		results[i] = v
+	})
+
+	if calls.Load() != 1 {
+		t.Errorf("期望初始化1次，但初始化了 %d 次", calls.Load())
+	}
+	for i, v := range results {
+		if v != results[0] || *v != 42 {
+			t.Fatalf("第 %d 个调用者得到了不同的值: %v", i, v)
+		}
+	}
+	if !l.Initialized() || l.MustGet() != results[0] {
+		t.Error("初始化之后应返回缓存的值")
+	}
+}
+
+// 测试默认缓存错误，WithRetry 时失败后重新初始化
+func TestLazyError(t *testing.T) {
+	var calls atomic.Int32
+	init := func() (string, error) {
+		if calls.Add(1) <= 2 {
+			return "", errUnavailable
+		}
+		return "已连接", nil
+	}
+
+	cached := NewLazy(init)
+	for i := 0; i < 3; i++ {
+		if _, err := cached.Get(); !errors.Is(err, errUnavailable) {
+			t.Errorf("期望缓存的错误，但得到: %v", err)
+		}
+	}
+	if calls.Load() != 1 || !cached.Initialized() {
+		t.Errorf("错误应只计算一次，但初始化了 %d 次", calls.Load())
+	}
+
+	calls.Store(0)
+	retry := NewLazy(init, WithRetry())
+	for i := 0; i < 2; i++ {
+		if _, err := retry.Get(); !errors.Is(err, errUnavailable) {
+			t.Errorf("第 %d 次期望失败，但得到: %v", i+1, err)
+		}
+		if retry.Initialized() {
+			t.Error("失败后不应缓存结果")
+		}
+	}
+	hammer(50, func(int) {
+		if v, err := retry.Get(); err != nil || v != "已连接" {
+			t.Errorf("期望重试成功，但得到: %v, %v", v, err)
+		}
+	})
+	if calls.Load() != 3 {
+		t.Errorf("成功之后不应再初始化，但初始化了 %d 次", calls.Load())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("MustGet 在初始化失败时应 panic")
+		}
+	}()
+	cached.MustGet()
+}
+
+// 测试重置后重新初始化，并发访问期间重置不会产生竞争
+func TestResettableLazy(t *testing.T) {
+	var version atomic.Int32
+	l := NewResettableLazy(func() (int32, error) {
+		return version.Add(1), nil
+	})
+
+	hammer(50, func(int) { l.Get() })
+	if v, _ := l.Get(); v != 1 {
+		t.Errorf("期望版本1，但得到: %v", v)
+	}
+
+	l.Reset()
+	if l.Initialized() {
+		t.Error("重置后不应有缓存的结果")
+	}
+	if v, _ := l.Get(); v != 2 {
+		t.Errorf("重置后期望版本2，但得到: %v", v)
+	}
+
+	hammer(50, func(i int) {
+		if i%10 == 0 {
+			l.Reset()
+		}
+		if v, err := l.Get(); err != nil || v < 2 {
+			t.Errorf("得到了过期的值: %v, %v", v, err)
+		}
+	})
+
+	failing := NewResettableLazy(func() (int, error) { return 0, errUnavailable }, WithRetry())
+	failing.Get()
+	if failing.Initialized() {
+		t.Error("WithRetry 时失败的结果不应缓存")
+	}
+}
+
+// 测试每个键只初始化一次，不同键互不影响
+func TestLazyMap(t *testing.T) {
+	var mutex sync.Mutex
+	calls := make(map[string]int)
+	m := NewLazyMap(func(key string) (string, error) {
+		mutex.Lock()
+		calls[key]++
+		mutex.Unlock()
+		if key == "bad" {
+			return "", errUnavailable
+		}
+		return "配置:" + key, nil
+	})
+
+	hammer(100, func(i int) {
+		key := fmt.Sprintf("k%d", i%5)
+		if v, err := m.Get(key); err != nil || v != "配置:"+key {
+			t.Errorf("键 %s 期望 配置:%s，但得到: %v, %v", key, key, v, err)
+		}
+	})
+	for key, n := range calls {
+		if n != 1 {
+			t.Errorf("键 %s 期望初始化1次，但初始化了 %d 次", key, n)
+		}
+	}
+	if m.Len() != 5 {
+		t.Errorf("期望5个键，但得到: %v", m.Len())
+	}
+
+	if v, ok := m.Loaded("k1"); !ok || v != "配置:k1" {
+		t.Errorf("Loaded 期望返回已初始化的值，但得到: %v, %v", v, ok)
+	}
+	if _, ok := m.Loaded("missing"); ok || m.Len() != 5 {
+		t.Error("Loaded 不应触发初始化")
+	}
+
+	if _, err := m.Get("bad"); !errors.Is(err, errUnavailable) {
+		t.Errorf("期望错误，但得到: %v", err)
+	}
+	if _, ok := m.Loaded("bad"); ok {
+		t.Error("初始化失败的键不应视为已加载")
+	}
+
+	m.Delete("k1")
+	m.Get("k1")
+	if calls["k1"] != 2 {
+		t.Errorf("删除后应重新初始化，但初始化了 %d 次", calls["k1"])
+	}
+}
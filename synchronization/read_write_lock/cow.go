@@ -0,0 +1,147 @@
+package read_write_lock
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// cowSnapshot 写时复制数据的一个不可变快照
+type cowSnapshot struct {
+	value   int
+	version int64
+}
+
+// COWData 使用写时复制保护的数据，提供与 Data 平行的读写接口
+// 读者原子地加载当前快照，不加锁也不重试；写者在互斥锁保护下创建新快照并原子替换，
+// 已被读者拿到的旧快照不会被修改，因此读到的值和版本总是一致的
+type COWData struct {
+	mu      sync.Mutex // 串行化写者，避免并发的读-改-写丢失更新
+	current atomic.Pointer[cowSnapshot]
+}
+
+// NewCOWData 创建一个新的写时复制数据实例
+func NewCOWData() *COWData {
+	d := &COWData{}
+	d.current.Store(&cowSnapshot{})
+	return d
+}
+
+// Read 读取数据值，从不阻塞
+func (d *COWData) Read() int {
+	return d.current.Load().value
+}
+
+// ReadVersioned 一致地读取数据值和对应的写入次数
+func (d *COWData) ReadVersioned() (int, int64) {
+	snapshot := d.current.Load()
+	return snapshot.value, snapshot.version
+}
+
+// Write 写入数据值
+func (d *COWData) Write(val int) bool {
+	d.Update(func(int) int { return val })
+	return true
+}
+
+// ReadWithCallback 以当前快照的值执行自定义读操作，回调期间的写入不影响回调看到的值
+func (d *COWData) ReadWithCallback(callback func(val int)) {
+	callback(d.Read())
+}
+
+// Update 在写者互斥锁保护下根据旧值计算新值并替换快照，是原子的读-改-写操作
+func (d *COWData) Update(fn func(val int) int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	old := d.current.Load()
+	d.current.Store(&cowSnapshot{value: fn(old.value), version: old.version + 1})
+}
+
+// ReadWriteWithCallback 根据读到的值计算新值并写入
+// 与 Data.ReadWriteWithCallback 不同，读和写之间不会插入其他写者
+func (d *COWData) ReadWriteWithCallback(readCallback func(val int) int) {
+	d.Update(readCallback)
+}
+
+// COWSlice 使用写时复制保护的切片，适合读远多于写的场景，如配置列表、路由表、订阅者列表
+// 读者拿到的切片是不可变快照，不能修改；每次写入都会复制整个切片，因此写入开销与长度成正比
+type COWSlice[T any] struct {
+	mu      sync.Mutex // 串行化写者
+	current atomic.Pointer[[]T]
+}
+
+// NewCOWSlice 创建写时复制切片，初始元素会被复制
+func NewCOWSlice[T any](items ...T) *COWSlice[T] {
+	s := &COWSlice[T]{}
+	snapshot := slices.Clone(items)
+	s.current.Store(&snapshot)
+	return s
+}
+
+// Snapshot 返回当前快照，调用方不能修改返回的切片
+func (s *COWSlice[T]) Snapshot() []T {
+	return *s.current.Load()
+}
+
+// Len 返回当前快照的长度
+func (s *COWSlice[T]) Len() int {
+	return len(s.Snapshot())
+}
+
+// Get 返回当前快照中下标为 i 的元素，越界时第二个返回值为 false
+func (s *COWSlice[T]) Get(i int) (T, bool) {
+	snapshot := s.Snapshot()
+	if i < 0 || i >= len(snapshot) {
+		var zero T
+		return zero, false
+	}
+	return snapshot[i], true
+}
+
+// ReadWithCallback 以当前快照执行自定义读操作，回调不能修改切片
+func (s *COWSlice[T]) ReadWithCallback(callback func(items []T)) {
+	callback(s.Snapshot())
+}
+
+// WriteWithCallback 把当前快照的副本交给回调修改，并用回调的返回值替换快照
+// 回调可以原地修改副本，也可以追加或删除元素后返回新的切片
+func (s *COWSlice[T]) WriteWithCallback(callback func(items []T) []T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := callback(slices.Clone(*s.current.Load()))
+	s.current.Store(&next)
+}
+
+// Append 在末尾追加元素
+func (s *COWSlice[T]) Append(items ...T) {
+	s.WriteWithCallback(func(current []T) []T {
+		return append(current, items...)
+	})
+}
+
+// Set 替换下标为 i 的元素，越界时返回 false
+func (s *COWSlice[T]) Set(i int, item T) bool {
+	ok := false
+	s.WriteWithCallback(func(current []T) []T {
+		if i >= 0 && i < len(current) {
+			current[i] = item
+			ok = true
+		}
+		return current
+	})
+	return ok
+}
+
+// DeleteFunc 删除所有满足条件的元素，返回删除的个数
+func (s *COWSlice[T]) DeleteFunc(del func(item T) bool) int {
+	deleted := 0
+	s.WriteWithCallback(func(current []T) []T {
+		before := len(current)
+		current = slices.DeleteFunc(current, del)
+		deleted = before - len(current)
+		return current
+	})
+	return deleted
+}
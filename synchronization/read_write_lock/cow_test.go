@@ -0,0 +1,230 @@
+package read_write_lock
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// 测试写时复制数据的基本读写
+func TestCOWDataBasic(t *testing.T) {
+	data := NewCOWData()
+	if v := data.Read(); v != 0 {
+		t.Errorf("期望初始值为0，但得到: %v", v)
+	}
+
+	data.Write(42)
+	data.ReadWithCallback(func(val int) {
+		if val != 42 {
+			t.Errorf("期望值为42，但得到: %v", val)
+		}
+	})
+
+	data.ReadWriteWithCallback(func(val int) int { return val * 2 })
+	if value, version := data.ReadVersioned(); value != 84 || version != 2 {
+		t.Errorf("期望值为84、版本为2，但得到: %v, %v", value, version)
+	}
+}
+
+// 测试并发的读-改-写不丢失更新，读者总是读到一致的值和版本
+func TestCOWDataConcurrentUpdate(t *testing.T) {
+	data := NewCOWData()
+	var stop atomic.Bool
+	var inconsistent atomic.Int64
+
+	var readers sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for !stop.Load() {
+				// 每次更新都加一，因此值和版本应始终相等
+				if value, version := data.ReadVersioned(); int64(value) != version {
+					inconsistent.Add(1)
+				}
+			}
+		}()
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				data.Update(func(val int) int { return val + 1 })
+			}
+		}()
+	}
+	wg.Wait()
+	stop.Store(true)
+	readers.Wait()
+
+	if value, version := data.ReadVersioned(); value != 1000 || version != 1000 {
+		t.Errorf("期望值和版本都为1000，但得到: %v, %v", value, version)
+	}
+	if inconsistent.Load() != 0 {
+		t.Errorf("读者读到了 %d 次不一致的数据", inconsistent.Load())
+	}
+}
+
+// 测试写时复制切片的读写和快照隔离
+func TestCOWSlice(t *testing.T) {
+	source := []string{"a", "b"}
+	list := NewCOWSlice(source...)
+	source[0] = "x"
+	if got := list.Snapshot(); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("初始元素应被复制，但得到: %v", got)
+	}
+
+	before := list.Snapshot()
+	list.Append("c", "d")
+	if !list.Set(0, "A") {
+		t.Error("Set 下标0应成功")
+	}
+	if list.Set(10, "z") {
+		t.Error("Set 越界应返回 false")
+	}
+	if n := list.DeleteFunc(func(s string) bool { return s == "b" || s == "d" }); n != 2 {
+		t.Errorf("期望删除2个元素，但删除了: %v", n)
+	}
+
+	if !slices.Equal(before, []string{"a", "b"}) {
+		t.Errorf("已经拿到的快照不应被写入修改，但得到: %v", before)
+	}
+	if got := list.Snapshot(); !slices.Equal(got, []string{"A", "c"}) {
+		t.Errorf("期望 [A c]，但得到: %v", got)
+	}
+	if v, ok := list.Get(1); !ok || v != "c" || list.Len() != 2 {
+		t.Errorf("Get(1) 期望 c，但得到: %v, %v", v, ok)
+	}
+	if _, ok := list.Get(2); ok {
+		t.Error("Get 越界应返回 false")
+	}
+
+	list.ReadWithCallback(func(items []string) {
+		list.Append("e") // 回调期间的写入不影响回调看到的快照
+		if len(items) != 2 {
+			t.Errorf("回调中的快照长度应为2，但得到: %v", len(items))
+		}
+	})
+	if list.Len() != 3 {
+		t.Errorf("期望长度为3，但得到: %v", list.Len())
+	}
+}
+
+// 测试并发追加不丢失元素
+func TestCOWSliceConcurrentAppend(t *testing.T) {
+	list := NewCOWSlice[int]()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				list.Append(i*50 + j)
+				list.Snapshot()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	got := slices.Clone(list.Snapshot())
+	slices.Sort(got)
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("下标 %d 期望为 %d，但得到: %v", i, i, v)
+		}
+	}
+	if len(got) != 500 {
+		t.Errorf("期望500个元素，但得到: %v", len(got))
+	}
+}
+
+// rwSlice 用读写锁保护的切片，作为写时复制切片的对照
+type rwSlice struct {
+	locker RWLocker
+	items  []int
+}
+
+func (s *rwSlice) sum() int {
+	s.locker.ReadLock()
+	defer s.locker.ReadUnlock()
+
+	total := 0
+	for _, v := range s.items {
+		total += v
+	}
+	return total
+}
+
+func (s *rwSlice) set(i, v int) {
+	s.locker.WriteLock()
+	defer s.locker.WriteUnlock()
+
+	s.items[i%len(s.items)] = v
+}
+
+// benchmarkRatio 按读写比例并发执行，每 writeEvery 次操作中有1次写入，0 表示只读
+func benchmarkRatio(b *testing.B, writeEvery int, read func(), write func(int)) {
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if writeEvery > 0 && i%writeEvery == 0 {
+				write(i)
+			} else {
+				read()
+			}
+			i++
+		}
+	})
+}
+
+// 读写比例：只读、每100次写1次、每10次写1次、每2次写1次
+var benchmarkRatios = []int{0, 100, 10, 2}
+
+func ratioName(writeEvery int) string {
+	if writeEvery == 0 {
+		return "reads=100%"
+	}
+	return fmt.Sprintf("reads=%d%%", 100-100/writeEvery)
+}
+
+// 对比单个整数的写时复制与读写锁
+func BenchmarkCOWDataVsRWMutex(b *testing.B) {
+	for _, writeEvery := range benchmarkRatios {
+		b.Run("COW/"+ratioName(writeEvery), func(b *testing.B) {
+			data := NewCOWData()
+			benchmarkRatio(b, writeEvery, func() { data.Read() }, func(v int) { data.Write(v) })
+		})
+		b.Run("RWMutex/"+ratioName(writeEvery), func(b *testing.B) {
+			data := NewData()
+			benchmarkRatio(b, writeEvery, func() { data.Read() }, func(v int) { data.Write(v) })
+		})
+	}
+}
+
+// 对比64个元素的切片：读者遍历求和，写者修改一个元素
+func BenchmarkCOWSliceVsRWMutex(b *testing.B) {
+	const size = 64
+	for _, writeEvery := range benchmarkRatios {
+		b.Run("COW/"+ratioName(writeEvery), func(b *testing.B) {
+			list := NewCOWSlice(make([]int, size)...)
+			read := func() {
+				total := 0
+				for _, v := range list.Snapshot() {
+					total += v
+				}
+				_ = total
+			}
+			benchmarkRatio(b, writeEvery, read, func(v int) { list.Set(v%size, v) })
+		})
+		b.Run("RWMutex/"+ratioName(writeEvery), func(b *testing.B) {
+			list := &rwSlice{locker: NewStandardRWLock(), items: make([]int, size)}
+			benchmarkRatio(b, writeEvery, func() { list.sum() }, func(v int) { list.set(v, v) })
+		})
+	}
+}
@@ -0,0 +1,357 @@
+package read_write_lock
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// IssueKind 调试读写锁检测到的问题类型
+type IssueKind int
+
+const (
+	// IssueReadAfterWrite 持有写锁的协程再次获取读锁，必然死锁
+	IssueReadAfterWrite IssueKind = iota
+	// IssueRecursiveWrite 持有写锁的协程再次获取写锁，必然死锁
+	IssueRecursiveWrite
+	// IssueLockUpgrade 持有读锁的协程获取写锁（锁升级），必然死锁
+	IssueLockUpgrade
+	// IssueDoubleUnlock 释放了没有被持有的锁，底层的 sync.RWMutex 会直接终止程序
+	IssueDoubleUnlock
+	// IssueForeignUnlock 释放了其他协程持有的锁，Go 允许这样做，但通常意味着所有权混乱
+	IssueForeignUnlock
+	// IssueLongHeld 锁的持有时间超过了阈值，可能是死锁或临界区过长
+	IssueLongHeld
+)
+
+// String 返回问题类型的描述
+func (k IssueKind) String() string {
+	switch k {
+	case IssueReadAfterWrite:
+		return "持有写锁时获取读锁"
+	case IssueRecursiveWrite:
+		return "重复获取写锁"
+	case IssueLockUpgrade:
+		return "持有读锁时获取写锁"
+	case IssueDoubleUnlock:
+		return "重复释放锁"
+	case IssueForeignUnlock:
+		return "释放其他协程持有的锁"
+	case IssueLongHeld:
+		return "锁持有时间过长"
+	default:
+		return "未知问题"
+	}
+}
+
+// Issue 一次检测到的问题
+type Issue struct {
+	Kind         IssueKind     // 问题类型
+	Write        bool          // 涉及的是写锁还是读锁
+	Goroutine    int64         // 触发问题的协程编号；持有时间过长时为持有者
+	Owner        int64         // 锁当前的持有者，没有时为 0
+	Held         time.Duration // 锁已被持有的时间
+	Stack        []byte        // 触发问题时的调用栈；持有时间过长时为空
+	AcquireStack []byte        // 持有者获取锁时的调用栈
+}
+
+// String 返回问题的简要描述，不包含调用栈
+func (i Issue) String() string {
+	mode := "读锁"
+	if i.Write {
+		mode = "写锁"
+	}
+	if i.Kind == IssueLongHeld {
+		return fmt.Sprintf("%s: 协程 %d 持有%s已 %v", i.Kind, i.Goroutine, mode, i.Held)
+	}
+	return fmt.Sprintf("%s: 协程 %d 操作%s，当前持有者 %d", i.Kind, i.Goroutine, mode, i.Owner)
+}
+
+// Error 使 Issue 可以作为 panic 的值被 recover 后当作错误处理
+func (i Issue) Error() string {
+	return i.String()
+}
+
+// debugHold 一次锁的持有记录
+type debugHold struct {
+	since time.Time
+	stack []byte
+	timer *time.Timer
+}
+
+// DebugRWLock 用于诊断读写锁误用的包装器，实现了 RWLocker 接口
+//
+// 它按协程记录锁的所有权：在阻塞获取会导致自身死锁时（持有写锁再获取读锁或写锁、
+// 持有读锁再获取写锁）先报告问题再 panic，而不是让程序永远挂起；
+// 重复释放会被报告并忽略；持有时间超过阈值的锁在仍被持有时就会被报告。
+// 协程编号通过解析调用栈获得，开销较大，只适合在调试和教学中使用。
+type DebugRWLock struct {
+	locker    RWLocker
+	threshold time.Duration
+	onIssue   func(Issue)
+
+	mu      sync.Mutex
+	writer  int64                  // 持有写锁的协程，0 表示没有
+	write   *debugHold             // 写锁的持有记录
+	readers map[int64][]*debugHold // 各协程持有的读锁，同一协程可以多次持有
+}
+
+// DebugOption 配置 DebugRWLock 的选项
+type DebugOption func(*DebugRWLock)
+
+// WithLocker 指定被包装的读写锁，默认使用 StandardRWLock
+func WithLocker(locker RWLocker) DebugOption {
+	return func(l *DebugRWLock) {
+		l.locker = locker
+	}
+}
+
+// WithHoldThreshold 设置锁持有时间的报告阈值，0 表示不检测
+func WithHoldThreshold(threshold time.Duration) DebugOption {
+	return func(l *DebugRWLock) {
+		l.threshold = threshold
+	}
+}
+
+// WithIssueHandler 设置问题的回调，默认打印到标准输出
+// 回调在不持有内部锁的情况下调用，但可能在持有被诊断的锁时调用，回调中不要再操作该锁
+func WithIssueHandler(handler func(Issue)) DebugOption {
+	return func(l *DebugRWLock) {
+		l.onIssue = handler
+	}
+}
+
+// NewDebugRWLock 创建一个调试读写锁
+func NewDebugRWLock(opts ...DebugOption) *DebugRWLock {
+	l := &DebugRWLock{
+		readers: make(map[int64][]*debugHold),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.locker == nil {
+		l.locker = NewStandardRWLock()
+	}
+	if l.onIssue == nil {
+		l.onIssue = func(issue Issue) {
+			fmt.Printf("[DebugRWLock] %v\n%s\n", issue, issue.Stack)
+		}
+	}
+	return l
+}
+
+// ReadLock 获取读锁，持有写锁的协程调用时报告 IssueReadAfterWrite 并 panic
+func (l *DebugRWLock) ReadLock() {
+	gid, stack := currentGoroutine()
+	l.mu.Lock()
+	if l.writer == gid {
+		issue := l.issueUnsafe(IssueReadAfterWrite, false, gid, stack)
+		l.mu.Unlock()
+		l.onIssue(issue)
+		panic(issue)
+	}
+	l.mu.Unlock()
+
+	l.locker.ReadLock()
+	l.acquired(false, gid, stack)
+}
+
+// WriteLock 获取写锁，已持有读锁或写锁的协程调用时报告问题并 panic
+func (l *DebugRWLock) WriteLock() {
+	gid, stack := currentGoroutine()
+	l.mu.Lock()
+	if l.writer == gid || len(l.readers[gid]) > 0 {
+		kind := IssueLockUpgrade
+		if l.writer == gid {
+			kind = IssueRecursiveWrite
+		}
+		issue := l.issueUnsafe(kind, true, gid, stack)
+		l.mu.Unlock()
+		l.onIssue(issue)
+		panic(issue)
+	}
+	l.mu.Unlock()
+
+	l.locker.WriteLock()
+	l.acquired(true, gid, stack)
+}
+
+// TryReadLock 尝试获取读锁，不阻塞
+func (l *DebugRWLock) TryReadLock() bool {
+	return l.try(false, l.locker.TryReadLock)
+}
+
+// TryWriteLock 尝试获取写锁，不阻塞
+func (l *DebugRWLock) TryWriteLock() bool {
+	return l.try(true, l.locker.TryWriteLock)
+}
+
+// TryReadLockWithTimeout 尝试在指定时间内获取读锁
+func (l *DebugRWLock) TryReadLockWithTimeout(timeout time.Duration) bool {
+	return l.try(false, func() bool { return l.locker.TryReadLockWithTimeout(timeout) })
+}
+
+// TryWriteLockWithTimeout 尝试在指定时间内获取写锁
+func (l *DebugRWLock) TryWriteLockWithTimeout(timeout time.Duration) bool {
+	return l.try(true, func() bool { return l.locker.TryWriteLockWithTimeout(timeout) })
+}
+
+// ReadUnlock 释放读锁，没有任何协程持有读锁时报告 IssueDoubleUnlock 并忽略本次释放
+func (l *DebugRWLock) ReadUnlock() {
+	gid, stack := currentGoroutine()
+	l.mu.Lock()
+	owner := gid
+	if len(l.readers[gid]) == 0 {
+		owner = l.anyReaderUnsafe()
+		if owner == 0 {
+			issue := l.issueUnsafe(IssueDoubleUnlock, false, gid, stack)
+			l.mu.Unlock()
+			l.onIssue(issue)
+			return
+		}
+	}
+
+	holds := l.readers[owner]
+	hold := holds[len(holds)-1]
+	var issue *Issue
+	if owner != gid {
+		foreign := l.issueUnsafe(IssueForeignUnlock, false, gid, stack)
+		foreign.Owner, foreign.AcquireStack = owner, hold.stack
+		issue = &foreign
+	}
+	if len(holds) == 1 {
+		delete(l.readers, owner)
+	} else {
+		l.readers[owner] = holds[:len(holds)-1]
+	}
+	l.stopTimer(hold)
+	l.mu.Unlock()
+
+	l.locker.ReadUnlock()
+	if issue != nil {
+		l.onIssue(*issue)
+	}
+}
+
+// WriteUnlock 释放写锁，写锁没有被持有时报告 IssueDoubleUnlock 并忽略本次释放
+func (l *DebugRWLock) WriteUnlock() {
+	gid, stack := currentGoroutine()
+	l.mu.Lock()
+	if l.writer == 0 {
+		issue := l.issueUnsafe(IssueDoubleUnlock, true, gid, stack)
+		l.mu.Unlock()
+		l.onIssue(issue)
+		return
+	}
+
+	var issue *Issue
+	if l.writer != gid {
+		foreign := l.issueUnsafe(IssueForeignUnlock, true, gid, stack)
+		issue = &foreign
+	}
+	l.stopTimer(l.write)
+	l.writer, l.write = 0, nil
+	l.mu.Unlock()
+
+	l.locker.WriteUnlock()
+	if issue != nil {
+		l.onIssue(*issue)
+	}
+}
+
+// try 执行非阻塞的获取，成功时记录所有权
+// 非阻塞获取不会造成死锁，因此不做自身死锁检查
+func (l *DebugRWLock) try(write bool, acquire func() bool) bool {
+	gid, stack := currentGoroutine()
+	if !acquire() {
+		return false
+	}
+	l.acquired(write, gid, stack)
+	return true
+}
+
+// acquired 记录一次成功的获取，并在设置了阈值时启动持有时间检测
+func (l *DebugRWLock) acquired(write bool, gid int64, stack []byte) {
+	hold := &debugHold{since: time.Now(), stack: stack}
+
+	l.mu.Lock()
+	if write {
+		l.writer, l.write = gid, hold
+	} else {
+		l.readers[gid] = append(l.readers[gid], hold)
+	}
+	if l.threshold > 0 {
+		hold.timer = time.AfterFunc(l.threshold, func() {
+			l.onIssue(Issue{
+				Kind:         IssueLongHeld,
+				Write:        write,
+				Goroutine:    gid,
+				Owner:        gid,
+				Held:         time.Since(hold.since),
+				AcquireStack: hold.stack,
+			})
+		})
+	}
+	l.mu.Unlock()
+}
+
+// stopTimer 停止持有时间检测，调用时必须持有内部锁
+func (l *DebugRWLock) stopTimer(hold *debugHold) {
+	if hold.timer != nil {
+		hold.timer.Stop()
+	}
+}
+
+// issueUnsafe 根据当前的所有权构造问题，调用时必须持有内部锁
+func (l *DebugRWLock) issueUnsafe(kind IssueKind, write bool, gid int64, stack []byte) Issue {
+	issue := Issue{Kind: kind, Write: write, Goroutine: gid, Stack: stack}
+	switch {
+	case l.writer != 0:
+		issue.Owner = l.writer
+		issue.Held = time.Since(l.write.since)
+		issue.AcquireStack = l.write.stack
+	case len(l.readers[gid]) > 0:
+		hold := l.readers[gid][0]
+		issue.Owner = gid
+		issue.Held = time.Since(hold.since)
+		issue.AcquireStack = hold.stack
+	}
+	return issue
+}
+
+// anyReaderUnsafe 返回编号最小的读锁持有者，没有时返回 0，调用时必须持有内部锁
+func (l *DebugRWLock) anyReaderUnsafe() int64 {
+	var owner int64
+	for gid := range l.readers {
+		if owner == 0 || gid < owner {
+			owner = gid
+		}
+	}
+	return owner
+}
+
+// goroutinePrefix 调用栈第一行的固定前缀，例如 "goroutine 18 [running]:"
+var goroutinePrefix = []byte("goroutine ")
+
+// currentGoroutine 返回当前协程的编号和调用栈
+func currentGoroutine() (int64, []byte) {
+	buf := make([]byte, 4096)
+	for {
+		n := runtime.Stack(buf, false)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	line := bytes.TrimPrefix(buf, goroutinePrefix)
+	if end := bytes.IndexByte(line, ' '); end > 0 {
+		line = line[:end]
+	}
+	gid, _ := strconv.ParseInt(string(line), 10, 64)
+	return gid, buf
+}
@@ -0,0 +1,224 @@
+package read_write_lock
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// issueRecorder 收集调试读写锁报告的问题
+type issueRecorder struct {
+	mu     sync.Mutex
+	issues []Issue
+}
+
+func (r *issueRecorder) record(issue Issue) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.issues = append(r.issues, issue)
+}
+
+func (r *issueRecorder) kinds() []IssueKind {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kinds := make([]IssueKind, len(r.issues))
+	for i, issue := range r.issues {
+		kinds[i] = issue.Kind
+	}
+	return kinds
+}
+
+// expectPanic 执行函数并返回 panic 的值，没有 panic 时测试失败
+func expectPanic(t *testing.T, fn func()) (value any) {
+	t.Helper()
+	defer func() {
+		value = recover()
+		if value == nil {
+			t.Error("应发生 panic")
+		}
+	}()
+	fn()
+	return nil
+}
+
+// 测试正常使用时不报告问题，且可以作为 Data 的锁
+func TestDebugRWLockNormalUse(t *testing.T) {
+	recorder := &issueRecorder{}
+	lock := NewDebugRWLock(WithIssueHandler(recorder.record))
+	data := NewDataWithLocker(lock)
+
+	data.Write(7)
+	if got := data.Read(); got != 7 {
+		t.Errorf("期望读取值为7，但得到: %v", got)
+	}
+
+	// 同一协程可以重复持有读锁
+	lock.ReadLock()
+	lock.ReadLock()
+	if lock.TryWriteLock() {
+		t.Error("持有读锁时不应获取到写锁")
+	}
+	lock.ReadUnlock()
+	lock.ReadUnlock()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(val int) {
+			defer wg.Done()
+			data.Write(val)
+			data.Read()
+		}(i)
+	}
+	wg.Wait()
+
+	if kinds := recorder.kinds(); len(kinds) != 0 {
+		t.Errorf("正常使用不应报告问题，但得到: %v", kinds)
+	}
+}
+
+// 测试必然死锁的加锁会先报告再 panic
+func TestDebugRWLockSelfDeadlock(t *testing.T) {
+	recorder := &issueRecorder{}
+	lock := NewDebugRWLock(WithIssueHandler(recorder.record))
+
+	lock.WriteLock()
+	value := expectPanic(t, lock.ReadLock)
+	issue, ok := value.(Issue)
+	if !ok {
+		t.Fatalf("panic 的值应为 Issue，实际为 %T", value)
+	}
+	if issue.Kind != IssueReadAfterWrite || issue.Goroutine != issue.Owner || issue.Goroutine == 0 {
+		t.Errorf("问题信息错误: %+v", issue)
+	}
+	if !strings.Contains(string(issue.Stack), "TestDebugRWLockSelfDeadlock") {
+		t.Error("调用栈应包含触发问题的位置")
+	}
+	if len(issue.AcquireStack) == 0 {
+		t.Error("应记录持有者获取锁时的调用栈")
+	}
+
+	expectPanic(t, lock.WriteLock)
+	lock.WriteUnlock()
+
+	lock.ReadLock()
+	expectPanic(t, lock.WriteLock)
+	lock.ReadUnlock()
+
+	want := []IssueKind{IssueReadAfterWrite, IssueRecursiveWrite, IssueLockUpgrade}
+	if got := recorder.kinds(); !equalKinds(got, want) {
+		t.Errorf("期望问题 %v，但得到: %v", want, got)
+	}
+
+	// 检测之后锁仍然可用
+	if !lock.TryWriteLock() {
+		t.Error("锁应处于空闲状态")
+	}
+	lock.WriteUnlock()
+}
+
+// 测试重复释放被报告并忽略，释放其他协程持有的锁被报告但照常执行
+func TestDebugRWLockUnlockIssues(t *testing.T) {
+	recorder := &issueRecorder{}
+	lock := NewDebugRWLock(WithIssueHandler(recorder.record))
+
+	lock.WriteUnlock() // 底层锁没有被持有，直接释放会终止程序
+	lock.ReadUnlock()
+
+	// 在另一个协程中获取锁，在当前协程中释放
+	held := make(chan struct{})
+	go func() {
+		lock.WriteLock()
+		close(held)
+	}()
+	<-held
+	lock.WriteUnlock()
+
+	held = make(chan struct{})
+	go func() {
+		lock.ReadLock()
+		close(held)
+	}()
+	<-held
+	lock.ReadUnlock()
+
+	want := []IssueKind{IssueDoubleUnlock, IssueDoubleUnlock, IssueForeignUnlock, IssueForeignUnlock}
+	if got := recorder.kinds(); !equalKinds(got, want) {
+		t.Errorf("期望问题 %v，但得到: %v", want, got)
+	}
+	recorder.mu.Lock()
+	foreign := recorder.issues[2]
+	recorder.mu.Unlock()
+	if !foreign.Write || foreign.Owner == foreign.Goroutine || foreign.Owner == 0 {
+		t.Errorf("问题信息错误: %+v", foreign)
+	}
+}
+
+// 测试持有时间超过阈值的锁在仍被持有时被报告
+func TestDebugRWLockLongHeld(t *testing.T) {
+	reported := make(chan Issue, 1)
+	lock := NewDebugRWLock(
+		WithHoldThreshold(20*time.Millisecond),
+		WithIssueHandler(func(issue Issue) { reported <- issue }),
+	)
+
+	// 在阈值内释放的锁不会被报告
+	lock.WriteLock()
+	lock.WriteUnlock()
+
+	lock.ReadLock()
+	select {
+	case issue := <-reported:
+		if issue.Kind != IssueLongHeld || issue.Write || issue.Held < 20*time.Millisecond {
+			t.Errorf("问题信息错误: %+v", issue)
+		}
+		if !strings.Contains(string(issue.AcquireStack), "TestDebugRWLockLongHeld") {
+			t.Error("应记录获取锁时的调用栈")
+		}
+		if !strings.Contains(issue.String(), "锁持有时间过长") {
+			t.Errorf("描述错误: %s", issue)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("持有时间超过阈值时应报告")
+	}
+	lock.ReadUnlock()
+
+	select {
+	case issue := <-reported:
+		t.Errorf("不应再报告问题: %+v", issue)
+	case <-time.After(40 * time.Millisecond):
+	}
+}
+
+// 测试协程编号的解析
+func TestCurrentGoroutine(t *testing.T) {
+	gid, stack := currentGoroutine()
+	if gid <= 0 {
+		t.Fatalf("协程编号应为正数，但得到: %d", gid)
+	}
+	if !strings.HasPrefix(string(stack), "goroutine ") {
+		t.Errorf("调用栈格式错误: %s", stack)
+	}
+
+	other := make(chan int64)
+	go func() {
+		id, _ := currentGoroutine()
+		other <- id
+	}()
+	if id := <-other; id == gid || id <= 0 {
+		t.Errorf("不同协程的编号应不同: %d, %d", gid, id)
+	}
+}
+
+func equalKinds(a, b []IssueKind) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
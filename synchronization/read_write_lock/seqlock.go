@@ -0,0 +1,129 @@
+package read_write_lock
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// SeqLock 顺序锁（乐观读锁）
+// 写者之间用互斥锁串行化，每次写入前后各把版本号加一，因此写入进行中版本号为奇数；
+// 读者不加锁，先读版本号，再复制数据，最后确认版本号没有变化，否则重试。
+// 读者永远不会阻塞写者，适合写入频繁、读取数据量小的场景。
+type SeqLock struct {
+	mutex    sync.Mutex
+	sequence atomic.Uint64
+	retries  atomic.Uint64
+}
+
+// NewSeqLock 创建一个新的顺序锁
+func NewSeqLock() *SeqLock {
+	return &SeqLock{}
+}
+
+// ReadBegin 开始一次乐观读，返回读取开始时的版本号
+// 如果有写入正在进行，会自旋等待写入完成
+func (s *SeqLock) ReadBegin() uint64 {
+	for {
+		seq := s.sequence.Load()
+		if seq&1 == 0 {
+			return seq
+		}
+		runtime.Gosched()
+	}
+}
+
+// ReadRetry 检查读取期间版本号是否变化，返回 true 表示需要重试
+func (s *SeqLock) ReadRetry(seq uint64) bool {
+	if s.sequence.Load() != seq {
+		s.retries.Add(1)
+		return true
+	}
+	return false
+}
+
+// WriteLock 获取写锁，版本号变为奇数
+func (s *SeqLock) WriteLock() {
+	s.mutex.Lock()
+	s.sequence.Add(1)
+}
+
+// WriteUnlock 释放写锁，版本号变为偶数
+func (s *SeqLock) WriteUnlock() {
+	s.sequence.Add(1)
+	s.mutex.Unlock()
+}
+
+// Sequence 返回当前版本号
+func (s *SeqLock) Sequence() uint64 {
+	return s.sequence.Load()
+}
+
+// Retries 返回读者因版本号变化而重试的总次数
+func (s *SeqLock) Retries() uint64 {
+	return s.retries.Load()
+}
+
+// SeqData 使用顺序锁保护的数据，提供与 Data 平行的读写接口
+// 数据字段使用原子类型存储：Go 内存模型中与写入并发的普通读取属于数据竞争，
+// 原子读写保证单个字段不被撕裂，顺序锁则保证多个字段之间的一致性
+type SeqData struct {
+	lock    *SeqLock
+	value   atomic.Int64 // 数据值
+	version atomic.Int64 // 写入次数，与 value 一起构成需要保持一致的一组字段
+}
+
+// NewSeqData 创建一个新的顺序锁数据实例
+func NewSeqData() *SeqData {
+	return &SeqData{
+		lock: NewSeqLock(),
+	}
+}
+
+// Read 乐观读取数据值，读取期间如有写入则重试
+func (d *SeqData) Read() int {
+	value, _ := d.ReadVersioned()
+	return value
+}
+
+// ReadVersioned 一致地读取数据值和对应的写入次数
+func (d *SeqData) ReadVersioned() (int, int64) {
+	for {
+		seq := d.lock.ReadBegin()
+		value := d.value.Load()
+		version := d.version.Load()
+		if !d.lock.ReadRetry(seq) {
+			return int(value), version
+		}
+	}
+}
+
+// Write 写入数据值
+func (d *SeqData) Write(val int) bool {
+	d.lock.WriteLock()
+	defer d.lock.WriteUnlock()
+
+	d.value.Store(int64(val))
+	d.version.Add(1)
+	return true
+}
+
+// ReadWithCallback 以一致的数据值执行自定义读操作
+// 回调在读取完成后执行，因此不会被重试
+func (d *SeqData) ReadWithCallback(callback func(val int)) {
+	callback(d.Read())
+}
+
+// Update 在写锁保护下根据旧值计算新值，是原子的读-改-写操作
+func (d *SeqData) Update(fn func(val int) int) {
+	d.lock.WriteLock()
+	defer d.lock.WriteUnlock()
+
+	d.value.Store(int64(fn(int(d.value.Load()))))
+	d.version.Add(1)
+}
+
+// Retries 返回读者重试的总次数
+func (d *SeqData) Retries() uint64 {
+	return d.lock.Retries()
+}
@@ -0,0 +1,169 @@
+package read_write_lock
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// 测试顺序锁数据的基本读写
+func TestSeqDataBasic(t *testing.T) {
+	data := NewSeqData()
+
+	if got := data.Read(); got != 0 {
+		t.Errorf("初始值应为0，但得到: %v", got)
+	}
+
+	data.Write(42)
+	if got := data.Read(); got != 42 {
+		t.Errorf("期望读取值为42，但得到: %v", got)
+	}
+
+	data.Update(func(val int) int { return val * 2 })
+	value, version := data.ReadVersioned()
+	if value != 84 || version != 2 {
+		t.Errorf("期望值84、版本2，但得到: %v, %v", value, version)
+	}
+
+	var seen int
+	data.ReadWithCallback(func(val int) { seen = val })
+	if seen != 84 {
+		t.Errorf("回调应收到84，但得到: %v", seen)
+	}
+}
+
+// 测试版本号的奇偶变化
+func TestSeqLockSequence(t *testing.T) {
+	lock := NewSeqLock()
+
+	seq := lock.ReadBegin()
+	if seq != 0 {
+		t.Errorf("初始版本号应为0，但得到: %v", seq)
+	}
+
+	lock.WriteLock()
+	if lock.Sequence()%2 != 1 {
+		t.Error("写入进行中版本号应为奇数")
+	}
+	lock.WriteUnlock()
+
+	if lock.Sequence() != 2 {
+		t.Errorf("一次写入后版本号应为2，但得到: %v", lock.Sequence())
+	}
+	if !lock.ReadRetry(seq) {
+		t.Error("读取期间发生写入时应要求重试")
+	}
+	if lock.Retries() != 1 {
+		t.Errorf("重试次数应为1，但得到: %v", lock.Retries())
+	}
+}
+
+// 测试并发写入下读者总能读到一致的数据
+func TestSeqDataConsistency(t *testing.T) {
+	lock := NewSeqLock()
+	var x, y atomic.Int64
+
+	var wg sync.WaitGroup
+	var stop atomic.Bool
+
+	// 写者始终保持 x == y
+	for w := 0; w < 2; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := int64(0); i < 5000; i++ {
+				lock.WriteLock()
+				x.Store(i)
+				y.Store(i)
+				lock.WriteUnlock()
+			}
+		}()
+	}
+
+	var inconsistent atomic.Int32
+	var readers sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for !stop.Load() {
+				for {
+					seq := lock.ReadBegin()
+					a, b := x.Load(), y.Load()
+					if lock.ReadRetry(seq) {
+						continue
+					}
+					if a != b {
+						inconsistent.Add(1)
+					}
+					break
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	stop.Store(true)
+	readers.Wait()
+
+	if inconsistent.Load() != 0 {
+		t.Errorf("读者读到了 %d 次不一致的数据", inconsistent.Load())
+	}
+}
+
+// 测试并发更新不丢失
+func TestSeqDataConcurrentUpdate(t *testing.T) {
+	data := NewSeqData()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				data.Update(func(val int) int { return val + 1 })
+				data.Read()
+			}
+		}()
+	}
+	wg.Wait()
+
+	value, version := data.ReadVersioned()
+	if value != 1000 || version != 1000 {
+		t.Errorf("期望值和版本都为1000，但得到: %v, %v", value, version)
+	}
+}
+
+// 写多读少场景下的基准测试：每4次操作中有1次写入
+func benchmarkWriteHeavy(b *testing.B, read func() int, write func(int)) {
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if i%4 == 0 {
+				write(i)
+			} else {
+				read()
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkSeqLockWriteHeavy(b *testing.B) {
+	data := NewSeqData()
+	benchmarkWriteHeavy(b, data.Read, func(v int) { data.Write(v) })
+}
+
+func BenchmarkStandardRWLockWriteHeavy(b *testing.B) {
+	data := NewData()
+	benchmarkWriteHeavy(b, data.Read, func(v int) { data.Write(v) })
+}
+
+func BenchmarkSeqLockReadOnly(b *testing.B) {
+	data := NewSeqData()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			data.Read()
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/XiaoluCoding626/go-design-pattern/creational/object_pool"
+	"github.com/XiaoluCoding626/go-design-pattern/synchronization/semaphore"
+)
+
+// buffer 示例中放在对象池里的缓冲区
+type buffer struct {
+	id   int
+	data []byte
+}
+
+func (b *buffer) Reset() error   { b.data = b.data[:0]; return nil }
+func (b *buffer) Validate() bool { return true }
+func (b *buffer) ID() int        { return b.id }
+
+// RunExample 运行资源作用域示例：信号量限流 + 对象池借出缓冲区，任何情况下都按相反顺序归还
+func RunExample() {
+	var nextID int
+	var idMutex sync.Mutex
+	cfg := object_pool.DefaultPoolConfig(func() (object_pool.Object, error) {
+		idMutex.Lock()
+		defer idMutex.Unlock()
+		nextID++
+		return &buffer{id: nextID, data: make([]byte, 0, 64)}, nil
+	})
+	cfg.InitialSize, cfg.MaxSize, cfg.MaxIdle = 2, 2, 2
+	pool, err := object_pool.NewObjectPool(cfg)
+	if err != nil {
+		fmt.Println("创建对象池失败:", err)
+		return
+	}
+	defer pool.Close()
+	sem := semaphore.New(2)
+	ctx := context.Background()
+
+	// 单个资源
+	Using(FromPool(pool, time.Second), func(obj object_pool.Object) error {
+		buf := obj.(*buffer)
+		buf.data = append(buf.data, "hello"...)
+		fmt.Printf("借出缓冲区 %d，写入 %q\n", buf.id, buf.data)
+		return nil
+	})
+
+	// 多个资源：先获取票证再借出缓冲区，结束时先归还缓冲区再归还票证
+	var wg sync.WaitGroup
+	for i := 1; i <= 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := Run(func(s *Scope) error {
+				if _, err := Acquire(s, Permit(ctx, sem)); err != nil {
+					return err
+				}
+				obj, err := Acquire(s, FromPool(pool, time.Second))
+				if err != nil {
+					return err
+				}
+				buf := obj.(*buffer)
+				buf.data = fmt.Appendf(buf.data, "任务 %d", i)
+				time.Sleep(10 * time.Millisecond)
+				if i == 3 {
+					return errors.New("任务 3 失败")
+				}
+				return nil
+			})
+			if err != nil {
+				fmt.Println("任务出错，资源已归还:", err)
+			}
+		}()
+	}
+	wg.Wait()
+	active, idle, _ := pool.Status()
+	fmt.Printf("全部结束: 可用票证 %d，借出 %d，空闲 %d\n", sem.Available(), active, idle)
+
+	// panic 时资源同样被归还
+	func() {
+		defer func() { fmt.Println("捕获 panic:", recover()) }()
+		Using(Permit(ctx, sem), func(struct{}) error {
+			panic("处理过程中崩溃")
+		})
+	}()
+	fmt.Println("panic 之后可用票证:", sem.Available())
+}
@@ -0,0 +1,52 @@
+package scope
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/XiaoluCoding626/go-design-pattern/creational/object_pool"
+	"github.com/XiaoluCoding626/go-design-pattern/synchronization/semaphore"
+)
+
+// FromPool 把对象池适配为资源：借出一个对象，释放时归还
+// 归还失败只会发生在池已关闭或对象已被池丢弃时，调用方无法补救，因此忽略该错误
+func FromPool(pool *object_pool.ObjectPool, timeout time.Duration) Acquirer[object_pool.Object] {
+	return func() (object_pool.Object, func(), error) {
+		obj, err := pool.AcquireWithTimeout(timeout)
+		if err != nil {
+			return nil, nil, err
+		}
+		return obj, func() { pool.ReleaseObject(obj) }, nil
+	}
+}
+
+// Permit 把信号量适配为资源：阻塞获取一个票证，直到 ctx 结束；释放时归还
+// 作用域保证每次获取恰好对应一次归还，不会出现 ErrIllegalRelease。
+// 需要一次获取多份容量时使用 Weighted，逐个获取票证在竞争下可能互相持有一部分而死锁
+func Permit(ctx context.Context, sem semaphore.Semaphorer) Acquirer[struct{}] {
+	return func() (struct{}, func(), error) {
+		if err := sem.Acquire(ctx); err != nil {
+			return struct{}{}, nil, err
+		}
+		return struct{}{}, func() { sem.Release() }, nil
+	}
+}
+
+// Weighted 把带权重的信号量适配为资源：获取 weight 的容量，释放时归还
+func Weighted(ctx context.Context, sem *semaphore.WeightedSemaphore, weight int64) Acquirer[int64] {
+	return func() (int64, func(), error) {
+		if err := sem.Acquire(ctx, weight); err != nil {
+			return 0, nil, err
+		}
+		return weight, func() { sem.Release(weight) }, nil
+	}
+}
+
+// Locked 把锁适配为资源：加锁，释放时解锁
+func Locked(l sync.Locker) Acquirer[sync.Locker] {
+	return func() (sync.Locker, func(), error) {
+		l.Lock()
+		return l, l.Unlock, nil
+	}
+}
@@ -0,0 +1,133 @@
+package scope
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// 作用域相关错误
+var (
+	ErrAcquire      = errors.New("获取资源失败")
+	ErrCleanupPanic = errors.New("释放资源时发生 panic")
+	ErrScopeClosed  = errors.New("作用域已结束")
+)
+
+// Acquirer 获取资源的函数：返回资源和对应的释放函数，释放函数可以为 nil
+// 获取失败时不应返回需要释放的资源
+type Acquirer[T any] func() (T, func(), error)
+
+// Scope 资源作用域：登记的释放函数在作用域结束时按登记的相反顺序（LIFO）执行
+//
+// 无论 Run 的函数正常返回、返回错误还是 panic，所有释放函数都会执行；
+// 某个释放函数 panic 不会阻止其余的释放函数执行，panic 被转换为 ErrCleanupPanic。
+// Scope 可以在多个协程中登记资源，但不应在 Run 返回后继续使用。
+type Scope struct {
+	mutex    sync.Mutex
+	cleanups []func() error
+	closed   bool
+}
+
+// Run 创建作用域并执行 fn，fn 返回后按 LIFO 顺序释放所有登记的资源
+// 返回 fn 的错误与释放时的错误合并后的结果；fn panic 时先释放资源，再继续向上传播原来的 panic
+func Run(fn func(s *Scope) error) (err error) {
+	s := &Scope{}
+	defer func() {
+		// fn panic 时这里的赋值没有意义，panic 会在释放完成后继续传播
+		err = errors.Join(err, s.close())
+	}()
+	return fn(s)
+}
+
+// Defer 登记一个释放函数，作用域结束时执行
+// 作用域已经结束时立即执行 cleanup 并返回 ErrScopeClosed，避免资源泄漏
+func (s *Scope) Defer(cleanup func() error) error {
+	if cleanup == nil {
+		return nil
+	}
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return errors.Join(ErrScopeClosed, runCleanup(cleanup))
+	}
+	s.cleanups = append(s.cleanups, cleanup)
+	s.mutex.Unlock()
+	return nil
+}
+
+// close 结束作用域，按 LIFO 顺序执行所有释放函数
+func (s *Scope) close() error {
+	s.mutex.Lock()
+	cleanups := s.cleanups
+	s.cleanups = nil
+	s.closed = true
+	s.mutex.Unlock()
+
+	var errs []error
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		if err := runCleanup(cleanups[i]); err != nil {
+			errs = append(errs, fmt.Errorf("释放第 %d 个资源: %w", i+1, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runCleanup 执行释放函数，把 panic 转换为错误
+func runCleanup(cleanup func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrCleanupPanic, r)
+		}
+	}()
+	return cleanup()
+}
+
+// Acquire 在作用域中获取资源，资源在作用域结束时释放
+// 获取失败时返回的错误可以用 errors.Is 判断为 ErrAcquire，之前获取的资源仍会在作用域结束时释放
+func Acquire[T any](s *Scope, acquire Acquirer[T]) (T, error) {
+	var zero T
+	s.mutex.Lock()
+	closed := s.closed
+	s.mutex.Unlock()
+	if closed {
+		return zero, ErrScopeClosed
+	}
+
+	res, release, err := acquire()
+	if err != nil {
+		return zero, fmt.Errorf("%w: %w", ErrAcquire, err)
+	}
+	if release != nil {
+		if err := s.Defer(func() error { release(); return nil }); err != nil {
+			return zero, err
+		}
+	}
+	return res, nil
+}
+
+// Using 获取一个资源并交给 fn 使用，fn 返回或 panic 后释放资源
+func Using[T any](acquire Acquirer[T], fn func(T) error) error {
+	return Run(func(s *Scope) error {
+		res, err := Acquire(s, acquire)
+		if err != nil {
+			return err
+		}
+		return fn(res)
+	})
+}
+
+// UsingAll 按顺序获取多个同类资源并交给 fn 使用，结束后按相反顺序释放
+// 某个资源获取失败时不再获取后面的资源，已经获取的资源按相反顺序释放
+func UsingAll[T any](acquires []Acquirer[T], fn func([]T) error) error {
+	return Run(func(s *Scope) error {
+		resources := make([]T, 0, len(acquires))
+		for i, acquire := range acquires {
+			res, err := Acquire(s, acquire)
+			if err != nil {
+				return fmt.Errorf("第 %d 个资源: %w", i+1, err)
+			}
+			resources = append(resources, res)
+		}
+		return fn(resources)
+	})
+}
@@ -0,0 +1,319 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/XiaoluCoding626/go-design-pattern/creational/object_pool"
+	"github.com/XiaoluCoding626/go-design-pattern/synchronization/semaphore"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder 记录资源的获取和释放顺序
+type recorder struct {
+	mutex  sync.Mutex
+	events []string
+}
+
+func (r *recorder) add(event string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, event)
+}
+
+// resource 返回一个记录获取和释放的资源
+func (r *recorder) resource(name string) Acquirer[string] {
+	return func() (string, func(), error) {
+		r.add("获取 " + name)
+		return name, func() { r.add("释放 " + name) }, nil
+	}
+}
+
+// failing 返回一个获取失败的资源
+func failing(err error) Acquirer[string] {
+	return func() (string, func(), error) {
+		return "", nil, err
+	}
+}
+
+// TestUsing 测试单个资源在 fn 返回后释放，fn 的错误原样返回
+func TestUsing(t *testing.T) {
+	r := &recorder{}
+	err := Using(r.resource("a"), func(name string) error {
+		r.add("使用 " + name)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"获取 a", "使用 a", "释放 a"}, r.events)
+
+	errWork := errors.New("处理失败")
+	err = Using(r.resource("b"), func(string) error { return errWork })
+	assert.ErrorIs(t, err, errWork)
+	assert.Equal(t, "释放 b", r.events[len(r.events)-1])
+
+	errDown := errors.New("连接被拒绝")
+	called := false
+	err = Using(failing(errDown), func(string) error { called = true; return nil })
+	assert.ErrorIs(t, err, ErrAcquire)
+	assert.ErrorIs(t, err, errDown)
+	assert.False(t, called, "获取失败时不调用 fn")
+
+	// 释放函数为 nil 的资源
+	err = Using(func() (int, func(), error) { return 42, nil, nil }, func(v int) error {
+		assert.Equal(t, 42, v)
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+// TestRunLIFO 测试多个资源按获取的相反顺序释放
+func TestRunLIFO(t *testing.T) {
+	r := &recorder{}
+	err := Run(func(s *Scope) error {
+		for _, name := range []string{"a", "b", "c"} {
+			if _, err := Acquire(s, r.resource(name)); err != nil {
+				return err
+			}
+		}
+		s.Defer(func() error { r.add("清理临时文件"); return nil })
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"获取 a", "获取 b", "获取 c", "清理临时文件", "释放 c", "释放 b", "释放 a"}, r.events)
+}
+
+// TestPartialAcquireFailure 测试获取到一半失败时，已获取的资源按相反顺序释放
+func TestPartialAcquireFailure(t *testing.T) {
+	r := &recorder{}
+	errFull := errors.New("资源耗尽")
+	called := false
+	err := UsingAll([]Acquirer[string]{r.resource("a"), r.resource("b"), failing(errFull), r.resource("d")},
+		func([]string) error { called = true; return nil })
+
+	assert.ErrorIs(t, err, ErrAcquire)
+	assert.ErrorIs(t, err, errFull)
+	assert.ErrorContains(t, err, "第 3 个资源")
+	assert.False(t, called)
+	assert.Equal(t, []string{"获取 a", "获取 b", "释放 b", "释放 a"}, r.events, "失败之后的资源不再获取")
+
+	r = &recorder{}
+	err = UsingAll([]Acquirer[string]{r.resource("x"), r.resource("y")}, func(names []string) error {
+		assert.Equal(t, []string{"x", "y"}, names)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"获取 x", "获取 y", "释放 y", "释放 x"}, r.events)
+}
+
+// TestPanicReleasesResources 测试 fn panic 时所有资源仍然按相反顺序释放，panic 继续向上传播
+func TestPanicReleasesResources(t *testing.T) {
+	r := &recorder{}
+	assert.PanicsWithValue(t, "崩溃", func() {
+		Run(func(s *Scope) error {
+			Acquire(s, r.resource("a"))
+			Acquire(s, r.resource("b"))
+			panic("崩溃")
+		})
+	})
+	assert.Equal(t, []string{"获取 a", "获取 b", "释放 b", "释放 a"}, r.events)
+
+	r = &recorder{}
+	assert.Panics(t, func() {
+		Using(r.resource("c"), func(string) error { panic(fmt.Errorf("包装的错误")) })
+	})
+	assert.Equal(t, []string{"获取 c", "释放 c"}, r.events)
+}
+
+// TestCleanupFailures 测试释放函数出错或 panic 时其余释放函数照常执行，错误合并返回
+func TestCleanupFailures(t *testing.T) {
+	r := &recorder{}
+	errFlush := errors.New("刷新失败")
+	errWork := errors.New("处理失败")
+	err := Run(func(s *Scope) error {
+		Acquire(s, r.resource("a"))
+		s.Defer(func() error { panic("释放时崩溃") })
+		s.Defer(func() error { return errFlush })
+		Acquire(s, r.resource("b"))
+		return errWork
+	})
+
+	assert.ErrorIs(t, err, errWork)
+	assert.ErrorIs(t, err, errFlush)
+	assert.ErrorIs(t, err, ErrCleanupPanic)
+	assert.ErrorContains(t, err, "释放第 2 个资源")
+	assert.ErrorContains(t, err, "释放第 3 个资源")
+	assert.Equal(t, []string{"获取 a", "获取 b", "释放 b", "释放 a"}, r.events)
+}
+
+// TestScopeClosed 测试作用域结束后不能再获取资源，登记的释放函数立即执行
+func TestScopeClosed(t *testing.T) {
+	r := &recorder{}
+	var leaked *Scope
+	Run(func(s *Scope) error {
+		leaked = s
+		return nil
+	})
+
+	_, err := Acquire(leaked, r.resource("a"))
+	assert.ErrorIs(t, err, ErrScopeClosed)
+	assert.Empty(t, r.events, "作用域结束后不再获取")
+
+	released := false
+	err = leaked.Defer(func() error { released = true; return nil })
+	assert.ErrorIs(t, err, ErrScopeClosed)
+	assert.True(t, released, "登记在已结束作用域上的释放函数立即执行")
+	assert.NoError(t, leaked.Defer(nil))
+}
+
+// testObject 测试用的池对象
+type testObject struct{ id int }
+
+func (o *testObject) Reset() error   { return nil }
+func (o *testObject) Validate() bool { return true }
+func (o *testObject) ID() int        { return o.id }
+
+func newTestPool(t *testing.T, size int) *object_pool.ObjectPool {
+	t.Helper()
+	var mutex sync.Mutex
+	next := 0
+	cfg := object_pool.DefaultPoolConfig(func() (object_pool.Object, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		next++
+		return &testObject{id: next}, nil
+	})
+	cfg.InitialSize, cfg.MaxSize, cfg.MaxIdle = size, size, size
+	pool, err := object_pool.NewObjectPool(cfg)
+	assert.NoError(t, err)
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// TestObjectPoolResource 测试对象池借出的对象在任何情况下都会归还
+func TestObjectPoolResource(t *testing.T) {
+	pool := newTestPool(t, 2)
+
+	err := Using(FromPool(pool, time.Second), func(obj object_pool.Object) error {
+		active, _, _ := pool.Status()
+		assert.Equal(t, 1, active)
+		return errors.New("处理失败")
+	})
+	assert.Error(t, err)
+	active, idle, _ := pool.Status()
+	assert.Equal(t, 0, active)
+	assert.Equal(t, 2, idle)
+
+	assert.Panics(t, func() {
+		UsingAll([]Acquirer[object_pool.Object]{FromPool(pool, time.Second), FromPool(pool, time.Second)},
+			func(objs []object_pool.Object) error {
+				assert.NotEqual(t, objs[0].ID(), objs[1].ID())
+				panic("崩溃")
+			})
+	})
+	active, _, _ = pool.Status()
+	assert.Equal(t, 0, active, "panic 后对象全部归还")
+
+	// 池耗尽时获取失败，之前借出的对象照常归还
+	err = UsingAll([]Acquirer[object_pool.Object]{
+		FromPool(pool, time.Second), FromPool(pool, time.Second), FromPool(pool, 10*time.Millisecond),
+	}, func([]object_pool.Object) error { return nil })
+	assert.ErrorIs(t, err, ErrAcquire)
+	active, _, _ = pool.Status()
+	assert.Equal(t, 0, active)
+}
+
+// TestSemaphoreResource 测试信号量票证在并发任务中总是归还，并发数不超过容量
+func TestSemaphoreResource(t *testing.T) {
+	sem := semaphore.New(3)
+	ctx := context.Background()
+
+	var (
+		mutex   sync.Mutex
+		running int
+		peak    int
+		wg      sync.WaitGroup
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { recover() }()
+			Using(Permit(ctx, sem), func(struct{}) error {
+				mutex.Lock()
+				running++
+				peak = max(peak, running)
+				mutex.Unlock()
+
+				time.Sleep(time.Millisecond)
+
+				mutex.Lock()
+				running--
+				mutex.Unlock()
+				if i%5 == 0 {
+					panic("崩溃")
+				}
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	assert.LessOrEqual(t, peak, 3)
+	assert.Equal(t, 3, sem.Available(), "所有票证都已归还")
+
+	// 获取超时
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	err := Run(func(s *Scope) error {
+		for i := 0; i < 3; i++ {
+			if _, err := Acquire(s, Permit(ctx, sem)); err != nil {
+				return err
+			}
+		}
+		_, err := Acquire(s, Permit(timeout, sem))
+		return err
+	})
+	assert.ErrorIs(t, err, ErrAcquire)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 3, sem.Available())
+
+	ws := semaphore.NewWeighted(10)
+	err = Using(Weighted(ctx, ws, 7), func(w int64) error {
+		assert.Equal(t, int64(3), ws.Available())
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), ws.Available())
+
+	var mu sync.Mutex
+	Using(Locked(&mu), func(sync.Locker) error {
+		assert.False(t, mu.TryLock())
+		return nil
+	})
+	assert.True(t, mu.TryLock())
+}
+
+// BenchmarkUsing 比较作用域与手写 defer 的开销
+func BenchmarkUsing(b *testing.B) {
+	var mu sync.Mutex
+	b.Run("Defer", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			func() {
+				mu.Lock()
+				defer mu.Unlock()
+			}()
+		}
+	})
+	b.Run("Using", func(b *testing.B) {
+		acquire := Locked(&mu)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			Using(acquire, func(sync.Locker) error { return nil })
+		}
+	})
+}
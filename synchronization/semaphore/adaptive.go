@@ -0,0 +1,376 @@
+package semaphore
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Sample 一次受保护调用的结果，由 AdaptiveTicket.Release 提交给限流信号
+type Sample struct {
+	Latency time.Duration // 从获取票证到释放票证的耗时
+	Err     error         // 调用返回的错误
+}
+
+// Signal 判断系统是否过载的信号，AdaptiveSemaphore 根据信号调整并发上限
+//
+// Observe 在信号量的锁内按顺序调用，实现不需要自己处理并发。
+// 如果信号还实现了 Reset()，上限降低后会调用 Reset 清空历史数据，避免同一批样本重复触发降低
+type Signal interface {
+	// Observe 记录一个样本，返回 true 表示系统过载
+	Observe(sample Sample) bool
+}
+
+// SignalFunc 把普通函数适配为 Signal
+type SignalFunc func(sample Sample) bool
+
+// Observe 调用函数本身
+func (f SignalFunc) Observe(sample Sample) bool {
+	return f(sample)
+}
+
+// ErrorSignal 任何错误都视为过载，是 AdaptiveSemaphore 的默认信号
+func ErrorSignal() Signal {
+	return SignalFunc(func(sample Sample) bool {
+		return sample.Err != nil
+	})
+}
+
+// errorRateSignal 最近 window 次调用的错误率超过阈值时视为过载
+type errorRateSignal struct {
+	threshold float64
+	outcomes  []bool // 环形缓冲区，true 表示调用失败
+	next      int
+	count     int
+	failures  int
+}
+
+// ErrorRateSignal 最近 window 次调用中失败的比例超过 threshold (0-1) 时视为过载，窗口填满之前不判断
+func ErrorRateSignal(window int, threshold float64) Signal {
+	if window <= 0 {
+		window = 1
+	}
+	return &errorRateSignal{threshold: threshold, outcomes: make([]bool, window)}
+}
+
+func (s *errorRateSignal) Observe(sample Sample) bool {
+	if s.count == len(s.outcomes) {
+		if s.outcomes[s.next] {
+			s.failures--
+		}
+	} else {
+		s.count++
+	}
+	failed := sample.Err != nil
+	if failed {
+		s.failures++
+	}
+	s.outcomes[s.next] = failed
+	s.next = (s.next + 1) % len(s.outcomes)
+
+	return s.count == len(s.outcomes) && float64(s.failures)/float64(s.count) > s.threshold
+}
+
+func (s *errorRateSignal) Reset() {
+	s.next, s.count, s.failures = 0, 0, 0
+}
+
+// latencySignal 最近 window 次调用的延迟百分位超过目标值时视为过载
+type latencySignal struct {
+	percentile float64
+	target     time.Duration
+	latencies  []time.Duration // 环形缓冲区
+	sorted     []time.Duration // 计算百分位时复用的缓冲区
+	next       int
+	count      int
+}
+
+// LatencySignal 最近 window 次调用延迟的第 percentile (0-100) 百分位超过 target 时视为过载，窗口填满之前不判断
+// 例如 LatencySignal(100, 99, 200*time.Millisecond) 表示最近 100 次调用的 p99 延迟超过 200 毫秒
+func LatencySignal(window int, percentile float64, target time.Duration) Signal {
+	if window <= 0 {
+		window = 1
+	}
+	return &latencySignal{
+		percentile: percentile,
+		target:     target,
+		latencies:  make([]time.Duration, window),
+		sorted:     make([]time.Duration, window),
+	}
+}
+
+func (s *latencySignal) Observe(sample Sample) bool {
+	s.latencies[s.next] = sample.Latency
+	s.next = (s.next + 1) % len(s.latencies)
+	if s.count < len(s.latencies) {
+		s.count++
+		if s.count < len(s.latencies) {
+			return false
+		}
+	}
+
+	copy(s.sorted, s.latencies)
+	sort.Slice(s.sorted, func(i, j int) bool { return s.sorted[i] < s.sorted[j] })
+	index := int(float64(len(s.sorted))*s.percentile/100+0.5) - 1
+	if index < 0 {
+		index = 0
+	} else if index >= len(s.sorted) {
+		index = len(s.sorted) - 1
+	}
+	return s.sorted[index] > s.target
+}
+
+func (s *latencySignal) Reset() {
+	s.next, s.count = 0, 0
+}
+
+// AdaptiveStats 自适应信号量的统计信息
+type AdaptiveStats struct {
+	Limit     int // 当前的并发上限
+	InFlight  int // 正在执行的调用数
+	Successes int // 没有触发过载的调用数
+	Overloads int // 触发过载的调用数
+	Decreases int // 上限降低的次数
+}
+
+// AdaptiveOption 配置 AdaptiveSemaphore 的选项
+type AdaptiveOption func(*AdaptiveSemaphore)
+
+// WithLimitBounds 设置并发上限的范围，默认为 [1, 1000]
+func WithLimitBounds(min, max int) AdaptiveOption {
+	return func(s *AdaptiveSemaphore) {
+		if min < 1 {
+			min = 1
+		}
+		if max < min {
+			max = min
+		}
+		s.minLimit, s.maxLimit = min, max
+	}
+}
+
+// WithAdditiveIncrease 设置加性增加的步长，每一轮（约等于上限个成功调用）上限增加 step，默认为 1
+func WithAdditiveIncrease(step float64) AdaptiveOption {
+	return func(s *AdaptiveSemaphore) {
+		if step > 0 {
+			s.increase = step
+		}
+	}
+}
+
+// WithMultiplicativeDecrease 设置过载时上限乘以的系数 (0-1)，默认为 0.5
+func WithMultiplicativeDecrease(factor float64) AdaptiveOption {
+	return func(s *AdaptiveSemaphore) {
+		if factor > 0 && factor < 1 {
+			s.decrease = factor
+		}
+	}
+}
+
+// WithSignals 设置判断过载的信号，任意一个信号返回 true 即视为过载，默认使用 ErrorSignal
+func WithSignals(signals ...Signal) AdaptiveOption {
+	return func(s *AdaptiveSemaphore) {
+		if len(signals) > 0 {
+			s.signals = signals
+		}
+	}
+}
+
+// AdaptiveSemaphore 自适应并发限制器，使用 AIMD（加性增加、乘性减少）调整并发上限
+//
+// 每个成功的调用使上限增加 step/上限，即每一轮增加 step；信号判断为过载时上限乘以减少系数。
+// 同一时刻开始的调用往往一起失败，为避免一次拥塞被重复惩罚，在上一次降低之前获取的票证触发的过载不会再次降低上限。
+// 上限降低时已经在执行的调用不受影响，新的获取会等到正在执行的调用数低于新的上限
+type AdaptiveSemaphore struct {
+	mu sync.Mutex
+
+	// 当前上限，使用浮点数累积加性增加的小数部分
+	limit              float64
+	minLimit, maxLimit int
+	increase, decrease float64
+	signals            []Signal
+
+	inFlight int
+	stats    AdaptiveStats
+
+	// 上限降低的代数，每次降低加一
+	generation uint64
+
+	// 有票证被归还或上限提高时关闭，用于唤醒所有等待者
+	released chan struct{}
+
+	// 时间来源，便于测试
+	now func() time.Time
+}
+
+// AdaptiveTicket 从 AdaptiveSemaphore 获取的票证，调用结束后必须调用 Release 提交结果
+type AdaptiveTicket struct {
+	sem        *AdaptiveSemaphore
+	acquired   time.Time
+	generation uint64 // 获取时信号量的代数
+	released   bool
+}
+
+// NewAdaptive 创建初始并发上限为 initial 的自适应信号量
+func NewAdaptive(initial int, opts ...AdaptiveOption) *AdaptiveSemaphore {
+	s := &AdaptiveSemaphore{
+		minLimit: 1,
+		maxLimit: 1000,
+		increase: 1,
+		decrease: 0.5,
+		signals:  []Signal{ErrorSignal()},
+		released: make(chan struct{}),
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.limit = float64(s.clamp(initial))
+	return s
+}
+
+// Acquire 获取一个票证，正在执行的调用数达到上限时阻塞等待
+// 如果提供的context被取消，则返回context的错误
+func (s *AdaptiveSemaphore) Acquire(ctx context.Context) (*AdaptiveTicket, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	for s.inFlight >= s.limitUnsafe() {
+		wait := s.released
+		s.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		s.mu.Lock()
+	}
+	defer s.mu.Unlock()
+	return s.acquireUnsafe(), nil
+}
+
+// TryAcquire 非阻塞地获取一个票证，达到上限时返回 false
+func (s *AdaptiveSemaphore) TryAcquire() (*AdaptiveTicket, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.inFlight >= s.limitUnsafe() {
+		return nil, false
+	}
+	return s.acquireUnsafe(), true
+}
+
+// Do 获取票证后执行 fn，并把 fn 的耗时和错误提交给限流信号
+func (s *AdaptiveSemaphore) Do(ctx context.Context, fn func() error) error {
+	ticket, err := s.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	err = fn()
+	ticket.Release(err)
+	return err
+}
+
+// Limit 返回当前的并发上限
+func (s *AdaptiveSemaphore) Limit() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.limitUnsafe()
+}
+
+// InFlight 返回正在执行的调用数
+func (s *AdaptiveSemaphore) InFlight() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inFlight
+}
+
+// Stats 返回统计信息
+func (s *AdaptiveSemaphore) Stats() AdaptiveStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.stats
+	stats.Limit = s.limitUnsafe()
+	stats.InFlight = s.inFlight
+	return stats
+}
+
+// Release 归还票证并提交调用结果，err 为调用返回的错误；重复释放返回 ErrIllegalRelease
+func (t *AdaptiveTicket) Release(err error) error {
+	s := t.sem
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t.released {
+		return ErrIllegalRelease
+	}
+	t.released = true
+	s.inFlight--
+
+	sample := Sample{Latency: s.now().Sub(t.acquired), Err: err}
+	overloaded := false
+	for _, signal := range s.signals {
+		// 每个信号都需要记录样本，不能短路
+		if signal.Observe(sample) {
+			overloaded = true
+		}
+	}
+
+	if overloaded {
+		s.stats.Overloads++
+		// 上一次降低之前开始的调用反映的是旧上限下的情况，不再重复降低
+		if t.generation < s.generation {
+			s.broadcastUnsafe()
+			return nil
+		}
+		s.limit = float64(s.clamp(int(s.limit * s.decrease)))
+		s.generation++
+		s.stats.Decreases++
+		for _, signal := range s.signals {
+			if r, ok := signal.(interface{ Reset() }); ok {
+				r.Reset()
+			}
+		}
+	} else {
+		s.stats.Successes++
+		s.limit += s.increase / s.limit
+		if s.limit > float64(s.maxLimit) {
+			s.limit = float64(s.maxLimit)
+		}
+	}
+	s.broadcastUnsafe()
+	return nil
+}
+
+// acquireUnsafe 占用一个票证，调用时必须持有锁
+func (s *AdaptiveSemaphore) acquireUnsafe() *AdaptiveTicket {
+	s.inFlight++
+	return &AdaptiveTicket{sem: s, acquired: s.now(), generation: s.generation}
+}
+
+// limitUnsafe 返回当前上限的整数部分，调用时必须持有锁
+func (s *AdaptiveSemaphore) limitUnsafe() int {
+	return int(s.limit)
+}
+
+// broadcastUnsafe 唤醒所有等待者重新检查上限，调用时必须持有锁
+func (s *AdaptiveSemaphore) broadcastUnsafe() {
+	close(s.released)
+	s.released = make(chan struct{})
+}
+
+// clamp 把上限限制在 [minLimit, maxLimit] 范围内
+func (s *AdaptiveSemaphore) clamp(limit int) int {
+	if limit < s.minLimit {
+		return s.minLimit
+	}
+	if limit > s.maxLimit {
+		return s.maxLimit
+	}
+	return limit
+}
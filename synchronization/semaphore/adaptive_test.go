@@ -0,0 +1,211 @@
+package semaphore
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errOverloaded = errors.New("下游过载")
+
+// 测试成功时加性增加、失败时乘性减少
+func TestAdaptiveSemaphoreAIMD(t *testing.T) {
+	s := NewAdaptive(4, WithLimitBounds(2, 8))
+	assert.Equal(t, 4, s.Limit())
+
+	// 每一轮（约等于上限个成功调用）上限加一
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, s.Do(context.Background(), func() error { return nil }))
+	}
+	assert.Equal(t, 5, s.Limit())
+
+	// 失败时上限减半
+	assert.ErrorIs(t, s.Do(context.Background(), func() error { return errOverloaded }), errOverloaded)
+	assert.Equal(t, 2, s.Limit())
+	assert.ErrorIs(t, s.Do(context.Background(), func() error { return errOverloaded }), errOverloaded)
+	assert.Equal(t, 2, s.Limit(), "上限不低于下限")
+
+	// 持续成功时上限增长到上界为止
+	for i := 0; i < 100; i++ {
+		s.Do(context.Background(), func() error { return nil })
+	}
+	assert.Equal(t, 8, s.Limit())
+
+	stats := s.Stats()
+	assert.Equal(t, AdaptiveStats{Limit: 8, Successes: 105, Overloads: 2, Decreases: 2}, stats)
+}
+
+// 测试同一批调用一起失败时只降低一次
+func TestAdaptiveSemaphoreSingleDecreasePerGeneration(t *testing.T) {
+	s := NewAdaptive(8)
+
+	tickets := make([]*AdaptiveTicket, 0, 8)
+	for i := 0; i < 8; i++ {
+		ticket, ok := s.TryAcquire()
+		assert.True(t, ok)
+		tickets = append(tickets, ticket)
+	}
+	_, ok := s.TryAcquire()
+	assert.False(t, ok, "达到上限后无法获取")
+	assert.Equal(t, 8, s.InFlight())
+
+	for _, ticket := range tickets {
+		assert.NoError(t, ticket.Release(errOverloaded))
+	}
+	assert.Equal(t, 4, s.Limit(), "同一代的票证只降低一次")
+	assert.Equal(t, 1, s.Stats().Decreases)
+	assert.Equal(t, 8, s.Stats().Overloads)
+
+	// 降低之后获取的票证失败会再次降低
+	ticket, _ := s.TryAcquire()
+	ticket.Release(errOverloaded)
+	assert.Equal(t, 2, s.Limit())
+
+	assert.ErrorIs(t, ticket.Release(nil), ErrIllegalRelease)
+}
+
+// 测试上限降低后等待者按新上限获取
+func TestAdaptiveSemaphoreBlocksAtLimit(t *testing.T) {
+	s := NewAdaptive(2)
+	first, _ := s.TryAcquire()
+	second, _ := s.TryAcquire()
+
+	acquired := make(chan *AdaptiveTicket, 1)
+	go func() {
+		ticket, err := s.Acquire(context.Background())
+		assert.NoError(t, err)
+		acquired <- ticket
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("达到上限时应阻塞")
+	case <-time.After(30 * time.Millisecond):
+	}
+
+	// 上限降为1，释放一个票证后仍有一个调用在执行，等待者继续阻塞
+	first.Release(errOverloaded)
+	assert.Equal(t, 1, s.Limit())
+	select {
+	case <-acquired:
+		t.Fatal("正在执行的调用数没有低于新上限时应继续阻塞")
+	case <-time.After(30 * time.Millisecond):
+	}
+
+	second.Release(nil)
+	select {
+	case ticket := <-acquired:
+		ticket.Release(nil)
+	case <-time.After(time.Second):
+		t.Fatal("释放后等待者应获取到票证")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := s.Acquire(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// 测试错误率信号
+func TestErrorRateSignal(t *testing.T) {
+	signal := ErrorRateSignal(4, 0.5)
+	assert.False(t, signal.Observe(Sample{}))
+	assert.False(t, signal.Observe(Sample{Err: errOverloaded}), "窗口填满之前不判断")
+	assert.False(t, signal.Observe(Sample{Err: errOverloaded}))
+	assert.False(t, signal.Observe(Sample{}), "错误率 50% 没有超过阈值")
+	assert.True(t, signal.Observe(Sample{Err: errOverloaded}), "错误率 75%")
+	assert.False(t, signal.Observe(Sample{}), "最早的样本移出窗口后错误率 50%")
+
+	// 单个错误不足以降低上限
+	s := NewAdaptive(10, WithSignals(ErrorRateSignal(10, 0.2)))
+	for i := 0; i < 10; i++ {
+		err := error(nil)
+		if i%5 == 0 {
+			err = errOverloaded
+		}
+		s.Do(context.Background(), func() error { return err })
+	}
+	assert.Equal(t, 0, s.Stats().Decreases)
+}
+
+// 测试延迟百分位信号
+func TestLatencySignal(t *testing.T) {
+	signal := LatencySignal(10, 90, 100*time.Millisecond)
+	for i := 0; i < 9; i++ {
+		assert.False(t, signal.Observe(Sample{Latency: time.Second}), "窗口填满之前不判断")
+	}
+	assert.True(t, signal.Observe(Sample{Latency: 10 * time.Millisecond}))
+
+	signal = LatencySignal(10, 90, 100*time.Millisecond)
+	for i := 0; i < 9; i++ {
+		signal.Observe(Sample{Latency: 10 * time.Millisecond})
+	}
+	assert.False(t, signal.Observe(Sample{Latency: time.Second}), "只有 p100 超过目标")
+
+	// 使用可控的时钟模拟慢调用
+	now := time.Unix(0, 0)
+	s := NewAdaptive(10, WithSignals(LatencySignal(2, 50, 100*time.Millisecond)))
+	s.now = func() time.Time { return now }
+	for i := 0; i < 2; i++ {
+		ticket, _ := s.TryAcquire()
+		now = now.Add(200 * time.Millisecond)
+		ticket.Release(nil)
+	}
+	assert.Equal(t, 5, s.Limit(), "延迟超过目标时降低上限，即使调用成功")
+}
+
+// 测试多个信号组合以及所有信号都会记录样本
+func TestAdaptiveSemaphoreMultipleSignals(t *testing.T) {
+	var observed int32
+	counting := SignalFunc(func(sample Sample) bool {
+		atomic.AddInt32(&observed, 1)
+		return false
+	})
+	s := NewAdaptive(4, WithSignals(ErrorSignal(), counting), WithMultiplicativeDecrease(0.75))
+
+	s.Do(context.Background(), func() error { return errOverloaded })
+	assert.Equal(t, int32(1), atomic.LoadInt32(&observed), "前面的信号判断过载时后面的信号仍要记录样本")
+	assert.Equal(t, 3, s.Limit())
+}
+
+// 测试并发调用时正在执行的调用数不超过上限
+func TestAdaptiveSemaphoreConcurrent(t *testing.T) {
+	s := NewAdaptive(4, WithLimitBounds(1, 16))
+	var running, maxRunning, calls int32
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s.Do(context.Background(), func() error {
+				n := atomic.AddInt32(&running, 1)
+				defer atomic.AddInt32(&running, -1)
+				for {
+					m := atomic.LoadInt32(&maxRunning)
+					if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+						break
+					}
+				}
+				atomic.AddInt32(&calls, 1)
+				time.Sleep(time.Millisecond)
+				if i%10 == 0 {
+					return errOverloaded
+				}
+				return nil
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(50), calls)
+	assert.LessOrEqual(t, maxRunning, int32(16))
+	assert.Equal(t, 0, s.InFlight())
+	stats := s.Stats()
+	assert.Equal(t, 50, stats.Successes+stats.Overloads)
+}
@@ -0,0 +1,244 @@
+package semaphore
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// KeyedSemaphore 按键（例如主机名、租户）维护相互独立的票证计数
+//
+// 每个键的信号量在第一次使用时创建，空闲超过一定时间后被回收；
+// 可以额外设置全局上限，限制所有键同时持有的票证总数。
+type KeyedSemaphore struct {
+	mu sync.Mutex
+
+	// 每个键的票证数量
+	perKey int
+
+	// 所有键合计的票证上限，0 表示不限制
+	globalLimit int
+
+	// 所有键当前持有的票证总数
+	inUse int
+
+	// 空闲键的保留时间，0 表示键一旦空闲立即回收
+	idleTimeout time.Duration
+
+	// 按键保存的状态
+	keys map[string]*keyState
+
+	// 有票证被归还时关闭，用于唤醒所有等待者
+	released chan struct{}
+
+	// 上一次扫描空闲键的时间
+	lastSweep time.Time
+
+	// 时间来源，便于测试
+	now func() time.Time
+}
+
+// keyState 单个键的票证使用情况
+type keyState struct {
+	held     int       // 已持有的票证数
+	waiters  int       // 正在等待的调用者数
+	lastUsed time.Time // 最后一次获取或释放的时间
+}
+
+// KeyedOption 配置 KeyedSemaphore 的选项
+type KeyedOption func(*KeyedSemaphore)
+
+// WithGlobalLimit 设置所有键合计的票证上限，n <= 0 表示不限制
+func WithGlobalLimit(n int) KeyedOption {
+	return func(ks *KeyedSemaphore) {
+		if n < 0 {
+			n = 0
+		}
+		ks.globalLimit = n
+	}
+}
+
+// WithIdleTimeout 设置空闲键的保留时间，超过该时间没有使用的键会被回收
+func WithIdleTimeout(d time.Duration) KeyedOption {
+	return func(ks *KeyedSemaphore) {
+		if d < 0 {
+			d = 0
+		}
+		ks.idleTimeout = d
+	}
+}
+
+// NewKeyed 创建按键限流的信号量，perKey 为每个键的票证数量
+func NewKeyed(perKey int, opts ...KeyedOption) *KeyedSemaphore {
+	if perKey <= 0 {
+		perKey = 1 // 确保至少有一个票证
+	}
+
+	ks := &KeyedSemaphore{
+		perKey:   perKey,
+		keys:     make(map[string]*keyState),
+		released: make(chan struct{}),
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(ks)
+	}
+	ks.lastSweep = ks.now()
+	return ks
+}
+
+// Acquire 获取指定键的一个票证，键的票证或全局票证用尽时阻塞等待
+// 如果提供的context被取消，则返回context的错误
+func (ks *KeyedSemaphore) Acquire(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ks.mu.Lock()
+	state := ks.stateUnsafe(key)
+	for !ks.tryAcquireUnsafe(state) {
+		wait := ks.released
+		state.waiters++
+		ks.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			ks.mu.Lock()
+			state.waiters--
+			ks.touchUnsafe(key, state)
+			ks.mu.Unlock()
+			return ctx.Err()
+		}
+
+		ks.mu.Lock()
+		state.waiters--
+	}
+	ks.touchUnsafe(key, state)
+	ks.mu.Unlock()
+	return nil
+}
+
+// TryAcquire 非阻塞地获取指定键的一个票证，立即返回结果
+func (ks *KeyedSemaphore) TryAcquire(key string) bool {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	state := ks.stateUnsafe(key)
+	ok := ks.tryAcquireUnsafe(state)
+	ks.touchUnsafe(key, state)
+	return ok
+}
+
+// Release 归还指定键的一个票证，该键没有持有票证时返回 ErrIllegalRelease
+func (ks *KeyedSemaphore) Release(key string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	state, ok := ks.keys[key]
+	if !ok || state.held <= 0 {
+		return ErrIllegalRelease
+	}
+	state.held--
+	ks.inUse--
+	ks.touchUnsafe(key, state)
+
+	// 唤醒所有等待者重新检查，等待者可能在等这个键，也可能在等全局票证
+	close(ks.released)
+	ks.released = make(chan struct{})
+	return nil
+}
+
+// Held 返回指定键当前持有的票证数
+func (ks *KeyedSemaphore) Held(key string) int {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if state, ok := ks.keys[key]; ok {
+		return state.held
+	}
+	return 0
+}
+
+// InUse 返回所有键合计持有的票证数
+func (ks *KeyedSemaphore) InUse() int {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	return ks.inUse
+}
+
+// Keys 返回当前被跟踪的键，按字典序排列
+func (ks *KeyedSemaphore) Keys() []string {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	keys := make([]string, 0, len(ks.keys))
+	for key := range ks.keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// EvictIdle 立即回收所有空闲超时的键，返回回收的数量
+// 获取和释放票证时也会定期回收，通常不需要手动调用
+func (ks *KeyedSemaphore) EvictIdle() int {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	return ks.evictIdleUnsafe(ks.now())
+}
+
+// stateUnsafe 返回键的状态，不存在时创建，调用时必须持有锁
+func (ks *KeyedSemaphore) stateUnsafe(key string) *keyState {
+	state, ok := ks.keys[key]
+	if !ok {
+		state = &keyState{lastUsed: ks.now()}
+		ks.keys[key] = state
+	}
+	return state
+}
+
+// tryAcquireUnsafe 在键和全局都有余量时占用一个票证，调用时必须持有锁
+func (ks *KeyedSemaphore) tryAcquireUnsafe(state *keyState) bool {
+	if state.held >= ks.perKey {
+		return false
+	}
+	if ks.globalLimit > 0 && ks.inUse >= ks.globalLimit {
+		return false
+	}
+	state.held++
+	ks.inUse++
+	return true
+}
+
+// touchUnsafe 更新键的使用时间并回收空闲键，调用时必须持有锁
+func (ks *KeyedSemaphore) touchUnsafe(key string, state *keyState) {
+	now := ks.now()
+	state.lastUsed = now
+
+	if ks.idleTimeout == 0 {
+		// 不保留空闲键，只需检查当前键
+		if state.held == 0 && state.waiters == 0 {
+			delete(ks.keys, key)
+		}
+		return
+	}
+	// 每隔一个保留时间扫描一次，避免每次操作都遍历所有键
+	if now.Sub(ks.lastSweep) >= ks.idleTimeout {
+		ks.evictIdleUnsafe(now)
+	}
+}
+
+// evictIdleUnsafe 删除没有持有者和等待者且空闲超时的键，调用时必须持有锁
+func (ks *KeyedSemaphore) evictIdleUnsafe(now time.Time) int {
+	ks.lastSweep = now
+	evicted := 0
+	for key, state := range ks.keys {
+		if state.held == 0 && state.waiters == 0 && now.Sub(state.lastUsed) >= ks.idleTimeout {
+			delete(ks.keys, key)
+			evicted++
+		}
+	}
+	return evicted
+}
@@ -0,0 +1,167 @@
+package semaphore
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试每个键的票证相互独立
+func TestKeyedSemaphorePerKey(t *testing.T) {
+	ks := NewKeyed(2)
+
+	assert.NoError(t, ks.Acquire(context.Background(), "a.example.com"))
+	assert.True(t, ks.TryAcquire("a.example.com"))
+	assert.False(t, ks.TryAcquire("a.example.com"), "同一个键的票证已用尽")
+	assert.True(t, ks.TryAcquire("b.example.com"), "其他键不受影响")
+
+	assert.Equal(t, 2, ks.Held("a.example.com"))
+	assert.Equal(t, 1, ks.Held("b.example.com"))
+	assert.Equal(t, 3, ks.InUse())
+	assert.Equal(t, []string{"a.example.com", "b.example.com"}, ks.Keys())
+
+	// 键用尽时阻塞，直到 context 超时
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, ks.Acquire(ctx, "a.example.com"), context.DeadlineExceeded)
+
+	assert.NoError(t, ks.Release("a.example.com"))
+	assert.NoError(t, ks.Release("a.example.com"))
+	assert.NoError(t, ks.Release("b.example.com"))
+	assert.ErrorIs(t, ks.Release("a.example.com"), ErrIllegalRelease)
+	assert.ErrorIs(t, ks.Release("unknown"), ErrIllegalRelease)
+	assert.Equal(t, 0, ks.InUse())
+
+	// 没有设置保留时间时，空闲键立即被回收
+	assert.Empty(t, ks.Keys())
+}
+
+// 测试释放后唤醒等待同一个键的调用者
+func TestKeyedSemaphoreWakesWaiter(t *testing.T) {
+	ks := NewKeyed(1)
+	assert.True(t, ks.TryAcquire("tenant-1"))
+
+	acquired := make(chan error, 1)
+	go func() {
+		acquired <- ks.Acquire(context.Background(), "tenant-1")
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("键的票证用尽时应阻塞")
+	case <-time.After(30 * time.Millisecond):
+	}
+	assert.Equal(t, []string{"tenant-1"}, ks.Keys(), "有等待者的键不应被回收")
+
+	assert.NoError(t, ks.Release("tenant-1"))
+	select {
+	case err := <-acquired:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("释放后等待者应获取到票证")
+	}
+	assert.Equal(t, 1, ks.Held("tenant-1"))
+	assert.NoError(t, ks.Release("tenant-1"))
+}
+
+// 测试全局上限限制所有键合计的并发数
+func TestKeyedSemaphoreGlobalLimit(t *testing.T) {
+	ks := NewKeyed(3, WithGlobalLimit(4))
+
+	var current, peak atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		key := []string{"a", "b", "c"}[i%3]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := ks.Acquire(context.Background(), key); err != nil {
+				t.Errorf("获取票证失败: %v", err)
+				return
+			}
+			n := current.Add(1)
+			for {
+				old := peak.Load()
+				if n <= old || peak.CompareAndSwap(old, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			current.Add(-1)
+			assert.NoError(t, ks.Release(key))
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, peak.Load(), int32(4), "并发数不应超过全局上限")
+	assert.Equal(t, 0, ks.InUse())
+
+	// 全局票证用尽时，即使键还有余量也无法获取
+	ks = NewKeyed(2, WithGlobalLimit(2))
+	assert.True(t, ks.TryAcquire("a"))
+	assert.True(t, ks.TryAcquire("b"))
+	assert.False(t, ks.TryAcquire("c"))
+	assert.False(t, ks.TryAcquire("a"))
+
+	acquired := make(chan error, 1)
+	go func() {
+		acquired <- ks.Acquire(context.Background(), "c")
+	}()
+	time.Sleep(20 * time.Millisecond)
+	assert.NoError(t, ks.Release("a"), "释放其他键的票证也应唤醒等待全局票证的调用者")
+	select {
+	case err := <-acquired:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("全局票证归还后等待者应获取到票证")
+	}
+}
+
+// 测试空闲键在超过保留时间后被回收
+func TestKeyedSemaphoreIdleEviction(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ks := NewKeyed(1, WithIdleTimeout(time.Minute))
+	ks.now = func() time.Time { return now }
+	ks.lastSweep = now
+
+	assert.True(t, ks.TryAcquire("busy"))
+	assert.True(t, ks.TryAcquire("idle"))
+	assert.NoError(t, ks.Release("idle"))
+	assert.Equal(t, []string{"busy", "idle"}, ks.Keys(), "保留时间内空闲键不会被回收")
+
+	now = now.Add(30 * time.Second)
+	assert.Equal(t, 0, ks.EvictIdle())
+
+	// 超过保留时间后，下一次操作时顺带回收，持有票证的键保留
+	now = now.Add(time.Minute)
+	assert.True(t, ks.TryAcquire("fresh"))
+	assert.Equal(t, []string{"busy", "fresh"}, ks.Keys())
+
+	assert.NoError(t, ks.Release("busy"))
+	assert.NoError(t, ks.Release("fresh"))
+	now = now.Add(time.Minute)
+	assert.Equal(t, 2, ks.EvictIdle())
+	assert.Empty(t, ks.Keys())
+
+	// 被回收的键再次使用时重新创建
+	assert.NoError(t, ks.Acquire(context.Background(), "busy"))
+	assert.Equal(t, 1, ks.Held("busy"))
+}
+
+// 测试已取消的 context 不会获取票证也不会留下键
+func TestKeyedSemaphoreCancelled(t *testing.T) {
+	ks := NewKeyed(0) // 至少一个票证
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.ErrorIs(t, ks.Acquire(ctx, "a"), context.Canceled)
+	assert.Equal(t, 0, ks.InUse())
+	assert.Empty(t, ks.Keys())
+
+	assert.True(t, ks.TryAcquire("a"))
+	assert.False(t, ks.TryAcquire("a"))
+}
@@ -0,0 +1,113 @@
+package semaphore
+
+import (
+	"context"
+	"sort"
+	"sync/atomic"
+)
+
+// semaphoreIDs 为所有信号量分配全局唯一编号
+var semaphoreIDs atomic.Uint64
+
+// nextSemaphoreID 返回下一个信号量编号
+func nextSemaphoreID() uint64 {
+	return semaphoreIDs.Add(1)
+}
+
+// AcquireAll 从多个信号量各获取一个票证，要么全部获取成功，要么一个也不持有
+//
+// 所有调用者都按照信号量的创建顺序依次获取，因此多个协程以不同顺序传入
+// 同一组信号量也不会互相等待形成死锁。同一个信号量出现多次时会获取多个票证。
+// 任意一步失败（例如 context 被取消）时，已经获取的票证会全部归还。
+func AcquireAll(ctx context.Context, sems ...*Semaphore) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ordered := make([]*Semaphore, len(sems))
+	copy(ordered, sems)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].id < ordered[j].id
+	})
+
+	for i, s := range ordered {
+		if err := s.Acquire(ctx); err != nil {
+			// 回滚已获取的票证
+			for _, held := range ordered[:i] {
+				held.Release()
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// TryAcquireAll 非阻塞地从多个信号量各获取一个票证，任意一个不可用时不持有任何票证
+func TryAcquireAll(sems ...*Semaphore) bool {
+	ordered := make([]*Semaphore, len(sems))
+	copy(ordered, sems)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].id < ordered[j].id
+	})
+
+	for i, s := range ordered {
+		if !s.TryAcquire() {
+			for _, held := range ordered[:i] {
+				held.Release()
+			}
+			return false
+		}
+	}
+	return true
+}
+
+// ReleaseAll 归还通过 AcquireAll 获取的票证，返回遇到的第一个错误
+func ReleaseAll(sems ...*Semaphore) error {
+	var firstErr error
+	for _, s := range sems {
+		if err := s.Release(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// WeightedRequest 描述从带权重信号量获取的资源量
+type WeightedRequest struct {
+	Sem    *WeightedSemaphore
+	Weight int64
+}
+
+// AcquireAllWeighted 从多个带权重信号量获取指定的资源量，要么全部获取成功，要么一个也不持有
+// 与 AcquireAll 一样按照信号量的创建顺序获取以避免死锁
+func AcquireAllWeighted(ctx context.Context, reqs ...WeightedRequest) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ordered := make([]WeightedRequest, len(reqs))
+	copy(ordered, reqs)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Sem.id < ordered[j].Sem.id
+	})
+
+	for i, req := range ordered {
+		if err := req.Sem.Acquire(ctx, req.Weight); err != nil {
+			ReleaseAllWeighted(ordered[:i]...)
+			return err
+		}
+		// 带权重信号量在资源充足时不会检查 context，这里补充检查以便及时回滚
+		if err := ctx.Err(); err != nil {
+			ReleaseAllWeighted(ordered[:i+1]...)
+			return err
+		}
+	}
+	return nil
+}
+
+// ReleaseAllWeighted 归还通过 AcquireAllWeighted 获取的资源
+func ReleaseAllWeighted(reqs ...WeightedRequest) {
+	for _, req := range reqs {
+		req.Sem.Release(req.Weight)
+	}
+}
@@ -0,0 +1,127 @@
+package semaphore
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试同时获取多个信号量
+func TestAcquireAll(t *testing.T) {
+	db := New(2)
+	api := New(1)
+
+	err := AcquireAll(context.Background(), db, api)
+	assert.NoError(t, err, "两个信号量都有票证时应获取成功")
+	assert.Equal(t, 1, db.Available())
+	assert.Equal(t, 0, api.Available())
+
+	assert.NoError(t, ReleaseAll(db, api))
+	assert.Equal(t, 2, db.Available())
+	assert.Equal(t, 1, api.Available())
+
+	// 同一个信号量出现多次时获取多个票证
+	assert.NoError(t, AcquireAll(context.Background(), db, db))
+	assert.Equal(t, 0, db.Available())
+	assert.NoError(t, ReleaseAll(db, db))
+}
+
+// 测试任意一个信号量无法获取时回滚所有票证
+func TestAcquireAllRollback(t *testing.T) {
+	db := New(2)
+	api := New(1)
+	assert.True(t, api.TryAcquire())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := AcquireAll(ctx, db, api)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 2, db.Available(), "失败时不应持有数据库票证")
+
+	assert.False(t, TryAcquireAll(db, api), "API票证不可用时非阻塞获取应失败")
+	assert.Equal(t, 2, db.Available(), "非阻塞获取失败时不应持有数据库票证")
+
+	api.Release()
+	assert.True(t, TryAcquireAll(api, db))
+	assert.NoError(t, ReleaseAll(api, db))
+
+	// 已取消的 context 直接返回
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	assert.ErrorIs(t, AcquireAll(cancelled, db), context.Canceled)
+	assert.Equal(t, 2, db.Available())
+}
+
+// 测试以相反顺序并发获取同一组信号量不会死锁
+func TestAcquireAllNoDeadlock(t *testing.T) {
+	db := New(1)
+	api := New(1)
+
+	var inCritical, maxInCritical atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sems := []*Semaphore{db, api}
+			if i%2 == 1 {
+				sems = []*Semaphore{api, db}
+			}
+
+			for j := 0; j < 20; j++ {
+				if err := AcquireAll(context.Background(), sems...); err != nil {
+					t.Errorf("获取失败: %v", err)
+					return
+				}
+				n := inCritical.Add(1)
+				if n > maxInCritical.Load() {
+					maxInCritical.Store(n)
+				}
+				inCritical.Add(-1)
+				ReleaseAll(sems...)
+			}
+		}(i)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("以不同顺序获取信号量发生了死锁")
+	}
+	assert.Equal(t, int32(1), maxInCritical.Load(), "同一时间只能有一个协程同时持有两个票证")
+	assert.Equal(t, 1, db.Available())
+	assert.Equal(t, 1, api.Available())
+}
+
+// 测试同时获取多个带权重信号量
+func TestAcquireAllWeighted(t *testing.T) {
+	memory := NewWeighted(100)
+	cpu := NewWeighted(4)
+
+	job := []WeightedRequest{{Sem: memory, Weight: 60}, {Sem: cpu, Weight: 2}}
+	assert.NoError(t, AcquireAllWeighted(context.Background(), job...))
+	assert.Equal(t, int64(40), memory.Available())
+	assert.Equal(t, int64(2), cpu.Available())
+
+	// 内存不足时等待超时，不应占用CPU
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := AcquireAllWeighted(ctx, WeightedRequest{Sem: cpu, Weight: 1}, WeightedRequest{Sem: memory, Weight: 50})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int64(2), cpu.Available(), "失败时不应持有CPU资源")
+
+	ReleaseAllWeighted(job...)
+	assert.Equal(t, int64(100), memory.Available())
+	assert.Equal(t, int64(4), cpu.Available())
+}
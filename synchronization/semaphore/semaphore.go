@@ -54,6 +54,9 @@ type Semaphore struct {
 
 	// 已获取的票证数量
 	acquired int
+
+	// 全局唯一的编号，AcquireAll 按编号顺序获取以避免死锁
+	id uint64
 }
 
 // New 创建一个新的信号量，指定票证总数
@@ -65,6 +68,7 @@ func New(size int) *Semaphore {
 	s := &Semaphore{
 		tickets: make(chan struct{}, size),
 		size:    size,
+		id:      nextSemaphoreID(),
 	}
 	s.initialize() // 初始化填充通道
 	return s
@@ -276,12 +280,16 @@ type WeightedSemaphore struct {
 
 	// 当资源被释放时通知等待者
 	cond *sync.Cond
+
+	// 全局唯一的编号，AcquireAllWeighted 按编号顺序获取以避免死锁
+	id uint64
 }
 
 // NewWeighted 创建一个新的带权重的信号量
 func NewWeighted(capacity int64) *WeightedSemaphore {
 	ws := &WeightedSemaphore{
 		capacity: capacity,
+		id:       nextSemaphoreID(),
 	}
 	ws.cond = sync.NewCond(&ws.mu)
 	return ws